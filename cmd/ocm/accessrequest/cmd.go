@@ -0,0 +1,40 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessrequest
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/cmd/ocm/accessrequest/decide"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/accessrequest/list"
+	pkg "github.com/openshift-online/ocm-cli/pkg/accessrequest"
+)
+
+var Cmd = &cobra.Command{
+	Use:     "access-request COMMAND",
+	Aliases: []string{"access-requests", "accessrequest", "accessrequests"},
+	Short:   "Manage the requests of SREs to access clusters",
+	Long: "List, approve and deny the requests of SREs to access clusters, for organizations " +
+		"that have the access transparency capability enabled.",
+	Args: cobra.MinimumNArgs(1),
+}
+
+func init() {
+	Cmd.AddCommand(decide.NewCmd(pkg.DecisionApproved))
+	Cmd.AddCommand(decide.NewCmd(pkg.DecisionDenied))
+	Cmd.AddCommand(list.Cmd)
+}
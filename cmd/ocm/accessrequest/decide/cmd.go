@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decide
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/accessrequest"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+// verbs contains the name of the command used to make each decision.
+var verbs = map[string]string{
+	accessrequest.DecisionApproved: "approve",
+	accessrequest.DecisionDenied:   "deny",
+}
+
+// titles contains the capitalized verbs used in the help of the commands.
+var titles = map[string]string{
+	accessrequest.DecisionApproved: "Approve",
+	accessrequest.DecisionDenied:   "Deny",
+}
+
+// NewCmd creates the command that makes the given decision about an access request.
+func NewCmd(decision string) *cobra.Command {
+	verb := verbs[decision]
+	title := titles[decision]
+	var justification string
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("%s ACCESS_REQUEST_ID", verb),
+		Short: fmt.Sprintf("%s an access request", title),
+		Long: fmt.Sprintf(
+			"%s the access request with the given identifier. Only requests that are "+
+				"still pending can be decided.",
+			title,
+		),
+		Example: fmt.Sprintf(`  # %s access request 'abc'
+  ocm access-request %s abc --justification "Needed to fix the ingress"`,
+			title, verb,
+		),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, argv []string) error {
+			return run(decision, argv[0], justification)
+		},
+	}
+	fs := cmd.Flags()
+	fs.StringVar(
+		&justification,
+		"justification",
+		"",
+		"Reason for the decision.",
+	)
+	if decision == accessrequest.DecisionDenied {
+		cmd.MarkFlagRequired("justification")
+	}
+	readonly.Mark(cmd)
+	return cmd
+}
+
+func run(decision, id, justification string) error {
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	err = accessrequest.Decide(connection, id, decision, justification)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Access request '%s' %s\n", id, strings.ToLower(decision))
+	return nil
+}
@@ -0,0 +1,285 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package list
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/accessrequest"
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/search"
+)
+
+var args struct {
+	clusterKey string
+	pending    bool
+	search     string
+	json       bool
+	watch      bool
+	interval   time.Duration
+	hook       string
+}
+
+var Cmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List access requests",
+	Long: "List the requests of SREs to access clusters. With the '--watch' option the " +
+		"command keeps running and reports new pending requests as they arrive, optionally " +
+		"running a hook command for each of them.",
+	Example: `  # List the pending access requests
+  ocm access-request list --pending
+
+  # Send a notification for each new pending access request
+  ocm access-request list --watch --hook 'notify-send "Access request $OCM_ACCESS_REQUEST_ID"'`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVarP(
+		&args.clusterKey,
+		"cluster",
+		"c",
+		"",
+		"Name or ID or external_id of the cluster. Only the access requests for this "+
+			"cluster will be listed.",
+	)
+	flags.BoolVar(
+		&args.pending,
+		"pending",
+		false,
+		"Only list the access requests that are pending a decision.",
+	)
+	flags.StringVar(
+		&args.search,
+		"search",
+		"",
+		"Search expression used to filter the access requests, for example "+
+			"\"requested_by = 'jdoe'\".",
+	)
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the access requests in JSON format.",
+	)
+	flags.BoolVar(
+		&args.watch,
+		"watch",
+		false,
+		"Keep running and report new pending access requests as they arrive.",
+	)
+	flags.DurationVar(
+		&args.interval,
+		"interval",
+		30*time.Second,
+		"Time between checks for new access requests when using the '--watch' option.",
+	)
+	flags.StringVar(
+		&args.hook,
+		"hook",
+		"",
+		"Shell command to run for each new pending access request when using the "+
+			"'--watch' option. The details of the request are passed in JSON format in "+
+			"the standard input, and the 'OCM_ACCESS_REQUEST_ID', "+
+			"'OCM_ACCESS_REQUEST_CLUSTER_ID' and 'OCM_ACCESS_REQUEST_REQUESTED_BY' "+
+			"environment variables.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	if args.search != "" {
+		err := search.Lint(args.search)
+		if err != nil {
+			return fmt.Errorf("Invalid search expression: %v", err)
+		}
+	}
+	if args.hook != "" && !args.watch {
+		return fmt.Errorf("Option '--hook' can only be used with '--watch'")
+	}
+	if args.watch && args.json {
+		return fmt.Errorf("Options '--watch' and '--json' are mutually exclusive")
+	}
+	if args.interval <= 0 {
+		return fmt.Errorf("Option '--interval' must be a positive duration")
+	}
+	if args.clusterKey != "" && !c.IsValidClusterKey(args.clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			args.clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Build the search query. When watching we are only interested in pending requests:
+	var terms []string
+	if args.clusterKey != "" {
+		cluster, err := c.GetCluster(connection, args.clusterKey)
+		if err != nil {
+			return fmt.Errorf("Failed to get cluster '%s': %v", args.clusterKey, err)
+		}
+		terms = append(terms, fmt.Sprintf("cluster_id = '%s'", cluster.ID()))
+	}
+	if args.pending || args.watch {
+		terms = append(terms, fmt.Sprintf("status.state = '%s'", accessrequest.StatePending))
+	}
+	if args.search != "" {
+		terms = append(terms, args.search)
+	}
+	if len(terms) > 1 {
+		for i, term := range terms {
+			terms[i] = fmt.Sprintf("(%s)", term)
+		}
+	}
+	query := strings.Join(terms, " and ")
+
+	if args.watch {
+		return watch(func() ([]*accessrequest.AccessRequest, error) {
+			return accessrequest.List(connection, query)
+		})
+	}
+
+	requests, err := accessrequest.List(connection, query)
+	if err != nil {
+		return err
+	}
+	if args.json {
+		data, err := json.Marshal(requests)
+		if err != nil {
+			return fmt.Errorf("Can't marshal access requests: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	writeHeader(writer)
+	for _, request := range requests {
+		writeRow(writer, request)
+	}
+	return writer.Flush()
+}
+
+// watch polls the server for pending access requests, printing the ones that haven't been seen
+// before and running the hook for them. It only returns when the list can't be retrieved.
+func watch(list func() ([]*accessrequest.AccessRequest, error)) error {
+	seen := map[string]bool{}
+	header := false
+	for {
+		requests, err := list()
+		if err != nil {
+			return err
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if !header {
+			writeHeader(writer)
+			header = true
+		}
+		var fresh []*accessrequest.AccessRequest
+		for _, request := range requests {
+			if seen[request.ID] {
+				continue
+			}
+			seen[request.ID] = true
+			fresh = append(fresh, request)
+			writeRow(writer, request)
+		}
+		err = writer.Flush()
+		if err != nil {
+			return err
+		}
+		for _, request := range fresh {
+			err = runHook(request)
+			if err != nil {
+				fmt.Fprintf(
+					os.Stderr,
+					"Hook failed for access request '%s': %v\n",
+					request.ID, err,
+				)
+			}
+		}
+		time.Sleep(args.interval)
+	}
+}
+
+// runHook runs the hook command, if any, for the given access request.
+func runHook(request *accessrequest.AccessRequest) error {
+	if args.hook == "" {
+		return nil
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	var hook *exec.Cmd
+	if runtime.GOOS == "windows" {
+		hook = exec.Command("cmd", "/C", args.hook)
+	} else {
+		hook = exec.Command("sh", "-c", args.hook)
+	}
+	hook.Env = append(
+		os.Environ(),
+		"OCM_ACCESS_REQUEST_ID="+request.ID,
+		"OCM_ACCESS_REQUEST_CLUSTER_ID="+request.ClusterID,
+		"OCM_ACCESS_REQUEST_REQUESTED_BY="+request.RequestedBy,
+	)
+	hook.Stdin = bytes.NewReader(data)
+	hook.Stdout = os.Stderr
+	hook.Stderr = os.Stderr
+	return hook.Run()
+}
+
+func writeHeader(writer *tabwriter.Writer) {
+	fmt.Fprintf(writer, "ID\tCLUSTER ID\tREQUESTED BY\tSTATE\tDEADLINE\tJUSTIFICATION\n")
+}
+
+func writeRow(writer *tabwriter.Writer, request *accessrequest.AccessRequest) {
+	var deadline string
+	if request.Deadline != nil {
+		deadline = request.Deadline.Format(time.RFC3339)
+	}
+	fmt.Fprintf(
+		writer,
+		"%s\t%s\t%s\t%s\t%s\t%s\n",
+		request.ID,
+		request.ClusterID,
+		request.RequestedBy,
+		request.State(),
+		deadline,
+		request.Justification,
+	)
+}
@@ -19,9 +19,12 @@ package account
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/orgdefaults"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/account/orgs"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/account/quota"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/quotatransfer"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/account/roles"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/sa"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/account/status"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/account/users"
 )
@@ -36,8 +39,11 @@ var Cmd = &cobra.Command{
 
 func init() {
 	Cmd.AddCommand(quota.Cmd)
+	Cmd.AddCommand(quotatransfer.Cmd)
 	Cmd.AddCommand(orgs.Cmd)
+	Cmd.AddCommand(orgdefaults.Cmd)
 	Cmd.AddCommand(status.Cmd)
 	Cmd.AddCommand(roles.Cmd)
 	Cmd.AddCommand(users.Cmd)
+	Cmd.AddCommand(sa.Cmd)
 }
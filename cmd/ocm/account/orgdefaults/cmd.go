@@ -0,0 +1,144 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orgdefaults
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/account"
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+var args struct {
+	org   string
+	set   []string
+	unset []string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "org-defaults",
+	Short: "Show or change the default cluster settings of an organization.",
+	Long: "Show or change the default cluster settings of an organization. The defaults are " +
+		"stored as labels of the organization and are used by the 'ocm create cluster " +
+		"--use-org-defaults' command for the options that aren't explicitly given. Only " +
+		"organization administrators can change them.",
+	Example: `  # Show the defaults of the organization of the current user
+  ocm account org-defaults
+
+  # Use the 'stable' channel group and add a 'team' label to the default machine pool
+  ocm account org-defaults --set channel-group=stable --set label.team=payments
+
+  # Stop using a default compute machine type
+  ocm account org-defaults --unset compute-machine-type`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVar(
+		&args.org,
+		"org",
+		"",
+		"Organization identifier. Defaults to the organization of the current user.",
+	)
+	fs.StringArrayVar(
+		&args.set,
+		"set",
+		nil,
+		"Default to set, in the form 'NAME=VALUE'. The name can be one of the options of "+
+			"the 'create cluster' command that support defaults or 'label.KEY' for a "+
+			"machine pool label. Can be used multiple times.",
+	)
+	fs.StringArrayVar(
+		&args.unset,
+		"unset",
+		nil,
+		"Name of a default to remove. Can be used multiple times.",
+	)
+	Cmd.RegisterFlagCompletionFunc("org", completion.Organizations)
+	readonly.MarkFlag(Cmd, "set")
+	readonly.MarkFlag(Cmd, "unset")
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the names before sending any request, so that nothing is changed if any of them
+	// is wrong:
+	values := map[string]string{}
+	names := []string{}
+	for _, text := range args.set {
+		name, value := arguments.ParseNameValuePair(text)
+		if _, err := account.ClusterDefaultsLabelKey(name); err != nil {
+			return err
+		}
+		values[name] = value
+		names = append(names, name)
+	}
+	for _, name := range args.unset {
+		if _, err := account.ClusterDefaultsLabelKey(name); err != nil {
+			return err
+		}
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	orgID := args.org
+	if orgID == "" {
+		orgID, err = account.CurrentOrganization(connection)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Apply the changes:
+	for _, name := range names {
+		err = account.SetClusterDefault(connection, orgID, name, values[name])
+		if err != nil {
+			return err
+		}
+	}
+	for _, name := range args.unset {
+		err = account.DeleteClusterDefault(connection, orgID, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Display the resulting defaults:
+	defaults, err := account.GetClusterDefaults(connection, orgID)
+	if err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "NAME\tVALUE\n")
+	for _, name := range defaults.Names() {
+		fmt.Fprintf(writer, "%s\t%s\n", name, defaults.Value(name))
+	}
+	return writer.Flush()
+}
@@ -17,10 +17,16 @@ limitations under the License.
 package orgs
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"os/exec"
+	"runtime"
+	"time"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 	"github.com/spf13/cobra"
 
@@ -28,20 +34,44 @@ import (
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/redact"
+	"github.com/openshift-online/ocm-cli/pkg/search"
 )
 
 var args struct {
 	columns   string
 	parameter []string
 	header    []string
+	search    string
+	watchNew  bool
+	interval  time.Duration
+	hook      string
+	redactPII bool
+	export    bool
+	withQuota bool
+	format    string
+	parallel  int
 }
 
 var Cmd = &cobra.Command{
 	Use:   "orgs",
 	Short: "List organizations.",
-	Long:  "Display a list of organizations.",
-	Args:  cobra.NoArgs,
-	RunE:  run,
+	Long: "Display a list of organizations. With the '--watch-new' option the command keeps " +
+		"running and reports the organizations created after it started, optionally " +
+		"running a hook command for each of them. With the '--export' option it writes a " +
+		"report in CSV or JSON format that contains the number of accounts of each " +
+		"organization and, with '--with-quota', the allowed and consumed quota.",
+	Example: `  # List the organizations
+  ocm account orgs
+
+  # Send a notification for each new organization with a name ending in '-partner'
+  ocm account orgs --watch-new --search "name like '%-partner'" \
+    --hook 'notify-send "New organization $OCM_ORG_NAME"'
+
+  # Export the organizations with their accounts and quota to a CSV file
+  ocm account orgs --export --with-quota > orgs.csv`,
+	Args: cobra.NoArgs,
+	RunE: run,
 }
 
 func init() {
@@ -55,12 +85,98 @@ func init() {
 		"id,name",
 		"Comma separated list of columns to display.",
 	)
+	fs.StringVar(
+		&args.search,
+		"search",
+		"",
+		"Search expression used to filter the organizations, for example "+
+			"\"name like 'my%'\".",
+	)
+	fs.BoolVar(
+		&args.watchNew,
+		"watch-new",
+		false,
+		"Keep running and report the organizations created after the command started.",
+	)
+	fs.DurationVar(
+		&args.interval,
+		"interval",
+		time.Minute,
+		"Time between checks for new organizations when using the '--watch-new' option.",
+	)
+	fs.StringVar(
+		&args.hook,
+		"hook",
+		"",
+		"Shell command to run for each new organization when using the '--watch-new' "+
+			"option. The details of the organization are passed in JSON format in the "+
+			"standard input, and the 'OCM_ORG_ID', 'OCM_ORG_NAME' and "+
+			"'OCM_ORG_EXTERNAL_ID' environment variables.",
+	)
+	fs.BoolVar(
+		&args.export,
+		"export",
+		false,
+		"Write a report with the number of accounts of each organization instead of the "+
+			"table.",
+	)
+	fs.BoolVar(
+		&args.withQuota,
+		"with-quota",
+		false,
+		"Add the allowed and consumed quota of each organization to the report generated "+
+			"by the '--export' option.",
+	)
+	fs.StringVar(
+		&args.format,
+		"format",
+		formatCSV,
+		"Format of the report generated by the '--export' option, either 'csv' or 'json'.",
+	)
+	fs.IntVar(
+		&args.parallel,
+		"parallel",
+		10,
+		"Maximum number of organizations whose details are retrieved simultaneously when "+
+			"using the '--export' option.",
+	)
+	arguments.AddRedactFlag(fs, &args.redactPII)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
 	// Create a context:
 	ctx := context.Background()
 
+	// Check the options:
+	if args.search != "" {
+		err := search.Lint(args.search)
+		if err != nil {
+			return fmt.Errorf("Invalid search expression: %v", err)
+		}
+	}
+	if args.hook != "" && !args.watchNew {
+		return fmt.Errorf("Option '--hook' can only be used with '--watch-new'")
+	}
+	if args.interval <= 0 {
+		return fmt.Errorf("Option '--interval' must be a positive duration")
+	}
+	if args.export && args.watchNew {
+		return fmt.Errorf("Options '--export' and '--watch-new' can't be used together")
+	}
+	if args.withQuota && !args.export {
+		return fmt.Errorf("Option '--with-quota' can only be used with '--export'")
+	}
+	if args.format != formatCSV && args.format != formatJSON {
+		return fmt.Errorf(
+			"Invalid format '%s', should be '%s' or '%s'",
+			args.format, formatCSV, formatJSON,
+		)
+	}
+	if args.parallel < 1 {
+		return fmt.Errorf("Option '--parallel' must be at least 1")
+	}
+	start := time.Now().UTC()
+
 	// Load the configuration:
 	cfg, err := config.Load()
 	if err != nil {
@@ -74,10 +190,48 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 	defer connection.Close()
 
-	// Create the output printer:
+	// Personal data is replaced with hashes if requested, so that the output can be shared:
+	var redactor *redact.Redactor
+	if args.redactPII {
+		redactor = redact.NewFromEnv()
+	}
+
+	// Create the request. Note that this request can be created outside of the loop and used
+	// for all iterations just changing the values of the `size` and `page` parameters.
+	request := connection.AccountsMgmt().V1().Organizations().List()
+	arguments.ApplyParameterFlag(request, args.parameter)
+	arguments.ApplyHeaderFlag(request, args.header)
+	if args.search != "" {
+		request.Search(args.search)
+	}
+
+	// The report is generated only when all the organizations have been retrieved, as the
+	// columns depend on the quota of all of them:
+	if args.export {
+		var orgs []*amv1.Organization
+		size := 100
+		for index := 1; ; index++ {
+			response, err := request.Size(size).Page(index).Send()
+			if err != nil {
+				return fmt.Errorf("can't retrieve organizations: %w", err)
+			}
+			orgs = append(orgs, response.Items().Slice()...)
+			if response.Size() < size {
+				break
+			}
+		}
+		return export(connection, orgs, redactor, os.Stdout)
+	}
+
+	// Create the output printer. When watching the rows are written as the organizations
+	// are found, so the pager isn't used:
+	pager := cfg.Pager
+	if args.watchNew {
+		pager = ""
+	}
 	printer, err := output.NewPrinter().
 		Writer(os.Stdout).
-		Pager(cfg.Pager).
+		Pager(pager).
 		Build(ctx)
 	if err != nil {
 		return err
@@ -88,6 +242,7 @@ func run(cmd *cobra.Command, argv []string) error {
 	table, err := printer.NewTable().
 		Name("orgs").
 		Columns(args.columns).
+		Learning(!args.watchNew).
 		Build(ctx)
 	if err != nil {
 		return err
@@ -100,11 +255,9 @@ func run(cmd *cobra.Command, argv []string) error {
 		return err
 	}
 
-	// Create the request. Note that this request can be created outside of the loop and used
-	// for all iterations just changing the values of the `size` and `page` parameters.
-	request := connection.AccountsMgmt().V1().Organizations().List()
-	arguments.ApplyParameterFlag(request, args.parameter)
-	arguments.ApplyHeaderFlag(request, args.header)
+	if args.watchNew {
+		return watch(connection, request, table, redactor, start)
+	}
 
 	// Send the request till we receive a page with less items than requested:
 	size := 100
@@ -120,7 +273,7 @@ func run(cmd *cobra.Command, argv []string) error {
 
 		// Display the items of the fetched page:
 		response.Items().Each(func(org *amv1.Organization) bool {
-			err = table.WriteObject(org)
+			err = writeOrg(table, redactor, org)
 			return err == nil
 		})
 		if err != nil {
@@ -137,3 +290,147 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	return nil
 }
+
+// watch reports the organizations created after the given time, printing the ones that haven't
+// been seen before and running the hook for them. Servers that support streaming send the new
+// organizations as events; otherwise, or when the server closes the stream, the list is polled.
+// It only returns when the organizations can't be retrieved. Note that the hook always receives
+// the organization without redacting it.
+func watch(connection *sdk.Connection, request *amv1.OrganizationsListRequest,
+	table *output.Table, redactor *redact.Redactor, since time.Time) error {
+	seen := map[string]bool{}
+	query := url.Values{}
+	query.Set("watch", "true")
+	if args.search != "" {
+		query.Set("search", args.search)
+	}
+	err := ocm.Stream(
+		context.Background(), connection, "/api/accounts_mgmt/v1/organizations", query,
+		func(event *ocm.Event) error {
+			if event.Type != "" && event.Type != "created" {
+				return nil
+			}
+			org, err := amv1.UnmarshalOrganization(event.Data)
+			if err != nil {
+				return fmt.Errorf("can't parse organization event: %w", err)
+			}
+			if seen[org.ID()] {
+				return nil
+			}
+			seen[org.ID()] = true
+			if org.CreatedAt().After(since) {
+				since = org.CreatedAt()
+			}
+			err = writeOrg(table, redactor, org)
+			if err != nil {
+				return err
+			}
+			reportHook(org)
+			return nil
+		},
+	)
+	if err != nil && err != ocm.ErrStreamingUnsupported {
+		return err
+	}
+
+	request.Parameter("order", "created_at asc")
+	for {
+		// Retrieve all the organizations created after the most recent one that we have
+		// seen:
+		query := fmt.Sprintf("created_at > '%s'", since.Format(time.RFC3339))
+		if args.search != "" {
+			query = fmt.Sprintf("(%s) and (%s)", query, args.search)
+		}
+		request.Search(query)
+		var fresh []*amv1.Organization
+		size := 100
+		for index := 1; ; index++ {
+			response, err := request.Size(size).Page(index).Send()
+			if err != nil {
+				return fmt.Errorf("can't retrieve organizations: %w", err)
+			}
+			response.Items().Each(func(org *amv1.Organization) bool {
+				if seen[org.ID()] {
+					return true
+				}
+				seen[org.ID()] = true
+				fresh = append(fresh, org)
+				if org.CreatedAt().After(since) {
+					since = org.CreatedAt()
+				}
+				return true
+			})
+			if response.Size() < size {
+				break
+			}
+		}
+
+		// Print the new organizations and run the hooks:
+		for _, org := range fresh {
+			err := writeOrg(table, redactor, org)
+			if err != nil {
+				return err
+			}
+		}
+		for _, org := range fresh {
+			reportHook(org)
+		}
+		time.Sleep(args.interval)
+	}
+}
+
+// reportHook runs the hook for the given organization, reporting the failure if it fails.
+func reportHook(org *amv1.Organization) {
+	err := runHook(org)
+	if err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Hook failed for organization '%s': %v\n",
+			org.ID(), err,
+		)
+	}
+}
+
+// writeOrg writes the organization to the table, replacing its name with a hash first if the
+// redactor isn't nil.
+func writeOrg(table *output.Table, redactor *redact.Redactor, org *amv1.Organization) error {
+	if redactor != nil {
+		var err error
+		org, err = amv1.NewOrganization().
+			Copy(org).
+			Name(redactor.Name(org.Name())).
+			Build()
+		if err != nil {
+			return err
+		}
+	}
+	return table.WriteObject(org)
+}
+
+// runHook runs the hook command, if any, for the given organization.
+func runHook(org *amv1.Organization) error {
+	if args.hook == "" {
+		return nil
+	}
+	buffer := &bytes.Buffer{}
+	err := amv1.MarshalOrganization(org, buffer)
+	if err != nil {
+		return err
+	}
+	var hook *exec.Cmd
+	if runtime.GOOS == "windows" {
+		hook = exec.Command("cmd", "/C", args.hook)
+	} else {
+		hook = exec.Command("sh", "-c", args.hook)
+	}
+	hook.Env = append(
+		os.Environ(),
+		"OCM_ORG_ID="+org.ID(),
+		"OCM_ORG_NAME="+org.Name(),
+		"OCM_ORG_EXTERNAL_ID="+org.ExternalID(),
+	)
+	hook.Stdin = buffer
+	hook.Stdout = os.Stderr
+	hook.Stderr = os.Stderr
+	return hook.Run()
+}
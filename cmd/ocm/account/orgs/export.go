@@ -0,0 +1,198 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the code that generates the report of the '--export' option.
+
+package orgs
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/parallel"
+	"github.com/openshift-online/ocm-cli/pkg/redact"
+)
+
+// Formats of the report:
+const (
+	formatCSV  = "csv"
+	formatJSON = "json"
+)
+
+// orgReport is the row of the report for one organization. Note that the field names are part of
+// the JSON output, so don't change them without considering the consumers of that output.
+type orgReport struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	ExternalID string         `json:"external_id,omitempty"`
+	Accounts   int            `json:"accounts"`
+	Quota      []*quotaReport `json:"quota,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// quotaReport is the summary of the cost of one quota of an organization.
+type quotaReport struct {
+	QuotaID  string `json:"quota_id"`
+	Allowed  int    `json:"allowed"`
+	Consumed int    `json:"consumed"`
+}
+
+// export retrieves the details of the given organizations concurrently and writes the report.
+// Organizations whose details can't be retrieved are included in the report with the error, and
+// then the function fails.
+func export(connection *sdk.Connection, orgs []*amv1.Organization, redactor *redact.Redactor,
+	out io.Writer) error {
+	reports := make([]*orgReport, len(orgs))
+	parallel.Each(len(orgs), args.parallel, func(i int) {
+		reports[i] = exportOrg(connection, orgs[i])
+		if redactor != nil {
+			reports[i].Name = redactor.Name(reports[i].Name)
+		}
+	})
+
+	var err error
+	switch args.format {
+	case formatJSON:
+		var data []byte
+		data, err = json.Marshal(reports)
+		if err == nil {
+			err = dump.Pretty(out, data)
+		}
+	default:
+		err = writeCSV(out, reports)
+	}
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, report := range reports {
+		if report.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("Failed to retrieve the details of %d organizations", failed)
+	}
+	return nil
+}
+
+// exportOrg retrieves the number of accounts and, if requested, the quota cost of the given
+// organization.
+func exportOrg(connection *sdk.Connection, org *amv1.Organization) *orgReport {
+	report := &orgReport{
+		ID:         org.ID(),
+		Name:       org.Name(),
+		ExternalID: org.ExternalID(),
+	}
+	collection := connection.AccountsMgmt().V1()
+	accountsResponse, err := collection.Accounts().List().
+		Search(fmt.Sprintf("organization.id = '%s'", org.ID())).
+		Size(1).
+		Send()
+	if err != nil {
+		report.Error = fmt.Sprintf("Can't retrieve accounts: %v", err)
+		return report
+	}
+	report.Accounts = accountsResponse.Total()
+	if !args.withQuota {
+		return report
+	}
+	size := 100
+	for index := 1; ; index++ {
+		quotaResponse, err := collection.Organizations().Organization(org.ID()).QuotaCost().
+			List().
+			Size(size).
+			Page(index).
+			Send()
+		if err != nil {
+			report.Error = fmt.Sprintf("Can't retrieve quota cost: %v", err)
+			return report
+		}
+		quotaResponse.Items().Each(func(cost *amv1.QuotaCost) bool {
+			report.Quota = append(report.Quota, &quotaReport{
+				QuotaID:  cost.QuotaID(),
+				Allowed:  cost.Allowed(),
+				Consumed: cost.Consumed(),
+			})
+			return true
+		})
+		if quotaResponse.Size() < size {
+			break
+		}
+	}
+	sort.Slice(report.Quota, func(i, j int) bool {
+		return report.Quota[i].QuotaID < report.Quota[j].QuotaID
+	})
+	return report
+}
+
+// writeCSV writes the report in CSV format. Each quota that appears in any organization gets two
+// columns, with the allowed and consumed values.
+func writeCSV(out io.Writer, reports []*orgReport) error {
+	quotaIDs := []string{}
+	seen := map[string]bool{}
+	for _, report := range reports {
+		for _, quota := range report.Quota {
+			if !seen[quota.QuotaID] {
+				seen[quota.QuotaID] = true
+				quotaIDs = append(quotaIDs, quota.QuotaID)
+			}
+		}
+	}
+	sort.Strings(quotaIDs)
+
+	writer := csv.NewWriter(out)
+	header := []string{"id", "name", "external_id", "accounts"}
+	for _, quotaID := range quotaIDs {
+		header = append(header, quotaID+".allowed", quotaID+".consumed")
+	}
+	header = append(header, "error")
+	err := writer.Write(header)
+	if err != nil {
+		return err
+	}
+	for _, report := range reports {
+		row := []string{report.ID, report.Name, report.ExternalID, strconv.Itoa(report.Accounts)}
+		quotas := map[string]*quotaReport{}
+		for _, quota := range report.Quota {
+			quotas[quota.QuotaID] = quota
+		}
+		for _, quotaID := range quotaIDs {
+			quota, ok := quotas[quotaID]
+			if ok {
+				row = append(row, strconv.Itoa(quota.Allowed), strconv.Itoa(quota.Consumed))
+			} else {
+				row = append(row, "", "")
+			}
+		}
+		row = append(row, report.Error)
+		err = writer.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
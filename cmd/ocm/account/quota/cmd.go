@@ -23,20 +23,27 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/pkg/account"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
 
 var args struct {
-	json bool
-	org  string
+	json          bool
+	org           string
+	failWhenAbove string
 }
 
 var Cmd = &cobra.Command{
-	Use:        "quota",
-	Short:      "Retrieve cluster quota information.",
-	Long:       "Retrieve cluster quota information of a specific organization.",
+	Use:   "quota",
+	Short: "Retrieve cluster quota information.",
+	Long:  "Retrieve cluster quota information of a specific organization.",
+	Example: `  # Fail if any quota of the organization is more than 80% consumed
+  ocm account quota --fail-when-above 80%`,
 	Args:       cobra.NoArgs,
 	Deprecated: "please use `ocm list quota` command",
 	RunE:       run,
@@ -57,9 +64,26 @@ func init() {
 		"",
 		"Specify which organization to query information from. Default to local users organization.",
 	)
+	flags.StringVar(
+		&args.failWhenAbove,
+		"fail-when-above",
+		"",
+		"Fail with exit code 3 if the usage of any quota is above this percentage, for "+
+			"example '80%'. The error message contains a summary of the quotas above it.",
+	)
+	Cmd.RegisterFlagCompletionFunc("org", completion.Organizations)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	var threshold float64
+	if args.failWhenAbove != "" {
+		var err error
+		threshold, err = account.ParseQuotaThreshold(args.failWhenAbove)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Load the configuration file:
 	cfg, err := config.Load()
 	if err != nil {
@@ -69,17 +93,10 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Not logged in, run the 'login' command")
 	}
 
-	// Check that the configuration has credentials or tokens that haven't have expired:
-	armed, reason, err := cfg.Armed()
-	if err != nil {
-		return err
-	}
-	if !armed {
-		return fmt.Errorf("Not logged in, %s, run the 'login' command", reason)
-	}
-
-	// Create the connection, and remember to close it:
-	connection, err := cfg.Connection()
+	// Create the connection, and remember to close it. This checks that the configuration has
+	// credentials or tokens that haven't expired, and gives the user the chance to provide new
+	// ones if needed:
+	connection, err := ocm.NewConnection().Config(cfg).Build()
 	if err != nil {
 		return fmt.Errorf("Can't create connection: %v", err)
 	}
@@ -130,7 +147,7 @@ func run(cmd *cobra.Command, argv []string) error {
 			return true
 		})
 
-		return nil
+		return checkThreshold(connection, orgID, threshold)
 
 	}
 
@@ -147,5 +164,18 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Failed to display quota JSON: %v", err)
 	}
 
-	return nil
+	return checkThreshold(connection, orgID, threshold)
+}
+
+// checkThreshold fails if the usage of any quota is above the threshold given with the
+// '--fail-when-above' option.
+func checkThreshold(connection *sdk.Connection, orgID string, threshold float64) error {
+	if args.failWhenAbove == "" {
+		return nil
+	}
+	quotas, err := account.ListQuotaCosts(connection, orgID)
+	if err != nil {
+		return err
+	}
+	return account.CheckQuotaThreshold(orgID, quotas, threshold)
 }
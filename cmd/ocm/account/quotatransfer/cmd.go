@@ -0,0 +1,358 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quotatransfer
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+)
+
+var args struct {
+	from        string
+	to          string
+	sku         string
+	amount      int
+	reason      string
+	output      string
+	interactive bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "quota-transfer",
+	Short: "Prepare a request to transfer quota between organizations",
+	Long: "Prepare a request to transfer some units of a SKU from one organization to another. " +
+		"The command checks that both organizations exist, that the source organization owns " +
+		"the SKU and that the requested amount isn't larger than the part of the quota that " +
+		"isn't consumed. The OCM API doesn't yet provide a way to apply the transfer, so the " +
+		"result is a request document that should be attached to the support case.",
+	Example: `  # Prepare the request interactively
+  ocm account quota-transfer --interactive
+
+  # Request the transfer of 2 units of SKU 'MW00530' to organization '1a2b3c'
+  ocm account quota-transfer --to 1a2b3c --sku MW00530 --amount 2 \
+    --reason "Move workloads to the new business unit" --output transfer.yaml`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.from,
+		"from",
+		"",
+		"Identifier of the organization that gives the quota. Defaults to the organization "+
+			"of the current user.",
+	)
+	flags.StringVar(
+		&args.to,
+		"to",
+		"",
+		"Identifier of the organization that receives the quota.",
+	)
+	flags.StringVar(
+		&args.sku,
+		"sku",
+		"",
+		"SKU to transfer.",
+	)
+	flags.IntVar(
+		&args.amount,
+		"amount",
+		0,
+		"Number of units of the SKU to transfer.",
+	)
+	flags.StringVar(
+		&args.reason,
+		"reason",
+		"",
+		"Business reason for the transfer.",
+	)
+	flags.StringVar(
+		&args.output,
+		"output",
+		"",
+		"File where the request will be written. Defaults to the standard output.",
+	)
+	arguments.AddInteractiveFlag(flags, &args.interactive)
+	arguments.SetQuestion(flags, "to", "Destination organization ID:")
+	arguments.SetQuestion(flags, "sku", "SKU:")
+	arguments.SetQuestion(flags, "amount", "Amount:")
+	arguments.SetQuestion(flags, "reason", "Reason:")
+	Cmd.RegisterFlagCompletionFunc("from", completion.Organizations)
+	Cmd.RegisterFlagCompletionFunc("to", completion.Organizations)
+}
+
+// idRE is the regular expression used to check organization identifiers and SKUs before using
+// them in search queries.
+var idRE = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// request is the document generated by the command. Note that the field names are part of the
+// output, so don't change them without considering the consumers of that output.
+type request struct {
+	Kind      string        `yaml:"kind"`
+	Created   string        `yaml:"created"`
+	Requester string        `yaml:"requester"`
+	From      *organization `yaml:"from"`
+	To        *organization `yaml:"to"`
+	SKU       string        `yaml:"sku"`
+	Amount    int           `yaml:"amount"`
+	Owned     int           `yaml:"owned"`
+	Available int           `yaml:"available"`
+	Reason    string        `yaml:"reason"`
+}
+
+// organization is the description of an organization inside the request.
+type organization struct {
+	ID         string `yaml:"id"`
+	Name       string `yaml:"name"`
+	ExternalID string `yaml:"external_id,omitempty"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	fs := cmd.Flags()
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+	collection := connection.AccountsMgmt().V1()
+
+	// The current account is the requester, and its organization is the default source:
+	accountResponse, err := collection.CurrentAccount().Get().Send()
+	if err != nil {
+		return fmt.Errorf("Can't retrieve current user information: %v", err)
+	}
+	account := accountResponse.Body()
+	if args.from == "" {
+		args.from = account.Organization().ID()
+	}
+
+	err = arguments.PromptString(fs, "to")
+	if err != nil {
+		return err
+	}
+	if args.to == "" {
+		return fmt.Errorf("Option '--to' is required")
+	}
+	for _, name := range []string{"from", "to"} {
+		value := fs.Lookup(name).Value.String()
+		if !idRE.MatchString(value) {
+			return fmt.Errorf("Organization identifier '%s' of option '--%s' isn't valid", value, name)
+		}
+	}
+	if args.from == args.to {
+		return fmt.Errorf("Source and destination organizations must be different")
+	}
+	from, err := getOrganization(connection, args.from)
+	if err != nil {
+		return err
+	}
+	to, err := getOrganization(connection, args.to)
+	if err != nil {
+		return err
+	}
+
+	// Find the SKUs owned by the source organization:
+	owned, err := getOwnedSKUs(connection, args.from)
+	if err != nil {
+		return err
+	}
+	options := []arguments.Option{}
+	for sku, count := range owned {
+		options = append(options, arguments.Option{
+			Value:       sku,
+			Description: strconv.Itoa(count),
+		})
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].Value < options[j].Value
+	})
+	err = arguments.PromptOneOf(fs, "sku", options)
+	if err != nil {
+		return err
+	}
+	if args.sku == "" {
+		return fmt.Errorf("Option '--sku' is required")
+	}
+	if !idRE.MatchString(args.sku) {
+		return fmt.Errorf("SKU '%s' isn't valid", args.sku)
+	}
+	if owned[args.sku] == 0 {
+		return fmt.Errorf("Organization '%s' doesn't own SKU '%s'", args.from, args.sku)
+	}
+	available, err := getAvailableSKUs(connection, args.from, args.sku, owned[args.sku])
+	if err != nil {
+		return err
+	}
+
+	err = arguments.PromptInt(fs, "amount", func() error {
+		return validateAmount(available)
+	})
+	if err != nil {
+		return err
+	}
+	err = validateAmount(available)
+	if err != nil {
+		return err
+	}
+
+	err = arguments.PromptString(fs, "reason")
+	if err != nil {
+		return err
+	}
+	if args.reason == "" {
+		return fmt.Errorf("Option '--reason' is required")
+	}
+
+	document := &request{
+		Kind:      "QuotaTransferRequest",
+		Created:   time.Now().UTC().Format(time.RFC3339),
+		Requester: account.Username(),
+		From:      from,
+		To:        to,
+		SKU:       args.sku,
+		Amount:    args.amount,
+		Owned:     owned[args.sku],
+		Available: available,
+		Reason:    args.reason,
+	}
+	data, err := yaml.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("Can't generate quota transfer request: %v", err)
+	}
+	if args.output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	err = os.WriteFile(args.output, data, 0600)
+	if err != nil {
+		return fmt.Errorf("Can't write quota transfer request: %v", err)
+	}
+	fmt.Fprintf(
+		os.Stdout,
+		"Request to transfer %d units of SKU '%s' from organization '%s' to '%s' written "+
+			"to '%s', attach it to the support case\n",
+		args.amount, args.sku, args.from, args.to, args.output,
+	)
+	return nil
+}
+
+// validateAmount checks that the amount requested is positive and not larger than the available
+// amount.
+func validateAmount(available int) error {
+	if args.amount < 1 {
+		return fmt.Errorf("Option '--amount' must be at least 1")
+	}
+	if args.amount > available {
+		return fmt.Errorf(
+			"Organization '%s' can transfer at most %d units of SKU '%s'",
+			args.from, available, args.sku,
+		)
+	}
+	return nil
+}
+
+// getOrganization retrieves the organization with the given identifier.
+func getOrganization(connection *sdk.Connection, id string) (result *organization, err error) {
+	response, err := connection.AccountsMgmt().V1().Organizations().Organization(id).Get().Send()
+	if err != nil {
+		err = fmt.Errorf("Can't retrieve organization '%s': %v", id, err)
+		return
+	}
+	org := response.Body()
+	result = &organization{
+		ID:         org.ID(),
+		Name:       org.Name(),
+		ExternalID: org.ExternalID(),
+	}
+	return
+}
+
+// getOwnedSKUs returns the number of units of each SKU owned by the given organization.
+func getOwnedSKUs(connection *sdk.Connection, orgID string) (result map[string]int, err error) {
+	response, err := connection.AccountsMgmt().V1().Organizations().Organization(orgID).
+		ResourceQuota().
+		List().
+		Size(-1).
+		Send()
+	if err != nil {
+		err = fmt.Errorf("Can't retrieve resource quota of organization '%s': %v", orgID, err)
+		return
+	}
+	result = map[string]int{}
+	response.Items().Each(func(quota *amv1.ResourceQuota) bool {
+		result[quota.SKU()] += quota.SkuCount()
+		return true
+	})
+	return
+}
+
+// getAvailableSKUs calculates how many of the owned units of the given SKU aren't consumed, using
+// the SKU rules to translate the consumed part of each quota into units of the SKU.
+func getAvailableSKUs(connection *sdk.Connection, orgID, sku string, owned int) (result int, err error) {
+	collection := connection.AccountsMgmt().V1()
+	rulesResponse, err := collection.SkuRules().List().
+		Search(fmt.Sprintf("sku = '%s'", sku)).
+		Send()
+	if err != nil {
+		err = fmt.Errorf("Can't retrieve rules of SKU '%s': %v", sku, err)
+		return
+	}
+	costResponse, err := collection.Organizations().Organization(orgID).QuotaCost().List().
+		Send()
+	if err != nil {
+		err = fmt.Errorf("Can't retrieve quota cost of organization '%s': %v", orgID, err)
+		return
+	}
+	result = owned
+	rulesResponse.Items().Each(func(rule *amv1.SkuRule) bool {
+		if rule.Allowed() <= 0 {
+			return true
+		}
+		costResponse.Items().Each(func(cost *amv1.QuotaCost) bool {
+			if cost.QuotaID() != rule.QuotaId() {
+				return true
+			}
+			free := (cost.Allowed() - cost.Consumed()) / rule.Allowed()
+			if free < 0 {
+				free = 0
+			}
+			if free < result {
+				result = free
+			}
+			return false
+		})
+		return true
+	})
+	return
+}
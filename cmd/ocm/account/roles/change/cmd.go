@@ -0,0 +1,234 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package change
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	acc_util "github.com/openshift-online/ocm-cli/pkg/account"
+	"github.com/openshift-online/ocm-cli/pkg/bulk"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+// Verbs of the commands:
+const (
+	Grant  = "grant"
+	Revoke = "revoke"
+)
+
+// titles contains the capitalized verbs used in the help of the commands.
+var titles = map[string]string{
+	Grant:  "Grant",
+	Revoke: "Revoke",
+}
+
+// prepositions contains the preposition used with each verb in the help of the commands.
+var prepositions = map[string]string{
+	Grant:  "to",
+	Revoke: "from",
+}
+
+// pastTenses contains the past tense of each verb, used in the summary.
+var pastTenses = map[string]string{
+	Grant:  "granted",
+	Revoke: "revoked",
+}
+
+type options struct {
+	users   []string
+	file    string
+	org     string
+	dryRun  bool
+	results string
+}
+
+// NewCmd creates the command that grants or revokes organization roles, depending on the verb.
+func NewCmd(verb string) *cobra.Command {
+	title := titles[verb]
+	preposition := prepositions[verb]
+	opts := &options{}
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("%s [ROLE] [flags]", verb),
+		Short: fmt.Sprintf("%s an organization role %s users", title, preposition),
+		Long: fmt.Sprintf(
+			"%s an organization role %s the users given with the '--user' option, or %s "+
+				"the roles listed in a CSV file %s the users listed in the same file. Each "+
+				"line of the file contains a user name and, optionally, a role. The role "+
+				"can be omitted when it is given as argument. A first line containing "+
+				"the 'username' and 'role' column names is ignored.\n\n"+
+				"The command exits with code 0 when all the changes succeeded, with "+
+				"code 2 when only some of them failed and with code 1 when all of them "+
+				"failed. Use the --results option to write the result for each user to "+
+				"a JSON file.",
+			title, preposition, strings.ToLower(title), preposition,
+		),
+		Example: fmt.Sprintf(`  # %s the 'ClusterEditor' role %s two users
+  ocm account roles %s ClusterEditor --user alice --user bob
+
+  # Show what would be changed for the users and roles listed in a file
+  ocm account roles %s --from-file users.csv --dry-run`,
+			title, preposition, verb, verb,
+		),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completion.FirstArg(completion.Roles),
+		RunE: func(cmd *cobra.Command, argv []string) error {
+			return run(verb, argv, opts)
+		},
+	}
+	fs := cmd.Flags()
+	fs.StringSliceVar(
+		&opts.users,
+		"user",
+		nil,
+		"User name. Can be repeated multiple times to specify multiple users.",
+	)
+	fs.StringVar(
+		&opts.file,
+		"from-file",
+		"",
+		"CSV file containing the user names and, optionally, the roles. Use '-' to read "+
+			"the standard input.",
+	)
+	fs.StringVar(
+		&opts.org,
+		"org",
+		"",
+		"Organization identifier. If given only the users of this organization are "+
+			"considered.",
+	)
+	dryrun.BoolVar(
+		fs,
+		&opts.dryRun,
+		"Show the changes that would be applied without applying them.",
+	)
+	fs.StringVar(
+		&opts.results,
+		"results",
+		"",
+		"Write the result for each user to this file, in JSON format.",
+	)
+	cmd.RegisterFlagCompletionFunc("org", completion.Organizations)
+	readonly.Mark(cmd)
+	return cmd
+}
+
+func run(verb string, argv []string, opts *options) error {
+	// Check the options and build the list of changes:
+	role := ""
+	if len(argv) > 0 {
+		role = argv[0]
+	}
+	if len(opts.users) > 0 && opts.file != "" {
+		return fmt.Errorf("Options '--user' and '--from-file' are mutually exclusive")
+	}
+	var changes []*acc_util.RoleChange
+	switch {
+	case opts.file != "":
+		var err error
+		changes, err = readFile(opts.file, role)
+		if err != nil {
+			return err
+		}
+	case len(opts.users) > 0:
+		if role == "" {
+			return fmt.Errorf("Role is mandatory when using the '--user' option")
+		}
+		for _, user := range opts.users {
+			changes = append(changes, &acc_util.RoleChange{
+				Username: user,
+				Role:     role,
+			})
+		}
+	default:
+		return fmt.Errorf("One of options '--user' or '--from-file' is mandatory")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Apply the changes:
+	changer := acc_util.NewRoleChanger(
+		connection,
+		acc_util.NewThrottle(os.Stderr),
+		opts.org,
+		opts.dryRun,
+		os.Stdout,
+		os.Stderr,
+	)
+	results := bulk.NewResults()
+	if verb == Grant {
+		err = changer.Grant(changes, results)
+	} else {
+		err = changer.Revoke(changes, results)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Print the summary:
+	prefix := ""
+	if opts.dryRun {
+		prefix = "to be "
+	}
+	fmt.Printf(
+		"\nUsers: %d, %s%s: %d, skipped: %d, failed: %d\n",
+		len(changes), prefix, pastTenses[verb],
+		results.Count(bulk.StatusSucceeded),
+		results.Count(bulk.StatusSkipped),
+		results.Count(bulk.StatusFailed),
+	)
+	if opts.results != "" {
+		err = results.Write(opts.results)
+		if err != nil {
+			return err
+		}
+	}
+	return results.Err(fmt.Sprintf("Failed to %s roles", verb))
+}
+
+func readFile(file, role string) (result []*acc_util.RoleChange, err error) {
+	reader := os.Stdin
+	if file != "-" {
+		// #nosec G304
+		reader, err = os.Open(file)
+		if err != nil {
+			err = fmt.Errorf("Can't open file '%s': %v", file, err)
+			return
+		}
+		defer reader.Close()
+	}
+	result, err = acc_util.ReadRoleChanges(reader, role)
+	if err != nil {
+		err = fmt.Errorf("Can't read file '%s': %v", file, err)
+		return
+	}
+	if len(result) == 0 {
+		err = fmt.Errorf("File '%s' doesn't contain any user", file)
+	}
+	return
+}
@@ -22,8 +22,11 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/roles/change"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
 
@@ -41,7 +44,8 @@ var Cmd = &cobra.Command{
 		}
 		return nil
 	},
-	RunE: run,
+	ValidArgsFunction: completion.FirstArg(completion.Roles),
+	RunE:              run,
 }
 
 func init() {
@@ -53,6 +57,8 @@ func init() {
 		false,
 		"Enable debug mode.",
 	)
+	Cmd.AddCommand(change.NewCmd(change.Grant))
+	Cmd.AddCommand(change.NewCmd(change.Revoke))
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -66,17 +72,10 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Not logged in, run the 'login' command")
 	}
 
-	// Check that the configuration has credentials or tokens that haven't have expired:
-	armed, reason, err := cfg.Armed()
-	if err != nil {
-		return err
-	}
-	if !armed {
-		return fmt.Errorf("Not logged in, %s, run the 'login' command", reason)
-	}
-
-	// Create the connection, and remember to close it:
-	connection, err := cfg.Connection()
+	// Create the connection, and remember to close it. This checks that the configuration has
+	// credentials or tokens that haven't expired, and gives the user the chance to provide new
+	// ones if needed:
+	connection, err := ocm.NewConnection().Config(cfg).Build()
 	if err != nil {
 		return fmt.Errorf("Can't create connection: %v", err)
 	}
@@ -0,0 +1,44 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sa
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/sa/create"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/sa/delete"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/sa/list"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/sa/rotate"
+)
+
+var Cmd = &cobra.Command{
+	Use:     "sa COMMAND",
+	Aliases: []string{"service-account", "service-accounts"},
+	Short:   "Manage service accounts",
+	Long: "Create, list, rotate the secrets of and delete the service accounts used by " +
+		"automation to access the OCM API. The service accounts are managed by the SSO " +
+		"server, so this only works when the token URL is the one of a realm of an SSO " +
+		"server that supports them.",
+	Args: cobra.MinimumNArgs(1),
+}
+
+func init() {
+	Cmd.AddCommand(create.Cmd)
+	Cmd.AddCommand(delete.Cmd)
+	Cmd.AddCommand(list.Cmd)
+	Cmd.AddCommand(rotate.Cmd)
+}
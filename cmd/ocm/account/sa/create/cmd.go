@@ -0,0 +1,71 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+	"github.com/openshift-online/ocm-cli/pkg/serviceaccount"
+)
+
+var args struct {
+	description string
+	secretFile  string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Create a service account",
+	Long: "Create a service account. The secret of the new service account is printed only " +
+		"once, or written to the file given with the '--secret-file' option.",
+	Example: `  # Create a service account for the CI pipeline and save the secret to a file
+  ocm account sa create my-pipeline --description "CI pipeline" --secret-file secret.txt`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.description,
+		"description",
+		"",
+		"Description of the service account.",
+	)
+	flags.StringVar(
+		&args.secretFile,
+		"secret-file",
+		"",
+		"Write the secret to this file instead of to the standard output.",
+	)
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	connection, path, err := serviceaccount.NewConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	account, err := serviceaccount.Create(connection, path, argv[0], args.description)
+	if err != nil {
+		return err
+	}
+	return serviceaccount.PrintCredentials(account, args.secretFile)
+}
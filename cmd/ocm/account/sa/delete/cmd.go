@@ -0,0 +1,57 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+	"github.com/openshift-online/ocm-cli/pkg/serviceaccount"
+)
+
+var Cmd = &cobra.Command{
+	Use:     "delete ID...",
+	Aliases: []string{"rm"},
+	Short:   "Delete service accounts",
+	Long: "Delete service accounts. Applications using the credentials of deleted service " +
+		"accounts will no longer be able to access the OCM API.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: run,
+}
+
+func init() {
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	connection, path, err := serviceaccount.NewConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	for _, id := range argv {
+		err = serviceaccount.Delete(connection, path, id)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Deleted service account '%s'\n", id)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package list
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/serviceaccount"
+)
+
+var args struct {
+	json bool
+}
+
+var Cmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List service accounts",
+	Long:    "List the service accounts visible to the current user. Secrets are never shown.",
+	Args:    cobra.NoArgs,
+	RunE:    run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the service accounts in JSON format.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	connection, path, err := serviceaccount.NewConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	accounts, err := serviceaccount.List(connection, path)
+	if err != nil {
+		return err
+	}
+	if args.json {
+		data, err := json.Marshal(accounts)
+		if err != nil {
+			return fmt.Errorf("Can't marshal service accounts: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "ID\tCLIENT ID\tNAME\tCREATED BY\tCREATED\n")
+	for _, account := range accounts {
+		created := ""
+		if !account.Created().IsZero() {
+			created = account.Created().UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\n",
+			account.ID, account.ClientID, account.Name, account.CreatedBy, created,
+		)
+	}
+	return writer.Flush()
+}
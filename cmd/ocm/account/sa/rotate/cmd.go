@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotate
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+	"github.com/openshift-online/ocm-cli/pkg/serviceaccount"
+)
+
+var args struct {
+	secretFile string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "rotate-secret ID",
+	Short: "Replace the secret of a service account",
+	Long: "Replace the secret of a service account with a new one. The old secret stops " +
+		"working immediately. The new secret is printed only once, or written to the file " +
+		"given with the '--secret-file' option.",
+	Example: `  # Rotate the secret of a service account and save it to a file
+  ocm account sa rotate-secret 7f1c5e4a-0000-0000-0000-000000000000 --secret-file secret.txt`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.secretFile,
+		"secret-file",
+		"",
+		"Write the secret to this file instead of to the standard output.",
+	)
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	connection, path, err := serviceaccount.NewConnection()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	account, err := serviceaccount.ResetSecret(connection, path, argv[0])
+	if err != nil {
+		return err
+	}
+	return serviceaccount.PrintCredentials(account, args.secretFile)
+}
@@ -17,21 +17,45 @@ limitations under the License.
 package users
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/users/invitations"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/users/invite"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/users/offboard"
 	acc_util "github.com/openshift-online/ocm-cli/pkg/account"
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
 	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/redact"
+	"github.com/openshift-online/ocm-cli/pkg/resume"
+	"github.com/openshift-online/ocm-cli/pkg/search"
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
 
 var args struct {
-	debug bool
-	org   string
-	roles []string
+	debug        bool
+	org          string
+	roles        []string
+	minRoleLevel string
+	search       string
+	resume       bool
+	output       string
+	columns      string
+	emailsOnly   bool
+	separator    string
+	redactPII    bool
+	progress     bool
 }
 
 // Cmd configures a new Cobra Command
@@ -39,13 +63,25 @@ var Cmd = &cobra.Command{
 	Use:   "users",
 	Short: "Retrieve users and their roles",
 	Long:  "Retrieve information of all users/roles in the same organization",
-	Args:  cobra.NoArgs,
-	RunE:  run,
+	Example: `  # List the users of the organization of the current user
+  ocm account users
+
+  # Get the email addresses of all the organization administrators, separated by commas
+  ocm account users --roles=OrganizationAdmin --emails-only --separator=,`,
+	Args: cobra.NoArgs,
+	RunE: run,
 }
 
-type userModel struct {
-	userName string
-	userID   string
+// columns are the names of the columns that can be selected with the '--columns' option.
+var columns = []string{
+	"username",
+	"id",
+	"email",
+	"first_name",
+	"last_name",
+	"org_id",
+	"org_name",
+	"roles",
 }
 
 func init() {
@@ -70,9 +106,110 @@ func init() {
 		`Role identifiers. Returns users with one or more of the specified roles.
 		Multiple roles can be specified like: --roles="role1,role2,role2".`,
 	)
+	flags.StringVar(
+		&args.minRoleLevel,
+		"min-role-level",
+		"",
+		fmt.Sprintf(
+			"Returns only users with this role or a more privileged one. The roles, from "+
+				"less to more privileged, are: %s.",
+			strings.Join(acc_util.HierarchyRoles(), ", "),
+		),
+	)
+	flags.StringVar(
+		&args.search,
+		"search",
+		"",
+		"Search expression used to filter the users, for example "+
+			"\"email like '%@example.com'\" or \"created_at > '2024-01-01'\".",
+	)
+	flags.BoolVar(
+		&args.resume,
+		"resume",
+		false,
+		"Continue an interrupted run with the same options from the page where it "+
+			"stopped, instead of starting again from the first page.",
+	)
+	arguments.AddOutputFlag(flags, &args.output)
+	flags.StringVar(
+		&args.columns,
+		"columns",
+		"",
+		fmt.Sprintf(
+			"Comma separated list of columns to display, from '%s'. By default the "+
+				"user name, identifier and roles are displayed, and also the "+
+				"organization when searching by role in all organizations.",
+			strings.Join(columns, "', '"),
+		),
+	)
+	arguments.AddRedactFlag(flags, &args.redactPII)
+	flags.BoolVar(
+		&args.emailsOnly,
+		"emails-only",
+		false,
+		"Print only the email addresses of the users, without duplicates, for example to "+
+			"build a list of recipients for a notification.",
+	)
+	flags.StringVar(
+		&args.separator,
+		"separator",
+		"\n",
+		"Text used to separate the email addresses when using the '--emails-only' option, "+
+			"for example ',' to put them in one line.",
+	)
+	flags.BoolVar(
+		&args.progress,
+		"progress",
+		false,
+		"Report the progress of the scan and the estimated remaining time in the standard "+
+			"error. By default the progress is reported only when the standard error is "+
+			"a terminal.",
+	)
+	Cmd.RegisterFlagCompletionFunc("org", completion.Organizations)
+	Cmd.RegisterFlagCompletionFunc("roles", completion.Roles)
+	Cmd.AddCommand(invitations.Cmd)
+	Cmd.AddCommand(invite.Cmd)
+	Cmd.AddCommand(offboard.Cmd)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	// Create a context:
+	ctx := context.Background()
+
+	// Check the output format:
+	format, err := output.ParseFormat(args.output)
+	if err != nil {
+		return err
+	}
+	if args.emailsOnly && (cmd.Flags().Changed("output") || args.columns != "") {
+		return fmt.Errorf(
+			"Option '--emails-only' can't be used with '--output' or '--columns'",
+		)
+	}
+	if cmd.Flags().Changed("separator") && !args.emailsOnly {
+		return fmt.Errorf("Option '--separator' can only be used with '--emails-only'")
+	}
+
+	// Check the minimum role level:
+	minLevel := -1
+	if args.minRoleLevel != "" {
+		level, ok := acc_util.RoleLevel(args.minRoleLevel)
+		if !ok {
+			return fmt.Errorf(
+				"Unknown role '%s' for option '--min-role-level', valid values are: %s",
+				args.minRoleLevel, strings.Join(acc_util.HierarchyRoles(), ", "),
+			)
+		}
+		minLevel = level
+	}
+
+	// Check the search expression before sending it to the server:
+	if args.search != "" {
+		err := search.Lint(args.search)
+		if err != nil {
+			return fmt.Errorf("Invalid search expression: %v", err)
+		}
+	}
 
 	// Load the configuration file:
 	cfg, err := config.Load()
@@ -83,17 +220,10 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Not logged in, run the 'login' command")
 	}
 
-	// Check that the configuration has credentials or tokens that haven't have expired:
-	armed, reason, err := cfg.Armed()
-	if err != nil {
-		return err
-	}
-	if !armed {
-		return fmt.Errorf("Not logged in, %s, run the 'login' command", reason)
-	}
-
-	// Create the connection, and remember to close it:
-	connection, err := cfg.Connection()
+	// Create the connection, and remember to close it. This checks that the configuration has
+	// credentials or tokens that haven't expired, and gives the user the chance to provide new
+	// ones if needed:
+	connection, err := ocm.NewConnection().Config(cfg).Build()
 	if err != nil {
 		return fmt.Errorf("Can't create connection: %v", err)
 	}
@@ -102,7 +232,6 @@ func run(cmd *cobra.Command, argv []string) error {
 	// needed variables:
 	pageSize := 100
 	pageIndex := 1
-	namePad := 40
 	searchQuery := ""
 
 	if args.org != "" {
@@ -126,60 +255,354 @@ func run(cmd *cobra.Command, argv []string) error {
 		searchQuery = fmt.Sprintf("organization_id='%s'", args.org)
 	}
 
-	// Print top.
-	fmt.Println(stringPad("USER", namePad), stringPad("USER ID", namePad), "ROLES")
+	// Add the search expression given by the user:
+	if args.search != "" {
+		if searchQuery != "" {
+			searchQuery = fmt.Sprintf("(%s) and (%s)", searchQuery, args.search)
+		} else {
+			searchQuery = args.search
+		}
+	}
+
+	// When the users aren't restricted to one organization, because we are searching by
+	// role in all organizations, we also display the organization of each user, so that the
+	// output is self describing:
+	allOrgs := args.org == "" && len(args.roles) > 0
+	orgs := acc_util.NewOrganizationCache(connection)
+
+	// Personal data is replaced with hashes if requested, so that the output can be shared:
+	var redactor *redact.Redactor
+	if args.redactPII {
+		redactor = redact.NewFromEnv()
+	}
+
+	// Large organizations need many requests, and the server may start rate limiting them. When
+	// that happens all the requests are paused till the retry window is over:
+	throttle := acc_util.NewThrottle(os.Stderr)
+
+	// The progress of the scan is saved after each page, so that if it is interrupted it can be
+	// resumed later with the '--resume' option:
+	scan := resume.NewScan(
+		"account users",
+		cfg.URL,
+		searchQuery,
+		strings.Join(args.roles, ","),
+		args.minRoleLevel,
+	)
+
+	// When searching by role the accounts with multiple matching roles appear in multiple pages
+	// of role bindings, so the set of accounts already written is saved with the progress, and
+	// they aren't written again when the scan is resumed:
+	seen := map[string]bool{}
+	if len(args.roles) > 0 {
+		scan.Track(seen)
+	}
+	if args.resume {
+		pageIndex, err = scan.Page()
+		if err != nil {
+			return err
+		}
+		if pageIndex > 1 {
+			fmt.Fprintf(os.Stderr, "Resuming from page %d\n", pageIndex)
+		}
+	}
+
+	// Create the list, including the organization columns by default when the users aren't
+	// restricted to one organization:
+	selected := args.columns
+	if selected == "" {
+		selected = "username,id,roles"
+		if allOrgs {
+			selected = "username,id,org_id,org_name,roles"
+		}
+	}
+	printer, err := output.NewPrinter().
+		Writer(os.Stdout).
+		Build(ctx)
+	if err != nil {
+		return err
+	}
+	defer printer.Close()
+	var list *output.List
+	var emails *emailWriter
+	if args.emailsOnly {
+		emails = newEmailWriter(printer, args.separator)
+	} else {
+		list, err = printer.NewList().
+			Name("users").
+			Format(format).
+			Columns(selected).
+			Known(columns...).
+			Build(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// When searching by role the role bindings are retrieved first, filtering them in the server,
+	// and then only the accounts that have those bindings, instead of all the accounts:
+	fetch := func(page int) ([]*amv1.Account, bool, error) {
+		return listAccounts(connection, throttle, searchQuery, page, pageSize)
+	}
+	count := func() (int, error) {
+		return countAccounts(connection, throttle, searchQuery)
+	}
+	what := "accounts"
+	if len(args.roles) > 0 {
+		bindingsQuery := fmt.Sprintf("role_id in ('%s')", strings.Join(args.roles, "', '"))
+		if args.org != "" {
+			bindingsQuery = fmt.Sprintf("%s and organization_id='%s'", bindingsQuery, args.org)
+		}
+		fetch = func(page int) ([]*amv1.Account, bool, error) {
+			return listRoleAccounts(
+				connection, throttle, bindingsQuery, searchQuery, seen, page, pageSize,
+			)
+		}
+		count = func() (int, error) {
+			return countRoleBindings(connection, throttle, bindingsQuery)
+		}
+		what = "role bindings"
+	}
+
+	// Large scans can take minutes, so the total is retrieved before starting, in order to report
+	// the progress and estimate the remaining time:
+	showProgress := output.IsTerminal(os.Stderr)
+	if cmd.Flags().Changed("progress") {
+		showProgress = args.progress
+	}
+	var progress *acc_util.Progress
+	if showProgress {
+		total, err := count()
+		if err != nil {
+			return err
+		}
+		initial := (pageIndex - 1) * pageSize
+		if initial > total {
+			initial = total
+		}
+		// Each report replaces the previous one only when the users aren't written to the
+		// same terminal, as otherwise the reports and the users would be mixed in the same
+		// lines:
+		overwrite := output.IsTerminal(os.Stderr) && !output.IsTerminal(os.Stdout)
+		progress = acc_util.NewProgress(os.Stderr, overwrite, what, total, initial)
+		defer progress.Close()
+	}
 
 	// Display a list of all users in our organization and their roles:
 	for {
-		// Get all users within organization
-		usersResponse, err := connection.AccountsMgmt().V1().Accounts().List().
-			Size(pageSize).
-			Page(pageIndex).
-			Parameter("search", searchQuery).
-			Send()
+		accounts, more, err := fetch(pageIndex)
 		if err != nil {
-			return fmt.Errorf("Can't retrieve accounts: %v", err)
+			return err
 		}
 
 		accountList := []*amv1.Account{}
-		accountMap := make(map[*amv1.Account]*userModel)
-
-		// Go through users found in page and display info:
-		usersResponse.Items().Each(func(account *amv1.Account) bool {
-			username := stringPad(account.Username(), namePad)
-			userID := stringPad(account.ID(), namePad)
+		accountMap := map[*amv1.Account]map[string]interface{}{}
 
+		// Go through users found in page and collect their details:
+		for _, account := range accounts {
+			orgID := account.Organization().ID()
+			orgName := account.Organization().Name()
+			if allOrgs && orgName == "" && orgID != "" {
+				orgName, err = orgs.Name(orgID)
+				if err != nil {
+					return err
+				}
+			}
 			accountList = append(accountList, account)
-			accountMap[account] = &userModel{
-				userName: username,
-				userID:   userID,
+			row := map[string]interface{}{
+				"username":   account.Username(),
+				"id":         account.ID(),
+				"email":      account.Email(),
+				"first_name": account.FirstName(),
+				"last_name":  account.LastName(),
+				"org_id":     orgID,
+				"org_name":   orgName,
 			}
-			return true
-		})
+			if redactor != nil {
+				row["username"] = redactor.Username(account.Username())
+				row["email"] = redactor.Email(account.Email())
+				row["first_name"] = redactor.Name(account.FirstName())
+				row["last_name"] = redactor.Name(account.LastName())
+				row["org_name"] = redactor.Name(orgName)
+			}
+			accountMap[account] = row
+		}
 
-		accountRoleMap, err := acc_util.GetRolesFromUsers(accountList, connection)
+		accountRoleMap, err := acc_util.GetRolesFromUsersThrottled(accountList, connection, throttle)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to get roles for user: %s\n", err)
-			os.Exit(1)
+			return fmt.Errorf("Failed to get roles for user: %v", err)
 		}
 
-		for k, v := range accountRoleMap {
-			if len(args.roles) > 0 {
-				if checkRoles(v, args.roles) {
-					fmt.Println(accountMap[k].userName, accountMap[k].userID, printArray(v))
-				}
-			} else {
-				fmt.Println(accountMap[k].userName, accountMap[k].userID, printArray(v))
+		for _, account := range accountList {
+			roles, ok := accountRoleMap[account]
+			if !ok {
+				continue
+			}
+			if len(args.roles) > 0 && !checkRoles(roles, args.roles) {
+				continue
+			}
+			if minLevel >= 0 && acc_util.MaxRoleLevel(roles) < minLevel {
+				continue
+			}
+			row := accountMap[account]
+			if emails != nil {
+				emails.Write(row["email"].(string))
+				continue
+			}
+			sort.Strings(roles)
+			row["roles"] = roles
+			err = list.Write(row)
+			if err != nil {
+				return err
 			}
 		}
 		// Resume loop:
-		if usersResponse.Size() < pageSize {
+		if !more {
+			progress.Finish()
 			break
 		}
+		progress.Set(pageIndex * pageSize)
 		pageIndex++
+		err = scan.Save(pageIndex)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Close the list, as some formats are written only when all the items are known:
+	if emails != nil {
+		emails.Close()
+	} else {
+		err = list.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return scan.Done()
+}
+
+// listAccounts retrieves one page of the accounts that match the given search query. It also
+// returns a flag indicating if there may be more pages.
+func listAccounts(connection *sdk.Connection, throttle *acc_util.Throttle, query string,
+	page, size int) (accounts []*amv1.Account, more bool, err error) {
+	var response *amv1.AccountsListResponse
+	err = throttle.Send(func() (int, http.Header, error) {
+		var err error
+		response, err = connection.AccountsMgmt().V1().Accounts().List().
+			Size(size).
+			Page(page).
+			Parameter("search", query).
+			Send()
+		return response.Status(), response.Header(), err
+	})
+	if err != nil {
+		err = fmt.Errorf("Can't retrieve accounts: %v", err)
+		return
+	}
+	accounts = response.Items().Slice()
+	more = response.Size() >= size
+	return
+}
+
+// countAccounts returns the total number of accounts that match the given search query.
+func countAccounts(connection *sdk.Connection, throttle *acc_util.Throttle,
+	query string) (total int, err error) {
+	var response *amv1.AccountsListResponse
+	err = throttle.Send(func() (int, http.Header, error) {
+		var err error
+		response, err = connection.AccountsMgmt().V1().Accounts().List().
+			Size(1).
+			Page(1).
+			Parameter("search", query).
+			Send()
+		return response.Status(), response.Header(), err
+	})
+	if err != nil {
+		err = fmt.Errorf("Can't count accounts: %v", err)
+		return
+	}
+	total = response.Total()
+	return
+}
+
+// countRoleBindings returns the total number of role bindings that match the given search query.
+func countRoleBindings(connection *sdk.Connection, throttle *acc_util.Throttle,
+	query string) (total int, err error) {
+	var response *amv1.RoleBindingsListResponse
+	err = throttle.Send(func() (int, http.Header, error) {
+		var err error
+		response, err = connection.AccountsMgmt().V1().RoleBindings().List().
+			Size(1).
+			Page(1).
+			Parameter("search", query).
+			Send()
+		return response.Status(), response.Header(), err
+	})
+	if err != nil {
+		err = fmt.Errorf("Can't count role bindings: %v", err)
+		return
+	}
+	total = response.Total()
+	return
+}
+
+// listRoleAccounts retrieves one page of the role bindings that match the given search query, and
+// then the accounts of those role bindings that also match the accounts search query. Accounts
+// that are in the seen set are skipped, so that accounts with multiple matching roles are returned
+// only once. The accounts are returned in the same order than the role bindings. It also returns
+// a flag indicating if there may be more pages.
+func listRoleAccounts(connection *sdk.Connection, throttle *acc_util.Throttle, bindingsQuery,
+	accountsQuery string, seen map[string]bool, page, size int) (accounts []*amv1.Account,
+	more bool, err error) {
+	var response *amv1.RoleBindingsListResponse
+	err = throttle.Send(func() (int, http.Header, error) {
+		var err error
+		response, err = connection.AccountsMgmt().V1().RoleBindings().List().
+			Size(size).
+			Page(page).
+			Parameter("search", bindingsQuery).
+			Send()
+		return response.Status(), response.Header(), err
+	})
+	if err != nil {
+		err = fmt.Errorf("Can't retrieve role bindings: %v", err)
+		return
+	}
+	more = response.Size() >= size
+	var ids []string
+	response.Items().Each(func(binding *amv1.RoleBinding) bool {
+		id := binding.Account().ID()
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+		return true
+	})
+	if len(ids) == 0 {
+		return
 	}
 
-	return nil
+	// Retrieve the accounts and sort them in the order of the role bindings:
+	query := fmt.Sprintf("id in ('%s')", strings.Join(ids, "', '"))
+	if accountsQuery != "" {
+		query = fmt.Sprintf("%s and (%s)", query, accountsQuery)
+	}
+	found, _, err := listAccounts(connection, throttle, query, 1, len(ids))
+	if err != nil {
+		return
+	}
+	index := map[string]*amv1.Account{}
+	for _, account := range found {
+		index[account.ID()] = account
+	}
+	for _, id := range ids {
+		account, ok := index[id]
+		if ok {
+			accounts = append(accounts, account)
+		}
+	}
+	return
 }
 
 func checkRoles(roles, roleArgs []string) bool {
@@ -193,25 +616,39 @@ func checkRoles(roles, roleArgs []string) bool {
 	return false
 }
 
-// stringPad will add whitespace or clip a string
-// depending on the strings size in comparison to padd variable.
-func stringPad(str string, padd int) string {
-	// Add padding
-	if len(str) < padd {
-		str = str + strings.Repeat(" ", padd-len(str))
-		// Clip
-	} else {
-		str = str[:padd-2] + "  "
+// emailWriter writes email addresses separated by the given text, skipping empty addresses and
+// addresses that have already been written. Addresses are compared ignoring case.
+type emailWriter struct {
+	out       io.Writer
+	separator string
+	seen      map[string]bool
+}
+
+func newEmailWriter(out io.Writer, separator string) *emailWriter {
+	return &emailWriter{
+		out:       out,
+		separator: separator,
+		seen:      map[string]bool{},
+	}
+}
+
+// Write writes the address, unless it is empty or it has already been written.
+func (w *emailWriter) Write(email string) {
+	email = strings.TrimSpace(email)
+	key := strings.ToLower(email)
+	if email == "" || w.seen[key] {
+		return
+	}
+	if len(w.seen) > 0 {
+		fmt.Fprint(w.out, w.separator)
 	}
-	return str
+	fmt.Fprint(w.out, email)
+	w.seen[key] = true
 }
 
-// printArray turns an array into a string
-// sepparated by `space`.
-func printArray(arrStr []string) string {
-	var finalString string
-	for item := range arrStr {
-		finalString = fmt.Sprint(arrStr[item], " ", finalString)
+// Close ends the list with a new line if any address has been written.
+func (w *emailWriter) Close() {
+	if len(w.seen) > 0 {
+		fmt.Fprintln(w.out)
 	}
-	return finalString
 }
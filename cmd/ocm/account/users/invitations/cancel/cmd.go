@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cancel
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/account"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+var args struct {
+	org string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "cancel EMAIL",
+	Short: "Cancel a pending invitation",
+	Long: "Cancel a pending invitation to the organization: the roles granted to the invited " +
+		"account are removed and the invitation is marked as cancelled. Invitations that have " +
+		"already been accepted can't be cancelled, use 'ocm account users offboard' instead.",
+	Example: `  # Cancel the invitation of a user
+  ocm account users invitations cancel jdoe@example.com`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.org,
+		"org",
+		"",
+		"Organization identifier. Defaults to the organization of the current user.",
+	)
+	Cmd.RegisterFlagCompletionFunc("org", completion.Organizations)
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	email := argv[0]
+	if !account.ValidEmail(email) {
+		return fmt.Errorf("Email address '%s' isn't valid", email)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	orgID := args.org
+	if orgID == "" {
+		orgID, err = account.CurrentOrganization(connection)
+		if err != nil {
+			return err
+		}
+	}
+	invited, err := account.FindAccountByEmail(connection, orgID, email)
+	if err != nil {
+		return err
+	}
+	if invited == nil {
+		return fmt.Errorf("There is no invitation for '%s' in organization '%s'", email, orgID)
+	}
+	err = account.CancelInvitation(connection, invited)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Cancelled invitation of '%s' to organization '%s'\n", email, orgID)
+	return nil
+}
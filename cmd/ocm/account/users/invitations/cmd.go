@@ -0,0 +1,150 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package invitations
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/cmd/ocm/account/users/invitations/cancel"
+	"github.com/openshift-online/ocm-cli/pkg/account"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	org  string
+	all  bool
+	json bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "invitations",
+	Short: "List the invitations to the organization",
+	Long: "List the users invited to the organization with the 'ocm account users invite' " +
+		"command. By default only the pending invitations are listed.",
+	Example: `  # List the pending invitations to the organization of the current user
+  ocm account users invitations
+
+  # List all the invitations, including the accepted and cancelled ones
+  ocm account users invitations --all`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.org,
+		"org",
+		"",
+		"Organization identifier. Defaults to the organization of the current user.",
+	)
+	flags.BoolVar(
+		&args.all,
+		"all",
+		false,
+		"List also the invitations that have been accepted or cancelled.",
+	)
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the invitations in JSON format.",
+	)
+	Cmd.RegisterFlagCompletionFunc("org", completion.Organizations)
+	Cmd.AddCommand(cancel.Cmd)
+}
+
+// Item is the representation of an invitation in the JSON output.
+type Item struct {
+	ID       string   `json:"id"`
+	Email    string   `json:"email"`
+	Username string   `json:"username"`
+	Status   string   `json:"status"`
+	Roles    []string `json:"roles"`
+	Created  string   `json:"created_at,omitempty"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	orgID := args.org
+	if orgID == "" {
+		orgID, err = account.CurrentOrganization(connection)
+		if err != nil {
+			return err
+		}
+	}
+	invitations, err := account.ListInvitations(connection, orgID)
+	if err != nil {
+		return err
+	}
+	items := []*Item{}
+	for _, invitation := range invitations {
+		if !args.all && invitation.Status != account.InvitationPending {
+			continue
+		}
+		item := &Item{
+			ID:       invitation.Account.ID(),
+			Email:    invitation.Account.Email(),
+			Username: invitation.Account.Username(),
+			Status:   invitation.Status,
+			Roles:    invitation.Roles,
+		}
+		if created, ok := invitation.Account.GetCreatedAt(); ok {
+			item.Created = created.UTC().Format("2006-01-02")
+		}
+		if item.Roles == nil {
+			item.Roles = []string{}
+		}
+		items = append(items, item)
+	}
+
+	if args.json {
+		data, err := json.Marshal(items)
+		if err != nil {
+			return fmt.Errorf("Can't marshal invitations: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	if len(items) == 0 {
+		fmt.Printf("Organization '%s' doesn't have pending invitations\n", orgID)
+		return nil
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "EMAIL\tUSERNAME\tSTATUS\tROLES\tCREATED\n")
+	for _, item := range items {
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\n",
+			item.Email, item.Username, item.Status, strings.Join(item.Roles, ","), item.Created,
+		)
+	}
+	return writer.Flush()
+}
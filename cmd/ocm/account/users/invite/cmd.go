@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package invite
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/account"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+var args struct {
+	org      string
+	roles    []string
+	username string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "invite EMAIL",
+	Short: "Invite a user to the organization",
+	Long: "Invite a user to the organization: an account is created for the email address, " +
+		"with the given roles, and marked as a pending invitation. The invitation is accepted " +
+		"when the user registers with Red Hat SSO using that email address. Pending " +
+		"invitations can be listed with 'ocm account users invitations' and cancelled with " +
+		"'ocm account users invitations cancel'. Only organization administrators can invite " +
+		"users.",
+	Example: `  # Invite a user to the organization of the current user as cluster editor
+  ocm account users invite jdoe@example.com --roles ClusterEditor
+
+  # Invite a user with a specific user name and several roles
+  ocm account users invite jdoe@example.com --username jdoe --roles ClusterEditor,ClusterViewer`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.org,
+		"org",
+		"",
+		"Organization identifier. Defaults to the organization of the current user.",
+	)
+	flags.StringSliceVar(
+		&args.roles,
+		"roles",
+		nil,
+		"Comma separated list of roles that will be granted to the user (required).",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("roles")
+	flags.StringVar(
+		&args.username,
+		"username",
+		"",
+		"User name of the new account. Defaults to the email address.",
+	)
+	Cmd.RegisterFlagCompletionFunc("org", completion.Organizations)
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	email := argv[0]
+	if !account.ValidEmail(email) {
+		return fmt.Errorf("Email address '%s' isn't valid", email)
+	}
+	username := args.username
+	if username == "" {
+		username = email
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Check that the roles exist before creating the account, so that it isn't left without
+	// roles if any of them is wrong:
+	for _, role := range args.roles {
+		response, err := connection.AccountsMgmt().V1().Roles().Role(role).Get().Send()
+		if err != nil {
+			if response != nil && response.Status() == http.StatusNotFound {
+				return fmt.Errorf("Role '%s' doesn't exist", role)
+			}
+			return fmt.Errorf("Can't retrieve role '%s': %v", role, err)
+		}
+	}
+
+	orgID := args.org
+	if orgID == "" {
+		orgID, err = account.CurrentOrganization(connection)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = account.Invite(connection, orgID, email, username, args.roles)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Invited '%s' to organization '%s'\n", email, orgID)
+	return nil
+}
@@ -0,0 +1,350 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offboard
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	"github.com/spf13/cobra"
+
+	acc_util "github.com/openshift-online/ocm-cli/pkg/account"
+	"github.com/openshift-online/ocm-cli/pkg/bulk"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/curl"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+var args struct {
+	successor string
+	org       string
+	dryRun    bool
+	results   string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "offboard USERNAME",
+	Short: "Remove the roles of a user and transfer their clusters",
+	Long: "Prepare the departure of a user from the organization: the clusters created by " +
+		"the user are transferred to the successor given with the '--successor' option, " +
+		"and then all the role bindings of the user are removed. Finally a report with the " +
+		"result of each change is printed.\n\n" +
+		"The command exits with code 0 when all the changes succeeded, with code 2 when " +
+		"only some of them failed and with code 1 when all of them failed.",
+	Example: `  # Show what would be changed to offboard user 'bob'
+  ocm account users offboard bob --successor alice --dry-run
+
+  # Offboard user 'bob', transferring the clusters to user 'alice'
+  ocm account users offboard bob --successor alice`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.successor,
+		"successor",
+		"",
+		"User name of the user that will own the clusters of the offboarded user. It is "+
+			"mandatory when the offboarded user owns clusters.",
+	)
+	flags.StringVar(
+		&args.org,
+		"org",
+		"",
+		"Organization identifier. If given the users are searched only in this "+
+			"organization.",
+	)
+	dryrun.BoolVar(
+		flags,
+		&args.dryRun,
+		"Show the changes that would be applied without applying them.",
+	)
+	flags.StringVar(
+		&args.results,
+		"results",
+		"",
+		"Write the result of each change to this file, in JSON format.",
+	)
+	Cmd.RegisterFlagCompletionFunc("org", completion.Organizations)
+	readonly.Mark(Cmd)
+}
+
+// activeStatuses are the statuses of the subscriptions that are transferred. Subscriptions of
+// clusters that have been deprovisioned or archived are left alone.
+var activeStatuses = "'Active', 'Disconnected', 'Reserved', 'Stale'"
+
+func run(cmd *cobra.Command, argv []string) error {
+	username := argv[0]
+	if args.successor == username {
+		return fmt.Errorf("The successor can't be the user that is offboarded")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+	throttle := acc_util.NewThrottle(os.Stderr)
+
+	// Find the users:
+	account, err := findAccount(connection, throttle, username)
+	if err != nil {
+		return err
+	}
+	var successor *amv1.Account
+	if args.successor != "" {
+		successor, err = findAccount(connection, throttle, args.successor)
+		if err != nil {
+			return err
+		}
+		if successor.Organization().ID() != account.Organization().ID() {
+			return fmt.Errorf(
+				"Successor '%s' isn't in organization '%s' of user '%s'",
+				args.successor, account.Organization().ID(), username,
+			)
+		}
+	}
+
+	// Find the subscriptions and the role bindings before changing anything, so that nothing
+	// is changed if the user owns clusters and there is no successor:
+	subscriptions, err := listSubscriptions(connection, throttle, account.ID())
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) > 0 && successor == nil {
+		return fmt.Errorf(
+			"User '%s' owns %d clusters, use the '--successor' option to give the user "+
+				"that will own them",
+			username, len(subscriptions),
+		)
+	}
+	bindings, err := listBindings(connection, throttle, account.ID())
+	if err != nil {
+		return err
+	}
+
+	// Transfer the clusters first, as they are more important than the roles:
+	results := bulk.NewResults()
+	transferred := 0
+	for _, subscription := range subscriptions {
+		action := fmt.Sprintf("transfer to %s", args.successor)
+		err = transfer(connection, throttle, subscription, successor)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", subscription.ID(), err)
+			results.Failed(subscription.ID(), subscription.DisplayName(), action, err)
+			continue
+		}
+		results.Succeeded(subscription.ID(), subscription.DisplayName(), action)
+		transferred++
+	}
+	removed := 0
+	for _, binding := range bindings {
+		action := fmt.Sprintf("remove %s role", binding.Role().ID())
+		name := binding.Type()
+		if binding.Subscription().ID() != "" {
+			name = fmt.Sprintf("%s %s", name, binding.Subscription().ID())
+		}
+		err = remove(connection, throttle, binding)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", binding.ID(), err)
+			results.Failed(binding.ID(), name, action, err)
+			continue
+		}
+		results.Succeeded(binding.ID(), name, action)
+		removed++
+	}
+
+	// Print the report:
+	if len(results.Items()) == 0 {
+		fmt.Printf("User '%s' doesn't have clusters or roles\n", username)
+		return nil
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "ID\tNAME\tACTION\tSTATUS\n")
+	for _, item := range results.Items() {
+		status := string(item.Status)
+		if args.dryRun && item.Status == bulk.StatusSucceeded {
+			status = "pending"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", item.ID, item.Name, item.Action, status)
+	}
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+	prefix := ""
+	if args.dryRun {
+		prefix = "to be "
+	}
+	fmt.Printf(
+		"\nClusters %stransferred: %d, roles %sremoved: %d, failed: %d\n",
+		prefix, transferred,
+		prefix, removed,
+		results.Count(bulk.StatusFailed),
+	)
+	if args.results != "" {
+		err = results.Write(args.results)
+		if err != nil {
+			return err
+		}
+	}
+	return results.Err(fmt.Sprintf("Failed to offboard user '%s'", username))
+}
+
+// findAccount retrieves the account with the given user name.
+func findAccount(connection *sdk.Connection, throttle *acc_util.Throttle,
+	username string) (result *amv1.Account, err error) {
+	query := fmt.Sprintf("username = '%s'", username)
+	if args.org != "" {
+		query = fmt.Sprintf("%s and organization_id = '%s'", query, args.org)
+	}
+	var response *amv1.AccountsListResponse
+	err = throttle.Send(func() (int, http.Header, error) {
+		var err error
+		response, err = connection.AccountsMgmt().V1().Accounts().List().
+			Search(query).
+			Size(1).
+			Send()
+		return response.Status(), response.Header(), err
+	})
+	if err != nil {
+		err = fmt.Errorf("Can't retrieve user '%s': %v", username, err)
+		return
+	}
+	if response.Items().Len() == 0 {
+		err = fmt.Errorf("User '%s' doesn't exist", username)
+		return
+	}
+	result = response.Items().Get(0)
+	return
+}
+
+// listSubscriptions retrieves the subscriptions of the active clusters created by the given
+// account.
+func listSubscriptions(connection *sdk.Connection, throttle *acc_util.Throttle,
+	accountID string) (result []*amv1.Subscription, err error) {
+	query := fmt.Sprintf("creator_id = '%s' and status in (%s)", accountID, activeStatuses)
+	size := 100
+	for page := 1; ; page++ {
+		var response *amv1.SubscriptionsListResponse
+		err = throttle.Send(func() (int, http.Header, error) {
+			var err error
+			response, err = connection.AccountsMgmt().V1().Subscriptions().List().
+				Search(query).
+				Size(size).
+				Page(page).
+				Send()
+			return response.Status(), response.Header(), err
+		})
+		if err != nil {
+			err = fmt.Errorf("Can't retrieve subscriptions: %v", err)
+			return
+		}
+		result = append(result, response.Items().Slice()...)
+		if response.Size() < size {
+			return
+		}
+	}
+}
+
+// listBindings retrieves all the role bindings of the given account, for the organization and
+// for individual subscriptions.
+func listBindings(connection *sdk.Connection, throttle *acc_util.Throttle,
+	accountID string) (result []*amv1.RoleBinding, err error) {
+	query := fmt.Sprintf("account_id = '%s'", accountID)
+	size := 100
+	for page := 1; ; page++ {
+		var response *amv1.RoleBindingsListResponse
+		err = throttle.Send(func() (int, http.Header, error) {
+			var err error
+			response, err = connection.AccountsMgmt().V1().RoleBindings().List().
+				Parameter("search", query).
+				Size(size).
+				Page(page).
+				Send()
+			return response.Status(), response.Header(), err
+		})
+		if err != nil {
+			err = fmt.Errorf("Can't retrieve roles: %v", err)
+			return
+		}
+		result = append(result, response.Items().Slice()...)
+		if response.Size() < size {
+			return
+		}
+	}
+}
+
+// transfer changes the creator of the subscription to the successor, unless in dry run mode.
+func transfer(connection *sdk.Connection, throttle *acc_util.Throttle,
+	subscription *amv1.Subscription, successor *amv1.Account) error {
+	fmt.Printf(
+		"Transfer cluster '%s' to '%s'\n",
+		subscription.DisplayName(), successor.Username(),
+	)
+	if args.dryRun {
+		return nil
+	}
+	body, err := amv1.NewSubscription().
+		Creator(amv1.NewAccount().ID(successor.ID())).
+		Build()
+	if err != nil {
+		return err
+	}
+	err = throttle.Send(func() (int, http.Header, error) {
+		response, err := connection.AccountsMgmt().V1().Subscriptions().
+			Subscription(subscription.ID()).
+			Update().
+			Body(body).
+			Send()
+		return response.Status(), response.Header(), err
+	})
+	if err != nil && !errors.Is(err, curl.ErrNotSent) {
+		return err
+	}
+	return nil
+}
+
+// remove deletes the role binding, unless in dry run mode.
+func remove(connection *sdk.Connection, throttle *acc_util.Throttle,
+	binding *amv1.RoleBinding) error {
+	fmt.Printf("Remove role '%s' of type '%s'\n", binding.Role().ID(), binding.Type())
+	if args.dryRun {
+		return nil
+	}
+	err := throttle.Send(func() (int, http.Header, error) {
+		response, err := connection.AccountsMgmt().V1().RoleBindings().
+			RoleBinding(binding.ID()).
+			Delete().
+			Send()
+		return response.Status(), response.Header(), err
+	})
+	if err != nil && !errors.Is(err, curl.ErrNotSent) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package annotate
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+var args struct {
+	remove string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "annotate [flags] {NAME|ID|EXTERNAL_ID} [NOTE]",
+	Short: "Add, list and remove notes about a cluster",
+	Long: "Add a free-form note to a cluster, so that the rest of the team can see it in the " +
+		"output of the 'describe cluster' command. Without a note the command lists the " +
+		"notes of the cluster. Notes are stored as labels of the subscription of the " +
+		"cluster, with keys that start with '" + c.NoteLabelPrefix + "'. The command " +
+		"is rejected in read-only mode, but the notes are still displayed by the " +
+		"'describe cluster' command.",
+	Example: `  # Add a note to cluster 'mycluster'
+  ocm annotate mycluster "Resized for Black Friday, revert after"
+
+  # List the notes of cluster 'mycluster'
+  ocm annotate mycluster
+
+  # Remove a note
+  ocm annotate mycluster --remove ocm.note.20231124T093000.000Z`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+	RunE:              run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.remove,
+		"remove",
+		"",
+		"Key of the note to remove, as shown when listing the notes.",
+	)
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+	text := ""
+	if len(argv) > 1 {
+		text = strings.TrimSpace(argv[1])
+		if text == "" {
+			return fmt.Errorf("Note can't be empty")
+		}
+	}
+	if text != "" && args.remove != "" {
+		return fmt.Errorf("Option '--remove' can't be used when adding a note")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	subID := cluster.Subscription().ID()
+	if subID == "" {
+		return fmt.Errorf("Cluster '%s' doesn't have a subscription", clusterKey)
+	}
+
+	switch {
+	case text != "":
+		key, err := c.AddNote(connection, subID, text)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Added note '%s' to cluster '%s'\n", key, cluster.Name())
+	case args.remove != "":
+		err = c.DeleteNote(connection, subID, args.remove)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed note '%s' from cluster '%s'\n", args.remove, cluster.Name())
+	default:
+		notes, err := c.ListNotes(connection, subID)
+		if err != nil {
+			return err
+		}
+		if len(notes) == 0 {
+			fmt.Printf("Cluster '%s' doesn't have notes\n", cluster.Name())
+			return nil
+		}
+		table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(table, "KEY\tCREATED\tNOTE\n")
+		for _, note := range notes {
+			fmt.Fprintf(
+				table,
+				"%s\t%s\t%s\n",
+				note.Key, note.CreatedAt.UTC().Format(time.RFC3339), note.Text,
+			)
+		}
+		table.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/cmd/ocm/api/request"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "api COMMAND",
+	Short: "Send requests to any API endpoint",
+	Long: "Send requests to the API endpoints that aren't supported by other commands, using " +
+		"the credentials of the current configuration. Collections are retrieved " +
+		"completely, following all the pages, and responses can be filtered with JMESPath " +
+		"expressions.",
+	Args: cobra.MinimumNArgs(1),
+}
+
+func init() {
+	Cmd.AddCommand(request.NewCmd(http.MethodDelete))
+	Cmd.AddCommand(request.NewCmd(http.MethodGet))
+	Cmd.AddCommand(request.NewCmd(http.MethodPatch))
+	Cmd.AddCommand(request.NewCmd(http.MethodPost))
+}
@@ -0,0 +1,215 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jmespath/go-jmespath"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/pagination"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
+)
+
+// options contains the values of the command line options of one of the request commands.
+type options struct {
+	parameter []string
+	header    []string
+	body      string
+	query     string
+	single    bool
+}
+
+// NewCmd creates the command that sends requests with the given HTTP method.
+func NewCmd(method string) *cobra.Command {
+	verb := strings.ToLower(method)
+	opts := &options{}
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("%s PATH", verb),
+		Short: fmt.Sprintf("Send a %s request", method),
+		Long: fmt.Sprintf(
+			"Send a %s request to the given path, which can also be the alias of a "+
+				"collection, like 'clusters'.",
+			method,
+		),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, argv []string) error {
+			return run(cmd.Context(), method, argv[0], opts)
+		},
+	}
+	fs := cmd.Flags()
+	arguments.AddParameterFlag(fs, &opts.parameter)
+	arguments.AddHeaderFlag(fs, &opts.header)
+	switch method {
+	case http.MethodGet:
+		cmd.Long += " If the response is a collection all the pages are retrieved and " +
+			"merged, unless the 'page' parameter is given. The 'size' parameter sets " +
+			"the number of items retrieved in each page."
+		cmd.Example = `  # Print the names of all the clusters that are ready
+  ocm api get /api/clusters_mgmt/v1/clusters --parameter search="state = 'ready'" \
+    --query 'items[].name'`
+	case http.MethodPost, http.MethodPatch:
+		arguments.AddBodyFlag(fs, &opts.body)
+	}
+	fs.StringVar(
+		&opts.query,
+		"query",
+		"",
+		"Filter the response using this JMESPath expression, for example 'items[].id'. "+
+			"Results that are strings are printed without quotes.",
+	)
+	fs.BoolVar(
+		&opts.single,
+		"single",
+		false,
+		"Return the output as a single line.",
+	)
+	if method != http.MethodGet {
+		readonly.Mark(cmd)
+	}
+	return cmd
+}
+
+func run(ctx context.Context, method, path string, opts *options) error {
+	// Check the options before sending the request:
+	path, err := urls.Expand([]string{path})
+	if err != nil {
+		return fmt.Errorf("Could not create URI: %v", err)
+	}
+	var query *jmespath.JMESPath
+	if opts.query != "" {
+		query, err = dump.ParseJMESPath(opts.query)
+		if err != nil {
+			return fmt.Errorf("Invalid JMESPath expression '%s': %v", opts.query, err)
+		}
+	}
+	var body []byte
+	if method == http.MethodPost || method == http.MethodPatch {
+		body, err = arguments.ReadBodyFlag(opts.body)
+		if err != nil {
+			return fmt.Errorf("Can't read body: %v", err)
+		}
+	}
+
+	// Extract the pagination parameters:
+	paginate := method == http.MethodGet
+	size := 0
+	var parameters []string
+	for _, parameter := range opts.parameter {
+		name, value := arguments.ParseNameValuePair(parameter)
+		switch {
+		case name == "page":
+			paginate = false
+		case name == "size" && paginate:
+			size, err = strconv.Atoi(value)
+			if err != nil || size <= 0 {
+				return fmt.Errorf("Value '%s' of parameter 'size' isn't a positive number", value)
+			}
+			continue
+		}
+		parameters = append(parameters, parameter)
+	}
+	if !paginate && size > 0 {
+		parameters = append(parameters, fmt.Sprintf("size=%d", size))
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Send the request, or the requests for all the pages:
+	newRequest := func() *sdk.Request {
+		request := methodRequest(connection, method)
+		// The path was already checked by the expansion, so errors can't happen here:
+		arguments.ApplyPathArg(request, path) // #nosec G104
+		arguments.ApplyParameterFlag(request, parameters)
+		arguments.ApplyHeaderFlag(request, opts.header)
+		if body != nil {
+			request.Bytes(body)
+		}
+		return request
+	}
+	var status int
+	if paginate {
+		status, body, err = pagination.Collect(ctx, newRequest, size)
+	} else {
+		var response *sdk.Response
+		response, err = newRequest().SendContext(ctx)
+		if err == nil {
+			status = response.Status()
+			body = response.Bytes()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("Can't send request: %v", err)
+	}
+
+	// Write the response:
+	switch {
+	case status >= http.StatusBadRequest:
+		err = writeBody(os.Stderr, body, opts.single)
+		if err != nil {
+			return fmt.Errorf("Can't print body: %v", err)
+		}
+		return fmt.Errorf("Request failed with status code %d", status)
+	case len(body) == 0:
+		return nil
+	case query != nil:
+		err = dump.JMESPath(os.Stdout, body, query, opts.single)
+	default:
+		err = writeBody(os.Stdout, body, opts.single)
+	}
+	if err != nil {
+		return fmt.Errorf("Can't print body: %v", err)
+	}
+	return nil
+}
+
+// methodRequest creates a request with the given HTTP method.
+func methodRequest(connection *sdk.Connection, method string) *sdk.Request {
+	switch method {
+	case http.MethodPost:
+		return connection.Post()
+	case http.MethodPatch:
+		return connection.Patch()
+	case http.MethodDelete:
+		return connection.Delete()
+	default:
+		return connection.Get()
+	}
+}
+
+func writeBody(stream *os.File, body []byte, single bool) error {
+	if single {
+		return dump.Single(stream, body)
+	}
+	return dump.Pretty(stream, body)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clear
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/cache"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Clear the cache",
+	Long:  "Remove all the responses and other data saved in the cache directory.",
+	Args:  cobra.NoArgs,
+	RunE:  run,
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	dir, err := cache.Dir()
+	if err != nil {
+		return fmt.Errorf("Can't find cache directory: %v", err)
+	}
+	err = cache.Clear()
+	if err != nil {
+		return fmt.Errorf("Can't clear cache directory '%s': %v", dir, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Cleared cache directory '%s'\n", dir)
+	return nil
+}
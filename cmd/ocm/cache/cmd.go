@@ -0,0 +1,40 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cache/clear"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "cache COMMAND",
+	Short: "Manage the cache of responses",
+	Long: "Manage the cache of responses used by the '--cached' and '--refresh' options.\n" +
+		"\n" +
+		"When the '--cached' option is used the responses to read requests are saved in the\n" +
+		"cache directory, and used instead of sending the same request again while they\n" +
+		"are newer than the time given by the '--cache-ttl' option. If the server can't be\n" +
+		"reached older responses are used as well, with a warning. The '--refresh' option\n" +
+		"always sends the requests, and updates the cache.",
+	Args: cobra.MinimumNArgs(1),
+}
+
+func init() {
+	Cmd.AddCommand(clear.Cmd)
+}
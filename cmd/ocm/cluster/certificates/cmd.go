@@ -0,0 +1,148 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	json    bool
+	timeout time.Duration
+}
+
+var Cmd = &cobra.Command{
+	Use:   "certificates [flags] {NAME|ID|EXTERNAL_ID}",
+	Short: "Show the certificates of a cluster",
+	Long: "Show the issuer and expiration date of the certificates of the API and ingress " +
+		"endpoints of a cluster. The API doesn't store this information, so it is obtained " +
+		"connecting to the API and console URLs of the cluster, and that is only possible " +
+		"when they are reachable from this machine.",
+	Example: `  # Show the certificates of cluster 'mycluster'
+  ocm cluster certificates mycluster`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+	RunE:              run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the certificates in JSON format",
+	)
+	flags.DurationVar(
+		&args.timeout,
+		"timeout",
+		10*time.Second,
+		"Maximum time to wait for the connection to each endpoint",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	if args.timeout <= 0 {
+		return fmt.Errorf("Option '--timeout' must be a positive duration")
+	}
+
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	certificates := c.GetCertificates(cluster, args.timeout)
+	if len(certificates) == 0 {
+		return fmt.Errorf("Cluster '%s' doesn't have API or console URLs yet", clusterKey)
+	}
+
+	// Write the certificates:
+	if args.json {
+		data, err := json.Marshal(certificates)
+		if err != nil {
+			return fmt.Errorf("Can't marshal certificates: %v", err)
+		}
+		err = dump.Pretty(os.Stdout, data)
+		if err != nil {
+			return err
+		}
+	} else {
+		now := time.Now()
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "ENDPOINT\tHOST\tISSUER\tNOT AFTER\tDAYS LEFT\n")
+		for _, certificate := range certificates {
+			if certificate.Error != "" {
+				fmt.Fprintf(writer, "%s\t%s\t-\t-\t-\n", certificate.Endpoint, certificate.Host)
+				continue
+			}
+			fmt.Fprintf(
+				writer, "%s\t%s\t%s\t%s\t%d\n",
+				certificate.Endpoint, certificate.Host, certificate.Issuer,
+				certificate.NotAfter.Format(time.RFC3339), certificate.DaysLeft(now),
+			)
+		}
+		err = writer.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Report the endpoints that couldn't be reached:
+	failed := 0
+	for _, certificate := range certificates {
+		if certificate.Error != "" {
+			fmt.Fprintf(
+				os.Stderr, "Can't get certificate of endpoint '%s': %s\n",
+				certificate.Endpoint, certificate.Error,
+			)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("Failed to get %d of %d certificates", failed, len(certificates))
+	}
+	return nil
+}
@@ -17,8 +17,25 @@ limitations under the License.
 package cluster
 
 import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/certificates"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/compliance"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/copyidps"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/costtags"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/events"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/login"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/logs"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/managementcluster"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/migratenetwork"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/protect"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/pullsecret"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/rightsizing"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/rotateoperatorroles"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/scale"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/schedule"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/ssh"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/status"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/upgradepolicies"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/uuidlabels"
 	"github.com/spf13/cobra"
 )
 
@@ -30,6 +47,23 @@ var Cmd = &cobra.Command{
 }
 
 func init() {
+	Cmd.AddCommand(certificates.Cmd)
+	Cmd.AddCommand(compliance.Cmd)
+	Cmd.AddCommand(copyidps.Cmd)
+	Cmd.AddCommand(costtags.Cmd)
+	Cmd.AddCommand(events.Cmd)
 	Cmd.AddCommand(login.Cmd)
+	Cmd.AddCommand(logs.Cmd)
+	Cmd.AddCommand(managementcluster.Cmd)
+	Cmd.AddCommand(migratenetwork.Cmd)
+	Cmd.AddCommand(protect.Cmd)
+	Cmd.AddCommand(pullsecret.Cmd)
+	Cmd.AddCommand(rightsizing.Cmd)
+	Cmd.AddCommand(rotateoperatorroles.Cmd)
+	Cmd.AddCommand(scale.Cmd)
+	Cmd.AddCommand(schedule.Cmd)
+	Cmd.AddCommand(ssh.Cmd)
 	Cmd.AddCommand(status.Cmd)
+	Cmd.AddCommand(upgradepolicies.Cmd)
+	Cmd.AddCommand(uuidlabels.Cmd)
 }
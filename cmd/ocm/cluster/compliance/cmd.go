@@ -0,0 +1,34 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compliance
+
+import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/compliance/report"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "compliance COMMAND",
+	Short: "Collect compliance evidence about clusters",
+	Long: "Collect the security relevant settings of clusters known to OCM, for example " +
+		"as evidence for compliance audits.",
+	Args: cobra.MinimumNArgs(1),
+}
+
+func init() {
+	Cmd.AddCommand(report.Cmd)
+}
@@ -0,0 +1,282 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/parallel"
+)
+
+// Formats of the report:
+const (
+	formatCSV  = "csv"
+	formatJSON = "json"
+)
+
+// Summary of the IMDSv2 settings of the cluster and its machine pools, used when some pools
+// require it and others don't:
+const imdsMixed = "mixed"
+
+var args struct {
+	search   string
+	format   string
+	parallel int
+}
+
+var Cmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report the security settings of clusters",
+	Long: "Generate a report containing for each cluster the security relevant settings " +
+		"known to OCM: FIPS mode, etcd encryption, private API, AWS PrivateLink, STS, " +
+		"customer managed encryption keys and the use of the version 2 of the AWS instance " +
+		"metadata service (IMDSv2) by the cluster and its machine pools.",
+	Example: `  # Generate the report for all the clusters in CSV format
+  ocm cluster compliance report > compliance.csv
+
+  # Generate the report for the AWS clusters in JSON format
+  ocm cluster compliance report --search "cloud_provider.id = 'aws'" --format json`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.search,
+		"search",
+		"",
+		"Only include the clusters that match this search criteria, for example "+
+			"\"cloud_provider.id = 'aws'\".",
+	)
+	flags.StringVar(
+		&args.format,
+		"format",
+		formatCSV,
+		fmt.Sprintf("Format of the report, either '%s' or '%s'", formatCSV, formatJSON),
+	)
+	flags.IntVar(
+		&args.parallel,
+		"parallel",
+		10,
+		"Maximum number of clusters whose machine pools are retrieved at the same time",
+	)
+}
+
+// clusterReport is the row of the report for one cluster. Note that the field names are part of
+// the JSON output, so don't change them without considering the consumers of that output.
+type clusterReport struct {
+	ID             string        `json:"id"`
+	Name           string        `json:"name"`
+	CloudProvider  string        `json:"cloud_provider"`
+	Region         string        `json:"region"`
+	State          string        `json:"state"`
+	FIPS           bool          `json:"fips"`
+	EtcdEncryption bool          `json:"etcd_encryption"`
+	PrivateAPI     bool          `json:"private_api"`
+	PrivateLink    bool          `json:"private_link"`
+	STS            bool          `json:"sts"`
+	KMSKeyARN      string        `json:"kms_key_arn,omitempty"`
+	IMDSv2         string        `json:"imdsv2,omitempty"`
+	MachinePools   []*poolReport `json:"machine_pools,omitempty"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// poolReport contains the security settings of a machine pool.
+type poolReport struct {
+	ID                    string `json:"id"`
+	EC2MetadataHTTPTokens string `json:"ec2_metadata_http_tokens"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	if args.format != formatCSV && args.format != formatJSON {
+		return fmt.Errorf(
+			"Option '--format' must be '%s' or '%s'", formatCSV, formatJSON,
+		)
+	}
+	if args.parallel < 1 {
+		return fmt.Errorf("Option '--parallel' must be at least 1")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Retrieve the clusters, including the settings that the SDK doesn't support:
+	clusters := []*cmv1.Cluster{}
+	details := []*cluster.ClusterDetails{}
+	size := 100
+	for index := 1; ; index++ {
+		items, itemDetails, count, err := cluster.ListClustersWithDetails(
+			connection, args.search, index, size,
+		)
+		if err != nil {
+			return err
+		}
+		clusters = append(clusters, items...)
+		details = append(details, itemDetails...)
+		if count < size {
+			break
+		}
+	}
+
+	// Generate the report, retrieving the machine pools of the clusters concurrently:
+	reports := make([]*clusterReport, len(clusters))
+	parallel.Each(len(clusters), args.parallel, func(i int) {
+		reports[i] = reportCluster(connection, clusters[i], details[i])
+	})
+
+	// Write the report:
+	switch args.format {
+	case formatJSON:
+		var data []byte
+		data, err = json.Marshal(reports)
+		if err == nil {
+			err = dump.Pretty(os.Stdout, data)
+		}
+	default:
+		err = writeCSV(os.Stdout, reports)
+	}
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, report := range reports {
+		if report.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("Failed to retrieve the details of %d clusters", failed)
+	}
+	return nil
+}
+
+// reportCluster generates the report for the given cluster. For AWS clusters it also retrieves the
+// machine pools, to check if they require IMDSv2.
+func reportCluster(connection *sdk.Connection, item *cmv1.Cluster,
+	details *cluster.ClusterDetails) *clusterReport {
+	report := &clusterReport{
+		ID:             item.ID(),
+		Name:           item.Name(),
+		CloudProvider:  item.CloudProvider().ID(),
+		Region:         item.Region().ID(),
+		State:          string(item.State()),
+		FIPS:           item.FIPS(),
+		EtcdEncryption: item.EtcdEncryption(),
+		PrivateAPI:     item.API().Listening() == cmv1.ListeningMethodInternal,
+		PrivateLink:    item.AWS().PrivateLink(),
+		STS:            item.AWS().STS().RoleARN() != "",
+		KMSKeyARN:      item.AWS().KMSKeyArn(),
+	}
+	if report.CloudProvider != "aws" {
+		return report
+	}
+
+	// Clusters with hosted control planes don't have machine pools, only the setting of the
+	// cluster applies to them:
+	settings := []string{details.EC2MetadataHTTPTokens()}
+	if !details.Hosted() {
+		pools, err := cluster.GetMachinePoolsDetails(connection, item.ID())
+		if err != nil {
+			report.Error = err.Error()
+			return report
+		}
+		ids := make([]string, 0, len(pools))
+		for id := range pools {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			setting := pools[id].EC2MetadataHTTPTokens()
+			report.MachinePools = append(report.MachinePools, &poolReport{
+				ID:                    id,
+				EC2MetadataHTTPTokens: setting,
+			})
+			settings = append(settings, setting)
+		}
+	}
+	report.IMDSv2 = settings[0]
+	for _, setting := range settings[1:] {
+		if setting != report.IMDSv2 {
+			report.IMDSv2 = imdsMixed
+			break
+		}
+	}
+	return report
+}
+
+// writeCSV writes the report in CSV format. The machine pools are summarized in one column
+// containing the identifiers of the pools that don't require IMDSv2.
+func writeCSV(out io.Writer, reports []*clusterReport) error {
+	writer := csv.NewWriter(out)
+	err := writer.Write([]string{
+		"id", "name", "cloud_provider", "region", "state", "fips", "etcd_encryption",
+		"private_api", "private_link", "sts", "kms_key_arn", "imdsv2",
+		"imdsv2_optional_pools", "error",
+	})
+	if err != nil {
+		return err
+	}
+	for _, report := range reports {
+		optional := []string{}
+		for _, pool := range report.MachinePools {
+			if pool.EC2MetadataHTTPTokens != cluster.EC2MetadataHTTPTokensRequired {
+				optional = append(optional, pool.ID)
+			}
+		}
+		err = writer.Write([]string{
+			report.ID,
+			report.Name,
+			report.CloudProvider,
+			report.Region,
+			report.State,
+			strconv.FormatBool(report.FIPS),
+			strconv.FormatBool(report.EtcdEncryption),
+			strconv.FormatBool(report.PrivateAPI),
+			strconv.FormatBool(report.PrivateLink),
+			strconv.FormatBool(report.STS),
+			report.KMSKeyARN,
+			report.IMDSv2,
+			strings.Join(optional, " "),
+			report.Error,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,340 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package copyidps
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/curl"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+var args struct {
+	from       string
+	to         string
+	skipGroups bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "copy-idps --from={NAME|ID|EXTERNAL_ID} --to={NAME|ID|EXTERNAL_ID}",
+	Short: "Copy identity providers and group members from one cluster to another",
+	Long: "Copy the identity providers and the members of the groups of a cluster to " +
+		"another cluster. The API doesn't return secrets, like client secrets, bind " +
+		"passwords or the passwords of the users of htpasswd identity providers, so " +
+		"they will be requested interactively. When not running in a terminal the " +
+		"identity providers that need secrets are skipped with a warning. Identity " +
+		"providers that already exist in the target cluster are skipped.",
+	Example: `  # Copy the identity providers of cluster 'prod' to cluster 'prod-new'
+  ocm cluster copy-idps --from prod --to prod-new`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVar(
+		&args.from,
+		"from",
+		"",
+		"Name, identifier or external identifier of the cluster to copy from (required).",
+	)
+	fs.StringVar(
+		&args.to,
+		"to",
+		"",
+		"Name, identifier or external identifier of the cluster to copy to (required).",
+	)
+	fs.BoolVar(
+		&args.skipGroups,
+		"skip-groups",
+		false,
+		"Don't copy the members of the groups.",
+	)
+	Cmd.MarkFlagRequired("from")
+	Cmd.MarkFlagRequired("to")
+	Cmd.RegisterFlagCompletionFunc("from", completion.Clusters)
+	Cmd.RegisterFlagCompletionFunc("to", completion.Clusters)
+	readonly.Mark(Cmd)
+}
+
+// secretFields contains, for each type of identity provider, the name of the field that contains
+// the secret that isn't returned by the API. The passwords of the users of htpasswd identity
+// providers are handled separately, as there is one for each user.
+var secretFields = map[string]string{
+	"github":  "client_secret",
+	"gitlab":  "client_secret",
+	"google":  "client_secret",
+	"ldap":    "bind_password",
+	"open_id": "client_secret",
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster keys (name, identifier or external identifier) given by the user
+	// are reasonably safe so that there is no risk of SQL injection:
+	for _, clusterKey := range []string{args.from, args.to} {
+		if !c.IsValidClusterKey(clusterKey) {
+			return fmt.Errorf(
+				"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+					"must contain only letters, digits, dashes and underscores",
+				clusterKey,
+			)
+		}
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+	clusterCollection := connection.ClustersMgmt().V1().Clusters()
+
+	source, err := c.GetCluster(connection, args.from)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", args.from, err)
+	}
+	target, err := c.GetCluster(connection, args.to)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", args.to, err)
+	}
+	if source.ID() == target.ID() {
+		return fmt.Errorf("Source and target clusters are the same")
+	}
+	if target.State() != cmv1.ClusterStateReady {
+		return fmt.Errorf("Cluster '%s' is not yet ready", args.to)
+	}
+
+	// Copy the identity providers that don't exist yet in the target cluster:
+	sourceIDPs, err := c.GetIdentityProviders(clusterCollection, source.ID())
+	if err != nil {
+		return err
+	}
+	targetIDPs, err := c.GetIdentityProviders(clusterCollection, target.ID())
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, idp := range targetIDPs {
+		existing[idp.Name()] = true
+	}
+	interactive := output.IsTerminal(os.Stdin)
+	for _, idp := range sourceIDPs {
+		if existing[idp.Name()] {
+			fmt.Printf("Identity provider '%s' already exists, skipping it\n", idp.Name())
+			continue
+		}
+		if !interactive && needsSecrets(idp) {
+			fmt.Fprintf(
+				os.Stderr,
+				"Warning: identity provider '%s' needs secrets that can only be entered "+
+					"in a terminal, skipping it\n",
+				idp.Name(),
+			)
+			continue
+		}
+		var users []*cmv1.HTPasswdUser
+		if idp.Type() == cmv1.IdentityProviderTypeHtpasswd {
+			users, err = c.GetHTPasswdUsers(clusterCollection, source.ID(), idp.ID())
+			if err != nil {
+				return err
+			}
+			if len(users) == 0 {
+				fmt.Fprintf(
+					os.Stderr,
+					"Warning: identity provider '%s' doesn't have users, skipping it\n",
+					idp.Name(),
+				)
+				continue
+			}
+		}
+		copied, err := copyIDP(idp, users)
+		if err != nil {
+			return fmt.Errorf("Failed to copy identity provider '%s': %v", idp.Name(), err)
+		}
+		_, err = clusterCollection.Cluster(target.ID()).
+			IdentityProviders().
+			Add().
+			Body(copied).
+			Send()
+		if err != nil {
+			return fmt.Errorf(
+				"Failed to add identity provider '%s' to cluster '%s': %w",
+				idp.Name(), args.to, err,
+			)
+		}
+		fmt.Printf("Identity provider '%s' has been created\n", idp.Name())
+	}
+	if args.skipGroups {
+		return nil
+	}
+
+	// Add the members of the groups that aren't yet members in the target cluster:
+	sourceGroups, err := c.GetGroups(clusterCollection, source.ID())
+	if err != nil {
+		return err
+	}
+	targetGroups, err := c.GetGroups(clusterCollection, target.ID())
+	if err != nil {
+		return err
+	}
+	members := map[string]map[string]bool{}
+	for _, group := range targetGroups {
+		members[group.ID()] = map[string]bool{}
+		group.Users().Each(func(user *cmv1.User) bool {
+			members[group.ID()][user.ID()] = true
+			return true
+		})
+	}
+	failed := false
+	for _, group := range sourceGroups {
+		group.Users().Each(func(user *cmv1.User) bool {
+			if members[group.ID()][user.ID()] {
+				return true
+			}
+			body, err := cmv1.NewUser().ID(user.ID()).Build()
+			if err == nil {
+				_, err = clusterCollection.Cluster(target.ID()).
+					Groups().
+					Group(group.ID()).
+					Users().
+					Add().
+					Body(body).
+					Send()
+			}
+			if errors.Is(err, curl.ErrNotSent) {
+				return true
+			}
+			if err != nil {
+				fmt.Fprintf(
+					os.Stderr, "Failed to add user '%s' to group '%s': %v\n",
+					user.ID(), group.ID(), err,
+				)
+				failed = true
+				return true
+			}
+			fmt.Printf("User '%s' has been added to group '%s'\n", user.ID(), group.ID())
+			return true
+		})
+	}
+	if failed {
+		return fmt.Errorf("Failed to add some users to cluster '%s'", args.to)
+	}
+	return nil
+}
+
+// needsSecrets checks if copying the given identity provider requires secrets that need to be
+// requested to the user.
+func needsSecrets(idp *cmv1.IdentityProvider) bool {
+	switch idp.Type() {
+	case cmv1.IdentityProviderTypeLDAP:
+		return idp.LDAP().BindDN() != ""
+	default:
+		return true
+	}
+}
+
+// copyIDP creates a copy of the given identity provider without the identifier, and with the
+// secrets requested to the user. For htpasswd identity providers the given users are added,
+// requesting the password of each of them.
+func copyIDP(idp *cmv1.IdentityProvider, users []*cmv1.HTPasswdUser) (result *cmv1.IdentityProvider,
+	err error) {
+	buffer := &bytes.Buffer{}
+	err = cmv1.MarshalIdentityProvider(idp, buffer)
+	if err != nil {
+		return
+	}
+	var data map[string]interface{}
+	err = json.Unmarshal(buffer.Bytes(), &data)
+	if err != nil {
+		return
+	}
+	delete(data, "id")
+	delete(data, "href")
+
+	// Request the secret, if the type of identity provider has one:
+	for field, secretField := range secretFields {
+		details, ok := data[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if field == "ldap" && details["bind_dn"] == nil {
+			continue
+		}
+		details[secretField], err = askSecret(fmt.Sprintf(
+			"Value of '%s' for identity provider '%s':",
+			secretField, idp.Name(),
+		))
+		if err != nil {
+			return
+		}
+	}
+
+	// Request the passwords of the users of htpasswd identity providers:
+	if _, ok := data["htpasswd"]; ok {
+		items := make([]interface{}, len(users))
+		for i, user := range users {
+			var password string
+			password, err = askSecret(fmt.Sprintf(
+				"Password of user '%s' for identity provider '%s':",
+				user.Username(), idp.Name(),
+			))
+			if err != nil {
+				return
+			}
+			items[i] = map[string]interface{}{
+				"username": user.Username(),
+				"password": password,
+			}
+		}
+		data["htpasswd"] = map[string]interface{}{
+			"users": map[string]interface{}{
+				"items": items,
+			},
+		}
+	}
+
+	text, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	result, err = cmv1.UnmarshalIdentityProvider(text)
+	return
+}
+
+// askSecret asks the user for a secret value that can't be empty.
+func askSecret(message string) (secret string, err error) {
+	err = survey.AskOne(
+		&survey.Password{
+			Message: message,
+		},
+		&secret,
+		survey.WithValidator(survey.Required),
+	)
+	return
+}
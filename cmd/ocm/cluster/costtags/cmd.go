@@ -0,0 +1,223 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package costtags
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+var args struct {
+	tags  []string
+	file  string
+	apply bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "cost-tags [flags] {NAME|ID|EXTERNAL_ID}",
+	Short: "Show, audit and set the cost allocation tags of a cluster",
+	Long: "Show the cost allocation tags recorded on a cluster, which are added to the AWS " +
+		"resources created for it. When desired tags are given with the '--tag' or '--file' " +
+		"options the command compares them with the recorded ones and fails if any is " +
+		"missing or different, unless the '--apply' option is used to update them.",
+	Example: `  # Show the tags of cluster 'mycluster'
+  ocm cluster cost-tags mycluster
+
+  # Check that the cluster has the tags required by the finance team
+  ocm cluster cost-tags mycluster --file finance-tags.yaml
+
+  # Add or fix the 'cost-center' tag
+  ocm cluster cost-tags mycluster --tag cost-center=1234 --apply`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+	RunE:              run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringArrayVar(
+		&args.tags,
+		"tag",
+		nil,
+		"Desired tag, in the form 'KEY=VALUE'. Can be used multiple times.",
+	)
+	flags.StringVar(
+		&args.file,
+		"file",
+		"",
+		"YAML or JSON file containing the desired tags as a map of keys to values.",
+	)
+	flags.BoolVar(
+		&args.apply,
+		"apply",
+		false,
+		"Update the tags recorded on the cluster so that they contain the desired ones.",
+	)
+	readonly.MarkFlag(Cmd, "apply")
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Read and validate the desired tags before sending any request:
+	desired, err := desiredTags()
+	if err != nil {
+		return err
+	}
+	if args.apply && len(desired) == 0 {
+		return fmt.Errorf("Option '--apply' requires desired tags, use '--tag' or '--file'")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	if cluster.CloudProvider().ID() != c.ProviderAWS {
+		return fmt.Errorf(
+			"Cost allocation tags are only recorded for AWS clusters, but cluster '%s' "+
+				"uses provider '%s'",
+			cluster.Name(), cluster.CloudProvider().ID(),
+		)
+	}
+	if !cluster.CCS().Enabled() {
+		fmt.Fprintf(
+			os.Stderr,
+			"WARNING: Cluster '%s' doesn't use a customer cloud subscription, so the "+
+				"tags will not appear in the cost reports of your AWS account\n",
+			cluster.Name(),
+		)
+	}
+	recorded := cluster.AWS().Tags()
+
+	// If there are no desired tags just show the recorded ones:
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if len(desired) == 0 {
+		keys := make([]string, 0, len(recorded))
+		for key := range recorded {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(writer, "KEY\tVALUE\n")
+		for _, key := range keys {
+			fmt.Fprintf(writer, "%s\t%s\n", key, recorded[key])
+		}
+		return writer.Flush()
+	}
+
+	// Show the differences:
+	pending := 0
+	fmt.Fprintf(writer, "KEY\tDESIRED\tRECORDED\tSTATUS\n")
+	for _, diff := range c.DiffTags(desired, recorded) {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", diff.Key, diff.Desired, diff.Recorded, diff.Status)
+		if diff.Status == c.TagMissing || diff.Status == c.TagDifferent {
+			pending++
+		}
+	}
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+	if pending == 0 {
+		return nil
+	}
+	if !args.apply {
+		return fmt.Errorf(
+			"%d tags of cluster '%s' don't have the desired values, use '--apply' to "+
+				"update them",
+			pending, cluster.Name(),
+		)
+	}
+
+	// Update the tags, preserving the ones that aren't in the desired set:
+	merged := map[string]string{}
+	for key, value := range recorded {
+		merged[key] = value
+	}
+	for key, value := range desired {
+		merged[key] = value
+	}
+	patch, err := cmv1.NewCluster().
+		AWS(cmv1.NewAWS().Tags(merged)).
+		Build()
+	if err != nil {
+		return err
+	}
+	_, err = connection.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).Update().
+		Body(patch).
+		Send()
+	if err != nil {
+		return fmt.Errorf("Can't update tags of cluster '%s': %w", cluster.Name(), err)
+	}
+	fmt.Printf(
+		"\nUpdated %d tags of cluster '%s'. Resources created before the update may "+
+			"take some time to show the new values in the cost reports.\n",
+		pending, cluster.Name(),
+	)
+	return nil
+}
+
+// desiredTags returns the tags given with the '--file' and '--tag' options, the latter taking
+// precedence.
+func desiredTags() (tags map[string]string, err error) {
+	tags = map[string]string{}
+	if args.file != "" {
+		// #nosec G304
+		data, err := ioutil.ReadFile(args.file)
+		if err != nil {
+			return nil, fmt.Errorf("Can't read tags file '%s': %v", args.file, err)
+		}
+		err = yaml.Unmarshal(data, &tags)
+		if err != nil {
+			return nil, fmt.Errorf("Can't parse tags file '%s': %v", args.file, err)
+		}
+	}
+	for _, text := range args.tags {
+		key, value := arguments.ParseNameValuePair(text)
+		tags[key] = value
+	}
+	err = c.ValidateTags(tags)
+	return
+}
@@ -0,0 +1,308 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/utils"
+)
+
+var args struct {
+	since string
+	until string
+	json  bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "events [flags] {NAME|ID|EXTERNAL_ID}",
+	Short: "Show the timeline of events of a cluster",
+	Long: "Show in chronological order the events of a cluster: its creation and " +
+		"expiration, the upgrade policies and their state, the limited support reasons " +
+		"and the service logs. The API doesn't keep the history of the states of the " +
+		"cluster, those are visible only through the service logs.",
+	Example: `  # Show the events of cluster 'mycluster' during the last day
+  ocm cluster events mycluster --since 24h
+
+  # Show the events in JSON format, for example for a postmortem document
+  ocm cluster events mycluster --json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+	RunE:              run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.since,
+		"since",
+		"",
+		"Only show the events that happened after this time. It can be a time in "+
+			"RFC3339 format, like '2022-01-01T10:00:00Z', or a duration relative to the "+
+			"current time, like '2h' or '7d'.",
+	)
+	flags.StringVar(
+		&args.until,
+		"until",
+		"",
+		"Only show the events that happened before this time. The format is the same "+
+			"than for the '--since' option.",
+	)
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the events in JSON format",
+	)
+}
+
+// Sources of events:
+const (
+	sourceCluster        = "cluster"
+	sourceUpgrade        = "upgrade"
+	sourceLimitedSupport = "limited_support"
+	sourceServiceLog     = "service_log"
+)
+
+// Event is an entry of the timeline.
+type Event struct {
+	Time     time.Time `json:"time"`
+	Source   string    `json:"source"`
+	Severity string    `json:"severity,omitempty"`
+	Summary  string    `json:"summary"`
+	Details  string    `json:"details,omitempty"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	now := time.Now()
+	since, err := utils.ParseTime(args.since, now)
+	if err != nil {
+		return fmt.Errorf("Invalid value for option '--since': %v", err)
+	}
+	until, err := utils.ParseTime(args.until, now)
+	if err != nil {
+		return fmt.Errorf("Invalid value for option '--until': %v", err)
+	}
+	if !since.IsZero() && !until.IsZero() && since.After(until) {
+		return fmt.Errorf("Value of option '--since' must not be later than '--until'")
+	}
+
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	// Collect the events from all the sources:
+	events := clusterEvents(cluster)
+	upgradeEvents, err := upgradeEvents(connection, cluster)
+	if err != nil {
+		return err
+	}
+	events = append(events, upgradeEvents...)
+	limitedSupportEvents, err := limitedSupportEvents(connection, cluster)
+	if err != nil {
+		return err
+	}
+	events = append(events, limitedSupportEvents...)
+	serviceLogEvents, err := serviceLogEvents(connection, cluster)
+	if err != nil {
+		return err
+	}
+	events = append(events, serviceLogEvents...)
+
+	// Filter and sort the events:
+	timeline := []*Event{}
+	for _, event := range events {
+		if !since.IsZero() && event.Time.Before(since) {
+			continue
+		}
+		if !until.IsZero() && event.Time.After(until) {
+			continue
+		}
+		timeline = append(timeline, event)
+	}
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Time.Before(timeline[j].Time)
+	})
+
+	// Write the timeline:
+	if args.json {
+		data, err := json.Marshal(timeline)
+		if err != nil {
+			return fmt.Errorf("Can't marshal events: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "TIME\tSOURCE\tSEVERITY\tSUMMARY\n")
+	for _, event := range timeline {
+		severity := event.Severity
+		if severity == "" {
+			severity = "-"
+		}
+		fmt.Fprintf(
+			writer, "%s\t%s\t%s\t%s\n",
+			event.Time.UTC().Format(time.RFC3339), event.Source, severity, event.Summary,
+		)
+	}
+	return writer.Flush()
+}
+
+// clusterEvents returns the events that can be calculated from the cluster itself.
+func clusterEvents(cluster *cmv1.Cluster) []*Event {
+	var result []*Event
+	if created, ok := cluster.GetCreationTimestamp(); ok {
+		result = append(result, &Event{
+			Time:    created,
+			Source:  sourceCluster,
+			Summary: "Cluster created",
+		})
+	}
+	if expires, ok := cluster.GetExpirationTimestamp(); ok {
+		result = append(result, &Event{
+			Time:    expires,
+			Source:  sourceCluster,
+			Summary: "Cluster expires",
+		})
+	}
+	return result
+}
+
+// upgradeEvents returns an event for the next run of each upgrade policy, including its state.
+// Policies that don't have a next run are ignored.
+func upgradeEvents(connection *sdk.Connection, cluster *cmv1.Cluster) (result []*Event,
+	err error) {
+	policiesClient := connection.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
+		UpgradePolicies()
+	response, err := policiesClient.List().Send()
+	if err != nil {
+		err = fmt.Errorf("Can't retrieve upgrade policies: %v", err)
+		return
+	}
+	for _, policy := range response.Items().Slice() {
+		nextRun, ok := policy.GetNextRun()
+		if !ok {
+			continue
+		}
+		stateResponse, err := policiesClient.UpgradePolicy(policy.ID()).State().Get().Send()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"Can't retrieve state of upgrade policy '%s': %v",
+				policy.ID(), err,
+			)
+		}
+		state := stateResponse.Body()
+		result = append(result, &Event{
+			Time:   nextRun,
+			Source: sourceUpgrade,
+			Summary: fmt.Sprintf(
+				"Upgrade to version '%s' is %s",
+				policy.Version(), state.Value(),
+			),
+			Details: state.Description(),
+		})
+	}
+	return
+}
+
+// limitedSupportEvents returns an event for each limited support reason.
+func limitedSupportEvents(connection *sdk.Connection, cluster *cmv1.Cluster) (result []*Event,
+	err error) {
+	response, err := connection.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
+		LimitedSupportReasons().
+		List().
+		Send()
+	if err != nil {
+		err = fmt.Errorf("Can't retrieve limited support reasons: %v", err)
+		return
+	}
+	response.Items().Each(func(reason *cmv1.LimitedSupportReason) bool {
+		result = append(result, &Event{
+			Time:     reason.CreationTimestamp(),
+			Source:   sourceLimitedSupport,
+			Severity: "Warning",
+			Summary:  reason.Summary(),
+			Details:  reason.Details(),
+		})
+		return true
+	})
+	return
+}
+
+// serviceLogEvents returns an event for each service log entry of the cluster.
+func serviceLogEvents(connection *sdk.Connection, cluster *cmv1.Cluster) (result []*Event,
+	err error) {
+	request := connection.ServiceLogs().V1().ClusterLogs().List().
+		Search(fmt.Sprintf("cluster_id = '%s'", cluster.ID())).
+		Order("timestamp asc")
+	size := 100
+	index := 1
+	for {
+		response, err := request.Size(size).Page(index).Send()
+		if err != nil {
+			return nil, fmt.Errorf("Can't retrieve service logs: %v", err)
+		}
+		response.Items().Each(func(entry *slv1.LogEntry) bool {
+			result = append(result, &Event{
+				Time:     entry.Timestamp(),
+				Source:   sourceServiceLog,
+				Severity: string(entry.Severity()),
+				Summary:  entry.Summary(),
+				Details:  entry.Description(),
+			})
+			return true
+		})
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+	return
+}
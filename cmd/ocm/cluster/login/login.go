@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
@@ -48,6 +49,7 @@ var Cmd = &cobra.Command{
 
 		return nil
 	},
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
 }
 
 func init() {
@@ -0,0 +1,359 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/errors"
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/poll"
+	"github.com/openshift-online/ocm-cli/pkg/utils"
+)
+
+var args struct {
+	since    string
+	until    string
+	tail     int
+	output   string
+	follow   bool
+	interval time.Duration
+}
+
+var Cmd = &cobra.Command{
+	Use:     "logs [flags] {NAME|ID|EXTERNAL_ID} [LOG_ID]",
+	Aliases: []string{"console-logs"},
+	Short:   "List and download the logs of a cluster",
+	Long: "List the logs that OCM exposes for a cluster, or download one of them when the " +
+		"log identifier is given. Any log identifier returned by the server can be used, " +
+		"not only the install and uninstall logs of classic clusters but also, for example, " +
+		"the audit and console logs of hosted control plane clusters. With the '--follow' " +
+		"option the command keeps running and writes the new lines of the log as soon " +
+		"as the server sends them, or periodically if the server doesn't support streaming.",
+	Example: `  # List the logs available for cluster 'mycluster'
+  ocm cluster logs mycluster
+
+  # Download the install log of the last two hours
+  ocm cluster logs mycluster install --since 2h --output install.log
+
+  # Show the last 100 lines of the audit log of a hosted control plane cluster
+  ocm cluster logs mycluster audit --tail 100
+
+  # Follow the install log while the cluster is being installed
+  ocm cluster logs mycluster install --follow`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+	RunE:              run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.since,
+		"since",
+		"",
+		"Only show the lines of the log written after this time. It can be a time in "+
+			"RFC3339 format, like '2022-01-01T10:00:00Z', or a duration relative to the "+
+			"current time, like '2h' or '7d'.",
+	)
+	flags.StringVar(
+		&args.until,
+		"until",
+		"",
+		"Only show the lines of the log written before this time. The format is the same "+
+			"than for the '--since' option.",
+	)
+	flags.IntVar(
+		&args.tail,
+		"tail",
+		0,
+		"Only retrieve this number of lines from the end of the log.",
+	)
+	flags.StringVarP(
+		&args.output,
+		"output",
+		"o",
+		"",
+		"Write the log to this file instead of to the standard output.",
+	)
+	flags.BoolVarP(
+		&args.follow,
+		"follow",
+		"f",
+		false,
+		"Keep running and write the new lines of the log as they are added.",
+	)
+	flags.DurationVar(
+		&args.interval,
+		"interval",
+		10*time.Second,
+		"Time between checks for new lines when using the '--follow' option with a server "+
+			"that doesn't support streaming.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	now := time.Now()
+	since, err := utils.ParseTime(args.since, now)
+	if err != nil {
+		return fmt.Errorf("Invalid value for option '--since': %v", err)
+	}
+	until, err := utils.ParseTime(args.until, now)
+	if err != nil {
+		return fmt.Errorf("Invalid value for option '--until': %v", err)
+	}
+	if !since.IsZero() && !until.IsZero() && since.After(until) {
+		return fmt.Errorf("Value of option '--since' must not be later than '--until'")
+	}
+	if args.tail < 0 {
+		return fmt.Errorf("Option '--tail' must be a positive number")
+	}
+	if args.follow && !until.IsZero() {
+		return fmt.Errorf("Options '--follow' and '--until' can't be used together")
+	}
+	if args.interval <= 0 {
+		return fmt.Errorf("Option '--interval' must be a positive duration")
+	}
+	if len(argv) == 1 {
+		for _, name := range []string{"since", "until", "tail", "output", "follow", "interval"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("Option '--%s' can only be used with a log identifier", name)
+			}
+		}
+	}
+
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	logsPath := fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/logs", cluster.ID())
+
+	// Without a log identifier we just list the logs that are available:
+	if len(argv) == 1 {
+		response, err := connection.ClustersMgmt().V1().Clusters().
+			Cluster(cluster.ID()).
+			Logs().
+			List().
+			Send()
+		if err != nil {
+			return fmt.Errorf("Can't retrieve logs for cluster '%s': %v", clusterKey, err)
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "ID\tHREF\n")
+		response.Items().Each(func(log *cmv1.Log) bool {
+			fmt.Fprintf(writer, "%s\t%s\n", log.ID(), log.HREF())
+			return true
+		})
+		return writer.Flush()
+	}
+
+	// Retrieve the log, unless we are going to follow it:
+	logID := argv[1]
+	var log *cmv1.Log
+	if !args.follow {
+		log, err = getLog(connection, logsPath, logID, args.tail)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Write the log, filtering the lines that are outside of the requested time range:
+	var out io.Writer = os.Stdout
+	if args.output != "" {
+		file, err := os.Create(args.output)
+		if err != nil {
+			return fmt.Errorf("Can't create file '%s': %v", args.output, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if args.follow {
+		return follow(connection, logsPath, logID, out, since)
+	}
+	err = writeLines(out, log.Content(), since, until)
+	if err != nil {
+		return fmt.Errorf("Can't write log: %v", err)
+	}
+	if args.output != "" {
+		fmt.Printf("Log '%s' saved to '%s'\n", logID, args.output)
+	}
+
+	return nil
+}
+
+// getLog retrieves the log with the given identifier. The SDK only has explicit support for the install
+// and uninstall logs, so we use a raw request in order to also support other logs that the server
+// may expose.
+func getLog(connection *sdk.Connection, logsPath, logID string, tail int) (*cmv1.Log, error) {
+	request := connection.Get().Path(logsPath + "/" + logID)
+	if tail > 0 {
+		request.Parameter("tail", tail)
+	}
+	response, err := request.Send()
+	if err != nil {
+		return nil, fmt.Errorf("Can't retrieve log '%s': %v", logID, err)
+	}
+	if response.Status() >= http.StatusBadRequest {
+		apiErr, err := errors.UnmarshalErrorStatus(response.Bytes(), response.Status())
+		if err != nil {
+			return nil, fmt.Errorf(
+				"Can't retrieve log '%s': status is %d",
+				logID, response.Status(),
+			)
+		}
+		return nil, fmt.Errorf("Can't retrieve log '%s': %v", logID, apiErr)
+	}
+	log, err := cmv1.UnmarshalLog(response.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse log '%s': %v", logID, err)
+	}
+	return log, nil
+}
+
+// follow writes the lines of the log as they are added. Servers that support streaming send the
+// new lines as events. For other servers the complete log is retrieved periodically and only the
+// complete lines that weren't written before are written. It only returns when the log can't be
+// retrieved.
+func follow(connection *sdk.Connection, logsPath, logID string, out io.Writer,
+	since time.Time) error {
+	ctx := context.Background()
+	query := url.Values{}
+	query.Set("follow", "true")
+	if args.tail > 0 {
+		query.Set("tail", strconv.Itoa(args.tail))
+	}
+	err := ocm.Stream(ctx, connection, logsPath+"/"+logID, query, func(event *ocm.Event) error {
+		return writeLines(out, string(event.Data), since, time.Time{})
+	})
+	if err != ocm.ErrStreamingUnsupported {
+		return err
+	}
+
+	written := -1
+	return poll.Until(ctx, args.interval, func(ctx context.Context) (bool, error) {
+		log, err := getLog(connection, logsPath, logID, 0)
+		if err != nil {
+			return false, err
+		}
+		content := log.Content()
+		end := strings.LastIndex(content, "\n") + 1
+		var chunk string
+		switch {
+		case written < 0:
+			chunk = lastLines(content[0:end], args.tail)
+		case end >= written:
+			chunk = content[written:end]
+		default:
+			// The log is shorter than before, so it has been truncated or replaced
+			// and we need to write it again:
+			chunk = content[0:end]
+		}
+		written = end
+		return false, writeLines(out, chunk, since, time.Time{})
+	})
+}
+
+// lastLines returns the given number of lines from the end of the content, or all the content if
+// the number is zero.
+func lastLines(content string, count int) string {
+	if count <= 0 {
+		return content
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[0 : len(lines)-1]
+	}
+	if len(lines) > count {
+		lines = lines[len(lines)-count:]
+	}
+	return strings.Join(lines, "")
+}
+
+// writeLines writes the lines of the given content whose time stamp is inside the given range.
+// Lines that don't contain a time stamp, like the continuation lines of multi-line messages, are
+// written only if the previous line with a time stamp was.
+func writeLines(out io.Writer, content string, since, until time.Time) error {
+	writer := bufio.NewWriter(out)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	include := since.IsZero()
+	for scanner.Scan() {
+		line := scanner.Text()
+		match := timestampRE.FindString(line)
+		if match != "" {
+			stamp, err := time.Parse(time.RFC3339Nano, strings.Replace(match, " ", "T", 1))
+			if err == nil {
+				include = (since.IsZero() || !stamp.Before(since)) &&
+					(until.IsZero() || !stamp.After(until))
+			}
+		}
+		if !include {
+			continue
+		}
+		_, err := fmt.Fprintln(writer, line)
+		if err != nil {
+			return err
+		}
+	}
+	err := scanner.Err()
+	if err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// timestampRE matches the RFC3339 time stamps that appear in the lines of the logs, for example
+// 'time="2022-01-01T10:00:00Z" level=info ...'.
+var timestampRE = regexp.MustCompile(
+	`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`,
+)
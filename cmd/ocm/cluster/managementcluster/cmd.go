@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managementcluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	json bool
+}
+
+var Cmd = &cobra.Command{
+	Use:     "management-cluster {NAME|ID|EXTERNAL_ID}",
+	Aliases: []string{"mc"},
+	Short:   "Show the management and service clusters of a hosted control plane",
+	Long: "Show the management cluster that runs the hosted control plane of a cluster, the " +
+		"namespace that contains it and the service cluster that manages the management " +
+		"cluster. The details of the management and service clusters are only visible to " +
+		"privileged users.",
+	Example: `  # Show where the control plane of cluster 'mycluster' runs
+  ocm cluster management-cluster mycluster
+
+  # Show the same details in JSON format
+  ocm cluster mc mycluster --json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+	RunE:              run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the details in JSON format.",
+	)
+}
+
+// Placement describes where the hosted control plane of a cluster runs.
+type Placement struct {
+	ClusterID         string `json:"cluster_id"`
+	ClusterName       string `json:"cluster_name"`
+	ManagementCluster string `json:"management_cluster"`
+	ServiceCluster    string `json:"service_cluster,omitempty"`
+	HCPNamespace      string `json:"hcp_namespace,omitempty"`
+	Region            string `json:"region,omitempty"`
+	Sector            string `json:"sector,omitempty"`
+	Status            string `json:"status,omitempty"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	details, err := c.GetClusterDetails(connection, cluster.ID())
+	if err != nil {
+		return err
+	}
+	if !details.Hosted() {
+		return fmt.Errorf("Cluster '%s' doesn't have a hosted control plane", clusterKey)
+	}
+	if details.ManagementCluster() == "" {
+		return fmt.Errorf(
+			"Cluster '%s' hasn't been assigned a management cluster yet",
+			clusterKey,
+		)
+	}
+	placement := &Placement{
+		ClusterID:         cluster.ID(),
+		ClusterName:       cluster.Name(),
+		ManagementCluster: details.ManagementCluster(),
+		HCPNamespace:      details.HCPNamespace(),
+	}
+
+	// The fleet management API is only available to privileged users, so if it fails we still
+	// report what the clusters management API knows:
+	management, err := c.GetManagementCluster(connection, placement.ManagementCluster)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else {
+		placement.ServiceCluster = management.ServiceCluster()
+		placement.Region = management.Region
+		placement.Sector = management.Sector
+		placement.Status = management.Status
+	}
+
+	if args.json {
+		data, err := json.Marshal(placement)
+		if err != nil {
+			return fmt.Errorf("Can't marshal details: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Cluster:\t%s (%s)\n", placement.ClusterName, placement.ClusterID)
+	fmt.Fprintf(writer, "Management cluster:\t%s\n", placement.ManagementCluster)
+	fmt.Fprintf(writer, "Service cluster:\t%s\n", orUnknown(placement.ServiceCluster))
+	fmt.Fprintf(writer, "HCP namespace:\t%s\n", orUnknown(placement.HCPNamespace))
+	if placement.Region != "" {
+		fmt.Fprintf(writer, "Region:\t%s\n", placement.Region)
+	}
+	if placement.Sector != "" {
+		fmt.Fprintf(writer, "Sector:\t%s\n", placement.Sector)
+	}
+	if placement.Status != "" {
+		fmt.Fprintf(writer, "Status:\t%s\n", placement.Status)
+	}
+	return writer.Flush()
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
@@ -0,0 +1,283 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migratenetwork
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+	"github.com/openshift-online/ocm-cli/pkg/resume"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var args struct {
+	api        string
+	apps       string
+	addRouter  string
+	labelMatch string
+	dryRun     bool
+	restart    bool
+	timeout    time.Duration
+}
+
+// interval is the time between checks of the result of each step.
+const interval = 10 * time.Second
+
+var Cmd = &cobra.Command{
+	Use:   "migrate-network [flags] {NAME|ID|EXTERNAL_ID}",
+	Short: "Change the network exposure of a cluster step by step",
+	Long: "Change the network exposure of the API server and the routers of a cluster. The " +
+		"command calculates the steps needed, applies them in a safe order, and after each one " +
+		"waits till the cluster reports the new configuration. The progress is saved, so if a " +
+		"step fails the same command can be run again and it will continue with the steps " +
+		"that haven't been completed yet. Steps that are already in place are skipped.",
+	Example: `  # Make the API server and the default router of cluster 'mycluster' private
+  ocm cluster migrate-network mycluster --api private --apps private
+
+  # Add a public router for the routes labeled 'exposure=public'
+  ocm cluster migrate-network mycluster --add-router public --router-label-match exposure=public
+
+  # Show the steps without changing the cluster
+  ocm cluster migrate-network mycluster --api private --dry-run`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+	RunE:              run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.api,
+		"api",
+		"",
+		"New exposure of the API server, 'private' or 'public'.",
+	)
+	flags.StringVar(
+		&args.apps,
+		"apps",
+		"",
+		"New exposure of the default router of the applications, 'private' or 'public'.",
+	)
+	flags.StringVar(
+		&args.addRouter,
+		"add-router",
+		"",
+		"Add an additional router for the applications, 'private' or 'public'.",
+	)
+	flags.StringVar(
+		&args.labelMatch,
+		"router-label-match",
+		"",
+		"Comma separated list of 'key=value' labels of the routes exposed by the additional "+
+			"router. Requires the '--add-router' option.",
+	)
+	dryrun.BoolVar(
+		flags,
+		&args.dryRun,
+		"Show the steps that would be applied without changing the cluster.",
+	)
+	flags.BoolVar(
+		&args.restart,
+		"restart",
+		false,
+		"Forget the saved progress of a previous run and check all the steps again.",
+	)
+	flags.DurationVar(
+		&args.timeout,
+		"timeout",
+		10*time.Minute,
+		"Maximum time to wait till the cluster reports the result of each step.",
+	)
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Check the options:
+	migration := c.NetworkMigration{}
+	var err error
+	migration.API, err = parseListening("api", args.api)
+	if err != nil {
+		return err
+	}
+	migration.Apps, err = parseListening("apps", args.apps)
+	if err != nil {
+		return err
+	}
+	migration.Router, err = parseListening("add-router", args.addRouter)
+	if err != nil {
+		return err
+	}
+	if args.labelMatch != "" {
+		if migration.Router == "" {
+			return fmt.Errorf("Option '--router-label-match' requires option '--add-router'")
+		}
+		migration.RouterRouteSelectors = map[string]string{}
+		for _, labelMatch := range strings.Split(args.labelMatch, ",") {
+			if !strings.Contains(labelMatch, "=") {
+				return fmt.Errorf("Expected key=value format for router-label-match")
+			}
+			tokens := strings.Split(labelMatch, "=")
+			migration.RouterRouteSelectors[strings.TrimSpace(tokens[0])] = strings.TrimSpace(tokens[1])
+		}
+	}
+	if migration.API == "" && migration.Apps == "" && migration.Router == "" {
+		return fmt.Errorf(
+			"At least one of the '--api', '--apps' or '--add-router' options is required",
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	if cluster.State() != cmv1.ClusterStateReady {
+		return fmt.Errorf(
+			"Cluster '%s' is in state '%s', its network can only be changed when it is ready",
+			clusterKey, cluster.State(),
+		)
+	}
+	clusters := connection.ClustersMgmt().V1().Clusters()
+	steps, err := c.PlanNetworkMigration(clusters, cluster.ID(), migration)
+	if err != nil {
+		return err
+	}
+
+	// The progress is identified by the server, the cluster and the requested changes, so that
+	// a different migration of the same cluster starts from the beginning:
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
+	progress := resume.NewSteps(
+		"migrate-network", cfg.URL, cluster.ID(),
+		string(migration.API), string(migration.Apps), string(migration.Router),
+		c.FormatRouteSelectors(migration.RouterRouteSelectors),
+	)
+	if args.restart {
+		err = progress.Done()
+		if err != nil {
+			return err
+		}
+	}
+	completed, err := progress.Completed()
+	if err != nil {
+		return err
+	}
+	if completed >= len(steps) {
+		completed = 0
+	}
+	if completed > 0 && !args.dryRun {
+		fmt.Printf(
+			"Resuming network migration of cluster '%s' after step %d of %d\n",
+			clusterKey, completed, len(steps),
+		)
+	}
+
+	for i, step := range steps {
+		number := i + 1
+		if i < completed {
+			fmt.Printf("Step %d of %d: %s: completed in a previous run\n", number, len(steps),
+				step.Description)
+			continue
+		}
+		done, err := step.Done()
+		if err != nil {
+			return fmt.Errorf("Step %d of %d failed: %v", number, len(steps), err)
+		}
+		if done {
+			fmt.Printf("Step %d of %d: %s: already in place\n", number, len(steps), step.Description)
+		} else if args.dryRun {
+			fmt.Printf("Step %d of %d: %s: would be applied\n", number, len(steps), step.Description)
+			continue
+		} else {
+			fmt.Printf("Step %d of %d: %s\n", number, len(steps), step.Description)
+			err = step.Apply()
+			if err != nil {
+				return fmt.Errorf(
+					"Step %d of %d failed: %v, run the same command again to retry it",
+					number, len(steps), err,
+				)
+			}
+			err = c.VerifyNetworkStep(step, args.timeout, interval)
+			if err != nil {
+				return fmt.Errorf(
+					"Step %d of %d failed: %v, run the same command again to retry it",
+					number, len(steps), err,
+				)
+			}
+		}
+		if !args.dryRun {
+			err = progress.Save(number)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if args.dryRun {
+		return nil
+	}
+	err = progress.Done()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Network migration of cluster '%s' completed\n", clusterKey)
+	return nil
+}
+
+// parseListening converts the value of the given option to a listening method.
+func parseListening(option, value string) (cmv1.ListeningMethod, error) {
+	switch value {
+	case "":
+		return "", nil
+	case "private":
+		return cmv1.ListeningMethodInternal, nil
+	case "public":
+		return cmv1.ListeningMethodExternal, nil
+	default:
+		return "", fmt.Errorf(
+			"Invalid value '%s' for option '--%s', valid values are 'private' and 'public'",
+			value, option,
+		)
+	}
+}
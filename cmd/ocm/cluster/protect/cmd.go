@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protect
+
+import (
+	"fmt"
+	"net/http"
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/protection"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+var args struct {
+	remove bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "protect [flags] {NAME|ID|EXTERNAL_ID}",
+	Short: "Mark a cluster as a production cluster",
+	Long: "Add the '" + protection.LabelKey + "=" + protection.LabelValue + "' label to the " +
+		"subscription of a cluster. When the 'production_interlock' configuration setting " +
+		"of the current context is 'confirm', commands that try to change a cluster with " +
+		"that label ask for confirmation when running in a terminal, and otherwise fail " +
+		"unless the '--" + protection.FlagName + "' option is used. When it is 'flag' the " +
+		"option is always required.",
+	Example: `  # Enable the interlock for the current context
+  ocm config set production_interlock confirm
+
+  # Mark cluster 'mycluster' as a production cluster
+  ocm cluster protect mycluster
+
+  # Remove the mark
+  ocm cluster protect mycluster --remove --i-know-this-is-production`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+	RunE:              run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.remove,
+		"remove",
+		false,
+		"Remove the production mark. This requires the '--"+protection.FlagName+"' option.",
+	)
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+	if args.remove && !protection.Confirmed() {
+		return fmt.Errorf(
+			"Removing the production mark requires the '--%s' option",
+			protection.FlagName,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	subID := cluster.Subscription().ID()
+	if subID == "" {
+		return fmt.Errorf("Cluster '%s' doesn't have a subscription", clusterKey)
+	}
+	labels := connection.AccountsMgmt().V1().Subscriptions().Subscription(subID).Labels()
+
+	// Remove the label:
+	if args.remove {
+		response, err := labels.Labels(protection.LabelKey).Delete().Send()
+		if response != nil && response.Status() == http.StatusNotFound {
+			fmt.Printf("Cluster '%s' isn't marked as a production cluster\n", cluster.Name())
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Can't remove production mark: %w", err)
+		}
+		fmt.Printf("Cluster '%s' is no longer marked as a production cluster\n", cluster.Name())
+		return nil
+	}
+
+	// Add the label, or update it if it has a different value:
+	existing, err := c.ListSubscriptionLabels(connection, subID)
+	if err != nil {
+		return err
+	}
+	label, err := amv1.NewLabel().
+		Key(protection.LabelKey).
+		Value(protection.LabelValue).
+		Build()
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, item := range existing {
+		if item.Key() != protection.LabelKey {
+			continue
+		}
+		if item.Value() == protection.LabelValue {
+			fmt.Printf("Cluster '%s' is already marked as a production cluster\n", cluster.Name())
+			return nil
+		}
+		found = true
+	}
+	if found {
+		_, err = labels.Labels(protection.LabelKey).Update().Body(label).Send()
+	} else {
+		_, err = labels.Add().Body(label).Send()
+	}
+	if err != nil {
+		return fmt.Errorf("Can't add production mark: %w", err)
+	}
+	fmt.Printf("Cluster '%s' is now marked as a production cluster\n", cluster.Name())
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/pullsecret"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+var args struct {
+	file    string
+	current string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "rotate [flags] {NAME|ID|EXTERNAL_ID}",
+	Short: "Rotate the pull secret of a cluster",
+	Long: "Validate a new pull secret for a cluster and show which registries change " +
+		"compared to the current one. The clusters API doesn't support updating the " +
+		"pull secret, so the command also prints the command that applies it inside " +
+		"the cluster. By default the new pull secret is compared with the one that OCM " +
+		"generates for the current user, use the '--current' option to compare with a " +
+		"different one.",
+	Example: `  # Check the new pull secret for cluster 'mycluster'
+  ocm cluster pull-secret rotate mycluster --file pull-secret.json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+	RunE:              run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.file,
+		"file",
+		"",
+		"File containing the new pull secret, in docker configuration JSON format.",
+	)
+	flags.StringVar(
+		&args.current,
+		"current",
+		"",
+		"File containing the current pull secret of the cluster. If not given the "+
+			"pull secret that OCM generates for the current user is used.",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("file")
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Read and validate the new pull secret before sending any request:
+	updated, err := readFile(args.file)
+	if err != nil {
+		return err
+	}
+	var current pullsecret.PullSecret
+	if args.current != "" {
+		current, err = readFile(args.current)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	// If the current pull secret wasn't given use the one that OCM generates for the user:
+	if current == nil {
+		response, err := connection.AccountsMgmt().V1().AccessToken().Post().Send()
+		if err != nil {
+			return fmt.Errorf("Can't retrieve current pull secret: %w", err)
+		}
+		current = pullsecret.PullSecret{}
+		for registry, auth := range response.Body().Auths() {
+			current[registry] = &pullsecret.Credentials{
+				Auth:  auth.Auth(),
+				Email: auth.Email(),
+			}
+		}
+	}
+
+	// Show the changes:
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "REGISTRY\tCHANGE\n")
+	for _, change := range pullsecret.Diff(current, updated) {
+		fmt.Fprintf(writer, "%s\t%s\n", change.Registry, change.Change)
+	}
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	// Explain how to apply the new pull secret:
+	fmt.Printf(
+		"\nThe API doesn't support updating the pull secret of cluster '%s', to apply "+
+			"it run the following command with cluster administrator permissions:\n\n"+
+			"  oc set data secret/pull-secret -n openshift-config "+
+			"--from-file=.dockerconfigjson=%s\n",
+		cluster.Name(), args.file,
+	)
+	return nil
+}
+
+func readFile(file string) (result pullsecret.PullSecret, err error) {
+	// #nosec G304
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		err = fmt.Errorf("Can't read pull secret file '%s': %v", file, err)
+		return
+	}
+	result, err = pullsecret.Parse(data)
+	if err != nil {
+		err = fmt.Errorf("Invalid pull secret file '%s': %v", file, err)
+	}
+	return
+}
@@ -0,0 +1,200 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rightsizing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	cluster string
+	target  int
+	json    bool
+}
+
+// staleAge is the age of the metrics after which a warning is written, as the recommendations
+// may no longer match the usage of the cluster.
+const staleAge = 24 * time.Hour
+
+var Cmd = &cobra.Command{
+	Use:   "rightsizing --cluster={NAME|ID|EXTERNAL_ID}",
+	Short: "Recommend machine pool sizes for a cluster",
+	Long: "Compare the CPU and memory usage reported by the telemetry of a cluster with the\n" +
+		"capacity of its machine pools, and recommend the number of replicas, or the\n" +
+		"autoscaling limits, that would bring the usage of the most used resource close to\n" +
+		"the target utilization. When one resource is used much more than the other an\n" +
+		"instance type optimized for that resource is recommended as well.\n" +
+		"\n" +
+		"The recommendations are based on the last metrics reported by the cluster, so\n" +
+		"review them before applying them.",
+	Example: "  # Show the recommendations for a cluster:\n" +
+		"  ocm cluster rightsizing --cluster=mycluster\n" +
+		"\n" +
+		"  # Use a lower target utilization and write the result in JSON format:\n" +
+		"  ocm cluster rightsizing --cluster=mycluster --target=50 --json",
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.cluster,
+		"cluster",
+		"",
+		"Name, identifier or external identifier of the cluster (required).",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("cluster")
+	Cmd.RegisterFlagCompletionFunc("cluster", completion.Clusters)
+
+	flags.IntVar(
+		&args.target,
+		"target",
+		70,
+		"Target utilization of the most used resource, as a percentage.",
+	)
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the report in JSON format.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	if !c.IsValidClusterKey(args.cluster) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			args.cluster,
+		)
+	}
+	if args.target <= 0 || args.target > 100 {
+		return fmt.Errorf("Option '--target' must be between 1 and 100")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Get the cluster, the metrics of its subscription and its machine pools:
+	cluster, err := c.GetCluster(connection, args.cluster)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", args.cluster, err)
+	}
+	subID := cluster.Subscription().ID()
+	if subID == "" {
+		return fmt.Errorf("Cluster '%s' doesn't have a subscription", cluster.Name())
+	}
+	subResponse, err := connection.AccountsMgmt().V1().Subscriptions().Subscription(subID).Get().
+		Send()
+	if err != nil {
+		return fmt.Errorf("Failed to get subscription '%s': %v", subID, err)
+	}
+	metrics := subResponse.Body().Metrics()
+	if len(metrics) == 0 || !c.HasUsageMetrics(metrics[0]) {
+		return fmt.Errorf("Cluster '%s' doesn't report CPU and memory metrics", cluster.Name())
+	}
+	pools, err := c.GetMachinePools(connection.ClustersMgmt().V1().Clusters(), cluster.ID())
+	if err != nil {
+		return err
+	}
+
+	// Calculate and write the recommendations:
+	report := c.Rightsize(cluster, metrics[0], pools, float64(args.target)/100)
+	updated := metrics[0].Cpu().UpdatedTimestamp()
+	if !updated.IsZero() && time.Since(updated) > staleAge {
+		fmt.Fprintf(
+			os.Stderr,
+			"Warning: metrics were last updated %s, recommendations may be outdated\n",
+			report.UpdatedTimestamp,
+		)
+	}
+	if args.json {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("Can't marshal report: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	return writeReport(report)
+}
+
+func writeReport(report *c.Rightsizing) error {
+	updated := report.UpdatedTimestamp
+	if updated == "" {
+		updated = "unknown"
+	}
+	fmt.Printf(
+		"Cluster:  %s (%s)\n"+
+			"Metrics:  %s\n"+
+			"CPU:      %.2f/%.2f cores (%.0f%%)\n"+
+			"Memory:   %.2f/%.2f GiB (%.0f%%)\n"+
+			"Target:   %.0f%%\n"+
+			"\n",
+		report.ClusterName, report.ClusterID,
+		updated,
+		report.CPUUsed, report.CPUTotal, 100*report.CPUUtilization,
+		report.MemoryUsed/(1<<30), report.MemoryTotal/(1<<30), 100*report.MemoryUtilization,
+		100*report.Target,
+	)
+	if len(report.Pools) == 0 {
+		fmt.Printf("Cluster '%s' doesn't have machine pools\n", report.ClusterName)
+		return nil
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "POOL\tINSTANCE TYPE\tREPLICAS\tRECOMMENDED\tREASON\n")
+	for _, pool := range report.Pools {
+		current := fmt.Sprintf("%d", pool.Replicas)
+		recommended := fmt.Sprintf("%d", pool.RecommendedReplicas)
+		if pool.Autoscaling() {
+			current = fmt.Sprintf("%d-%d", pool.MinReplicas, pool.MaxReplicas)
+			recommended = fmt.Sprintf(
+				"%d-%d",
+				pool.RecommendedMinReplicas, pool.RecommendedMaxReplicas,
+			)
+		}
+		if pool.RecommendedInstanceType != "" {
+			recommended += " " + pool.RecommendedInstanceType
+		}
+		if !pool.Changed() {
+			recommended = "no change"
+		}
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\n",
+			pool.ID, pool.InstanceType, current, recommended, pool.Reason,
+		)
+	}
+	return writer.Flush()
+}
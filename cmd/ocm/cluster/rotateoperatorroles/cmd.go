@@ -0,0 +1,216 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotateoperatorroles
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/sts"
+)
+
+var args struct {
+	dir     string
+	execute bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "rotate-operator-roles [flags] {NAME|ID|EXTERNAL_ID}",
+	Short: "Show the IAM changes needed by the operator roles of a cluster",
+	Long: "Compare the AWS STS operator roles of a cluster with the operator policies " +
+		"currently required by OCM, for example after a minor upgrade, and print the AWS " +
+		"CLI commands that update the policies of the existing roles and create the " +
+		"missing ones. The policy documents are written to the directory given with the " +
+		"'--dir' option. Policies are assumed to have the same name than the roles, as " +
+		"created by the 'rosa' tool. With the '--execute' option the commands are also " +
+		"executed, using the credentials configured for the AWS CLI.",
+	Example: `  # Show the changes needed by the operator roles of cluster 'mycluster'
+  ocm cluster rotate-operator-roles mycluster
+
+  # Apply the changes
+  ocm cluster rotate-operator-roles mycluster --execute`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+	RunE:              run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.dir,
+		"dir",
+		"operator-policies",
+		"Directory where the policy documents will be written.",
+	)
+	flags.BoolVar(
+		&args.execute,
+		"execute",
+		false,
+		"Execute the AWS CLI commands instead of only printing them.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Check that the AWS CLI is available before doing anything else:
+	var aws string
+	if args.execute {
+		var err error
+		aws, err = exec.LookPath("aws")
+		if err != nil {
+			return fmt.Errorf("Can't find the 'aws' command, make sure it is installed")
+		}
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	// Get the policies required by the server:
+	response, err := connection.ClustersMgmt().V1().AWSInquiries().STSPolicies().List().
+		Search("policy_type = 'OperatorRole'").
+		Send()
+	if err != nil {
+		return fmt.Errorf("Can't retrieve operator policies: %v", err)
+	}
+	var policies []*cmv1.AWSSTSPolicy
+	response.Items().Each(func(policy *cmv1.AWSSTSPolicy) bool {
+		policies = append(policies, policy)
+		return true
+	})
+
+	// Calculate the changes:
+	roles, err := sts.Plan(cluster, policies, args.dir)
+	if err != nil {
+		return fmt.Errorf("Can't check operator roles: %v", err)
+	}
+
+	// Show the status of the roles:
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "POLICY\tSTATUS\tROLE\n")
+	missing := false
+	for _, role := range roles {
+		arn := role.RoleARN
+		if arn == "" {
+			arn = "-"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", role.PolicyID, role.Status, arn)
+		if role.Status == sts.StatusMissing {
+			missing = true
+		}
+	}
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	// Write the policy documents and collect the commands:
+	var commands [][]string
+	for _, role := range roles {
+		if len(role.Commands) == 0 {
+			continue
+		}
+		err = os.MkdirAll(args.dir, 0755)
+		if err != nil {
+			return fmt.Errorf("Can't create directory '%s': %v", args.dir, err)
+		}
+		file := role.PolicyFile(args.dir)
+		err = ioutil.WriteFile(file, []byte(role.Policy), 0600)
+		if err != nil {
+			return fmt.Errorf("Can't write policy file '%s': %v", file, err)
+		}
+		commands = append(commands, role.Commands...)
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+
+	// Print the commands and, if requested, execute them:
+	fmt.Printf("\nThe following commands apply the changes:\n\n")
+	for _, command := range commands {
+		fmt.Printf("  aws %s\n", join(command))
+	}
+	if missing {
+		fmt.Printf(
+			"\nThe roles for the missing policies must trust the service accounts of the "+
+				"operators, create them with 'rosa create operator-roles --cluster %s'.\n",
+			cluster.Name(),
+		)
+	}
+	if !args.execute {
+		return nil
+	}
+	fmt.Println()
+	for _, command := range commands {
+		fmt.Printf("Running 'aws %s'\n", join(command))
+		// #nosec G204
+		awsCmd := exec.Command(aws, command...)
+		awsCmd.Stdin = os.Stdin
+		awsCmd.Stdout = os.Stdout
+		awsCmd.Stderr = os.Stderr
+		err = awsCmd.Run()
+		if err != nil {
+			return fmt.Errorf("Command 'aws %s' failed: %v", join(command), err)
+		}
+	}
+	return nil
+}
+
+// safeRE matches the arguments that can be written in a shell command without quotes.
+var safeRE = regexp.MustCompile(`^[A-Za-z0-9_\-./:=@,]+$`)
+
+// join joins the arguments of a command, quoting the ones that need it.
+func join(command []string) string {
+	result := make([]string, len(command))
+	for i, arg := range command {
+		if safeRE.MatchString(arg) {
+			result[i] = arg
+		} else {
+			result[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(result, " ")
+}
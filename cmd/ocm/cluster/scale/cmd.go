@@ -0,0 +1,198 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scale
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var args struct {
+	compute int
+	dryRun  bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "scale [flags] {NAME|ID|EXTERNAL_ID}",
+	Short: "Change the number of compute nodes of a cluster",
+	Long: "Change the number of compute nodes of the default machine pool of a cluster. This is " +
+		"equivalent to 'ocm edit machinepool --cluster=... --replicas=... default', but it also " +
+		"checks that the organization has enough quota for the additional nodes and that " +
+		"autoscaling isn't enabled for the default machine pool.",
+	Example: `  # Scale cluster 'mycluster' to 6 compute nodes
+  ocm cluster scale mycluster --compute 6
+
+  # Check if cluster 'mycluster' can be scaled to 9 compute nodes without changing it
+  ocm cluster scale mycluster --compute 9 --dry-run`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+	RunE:              run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.IntVar(
+		&args.compute,
+		"compute",
+		0,
+		"Number of compute nodes of the default machine pool (required).",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("compute")
+	dryrun.BoolVar(
+		flags,
+		&args.dryRun,
+		"Check the new number of compute nodes, including the quota, without changing the cluster.",
+	)
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+	if args.compute < 1 {
+		return fmt.Errorf("Option '--compute' must be at least 1")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	// The number of nodes of an autoscaled pool is decided by the autoscaler, so changing it
+	// would either fail or be reverted:
+	if _, ok := cluster.Nodes().GetAutoscaleCompute(); ok {
+		return fmt.Errorf(
+			"Cluster '%s' has autoscaling enabled for the default machine pool, use "+
+				"'ocm edit machinepool --cluster=%s --min-replicas=... --max-replicas=... "+
+				"default' to change its limits",
+			clusterKey, clusterKey,
+		)
+	}
+
+	current := cluster.Nodes().Compute()
+	if args.compute == current {
+		fmt.Printf("Cluster '%s' already has %d compute nodes\n", clusterKey, current)
+		return nil
+	}
+	err = c.ValidateComputeNodes(args.compute, cluster.CCS().Enabled(), cluster.MultiAZ())
+	if err != nil {
+		return fmt.Errorf("Can't scale cluster '%s' to %d compute nodes: %v", clusterKey, args.compute, err)
+	}
+
+	// Scaling down never needs additional quota:
+	if args.compute > current {
+		err = checkQuota(connection, cluster, args.compute-current)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.dryRun {
+		fmt.Printf(
+			"Cluster '%s' can be scaled from %d to %d compute nodes\n",
+			clusterKey, current, args.compute,
+		)
+		return nil
+	}
+
+	fmt.Printf(
+		"Scaling cluster '%s' from %d to %d compute nodes\n",
+		clusterKey, current, args.compute,
+	)
+	err = c.UpdateCluster(connection.ClustersMgmt().V1().Clusters(), cluster.ID(), c.Spec{
+		ComputeNodes: args.compute,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to scale cluster '%s': %v", clusterKey, err)
+	}
+	return nil
+}
+
+// checkQuota checks that the organization of the current user has enough quota to add the given
+// number of compute nodes to the cluster.
+func checkQuota(connection *sdk.Connection, cluster *cmv1.Cluster, nodes int) error {
+	// The quota uses the generic name of the machine type, for example 'standard-4' instead of
+	// 'm5.xlarge', so we need to get it:
+	machineTypeID := cluster.Nodes().ComputeMachineType().ID()
+	resourceName := machineTypeID
+	machineTypesResponse, err := connection.ClustersMgmt().V1().MachineTypes().List().
+		Search(fmt.Sprintf("id = '%s'", machineTypeID)).
+		Size(1).
+		Send()
+	if err != nil {
+		return fmt.Errorf("Failed to get machine type '%s': %v", machineTypeID, err)
+	}
+	if machineTypesResponse.Size() > 0 {
+		genericName := machineTypesResponse.Items().Get(0).GenericName()
+		if genericName != "" {
+			resourceName = genericName
+		}
+	}
+
+	accountResponse, err := connection.AccountsMgmt().V1().CurrentAccount().Get().Send()
+	if err != nil {
+		return fmt.Errorf("Failed to get current account: %v", err)
+	}
+	orgID := accountResponse.Body().Organization().ID()
+	quotaResponse, err := connection.AccountsMgmt().V1().Organizations().Organization(orgID).
+		QuotaCost().
+		List().
+		Parameter("fetchRelatedResources", true).
+		Size(-1).
+		Send()
+	if err != nil {
+		return fmt.Errorf("Failed to get quota-cost: %v", err)
+	}
+
+	available := c.AvailableComputeNodes(
+		quotaResponse.Items().Slice(), resourceName,
+		cluster.CCS().Enabled(), cluster.MultiAZ(),
+	)
+	if available >= 0 && available < nodes {
+		return fmt.Errorf(
+			"Organization '%s' doesn't have enough quota to add %d compute nodes of type "+
+				"'%s' to cluster '%s', only %d are available",
+			orgID, nodes, machineTypeID, cluster.Name(), available,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/schedule"
+)
+
+// NewCmd creates the command that schedules the given action, 'hibernate' or 'resume'.
+func NewCmd(action string) *cobra.Command {
+	var cron string
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("%s CLUSTER --cron EXPRESSION", action),
+		Short: fmt.Sprintf("Schedule a cluster to %s periodically", action),
+		Long: fmt.Sprintf(
+			"Schedule a cluster to %s periodically. The schedule is stored locally and "+
+				"is executed by the 'ocm cluster schedule run' command, which should be "+
+				"run every few minutes, for example from cron. Adding a schedule for a "+
+				"cluster that already has one for the same action replaces it.",
+			action,
+		),
+		Example: fmt.Sprintf(`  # %s cluster 'mycluster' at 20:00 from Monday to Friday
+  ocm cluster schedule %s mycluster --cron "0 20 * * 1-5"`,
+			strings.ToUpper(action[:1])+action[1:],
+			action,
+		),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completion.FirstArg(completion.Clusters),
+		RunE: func(cmd *cobra.Command, argv []string) error {
+			return run(action, cron, argv[0])
+		},
+	}
+	fs := cmd.Flags()
+	fs.StringVar(
+		&cron,
+		"cron",
+		"",
+		"Cron expression with five fields, minute, hour, day of month, month and day "+
+			"of week, in local time.",
+	)
+	cmd.MarkFlagRequired("cron")
+	return cmd
+}
+
+func run(action, cron, clusterKey string) error {
+	// Check the cron expression:
+	parsed, err := schedule.ParseCron(cron)
+	if err != nil {
+		return err
+	}
+
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Verify the cluster exists in OCM.
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	// Add or replace the schedule. The last run time is set to the current time so that the
+	// 'run' command only executes occurrences that happen after the schedule was created:
+	return schedule.Update(func(schedules []*schedule.Schedule) ([]*schedule.Schedule, error) {
+		var found *schedule.Schedule
+		for _, item := range schedules {
+			if item.ClusterID == cluster.ID() && item.Action == action {
+				found = item
+				break
+			}
+		}
+		if found == nil {
+			found = &schedule.Schedule{
+				ClusterID: cluster.ID(),
+				Action:    action,
+			}
+			schedules = append(schedules, found)
+		}
+		if found.Cron != parsed.String() {
+			found.LastRun = time.Now()
+		}
+		found.ClusterName = cluster.Name()
+		found.Cron = parsed.String()
+		return schedules, nil
+	})
+}
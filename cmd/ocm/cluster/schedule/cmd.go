@@ -0,0 +1,41 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/schedule/action"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/schedule/delete"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/schedule/run"
+	"github.com/openshift-online/ocm-cli/pkg/schedule"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "schedule COMMAND",
+	Short: "Schedule clusters to hibernate and resume periodically",
+	Long: "Schedule clusters to hibernate and resume periodically, for example to power " +
+		"down development clusters during nights and weekends. The API doesn't support " +
+		"schedules, so they are stored locally and executed by the 'run' command.",
+	Args: cobra.MinimumNArgs(1),
+}
+
+func init() {
+	Cmd.AddCommand(action.NewCmd(schedule.ActionHibernate))
+	Cmd.AddCommand(action.NewCmd(schedule.ActionResume))
+	Cmd.AddCommand(delete.Cmd)
+	Cmd.AddCommand(run.Cmd)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/schedule"
+)
+
+var args struct {
+	action string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "delete CLUSTER_ID",
+	Short: "Delete the schedules of a cluster",
+	Long:  "Delete the hibernation and resume schedules of a cluster.",
+	Example: `  # Delete all the schedules of a cluster
+  ocm cluster schedule delete 1a2b3c4d5e6f7g8h9i0j
+
+  # Delete only the hibernation schedule
+  ocm cluster schedule delete 1a2b3c4d5e6f7g8h9i0j --action hibernate`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+	RunE:              run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVar(
+		&args.action,
+		"action",
+		"",
+		"Delete only the schedule for this action, 'hibernate' or 'resume'.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	clusterID := argv[0]
+	switch args.action {
+	case "", schedule.ActionHibernate, schedule.ActionResume:
+	default:
+		return fmt.Errorf(
+			"Invalid action '%s', valid values are '%s' and '%s'",
+			args.action, schedule.ActionHibernate, schedule.ActionResume,
+		)
+	}
+
+	return schedule.Update(func(schedules []*schedule.Schedule) ([]*schedule.Schedule, error) {
+		kept := []*schedule.Schedule{}
+		for _, item := range schedules {
+			matches := item.ClusterID == clusterID || item.ClusterName == clusterID
+			if matches && (args.action == "" || args.action == item.Action) {
+				continue
+			}
+			kept = append(kept, item)
+		}
+		if len(kept) == len(schedules) {
+			return nil, fmt.Errorf("There are no matching schedules for cluster '%s'", clusterID)
+		}
+		return kept, nil
+	})
+}
@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package run
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/curl"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+	"github.com/openshift-online/ocm-cli/pkg/schedule"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "run",
+	Short: "Execute the schedules that are due",
+	Long: "Hibernate or resume the clusters whose schedules are due since the last time this " +
+		"command was executed. It is intended to be executed every few minutes, for " +
+		"example from cron.",
+	Example: `  # Crontab entry that runs the schedules every five minutes
+  */5 * * * * ocm cluster schedule run`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	schedules, err := schedule.Load()
+	if err != nil {
+		return err
+	}
+	if len(schedules) == 0 {
+		return nil
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+	clusters := connection.ClustersMgmt().V1().Clusters()
+
+	// Execute the schedules that are due. Failures are reported but don't prevent executing
+	// the rest of the schedules:
+	now := time.Now()
+	failed := 0
+	executed := map[string]bool{}
+	for _, item := range schedules {
+		cron, err := schedule.ParseCron(item.Cron)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Schedule of cluster '%s' is invalid: %v\n", item.ClusterID, err)
+			failed++
+			continue
+		}
+		if !cron.Due(item.LastRun, now) {
+			continue
+		}
+		resource := clusters.Cluster(item.ClusterID)
+		switch item.Action {
+		case schedule.ActionHibernate:
+			_, err = resource.Hibernate().Send()
+		case schedule.ActionResume:
+			_, err = resource.Resume().Send()
+		default:
+			err = fmt.Errorf("unknown action '%s'", item.Action)
+		}
+		if errors.Is(err, curl.ErrNotSent) {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(
+				os.Stderr, "Failed to %s cluster '%s': %v\n",
+				item.Action, item.ClusterID, err,
+			)
+			failed++
+			continue
+		}
+		executed[item.Key()] = true
+		fmt.Printf("Executed '%s' for cluster '%s'\n", item.Action, item.ClusterID)
+	}
+
+	// Save the last run times. Note that the file is loaded again, as schedules may have been
+	// added or deleted while the actions were executed:
+	err = schedule.Update(func(schedules []*schedule.Schedule) ([]*schedule.Schedule, error) {
+		for _, item := range schedules {
+			if executed[item.Key()] {
+				item.LastRun = now
+			}
+		}
+		return schedules, nil
+	})
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d schedules failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+// jumpEnv is the name of the environment variable that contains the default jump host.
+const jumpEnv = "OCM_SSH_JUMP"
+
+var args struct {
+	user   string
+	jump   string
+	dryRun bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "ssh [flags] {NAME|ID|EXTERNAL_ID} [-- COMMAND...]",
+	Short: "Connect to a cluster with SSH",
+	Long: "Resolve the SSH host of a cluster and run the 'ssh' command to connect to it.\n" +
+		"\n" +
+		"The SSH host is calculated from the API URL of the cluster. Clusters whose API is\n" +
+		"private can only be reached through a jump host, that is given with the '--jump'\n" +
+		"option or the '" + jumpEnv + "' environment variable and passed to 'ssh' with the\n" +
+		"'-J' option. Arguments after '--' are the command to run in the SSH host, instead of\n" +
+		"an interactive shell.",
+	Example: "  # Connect to a public cluster:\n" +
+		"  ocm cluster ssh mycluster\n" +
+		"\n" +
+		"  # Connect to a private cluster through a bastion host:\n" +
+		"  ocm cluster ssh --jump bastion.example.com mycluster\n" +
+		"\n" +
+		"  # Run a command instead of an interactive shell:\n" +
+		"  ocm cluster ssh mycluster -- uptime\n" +
+		"\n" +
+		"  # Print the 'ssh' command instead of running it:\n" +
+		"  ocm cluster ssh --dry-run mycluster",
+	Args:              cobra.MinimumNArgs(1),
+	RunE:              run,
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.user,
+		"user",
+		c.SSHUser,
+		"User name used to connect to the SSH host.",
+	)
+	flags.StringVar(
+		&args.jump,
+		"jump",
+		os.Getenv(jumpEnv),
+		"Jump host used to reach private clusters, in the '[USER@]HOST[:PORT]' format "+
+			"accepted by the '-J' option of 'ssh'.",
+	)
+	dryrun.BoolVar(
+		flags,
+		&args.dryRun,
+		"Print the 'ssh' command instead of running it.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Find the SSH client before doing anything else:
+	path := "ssh"
+	if !args.dryRun {
+		var err error
+		path, err = exec.LookPath("ssh")
+		if err != nil {
+			return fmt.Errorf("To run this, you need to install the 'ssh' command first")
+		}
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Get the cluster and calculate the connection details:
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	host, err := c.SSHHost(cluster)
+	if err != nil {
+		return err
+	}
+	sshArgs := []string{}
+	if c.IsPrivate(cluster) {
+		if args.jump == "" {
+			return fmt.Errorf(
+				"Cluster '%s' is private, use the '--jump' option or the '%s' "+
+					"environment variable to give the jump host",
+				cluster.Name(), jumpEnv,
+			)
+		}
+		sshArgs = append(sshArgs, "-J", args.jump)
+	}
+	sshArgs = append(sshArgs, args.user+"@"+host)
+	sshArgs = append(sshArgs, argv[1:]...)
+
+	if args.dryRun {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", path, strings.Join(sshArgs, " "))
+		return nil
+	}
+
+	// #nosec G204
+	sshCmd := exec.Command(path, sshArgs...)
+	sshCmd.Stdin = os.Stdin
+	sshCmd.Stdout = os.Stdout
+	sshCmd.Stderr = os.Stderr
+	err = sshCmd.Run()
+	if err != nil {
+		return fmt.Errorf("Failed to connect to cluster '%s': %v", cluster.Name(), err)
+	}
+	return nil
+}
@@ -19,15 +19,17 @@ package status
 import (
 	"fmt"
 
+	"github.com/openshift-online/ocm-cli/pkg/completion"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	"github.com/spf13/cobra"
 )
 
 var Cmd = &cobra.Command{
-	Use:   "status [flags] CLUSTER_ID",
-	Short: "Status of a cluster",
-	Long:  "Get the status of a cluster identified by its cluster ID",
-	RunE:  run,
+	Use:               "status [flags] CLUSTER_ID",
+	Short:             "Status of a cluster",
+	Long:              "Get the status of a cluster identified by its cluster ID",
+	ValidArgsFunction: completion.FirstArg(completion.Clusters),
+	RunE:              run,
 }
 
 func run(cmd *cobra.Command, argv []string) error {
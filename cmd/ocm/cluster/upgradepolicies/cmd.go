@@ -0,0 +1,39 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgradepolicies
+
+import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/upgradepolicies/freezes"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/upgradepolicies/pause"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/upgradepolicies/resume"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "upgrade-policies COMMAND",
+	Short: "Pause and resume the upgrade policies of multiple clusters",
+	Long: "Pause and resume the upgrade policies of multiple clusters, for example during " +
+		"a change freeze. The paused policies are saved in local freeze records, which " +
+		"are kept after resuming for later audit.",
+	Args: cobra.MinimumNArgs(1),
+}
+
+func init() {
+	Cmd.AddCommand(freezes.Cmd)
+	Cmd.AddCommand(pause.Cmd)
+	Cmd.AddCommand(resume.Cmd)
+}
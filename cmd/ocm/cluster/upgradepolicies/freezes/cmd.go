@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package freezes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/freeze"
+)
+
+var args struct {
+	json bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "freezes",
+	Short: "List the freezes of upgrade policies",
+	Long: "List the freezes created by the 'pause' command, including the ones that have " +
+		"already been resumed. Use the --json option to see the clusters and the saved " +
+		"upgrade policies of each freeze.",
+	Example: `  # List the freezes
+  ocm cluster upgrade-policies freezes
+
+  # Show the complete freeze records
+  ocm cluster upgrade-policies freezes --json`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the complete freeze records in JSON format.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	freezes, err := freeze.Load()
+	if err != nil {
+		return err
+	}
+	if args.json {
+		if freezes == nil {
+			freezes = []*freeze.Freeze{}
+		}
+		data, err := json.Marshal(freezes)
+		if err != nil {
+			return fmt.Errorf("Can't marshal freezes: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	if len(freezes) == 0 {
+		fmt.Printf("There are no freezes\n")
+		return nil
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "ID\tSTATE\tCLUSTERS\tPAUSED AT\tPAUSED BY\tREASON\n")
+	for _, item := range freezes {
+		state := "active"
+		if !item.Active() {
+			state = "resumed"
+		}
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%d\t%s\t%s\t%s\n",
+			item.ID, state, len(item.Clusters), item.PausedAt.UTC().Format("2006-01-02 15:04"),
+			item.PausedBy, item.Reason,
+		)
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,319 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pause
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/bulk"
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/curl"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+	"github.com/openshift-online/ocm-cli/pkg/freeze"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+	"github.com/openshift-online/ocm-cli/pkg/search"
+)
+
+var args struct {
+	search  string
+	shard   string
+	reason  string
+	dryRun  bool
+	results string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "pause --search=EXPRESSION",
+	Short: "Pause the upgrade policies of multiple clusters",
+	Long: "Pause the upgrade policies of all the clusters that match a search expression, " +
+		"for example during a change freeze. The API doesn't support pausing upgrade " +
+		"policies, so they are saved in a local freeze record and then deleted. The " +
+		"'resume' command creates them again with the original schedules.\n\n" +
+		"Clusters are processed grouped by the provision shard where they run, and the " +
+		"--shard option can be used to pause only the clusters of one shard.\n\n" +
+		"The command exits with code 0 when all the clusters succeeded, with code 2 " +
+		"when only some of them failed and with code 1 when all of them failed. Use " +
+		"the --results option to write the result of each cluster to a JSON file.",
+	Example: `  # Show the upgrade policies that would be paused in the production clusters
+  ocm cluster upgrade-policies pause --search="name like 'prod-%'" --dry-run
+
+  # Pause them, explaining why
+  ocm cluster upgrade-policies pause --search="name like 'prod-%'" --reason="End of quarter freeze"
+
+  # Pause only the clusters of one shard
+  ocm cluster upgrade-policies pause --search="name like 'prod-%'" --shard=https://api.hive-01.example.com:6443`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVar(
+		&args.search,
+		"search",
+		"",
+		"Search expression used to select the clusters (required).",
+	)
+	fs.StringVar(
+		&args.shard,
+		"shard",
+		"",
+		"Pause only the clusters that run in the provision shard with this identifier or "+
+			"server URL.",
+	)
+	fs.StringVar(
+		&args.reason,
+		"reason",
+		"",
+		"Reason of the freeze, saved in the freeze record.",
+	)
+	dryrun.BoolVar(
+		fs,
+		&args.dryRun,
+		"Show the upgrade policies that would be paused without pausing them.",
+	)
+	fs.StringVar(
+		&args.results,
+		"results",
+		"",
+		"Write the result of each cluster to this file, in JSON format.",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("search")
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the search expression before sending it to the server:
+	err := search.Lint(args.search)
+	if err != nil {
+		return fmt.Errorf("Invalid search expression: %v", err)
+	}
+
+	// Load the configuration file:
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("Not logged in, run the 'login' command")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Config(cfg).Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+	clusterCollection := connection.ClustersMgmt().V1().Clusters()
+
+	// Retrieve the clusters:
+	var clusters []*cmv1.Cluster
+	size := 100
+	index := 1
+	for {
+		response, err := clusterCollection.List().
+			Search(args.search).
+			Size(size).
+			Page(index).
+			Send()
+		if err != nil {
+			return fmt.Errorf("Can't retrieve clusters: %v", err)
+		}
+		clusters = append(clusters, response.Items().Slice()...)
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+
+	// Find the shard and the upgrade policies of each cluster. The shard is only available to
+	// some users, so failing to get it is only an error when the user asked to filter by shard:
+	now := time.Now()
+	record := &freeze.Freeze{
+		ID:       freeze.NewID(now),
+		Reason:   args.reason,
+		Search:   args.search,
+		Shard:    args.shard,
+		PausedAt: now.UTC(),
+		PausedBy: cfg.UserName(),
+		Clusters: []*freeze.Cluster{},
+	}
+	policies := map[string][]*cmv1.UpgradePolicy{}
+	results := bulk.NewResults()
+	for _, cluster := range clusters {
+		shard, err := c.GetProvisionShard(clusterCollection, cluster.ID())
+		if err != nil && args.shard != "" {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cluster.Name(), err)
+			results.Failed(cluster.ID(), cluster.Name(), "", err)
+			continue
+		}
+		if args.shard != "" && shard.ID() != args.shard && shard.HiveConfig().Server() != args.shard {
+			continue
+		}
+		items, err := c.GetUpgradePolicies(clusterCollection, cluster.ID())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cluster.Name(), err)
+			results.Failed(cluster.ID(), cluster.Name(), "", err)
+			continue
+		}
+		if len(items) == 0 {
+			results.Skipped(cluster.ID(), cluster.Name(), "")
+			continue
+		}
+		saved, err := freeze.MarshalPolicies(items)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cluster.Name(), err)
+			results.Failed(cluster.ID(), cluster.Name(), "", err)
+			continue
+		}
+		record.Clusters = append(record.Clusters, &freeze.Cluster{
+			ID:       cluster.ID(),
+			Name:     cluster.Name(),
+			Shard:    shardName(shard),
+			State:    freeze.StatePending,
+			Policies: saved,
+		})
+		policies[cluster.ID()] = items
+	}
+	sort.SliceStable(record.Clusters, func(i, j int) bool {
+		if record.Clusters[i].Shard != record.Clusters[j].Shard {
+			return record.Clusters[i].Shard < record.Clusters[j].Shard
+		}
+		return record.Clusters[i].Name < record.Clusters[j].Name
+	})
+
+	// Save the record before deleting anything, so that the schedules aren't lost even if the
+	// command is interrupted. In curl mode nothing is deleted, so nothing is saved either:
+	save := !args.dryRun && !curl.Enabled() && len(record.Clusters) > 0
+	if save {
+		err = freeze.Create(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Pause the upgrade policies, one shard after the other:
+	shard := ""
+	for i, cluster := range record.Clusters {
+		if i == 0 || cluster.Shard != shard {
+			shard = cluster.Shard
+			if shard == "" {
+				fmt.Printf("Unknown shard:\n")
+			} else {
+				fmt.Printf("Shard '%s':\n", shard)
+			}
+		}
+		pause(clusterCollection, cluster, policies[cluster.ID], results, os.Stdout, os.Stderr)
+	}
+	if save {
+		err = freeze.Save(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Print the summary:
+	verb := "paused"
+	if args.dryRun {
+		verb = "to be paused"
+	}
+	fmt.Printf(
+		"\nClusters: %d, %s: %d, skipped: %d, failed: %d\n",
+		len(results.Items()), verb, results.Count(bulk.StatusSucceeded),
+		results.Count(bulk.StatusSkipped), results.Count(bulk.StatusFailed),
+	)
+	if !args.dryRun && record.Count(freeze.StatePaused) > 0 {
+		fmt.Printf(
+			"Freeze '%s' saved, use 'ocm cluster upgrade-policies resume %s' to resume "+
+				"the upgrade policies\n",
+			record.ID, record.ID,
+		)
+	}
+	if args.results != "" {
+		err = results.Write(args.results)
+		if err != nil {
+			return err
+		}
+	}
+	return results.Err("Failed to pause upgrade policies")
+}
+
+// pause deletes the upgrade policies of one cluster, writing the changes to the given output and
+// the errors to the given error output, and recording the result. Only the policies that were
+// actually deleted are kept in the freeze record.
+func pause(clusterCollection *cmv1.ClustersClient, cluster *freeze.Cluster,
+	policies []*cmv1.UpgradePolicy, results *bulk.Results, out, errOut io.Writer) {
+	policiesClient := clusterCollection.Cluster(cluster.ID).UpgradePolicies()
+	var deleted []*cmv1.UpgradePolicy
+	var failure error
+	for _, policy := range policies {
+		fmt.Fprintf(out, "  %s: pause %s\n", cluster.Name, freeze.DescribePolicy(policy))
+		if args.dryRun {
+			continue
+		}
+		_, err := policiesClient.UpgradePolicy(policy.ID()).Delete().Send()
+		if errors.Is(err, curl.ErrNotSent) {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(errOut, "%s: %v\n", cluster.Name, err)
+			failure = err
+			continue
+		}
+		deleted = append(deleted, policy)
+	}
+	if args.dryRun || curl.Enabled() {
+		results.Succeeded(cluster.ID, cluster.Name, "pause")
+		return
+	}
+	if len(deleted) == 0 {
+		cluster.State = freeze.StateFailed
+		cluster.Policies = nil
+		results.Failed(cluster.ID, cluster.Name, "pause", failure)
+		return
+	}
+	cluster.State = freeze.StatePaused
+	saved, err := freeze.MarshalPolicies(deleted)
+	if err == nil {
+		cluster.Policies = saved
+	}
+	if failure != nil {
+		results.Failed(cluster.ID, cluster.Name, "pause", failure)
+		return
+	}
+	results.Succeeded(cluster.ID, cluster.Name, "pause")
+}
+
+// shardName returns the name used to identify the shard in the output and in the freeze record:
+// the server URL if it is available, or else the identifier.
+func shardName(shard *cmv1.ProvisionShard) string {
+	if server := shard.HiveConfig().Server(); server != "" {
+		return server
+	}
+	return shard.ID()
+}
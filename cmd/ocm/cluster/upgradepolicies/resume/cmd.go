@@ -0,0 +1,259 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resume
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/bulk"
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/curl"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+	"github.com/openshift-online/ocm-cli/pkg/freeze"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+var args struct {
+	dryRun  bool
+	results string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "resume [FREEZE]",
+	Short: "Resume the upgrade policies paused by a freeze",
+	Long: "Create again the upgrade policies that were paused by the 'pause' command, with " +
+		"the original schedules. If no freeze is given the only active one is resumed.\n\n" +
+		"Policies that already exist are skipped, so the command can be run again to retry " +
+		"the clusters that failed. Manual upgrades that were scheduled for a time that " +
+		"passed during the freeze can't be created again, and are reported so that they " +
+		"can be scheduled again. The freeze record is kept for later audit, and is marked " +
+		"as resumed when all its clusters have been resumed.",
+	Example: `  # Resume the only active freeze
+  ocm cluster upgrade-policies resume
+
+  # Show what would be resumed for a specific freeze
+  ocm cluster upgrade-policies resume 20221001-100000 --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	dryrun.BoolVar(
+		fs,
+		&args.dryRun,
+		"Show the upgrade policies that would be created without creating them.",
+	)
+	fs.StringVar(
+		&args.results,
+		"results",
+		"",
+		"Write the result of each cluster to this file, in JSON format.",
+	)
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Find the freeze:
+	freezes, err := freeze.Load()
+	if err != nil {
+		return err
+	}
+	var record *freeze.Freeze
+	if len(argv) == 1 {
+		record = freeze.Find(freezes, argv[0])
+		if record == nil {
+			return fmt.Errorf("Freeze '%s' doesn't exist", argv[0])
+		}
+		if !record.Active() {
+			return fmt.Errorf(
+				"Freeze '%s' was already resumed at %s",
+				record.ID, record.ResumedAt.UTC().Format(time.RFC3339),
+			)
+		}
+	} else {
+		var active []string
+		for _, item := range freezes {
+			if item.Active() {
+				active = append(active, item.ID)
+				record = item
+			}
+		}
+		switch len(active) {
+		case 0:
+			return fmt.Errorf("There are no active freezes")
+		case 1:
+		default:
+			return fmt.Errorf(
+				"There are %d active freezes, specify which one to resume: %s",
+				len(active), strings.Join(active, ", "),
+			)
+		}
+	}
+
+	// Load the configuration file:
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("Not logged in, run the 'login' command")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Config(cfg).Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+	clusterCollection := connection.ClustersMgmt().V1().Clusters()
+
+	// Resume the clusters, one shard after the other. Clusters in the pending state may have
+	// been interrupted before or after deleting the policies, so they are resumed as well:
+	results := bulk.NewResults()
+	shard := ""
+	first := true
+	for _, cluster := range record.Clusters {
+		if cluster.State != freeze.StatePaused && cluster.State != freeze.StatePending {
+			continue
+		}
+		if first || cluster.Shard != shard {
+			first = false
+			shard = cluster.Shard
+			if shard == "" {
+				fmt.Printf("Unknown shard:\n")
+			} else {
+				fmt.Printf("Shard '%s':\n", shard)
+			}
+		}
+		resume(clusterCollection, cluster, results, os.Stdout, os.Stderr)
+	}
+
+	// Mark the freeze as resumed when there are no clusters left to resume:
+	if !args.dryRun {
+		if record.Count(freeze.StatePaused)+record.Count(freeze.StatePending) == 0 {
+			now := time.Now().UTC()
+			record.ResumedAt = &now
+			record.ResumedBy = cfg.UserName()
+		}
+		err = freeze.Save(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Print the summary:
+	verb := "resumed"
+	if args.dryRun {
+		verb = "to be resumed"
+	}
+	fmt.Printf(
+		"\nClusters: %d, %s: %d, skipped: %d, failed: %d\n",
+		len(results.Items()), verb, results.Count(bulk.StatusSucceeded),
+		results.Count(bulk.StatusSkipped), results.Count(bulk.StatusFailed),
+	)
+	if !record.Active() {
+		fmt.Printf("Freeze '%s' resumed\n", record.ID)
+	}
+	if args.results != "" {
+		err = results.Write(args.results)
+		if err != nil {
+			return err
+		}
+	}
+	return results.Err("Failed to resume upgrade policies")
+}
+
+// resume creates again the upgrade policies of one cluster, writing the changes to the given
+// output and the errors to the given error output, and recording the result.
+func resume(clusterCollection *cmv1.ClustersClient, cluster *freeze.Cluster,
+	results *bulk.Results, out, errOut io.Writer) {
+	existing, err := c.GetUpgradePolicies(clusterCollection, cluster.ID)
+	if err != nil {
+		fmt.Fprintf(errOut, "%s: %v\n", cluster.Name, err)
+		results.Failed(cluster.ID, cluster.Name, "resume", err)
+		return
+	}
+	policiesClient := clusterCollection.Cluster(cluster.ID).UpgradePolicies()
+	now := time.Now()
+	created := 0
+	var failure error
+	for _, data := range cluster.Policies {
+		policy, err := freeze.UnmarshalPolicy(data)
+		if err != nil {
+			fmt.Fprintf(errOut, "%s: %v\n", cluster.Name, err)
+			failure = err
+			continue
+		}
+		description := freeze.DescribePolicy(policy)
+		if exists(existing, policy) {
+			fmt.Fprintf(out, "  %s: %s already exists\n", cluster.Name, description)
+			continue
+		}
+		if policy.ScheduleType() != "automatic" && policy.NextRun().Before(now) {
+			fmt.Fprintf(
+				errOut,
+				"%s: %s wasn't resumed because that time has passed, schedule it again\n",
+				cluster.Name, description,
+			)
+			continue
+		}
+		fmt.Fprintf(out, "  %s: resume %s\n", cluster.Name, description)
+		if args.dryRun {
+			created++
+			continue
+		}
+		_, err = policiesClient.Add().Body(policy).Send()
+		if err != nil && !errors.Is(err, curl.ErrNotSent) {
+			fmt.Fprintf(errOut, "%s: %v\n", cluster.Name, err)
+			failure = err
+			continue
+		}
+		created++
+	}
+	switch {
+	case failure != nil:
+		results.Failed(cluster.ID, cluster.Name, "resume", failure)
+		return
+	case created == 0:
+		results.Skipped(cluster.ID, cluster.Name, "")
+	default:
+		results.Succeeded(cluster.ID, cluster.Name, "resume")
+	}
+	if !args.dryRun {
+		cluster.State = freeze.StateResumed
+	}
+}
+
+func exists(existing []*cmv1.UpgradePolicy, policy *cmv1.UpgradePolicy) bool {
+	for _, item := range existing {
+		if freeze.SamePolicy(item, policy) {
+			return true
+		}
+	}
+	return false
+}
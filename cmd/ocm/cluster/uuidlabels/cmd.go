@@ -0,0 +1,35 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uuidlabels
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster/uuidlabels/sync"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "uuid-labels COMMAND",
+	Short: "Manage the identifiers and labels shared by clusters and subscriptions",
+	Long: "Manage the name, identifiers and labels that are stored both in the cluster and in " +
+		"its subscription.",
+	Args: cobra.MinimumNArgs(1),
+}
+
+func init() {
+	Cmd.AddCommand(sync.Cmd)
+}
@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/completion"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+var args struct {
+	labels []string
+	fix    bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "sync [flags] {NAME|ID|EXTERNAL_ID}...",
+	Short: "Check that clusters and their subscriptions have the same metadata",
+	Long: "Compare the name and the identifiers of each cluster with the display name and the\n" +
+		"identifiers of its subscription, and the cluster properties given with the '--label'\n" +
+		"option with the subscription labels with the same keys. Differences break the\n" +
+		"reports that join clusters and subscriptions.\n" +
+		"\n" +
+		"The cluster is the source of truth: with the '--fix' option the subscription is\n" +
+		"changed to match it. The command fails if there are differences that weren't fixed.",
+	Example: "  # Check the metadata of a cluster:\n" +
+		"  ocm cluster uuid-labels sync mycluster\n" +
+		"\n" +
+		"  # Check also the 'cost-center' label, and fix the differences:\n" +
+		"  ocm cluster uuid-labels sync --label=cost-center --fix mycluster",
+	Args:              cobra.MinimumNArgs(1),
+	RunE:              run,
+	ValidArgsFunction: completion.Clusters,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringArrayVar(
+		&args.labels,
+		"label",
+		nil,
+		"Key of a cluster property that should also be a subscription label with the same "+
+			"value. Can be used multiple times.",
+	)
+	flags.BoolVar(
+		&args.fix,
+		"fix",
+		false,
+		"Change the subscriptions to match the clusters.",
+	)
+	readonly.MarkFlag(Cmd, "fix")
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster keys (names, identifiers or external identifiers) given by the user
+	// are reasonably safe so that there is no risk of SQL injection:
+	for _, clusterKey := range argv {
+		if !c.IsValidClusterKey(clusterKey) {
+			return fmt.Errorf(
+				"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+					"must contain only letters, digits, dashes and underscores",
+				clusterKey,
+			)
+		}
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	rows := [][]string{}
+	pending := 0
+	for _, clusterKey := range argv {
+		cluster, err := c.GetCluster(connection, clusterKey)
+		if err != nil {
+			return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+		}
+		subID := cluster.Subscription().ID()
+		if subID == "" {
+			return fmt.Errorf("Cluster '%s' doesn't have a subscription", cluster.Name())
+		}
+		subResponse, err := connection.AccountsMgmt().V1().Subscriptions().Subscription(subID).
+			Get().
+			Send()
+		if err != nil {
+			return fmt.Errorf("Failed to get subscription '%s': %v", subID, err)
+		}
+		labels, err := c.ListSubscriptionLabels(connection, subID)
+		if err != nil {
+			return err
+		}
+		drifts := c.CompareMetadata(cluster, subResponse.Body(), labels, args.labels)
+		if args.fix {
+			err = c.FixMetadata(connection, subID, drifts)
+			if err != nil {
+				return err
+			}
+		}
+		for _, drift := range drifts {
+			status := "different"
+			switch {
+			case args.fix && drift.Fixable:
+				status = "fixed"
+			case !drift.Fixable:
+				status = "can't be fixed"
+				pending++
+			default:
+				pending++
+			}
+			rows = append(rows, []string{
+				cluster.ID(), drift.Field, drift.ClusterValue, drift.SubscriptionValue,
+				status,
+			})
+		}
+	}
+
+	// Write the result:
+	if len(rows) == 0 {
+		fmt.Printf("Clusters and subscriptions have the same metadata\n")
+		return nil
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "CLUSTER\tFIELD\tCLUSTER VALUE\tSUBSCRIPTION VALUE\tSTATUS\n")
+	for _, row := range rows {
+		fmt.Fprintf(writer, "%s\n", strings.Join(row, "\t"))
+	}
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		if args.fix {
+			return fmt.Errorf("Found %d differences that can't be fixed", pending)
+		}
+		return fmt.Errorf("Found %d differences, use the '--fix' option to fix them", pending)
+	}
+	return nil
+}
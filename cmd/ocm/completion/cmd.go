@@ -57,6 +57,10 @@ $ ocm completion fish | source
 # To load completions for each session, execute once:
 $ ocm completion fish > ~/.config/fish/completions/ocm.fish
 
+Organization identifiers, role identifiers and cluster names are completed with the
+values that exist in the server. The results are cached for one minute in the user
+cache directory, for example '~/.cache/ocm/completion' in Linux.
+
 P.S. Debugging completion logic:
 - Set BASH_COMP_DEBUG_FILE env var to enable logging to that file.
 - See https://github.com/spf13/cobra/blob/master/shell_completions.md.
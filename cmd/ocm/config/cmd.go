@@ -24,20 +24,28 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-cli/cmd/ocm/config/get"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/config/getcontexts"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/config/set"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/config/usecontext"
 	"github.com/openshift-online/ocm-cli/pkg/config"
 )
 
 func configVarDocs() (ret string) {
 	// TODO(efried): Figure out how to get the Type without instantiating.
 	configType := reflect.ValueOf(config.Config{}).Type()
-	fieldHelps := make([]string, configType.NumField())
-	for i := 0; i < len(fieldHelps); i++ {
-		tag := configType.Field(i).Tag
+	names := make([]string, configType.NumField())
+	width := 0
+	for i := 0; i < len(names); i++ {
 		// TODO(efried): Use JSON parser instead
-		name := strings.Split(tag.Get("json"), ",")[0]
-		doc := tag.Get("doc")
-		fieldHelps[i] = fmt.Sprintf("\t%-15s%s", name, doc)
+		names[i] = strings.Split(configType.Field(i).Tag.Get("json"), ",")[0]
+		if len(names[i]) > width {
+			width = len(names[i])
+		}
+	}
+	fieldHelps := make([]string, len(names))
+	for i := 0; i < len(fieldHelps); i++ {
+		doc := configType.Field(i).Tag.Get("doc")
+		fieldHelps[i] = fmt.Sprintf("\t%-*s  %s", width, names[i], doc)
 	}
 	ret = strings.Join(fieldHelps, "\n")
 	return
@@ -58,6 +66,10 @@ The following variables are supported:
 
 %s
 
+The file can contain multiple named contexts, each with its own values for these variables. The
+'get' and 'set' commands use the current context, or the one given with the '--context' flag. Use
+'ocm config get-contexts' to list them and 'ocm config use-context' to change the current one.
+
 Note that "ocm config get access_token" gives whatever the file contains - may be missing or expired;
 you probably want "ocm token" command instead which will obtain a fresh token if needed.
 `, loc, configVarDocs())
@@ -72,5 +84,7 @@ var Cmd = &cobra.Command{
 
 func init() {
 	Cmd.AddCommand(get.Cmd)
+	Cmd.AddCommand(getcontexts.Cmd)
 	Cmd.AddCommand(set.Cmd)
+	Cmd.AddCommand(usecontext.Cmd)
 }
@@ -83,6 +83,18 @@ func run(cmd *cobra.Command, argv []string) error {
 		fmt.Fprintf(os.Stdout, "%s\n", cfg.URL)
 	case "pager":
 		fmt.Fprintf(os.Stdout, "%s\n", cfg.Pager)
+	case "read_only":
+		fmt.Fprintf(os.Stdout, "%v\n", cfg.ReadOnly)
+	case "auth_provider":
+		fmt.Fprintf(os.Stdout, "%s\n", cfg.AuthProvider)
+	case "auth_command":
+		fmt.Fprintf(os.Stdout, "%s\n", cfg.AuthCommand)
+	case "production_interlock":
+		fmt.Fprintf(os.Stdout, "%s\n", cfg.ProductionInterlock)
+	case "default_cluster":
+		fmt.Fprintf(os.Stdout, "%s\n", cfg.DefaultCluster)
+	case "user_agent":
+		fmt.Fprintf(os.Stdout, "%s\n", cfg.UserAgent)
 	default:
 		return fmt.Errorf("Unknown setting")
 	}
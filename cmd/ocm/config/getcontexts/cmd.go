@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package getcontexts
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "get-contexts",
+	Short: "List the configuration contexts",
+	Long: "List the contexts of the configuration file, each with its own server and " +
+		"credentials. The current context is marked with an asterisk.",
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	names, err := config.Contexts()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
+	current, err := config.SelectedContext()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "CURRENT\tNAME\tURL\tSTATUS\n")
+	for _, name := range names {
+		cfg, err := config.LoadContext(name)
+		if err != nil {
+			return err
+		}
+		mark := ""
+		if name == current {
+			mark = "*"
+		}
+		status := "logged in"
+		armed, reason, err := cfg.Armed()
+		if err != nil {
+			return err
+		}
+		if !armed {
+			status = reason
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", mark, name, cfg.URL, status)
+	}
+	return writer.Flush()
+}
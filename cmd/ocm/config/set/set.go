@@ -19,10 +19,14 @@ package set
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/pkg/agent"
+	"github.com/openshift-online/ocm-cli/pkg/cluster"
 	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/protection"
 )
 
 var args struct {
@@ -86,6 +90,44 @@ func run(cmd *cobra.Command, argv []string) error {
 		cfg.URL = value
 	case "pager":
 		cfg.Pager = value
+	case "read_only":
+		cfg.ReadOnly, err = strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("Failed to set read_only: %v", value)
+		}
+	case "auth_provider":
+		if value != "" && !validAuthProvider(value) {
+			return fmt.Errorf(
+				"Unknown authentication provider '%s', valid values are %s",
+				value, strings.Join(config.AuthProviders(), ", "),
+			)
+		}
+		cfg.AuthProvider = value
+	case "auth_command":
+		cfg.AuthCommand = value
+	case "production_interlock":
+		if !protection.ValidMode(value) {
+			return fmt.Errorf(
+				"Unknown production interlock mode '%s', valid values are %s",
+				value, strings.Join(protection.Modes(), ", "),
+			)
+		}
+		cfg.ProductionInterlock = value
+	case "default_cluster":
+		if value != "" && !cluster.IsValidClusterKey(value) {
+			return fmt.Errorf(
+				"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+					"must contain only letters, digits, dashes and underscores",
+				value,
+			)
+		}
+		cfg.DefaultCluster = value
+	case "user_agent":
+		err = agent.Validate(value)
+		if err != nil {
+			return err
+		}
+		cfg.UserAgent = value
 	default:
 		return fmt.Errorf("Unknown setting")
 	}
@@ -98,3 +140,12 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	return nil
 }
+
+func validAuthProvider(name string) bool {
+	for _, provider := range config.AuthProviders() {
+		if name == provider {
+			return true
+		}
+	}
+	return false
+}
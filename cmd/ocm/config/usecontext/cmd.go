@@ -0,0 +1,46 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecontext
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "use-context NAME",
+	Short: "Change the current configuration context",
+	Long: "Change the current context of the configuration file, so that the following " +
+		"commands use its server and credentials. Commands can also use a different context " +
+		"with the '--context' flag or the 'OCM_CONTEXT' environment variable. To create a " +
+		"new context log in with the '--context' flag.",
+	Example: `  # Log in to the staging environment in a new context:
+  ocm login --context staging --url staging --token ...
+
+  # Make it the current context:
+  ocm config use-context staging
+
+  # Go back to the default context:
+  ocm config use-context default`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	return config.UseContext(argv[0])
+}
@@ -0,0 +1,160 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
+)
+
+// defaultFormat is the template used when the '--format' option isn't given.
+const defaultFormat = "{{ .Context }}:{{ .Environment }} " +
+	"{{ if .LoggedIn }}{{ .User }}{{ else }}(logged out){{ end }}" +
+	"{{ if .Cluster }} {{ .Cluster }}{{ end }}"
+
+var args struct {
+	json   bool
+	format string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print a one line summary of the current context",
+	Long: "Print the current context, the environment, the user and the default cluster in a " +
+		"single short line, suitable for shell prompts. The information is taken from the " +
+		"configuration file and the saved tokens, without contacting the server, so the " +
+		"command is fast and works offline. Nothing is printed if there is no configuration " +
+		"file.",
+	Example: `  # Print the summary, for example 'default:production jdoe my-cluster'
+  ocm context status
+
+  # Print only the user and the environment
+  ocm context status --format '{{ .User }}@{{ .Environment }}'
+
+  # Add it to the prompt of starship with a custom module in 'starship.toml'
+  [custom.ocm]
+  command = "ocm context status"
+  when = true`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Print the details in JSON format.",
+	)
+	flags.StringVar(
+		&args.format,
+		"format",
+		defaultFormat,
+		"Go template used to print the details. The available fields are 'Context', "+
+			"'Environment', 'URL', 'User', 'LoggedIn' and 'Cluster'.",
+	)
+}
+
+// Status contains the details that are printed. Note that the field names are part of the JSON
+// output and of the template, so don't change them without considering the consumers.
+type Status struct {
+	Context     string `json:"context"`
+	Environment string `json:"environment"`
+	URL         string `json:"url"`
+	User        string `json:"user,omitempty"`
+	LoggedIn    bool   `json:"logged_in"`
+	Cluster     string `json:"cluster,omitempty"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	tmpl, err := template.New("status").Parse(args.format)
+	if err != nil {
+		return fmt.Errorf("Invalid format: %v", err)
+	}
+
+	// Check the file before loading the configuration, as loading it when it doesn't exist
+	// returns an empty configuration:
+	file, err := config.Location()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	context, err := config.SelectedContext()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	status := &Status{
+		Context:     context,
+		Environment: environment(cfg.URL),
+		URL:         cfg.URL,
+		User:        cfg.UserName(),
+		Cluster:     cfg.DefaultCluster,
+	}
+	status.LoggedIn, _, err = cfg.Armed()
+	if err != nil {
+		return err
+	}
+
+	if args.json {
+		data, err := json.Marshal(status)
+		if err != nil {
+			return err
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	err = tmpl.Execute(os.Stdout, status)
+	if err != nil {
+		return fmt.Errorf("Can't print status: %v", err)
+	}
+	fmt.Println()
+	return nil
+}
+
+// environment returns the name of the well known environment of the given URL, or its host name
+// if it isn't one of them.
+func environment(text string) string {
+	name := urls.Environment(text)
+	if name != "" {
+		return name
+	}
+	parsed, err := url.Parse(text)
+	if err != nil || parsed.Host == "" {
+		return text
+	}
+	return parsed.Hostname()
+}
@@ -26,8 +26,11 @@ import (
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/openshift-online/ocm-cli/pkg/account"
 	"github.com/openshift-online/ocm-cli/pkg/arguments"
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+	"github.com/openshift-online/ocm-cli/pkg/ghactions"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	"github.com/openshift-online/ocm-cli/pkg/provider"
 	"github.com/openshift-online/ocm-cli/pkg/utils"
@@ -43,8 +46,9 @@ var args struct {
 	clusterName string
 
 	// flags
-	interactive bool
-	dryRun      bool
+	interactive    bool
+	dryRun         bool
+	useOrgDefaults bool
 
 	region                string
 	version               string
@@ -72,6 +76,10 @@ var args struct {
 	machineCIDR net.IPNet
 	serviceCIDR net.IPNet
 	podCIDR     net.IPNet
+
+	// Organization defaults
+	orgDefaults   map[string]bool
+	computeLabels map[string]string
 }
 
 const clusterNameHelp = "will be used when generating a sub-domain for your cluster on openshiftapps.com."
@@ -102,12 +110,19 @@ var Cmd = &cobra.Command{
 func init() {
 	fs := Cmd.Flags()
 	arguments.AddInteractiveFlag(fs, &args.interactive)
-	fs.BoolVar(
+	dryrun.BoolVar(
+		fs,
 		&args.dryRun,
-		"dry-run",
-		false,
 		"Simulate creating the cluster.",
 	)
+	fs.BoolVar(
+		&args.useOrgDefaults,
+		"use-org-defaults",
+		false,
+		"Use the default cluster settings of the organization for the options that aren't "+
+			"explicitly given. See `ocm account org-defaults`.",
+	)
+	ghactions.AddFlag(fs)
 
 	arguments.AddProviderFlag(fs, &args.provider)
 	Cmd.RegisterFlagCompletionFunc("provider", arguments.MakeCompleteFunc(osdProviderOptions))
@@ -359,7 +374,11 @@ func minComputeNodes(ccs bool, multiAZ bool) (min int) {
 }
 
 func preRun(cmd *cobra.Command, argv []string) error {
-	var err error
+	err := ghactions.CheckFlag()
+	if err != nil {
+		return err
+	}
+
 	// Create the client for the OCM API:
 	connection, err := ocm.NewConnection().Build()
 	if err != nil {
@@ -375,6 +394,13 @@ func preRun(cmd *cobra.Command, argv []string) error {
 	// Validate flags / ask for missing data.
 	fs := cmd.Flags()
 
+	if args.useOrgDefaults {
+		err = applyOrgDefaults(connection, fs)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Only offer the 2 providers known to support OSD now;
 	// but don't validate if set, to not block `ocm` CLI from creating clusters on future providers.
 	providers, _ := osdProviderOptions(connection)
@@ -415,7 +441,8 @@ func preRun(cmd *cobra.Command, argv []string) error {
 		return err
 	}
 
-	if cmd.Flags().Changed("channel-group") && !cmd.Flags().Changed("version") {
+	if cmd.Flags().Changed("channel-group") && !args.orgDefaults["channel-group"] &&
+		!cmd.Flags().Changed("version") {
 		return fmt.Errorf("Version is required for channel group '%s'", args.channelGroup)
 	}
 
@@ -490,6 +517,11 @@ func preRun(cmd *cobra.Command, argv []string) error {
 	if err != nil {
 		return err
 	}
+
+	err = validateNetwork()
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -523,6 +555,7 @@ func run(cmd *cobra.Command, argv []string) error {
 		Expiration:         expiration,
 		ComputeMachineType: args.computeMachineType,
 		ComputeNodes:       args.computeNodes,
+		ComputeLabels:      args.computeLabels,
 		Autoscaling:        args.autoscaling,
 		NetworkType:        args.networkType,
 		MachineCIDR:        args.machineCIDR,
@@ -533,21 +566,53 @@ func run(cmd *cobra.Command, argv []string) error {
 		EtcdEncryption:     args.etcdEncryption,
 	}
 
+	// Make sure that the cloud provider credentials don't appear in the logs of the workflow:
+	if ghactions.Enabled() {
+		for _, secret := range []string{
+			args.ccs.AWS.AccessKeyID,
+			args.ccs.AWS.SecretAccessKey,
+			args.ccs.GCP.PrivateKeyID,
+			args.ccs.GCP.PrivateKey,
+		} {
+			ghactions.Mask(os.Stdout, secret)
+		}
+	}
+
 	cluster, err := c.CreateCluster(connection.ClustersMgmt().V1(), clusterConfig, args.dryRun)
 	if err != nil {
-		return fmt.Errorf("Failed to create cluster: %v", err)
+		return fmt.Errorf("Failed to create cluster: %w", err)
 	}
 
 	// Print the result:
 	if cluster == nil {
 		if args.dryRun {
 			fmt.Println("dry run: Would be successful.")
+			if ghactions.Enabled() {
+				ghactions.Notice(os.Stdout, fmt.Sprintf(
+					"Cluster '%s' would be created successfully", args.clusterName,
+				))
+			}
 		}
 	} else {
 		err = c.PrintClusterDescription(connection, cluster)
 		if err != nil {
 			return err
 		}
+		if ghactions.Enabled() {
+			ghactions.Notice(os.Stdout, fmt.Sprintf(
+				"Cluster '%s' created with identifier '%s'", cluster.Name(), cluster.ID(),
+			))
+			err = ghactions.SetOutputs(map[string]string{
+				"cluster_id":   cluster.ID(),
+				"cluster_name": cluster.Name(),
+				"api_url":      cluster.API().URL(),
+				"console_url":  cluster.Console().URL(),
+				"state":        string(cluster.State()),
+			})
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -559,6 +624,40 @@ func wasClusterWideProxyReceived() bool {
 		(args.clusterWideProxy.AdditionalTrustBundleFile != nil && *args.clusterWideProxy.AdditionalTrustBundleFile != "")
 }
 
+// applyOrgDefaults sets the options that weren't explicitly given to the default cluster settings
+// of the organization of the current user.
+func applyOrgDefaults(connection *sdk.Connection, fs *pflag.FlagSet) error {
+	orgID, err := account.CurrentOrganization(connection)
+	if err != nil {
+		return err
+	}
+	defaults, err := account.GetClusterDefaults(connection, orgID)
+	if err != nil {
+		return err
+	}
+	args.orgDefaults = map[string]bool{}
+	for _, name := range account.ClusterDefaultsOptions {
+		value, ok := defaults.Options[name]
+		if !ok || fs.Changed(name) {
+			continue
+		}
+		err = fs.Set(name, value)
+		if err != nil {
+			return fmt.Errorf("Invalid organization default for '%s': %v", name, err)
+		}
+		args.orgDefaults[name] = true
+		fmt.Fprintf(os.Stderr, "Using organization default for '%s': %s\n", name, value)
+	}
+	if len(defaults.Labels) > 0 {
+		args.computeLabels = defaults.Labels
+		fmt.Fprintf(
+			os.Stderr, "Using organization default machine pool labels: %s\n",
+			c.PrintLabels(defaults.Labels),
+		)
+	}
+	return nil
+}
+
 // promptName checks and/or reads the cluster name
 func promptName(argv []string) error {
 	if len(argv) == 1 && argv[0] != "" {
@@ -986,6 +1085,29 @@ func promptNetwork(fs *pflag.FlagSet) error {
 	return nil
 }
 
+// validateNetwork checks that the machine, service and pod CIDRs don't overlap with each other or
+// with the well known reserved ranges.
+func validateNetwork() error {
+	ranges := []c.NetworkRange{
+		{Name: "option '--machine-cidr'", CIDR: ipNet(args.machineCIDR)},
+		{Name: "option '--service-cidr'", CIDR: ipNet(args.serviceCIDR)},
+		{Name: "option '--pod-cidr'", CIDR: ipNet(args.podCIDR)},
+	}
+	conflicts := c.NetworkConflicts(ranges, args.networkType)
+	if len(conflicts) > 0 {
+		return fmt.Errorf("Network ranges conflict:\n  %s", strings.Join(conflicts, "\n  "))
+	}
+	return nil
+}
+
+// ipNet returns a pointer to the given network, or nil if it hasn't been set.
+func ipNet(value net.IPNet) *net.IPNet {
+	if value.IP == nil {
+		return nil
+	}
+	return &value
+}
+
 func validateComputeNodes() error {
 	min := minComputeNodes(args.ccs.Enabled, args.multiAZ)
 	if args.computeNodes < min {
@@ -20,6 +20,7 @@ import (
 	"github.com/openshift-online/ocm-cli/cmd/ocm/create/machinepool"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/create/upgradepolicy"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/create/user"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
 	"github.com/spf13/cobra"
 )
 
@@ -37,4 +38,5 @@ func init() {
 	Cmd.AddCommand(machinepool.Cmd)
 	Cmd.AddCommand(upgradepolicy.Cmd)
 	Cmd.AddCommand(user.Cmd)
+	readonly.Mark(Cmd)
 }
@@ -342,7 +342,7 @@ func run(cmd *cobra.Command, argv []string) error {
 		Body(idp).
 		Send()
 	if err != nil {
-		return fmt.Errorf("Failed to add IDP to cluster '%s': %v", clusterKey, err)
+		return fmt.Errorf("Failed to add IDP to cluster '%s': %w", clusterKey, err)
 	}
 
 	fmt.Printf(
@@ -143,7 +143,7 @@ func run(cmd *cobra.Command, argv []string) error {
 		Body(ingress).
 		Send()
 	if err != nil {
-		return fmt.Errorf("Failed to add ingress to cluster '%s': %v", clusterKey, err)
+		return fmt.Errorf("Failed to add ingress to cluster '%s': %w", clusterKey, err)
 	}
 	return nil
 }
@@ -32,6 +32,7 @@ var args struct {
 	autoscaling  c.Autoscaling
 	labels       string
 	taints       string
+	imds         string
 }
 
 var Cmd = &cobra.Command{
@@ -47,7 +48,9 @@ var Cmd = &cobra.Command{
   # Add a machine pool mp-1 with labels and m5.xlarge instance type to a cluster
   ocm create machinepool --cluster mycluster --instance-type m5.xlarge --replicas 3 --labels "foo=bar,bar=baz" mp-1
   # Add a machine pool mp-1 with taints and m5.xlarge instance type to a cluster
-  ocm create machinepool --cluster mycluster --instance-type m5.xlarge --replicas 3 --taints "foo=bar:NoSchedule" mp-1`,
+  ocm create machinepool --cluster mycluster --instance-type m5.xlarge --replicas 3 --taints "foo=bar:NoSchedule" mp-1
+  # Add a machine pool mp-1 whose nodes require IMDSv2 to a cluster
+  ocm create machinepool --cluster mycluster --instance-type m5.xlarge --replicas 3 --ec2-metadata-http-tokens required mp-1`,
 	RunE: run,
 }
 
@@ -98,6 +101,8 @@ func init() {
 		"Taints for machine pool. Format should be a comma-separated list of 'key=value:scheduleType'. "+
 			"This list will overwrite any modifications made to Node taints on an ongoing basis.",
 	)
+
+	arguments.AddEC2MetadataHTTPTokensFlag(flags, &args.imds)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -141,6 +146,13 @@ func run(cmd *cobra.Command, argv []string) error {
 		}
 	}
 
+	err := arguments.CheckOneOf(
+		cmd.Flags(), "ec2-metadata-http-tokens", arguments.EC2MetadataHTTPTokensOptions,
+	)
+	if err != nil {
+		return err
+	}
+
 	isMinReplicasSet := cmd.Flags().Changed("min-replicas")
 	isMaxReplicasSet := cmd.Flags().Changed("max-replicas")
 	isReplicasSet := cmd.Flags().Changed("replicas")
@@ -181,6 +193,11 @@ func run(cmd *cobra.Command, argv []string) error {
 	if cluster.State() != cmv1.ClusterStateReady {
 		return fmt.Errorf("Cluster '%s' is not yet ready", clusterKey)
 	}
+	if args.imds != "" && cluster.CloudProvider().ID() != "aws" {
+		return fmt.Errorf(
+			"Option '--ec2-metadata-http-tokens' can only be used with AWS clusters",
+		)
+	}
 
 	machineTypeList, err := provider.GetMachineTypeOptions(connection.ClustersMgmt().V1(),
 		cluster.CloudProvider().ID(),
@@ -213,13 +230,23 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Failed to create machine pool for cluster '%s': %v", clusterKey, err)
 	}
 
-	_, err = clusterCollection.Cluster(cluster.ID()).
-		MachinePools().
-		Add().
-		Body(machinePool).
-		Send()
+	// The version of the SDK that we use doesn't support the IMDSv2 setting, so when it is
+	// needed we use a raw request:
+	if args.imds != "" {
+		err = c.AddMachinePool(connection, cluster.ID(), machinePool, &c.MachinePoolDetails{
+			AWS: &c.MachinePoolAWSDetails{
+				EC2MetadataHTTPTokens: args.imds,
+			},
+		})
+	} else {
+		_, err = clusterCollection.Cluster(cluster.ID()).
+			MachinePools().
+			Add().
+			Body(machinePool).
+			Send()
+	}
 	if err != nil {
-		return fmt.Errorf("Failed to add machine pool to cluster '%s': %v", clusterKey, err)
+		return fmt.Errorf("Failed to add machine pool to cluster '%s': %w", clusterKey, err)
 	}
 	return nil
 }
@@ -18,11 +18,13 @@ package upgradepolicy
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ghactions"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 
@@ -32,6 +34,7 @@ import (
 
 var args struct {
 	clusterKey string
+	nodePool   string
 }
 
 var Cmd = &cobra.Command{
@@ -39,8 +42,10 @@ var Cmd = &cobra.Command{
 	Aliases: []string{"upgradepolicy", "upgrade-policies", "upgradepolicys"},
 	Short:   "set an upgrade policy for the cluster",
 	Long:    "set a manual or automatic upgrade policy for the cluster",
-	Example: " ocm create upgrade-policy --cluster mycluster\n",
-	RunE:    run,
+	Example: ` ocm create upgrade-policy --cluster mycluster
+ ocm create upgrade-policy --cluster mycluster --node-pool workers
+`,
+	RunE: run,
 }
 
 func init() {
@@ -55,9 +60,24 @@ func init() {
 	)
 	//nolint:gosec
 	Cmd.MarkFlagRequired("cluster")
+
+	flags.StringVar(
+		&args.nodePool,
+		"node-pool",
+		"",
+		"Identifier of the node pool to upgrade. Only for clusters with hosted control "+
+			"planes, where node pools are upgraded independently of the control plane.",
+	)
+
+	ghactions.AddFlag(flags)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	err := ghactions.CheckFlag()
+	if err != nil {
+		return err
+	}
+
 	// Check that the cluster key (name, identifier or external identifier) given by the user
 	// is reasonably safe so that there is no risk of SQL injection:
 	clusterKey := args.clusterKey
@@ -83,10 +103,20 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("failed to get cluster '%s': %v", clusterKey, err)
 	}
 
+	// Get the node pool, if the policy is for a node pool instead of for the control plane:
+	var nodePool *c.NodePool
+	if args.nodePool != "" {
+		nodePool, _, err = c.GetNodePool(connection, cluster.ID(), args.nodePool)
+		if err != nil {
+			return err
+		}
+	}
+
 	var scheduleType string
 	var version string
 	var upgradePreference string
 	var timestamp time.Time
+	var cronExpression string
 
 	prompt := &survey.Select{
 		Message: "Select policy type",
@@ -97,8 +127,6 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Failed to get a policy type")
 	}
 
-	var upgradeBuilder *cmv1.UpgradePolicyBuilder
-
 	if scheduleType == "automatic" {
 		prompt = &survey.Select{
 			Message: "Select day",
@@ -147,16 +175,18 @@ func run(cmd *cobra.Command, argv []string) error {
 			return nil
 		}
 
-		cronExpression := fmt.Sprintf("0 %d * * %d", hourInt, dayInt)
-
-		upgradeBuilder = cmv1.NewUpgradePolicy().
-			ScheduleType("automatic").
-			Schedule(cronExpression)
+		cronExpression = fmt.Sprintf("0 %d * * %d", hourInt, dayInt)
 
 	} else {
 
-		availableUpgrades, err := c.GetAvailableUpgrades(
-			connection.ClustersMgmt().V1(), c.GetVersionID(cluster), cluster.Product().ID())
+		var availableUpgrades []string
+		if nodePool != nil {
+			availableUpgrades, err = c.GetNodePoolAvailableUpgrades(
+				connection.ClustersMgmt().V1(), cluster, nodePool)
+		} else {
+			availableUpgrades, err = c.GetAvailableUpgrades(
+				connection.ClustersMgmt().V1(), c.GetVersionID(cluster), cluster.Product().ID())
+		}
 		if err != nil {
 			return fmt.Errorf("Failed to find available upgrades: %v", err)
 		}
@@ -225,7 +255,35 @@ func run(cmd *cobra.Command, argv []string) error {
 			timestamp, _ = time.Parse(time.RFC3339, desiredTime)
 			fmt.Println(timestamp)
 		}
+	}
 
+	// Node pool upgrade policies aren't supported by the SDK yet, so they are sent with a raw
+	// request:
+	if nodePool != nil {
+		policy := &c.NodePoolUpgradePolicy{
+			ScheduleType: scheduleType,
+			UpgradeType:  "NodePool",
+		}
+		if scheduleType == "automatic" {
+			policy.Schedule = cronExpression
+		} else {
+			policy.Version = version
+			policy.NextRun = &timestamp
+		}
+		policy, err = c.AddNodePoolUpgradePolicy(connection, cluster.ID(), nodePool.ID, policy)
+		if err != nil {
+			return err
+		}
+		fmt.Println("upgrade policy successfully created")
+		return report(cluster, nodePool.ID, policy.ID, scheduleType, version, cronExpression, timestamp)
+	}
+
+	var upgradeBuilder *cmv1.UpgradePolicyBuilder
+	if scheduleType == "automatic" {
+		upgradeBuilder = cmv1.NewUpgradePolicy().
+			ScheduleType("automatic").
+			Schedule(cronExpression)
+	} else {
 		upgradeBuilder = cmv1.NewUpgradePolicy().
 			ScheduleType("manual").
 			NextRun(timestamp).
@@ -237,15 +295,46 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Failed to set an upgrade policy for cluster '%s': %v", clusterKey, err)
 	}
 
-	_, err = clusterCollection.Cluster(cluster.ID()).
+	response, err := clusterCollection.Cluster(cluster.ID()).
 		UpgradePolicies().
 		Add().
 		Body(upgradePolicy).
 		Send()
 	if err != nil {
-		return fmt.Errorf("Failed to create upgrade policy for cluster: %v", err)
+		return fmt.Errorf("Failed to create upgrade policy for cluster: %w", err)
 	}
 	fmt.Println("upgrade policy successfully created")
 
-	return nil
+	return report(cluster, "", response.Body().ID(), scheduleType, version, cronExpression, timestamp)
+}
+
+// report writes the GitHub Actions annotation and step outputs for the upgrade policy that has
+// been created.
+func report(cluster *cmv1.Cluster, nodePoolID, policyID, scheduleType, version, schedule string,
+	nextRun time.Time) error {
+	if !ghactions.Enabled() {
+		return nil
+	}
+	outputs := map[string]string{
+		"cluster_id":        cluster.ID(),
+		"upgrade_policy_id": policyID,
+		"schedule_type":     scheduleType,
+	}
+	if nodePoolID != "" {
+		outputs["node_pool_id"] = nodePoolID
+	}
+	if scheduleType == "automatic" {
+		outputs["schedule"] = schedule
+		ghactions.Notice(os.Stdout, fmt.Sprintf(
+			"Automatic upgrades of cluster '%s' scheduled with '%s'", cluster.Name(), schedule,
+		))
+	} else {
+		outputs["version"] = version
+		outputs["next_run"] = nextRun.UTC().Format(time.RFC3339)
+		ghactions.Notice(os.Stdout, fmt.Sprintf(
+			"Upgrade of cluster '%s' to version '%s' scheduled for %s",
+			cluster.Name(), version, outputs["next_run"],
+		))
+	}
+	return ghactions.SetOutputs(outputs)
 }
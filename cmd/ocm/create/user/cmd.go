@@ -14,6 +14,7 @@ limitations under the License.
 package user
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/spf13/cobra"
 
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/curl"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 )
 
@@ -121,6 +123,9 @@ func run(cmd *cobra.Command, argv []string) error {
 			Add().
 			Body(user).
 			Send()
+		if errors.Is(err, curl.ErrNotSent) {
+			continue
+		}
 		if err != nil {
 			fmt.Printf("Failed to add '%s' user '%s' to cluster '%s': %v\n", args.group, username, clusterKey, err)
 			failedToAddUser = true
@@ -17,9 +17,11 @@ limitations under the License.
 package delete
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-cli/cmd/ocm/delete/idp"
@@ -29,20 +31,28 @@ import (
 	"github.com/openshift-online/ocm-cli/cmd/ocm/delete/user"
 	"github.com/openshift-online/ocm-cli/pkg/arguments"
 	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/curl"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/hooks"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
 	"github.com/openshift-online/ocm-cli/pkg/urls"
 )
 
 var args struct {
-	parameter []string
-	header    []string
+	parameter    []string
+	header       []string
+	idsFromStdin bool
 }
 
 var Cmd = &cobra.Command{
-	Use:       "delete [flags] PATH",
-	Short:     "Send a DELETE request",
-	Long:      "Send a DELETE request to the given path.",
+	Use:   "delete [flags] PATH",
+	Short: "Send a DELETE request",
+	Long: "Send a DELETE request to the given path. With the '--ids-from-stdin' option a " +
+		"request is sent for each identifier read from the standard input, appending it to " +
+		"the given resource or path.",
+	Example: `  # Delete the clusters listed in a file
+  ocm delete cluster --ids-from-stdin < clusters.txt`,
 	RunE:      run,
 	ValidArgs: urls.Resources(),
 }
@@ -51,17 +61,41 @@ func init() {
 	fs := Cmd.Flags()
 	arguments.AddParameterFlag(fs, &args.parameter)
 	arguments.AddHeaderFlag(fs, &args.header)
+	arguments.AddIDsFromStdinFlag(fs, &args.idsFromStdin)
 	Cmd.AddCommand(idp.Cmd)
 	Cmd.AddCommand(ingress.Cmd)
 	Cmd.AddCommand(machinepool.Cmd)
 	Cmd.AddCommand(upgradepolicy.Cmd)
 	Cmd.AddCommand(user.Cmd)
+	readonly.Mark(Cmd)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
-	path, err := urls.Expand(argv)
-	if err != nil {
-		return fmt.Errorf("Could not create URI: %v", err)
+	var paths []string
+	if args.idsFromStdin {
+		if len(argv) != 1 {
+			return fmt.Errorf(
+				"Option '--ids-from-stdin' requires exactly one resource or path, " +
+					"for example 'cluster'",
+			)
+		}
+		ids, err := arguments.IDs(nil, true)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			path, err := urls.ExpandID(argv[0], id)
+			if err != nil {
+				return fmt.Errorf("Could not create URI: %v", err)
+			}
+			paths = append(paths, path)
+		}
+	} else {
+		path, err := urls.Expand(argv)
+		if err != nil {
+			return fmt.Errorf("Could not create URI: %v", err)
+		}
+		paths = append(paths, path)
 	}
 
 	// Create the client for the OCM API:
@@ -71,30 +105,19 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 	defer connection.Close()
 
-	// Create and populate the request:
-	request := connection.Delete()
-	err = arguments.ApplyPathArg(request, path)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Can't parse path '%s': %v\n", path, err)
-		os.Exit(1)
-	}
-	arguments.ApplyParameterFlag(request, args.parameter)
-	arguments.ApplyHeaderFlag(request, args.header)
-
-	// Send the request:
-	response, err := request.Send()
-	if err != nil {
-		return fmt.Errorf("Can't send request: %v", err)
-	}
-	status := response.Status()
-	body := response.Bytes()
-	if status < 400 {
-		err = dump.Pretty(os.Stdout, body)
-	} else {
-		err = dump.Pretty(os.Stderr, body)
-	}
-	if err != nil {
-		return fmt.Errorf("Can't print body: %v", err)
+	// Send the requests:
+	failed := 0
+	for _, path := range paths {
+		status, err := send(connection, path)
+		if errors.Is(err, curl.ErrNotSent) && len(paths) > 1 {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if status >= 400 {
+			failed++
+		}
 	}
 
 	// Load the configuration file:
@@ -117,9 +140,45 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 
 	// Bye:
-	if status >= 400 {
-		os.Exit(1)
+	if failed > 0 {
+		if len(paths) > 1 {
+			fmt.Fprintf(os.Stderr, "Failed to delete %d of %d objects\n", failed, len(paths))
+		}
+		hooks.Exit(1)
 	}
 
 	return nil
 }
+
+// send sends the delete request for the given path and prints the response body. It returns the
+// status code of the response.
+func send(connection *sdk.Connection, path string) (status int, err error) {
+	// Create and populate the request:
+	request := connection.Delete()
+	err = arguments.ApplyPathArg(request, path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Can't parse path '%s': %v\n", path, err)
+		hooks.Exit(1)
+	}
+	arguments.ApplyParameterFlag(request, args.parameter)
+	arguments.ApplyHeaderFlag(request, args.header)
+
+	// Send the request:
+	response, err := request.Send()
+	if err != nil {
+		err = fmt.Errorf("Can't send request: %w", err)
+		return
+	}
+	status = response.Status()
+	body := response.Bytes()
+	if status < 400 {
+		err = dump.Pretty(os.Stdout, body)
+	} else {
+		err = dump.Pretty(os.Stderr, body)
+	}
+	if err != nil {
+		err = fmt.Errorf("Can't print body: %v", err)
+		return
+	}
+	return
+}
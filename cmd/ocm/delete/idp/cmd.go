@@ -113,7 +113,10 @@ func run(cmd *cobra.Command, argv []string) error {
 		Delete().
 		Send()
 	if err != nil {
-		return fmt.Errorf("Failed to delete identity provider '%s' on cluster '%s'", idpName, clusterKey)
+		return fmt.Errorf(
+			"Failed to delete identity provider '%s' on cluster '%s': %w",
+			idpName, clusterKey, err,
+		)
 	}
 	fmt.Printf("Deleted identity provider '%s' on cluster '%s'\n", idpName, clusterKey)
 	return nil
@@ -126,7 +126,10 @@ func run(cmd *cobra.Command, argv []string) error {
 		Delete().
 		Send()
 	if err != nil {
-		return fmt.Errorf("Failed to delete ingress '%s' on cluster '%s'", ingress.ID(), clusterKey)
+		return fmt.Errorf(
+			"Failed to delete ingress '%s' on cluster '%s': %w",
+			ingress.ID(), clusterKey, err,
+		)
 	}
 
 	fmt.Printf("Deleted ingress '%s' on cluster '%s'\n", ingressID, clusterKey)
@@ -94,7 +94,10 @@ func run(cmd *cobra.Command, argv []string) error {
 		Delete().
 		Send()
 	if err != nil {
-		return fmt.Errorf("Failed to delete machine pool '%s' on cluster '%s'", machinePoolID, clusterKey)
+		return fmt.Errorf(
+			"Failed to delete machine pool '%s' on cluster '%s': %w",
+			machinePoolID, clusterKey, err,
+		)
 	}
 
 	fmt.Printf("Deleted machine pool '%s' on cluster '%s'\n", machinePoolID, clusterKey)
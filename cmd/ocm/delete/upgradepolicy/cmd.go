@@ -24,6 +24,7 @@ import (
 
 var args struct {
 	clusterKey string
+	nodePool   string
 }
 
 var Cmd = &cobra.Command{
@@ -32,7 +33,9 @@ var Cmd = &cobra.Command{
 	Short:   "Delete cluster upgrade policy",
 	Long:    "Delete the upgrade policy of a cluster.",
 	Example: `  # Delete upgrade policy from a cluster named 'mycluster'
-  ocm delete upgradepolicy --cluster=mycluster <id>`,
+  ocm delete upgradepolicy --cluster=mycluster <id>
+  # Delete upgrade policy from node pool 'workers' of a cluster named 'mycluster'
+  ocm delete upgradepolicy --cluster=mycluster --node-pool=workers <id>`,
 	RunE: run,
 }
 
@@ -48,6 +51,14 @@ func init() {
 	)
 	//nolint:gosec
 	Cmd.MarkFlagRequired("cluster")
+
+	flags.StringVar(
+		&args.nodePool,
+		"node-pool",
+		"",
+		"Identifier of the node pool that the upgrade policy belongs to. Only for clusters "+
+			"with hosted control planes.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -87,6 +98,18 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
 	}
 
+	if args.nodePool != "" {
+		err = c.DeleteNodePoolUpgradePolicy(connection, cluster.ID(), args.nodePool, upgradePolicyID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf(
+			"Deleted upgrade policy '%s' on node pool '%s' of cluster '%s'\n",
+			upgradePolicyID, args.nodePool, clusterKey,
+		)
+		return nil
+	}
+
 	_, err = clusterCollection.
 		Cluster(cluster.ID()).
 		UpgradePolicies().
@@ -94,7 +117,10 @@ func run(cmd *cobra.Command, argv []string) error {
 		Delete().
 		Send()
 	if err != nil {
-		return fmt.Errorf("Failed to delete upgrade policy '%s' on cluster '%s'", upgradePolicyID, clusterKey)
+		return fmt.Errorf(
+			"Failed to delete upgrade policy '%s' on cluster '%s': %w",
+			upgradePolicyID, clusterKey, err,
+		)
 	}
 
 	fmt.Printf("Deleted upgrade policy '%s' on cluster '%s'\n", upgradePolicyID, clusterKey)
@@ -112,7 +112,10 @@ func run(cmd *cobra.Command, argv []string) error {
 		Delete().
 		Send()
 	if err != nil {
-		return fmt.Errorf("Failed to delete '%s' user '%s' on cluster '%s'", args.group, username, clusterKey)
+		return fmt.Errorf(
+			"Failed to delete '%s' user '%s' on cluster '%s': %w",
+			args.group, username, clusterKey, err,
+		)
 	}
 
 	fmt.Printf("Deleted '%s' user '%s' on cluster '%s'\n", args.group, username, clusterKey)
@@ -22,25 +22,42 @@ import (
 	"os"
 	"regexp"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
 	clusterpkg "github.com/openshift-online/ocm-cli/pkg/cluster"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/hooks"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/parallel"
 )
 
 var args struct {
-	json   bool
-	output bool
+	json         bool
+	output       bool
+	idsFromStdin bool
+	parallel     int
 }
 
 var Cmd = &cobra.Command{
-	Use:   "cluster [flags] {NAME|ID|EXTERNAL_ID}",
-	Short: "Show details of a cluster",
-	Long:  "Show details of a cluster identified by name, identifier or external identifier",
-	RunE:  run,
+	Use:     "cluster [flags] {NAME|ID|EXTERNAL_ID}...",
+	Aliases: []string{"clusters"},
+	Short:   "Show details of a cluster",
+	Long: "Show details of a cluster identified by name, identifier or external identifier. " +
+		"Multiple clusters can be given in the command line, or read from the standard " +
+		"input with the '--ids-from-stdin' option. The details of multiple clusters are " +
+		"retrieved concurrently and printed in the order given, and with the '--json' " +
+		"option they are printed as a single JSON array.",
+	Example: `  # Describe the clusters that are in error state
+  ocm list clusters --parameter search="state = 'error'" --no-headers | \
+  ocm describe cluster --ids-from-stdin
+
+  # Get the details of two clusters as a JSON array
+  ocm describe clusters mycluster yourcluster --json`,
+	RunE: run,
 }
 
 func init() {
@@ -58,31 +75,44 @@ func init() {
 		false,
 		"Output the entire JSON structure",
 	)
+	arguments.AddIDsFromStdinFlag(flags, &args.idsFromStdin)
+	flags.IntVar(
+		&args.parallel,
+		"parallel",
+		10,
+		"Maximum number of clusters whose details are retrieved simultaneously.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
-	// Check that there is exactly one cluster name, identifir or external identifier in the
-	// command line arguments:
-	if len(argv) != 1 {
-		fmt.Fprintf(
-			os.Stderr,
-			"Expected exactly one cluster name, identifier or external identifier "+
-				"is required\n",
+	if len(argv) == 0 && !args.idsFromStdin {
+		return fmt.Errorf(
+			"At least one cluster name, identifier or external identifier is required",
 		)
-		os.Exit(1)
+	}
+	if args.parallel < 1 {
+		return fmt.Errorf("Option '--parallel' must be at least 1")
 	}
 
-	// Check that the cluster key (name, identifier or external identifier) given by the user
-	// is reasonably safe so that there is no risk of SQL injection:
-	key := argv[0]
-	if !keyRE.MatchString(key) {
-		fmt.Fprintf(
-			os.Stderr,
-			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
-				"must contain only letters, digits, dashes and underscores\n",
-			key,
-		)
-		os.Exit(1)
+	// Get the cluster names, identifiers or external identifiers from the command line or from
+	// the standard input:
+	keys, err := arguments.IDs(argv, args.idsFromStdin)
+	if err != nil {
+		return err
+	}
+
+	// Check that the cluster keys (name, identifier or external identifier) given by the user
+	// are reasonably safe so that there is no risk of SQL injection:
+	for _, key := range keys {
+		if !keyRE.MatchString(key) {
+			fmt.Fprintf(
+				os.Stderr,
+				"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+					"must contain only letters, digits, dashes and underscores\n",
+				key,
+			)
+			hooks.Exit(1)
+		}
 	}
 
 	// Create the client for the OCM API:
@@ -92,9 +122,76 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 	defer connection.Close()
 
+	// When there is only one cluster report the error directly:
+	if len(keys) == 1 {
+		return describe(connection, keys[0])
+	}
+
+	// Otherwise retrieve and render the clusters concurrently, and then print them in the
+	// order given, so that the output doesn't depend on which request finishes first:
+	clusters := make([]*cmv1.Cluster, len(keys))
+	sections := make([]*bytes.Buffer, len(keys))
+	errs := make([]error, len(keys))
+	parallel.Each(len(keys), args.parallel, func(i int) {
+		clusters[i], errs[i] = fetch(connection, keys[i])
+		if errs[i] != nil || args.json {
+			return
+		}
+		sections[i] = &bytes.Buffer{}
+		errs[i] = clusterpkg.WriteClusterDescription(sections[i], connection, clusters[i])
+	})
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			failed++
+		}
+	}
+
+	// Print the clusters as a JSON array, or as sections separated by empty lines:
+	if args.json {
+		var list []*cmv1.Cluster
+		for i, cluster := range clusters {
+			if errs[i] == nil {
+				list = append(list, cluster)
+			}
+		}
+		buf := &bytes.Buffer{}
+		err = cmv1.MarshalClusterList(list, buf)
+		if err != nil {
+			return fmt.Errorf("Failed to Marshal clusters into JSON encoder: %v", err)
+		}
+		err = dump.Pretty(os.Stdout, buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("Can't print body: %v", err)
+		}
+	} else {
+		printed := 0
+		for i, section := range sections {
+			if errs[i] != nil {
+				continue
+			}
+			if printed > 0 {
+				fmt.Println()
+			}
+			_, err = section.WriteTo(os.Stdout)
+			if err != nil {
+				return err
+			}
+			printed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("Failed to describe %d of %d clusters", failed, len(keys))
+	}
+	return nil
+}
+
+// fetch retrieves the cluster with the given key, and saves it to a file if requested.
+func fetch(connection *sdk.Connection, key string) (*cmv1.Cluster, error) {
 	cluster, err := c.GetCluster(connection, key)
 	if err != nil {
-		return fmt.Errorf("Can't retrieve cluster for key '%s': %v", key, err)
+		return nil, fmt.Errorf("Can't retrieve cluster for key '%s': %v", key, err)
 	}
 
 	if args.output {
@@ -104,16 +201,27 @@ func run(cmd *cobra.Command, argv []string) error {
 		// Attempt to create file:
 		myFile, err := os.Create(filename)
 		if err != nil {
-			return fmt.Errorf("Failed to create file: %v", err)
+			return nil, fmt.Errorf("Failed to create file: %v", err)
 		}
+		defer myFile.Close()
 
 		// Dump encoder content into file:
 		err = cmv1.MarshalCluster(cluster, myFile)
 		if err != nil {
-			return fmt.Errorf("Failed to Marshal cluster into file: %v", err)
+			return nil, fmt.Errorf("Failed to Marshal cluster into file: %v", err)
 		}
 	}
 
+	return cluster, nil
+}
+
+// describe prints the details of the cluster with the given key.
+func describe(connection *sdk.Connection, key string) error {
+	cluster, err := fetch(connection, key)
+	if err != nil {
+		return err
+	}
+
 	// Get full API response (JSON):
 	if args.json {
 		// Buffer for pretty output:
@@ -15,6 +15,9 @@ package describe
 
 import (
 	"github.com/openshift-online/ocm-cli/cmd/ocm/describe/cluster"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/describe/machinepool"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/describe/nodepool"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/describe/quota"
 	"github.com/spf13/cobra"
 )
 
@@ -26,4 +29,7 @@ var Cmd = &cobra.Command{
 
 func init() {
 	Cmd.AddCommand(cluster.Cmd)
+	Cmd.AddCommand(machinepool.Cmd)
+	Cmd.AddCommand(nodepool.Cmd)
+	Cmd.AddCommand(quota.Cmd)
 }
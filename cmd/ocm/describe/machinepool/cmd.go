@@ -0,0 +1,175 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinepool
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+// notAvailable is displayed when the server doesn't report a value.
+const notAvailable = "N/A"
+
+var args struct {
+	clusterKey string
+	json       bool
+}
+
+var Cmd = &cobra.Command{
+	Use:     "machinepool --cluster={NAME|ID|EXTERNAL_ID} MACHINE_POOL_ID",
+	Aliases: []string{"machine-pool"},
+	Short:   "Show details of a machine pool",
+	Long:    "Show details of a machine pool of a cluster.",
+	Example: `  # Show the details of machine pool 'mp1' of cluster 'mycluster'
+  ocm describe machinepool --cluster=mycluster mp1`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVarP(
+		&args.clusterKey,
+		"cluster",
+		"c",
+		"",
+		"Name or ID or external_id of the cluster that contains the machine pool (required).",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("cluster")
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the entire JSON structure",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	machinePoolID := argv[0]
+
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := args.clusterKey
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	// The version of the SDK that we use doesn't support some of the details of machine pools, like
+	// the subnets, so we use a raw request:
+	machinePool, details, body, err := c.GetMachinePool(connection, cluster.ID(), machinePoolID)
+	if err != nil {
+		return err
+	}
+
+	// The JSON output is the document returned by the server, so that it contains all the
+	// fields, even the ones that we don't know about:
+	if args.json {
+		err = dump.Pretty(os.Stdout, body)
+		if err != nil {
+			return fmt.Errorf("Can't print body: %v", err)
+		}
+		return nil
+	}
+
+	// Print the description of the machine pool:
+	replicas := fmt.Sprintf("%d", machinePool.Replicas())
+	autoscaling := machinePool.Autoscaling()
+	if autoscaling != nil {
+		replicas = fmt.Sprintf("%d-%d", autoscaling.MinReplicas(), autoscaling.MaxReplicas())
+	}
+	spot := "No"
+	spotOptions := machinePool.AWS().SpotMarketOptions()
+	if spotOptions != nil {
+		spot = "Yes (on-demand price)"
+		if maxPrice, ok := spotOptions.GetMaxPrice(); ok {
+			spot = fmt.Sprintf("Yes (max price %g)", maxPrice)
+		}
+	}
+	// Machine pools use the version of the cluster unless the server says otherwise:
+	version := cluster.Version().RawID()
+	if details.Version != nil {
+		version = details.Version.RawID
+		if version == "" {
+			version = details.Version.ID
+		}
+	}
+	currentReplicas := notAvailable
+	var message string
+	if details.Status != nil {
+		currentReplicas = fmt.Sprintf("%d", details.Status.CurrentReplicas)
+		message = details.Status.Message
+	}
+	fmt.Printf("\n"+
+		"ID:			%s\n"+
+		"Cluster ID:		%s\n"+
+		"Version:		%s\n"+
+		"Autoscaling:		%t\n"+
+		"Replicas:		%s\n"+
+		"Current replicas:	%s\n"+
+		"Instance type:		%s\n"+
+		"Labels:			%s\n"+
+		"Taints:			%s\n"+
+		"Availability zones:	%s\n"+
+		"Subnets:		%s\n"+
+		"Spot instances:		%s\n",
+		machinePool.ID(),
+		cluster.ID(),
+		version,
+		autoscaling != nil,
+		replicas,
+		currentReplicas,
+		machinePool.InstanceType(),
+		c.PrintLabels(machinePool.Labels()),
+		c.PrintTaints(machinePool.Taints()),
+		strings.Join(machinePool.AvailabilityZones(), ", "),
+		strings.Join(details.Subnets, ", "),
+		spot,
+	)
+	if cluster.CloudProvider().ID() == "aws" {
+		fmt.Printf("EC2 metadata tokens:	%s\n", details.EC2MetadataHTTPTokens())
+	}
+	if message != "" {
+		fmt.Printf("Message:		%s\n", message)
+	}
+	fmt.Println()
+
+	return nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodepool
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	clusterKey string
+	json       bool
+}
+
+var Cmd = &cobra.Command{
+	Use:     "nodepool --cluster={NAME|ID|EXTERNAL_ID} NODE_POOL_ID",
+	Aliases: []string{"node-pool"},
+	Short:   "Show details of a node pool",
+	Long:    "Show details of a node pool of a cluster with a hosted control plane.",
+	Example: `  # Show the details of node pool 'workers' of cluster 'mycluster'
+  ocm describe nodepool --cluster=mycluster workers`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVarP(
+		&args.clusterKey,
+		"cluster",
+		"c",
+		"",
+		"Name or ID or external_id of the cluster that contains the node pool (required).",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("cluster")
+	flags.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the entire JSON structure",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	nodePoolID := argv[0]
+
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := args.clusterKey
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	nodePool, body, err := c.GetNodePool(connection, cluster.ID(), nodePoolID)
+	if err != nil {
+		return err
+	}
+
+	// The JSON output is the document returned by the server, so that it contains all the
+	// fields, even the ones that we don't know about:
+	if args.json {
+		err = dump.Pretty(os.Stdout, body)
+		if err != nil {
+			return fmt.Errorf("Can't print body: %v", err)
+		}
+		return nil
+	}
+
+	// Print the description of the node pool:
+	replicas := fmt.Sprintf("%d", nodePool.Replicas)
+	if nodePool.Autoscaling != nil {
+		replicas = fmt.Sprintf(
+			"%d-%d",
+			nodePool.Autoscaling.MinReplica, nodePool.Autoscaling.MaxReplica,
+		)
+	}
+	var instanceType string
+	if nodePool.AWSNodePool != nil {
+		instanceType = nodePool.AWSNodePool.InstanceType
+	}
+	var version string
+	if nodePool.Version != nil {
+		version = nodePool.Version.RawID
+		if version == "" {
+			version = nodePool.Version.ID
+		}
+	}
+	var currentReplicas, message string
+	if nodePool.Status != nil {
+		currentReplicas = fmt.Sprintf("%d", nodePool.Status.CurrentReplicas)
+		message = nodePool.Status.Message
+	}
+	fmt.Printf("\n"+
+		"ID:			%s\n"+
+		"Cluster ID:		%s\n"+
+		"Version:		%s\n"+
+		"Autoscaling:		%t\n"+
+		"Replicas:		%s\n"+
+		"Current replicas:	%s\n"+
+		"Instance type:		%s\n"+
+		"Labels:			%s\n"+
+		"Taints:			%s\n"+
+		"Availability zone:	%s\n"+
+		"Subnet:			%s\n"+
+		"Auto repair:		%t\n",
+		nodePool.ID,
+		cluster.ID(),
+		version,
+		nodePool.Autoscaling != nil,
+		replicas,
+		currentReplicas,
+		instanceType,
+		c.PrintLabels(nodePool.Labels),
+		c.PrintNodePoolTaints(nodePool.Taints),
+		nodePool.AvailabilityZone,
+		nodePool.Subnet,
+		nodePool.AutoRepair,
+	)
+	if message != "" {
+		fmt.Printf("Message:		%s\n", message)
+	}
+	fmt.Println()
+
+	return nil
+}
@@ -0,0 +1,221 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	"github.com/spf13/cobra"
+
+	acc_util "github.com/openshift-online/ocm-cli/pkg/account"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	org string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "quota QUOTA_ID",
+	Short: "Show details of a quota",
+	Long: "Show the details of a quota cost entry of an organization: the resources that " +
+		"consume it and their billing model and cost, the SKU rules that grant it, and " +
+		"the subscriptions, clusters and add-ons, that currently consume it.",
+	Example: `  # Show why the quota for 'cluster|byoc|moa|marketplace' is consumed
+  ocm describe quota 'cluster|byoc|moa|marketplace'`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.org,
+		"org",
+		"",
+		"Organization identifier. Defaults to the organization of the current user.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// The quota identifier is used in search queries, so make sure that it doesn't contain
+	// quotes:
+	quotaID := argv[0]
+	if strings.ContainsAny(quotaID, `'"`) {
+		return fmt.Errorf("Quota identifier '%s' isn't valid: it must not contain quotes", quotaID)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Get the organization of the current user, if needed:
+	orgID := args.org
+	if orgID == "" {
+		response, err := connection.AccountsMgmt().V1().CurrentAccount().Get().Send()
+		if err != nil {
+			return fmt.Errorf("Can't retrieve current user information: %v", err)
+		}
+		orgID = response.Body().Organization().ID()
+	}
+
+	// Find the quota cost:
+	quotaResponse, err := connection.AccountsMgmt().V1().Organizations().Organization(orgID).
+		QuotaCost().
+		List().
+		Parameter("fetchRelatedResources", true).
+		Parameter("search", fmt.Sprintf("quota_id = '%s'", quotaID)).
+		Send()
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve quota: %v", err)
+	}
+	var quota *amv1.QuotaCost
+	quotaResponse.Items().Each(func(item *amv1.QuotaCost) bool {
+		if item.QuotaID() == quotaID {
+			quota = item
+			return false
+		}
+		return true
+	})
+	if quota == nil {
+		return fmt.Errorf("Quota '%s' doesn't exist in organization '%s'", quotaID, orgID)
+	}
+
+	// Get the SKU rules:
+	skuResponse, err := connection.AccountsMgmt().V1().SkuRules().List().
+		Search(fmt.Sprintf("quota_id = '%s'", quotaID)).
+		Send()
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve SKU rules: %v", err)
+	}
+
+	// Find the consumers:
+	consumers, err := findConsumers(connection, orgID, quota)
+	if err != nil {
+		return err
+	}
+
+	// Print the description of the quota:
+	fmt.Printf("\n"+
+		"Quota ID:		%s\n"+
+		"Organization ID:	%s\n"+
+		"Allowed:		%d\n"+
+		"Consumed:		%d\n",
+		quota.QuotaID(),
+		orgID,
+		quota.Allowed(),
+		quota.Consumed(),
+	)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "\nRelated resources:\n\n")
+	fmt.Fprintf(writer, "RESOURCE TYPE\tRESOURCE NAME\tPRODUCT\tBILLING MODEL\tBYOC\tAZ TYPE\tCOST\n")
+	for _, related := range quota.RelatedResources() {
+		fmt.Fprintf(
+			writer, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			related.ResourceType(), related.ResourceName(), related.Product(),
+			related.BillingModel(), related.BYOC(), related.AvailabilityZoneType(),
+			related.Cost(),
+		)
+	}
+	fmt.Fprintf(writer, "\nSKU rules:\n\n")
+	fmt.Fprintf(writer, "SKU\tALLOWED\n")
+	skuResponse.Items().Each(func(rule *amv1.SkuRule) bool {
+		fmt.Fprintf(writer, "%s\t%d\n", rule.Sku(), rule.Allowed())
+		return true
+	})
+	fmt.Fprintf(writer, "\nConsumers:\n\n")
+	fmt.Fprintf(writer, "SUBSCRIPTION ID\tCLUSTER ID\tNAME\tRESOURCE NAME\tCOUNT\tCONSUMED\n")
+	for _, consumer := range consumers {
+		fmt.Fprintf(
+			writer, "%s\t%s\t%s\t%s\t%d\t%d\n",
+			consumer.subscription.ID(), consumer.subscription.ClusterID(),
+			consumer.subscription.DisplayName(), consumer.resource.ResourceName(),
+			consumer.resource.Count(), consumer.consumed,
+		)
+	}
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// consumer is a reserved resource of a subscription that consumes the quota.
+type consumer struct {
+	subscription *amv1.Subscription
+	resource     *amv1.ReservedResource
+	consumed     int
+}
+
+// findConsumers finds the reserved resources of the active subscriptions of the organization that
+// consume the given quota.
+func findConsumers(connection *sdk.Connection, orgID string,
+	quota *amv1.QuotaCost) (result []*consumer, err error) {
+	subscriptionsClient := connection.AccountsMgmt().V1().Subscriptions()
+	request := subscriptionsClient.List().Search(fmt.Sprintf(
+		"organization_id = '%s' and status in ('Active', 'Reserved')",
+		orgID,
+	))
+	size := 100
+	index := 1
+	for {
+		response, err := request.Size(size).Page(index).Send()
+		if err != nil {
+			return nil, fmt.Errorf("Can't retrieve subscriptions: %v", err)
+		}
+		for _, subscription := range response.Items().Slice() {
+			resourcesResponse, err := subscriptionsClient.Subscription(subscription.ID()).
+				ReservedResources().
+				List().
+				Send()
+			if err != nil {
+				return nil, fmt.Errorf(
+					"Can't retrieve reserved resources of subscription '%s': %v",
+					subscription.ID(), err,
+				)
+			}
+			for _, resource := range resourcesResponse.Items().Slice() {
+				for _, related := range quota.RelatedResources() {
+					if acc_util.QuotaMatches(related, resource) {
+						result = append(result, &consumer{
+							subscription: subscription,
+							resource:     resource,
+							consumed:     resource.Count() * related.Cost(),
+						})
+						break
+					}
+				}
+			}
+		}
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+	return
+}
@@ -16,6 +16,7 @@ package cluster
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -46,7 +47,10 @@ var Cmd = &cobra.Command{
 	Short: "Edit cluster",
 	Long:  "Edit cluster.",
 	Example: `  # Edit a cluster named "mycluster" to make it private
-  ocm edit cluster mycluster --private`,
+  ocm edit cluster --cluster=mycluster --private
+
+  # Move a cluster named "mycluster" to the fast channel group
+  ocm edit cluster --cluster=mycluster --channel-group=fast`,
 	Args: cobra.NoArgs,
 	RunE: run,
 }
@@ -95,7 +99,9 @@ func init() {
 		&args.channelGroup,
 		"channel-group",
 		"",
-		"The channel group which the cluster version belongs to.",
+		"The channel group which the cluster version belongs to. The channel group must "+
+			"contain the current version of the cluster, or one of the versions it can be "+
+			"upgraded to.",
 	)
 
 	args.clusterWideProxy.HTTPProxy = new(string)
@@ -176,6 +182,21 @@ func run(cmd *cobra.Command, argv []string) error {
 	var channelGroup string
 	if cmd.Flags().Changed("channel-group") {
 		channelGroup = args.channelGroup
+		if channelGroup == "" {
+			return fmt.Errorf("Option '--channel-group' can't be empty")
+		}
+
+		// Check that the cluster will be able to receive updates from the new channel
+		// group before changing it:
+		warnings, err := c.CheckChannelGroup(
+			connection.ClustersMgmt().V1(), cluster, channelGroup,
+		)
+		if err != nil {
+			return err
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
 	}
 
 	var httpProxy *string
@@ -17,6 +17,7 @@ import (
 	"github.com/openshift-online/ocm-cli/cmd/ocm/edit/cluster"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/edit/ingress"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/edit/machinepool"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
 	"github.com/spf13/cobra"
 )
 
@@ -31,4 +32,5 @@ func init() {
 	Cmd.AddCommand(ingress.Cmd)
 	Cmd.AddCommand(cluster.Cmd)
 	Cmd.AddCommand(machinepool.Cmd)
+	readonly.Mark(Cmd)
 }
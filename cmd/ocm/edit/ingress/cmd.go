@@ -174,7 +174,7 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	ingress, err = ingressBuilder.Build()
 	if err != nil {
-		return fmt.Errorf("Failed to edit ingress for cluster '%s': %v", clusterKey, err)
+		return fmt.Errorf("Failed to edit ingress for cluster '%s': %w", clusterKey, err)
 	}
 
 	_, err = clusterCollection.
@@ -185,7 +185,7 @@ func run(cmd *cobra.Command, argv []string) error {
 		Body(ingress).
 		Send()
 	if err != nil {
-		return fmt.Errorf("Failed to edit ingress for cluster '%s': %v", clusterKey, err)
+		return fmt.Errorf("Failed to edit ingress for cluster '%s': %w", clusterKey, err)
 	}
 	return nil
 }
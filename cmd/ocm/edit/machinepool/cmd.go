@@ -30,6 +30,7 @@ var args struct {
 	autoscaling c.Autoscaling
 	labels      string
 	taints      string
+	imds        string
 }
 
 var Cmd = &cobra.Command{
@@ -40,7 +41,9 @@ var Cmd = &cobra.Command{
 	Example: `  #  Update the number of replicas for machine pool with ID 'a1b2'
   ocm edit machinepool --replicas=3 --cluster=mycluster a1b2
   # Enable autoscaling and Set 3-5 replicas on machine pool 'mp1' on cluster 'mycluster'
-  ocm edit machinepool --enable-autoscaling --min-replicas=3 max-replicas=5 --cluster=mycluster mp1`,
+  ocm edit machinepool --enable-autoscaling --min-replicas=3 max-replicas=5 --cluster=mycluster mp1
+  # Require IMDSv2 for the nodes of machine pool 'mp1' on cluster 'mycluster'
+  ocm edit machinepool --ec2-metadata-http-tokens=required --cluster=mycluster mp1`,
 	RunE: run,
 }
 
@@ -81,6 +84,8 @@ func init() {
 		"Taints for machine pool. Format should be a comma-separated list of 'key=value:scheduleType'. "+
 			"This list will overwrite any modifications made to Node taints on an ongoing basis.",
 	)
+
+	arguments.AddEC2MetadataHTTPTokensFlag(flags, &args.imds)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -92,6 +97,20 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	machinePoolID := argv[0]
 
+	err := arguments.CheckOneOf(
+		cmd.Flags(), "ec2-metadata-http-tokens", arguments.EC2MetadataHTTPTokensOptions,
+	)
+	if err != nil {
+		return err
+	}
+	if args.imds != "" && machinePoolID == "default" {
+		return fmt.Errorf(
+			"Option '--ec2-metadata-http-tokens' can't be used with the default machine pool, " +
+				"its setting is part of the cluster and can only be chosen when the cluster " +
+				"is created",
+		)
+	}
+
 	// Check that the cluster key (name, identifier or external identifier) given by the user
 	// is reasonably safe so that there is no risk of SQL injection:
 	clusterKey := args.clusterKey
@@ -117,6 +136,11 @@ func run(cmd *cobra.Command, argv []string) error {
 	if err != nil {
 		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
 	}
+	if args.imds != "" && cluster.CloudProvider().ID() != "aws" {
+		return fmt.Errorf(
+			"Option '--ec2-metadata-http-tokens' can only be used with AWS clusters",
+		)
+	}
 
 	labels := make(map[string]string)
 	if args.labels != "" {
@@ -163,13 +187,13 @@ func run(cmd *cobra.Command, argv []string) error {
 	// Editing the default machine pool is a different process
 	if machinePoolID == "default" {
 		if isReplicasSet {
-			err = validateComputeNodes(args.replicas, cluster.CCS().Enabled(), cluster.MultiAZ())
+			err = c.ValidateComputeNodes(args.replicas, cluster.CCS().Enabled(), cluster.MultiAZ())
 			if err != nil {
 				return err
 			}
 		}
 		if isMinReplicasSet {
-			err = validateComputeNodes(args.autoscaling.MinReplicas, cluster.CCS().Enabled(), cluster.MultiAZ())
+			err = c.ValidateComputeNodes(args.autoscaling.MinReplicas, cluster.CCS().Enabled(), cluster.MultiAZ())
 			if err != nil {
 				return err
 			}
@@ -214,41 +238,25 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Failed to create machine pool body for cluster '%s': %v", clusterKey, err)
 	}
 
-	_, err = clusterCollection.
-		Cluster(cluster.ID()).
-		MachinePools().
-		MachinePool(machinePoolID).
-		Update().
-		Body(machinePool).
-		Send()
-	if err != nil {
-		return fmt.Errorf("Failed to edit machine pool for cluster '%s': %v", clusterKey, err)
-	}
-	return nil
-}
-
-func validateComputeNodes(nodes int, ccs bool, multiAZ bool) error {
-	var min int
-	if ccs {
-		if multiAZ {
-			min = 3
-		} else {
-			min = 2
-		}
+	// The version of the SDK that we use doesn't support the IMDSv2 setting, so when it is
+	// needed we use a raw request:
+	if args.imds != "" {
+		err = c.UpdateMachinePool(connection, cluster.ID(), machinePool, &c.MachinePoolDetails{
+			AWS: &c.MachinePoolAWSDetails{
+				EC2MetadataHTTPTokens: args.imds,
+			},
+		})
 	} else {
-		if multiAZ {
-			min = 9
-		} else {
-			min = 4
-		}
-	}
-
-	if nodes < min {
-		return fmt.Errorf("Minimum is %d nodes", min)
+		_, err = clusterCollection.
+			Cluster(cluster.ID()).
+			MachinePools().
+			MachinePool(machinePoolID).
+			Update().
+			Body(machinePool).
+			Send()
 	}
-
-	if multiAZ && nodes%3 != 0 {
-		return fmt.Errorf("Multi-zone clusters require nodes to be multiple of 3")
+	if err != nil {
+		return fmt.Errorf("Failed to edit machine pool for cluster '%s': %w", clusterKey, err)
 	}
 	return nil
 }
@@ -0,0 +1,95 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package examples
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/examples"
+)
+
+var args struct {
+	check bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "examples [COMMAND]...",
+	Short: "Display examples of commands",
+	Long: "Display the examples of a command, including samples of their output. When no " +
+		"command is given it lists the commands that have examples.",
+	Example: `  # List the commands that have examples
+  ocm examples
+
+  # Display the examples of the 'list clusters' command
+  ocm examples list clusters`,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.check,
+		"check",
+		false,
+		"Check that all the examples use commands, flags and arguments that exist.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	registry, err := examples.Load()
+	if err != nil {
+		return err
+	}
+	root := cmd.Root()
+
+	if args.check {
+		errs := registry.Check(root)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("Found %d invalid examples", len(errs))
+		}
+		fmt.Printf("Checked examples of %d commands\n", len(registry.Commands()))
+		return nil
+	}
+
+	if len(argv) == 0 {
+		for _, command := range registry.Commands() {
+			fmt.Printf("%s %s\n", root.Name(), command)
+		}
+		return nil
+	}
+
+	target, rest, err := root.Find(argv)
+	if err != nil || target == root || len(rest) > 0 {
+		return fmt.Errorf("Command '%s' doesn't exist", strings.Join(argv, " "))
+	}
+	text := examples.Format(registry.For(target), true)
+	if text == "" {
+		text = target.Example
+	}
+	if text == "" {
+		return fmt.Errorf("Command '%s' doesn't have examples", examples.Path(target))
+	}
+	fmt.Println(text)
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-cli/cmd/ocm/fail/job"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
 )
 
 var Cmd = &cobra.Command{
@@ -27,4 +28,5 @@ var Cmd = &cobra.Command{
 
 func init() {
 	Cmd.AddCommand(job.Cmd)
+	readonly.Mark(Cmd)
 }
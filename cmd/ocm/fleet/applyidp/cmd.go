@@ -0,0 +1,375 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applyidp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/bulk"
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/curl"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/parallel"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+	"github.com/openshift-online/ocm-cli/pkg/search"
+)
+
+var args struct {
+	file        string
+	search      string
+	dryRun      bool
+	forceUpdate bool
+	results     string
+	parallel    int
+}
+
+var Cmd = &cobra.Command{
+	Use:   "apply-idp --file=FILE --search=EXPRESSION",
+	Short: "Apply an identity provider to multiple clusters",
+	Long: "Create or update an identity provider in all the ready clusters that match a " +
+		"search expression. The file contains the identity provider in the JSON format " +
+		"used by the API, including the secrets. Clusters that already have an identity " +
+		"provider with the same name and settings are skipped.\n\n" +
+		"The API doesn't return secrets, like client secrets or passwords, so changes " +
+		"that only affect secrets aren't detected. Use the --force-update option to " +
+		"update the existing identity providers even if no changes are detected, for " +
+		"example to rotate a client secret.\n\n" +
+		"The command exits with code 0 when all the clusters succeeded, with code 2 " +
+		"when only some of them failed and with code 1 when all of them failed. Use " +
+		"the --results option to write the result of each cluster to a JSON file.",
+	Example: `  # Show what would be changed in the production clusters
+  ocm fleet apply-idp --file=sso.json --search="name like 'prod-%'" --dry-run
+
+  # Apply the changes
+  ocm fleet apply-idp --file=sso.json --search="name like 'prod-%'"
+
+  # Rotate the client secret of the identity provider of all the production clusters
+  ocm fleet apply-idp --file=sso.json --search="name like 'prod-%'" --force-update
+
+  # Apply the changes and save the result of each cluster for later processing
+  ocm fleet apply-idp --file=sso.json --search="name like 'prod-%'" --results=results.json
+
+  # Apply the changes to ten clusters at a time
+  ocm fleet apply-idp --file=sso.json --search="name like 'prod-%'" --parallel=10`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVar(
+		&args.file,
+		"file",
+		"",
+		"File containing the identity provider (required).",
+	)
+	fs.StringVar(
+		&args.search,
+		"search",
+		"",
+		"Search expression used to select the clusters (required).",
+	)
+	dryrun.BoolVar(
+		fs,
+		&args.dryRun,
+		"Show the changes that would be applied to each cluster without applying them.",
+	)
+	fs.BoolVar(
+		&args.forceUpdate,
+		"force-update",
+		false,
+		"Update existing identity providers even if no changes are detected. Changes "+
+			"that only affect secrets can't be detected because the API doesn't return them.",
+	)
+	fs.StringVar(
+		&args.results,
+		"results",
+		"",
+		"Write the result of each cluster to this file, in JSON format.",
+	)
+	fs.IntVar(
+		&args.parallel,
+		"parallel",
+		1,
+		"Number of clusters that are processed in parallel. The output of each cluster "+
+			"is written as a whole, but the order of the clusters isn't preserved when "+
+			"this is greater than one.",
+	)
+	Cmd.MarkFlagRequired("file")
+	Cmd.MarkFlagRequired("search")
+	readonly.Mark(Cmd)
+}
+
+// secretFields contains the names of the fields that the API doesn't return, and that are
+// therefore ignored when comparing the desired identity provider with the existing one.
+var secretFields = map[string]bool{
+	"bind_password": true,
+	"client_secret": true,
+	"password":      true,
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	if args.parallel < 1 {
+		return fmt.Errorf("Option '--parallel' must be at least 1")
+	}
+
+	// Check the search expression before sending it to the server:
+	err := search.Lint(args.search)
+	if err != nil {
+		return fmt.Errorf("Invalid search expression: %v", err)
+	}
+
+	// Load the identity provider:
+	// #nosec G304
+	data, err := os.ReadFile(args.file)
+	if err != nil {
+		return fmt.Errorf("Can't read file '%s': %v", args.file, err)
+	}
+	desired, err := cmv1.UnmarshalIdentityProvider(data)
+	if err != nil {
+		return fmt.Errorf("Can't parse identity provider from file '%s': %v", args.file, err)
+	}
+	if desired.Name() == "" {
+		return fmt.Errorf("Identity provider in file '%s' doesn't have a name", args.file)
+	}
+	desiredFields, err := flatten(desired)
+	if err != nil {
+		return err
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+	clusterCollection := connection.ClustersMgmt().V1().Clusters()
+
+	// Retrieve the clusters:
+	var clusters []*cmv1.Cluster
+	query := fmt.Sprintf("(%s) and state = 'ready'", args.search)
+	size := 100
+	index := 1
+	for {
+		response, err := clusterCollection.List().
+			Search(query).
+			Size(size).
+			Page(index).
+			Send()
+		if err != nil {
+			return fmt.Errorf("Can't retrieve clusters: %v", err)
+		}
+		clusters = append(clusters, response.Items().Slice()...)
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+
+	// Apply the identity provider to each cluster. The output of each cluster is collected in
+	// blocks so that the lines of different clusters aren't mixed when running in parallel:
+	stdout := parallel.NewWriter(os.Stdout)
+	stderr := parallel.NewWriter(os.Stderr)
+	results := bulk.NewResults()
+	parallel.Each(len(clusters), args.parallel, func(i int) {
+		out := stdout.Block()
+		errOut := stderr.Block()
+		apply(clusterCollection, clusters[i], desired, desiredFields, results, out, errOut)
+		out.Flush()
+		errOut.Flush()
+	})
+	var created, updated int
+	for _, item := range results.Items() {
+		if item.Status != bulk.StatusSucceeded {
+			continue
+		}
+		switch item.Action {
+		case "create":
+			created++
+		case "update":
+			updated++
+		}
+	}
+	skipped := results.Count(bulk.StatusSkipped)
+	failed := results.Count(bulk.StatusFailed)
+
+	// Print the summary:
+	verb := ""
+	if args.dryRun {
+		verb = "to be "
+	}
+	fmt.Printf(
+		"\nClusters: %d, %screated: %d, %supdated: %d, skipped: %d, failed: %d\n",
+		len(clusters), verb, created, verb, updated, skipped, failed,
+	)
+	if args.results != "" {
+		err = results.Write(args.results)
+		if err != nil {
+			return err
+		}
+	}
+	return results.Err("Failed to apply identity provider")
+}
+
+// apply creates or updates the identity provider of one cluster, writing the changes to the
+// given output and the errors to the given error output, and recording the result.
+func apply(clusterCollection *cmv1.ClustersClient, cluster *cmv1.Cluster,
+	desired *cmv1.IdentityProvider, desiredFields map[string]interface{},
+	results *bulk.Results, out, errOut io.Writer) {
+	idps, err := c.GetIdentityProviders(clusterCollection, cluster.ID())
+	if err != nil {
+		fmt.Fprintf(errOut, "%s: %v\n", cluster.Name(), err)
+		results.Failed(cluster.ID(), cluster.Name(), "", err)
+		return
+	}
+	var existing *cmv1.IdentityProvider
+	for _, idp := range idps {
+		if idp.Name() == desired.Name() {
+			existing = idp
+			break
+		}
+	}
+	idpsClient := clusterCollection.Cluster(cluster.ID()).IdentityProviders()
+	if existing == nil {
+		fmt.Fprintf(out, "%s: create identity provider '%s'\n", cluster.Name(), desired.Name())
+		if !args.dryRun {
+			_, err = idpsClient.Add().Body(desired).Send()
+			if err != nil && !errors.Is(err, curl.ErrNotSent) {
+				fmt.Fprintf(errOut, "%s: %v\n", cluster.Name(), err)
+				results.Failed(cluster.ID(), cluster.Name(), "create", err)
+				return
+			}
+		}
+		results.Succeeded(cluster.ID(), cluster.Name(), "create")
+		return
+	}
+	existingFields, err := flatten(existing)
+	if err != nil {
+		fmt.Fprintf(errOut, "%s: %v\n", cluster.Name(), err)
+		results.Failed(cluster.ID(), cluster.Name(), "update", err)
+		return
+	}
+	changes := diff(existingFields, desiredFields)
+	switch {
+	case len(changes) > 0:
+		fmt.Fprintf(out, "%s: update identity provider '%s'\n", cluster.Name(), desired.Name())
+	case args.forceUpdate:
+		fmt.Fprintf(
+			out,
+			"%s: update identity provider '%s' (forced)\n",
+			cluster.Name(), desired.Name(),
+		)
+	default:
+		fmt.Fprintf(out, "%s: identity provider '%s' is up to date\n", cluster.Name(), desired.Name())
+		results.Skipped(cluster.ID(), cluster.Name(), "")
+		return
+	}
+	for _, change := range changes {
+		fmt.Fprintf(out, "  %s\n", change)
+	}
+	if !args.dryRun {
+		_, err = idpsClient.IdentityProvider(existing.ID()).Update().Body(desired).Send()
+		if err != nil && !errors.Is(err, curl.ErrNotSent) {
+			fmt.Fprintf(errOut, "%s: %v\n", cluster.Name(), err)
+			results.Failed(cluster.ID(), cluster.Name(), "update", err)
+			return
+		}
+	}
+	results.Succeeded(cluster.ID(), cluster.Name(), "update")
+}
+
+// flatten converts the identity provider into a map where the keys are the dot separated paths of
+// the fields, excluding the fields that identify the object and the secrets.
+func flatten(idp *cmv1.IdentityProvider) (result map[string]interface{}, err error) {
+	buffer := &bytes.Buffer{}
+	err = cmv1.MarshalIdentityProvider(idp, buffer)
+	if err != nil {
+		return
+	}
+	var data map[string]interface{}
+	err = json.Unmarshal(buffer.Bytes(), &data)
+	if err != nil {
+		return
+	}
+	delete(data, "kind")
+	delete(data, "id")
+	delete(data, "href")
+	result = map[string]interface{}{}
+	flattenInto(result, "", data)
+	return
+}
+
+func flattenInto(result map[string]interface{}, prefix string, data map[string]interface{}) {
+	for name, value := range data {
+		if secretFields[name] {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenInto(result, path, nested)
+			continue
+		}
+		result[path] = value
+	}
+}
+
+// diff returns a description of the fields of the desired identity provider that have different
+// values in the existing one, sorted by path.
+func diff(existing, desired map[string]interface{}) []string {
+	var paths []string
+	for path, value := range desired {
+		if !reflect.DeepEqual(existing[path], value) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	result := make([]string, len(paths))
+	for i, path := range paths {
+		result[i] = fmt.Sprintf(
+			"%s: %s -> %s",
+			path, format(existing[path]), format(desired[path]),
+		)
+	}
+	return result
+}
+
+func format(value interface{}) string {
+	if value == nil {
+		return "NONE"
+	}
+	text, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.TrimSpace(string(text))
+}
@@ -0,0 +1,232 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/parallel"
+)
+
+var args struct {
+	json     bool
+	days     int
+	parallel int
+	timeout  time.Duration
+}
+
+var Cmd = &cobra.Command{
+	Use:   "certificates",
+	Short: "Report the certificates that will expire soon",
+	Long: "Check the certificates of the API and ingress endpoints of all the ready clusters " +
+		"and report the ones that will expire within the given number of days. The " +
+		"certificates are obtained connecting to the clusters, so clusters that aren't " +
+		"reachable from this machine are reported as unreachable. The command fails when " +
+		"there are certificates about to expire, so that it can be used in scheduled jobs " +
+		"to send alerts.",
+	Example: `  # Report the certificates that will expire within the next 30 days
+  ocm fleet certificates
+
+  # Report the certificates that will expire within the next week in JSON format
+  ocm fleet certificates --days 7 --json`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the report in JSON format",
+	)
+	fs.IntVar(
+		&args.days,
+		"days",
+		30,
+		"Report certificates that will expire within this number of days",
+	)
+	fs.IntVar(
+		&args.parallel,
+		"parallel",
+		10,
+		"Maximum number of clusters to check at the same time",
+	)
+	fs.DurationVar(
+		&args.timeout,
+		"timeout",
+		10*time.Second,
+		"Maximum time to wait for the connection to each endpoint",
+	)
+}
+
+// Report is the report generated by the command. Note that the field names are part of the JSON
+// output, so don't change them without considering the consumers of that output.
+type Report struct {
+	Total        int                  `json:"total"`
+	Expiring     int                  `json:"expiring"`
+	Unreachable  int                  `json:"unreachable"`
+	Certificates []*CertificateReport `json:"certificates"`
+}
+
+// CertificateReport contains the details of a certificate that will expire soon or that couldn't
+// be retrieved.
+type CertificateReport struct {
+	ClusterID   string `json:"cluster_id"`
+	ClusterName string `json:"cluster_name"`
+	*cluster.Certificate
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	if args.days < 0 {
+		return fmt.Errorf("Option '--days' must not be negative")
+	}
+	if args.parallel < 1 {
+		return fmt.Errorf("Option '--parallel' must be at least 1")
+	}
+	if args.timeout <= 0 {
+		return fmt.Errorf("Option '--timeout' must be a positive duration")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Retrieve the ready clusters, as the others may not have endpoints yet:
+	clusters := []*cmv1.Cluster{}
+	size := 100
+	index := 1
+	for {
+		response, err := connection.ClustersMgmt().V1().Clusters().List().
+			Search("state = 'ready'").
+			Size(size).
+			Page(index).
+			Send()
+		if err != nil {
+			return fmt.Errorf("Can't retrieve clusters: %v", err)
+		}
+		clusters = append(clusters, response.Items().Slice()...)
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+
+	// Check the certificates of all the clusters:
+	results := make([][]*cluster.Certificate, len(clusters))
+	parallel.Each(len(clusters), args.parallel, func(i int) {
+		results[i] = cluster.GetCertificates(clusters[i], args.timeout)
+	})
+	now := time.Now()
+	deadline := now.Add(time.Duration(args.days) * 24 * time.Hour)
+	report := &Report{
+		Total:        len(clusters),
+		Certificates: []*CertificateReport{},
+	}
+	for i, item := range clusters {
+		for _, certificate := range results[i] {
+			switch {
+			case certificate.Error != "":
+				report.Unreachable++
+			case certificate.NotAfter.Before(deadline):
+				report.Expiring++
+			default:
+				continue
+			}
+			report.Certificates = append(report.Certificates, &CertificateReport{
+				ClusterID:   item.ID(),
+				ClusterName: item.Name(),
+				Certificate: certificate,
+			})
+		}
+	}
+
+	// Write the report:
+	if args.json {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("Can't marshal report: %v", err)
+		}
+		err = dump.Pretty(os.Stdout, data)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = writeReport(report, now)
+		if err != nil {
+			return err
+		}
+	}
+	if report.Expiring > 0 {
+		return fmt.Errorf(
+			"Found %d certificates that will expire within %d days",
+			report.Expiring, args.days,
+		)
+	}
+	return nil
+}
+
+func writeReport(report *Report, now time.Time) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Total clusters:\t%d\n", report.Total)
+	fmt.Fprintf(writer, "Expiring:\t%d (within %d days)\n", report.Expiring, args.days)
+	fmt.Fprintf(writer, "Unreachable:\t%d\n", report.Unreachable)
+	err := writer.Flush()
+	if err != nil {
+		return err
+	}
+	if len(report.Certificates) == 0 {
+		return nil
+	}
+	fmt.Println()
+	writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "CLUSTER ID\tNAME\tENDPOINT\tISSUER\tNOT AFTER\tDAYS LEFT\n")
+	for _, certificate := range report.Certificates {
+		if certificate.Error != "" {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t-\t-\tunreachable\n",
+				certificate.ClusterID,
+				certificate.ClusterName,
+				certificate.Endpoint,
+			)
+			continue
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%d\n",
+			certificate.ClusterID,
+			certificate.ClusterName,
+			certificate.Endpoint,
+			certificate.Issuer,
+			certificate.NotAfter.Format(time.RFC3339),
+			certificate.DaysLeft(now),
+		)
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,45 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fleet
+
+import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/fleet/applyidp"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/fleet/certificates"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/fleet/drift"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/fleet/health"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/fleet/hostedclusters"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/fleet/owners"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/fleet/versions"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "fleet COMMAND",
+	Short: "Get aggregated information about all the clusters",
+	Long:  "Get reports that aggregate information about all the clusters visible to the current user",
+	Args:  cobra.MinimumNArgs(1),
+}
+
+func init() {
+	Cmd.AddCommand(applyidp.Cmd)
+	Cmd.AddCommand(certificates.Cmd)
+	Cmd.AddCommand(drift.Cmd)
+	Cmd.AddCommand(health.Cmd)
+	Cmd.AddCommand(hostedclusters.Cmd)
+	Cmd.AddCommand(owners.Cmd)
+	Cmd.AddCommand(versions.Cmd)
+}
@@ -0,0 +1,269 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/drift"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/parallel"
+)
+
+var args struct {
+	baseline string
+	search   string
+	json     bool
+	parallel int
+}
+
+var Cmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Report the clusters whose configuration deviates from a baseline",
+	Long: "Compare the configuration of all the ready clusters with the baseline given in a " +
+		"YAML file and report the fields that deviate from it. The baseline can check the " +
+		"channel group, the proxy settings, the types of identity providers and the " +
+		"autoscaling settings of the default compute pool. Fields that aren't in the " +
+		"baseline aren't checked. The command fails when there are deviations, so that it " +
+		"can be used in scheduled jobs to send alerts.",
+	Example: `  # Check all the clusters against the baseline in the 'baseline.yaml' file
+  ocm fleet drift --baseline baseline.yaml
+
+  # Check only the AWS clusters and generate the report in JSON format
+  ocm fleet drift --baseline baseline.yaml --search "cloud_provider.id = 'aws'" --json
+
+  # Example of a baseline file
+  channel_group: stable
+  proxy:
+    http_proxy: ""
+    https_proxy: ""
+  identity_providers:
+    required: [ldap]
+    allowed: [htpasswd]
+  autoscaling:
+    enabled: true
+    min_replicas: 3
+    max_replicas: 12`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVar(
+		&args.baseline,
+		"baseline",
+		"",
+		"YAML file containing the expected configuration of the clusters",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("baseline")
+	fs.StringVar(
+		&args.search,
+		"search",
+		"",
+		"Additional search criteria used to select the clusters",
+	)
+	fs.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the report in JSON format",
+	)
+	fs.IntVar(
+		&args.parallel,
+		"parallel",
+		10,
+		"Number of clusters whose identity providers are retrieved in parallel",
+	)
+}
+
+// Report is the drift report generated by the command. Note that the field names are part of the
+// JSON output, so don't change them without considering the consumers of that output.
+type Report struct {
+	Total      int              `json:"total"`
+	Drifted    int              `json:"drifted"`
+	Deviations int              `json:"deviations"`
+	Clusters   []*ClusterReport `json:"clusters"`
+}
+
+// ClusterReport contains the deviations of a cluster, or the error that prevented checking it.
+type ClusterReport struct {
+	ID         string             `json:"id"`
+	Name       string             `json:"name"`
+	Error      string             `json:"error,omitempty"`
+	Deviations []*drift.Deviation `json:"deviations"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	if args.parallel < 1 {
+		return fmt.Errorf("Option '--parallel' must be at least 1")
+	}
+	baseline, err := drift.Load(args.baseline)
+	if err != nil {
+		return err
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+	client := connection.ClustersMgmt().V1()
+
+	// Retrieve the ready clusters, as the configuration of the others may not be complete yet:
+	search := "state = 'ready'"
+	if args.search != "" {
+		search = fmt.Sprintf("%s and (%s)", search, args.search)
+	}
+	clusters := []*cmv1.Cluster{}
+	size := 100
+	index := 1
+	for {
+		response, err := client.Clusters().List().
+			Search(search).
+			Size(size).
+			Page(index).
+			Send()
+		if err != nil {
+			return fmt.Errorf("Can't retrieve clusters: %v", err)
+		}
+		clusters = append(clusters, response.Items().Slice()...)
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+
+	// Check all the clusters, retrieving the identity providers only if the baseline needs
+	// them:
+	results := make([]*ClusterReport, len(clusters))
+	parallel.Each(len(clusters), args.parallel, func(i int) {
+		item := clusters[i]
+		result := &ClusterReport{
+			ID:         item.ID(),
+			Name:       item.Name(),
+			Deviations: []*drift.Deviation{},
+		}
+		results[i] = result
+		var idps []*cmv1.IdentityProvider
+		if baseline.NeedsIdentityProviders() {
+			var err error
+			idps, err = c.GetIdentityProviders(client.Clusters(), item.ID())
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+		}
+		result.Deviations = baseline.Check(item, idps)
+	})
+	report := &Report{
+		Total:    len(clusters),
+		Clusters: []*ClusterReport{},
+	}
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		} else if len(result.Deviations) == 0 {
+			continue
+		} else {
+			report.Drifted++
+			report.Deviations += len(result.Deviations)
+		}
+		report.Clusters = append(report.Clusters, result)
+	}
+
+	// Write the report:
+	if args.json {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("Can't marshal report: %v", err)
+		}
+		err = dump.Pretty(os.Stdout, data)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = writeReport(report)
+		if err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("Failed to check %d of %d clusters", failed, report.Total)
+	}
+	if report.Deviations > 0 {
+		return fmt.Errorf(
+			"Found %d deviations in %d clusters",
+			report.Deviations, report.Drifted,
+		)
+	}
+	return nil
+}
+
+func writeReport(report *Report) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Total clusters:\t%d\n", report.Total)
+	fmt.Fprintf(writer, "Drifted clusters:\t%d\n", report.Drifted)
+	fmt.Fprintf(writer, "Deviations:\t%d\n", report.Deviations)
+	err := writer.Flush()
+	if err != nil {
+		return err
+	}
+	if len(report.Clusters) == 0 {
+		return nil
+	}
+	fmt.Println()
+	writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "CLUSTER ID\tNAME\tFIELD\tEXPECTED\tACTUAL\n")
+	for _, result := range report.Clusters {
+		if result.Error != "" {
+			fmt.Fprintf(writer, "%s\t%s\t-\t-\t%s\n", result.ID, result.Name, result.Error)
+			continue
+		}
+		for _, deviation := range result.Deviations {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+				result.ID,
+				result.Name,
+				deviation.Field,
+				displayValue(deviation.Expected),
+				displayValue(deviation.Actual),
+			)
+		}
+	}
+	return writer.Flush()
+}
+
+// displayValue returns the text used in the table for the given value, so that empty values are
+// visible.
+func displayValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,274 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	goVersion "github.com/hashicorp/go-version"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	json           bool
+	expirationDays int
+	channelGroup   string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "health",
+	Short: "Report the health of all the clusters",
+	Long: "Check all the clusters and report the ones that aren't ready, that are in limited " +
+		"support, that run a version two or more minor versions older than the latest one, " +
+		"or that will expire soon.",
+	Example: `  # Show the health report of all the clusters
+  ocm fleet health
+
+  # Generate the report in JSON format, for example to feed a dashboard
+  ocm fleet health --json`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the report in JSON format",
+	)
+	fs.IntVar(
+		&args.expirationDays,
+		"expiration-days",
+		7,
+		"Report clusters that will expire within this number of days",
+	)
+	fs.StringVar(
+		&args.channelGroup,
+		"channel-group",
+		"stable",
+		"Channel group used to find the latest version",
+	)
+}
+
+// Names of the issues that can be reported for a cluster:
+const (
+	issueNotReady       = "not_ready"
+	issueLimitedSupport = "limited_support"
+	issueOutdated       = "outdated"
+	issueExpiring       = "expiring"
+)
+
+// Report is the health report generated by the command. Note that the field names are part of the
+// JSON output, so don't change them without considering the consumers of that output.
+type Report struct {
+	Total          int              `json:"total"`
+	NotReady       int              `json:"not_ready"`
+	LimitedSupport int              `json:"limited_support"`
+	Outdated       int              `json:"outdated"`
+	Expiring       int              `json:"expiring"`
+	LatestVersion  string           `json:"latest_version,omitempty"`
+	Clusters       []*ClusterReport `json:"clusters"`
+}
+
+// ClusterReport contains the details of a cluster that has at least one issue.
+type ClusterReport struct {
+	ID                  string     `json:"id"`
+	Name                string     `json:"name"`
+	State               string     `json:"state"`
+	Version             string     `json:"version"`
+	ExpirationTimestamp *time.Time `json:"expiration_timestamp,omitempty"`
+	Issues              []string   `json:"issues"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	if args.expirationDays < 0 {
+		return fmt.Errorf("Option '--expiration-days' must not be negative")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+	client := connection.ClustersMgmt().V1()
+
+	// Find the latest enabled version, as clusters are considered outdated when they are two or
+	// more minor versions behind it:
+	versions, _, err := cluster.GetEnabledVersions(client, args.channelGroup)
+	if err != nil {
+		return fmt.Errorf("Can't retrieve versions: %v", err)
+	}
+	report := &Report{
+		Clusters: []*ClusterReport{},
+	}
+	latestMinor := -1
+	if len(versions) > 0 {
+		report.LatestVersion = versions[len(versions)-1]
+		latestMinor = minorVersion(report.LatestVersion)
+	}
+
+	// Check all the clusters, page by page:
+	deadline := time.Now().Add(time.Duration(args.expirationDays) * 24 * time.Hour)
+	size := 100
+	index := 1
+	for {
+		response, err := client.Clusters().List().
+			Size(size).
+			Page(index).
+			Send()
+		if err != nil {
+			return fmt.Errorf("Can't retrieve clusters: %v", err)
+		}
+		response.Items().Each(func(item *cmv1.Cluster) bool {
+			report.Total++
+			clusterReport := checkCluster(item, latestMinor, deadline)
+			if len(clusterReport.Issues) == 0 {
+				return true
+			}
+			for _, issue := range clusterReport.Issues {
+				switch issue {
+				case issueNotReady:
+					report.NotReady++
+				case issueLimitedSupport:
+					report.LimitedSupport++
+				case issueOutdated:
+					report.Outdated++
+				case issueExpiring:
+					report.Expiring++
+				}
+			}
+			report.Clusters = append(report.Clusters, clusterReport)
+			return true
+		})
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+
+	// Write the report:
+	if args.json {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("Can't marshal report: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	return writeReport(report)
+}
+
+// checkCluster checks the health of the given cluster and returns a report containing the issues
+// that were found.
+func checkCluster(item *cmv1.Cluster, latestMinor int, deadline time.Time) *ClusterReport {
+	result := &ClusterReport{
+		ID:      item.ID(),
+		Name:    item.Name(),
+		State:   string(item.State()),
+		Version: clusterVersion(item),
+		Issues:  []string{},
+	}
+	if item.State() != cmv1.ClusterStateReady {
+		result.Issues = append(result.Issues, issueNotReady)
+	}
+	if item.Status().LimitedSupportReasonCount() > 0 {
+		result.Issues = append(result.Issues, issueLimitedSupport)
+	}
+	if latestMinor >= 0 {
+		minor := minorVersion(result.Version)
+		if minor >= 0 && latestMinor-minor >= 2 {
+			result.Issues = append(result.Issues, issueOutdated)
+		}
+	}
+	expiration, ok := item.GetExpirationTimestamp()
+	if ok && !expiration.IsZero() {
+		result.ExpirationTimestamp = &expiration
+		if expiration.Before(deadline) {
+			result.Issues = append(result.Issues, issueExpiring)
+		}
+	}
+	return result
+}
+
+// clusterVersion returns the OpenShift version of the cluster without the 'openshift-v' prefix.
+func clusterVersion(item *cmv1.Cluster) string {
+	result := item.OpenshiftVersion()
+	if result == "" {
+		result = cluster.DropOpenshiftVPrefix(item.Version().ID())
+	}
+	return result
+}
+
+// minorVersion returns the minor component of the given version, or -1 if it can't be parsed.
+func minorVersion(text string) int {
+	parsed, err := goVersion.NewVersion(text)
+	if err != nil {
+		return -1
+	}
+	segments := parsed.Segments()
+	if len(segments) < 2 {
+		return -1
+	}
+	return segments[1]
+}
+
+func writeReport(report *Report) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Total clusters:\t%d\n", report.Total)
+	fmt.Fprintf(writer, "Not ready:\t%d\n", report.NotReady)
+	fmt.Fprintf(writer, "Limited support:\t%d\n", report.LimitedSupport)
+	if report.LatestVersion != "" {
+		fmt.Fprintf(writer, "Outdated:\t%d (latest version is %s)\n",
+			report.Outdated, report.LatestVersion)
+	} else {
+		fmt.Fprintf(writer, "Outdated:\t%d\n", report.Outdated)
+	}
+	fmt.Fprintf(writer, "Expiring:\t%d (within %d days)\n", report.Expiring, args.expirationDays)
+	err := writer.Flush()
+	if err != nil {
+		return err
+	}
+	if len(report.Clusters) == 0 {
+		return nil
+	}
+	fmt.Println()
+	writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "ID\tNAME\tVERSION\tSTATE\tISSUES\n")
+	for _, clusterReport := range report.Clusters {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+			clusterReport.ID,
+			clusterReport.Name,
+			clusterReport.Version,
+			clusterReport.State,
+			strings.Join(clusterReport.Issues, ", "),
+		)
+	}
+	return writer.Flush()
+}
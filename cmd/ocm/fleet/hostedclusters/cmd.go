@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostedclusters
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	json bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "hosted-clusters MANAGEMENT_CLUSTER",
+	Short: "List the hosted control planes that run in a management cluster",
+	Long: "List the clusters whose hosted control planes run in the given management cluster, " +
+		"with the service cluster that manages it. This requires privileged access to the " +
+		"fleet management API.",
+	Example: `  # List the clusters hosted by management cluster 'hs-mc-abc123'
+  ocm fleet hosted-clusters hs-mc-abc123
+
+  # List the same clusters in JSON format
+  ocm fleet hosted-clusters hs-mc-abc123 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the report in JSON format",
+	)
+}
+
+// Report contains the clusters hosted by a management cluster.
+type Report struct {
+	ManagementCluster string           `json:"management_cluster"`
+	ServiceCluster    string           `json:"service_cluster,omitempty"`
+	Region            string           `json:"region,omitempty"`
+	Status            string           `json:"status,omitempty"`
+	Clusters          []*HostedCluster `json:"clusters"`
+}
+
+// HostedCluster contains the details of a cluster hosted by the management cluster.
+type HostedCluster struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	State        string `json:"state"`
+	Version      string `json:"version"`
+	HCPNamespace string `json:"hcp_namespace,omitempty"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// This also checks that the name is safe to use in the search of the clusters:
+	management, err := c.GetManagementCluster(connection, argv[0])
+	if err != nil {
+		return err
+	}
+	clusters, details, err := c.ListHostedClusters(connection, management.Name)
+	if err != nil {
+		return err
+	}
+
+	report := &Report{
+		ManagementCluster: management.Name,
+		ServiceCluster:    management.ServiceCluster(),
+		Region:            management.Region,
+		Status:            management.Status,
+		Clusters:          []*HostedCluster{},
+	}
+	for i, cluster := range clusters {
+		report.Clusters = append(report.Clusters, &HostedCluster{
+			ID:           cluster.ID(),
+			Name:         cluster.Name(),
+			State:        string(cluster.State()),
+			Version:      cluster.OpenshiftVersion(),
+			HCPNamespace: details[i].HCPNamespace(),
+		})
+	}
+
+	// Write the report:
+	if args.json {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("Can't marshal report: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	fmt.Printf(
+		"Management cluster '%s' of service cluster '%s' hosts %d clusters\n\n",
+		report.ManagementCluster, report.ServiceCluster, len(report.Clusters),
+	)
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "ID\tNAME\tSTATE\tVERSION\tHCP NAMESPACE\n")
+	for _, cluster := range report.Clusters {
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\n",
+			cluster.ID, cluster.Name, cluster.State, cluster.Version, cluster.HCPNamespace,
+		)
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,298 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package owners
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/redact"
+)
+
+var args struct {
+	deactivated bool
+	json        bool
+	redactPII   bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "owners",
+	Short: "Report the creators and owners of the clusters",
+	Long: "Report the account that created each cluster and the account that currently owns " +
+		"it, with their email addresses. The owner is the account that has the " +
+		"'ClusterOwner' role for the subscription of the cluster, or the creator if no " +
+		"account has that role. Clusters whose creator or owner has been banned or deleted " +
+		"are flagged, as they need a new owner.",
+	Example: `  # Show the creators and owners of all the clusters
+  ocm fleet owners
+
+  # Show only the clusters owned by deactivated accounts, in JSON format
+  ocm fleet owners --deactivated --json`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.BoolVar(
+		&args.deactivated,
+		"deactivated",
+		false,
+		"Report only the clusters whose creator or owner has been banned or deleted",
+	)
+	fs.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the report in JSON format",
+	)
+	arguments.AddRedactFlag(fs, &args.redactPII)
+}
+
+// ownerRole is the role that gives the ownership of a cluster.
+const ownerRole = "ClusterOwner"
+
+// activeStatuses are the statuses of the subscriptions that are included in the report.
+const activeStatuses = "'Active', 'Disconnected', 'Reserved', 'Stale'"
+
+// Flags used to report the problems of the accounts:
+const (
+	flagCreatorBanned  = "creator banned"
+	flagCreatorMissing = "creator deleted"
+	flagOwnerBanned    = "owner banned"
+	flagOwnerMissing   = "owner deleted"
+)
+
+// ClusterReport contains the creator and owner of a cluster.
+type ClusterReport struct {
+	ClusterID      string   `json:"cluster_id,omitempty"`
+	Name           string   `json:"name"`
+	SubscriptionID string   `json:"subscription_id"`
+	Creator        string   `json:"creator"`
+	CreatorEmail   string   `json:"creator_email"`
+	Owner          string   `json:"owner"`
+	OwnerEmail     string   `json:"owner_email"`
+	Flags          []string `json:"flags,omitempty"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Retrieve the subscriptions and the accounts that own them:
+	subscriptions, err := listSubscriptions(connection)
+	if err != nil {
+		return err
+	}
+	owners, err := listOwners(connection)
+	if err != nil {
+		return err
+	}
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, subscription := range subscriptions {
+		for _, id := range []string{subscription.Creator().ID(), owners[subscription.ID()]} {
+			if id != "" && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	accounts, err := getAccounts(connection, ids)
+	if err != nil {
+		return err
+	}
+
+	// Personal data is replaced with hashes if requested, so that the output can be shared:
+	var redactor *redact.Redactor
+	if args.redactPII {
+		redactor = redact.NewFromEnv()
+	}
+
+	// Generate the report:
+	reports := []*ClusterReport{}
+	for _, subscription := range subscriptions {
+		creatorID := subscription.Creator().ID()
+		ownerID := owners[subscription.ID()]
+		if ownerID == "" {
+			ownerID = creatorID
+		}
+		report := &ClusterReport{
+			ClusterID:      subscription.ClusterID(),
+			Name:           subscription.DisplayName(),
+			SubscriptionID: subscription.ID(),
+		}
+		var flag string
+		report.Creator, report.CreatorEmail, flag = describe(
+			accounts, creatorID, flagCreatorBanned, flagCreatorMissing, redactor,
+		)
+		if flag != "" {
+			report.Flags = append(report.Flags, flag)
+		}
+		report.Owner, report.OwnerEmail, flag = describe(
+			accounts, ownerID, flagOwnerBanned, flagOwnerMissing, redactor,
+		)
+		if flag != "" {
+			report.Flags = append(report.Flags, flag)
+		}
+		if args.deactivated && len(report.Flags) == 0 {
+			continue
+		}
+		reports = append(reports, report)
+	}
+
+	// Write the report:
+	if args.json {
+		data, err := json.Marshal(reports)
+		if err != nil {
+			return fmt.Errorf("Can't marshal report: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "CLUSTER ID\tNAME\tCREATOR\tCREATOR EMAIL\tOWNER\tOWNER EMAIL\tFLAGS\n")
+	for _, report := range reports {
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			report.ClusterID, report.Name,
+			report.Creator, report.CreatorEmail,
+			report.Owner, report.OwnerEmail,
+			strings.Join(report.Flags, ", "),
+		)
+	}
+	return writer.Flush()
+}
+
+// describe returns the user name and email address of the account with the given identifier,
+// and the flag that should be reported if the account is banned or doesn't exist.
+func describe(accounts map[string]*amv1.Account, id, banned, missing string,
+	redactor *redact.Redactor) (username, email, flag string) {
+	if id == "" {
+		return
+	}
+	account, ok := accounts[id]
+	if !ok {
+		username = id
+		flag = missing
+		return
+	}
+	username = account.Username()
+	email = account.Email()
+	if redactor != nil {
+		username = redactor.Username(username)
+		email = redactor.Email(email)
+	}
+	if account.Banned() {
+		flag = banned
+	}
+	return
+}
+
+// listSubscriptions retrieves the subscriptions of the clusters that haven't been deprovisioned.
+func listSubscriptions(connection *sdk.Connection) (result []*amv1.Subscription, err error) {
+	size := 100
+	for page := 1; ; page++ {
+		var response *amv1.SubscriptionsListResponse
+		response, err = connection.AccountsMgmt().V1().Subscriptions().List().
+			Search(fmt.Sprintf("status in (%s)", activeStatuses)).
+			Size(size).
+			Page(page).
+			Send()
+		if err != nil {
+			err = fmt.Errorf("Can't retrieve subscriptions: %v", err)
+			return
+		}
+		result = append(result, response.Items().Slice()...)
+		if response.Size() < size {
+			return
+		}
+	}
+}
+
+// listOwners retrieves the role bindings that give the ownership of subscriptions, and returns the
+// identifiers of the owner accounts indexed by subscription identifier.
+func listOwners(connection *sdk.Connection) (result map[string]string, err error) {
+	result = map[string]string{}
+	query := fmt.Sprintf("type = 'Subscription' and role_id = '%s'", ownerRole)
+	size := 100
+	for page := 1; ; page++ {
+		var response *amv1.RoleBindingsListResponse
+		response, err = connection.AccountsMgmt().V1().RoleBindings().List().
+			Parameter("search", query).
+			Size(size).
+			Page(page).
+			Send()
+		if err != nil {
+			err = fmt.Errorf("Can't retrieve role bindings: %v", err)
+			return
+		}
+		response.Items().Each(func(binding *amv1.RoleBinding) bool {
+			subscriptionID := binding.Subscription().ID()
+			if subscriptionID != "" && result[subscriptionID] == "" {
+				result[subscriptionID] = binding.Account().ID()
+			}
+			return true
+		})
+		if response.Size() < size {
+			return
+		}
+	}
+}
+
+// getAccounts retrieves the accounts with the given identifiers, in batches to reduce the number of
+// requests. Accounts that don't exist aren't included in the result.
+func getAccounts(connection *sdk.Connection, ids []string) (result map[string]*amv1.Account,
+	err error) {
+	result = map[string]*amv1.Account{}
+	size := 100
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		query := fmt.Sprintf("id in ('%s')", strings.Join(ids[start:end], "', '"))
+		var response *amv1.AccountsListResponse
+		response, err = connection.AccountsMgmt().V1().Accounts().List().
+			Search(query).
+			Size(size).
+			Send()
+		if err != nil {
+			err = fmt.Errorf("Can't retrieve accounts: %v", err)
+			return
+		}
+		response.Items().Each(func(account *amv1.Account) bool {
+			result[account.ID()] = account
+			return true
+		})
+	}
+	return
+}
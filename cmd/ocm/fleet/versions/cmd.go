@@ -0,0 +1,267 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package versions
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	goVersion "github.com/hashicorp/go-version"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	eolReport bool
+	days      int
+	json      bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "versions",
+	Short: "Report the versions used by the clusters",
+	Long: "Report the versions used by the clusters and their end of life dates. With the " +
+		"'--eol-report' option report the clusters whose version is reaching the end of " +
+		"support.",
+	Example: `  # Show how many clusters use each version
+  ocm fleet versions
+
+  # Show the clusters whose version reaches the end of life within the next 30 days
+  ocm fleet versions --eol-report --days 30`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.BoolVar(
+		&args.eolReport,
+		"eol-report",
+		false,
+		"Report the clusters whose version is approaching the end of life",
+	)
+	fs.IntVar(
+		&args.days,
+		"days",
+		90,
+		"Number of days before the end of life that a cluster is reported when using "+
+			"the '--eol-report' option",
+	)
+	fs.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the report in JSON format",
+	)
+}
+
+// VersionReport contains the information about a version used by at least one cluster.
+type VersionReport struct {
+	ID        string     `json:"id"`
+	Clusters  int        `json:"clusters"`
+	EndOfLife *time.Time `json:"end_of_life,omitempty"`
+	DaysLeft  *int       `json:"days_left,omitempty"`
+}
+
+// ClusterReport contains the information about a cluster whose version is approaching the end
+// of life.
+type ClusterReport struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	EndOfLife time.Time `json:"end_of_life"`
+	DaysLeft  int       `json:"days_left"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	if cmd.Flags().Changed("days") && !args.eolReport {
+		return fmt.Errorf("Option '--days' can only be used with '--eol-report'")
+	}
+	if args.days < 0 {
+		return fmt.Errorf("Option '--days' must not be negative")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+	client := connection.ClustersMgmt().V1()
+
+	// Retrieve all the clusters:
+	var clusters []*cmv1.Cluster
+	size := 100
+	index := 1
+	for {
+		response, err := client.Clusters().List().
+			Size(size).
+			Page(index).
+			Send()
+		if err != nil {
+			return fmt.Errorf("Can't retrieve clusters: %v", err)
+		}
+		clusters = append(clusters, response.Items().Slice()...)
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+
+	// Retrieve the details of the versions used by the clusters, as the cluster only contains
+	// a link to the version:
+	counts := map[string]int{}
+	for _, item := range clusters {
+		counts[item.Version().ID()]++
+	}
+	versionIDs := make([]string, 0, len(counts))
+	for versionID := range counts {
+		if versionID != "" {
+			versionIDs = append(versionIDs, versionID)
+		}
+	}
+	sortVersionIDs(versionIDs)
+	versions := map[string]*cmv1.Version{}
+	for _, versionID := range versionIDs {
+		response, err := client.Versions().Version(versionID).Get().Send()
+		if err != nil {
+			// Versions that have been removed don't have lifecycle information, but
+			// that shouldn't prevent reporting the rest:
+			if response != nil && response.Status() == http.StatusNotFound {
+				continue
+			}
+			return fmt.Errorf("Can't retrieve version '%s': %v", versionID, err)
+		}
+		versions[versionID] = response.Body()
+	}
+
+	// Generate the requested report:
+	now := time.Now()
+	if args.eolReport {
+		reports := []*ClusterReport{}
+		for _, item := range clusters {
+			version := versions[item.Version().ID()]
+			endOfLife, ok := version.GetEndOfLifeTimestamp()
+			if !ok || endOfLife.IsZero() {
+				continue
+			}
+			daysLeft := daysUntil(now, endOfLife)
+			if daysLeft > args.days {
+				continue
+			}
+			reports = append(reports, &ClusterReport{
+				ID:        item.ID(),
+				Name:      item.Name(),
+				Version:   cluster.DropOpenshiftVPrefix(item.Version().ID()),
+				EndOfLife: endOfLife,
+				DaysLeft:  daysLeft,
+			})
+		}
+		sort.SliceStable(reports, func(i, j int) bool {
+			return reports[i].DaysLeft < reports[j].DaysLeft
+		})
+		if args.json {
+			return writeJSON(reports)
+		}
+		return writeClusters(reports)
+	}
+	reports := []*VersionReport{}
+	for _, versionID := range versionIDs {
+		report := &VersionReport{
+			ID:       cluster.DropOpenshiftVPrefix(versionID),
+			Clusters: counts[versionID],
+		}
+		endOfLife, ok := versions[versionID].GetEndOfLifeTimestamp()
+		if ok && !endOfLife.IsZero() {
+			daysLeft := daysUntil(now, endOfLife)
+			report.EndOfLife = &endOfLife
+			report.DaysLeft = &daysLeft
+		}
+		reports = append(reports, report)
+	}
+	if args.json {
+		return writeJSON(reports)
+	}
+	return writeVersions(reports)
+}
+
+// daysUntil calculates the number of whole days from now till the given time. The result is
+// negative if the time is in the past.
+func daysUntil(now, then time.Time) int {
+	return int(math.Floor(then.Sub(now).Hours() / 24))
+}
+
+// sortVersionIDs sorts the given version identifiers in approximate semantic version order,
+// falling back to lexicographic order when they can't be parsed.
+func sortVersionIDs(ids []string) {
+	sort.Slice(ids, func(i, j int) bool {
+		s1 := cluster.DropOpenshiftVPrefix(ids[i])
+		s2 := cluster.DropOpenshiftVPrefix(ids[j])
+		v1, err1 := goVersion.NewVersion(s1)
+		v2, err2 := goVersion.NewVersion(s2)
+		if err1 != nil || err2 != nil {
+			return s1 < s2
+		}
+		return v1.LessThan(v2)
+	})
+}
+
+func writeJSON(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("Can't marshal report: %v", err)
+	}
+	return dump.Pretty(os.Stdout, data)
+}
+
+func writeVersions(reports []*VersionReport) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "VERSION\tCLUSTERS\tEND OF LIFE\tDAYS LEFT\n")
+	for _, report := range reports {
+		endOfLife := "NONE"
+		daysLeft := "NONE"
+		if report.EndOfLife != nil {
+			endOfLife = report.EndOfLife.Format("2006-01-02")
+			daysLeft = fmt.Sprintf("%d", *report.DaysLeft)
+		}
+		fmt.Fprintf(writer, "%s\t%d\t%s\t%s\n",
+			report.ID, report.Clusters, endOfLife, daysLeft)
+	}
+	return writer.Flush()
+}
+
+func writeClusters(reports []*ClusterReport) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "ID\tNAME\tVERSION\tEND OF LIFE\tDAYS LEFT\n")
+	for _, report := range reports {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\n",
+			report.ID, report.Name, report.Version,
+			report.EndOfLife.Format("2006-01-02"), report.DaysLeft)
+	}
+	return writer.Flush()
+}
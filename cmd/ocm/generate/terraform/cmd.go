@@ -0,0 +1,139 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"fmt"
+	"os"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/terraform"
+)
+
+var args struct {
+	output    string
+	noImports bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "terraform {NAME|ID|EXTERNAL_ID}",
+	Short: "Generate the Terraform configuration of a cluster",
+	Long: "Generate a Terraform configuration for the RHCS provider that describes an " +
+		"existing ROSA classic cluster, its machine pools and its identity providers, so " +
+		"that a cluster created with other tools can be managed with Terraform. The API " +
+		"doesn't return secrets, like client secrets or passwords, so those are replaced " +
+		"by sensitive variables. The generated configuration contains 'import' blocks, " +
+		"which require Terraform 1.5 or newer, so that the existing objects are adopted " +
+		"instead of created again.",
+	Example: `  # Generate the Terraform configuration of cluster 'mycluster'
+  ocm generate terraform mycluster --output main.tf`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.output,
+		"output",
+		"",
+		"File where the configuration will be written. By default it is written to the "+
+			"standard output.",
+	)
+	flags.BoolVar(
+		&args.noImports,
+		"no-imports",
+		false,
+		"Don't generate the 'import' blocks.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+	clusterCollection := connection.ClustersMgmt().V1().Clusters()
+
+	// Retrieve the cluster and the related objects:
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	machinePools, err := c.GetMachinePools(clusterCollection, cluster.ID())
+	if err != nil {
+		return err
+	}
+	idps, err := c.GetIdentityProviders(clusterCollection, cluster.ID())
+	if err != nil {
+		return err
+	}
+	users := map[string][]*cmv1.HTPasswdUser{}
+	for _, idp := range idps {
+		if idp.Type() != cmv1.IdentityProviderTypeHtpasswd {
+			continue
+		}
+		users[idp.ID()], err = c.GetHTPasswdUsers(clusterCollection, cluster.ID(), idp.ID())
+		if err != nil {
+			return err
+		}
+	}
+	export := &terraform.Export{
+		Cluster:           cluster,
+		MachinePools:      machinePools,
+		IdentityProviders: idps,
+		HTPasswdUsers:     users,
+		Imports:           !args.noImports,
+	}
+
+	// Write the configuration:
+	if args.output == "" {
+		return export.Write(os.Stdout)
+	}
+	file, err := os.Create(args.output)
+	if err != nil {
+		return fmt.Errorf("Can't create file '%s': %v", args.output, err)
+	}
+	err = export.Write(file)
+	if err != nil {
+		file.Close()
+		os.Remove(args.output)
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("Can't write file '%s': %v", args.output, err)
+	}
+	return nil
+}
@@ -17,27 +17,46 @@ limitations under the License.
 package get
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/itchyny/gojq"
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-cli/pkg/arguments"
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/hooks"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/poll"
 	"github.com/openshift-online/ocm-cli/pkg/urls"
 )
 
 var args struct {
 	parameter []string
 	header    []string
+	fields    []string
 	single    bool
+	jq        string
+	poll      time.Duration
+	until     string
+	timeout   time.Duration
 }
 
 var Cmd = &cobra.Command{
-	Use:       "get RESOURCE [ID]",
-	Short:     "Send a GET request",
-	Long:      "Send a GET request to the given path.",
+	Use:   "get RESOURCE [ID]",
+	Short: "Send a GET request",
+	Long: "Send a GET request to the given path. With the '--poll' and '--until' options " +
+		"the request is repeated till the jq expression applied to the response is " +
+		"true, and then the last response is printed. This can be used to wait for " +
+		"changes in objects that the 'wait' command doesn't support.",
+	Example: `  # Print the identifiers of the clusters that are ready
+  ocm get clusters --jq '.items[] | select(.state == "ready") | .id'
+
+  # Wait till the cluster has a console URL, checking every 30 seconds
+  ocm get cluster 123 --poll 30s --until '.console.url != null' --jq .console.url`,
 	RunE:      run,
 	ValidArgs: urls.Resources(),
 }
@@ -46,12 +65,40 @@ func init() {
 	fs := Cmd.Flags()
 	arguments.AddParameterFlag(fs, &args.parameter)
 	arguments.AddHeaderFlag(fs, &args.header)
+	arguments.AddFieldsFlag(fs, &args.fields)
 	fs.BoolVar(
 		&args.single,
 		"single",
 		false,
 		"Return the output as a single line.",
 	)
+	fs.StringVar(
+		&args.jq,
+		"jq",
+		"",
+		"Filter the response using this jq expression. Results that are strings are "+
+			"printed without quotes, one per line.",
+	)
+	fs.DurationVar(
+		&args.poll,
+		"poll",
+		0,
+		"Repeat the request with this interval till the expression given with the "+
+			"'--until' option is true.",
+	)
+	fs.StringVar(
+		&args.until,
+		"until",
+		"",
+		"jq expression that is applied to each response when using the '--poll' option. "+
+			"Polling stops when it is true.",
+	)
+	fs.DurationVar(
+		&args.timeout,
+		"timeout",
+		30*time.Minute,
+		"Maximum time to poll, for example '45m' or '2h'.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -60,6 +107,39 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Could not create URI: %v", err)
 	}
 
+	// Check the filter before sending the request:
+	var filter *gojq.Code
+	if args.jq != "" {
+		filter, err = dump.ParseJQ(args.jq)
+		if err != nil {
+			return fmt.Errorf("Invalid jq expression '%s': %v", args.jq, err)
+		}
+	}
+	var until *gojq.Code
+	if args.poll != 0 || args.until != "" {
+		if args.poll == 0 {
+			return fmt.Errorf("Option '--until' requires the '--poll' option")
+		}
+		if args.poll < 0 {
+			return fmt.Errorf("Option '--poll' must be a positive duration")
+		}
+		if args.until == "" {
+			return fmt.Errorf("Option '--poll' requires the '--until' option")
+		}
+		until, err = dump.ParseJQ(args.until)
+		if err != nil {
+			return fmt.Errorf("Invalid jq expression '%s': %v", args.until, err)
+		}
+	}
+	if cmd.Flags().Changed("timeout") {
+		if until == nil {
+			return fmt.Errorf("Option '--timeout' can only be used with '--poll'")
+		}
+		if args.timeout <= 0 {
+			return fmt.Errorf("Option '--timeout' must be positive")
+		}
+	}
+
 	// Load the configuration file:
 	cfg, err := config.Load()
 	if err != nil {
@@ -69,17 +149,10 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Not logged in, run the 'login' command")
 	}
 
-	// Check that the configuration has credentials or tokens that don't have expired:
-	armed, reason, err := cfg.Armed()
-	if err != nil {
-		return err
-	}
-	if !armed {
-		return fmt.Errorf("Not logged in, %s, run the 'login' command", reason)
-	}
-
-	// Create the connection:
-	connection, err := cfg.Connection()
+	// Create the connection. This checks that the configuration has
+	// credentials or tokens that haven't expired, and gives the user the chance to provide new
+	// ones if needed:
+	connection, err := ocm.NewConnection().Config(cfg).Build()
 	if err != nil {
 		return fmt.Errorf("Can't create connection: %v", err)
 	}
@@ -89,20 +162,48 @@ func run(cmd *cobra.Command, argv []string) error {
 	err = arguments.ApplyPathArg(request, path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Can't parse path '%s': %v\n", path, err)
-		os.Exit(1)
+		hooks.Exit(1)
 	}
 	arguments.ApplyParameterFlag(request, args.parameter)
 	arguments.ApplyHeaderFlag(request, args.header)
-
-	// Send the request:
-	response, err := request.Send()
+	arguments.ApplyFieldsFlag(request, args.fields)
+
+	// Send the request, repeating it till the condition is true if polling. Responses with
+	// error codes aren't retried, as the condition can't be applied to them:
+	ctx := context.Background()
+	if until != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, args.timeout)
+		defer cancel()
+	}
+	var status int
+	var body []byte
+	err = poll.Until(ctx, args.poll, func(ctx context.Context) (bool, error) {
+		response, err := request.SendContext(ctx)
+		if err != nil {
+			return false, fmt.Errorf("Can't send request: %v", err)
+		}
+		status = response.Status()
+		body = response.Bytes()
+		if until == nil || status >= 400 {
+			return true, nil
+		}
+		done, err := dump.JQTest(body, until)
+		if err != nil {
+			return false, fmt.Errorf("Can't evaluate jq expression '%s': %v", args.until, err)
+		}
+		return done, nil
+	})
+	if poll.IsTimeout(err) {
+		return fmt.Errorf("Timed out after %s waiting for '%s'", args.timeout, args.until)
+	}
 	if err != nil {
-		return fmt.Errorf("Can't send request: %v", err)
+		return err
 	}
-	status := response.Status()
-	body := response.Bytes()
 	if status < 400 {
-		if args.single {
+		if filter != nil {
+			err = dump.JQ(os.Stdout, body, filter, args.single)
+		} else if args.single {
 			err = dump.Single(os.Stdout, body)
 		} else {
 			err = dump.Pretty(os.Stdout, body)
@@ -130,7 +231,7 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	// Bye:
 	if status >= 400 {
-		os.Exit(1)
+		hooks.Exit(1)
 	}
 
 	return nil
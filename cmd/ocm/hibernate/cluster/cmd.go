@@ -1,44 +1,62 @@
 package cluster
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
+
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/curl"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 
 	"github.com/spf13/cobra"
 )
 
+var args struct {
+	idsFromStdin bool
+}
+
 var Cmd = &cobra.Command{
-	Use:   "cluster {NAME|ID|EXTERNAL_ID}",
+	Use:   "cluster {NAME|ID|EXTERNAL_ID}...",
 	Short: "Initiate cluster hibernation",
 	Long: "Initiates cluster hibernation. While hibernating a cluster will not consume any cloud provider infrastructure" +
 		"but will be counted for quota.",
+	Example: `  # Hibernate all the clusters listed in a file
+  ocm hibernate cluster --ids-from-stdin < clusters.txt`,
 	RunE: run,
 }
 
+func init() {
+	arguments.AddIDsFromStdinFlag(Cmd.Flags(), &args.idsFromStdin)
+}
+
 func run(cmd *cobra.Command, argv []string) error {
-	// Check that there is exactly one cluster name, identifir or external identifier in the
-	// command line arguments:
-	if len(argv) != 1 {
-		fmt.Fprintf(
-			os.Stderr,
-			"Expected exactly one cluster name, identifier or external identifier "+
-				"is required\n",
+	if len(argv) == 0 && !args.idsFromStdin {
+		return fmt.Errorf(
+			"At least one cluster name, identifier or external identifier is required",
 		)
-		os.Exit(1)
 	}
 
-	// Check that the cluster key (name, identifier or external identifier) given by the user
-	// is reasonably safe so that there is no risk of SQL injection:
-	clusterKey := argv[0]
-	if !c.IsValidClusterKey(clusterKey) {
-		return fmt.Errorf(
-			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
-				"must contain only letters, digits, dashes and underscores",
-			clusterKey,
-		)
+	// Get the cluster names, identifiers or external identifiers from the command line or from
+	// the standard input:
+	clusterKeys, err := arguments.IDs(argv, args.idsFromStdin)
+	if err != nil {
+		return err
+	}
+
+	// Check that the cluster keys (name, identifier or external identifier) given by the user
+	// are reasonably safe so that there is no risk of SQL injection:
+	for _, clusterKey := range clusterKeys {
+		if !c.IsValidClusterKey(clusterKey) {
+			return fmt.Errorf(
+				"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+					"must contain only letters, digits, dashes and underscores",
+				clusterKey,
+			)
+		}
 	}
 
 	// Create the client for the OCM API:
@@ -48,16 +66,36 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 	defer connection.Close()
 
-	// Get the client for the cluster management api
-	clusterCollection := connection.ClustersMgmt().V1().Clusters()
+	// When there is only one cluster report the error directly, otherwise continue with the
+	// rest of the clusters and report the number of failures at the end:
+	if len(clusterKeys) == 1 {
+		return hibernate(connection, clusterKeys[0])
+	}
+	failed := 0
+	for _, clusterKey := range clusterKeys {
+		err = hibernate(connection, clusterKey)
+		if errors.Is(err, curl.ErrNotSent) {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("Failed to hibernate %d of %d clusters", failed, len(clusterKeys))
+	}
+	return nil
+}
 
+// hibernate sends the request to hibernate the cluster with the given key.
+func hibernate(connection *sdk.Connection, clusterKey string) error {
 	// Verify the cluster exists in OCM.
 	cluster, err := c.GetCluster(connection, clusterKey)
 	if err != nil {
 		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
 	}
-
-	_, err = clusterCollection.Cluster(cluster.ID()).Hibernate().Send()
+	_, err = connection.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).Hibernate().Send()
 	if err != nil {
 		return err
 	}
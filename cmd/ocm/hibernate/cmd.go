@@ -2,6 +2,7 @@ package hibernate
 
 import (
 	"github.com/openshift-online/ocm-cli/cmd/ocm/hibernate/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
 	"github.com/spf13/cobra"
 )
 
@@ -13,4 +14,5 @@ var Cmd = &cobra.Command{
 
 func init() {
 	Cmd.AddCommand(cluster.Cmd)
+	readonly.Mark(Cmd)
 }
@@ -0,0 +1,256 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	"github.com/openshift-online/ocm-sdk-go/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/manifest"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	dir   string
+	prune bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "cluster {NAME|ID|EXTERNAL_ID}",
+	Short: "Import a cluster and its resources into local manifests",
+	Long: "Fetch a cluster and its machine pools, identity providers, ingresses and upgrade " +
+		"policies, and write them as YAML manifests to a directory, one file per object. " +
+		"When the manifests already exist they are updated with the current state of the " +
+		"objects, preserving the comments added to the fields that still exist.",
+	Example: `  # Import cluster 'mycluster' into the 'mycluster' directory
+  ocm import cluster mycluster
+
+  # Update the manifests of cluster 'mycluster', removing the ones of deleted objects
+  ocm import cluster mycluster --dir clusters/mycluster --prune`,
+	Args: cobra.ExactArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.dir,
+		"dir",
+		"",
+		"Directory where the manifests will be written. By default it is the name of "+
+			"the cluster.",
+	)
+	flags.BoolVar(
+		&args.prune,
+		"prune",
+		false,
+		"Remove the manifests of objects that no longer exist.",
+	)
+}
+
+// collection describes a collection of objects of the cluster that is written to a sub
+// directory of the manifests directory.
+type collection struct {
+	// dir is the name of the sub directory and also of the collection in the API.
+	dir string
+
+	// key is the name of the field used for the names of the files.
+	key string
+}
+
+// collections is the list of collections that are imported.
+var collections = []collection{
+	{dir: "machine_pools", key: "id"},
+	{dir: "identity_providers", key: "name"},
+	{dir: "ingresses", key: "id"},
+	{dir: "upgrade_policies", key: "id"},
+}
+
+// unsafeRE matches the characters that aren't used in the names of the manifest files.
+var unsafeRE = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check that the cluster key (name, identifier or external identifier) given by the user
+	// is reasonably safe so that there is no risk of SQL injection:
+	clusterKey := argv[0]
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Get the cluster:
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	dir := args.dir
+	if dir == "" {
+		dir = cluster.Name()
+	}
+	clusterPath := "/api/clusters_mgmt/v1/clusters/" + url.PathEscape(cluster.ID())
+
+	// Write the manifest of the cluster:
+	data, err := send(connection.Get().Path(clusterPath))
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+	object, err := manifest.Parse(data)
+	if err != nil {
+		return fmt.Errorf("Failed to parse cluster '%s': %v", clusterKey, err)
+	}
+	err = write(filepath.Join(dir, "cluster.yaml"), object)
+	if err != nil {
+		return err
+	}
+
+	// Write the manifests of the objects of each collection:
+	for _, coll := range collections {
+		items, err := list(connection, clusterPath+"/"+coll.dir)
+		if err != nil {
+			return fmt.Errorf(
+				"Failed to get %s of cluster '%s': %v",
+				strings.ReplaceAll(coll.dir, "_", " "), clusterKey, err,
+			)
+		}
+		written := map[string]bool{}
+		for _, item := range items {
+			name := manifest.Field(item, coll.key)
+			if name == nil || name.Value == "" {
+				continue
+			}
+			file := unsafeRE.ReplaceAllString(name.Value, "_") + ".yaml"
+			written[file] = true
+			err = write(filepath.Join(dir, coll.dir, file), item)
+			if err != nil {
+				return err
+			}
+		}
+		err = prune(filepath.Join(dir, coll.dir), written)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// write writes a manifest and reports to the user if it was created or updated.
+func write(path string, object *yaml.Node) error {
+	change, err := manifest.Write(path, object)
+	if err != nil {
+		return fmt.Errorf("Failed to write manifest '%s': %v", path, err)
+	}
+	if change != manifest.Unchanged {
+		fmt.Printf("Manifest '%s' %s\n", path, change)
+	}
+	return nil
+}
+
+// prune removes, or reports if the '--prune' option isn't used, the manifests of the given
+// directory that weren't written because the corresponding objects no longer exist.
+func prune(dir string, written map[string]bool) error {
+	stale, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(stale)
+	for _, path := range stale {
+		if written[filepath.Base(path)] {
+			continue
+		}
+		if !args.prune {
+			fmt.Fprintf(
+				os.Stderr,
+				"Warning: manifest '%s' doesn't correspond to an existing object, use "+
+					"'--prune' to remove it\n",
+				path,
+			)
+			continue
+		}
+		err = os.Remove(path)
+		if err != nil {
+			return fmt.Errorf("Failed to remove manifest '%s': %v", path, err)
+		}
+		fmt.Printf("Manifest '%s' removed\n", path)
+	}
+	return nil
+}
+
+// list retrieves all the pages of the collection with the given path.
+func list(connection *sdk.Connection, path string) (items []*yaml.Node, err error) {
+	size := 100
+	for page := 1; ; page++ {
+		var data []byte
+		data, err = send(
+			connection.Get().
+				Path(path).
+				Parameter("page", page).
+				Parameter("size", size),
+		)
+		if err != nil {
+			return
+		}
+		var pageItems []*yaml.Node
+		pageItems, err = manifest.Items(data)
+		if err != nil {
+			return
+		}
+		items = append(items, pageItems...)
+		if len(pageItems) < size {
+			return
+		}
+	}
+}
+
+// send sends the given request and returns the body of the response, or an error if the server
+// responds with an error status.
+func send(request *sdk.Request) ([]byte, error) {
+	response, err := request.Send()
+	if err != nil {
+		return nil, err
+	}
+	if response.Status() >= http.StatusBadRequest {
+		apiErr, err := errors.UnmarshalErrorStatus(response.Bytes(), response.Status())
+		if err != nil {
+			return nil, fmt.Errorf("status is %d", response.Status())
+		}
+		return nil, apiErr
+	}
+	return response.Bytes(), nil
+}
@@ -0,0 +1,182 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/junit"
+	"github.com/openshift-online/ocm-cli/pkg/lint"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	online bool
+	junit  string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "lint [flags] {FILE|DIR}...",
+	Short: "Check cluster, machine pool and identity provider specification files",
+	Long: "Check that cluster, machine pool and identity provider specification files, like " +
+		"the ones written by the 'import cluster' command, match the API types and follow " +
+		"the recommended practices. Directories are checked recursively. The API is only " +
+		"contacted when the '--online' option is used, to check the availability of the " +
+		"versions.",
+	Example: `  # Check the specification of a cluster
+  ocm lint cluster.yaml
+
+  # Check all the specification files of a directory, including the versions
+  ocm lint --online clusters/mycluster
+
+  # Check the files and write the results as a JUnit report for the CI system
+  ocm lint --junit lint.xml clusters`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.online,
+		"online",
+		false,
+		"Also run the checks that need to contact the API, like the availability of "+
+			"versions.",
+	)
+	flags.StringVar(
+		&args.junit,
+		"junit",
+		"",
+		"Also write the results to this file as a JUnit XML report, with one test case "+
+			"for each file.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Find the files:
+	files := []string{}
+	for _, arg := range argv {
+		found, err := find(arg)
+		if err != nil {
+			return err
+		}
+		files = append(files, found...)
+	}
+
+	// Create the linter, and if requested fetch the data that it needs from the API:
+	linter := lint.NewLinter()
+	if args.online {
+		connection, err := ocm.NewConnection().Build()
+		if err != nil {
+			return fmt.Errorf("Failed to create OCM connection: %v", err)
+		}
+		defer connection.Close()
+		versions, _, err := c.GetEnabledVersions(connection.ClustersMgmt().V1(), "")
+		if err != nil {
+			return fmt.Errorf("Can't retrieve versions: %v", err)
+		}
+		linter.Versions(versions)
+	}
+
+	// Check the files:
+	suite := junit.NewSuite("ocm lint")
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		start := time.Now()
+		before := len(linter.Problems())
+		linter.File(file, data)
+		report(suite.Add("lint", file), linter.Problems()[before:], time.Since(start))
+	}
+	for _, problem := range linter.Problems() {
+		fmt.Println(problem)
+	}
+	if args.junit != "" {
+		err := junit.WriteFile(args.junit, suite)
+		if err != nil {
+			return err
+		}
+	}
+	errors := linter.Errors()
+	if errors > 0 {
+		return fmt.Errorf(
+			"Found %d errors and %d warnings",
+			errors, len(linter.Problems())-errors,
+		)
+	}
+	return nil
+}
+
+// report fills the JUnit test case of a file with the problems found in it. Errors make the case
+// fail, and warnings are added to its output.
+func report(item *junit.Case, problems []*lint.Problem, duration time.Duration) {
+	item.Duration = duration
+	errors := []string{}
+	warnings := []string{}
+	for _, problem := range problems {
+		if problem.Severity == lint.Error {
+			errors = append(errors, problem.String())
+		} else {
+			warnings = append(warnings, problem.String())
+		}
+	}
+	if len(errors) > 0 {
+		item.Failure = fmt.Sprintf("Found %d errors", len(errors))
+		item.Details = strings.Join(errors, "\n")
+	}
+	item.Output = strings.Join(warnings, "\n")
+}
+
+// find returns the specification files that correspond to the given argument: the argument
+// itself if it is a file, or the YAML and JSON files that it contains if it is a directory.
+func find(path string) (files []string, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if !info.IsDir() {
+		files = []string{path}
+		return
+	}
+	err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(file) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, file)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return
+}
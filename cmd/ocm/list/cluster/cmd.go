@@ -20,7 +20,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/spf13/cobra"
@@ -29,15 +31,46 @@ import (
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
 	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/utils"
 )
 
 var args struct {
-	parameter []string
-	header    []string
-	managed   bool
-	noHeaders bool
-	columns   string
-	padding   int
+	parameter     []string
+	header        []string
+	fields        []string
+	managed       bool
+	noHeaders     bool
+	columns       string
+	padding       int
+	groupBy       string
+	createdAfter  string
+	createdBefore string
+}
+
+// groupByColumns maps the values accepted by the `--group-by` option to the names of the columns
+// that will be used to display the aggregated results.
+var groupByColumns = map[string]string{
+	"state":    "state",
+	"version":  "openshift_version",
+	"region":   "region.id",
+	"provider": "cloud_provider.id",
+}
+
+// groupByValues contains the functions used to extract from a cluster the value used to group it
+// for each of the values accepted by the `--group-by` option.
+var groupByValues = map[string]func(*v1.Cluster) string{
+	"state": func(cluster *v1.Cluster) string {
+		return string(cluster.State())
+	},
+	"version": func(cluster *v1.Cluster) string {
+		return cluster.OpenshiftVersion()
+	},
+	"region": func(cluster *v1.Cluster) string {
+		return cluster.Region().ID()
+	},
+	"provider": func(cluster *v1.Cluster) string {
+		return cluster.CloudProvider().ID()
+	},
 }
 
 // Cmd Constant:
@@ -46,14 +79,20 @@ var Cmd = &cobra.Command{
 	Aliases: []string{"cluster"},
 	Short:   "List clusters",
 	Long:    "List clusters, optionally filtering by substring of ID or Name",
-	Args:    cobra.RangeArgs(0, 1),
-	RunE:    run,
+	Example: `  # List the clusters created during the last week
+  ocm list clusters --created-after 7d
+
+  # List the clusters created during January 2022
+  ocm list clusters --created-after 2022-01-01 --created-before 2022-02-01`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: run,
 }
 
 func init() {
 	fs := Cmd.Flags()
 	arguments.AddParameterFlag(fs, &args.parameter)
 	arguments.AddHeaderFlag(fs, &args.header)
+	arguments.AddFieldsFlag(fs, &args.fields)
 	fs.BoolVar(
 		&args.managed,
 		"managed",
@@ -85,12 +124,77 @@ func init() {
 		-1,
 		"Change all column sizes.",
 	)
+	fs.StringVar(
+		&args.groupBy,
+		"group-by",
+		"",
+		"Instead of listing the clusters display the number of clusters grouped by the "+
+			"given field. Valid values are 'state', 'version', 'region' and 'provider'.",
+	)
+	fs.StringVar(
+		&args.createdAfter,
+		"created-after",
+		"",
+		"Only list the clusters created after this time. It can be a time in RFC3339 "+
+			"format, like '2022-01-01T10:00:00Z', a date, like '2022-01-01', or a duration "+
+			"relative to the current time, like '12h', '7d' or '2w'.",
+	)
+	fs.StringVar(
+		&args.createdBefore,
+		"created-before",
+		"",
+		"Only list the clusters created before this time. It accepts the same values "+
+			"as the '--created-after' option.",
+	)
+	Cmd.RegisterFlagCompletionFunc("group-by", groupByCompletion)
+}
+
+func groupByCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return groupByNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// groupByNames returns the sorted list of values accepted by the `--group-by` option.
+func groupByNames() []string {
+	names := make([]string, 0, len(groupByColumns))
+	for name := range groupByColumns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func run(cmd *cobra.Command, argv []string) error {
 	// Create a context:
 	ctx := context.Background()
 
+	// Check the options:
+	if args.groupBy != "" {
+		if _, ok := groupByColumns[args.groupBy]; !ok {
+			return fmt.Errorf(
+				"Invalid value '%s' for option '--group-by', valid values are '%s'",
+				args.groupBy, strings.Join(groupByNames(), "', '"),
+			)
+		}
+		if cmd.Flags().Changed("columns") {
+			return fmt.Errorf("Options '--group-by' and '--columns' are mutually exclusive")
+		}
+	}
+	now := time.Now()
+	createdAfter, err := utils.ParseTime(args.createdAfter, now)
+	if err != nil {
+		return fmt.Errorf("Invalid value for option '--created-after': %v", err)
+	}
+	createdBefore, err := utils.ParseTime(args.createdBefore, now)
+	if err != nil {
+		return fmt.Errorf("Invalid value for option '--created-before': %v", err)
+	}
+	if !createdAfter.IsZero() && !createdBefore.IsZero() && !createdAfter.Before(createdBefore) {
+		return fmt.Errorf(
+			"Value of option '--created-after' must be earlier than the value of " +
+				"option '--created-before'",
+		)
+	}
+
 	// Load the configuration:
 	cfg, err := config.Load()
 	if err != nil {
@@ -114,10 +218,20 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 	defer printer.Close()
 
-	// Create the output table:
+	// Create the output table. When grouping the table contains only the value of the grouping
+	// field and the number of clusters that have that value:
+	// If only some fields are requested and the columns haven't been explicitly given then
+	// display the requested fields, as the rest of the columns would be empty:
+	columns := args.columns
+	if len(args.fields) > 0 && !cmd.Flags().Changed("columns") {
+		columns = strings.Join(args.fields, ", ")
+	}
+	if args.groupBy != "" {
+		columns = groupByColumns[args.groupBy] + ", count"
+	}
 	table, err := printer.NewTable().
 		Name("clusters").
-		Columns(args.columns).
+		Columns(columns).
 		Build(ctx)
 	if err != nil {
 		return err
@@ -145,6 +259,22 @@ func run(cmd *cobra.Command, argv []string) error {
 		searchTerms = append(searchTerms, term)
 	}
 
+	// Add the search terms for the `--created-after` and `--created-before` flags:
+	if !createdAfter.IsZero() {
+		term := fmt.Sprintf(
+			"creation_timestamp >= '%s'",
+			createdAfter.UTC().Format(time.RFC3339),
+		)
+		searchTerms = append(searchTerms, term)
+	}
+	if !createdBefore.IsZero() {
+		term := fmt.Sprintf(
+			"creation_timestamp < '%s'",
+			createdBefore.UTC().Format(time.RFC3339),
+		)
+		searchTerms = append(searchTerms, term)
+	}
+
 	// If the `search` parameter has been specified with the `--parameter` flag then we have to
 	// remove it and add the values to the list of search terms, otherwise we will be sending
 	// multiple `search` query parameters and the server will ignore all but one of them. Note
@@ -183,6 +313,15 @@ func run(cmd *cobra.Command, argv []string) error {
 	request := connection.ClustersMgmt().V1().Clusters().List().Search(searchQuery)
 	arguments.ApplyParameterFlag(request, args.parameter)
 	arguments.ApplyHeaderFlag(request, args.header)
+	arguments.ApplyFieldsFlag(request, args.fields)
+
+	// When grouping we accumulate the counts and write them once all the pages have been
+	// processed:
+	var groupValue func(*v1.Cluster) string
+	groupCounts := map[string]int{}
+	if args.groupBy != "" {
+		groupValue = groupByValues[args.groupBy]
+	}
 
 	// Send the request till we receive a page with less items than requested:
 	size := 100
@@ -196,8 +335,12 @@ func run(cmd *cobra.Command, argv []string) error {
 			return fmt.Errorf("Can't retrieve clusters: %v", err)
 		}
 
-		// Display the items of the fetched page:
+		// Display or count the items of the fetched page:
 		response.Items().Each(func(cluster *v1.Cluster) bool {
+			if groupValue != nil {
+				groupCounts[groupValue(cluster)]++
+				return true
+			}
 			err = table.WriteObject(cluster)
 			return err == nil
 		})
@@ -213,5 +356,39 @@ func run(cmd *cobra.Command, argv []string) error {
 		index++
 	}
 
+	// Write the aggregated counts, if needed:
+	if groupValue != nil {
+		err = writeGroups(table, groupCounts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeGroups writes to the table the given group counts, sorted so that the larger groups are
+// displayed first.
+func writeGroups(table *output.Table, counts map[string]int) error {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		var value interface{}
+		if key != "" {
+			value = key
+		}
+		err := table.WriteRow([]interface{}{value, counts[key]})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list/org"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list/quota"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list/region"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/list/schedule"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list/upgradepolicy"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list/user"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list/version"
@@ -43,6 +44,7 @@ func init() {
 	Cmd.AddCommand(machinepool.Cmd)
 	Cmd.AddCommand(quota.Cmd)
 	Cmd.AddCommand(region.Cmd)
+	Cmd.AddCommand(schedule.Cmd)
 	Cmd.AddCommand(upgradepolicy.Cmd)
 	Cmd.AddCommand(user.Cmd)
 	Cmd.AddCommand(version.Cmd)
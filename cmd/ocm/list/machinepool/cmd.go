@@ -95,29 +95,57 @@ func run(cmd *cobra.Command, argv []string) error {
 		return err
 	}
 
+	// The version of the SDK that we use doesn't support the IMDSv2 setting of the machine
+	// pools, so for AWS clusters we get it with raw requests:
+	aws := cluster.CloudProvider().ID() == "aws"
+	var clusterDetails *c.ClusterDetails
+	var poolsDetails map[string]*c.MachinePoolDetails
+	if aws {
+		clusterDetails, err = c.GetClusterDetails(connection, cluster.ID())
+		if err != nil {
+			return err
+		}
+		poolsDetails, err = c.GetMachinePoolsDetails(connection, cluster.ID())
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create the writer that will be used to print the tabulated results:
 	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 
-	fmt.Fprintf(writer, "ID\tAUTOSCALING\tREPLICAS\tINSTANCE TYPE\tLABELS\t\tTAINTS\t\tAVAILABILITY ZONES\n")
-	fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t\t%s\t\t%s\n",
+	header := "ID\tAUTOSCALING\tREPLICAS\tINSTANCE TYPE\tLABELS\t\tTAINTS\t\tAVAILABILITY ZONES"
+	if aws {
+		header += "\t\tEC2 METADATA HTTP TOKENS"
+	}
+	fmt.Fprintf(writer, "%s\n", header)
+	row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t\t%s\t\t%s",
 		"default",
 		printAutoscaling(cluster.Nodes().AutoscaleCompute()),
 		printReplicas(cluster.Nodes().AutoscaleCompute(), cluster.Nodes().Compute()),
 		cluster.Nodes().ComputeMachineType().ID(),
-		printLabels(cluster.Nodes().ComputeLabels()),
+		c.PrintLabels(cluster.Nodes().ComputeLabels()),
 		"",
 		printAZ(cluster.Nodes().AvailabilityZones()),
 	)
+	if aws {
+		row += "\t\t" + clusterDetails.EC2MetadataHTTPTokens()
+	}
+	fmt.Fprintf(writer, "%s\n", row)
 	for _, machinePool := range machinePools {
-		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t\t%s\t\t%s\n",
+		row = fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t\t%s\t\t%s",
 			machinePool.ID(),
 			printAutoscaling(machinePool.Autoscaling()),
 			printReplicas(machinePool.Autoscaling(), machinePool.Replicas()),
 			machinePool.InstanceType(),
-			printLabels(machinePool.Labels()),
-			printTaints(machinePool.Taints()),
+			c.PrintLabels(machinePool.Labels()),
+			c.PrintTaints(machinePool.Taints()),
 			printAZ(machinePool.AvailabilityZones()),
 		)
+		if aws {
+			row += "\t\t" + poolsDetails[machinePool.ID()].EC2MetadataHTTPTokens()
+		}
+		fmt.Fprintf(writer, "%s\n", row)
 	}
 	writer.Flush()
 
@@ -146,27 +174,3 @@ func printAZ(az []string) string {
 	}
 	return strings.Join(az, ", ")
 }
-
-func printLabels(labels map[string]string) string {
-	if len(labels) == 0 {
-		return ""
-	}
-	output := []string{}
-	for k, v := range labels {
-		output = append(output, fmt.Sprintf("%s=%s", k, v))
-	}
-
-	return strings.Join(output, ", ")
-}
-
-func printTaints(taints []*cmv1.Taint) string {
-	if len(taints) == 0 {
-		return ""
-	}
-	output := []string{}
-	for _, taint := range taints {
-		output = append(output, fmt.Sprintf("%s=%s:%s", taint.Key(), taint.Value(), taint.Effect()))
-	}
-
-	return strings.Join(output, ", ")
-}
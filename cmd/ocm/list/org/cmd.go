@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 	"github.com/spf13/cobra"
@@ -33,6 +34,7 @@ import (
 var args struct {
 	parameter []string
 	header    []string
+	fields    []string
 	columns   string
 }
 
@@ -49,6 +51,7 @@ func init() {
 	fs := Cmd.Flags()
 	arguments.AddParameterFlag(fs, &args.parameter)
 	arguments.AddHeaderFlag(fs, &args.header)
+	arguments.AddFieldsFlag(fs, &args.fields)
 	fs.StringVar(
 		&args.columns,
 		"columns",
@@ -84,10 +87,17 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 	defer printer.Close()
 
+	// If only some fields are requested and the columns haven't been explicitly given then
+	// display the requested fields, as the rest of the columns would be empty:
+	columns := args.columns
+	if len(args.fields) > 0 && !cmd.Flags().Changed("columns") {
+		columns = strings.Join(args.fields, ", ")
+	}
+
 	// Create the output table:
 	table, err := printer.NewTable().
 		Name("orgs").
-		Columns(args.columns).
+		Columns(columns).
 		Build(ctx)
 	if err != nil {
 		return err
@@ -105,6 +115,7 @@ func run(cmd *cobra.Command, argv []string) error {
 	request := connection.AccountsMgmt().V1().Organizations().List()
 	arguments.ApplyParameterFlag(request, args.parameter)
 	arguments.ApplyHeaderFlag(request, args.header)
+	arguments.ApplyFieldsFlag(request, args.fields)
 
 	// Send the request till we receive a page with less items than requested:
 	size := 100
@@ -23,22 +23,27 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/pkg/account"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
 )
 
 var args struct {
-	json bool
-	org  string
+	json          bool
+	org           string
+	failWhenAbove string
 }
 
 var Cmd = &cobra.Command{
 	Use:   "quota",
 	Short: "Retrieve cluster quota information.",
 	Long:  "Retrieve cluster quota information of a specific organization.",
-	Args:  cobra.NoArgs,
-	RunE:  run,
+	Example: `  # Fail if any quota of the organization is more than 80% consumed
+  ocm list quota --fail-when-above 80%`,
+	Args: cobra.NoArgs,
+	RunE: run,
 }
 
 func init() {
@@ -56,9 +61,25 @@ func init() {
 		"",
 		"Specify which organization to query information from. Default to local users organization.",
 	)
+	flags.StringVar(
+		&args.failWhenAbove,
+		"fail-when-above",
+		"",
+		"Fail with exit code 3 if the usage of any quota is above this percentage, for "+
+			"example '80%'. The error message contains a summary of the quotas above it.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	var threshold float64
+	if args.failWhenAbove != "" {
+		var err error
+		threshold, err = account.ParseQuotaThreshold(args.failWhenAbove)
+		if err != nil {
+			return err
+		}
+	}
+
 	connection, err := ocm.NewConnection().Build()
 	if err != nil {
 		return fmt.Errorf("Failed to create OCM connection: %v", err)
@@ -106,7 +127,7 @@ func run(cmd *cobra.Command, argv []string) error {
 			return nil
 		}
 
-		return nil
+		return checkThreshold(connection, orgID, threshold)
 	}
 
 	// TODO: Do this without hard-code; could not find any marshall method
@@ -123,5 +144,18 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Failed to display quota JSON: %v", err)
 	}
 
-	return nil
+	return checkThreshold(connection, orgID, threshold)
+}
+
+// checkThreshold fails if the usage of any quota is above the threshold given with the
+// '--fail-when-above' option.
+func checkThreshold(connection *sdk.Connection, orgID string, threshold float64) error {
+	if args.failWhenAbove == "" {
+		return nil
+	}
+	quotas, err := account.ListQuotaCosts(connection, orgID)
+	if err != nil {
+		return err
+	}
+	return account.CheckQuotaThreshold(orgID, quotas, threshold)
 }
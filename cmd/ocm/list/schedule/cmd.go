@@ -0,0 +1,54 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/schedule"
+)
+
+var Cmd = &cobra.Command{
+	Use:     "schedules",
+	Aliases: []string{"schedule"},
+	Short:   "List cluster schedules",
+	Long:    "List the schedules that hibernate and resume clusters periodically",
+	Args:    cobra.NoArgs,
+	RunE:    run,
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	schedules, err := schedule.Load()
+	if err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "CLUSTER ID\tCLUSTER NAME\tACTION\tCRON\tLAST RUN\n")
+	for _, item := range schedules {
+		lastRun := "NONE"
+		if !item.LastRun.IsZero() {
+			lastRun = item.LastRun.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+			item.ClusterID, item.ClusterName, item.Action, item.Cron, lastRun)
+	}
+	return writer.Flush()
+}
@@ -23,6 +23,7 @@ import (
 
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 
 	"github.com/spf13/cobra"
@@ -30,6 +31,9 @@ import (
 
 var args struct {
 	clusterKey string
+	nodePool   string
+	nodePools  bool
+	pending    bool
 }
 
 var Cmd = &cobra.Command{
@@ -38,7 +42,11 @@ var Cmd = &cobra.Command{
 	Short:   "List cluster upgrade policies",
 	Long:    "List upgrade policies for a cluster.",
 	Example: `  # List all upgrade policies on a cluster named "mycluster"
-  ocm list upgradepolicies --cluster=mycluster`,
+  ocm list upgradepolicies --cluster=mycluster
+  # List the upgrade policies of all the node pools of a cluster named "mycluster"
+  ocm list upgradepolicies --cluster=mycluster --node-pools
+  # List the node pool upgrades that haven't finished yet
+  ocm list upgradepolicies --cluster=mycluster --node-pools --pending`,
 	Args: cobra.NoArgs,
 	RunE: run,
 }
@@ -55,10 +63,40 @@ func init() {
 	)
 	//nolint:gosec
 	Cmd.MarkFlagRequired("cluster")
+
+	flags.StringVar(
+		&args.nodePool,
+		"node-pool",
+		"",
+		"List the upgrade policies of this node pool instead of the ones of the control plane. "+
+			"Only for clusters with hosted control planes.",
+	)
+	flags.BoolVar(
+		&args.nodePools,
+		"node-pools",
+		false,
+		"List the upgrade policies of all the node pools of the cluster. Only for clusters "+
+			"with hosted control planes.",
+	)
+	flags.BoolVar(
+		&args.pending,
+		"pending",
+		false,
+		"List only the node pool upgrade policies that haven't finished yet, excluding the "+
+			"completed, cancelled and failed ones. Requires '--node-pool' or '--node-pools'.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
 
+	// Check the options:
+	if args.nodePool != "" && args.nodePools {
+		return fmt.Errorf("Options '--node-pool' and '--node-pools' are mutually exclusive")
+	}
+	if args.pending && args.nodePool == "" && !args.nodePools {
+		return fmt.Errorf("Option '--pending' requires '--node-pool' or '--node-pools'")
+	}
+
 	// Check that the cluster key (name, identifier or external identifier) given by the user
 	// is reasonably safe so that there is no risk of SQL injection:
 	clusterKey := args.clusterKey
@@ -89,6 +127,10 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Cluster '%s' is not yet ready", clusterKey)
 	}
 
+	if args.nodePool != "" || args.nodePools {
+		return listNodePoolPolicies(connection, cluster)
+	}
+
 	upgradePolicies, err := c.GetUpgradePolicies(clusterCollection, cluster.ID())
 	if err != nil {
 		return err
@@ -111,3 +153,54 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	return nil
 }
+
+// listNodePoolPolicies prints the upgrade policies of the node pool selected with the
+// '--node-pool' option, or of all the node pools of the cluster if '--node-pools' was used.
+func listNodePoolPolicies(connection *sdk.Connection, cluster *cmv1.Cluster) error {
+	nodePoolIDs := []string{args.nodePool}
+	if args.nodePools {
+		nodePools, err := c.GetNodePools(connection, cluster.ID())
+		if err != nil {
+			return err
+		}
+		nodePoolIDs = nodePoolIDs[:0]
+		for _, nodePool := range nodePools {
+			nodePoolIDs = append(nodePoolIDs, nodePool.ID)
+		}
+	}
+
+	// Create the writer that will be used to print the tabulated results:
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "ID\tNODE POOL\tSCHEDULE TYPE\tUPGRADE VERSION\tSTATE\tNEXT RUN\n")
+	for _, nodePoolID := range nodePoolIDs {
+		upgradePolicies, err := c.GetNodePoolUpgradePolicies(connection, cluster.ID(), nodePoolID)
+		if err != nil {
+			return err
+		}
+		for _, upgradePolicy := range upgradePolicies {
+			if args.pending && !upgradePolicy.Pending() {
+				continue
+			}
+			var state, nextRun string
+			if upgradePolicy.State != nil {
+				state = upgradePolicy.State.Value
+			}
+			if upgradePolicy.NextRun != nil {
+				nextRun = upgradePolicy.NextRun.String()
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				upgradePolicy.ID,
+				nodePoolID,
+				upgradePolicy.ScheduleType,
+				upgradePolicy.Version,
+				state,
+				nextRun)
+		}
+	}
+
+	//nolint:gosec
+	writer.Flush()
+
+	return nil
+}
@@ -18,33 +18,32 @@ package login
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/devicecode"
+	"github.com/openshift-online/ocm-cli/pkg/hooks"
 	"github.com/openshift-online/ocm-cli/pkg/urls"
 )
 
-const (
-	productionURL  = "https://api.openshift.com"
-	stagingURL     = "https://api.stage.openshift.com"
-	integrationURL = "https://api.integration.openshift.com"
-)
-
 // When the value of the `--url` option is one of the keys of this map it will be replaced by the
 // corresponding value.
 var urlAliases = map[string]string{
-	"production":  productionURL,
-	"prod":        productionURL,
-	"prd":         productionURL,
-	"staging":     stagingURL,
-	"stage":       stagingURL,
-	"stg":         stagingURL,
-	"integration": integrationURL,
-	"int":         integrationURL,
+	"production":  urls.ProductionURL,
+	"prod":        urls.ProductionURL,
+	"prd":         urls.ProductionURL,
+	"staging":     urls.StagingURL,
+	"stage":       urls.StagingURL,
+	"stg":         urls.StagingURL,
+	"integration": urls.IntegrationURL,
+	"int":         urls.IntegrationURL,
+	"sandbox":     urls.SandboxURL,
 }
 
 var args struct {
@@ -58,6 +57,9 @@ var args struct {
 	password     string
 	insecure     bool
 	persistent   bool
+	tokenFile    string
+	deviceCode   bool
+	authCommand  string
 }
 
 var Cmd = &cobra.Command{
@@ -65,7 +67,21 @@ var Cmd = &cobra.Command{
 	Short: "Log in",
 	Long: "Log in, saving the credentials to the configuration file.\n" +
 		"The recommend way is using '--token', which you can obtain at: " +
-		urls.OfflineTokenPage,
+		urls.OfflineTokenPage + "\n\n" +
+		"Alternatively use '--use-device-code' to log in with a browser, possibly in a " +
+		"different machine, without copying tokens. The refresh tokens obtained this way " +
+		"are replaced in the configuration file when the server rotates them, and when " +
+		"they expire the browser flow starts again.\n\n" +
+		"Deployments that don't use the Red Hat SSO can use '--auth-command' to obtain the " +
+		"tokens from a credential helper, similar to the exec plugins of kubeconfig files.",
+	Example: `  # Log in with a token
+  ocm login --token-file=token.txt
+
+  # Log in with a browser
+  ocm login --use-device-code
+
+  # Log in with a credential helper
+  ocm login --url=https://api.example.com --auth-command="vault read -field=token secret/ocm"`,
 	Args: cobra.NoArgs,
 	RunE: run,
 }
@@ -108,7 +124,8 @@ func init() {
 		"url",
 		sdk.DefaultURL,
 		"URL of the API gateway. The value can be the complete URL or an alias. The "+
-			"valid aliases are 'production', 'staging', 'integration' and their shorthands.",
+			"valid aliases are 'production', 'staging', 'integration' and their shorthands, "+
+			"and 'sandbox' for the local server started with 'ocm sandbox start'.",
 	)
 	flags.StringVar(
 		&args.token,
@@ -116,6 +133,30 @@ func init() {
 		"",
 		"Access or refresh token.",
 	)
+	flags.StringVar(
+		&args.tokenFile,
+		"token-file",
+		"",
+		"File containing the access or refresh token. Use '-' to read the token from the "+
+			"standard input. This avoids having the token in the shell history or in the "+
+			"list of processes.",
+	)
+	flags.BoolVar(
+		&args.deviceCode,
+		"use-device-code",
+		false,
+		"Log in using the OAuth device authorization flow: the command prints a URL and a "+
+			"code, and waits till the user opens the URL in a browser and approves the "+
+			"access.",
+	)
+	flags.StringVar(
+		&args.authCommand,
+		"auth-command",
+		"",
+		"Credential helper command that writes a token to the standard output, with its "+
+			"arguments separated by spaces. The helper runs every time that a token is "+
+			"needed, and the tokens aren't saved to the configuration file.",
+	)
 	flags.StringVar(
 		&args.user,
 		"user",
@@ -154,21 +195,59 @@ func run(cmd *cobra.Command, argv []string) error {
 		return fmt.Errorf("Option '--url' is mandatory")
 	}
 
+	// Read the token from the file or from the standard input if requested:
+	if args.tokenFile != "" {
+		if args.token != "" {
+			return fmt.Errorf("Options '--token' and '--token-file' are mutually exclusive")
+		}
+		args.token, err = readTokenFile(args.tokenFile)
+		if err != nil {
+			return err
+		}
+		if args.token == "" {
+			return fmt.Errorf("Token file '%s' is empty", args.tokenFile)
+		}
+	}
+
+	// Tokens copied from files or consoles, specially on Windows, may contain trailing
+	// carriage returns or spaces that would make them invalid:
+	args.token = strings.TrimSpace(args.token)
+
+	// The sandbox server accepts any credentials, so use the default ones if none have been
+	// given:
+	sandbox := args.url == "sandbox" || args.url == urls.SandboxURL
+	if sandbox && args.token == "" && args.user == "" && args.clientID == "" {
+		args.clientID = urls.SandboxClientID
+		args.clientSecret = urls.SandboxClientSecret
+	}
+
 	// Check that we have some kind of credentials:
 	havePassword := args.user != "" && args.password != ""
 	haveSecret := args.clientID != "" && args.clientSecret != ""
 	haveToken := args.token != ""
-	if !havePassword && !haveSecret && !haveToken {
+	haveCommand := args.authCommand != ""
+	if args.deviceCode && (havePassword || haveSecret || haveToken || haveCommand) {
+		return fmt.Errorf(
+			"Option '--use-device-code' can't be used with other credentials",
+		)
+	}
+	if haveCommand && (havePassword || haveSecret || haveToken) {
+		return fmt.Errorf(
+			"Option '--auth-command' can't be used with other credentials",
+		)
+	}
+	if !havePassword && !haveSecret && !haveToken && !args.deviceCode && !haveCommand {
 		// Allow bare `ocm login` to suggest the token page without noise of full help.
 		fmt.Fprintf(
 			os.Stderr,
 			"In order to log in it is mandatory to use '--token', '--user' and "+
-				"'--password', or '--client-id' and '--client-secret'.\n"+
+				"'--password', '--client-id' and '--client-secret', "+
+				"'--use-device-code' or '--auth-command'.\n"+
 				"You can obtain a token at: %s .\n"+
 				"See 'ocm login --help' for full help.\n",
 			urls.OfflineTokenPage,
 		)
-		os.Exit(1)
+		hooks.Exit(1)
 	}
 
 	// Inform the user that it isn't recommended to authenticate with user name and password:
@@ -183,10 +262,9 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 
 	// If a token has been provided parse it:
-	var token *jwt.Token
 	if haveToken {
 		parser := new(jwt.Parser)
-		token, _, err = parser.ParseUnverified(args.token, jwt.MapClaims{})
+		_, _, err = parser.ParseUnverified(args.token, jwt.MapClaims{})
 		if err != nil {
 			return fmt.Errorf("Can't parse token '%s': %v", args.token, err)
 		}
@@ -194,6 +272,9 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	// Apply the default OpenID details if not explicitly provided by the user:
 	tokenURL := sdk.DefaultTokenURL
+	if sandbox {
+		tokenURL = urls.SandboxTokenURL
+	}
 	if args.tokenURL != "" {
 		tokenURL = args.tokenURL
 	}
@@ -229,22 +310,37 @@ func run(cmd *cobra.Command, argv []string) error {
 	cfg.Insecure = args.insecure
 	cfg.AccessToken = ""
 	cfg.RefreshToken = ""
+	cfg.AuthProvider = ""
+	cfg.AuthCommand = ""
+	if haveCommand {
+		cfg.AuthProvider = config.AuthProviderExec
+		cfg.AuthCommand = args.authCommand
+	}
+
+	// Obtain the tokens with the browser if requested, and remember it so that the flow runs
+	// again when the tokens expire:
+	if args.deviceCode {
+		cfg.AuthProvider = config.AuthProviderDeviceCode
+		var tokens *devicecode.Tokens
+		tokens, err = devicecode.NewFlow().
+			TokenURL(tokenURL).
+			ClientID(clientID).
+			Scopes(args.scopes...).
+			Insecure(args.insecure).
+			Output(os.Stderr).
+			Run(cmd.Context())
+		if err != nil {
+			return err
+		}
+		cfg.AccessToken = tokens.Access
+		cfg.RefreshToken = tokens.Refresh
+	}
 
 	// Put the token in the place of the configuration that corresponds to its type:
 	if haveToken {
-		typ, err := tokenType(token)
+		err = cfg.SetToken(args.token)
 		if err != nil {
-			return fmt.Errorf("Can't extract type from 'typ' claim of token '%s': %v", args.token, err)
-		}
-		switch typ {
-		case "Bearer":
-			cfg.AccessToken = args.token
-		case "Refresh", "Offline":
-			cfg.RefreshToken = args.token
-		case "":
-			return fmt.Errorf("Don't know how to handle empty type in token '%s'", args.token)
-		default:
-			return fmt.Errorf("Don't know how to handle token type '%s' in token '%s'", typ, args.token)
+			return err
 		}
 	}
 
@@ -259,9 +355,12 @@ func run(cmd *cobra.Command, argv []string) error {
 	}
 
 	// Save the configuration, but clear the user name and password before unless we have
-	// explicitly been asked to store them persistently:
-	cfg.AccessToken = accessToken
-	cfg.RefreshToken = refreshToken
+	// explicitly been asked to store them persistently. The tokens returned by credential helpers
+	// aren't saved, as the helper will be called again when they are needed:
+	if !haveCommand {
+		cfg.AccessToken = accessToken
+		cfg.RefreshToken = refreshToken
+	}
 	if !args.persistent {
 		cfg.User = ""
 		cfg.Password = ""
@@ -274,23 +373,20 @@ func run(cmd *cobra.Command, argv []string) error {
 	return nil
 }
 
-// tokenType extracts the value of the `typ` claim. It returns the value as a string, or the empty
-// string if there is no such claim.
-func tokenType(token *jwt.Token) (typ string, err error) {
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		err = fmt.Errorf("expected map claims but got %T", claims)
-		return
+// readTokenFile reads the token from the given file, or from the standard input if the name of the
+// file is '-'.
+func readTokenFile(file string) (token string, err error) {
+	var data []byte
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		// #nosec G304
+		data, err = os.ReadFile(file)
 	}
-	claim, ok := claims["typ"]
-	if !ok {
-		return
-	}
-	value, ok := claim.(string)
-	if !ok {
-		err = fmt.Errorf("expected string 'typ' but got %T", claim)
+	if err != nil {
+		err = fmt.Errorf("Can't read token file '%s': %v", file, err)
 		return
 	}
-	typ = value
+	token = strings.TrimSpace(string(data))
 	return
 }
@@ -0,0 +1,35 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"github.com/openshift-online/ocm-cli/cmd/ocm/logs/service/post"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/logs/service/templates"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "service COMMAND",
+	Short: "Work with service logs",
+	Long:  "Post service logs to clusters using templates, and list the available templates",
+	Args:  cobra.MinimumNArgs(1),
+}
+
+func init() {
+	Cmd.AddCommand(post.Cmd)
+	Cmd.AddCommand(templates.Cmd)
+}
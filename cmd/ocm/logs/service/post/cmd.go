@@ -0,0 +1,195 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package post
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+	"github.com/openshift-online/ocm-cli/pkg/servicelog"
+)
+
+var args struct {
+	template string
+	vars     []string
+	dryRun   bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "post",
+	Short: "Post a service log to a cluster using a template",
+	Long: "Render a service log template with the given variables and post the result to " +
+		"a cluster. The 'cluster' variable is mandatory and selects the cluster, by name, " +
+		"identifier or external identifier. Templates can also use the 'cluster_id' and " +
+		"'cluster_name' variables, calculated from the cluster. Use the 'ocm logs service " +
+		"templates' command to see the available templates and their variables.",
+	Example: `  # Notify the start of an incident
+  ocm logs service post --template incident-start --var cluster=mycluster \
+  --var impact="The console isn't reachable"
+
+  # Show the service log that would be posted, without posting it
+  ocm logs service post --template incident-resolved --var cluster=mycluster \
+  --var resolution="The ingress controller was restarted" --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.template,
+		"template",
+		"",
+		"Name of the template.",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("template")
+	flags.StringArrayVar(
+		&args.vars,
+		"var",
+		nil,
+		"Value of a variable of the template, in the form 'NAME=VALUE'. Can be used "+
+			"multiple times.",
+	)
+	dryrun.BoolVar(
+		flags,
+		&args.dryRun,
+		"Show the service log that would be posted without posting it.",
+	)
+	Cmd.RegisterFlagCompletionFunc("template", completeTemplates)
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Parse the variables:
+	vars := map[string]string{}
+	for _, text := range args.vars {
+		name, value := arguments.ParseNameValuePair(text)
+		if name == "" {
+			return fmt.Errorf("Variable '%s' isn't valid, it must be 'NAME=VALUE'", text)
+		}
+		vars[name] = value
+	}
+	clusterKey := vars[servicelog.VarCluster]
+	if clusterKey == "" {
+		return fmt.Errorf(
+			"Variable '%s' is mandatory, use '--var %s={NAME|ID|EXTERNAL_ID}'",
+			servicelog.VarCluster, servicelog.VarCluster,
+		)
+	}
+	if !c.IsValidClusterKey(clusterKey) {
+		return fmt.Errorf(
+			"Cluster name, identifier or external identifier '%s' isn't valid: it "+
+				"must contain only letters, digits, dashes and underscores",
+			clusterKey,
+		)
+	}
+
+	// Find the template:
+	templates, err := loadTemplates()
+	if err != nil {
+		return err
+	}
+	template, ok := templates[args.template]
+	if !ok {
+		return fmt.Errorf(
+			"Template '%s' doesn't exist, available templates are %s",
+			args.template, strings.Join(servicelog.Names(templates), ", "),
+		)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	cluster, err := c.GetCluster(connection, clusterKey)
+	if err != nil {
+		return fmt.Errorf("Failed to get cluster '%s': %v", clusterKey, err)
+	}
+
+	// Render the template:
+	vars[servicelog.VarClusterID] = cluster.ID()
+	vars[servicelog.VarClusterName] = cluster.Name()
+	entry, err := template.Render(vars)
+	if err != nil {
+		return err
+	}
+	if args.dryRun {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("Can't marshal service log: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+
+	// Post the service log:
+	body, err := slv1.NewLogEntry().
+		ClusterID(cluster.ID()).
+		ClusterUUID(cluster.ExternalID()).
+		SubscriptionID(cluster.Subscription().ID()).
+		Severity(slv1.Severity(entry.Severity)).
+		ServiceName(entry.ServiceName).
+		Summary(entry.Summary).
+		Description(entry.Description).
+		InternalOnly(entry.InternalOnly).
+		Build()
+	if err != nil {
+		return fmt.Errorf("Failed to build service log: %v", err)
+	}
+	response, err := connection.ServiceLogs().V1().ClusterLogs().Add().
+		Body(body).
+		Send()
+	if err != nil {
+		return fmt.Errorf("Failed to post service log to cluster '%s': %v", clusterKey, err)
+	}
+	fmt.Printf(
+		"Posted service log '%s' to cluster '%s'\n",
+		response.Body().ID(), cluster.Name(),
+	)
+	return nil
+}
+
+func loadTemplates() (map[string]*servicelog.Template, error) {
+	location, err := config.Location()
+	if err != nil {
+		return nil, err
+	}
+	return servicelog.LoadTemplates(servicelog.Locations(location))
+}
+
+func completeTemplates(cmd *cobra.Command, argv []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	templates, err := loadTemplates()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return servicelog.Names(templates), cobra.ShellCompDirectiveNoFileComp
+}
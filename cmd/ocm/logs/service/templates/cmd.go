@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/servicelog"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "templates [NAME]",
+	Short: "List the service log templates",
+	Long: "List the templates that can be used to post service logs, or show the details " +
+		"of a template when its name is given. Besides the builtin templates, templates are " +
+		"loaded from the 'service-log-templates' directory next to the configuration file, " +
+		"or from the directories given in the 'OCM_SERVICE_LOG_TEMPLATES' environment " +
+		"variable. Each template is a YAML file, and its name is the name of the file " +
+		"without the extension. Templates loaded from those directories replace builtin " +
+		"templates with the same name.",
+	Example: `  # List the available templates
+  ocm logs service templates
+
+  # Show the variables and the text of the 'incident-start' template
+  ocm logs service templates incident-start`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: run,
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	location, err := config.Location()
+	if err != nil {
+		return err
+	}
+	templates, err := servicelog.LoadTemplates(servicelog.Locations(location))
+	if err != nil {
+		return err
+	}
+	if len(argv) == 0 {
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "NAME\tSEVERITY\tSOURCE\tDESCRIPTION\n")
+		for _, name := range servicelog.Names(templates) {
+			template := templates[name]
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n",
+				template.Name,
+				template.Log.Severity,
+				template.Source,
+				template.Description,
+			)
+		}
+		return writer.Flush()
+	}
+	template, ok := templates[argv[0]]
+	if !ok {
+		return fmt.Errorf(
+			"Template '%s' doesn't exist, available templates are %s",
+			argv[0], strings.Join(servicelog.Names(templates), ", "),
+		)
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Name:\t%s\n", template.Name)
+	fmt.Fprintf(writer, "Description:\t%s\n", template.Description)
+	fmt.Fprintf(writer, "Source:\t%s\n", template.Source)
+	fmt.Fprintf(writer, "Severity:\t%s\n", template.Log.Severity)
+	fmt.Fprintf(writer, "Service name:\t%s\n", template.Log.ServiceName)
+	fmt.Fprintf(writer, "Internal only:\t%t\n", template.Log.InternalOnly)
+	fmt.Fprintf(writer, "Summary:\t%s\n", template.Log.Summary)
+	fmt.Fprintf(writer, "Description template:\t%s\n", template.Log.Description)
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "VARIABLE\tDEFAULT\tDESCRIPTION\n")
+	fmt.Fprintf(writer, "%s\t-\tName, identifier or external identifier of the cluster\n",
+		servicelog.VarCluster)
+	for _, variable := range template.Variables {
+		value := "-"
+		if variable.Default != nil {
+			value = *variable.Default
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", variable.Name, value, variable.Description)
+	}
+	return writer.Flush()
+}
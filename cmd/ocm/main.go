@@ -17,51 +17,82 @@ limitations under the License.
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	_ "github.com/golang/glog"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	"github.com/openshift-online/ocm-cli/cmd/ocm/accessrequest"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/account"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/annotate"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/api"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/cache"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/cluster"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/completion"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/config"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/contextcmd"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/create"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/delete"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/describe"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/edit"
+	examplescmd "github.com/openshift-online/ocm-cli/cmd/ocm/examples"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/fail"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/fleet"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/generate"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/get"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/hibernate"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/importcmd"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/lint"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/list"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/login"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/logout"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/logs"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/patch"
 	plugincmd "github.com/openshift-online/ocm-cli/cmd/ocm/plugin"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/pop"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/post"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/push"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/releasenotes"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/resume"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/sandbox"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/search"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/success"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/token"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/top"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/tunnel"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/version"
+	"github.com/openshift-online/ocm-cli/cmd/ocm/wait"
 	"github.com/openshift-online/ocm-cli/cmd/ocm/whoami"
 	"github.com/openshift-online/ocm-cli/pkg/arguments"
+	"github.com/openshift-online/ocm-cli/pkg/bulk"
+	pkgcache "github.com/openshift-online/ocm-cli/pkg/cache"
+	ocmconfig "github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/curl"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+	"github.com/openshift-online/ocm-cli/pkg/examples"
+	"github.com/openshift-online/ocm-cli/pkg/ghactions"
+	"github.com/openshift-online/ocm-cli/pkg/hints"
+	"github.com/openshift-online/ocm-cli/pkg/hooks"
+	"github.com/openshift-online/ocm-cli/pkg/i18n"
 	plugin "github.com/openshift-online/ocm-cli/pkg/plugin"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+	"github.com/openshift-online/ocm-cli/pkg/trace"
 	"github.com/openshift-online/ocm-cli/pkg/urls"
 )
 
 var root = &cobra.Command{
-	Use:           "ocm",
-	Long:          "Command line tool for api.openshift.com.",
-	SilenceUsage:  true,
-	SilenceErrors: true,
+	Use:               "ocm",
+	Long:              "Command line tool for api.openshift.com.",
+	SilenceUsage:      true,
+	SilenceErrors:     true,
+	PersistentPreRunE: preRun,
 }
 
 func init() {
@@ -79,33 +110,123 @@ func init() {
 	// Add the command line flags:
 	fs := root.PersistentFlags()
 	arguments.AddDebugFlag(fs)
+	arguments.AddContextFlag(fs)
+	arguments.AddCurlFlag(fs)
+	arguments.AddTraceFlag(fs)
+	arguments.AddNoCompressFlag(fs)
+	arguments.AddImpersonateFlags(fs)
+	arguments.AddCacheFlags(fs)
+	arguments.AddLangFlag(fs)
+	arguments.AddProductionFlag(fs)
+	arguments.AddUserAgentFlag(fs)
+
+	// Translate the help when it is requested, as the language may be selected with a flag that
+	// isn't parsed till then. The examples of the registry are added at the same time, so that
+	// they aren't loaded unless needed:
+	help := root.HelpFunc()
+	root.SetHelpFunc(func(cmd *cobra.Command, argv []string) {
+		registry, err := examples.Load()
+		if err == nil {
+			registry.Apply(root)
+		}
+		i18n.Localize(root)
+		help(cmd, argv)
+	})
 
 	// Register the subcommands:
+	root.AddCommand(accessrequest.Cmd)
 	root.AddCommand(account.Cmd)
+	root.AddCommand(annotate.Cmd)
+	root.AddCommand(api.Cmd)
+	root.AddCommand(cache.Cmd)
 	root.AddCommand(cluster.Cmd)
 	root.AddCommand(completion.Cmd)
 	root.AddCommand(config.Cmd)
+	root.AddCommand(contextcmd.Cmd)
 	root.AddCommand(create.Cmd)
 	root.AddCommand(delete.Cmd)
 	root.AddCommand(describe.Cmd)
 	root.AddCommand(edit.Cmd)
+	root.AddCommand(examplescmd.Cmd)
 	root.AddCommand(fail.Cmd)
+	root.AddCommand(fleet.Cmd)
+	root.AddCommand(generate.Cmd)
 	root.AddCommand(get.Cmd)
 	root.AddCommand(hibernate.Cmd)
+	root.AddCommand(importcmd.Cmd)
+	root.AddCommand(lint.Cmd)
 	root.AddCommand(list.Cmd)
 	root.AddCommand(login.Cmd)
 	root.AddCommand(logout.Cmd)
+	root.AddCommand(logs.Cmd)
 	root.AddCommand(patch.Cmd)
 	root.AddCommand(plugincmd.Cmd)
 	root.AddCommand(post.Cmd)
 	root.AddCommand(pop.Cmd)
 	root.AddCommand(push.Cmd)
+	root.AddCommand(releasenotes.Cmd)
 	root.AddCommand(resume.Cmd)
+	root.AddCommand(sandbox.Cmd)
+	root.AddCommand(search.Cmd)
 	root.AddCommand(success.Cmd)
 	root.AddCommand(token.Cmd)
+	root.AddCommand(top.Cmd)
 	root.AddCommand(tunnel.Cmd)
 	root.AddCommand(version.Cmd)
+	root.AddCommand(wait.Cmd)
 	root.AddCommand(whoami.Cmd)
+
+	// Add the '--dry-run=client' flag to the commands that don't have their own dry run mode.
+	// This needs to be done after registering all the subcommands:
+	dryrun.AddFlags(root)
+}
+
+// preRun runs the checks that apply to all the commands. When the command is only being
+// validated nothing is sent to the server, so the read-only mode doesn't apply and the hooks
+// aren't executed.
+func preRun(cmd *cobra.Command, argv []string) error {
+	err := checkCache(cmd)
+	if err != nil {
+		return err
+	}
+	if dryrun.Enabled() {
+		return nil
+	}
+	err = checkReadOnly(cmd, argv)
+	if err != nil {
+		return err
+	}
+	location, _ := ocmconfig.Location()
+	return hooks.Start(hooks.Location(location), cmd, argv)
+}
+
+// checkCache rejects the cache options for commands that change the server, as using responses
+// from the cache could make them act on outdated data.
+func checkCache(cmd *cobra.Command) error {
+	if pkgcache.Enabled() && readonly.Marked(cmd) {
+		return fmt.Errorf(
+			"Options '--cached' and '--refresh' can only be used with commands that " +
+				"don't change the server",
+		)
+	}
+	return nil
+}
+
+// checkReadOnly rejects the commands that change the server when the read-only mode is enabled,
+// before they do anything else, like asking questions to the user.
+func checkReadOnly(cmd *cobra.Command, argv []string) error {
+	if !readonly.Marked(cmd) {
+		return nil
+	}
+	cfg, err := ocmconfig.Load()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
+	enabled := readonly.EnvEnabled()
+	if cfg != nil {
+		enabled = cfg.ReadOnlyMode()
+	}
+	return readonly.Check(cmd, enabled)
 }
 
 func main() {
@@ -142,7 +263,13 @@ func main() {
 
 	// Execute the root command and exit inmediately if there was no error:
 	root.SetArgs(os.Args[1:])
-	err = root.Execute()
+	start := time.Now()
+	cmd, err := root.ExecuteC()
+	err = dryrun.Finish(os.Stdout, cmd, err)
+	hooks.Finish(err)
+	if trace.Enabled() {
+		trace.Summary(os.Stderr, time.Since(start))
+	}
 	if err == nil {
 		os.Exit(0)
 	}
@@ -150,6 +277,10 @@ func main() {
 	// Replace well known errors with user friendly messages:
 	message := err.Error()
 	switch {
+	case errors.Is(err, curl.ErrNotSent):
+		// The request was intentionally not sent, and the curl command has already been
+		// printed, so this isn't an error.
+		os.Exit(0)
 	case strings.Contains(message, "Offline user session not found"):
 		message = fmt.Sprintf(
 			"Offline access token is no longer valid. Go to %s to get a new one and "+
@@ -158,10 +289,24 @@ func main() {
 			urls.OfflineTokenPage,
 		)
 	default:
-		message = fmt.Sprintf("Error: %s", message)
+		message = i18n.Sprintf("Error: %s", i18n.Translate(message))
+		hint := hints.Find(err)
+		if hint != nil {
+			message = fmt.Sprintf("%s\n\n%s", message, hint)
+		}
 	}
 	fmt.Fprintf(os.Stderr, "%s\n", message)
 
-	// Exit signaling an error:
+	// Commands that write their output for GitHub Actions also report the error as an
+	// annotation, so that it is visible in the summary of the workflow run:
+	if ghactions.Enabled() {
+		ghactions.Error(os.Stdout, err.Error())
+	}
+
+	// Exit signaling an error, using the exit code requested by the command if there is one:
+	var bulkErr *bulk.Error
+	if errors.As(err, &bulkErr) {
+		os.Exit(bulkErr.Code)
+	}
 	os.Exit(1)
 }
@@ -25,7 +25,9 @@ import (
 	"github.com/openshift-online/ocm-cli/pkg/arguments"
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/hooks"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
 	"github.com/openshift-online/ocm-cli/pkg/urls"
 )
 
@@ -48,6 +50,7 @@ func init() {
 	arguments.AddParameterFlag(fs, &args.parameter)
 	arguments.AddHeaderFlag(fs, &args.header)
 	arguments.AddBodyFlag(fs, &args.body)
+	readonly.Mark(Cmd)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -68,7 +71,7 @@ func run(cmd *cobra.Command, argv []string) error {
 	err = arguments.ApplyPathArg(request, path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Can't parse path '%s': %v\n", path, err)
-		os.Exit(1)
+		hooks.Exit(1)
 	}
 	arguments.ApplyParameterFlag(request, args.parameter)
 	arguments.ApplyHeaderFlag(request, args.header)
@@ -80,7 +83,7 @@ func run(cmd *cobra.Command, argv []string) error {
 	// Send the request:
 	response, err := request.Send()
 	if err != nil {
-		return fmt.Errorf("Can't send request: %v", err)
+		return fmt.Errorf("Can't send request: %w", err)
 	}
 	status := response.Status()
 	body := response.Bytes()
@@ -109,7 +112,7 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	// Bye:
 	if status >= 400 {
-		os.Exit(1)
+		hooks.Exit(1)
 	}
 
 	return nil
@@ -23,7 +23,6 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
-	"sort"
 	"strings"
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
@@ -34,9 +33,16 @@ import (
 var Cmd = &cobra.Command{
 	Use:   "list",
 	Short: "List ocm plugins",
-	Long:  "List all the plugins under the user executable path",
-	Args:  cobra.NoArgs,
-	RunE:  run,
+	Long: "List all the plugins under the user executable path. Plugins are executables " +
+		"whose names start with 'ocm-', and they are called when the first arguments " +
+		"of the command line don't correspond to a built-in command. For example, " +
+		"'ocm my-tool' calls the 'ocm-my_tool' executable. Plugins receive the " +
+		"'OCM_CONFIG' and 'OCM_CONTEXT' environment variables, pointing to the " +
+		"configuration file and context in use.\n\n" +
+		"A warning is written for plugins that will never be called because a built-in " +
+		"command or another plugin that appears earlier in the path has the same name.",
+	Args: cobra.NoArgs,
+	RunE: run,
 }
 
 var args struct {
@@ -119,9 +125,46 @@ func run(cmd *cobra.Command, argv []string) error {
 		return err
 	}
 
+	// Warn about the plugins that will never be called:
+	seen := map[string]string{}
+	for _, plugin := range plugins {
+		first, shadowed := seen[plugin.Name]
+		if shadowed {
+			fmt.Fprintf(
+				os.Stderr,
+				"Warning: plugin '%s' in '%s' is shadowed by the plugin with the same "+
+					"name in '%s'\n",
+				plugin.Name, plugin.Path, first,
+			)
+			continue
+		}
+		seen[plugin.Name] = plugin.Path
+		command := pluginCommand(plugin.Name)
+		found, _, err := cmd.Root().Find(command)
+		if err == nil && found != cmd.Root() {
+			fmt.Fprintf(
+				os.Stderr,
+				"Warning: plugin '%s' in '%s' is overshadowed by the built-in '%s' "+
+					"command\n",
+				plugin.Name, plugin.Path, found.CommandPath(),
+			)
+		}
+	}
+
 	return nil
 }
 
+// pluginCommand returns the arguments that call the plugin with the given name, for example
+// 'my', 'tool' for 'ocm-my-tool'. Underscores in the name correspond to dashes in the
+// arguments.
+func pluginCommand(name string) []string {
+	result := strings.Split(strings.TrimPrefix(name, pluginPrefix), "-")
+	for i, arg := range result {
+		result[i] = strings.ReplaceAll(arg, "_", "-")
+	}
+	return result
+}
+
 // Plugin contains the description fo a Plugin.
 type Plugin struct {
 	Name string
@@ -188,24 +231,23 @@ func listPlugins(dir string) (result []Plugin, err error) {
 	return
 }
 
-// uniquePath remove the duplicate items from the PATH
+// uniquePath remove the duplicate items from the PATH, preserving the order, as it determines
+// which plugin is called when there are several with the same name.
 func uniquePath(path []string) []string {
-	keys := make(map[string]int)
+	keys := make(map[string]bool)
 	uniPath := make([]string, 0)
 
 	for _, p := range path {
 		if p == "" {
 			p = "."
 		}
-		keys[p] = 1
-	}
-
-	for element := range keys {
-		uniPath = append(uniPath, element)
+		if keys[p] {
+			continue
+		}
+		keys[p] = true
+		uniPath = append(uniPath, p)
 	}
 
-	sort.Strings(uniPath)
-
 	return uniPath
 }
 
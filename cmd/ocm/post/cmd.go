@@ -18,14 +18,19 @@ package post
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-cli/pkg/arguments"
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/hooks"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/openapi"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
 	"github.com/openshift-online/ocm-cli/pkg/urls"
 )
 
@@ -33,6 +38,7 @@ var args struct {
 	parameter []string
 	header    []string
 	body      string
+	validate  bool
 }
 
 var Cmd = &cobra.Command{
@@ -48,6 +54,14 @@ func init() {
 	arguments.AddParameterFlag(fs, &args.parameter)
 	arguments.AddHeaderFlag(fs, &args.header)
 	arguments.AddBodyFlag(fs, &args.body)
+	fs.BoolVar(
+		&args.validate,
+		"validate",
+		false,
+		"Validate the body against the OpenAPI specification published by the service "+
+			"before sending it, and don't send it if it isn't valid.",
+	)
+	readonly.Mark(Cmd)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -68,22 +82,29 @@ func run(cmd *cobra.Command, argv []string) error {
 	err = arguments.ApplyPathArg(request, path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Can't parse path '%s': %v\n", path, err)
-		os.Exit(1)
+		hooks.Exit(1)
 	}
 	arguments.ApplyParameterFlag(request, args.parameter)
 	arguments.ApplyHeaderFlag(request, args.header)
-	err = arguments.ApplyBodyFlag(request, args.body)
+	body, err := arguments.ReadBodyFlag(args.body)
 	if err != nil {
 		return fmt.Errorf("Can't read body: %v", err)
 	}
+	if args.validate {
+		err = validate(connection, path, body)
+		if err != nil {
+			return err
+		}
+	}
+	request.Bytes(body)
 
 	// Send the request:
 	response, err := request.Send()
 	if err != nil {
-		return fmt.Errorf("Can't send request: %v", err)
+		return fmt.Errorf("Can't send request: %w", err)
 	}
 	status := response.Status()
-	body := response.Bytes()
+	body = response.Bytes()
 	if status < 400 {
 		err = dump.Pretty(os.Stdout, body)
 	} else {
@@ -110,8 +131,50 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	// Bye:
 	if status >= 400 {
-		os.Exit(1)
+		hooks.Exit(1)
 	}
 
 	return nil
 }
+
+// validate checks the given body against the OpenAPI specification of the service that serves
+// the given path.
+func validate(connection *sdk.Connection, path string, body []byte) error {
+	specPath, err := openapi.SpecPath(path)
+	if err != nil {
+		return fmt.Errorf("Can't validate body: %v", err)
+	}
+	response, err := connection.Get().Path(specPath).Send()
+	if err != nil {
+		return fmt.Errorf("Can't retrieve OpenAPI specification: %w", err)
+	}
+	if response.Status() >= 400 {
+		return fmt.Errorf(
+			"Can't retrieve OpenAPI specification from '%s': status is %d",
+			specPath, response.Status(),
+		)
+	}
+	spec, err := openapi.Parse(response.Bytes())
+	if err != nil {
+		return err
+	}
+	schema, ok := spec.RequestSchema(http.MethodPost, path)
+	if !ok {
+		return fmt.Errorf(
+			"Can't validate body: the OpenAPI specification doesn't describe the body of "+
+				"POST requests for path '%s'",
+			path,
+		)
+	}
+	problems, err := spec.Validate(schema, body)
+	if err != nil {
+		return fmt.Errorf("Can't validate body: %v", err)
+	}
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "%s\n", problem)
+		}
+		return fmt.Errorf("Body doesn't match the OpenAPI specification")
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/openshift-online/ocm-cli/pkg/arguments"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
 	"github.com/openshift-online/ocm-sdk-go/jobqueue/v1"
 )
 
@@ -44,6 +45,7 @@ func init() {
 	// Add flags to rootCmd:
 	flags := Cmd.Flags()
 	arguments.AddParameterFlag(flags, &args.parameter)
+	readonly.Mark(Cmd)
 }
 
 func run(_ *cobra.Command, argv []string) error {
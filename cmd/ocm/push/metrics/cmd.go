@@ -0,0 +1,220 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/bulk"
+	"github.com/openshift-online/ocm-cli/pkg/curl"
+	"github.com/openshift-online/ocm-cli/pkg/disconnected"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+var args struct {
+	file    string
+	dryRun  bool
+	results string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "metrics --file=FILE",
+	Short: "Push the subscription data of disconnected clusters",
+	Long: "Register disconnected clusters and update the data of their subscriptions, like " +
+		"the number of nodes, sockets and CPUs and the OpenShift version, from an export " +
+		"file. Disconnected clusters don't report telemetry, so this data is needed for " +
+		"entitlement reporting.\n\n" +
+		"The export file is a YAML or JSON document containing a 'clusters' list. Each item " +
+		"contains the UUID of the cluster in the 'cluster_id' field and optionally the " +
+		"'display_name', 'openshift_version', 'nodes' (with 'master', 'infra' and " +
+		"'compute' counts), 'sockets', 'cpus' and 'system_units' ('Sockets' or " +
+		"'Cores/vCPU') fields. Fields that aren't present aren't changed.\n\n" +
+		"Clusters that don't have a subscription yet are registered as disconnected. " +
+		"Clusters whose subscription isn't disconnected are reported as failed, as their " +
+		"data is reported by telemetry.\n\n" +
+		"The command exits with code 0 when all the clusters succeeded, with code 2 " +
+		"when only some of them failed and with code 1 when all of them failed. Use " +
+		"the --results option to write the result of each cluster to a JSON file.",
+	Example: `  # Show what would be changed
+  ocm push metrics --file=clusters.yaml --dry-run
+
+  # Register and update the clusters
+  ocm push metrics --file=clusters.yaml`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVar(
+		&args.file,
+		"file",
+		"",
+		"Export file containing the data of the clusters (required).",
+	)
+	dryrun.BoolVar(
+		fs,
+		&args.dryRun,
+		"Show the clusters that would be registered or updated without changing them.",
+	)
+	fs.StringVar(
+		&args.results,
+		"results",
+		"",
+		"Write the result of each cluster to this file, in JSON format.",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("file")
+	readonly.Mark(Cmd)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Load the export file:
+	clusters, err := disconnected.Load(args.file)
+	if err != nil {
+		return err
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Push the data of each cluster:
+	results := bulk.NewResults()
+	for _, cluster := range clusters {
+		action, err := push(connection, cluster)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cluster.Name(), err)
+			results.Failed(cluster.ClusterID, cluster.Name(), action, err)
+			continue
+		}
+		results.Succeeded(cluster.ClusterID, cluster.Name(), action)
+	}
+
+	// Print the summary:
+	var registered, updated int
+	for _, item := range results.Items() {
+		if item.Status != bulk.StatusSucceeded {
+			continue
+		}
+		switch item.Action {
+		case actionRegister:
+			registered++
+		case actionUpdate:
+			updated++
+		}
+	}
+	verb := ""
+	if args.dryRun {
+		verb = "to be "
+	}
+	fmt.Printf(
+		"\nClusters: %d, %sregistered: %d, %supdated: %d, failed: %d\n",
+		len(clusters), verb, registered, verb, updated, results.Count(bulk.StatusFailed),
+	)
+	if args.results != "" {
+		err = results.Write(args.results)
+		if err != nil {
+			return err
+		}
+	}
+	return results.Err("Failed to push metrics")
+}
+
+// Actions recorded in the results:
+const (
+	actionRegister = "register"
+	actionUpdate   = "update"
+)
+
+// push registers the cluster if it doesn't have a subscription yet, and then updates the
+// subscription with the data from the export file. It returns the action that was applied.
+func push(connection *sdk.Connection, cluster *disconnected.Cluster) (action string, err error) {
+	collection := connection.AccountsMgmt().V1().Subscriptions()
+
+	// Find the subscription. The identifier has already been checked to be an UUID, so it is
+	// safe to use it in the search expression:
+	response, err := collection.List().
+		Search(fmt.Sprintf("external_cluster_id = '%s'", cluster.ClusterID)).
+		Size(1).
+		Send()
+	if err != nil {
+		err = fmt.Errorf("Can't retrieve subscription: %v", err)
+		return
+	}
+	var subscription *amv1.Subscription
+	if response.Items().Len() > 0 {
+		subscription = response.Items().Get(0)
+	}
+
+	// Register the cluster if needed:
+	action = actionUpdate
+	if subscription == nil {
+		action = actionRegister
+		fmt.Printf("%s: register cluster '%s'\n", cluster.Name(), cluster.ClusterID)
+		if args.dryRun {
+			return
+		}
+		registration, err := cluster.Registration().Build()
+		if err != nil {
+			return action, err
+		}
+		postResponse, err := collection.Post().Request(registration).Send()
+		if errors.Is(err, curl.ErrNotSent) {
+			return action, nil
+		}
+		if err != nil {
+			return action, fmt.Errorf("Can't register cluster: %v", err)
+		}
+		subscription = postResponse.Response()
+	} else if subscription.Status() != disconnected.StatusDisconnected {
+		err = fmt.Errorf(
+			"Subscription '%s' has status '%s', only disconnected clusters can be updated",
+			subscription.ID(), subscription.Status(),
+		)
+		return
+	} else {
+		fmt.Printf("%s: update subscription '%s'\n", cluster.Name(), subscription.ID())
+		if args.dryRun {
+			return
+		}
+	}
+
+	// Update the subscription:
+	body, err := cluster.Subscription().Build()
+	if err != nil {
+		return
+	}
+	_, err = collection.Subscription(subscription.ID()).Update().Body(body).Send()
+	if err != nil && !errors.Is(err, curl.ErrNotSent) {
+		err = fmt.Errorf("Can't update subscription '%s': %v", subscription.ID(), err)
+		return
+	}
+	err = nil
+	return
+}
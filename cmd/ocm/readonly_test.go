@@ -0,0 +1,157 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+// writeCommand describes a command that changes the server. When the flag is set the command
+// only changes the server when that flag is used.
+type writeCommand struct {
+	path string
+	flag string
+}
+
+// writeCommands are the commands that must be rejected when the read-only mode is enabled. New
+// commands that change the server should be added here.
+var writeCommands = []writeCommand{
+	{path: "ocm access-request approve"},
+	{path: "ocm access-request deny"},
+	{path: "ocm account org-defaults", flag: "set"},
+	{path: "ocm account org-defaults", flag: "unset"},
+	{path: "ocm account roles grant"},
+	{path: "ocm account roles revoke"},
+	{path: "ocm account sa create"},
+	{path: "ocm account sa delete"},
+	{path: "ocm account sa rotate-secret"},
+	{path: "ocm account users invitations cancel"},
+	{path: "ocm account users invite"},
+	{path: "ocm account users offboard"},
+	{path: "ocm annotate"},
+	{path: "ocm api delete"},
+	{path: "ocm api patch"},
+	{path: "ocm api post"},
+	{path: "ocm cluster copy-idps"},
+	{path: "ocm cluster cost-tags", flag: "apply"},
+	{path: "ocm cluster migrate-network"},
+	{path: "ocm cluster protect"},
+	{path: "ocm cluster pull-secret rotate"},
+	{path: "ocm cluster scale"},
+	{path: "ocm cluster schedule run"},
+	{path: "ocm cluster upgrade-policies pause"},
+	{path: "ocm cluster upgrade-policies resume"},
+	{path: "ocm cluster uuid-labels sync", flag: "fix"},
+	{path: "ocm create cluster"},
+	{path: "ocm create idp"},
+	{path: "ocm create ingress"},
+	{path: "ocm create machinepool"},
+	{path: "ocm create upgrade-policy"},
+	{path: "ocm create user"},
+	{path: "ocm delete"},
+	{path: "ocm delete idp"},
+	{path: "ocm delete ingress"},
+	{path: "ocm delete machinepool"},
+	{path: "ocm delete upgradepolicy"},
+	{path: "ocm delete user"},
+	{path: "ocm edit cluster"},
+	{path: "ocm edit ingress"},
+	{path: "ocm edit machinepool"},
+	{path: "ocm fail job"},
+	{path: "ocm fleet apply-idp"},
+	{path: "ocm hibernate cluster"},
+	{path: "ocm logs service post"},
+	{path: "ocm patch"},
+	{path: "ocm pop job"},
+	{path: "ocm post"},
+	{path: "ocm push job"},
+	{path: "ocm push metrics"},
+	{path: "ocm resume cluster"},
+	{path: "ocm success job"},
+}
+
+// findCommands returns a map containing all the commands of the tree, indexed by path.
+func findCommands(cmd *cobra.Command, result map[string]*cobra.Command) {
+	result[cmd.CommandPath()] = cmd
+	for _, child := range cmd.Commands() {
+		findCommands(child, result)
+	}
+}
+
+// markedWith checks if the command is marked when the given flag is used. The flag is restored
+// to its original value before returning, so that the tree is left as it was.
+func markedWith(cmd *cobra.Command, flag *pflag.Flag) bool {
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		value := slice.GetSlice()
+		defer func() {
+			Expect(slice.Replace(value)).To(Succeed())
+		}()
+	} else {
+		value := flag.Value.String()
+		defer func() {
+			Expect(flag.Value.Set(value)).To(Succeed())
+		}()
+	}
+	defer func() {
+		flag.Changed = false
+	}()
+	Expect(flag.Value.Set("true")).To(Succeed())
+	flag.Changed = true
+	return readonly.Marked(cmd)
+}
+
+var _ = Describe("Read only", func() {
+	var commands map[string]*cobra.Command
+
+	BeforeEach(func() {
+		commands = map[string]*cobra.Command{}
+		findCommands(root, commands)
+	})
+
+	It("Marks the commands that change the server", func() {
+		for _, item := range writeCommands {
+			cmd, ok := commands[item.path]
+			Expect(ok).To(BeTrue(), "Command '%s' doesn't exist", item.path)
+			if item.flag == "" {
+				Expect(readonly.Marked(cmd)).To(
+					BeTrue(),
+					"Command '%s' changes the server but it isn't marked", item.path,
+				)
+				continue
+			}
+			flag := cmd.Flags().Lookup(item.flag)
+			Expect(flag).ToNot(BeNil(), "Command '%s' doesn't have flag '%s'", item.path, item.flag)
+			Expect(readonly.Marked(cmd)).To(
+				BeFalse(),
+				"Command '%s' only changes the server with flag '%s' but it is always marked",
+				item.path, item.flag,
+			)
+			marked := markedWith(cmd, flag)
+			Expect(marked).To(
+				BeTrue(),
+				"Command '%s' changes the server with flag '%s' but it isn't marked",
+				item.path, item.flag,
+			)
+		}
+	})
+})
@@ -0,0 +1,193 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releasenotes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"text/tabwriter"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+var args struct {
+	from         string
+	to           string
+	channelGroup string
+	json         bool
+}
+
+var Cmd = &cobra.Command{
+	Use:   "release-notes --from=VERSION --to=VERSION",
+	Short: "Show the release notes of the versions between two versions",
+	Long: "Show the links to the release notes of all the OpenShift versions that are newer " +
+		"than the '--from' version and not newer than the '--to' version, using the versions " +
+		"known by the API. It also reports if there is a direct upgrade path between the two " +
+		"versions. This is intended to help review the changes included in an upgrade.",
+	Example: `  # Show the release notes of the versions included in an upgrade
+  ocm release-notes --from 4.14.10 --to 4.14.20
+
+  # Same, in JSON format
+  ocm release-notes --from 4.14.10 --to 4.14.20 --json`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVar(
+		&args.from,
+		"from",
+		"",
+		"Version that is currently installed (required).",
+	)
+	fs.StringVar(
+		&args.to,
+		"to",
+		"",
+		"Version that will be installed (required).",
+	)
+	fs.StringVar(
+		&args.channelGroup,
+		"channel-group",
+		"stable",
+		"Channel group of the versions.",
+	)
+	fs.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the report in JSON format",
+	)
+	//nolint:gosec
+	Cmd.MarkFlagRequired("from")
+	//nolint:gosec
+	Cmd.MarkFlagRequired("to")
+}
+
+// Report contains the release notes of the versions between two versions.
+type Report struct {
+	From          string                 `json:"from"`
+	To            string                 `json:"to"`
+	ChannelGroup  string                 `json:"channel_group"`
+	DirectUpgrade bool                   `json:"direct_upgrade"`
+	Versions      []*cluster.ReleaseNote `json:"versions"`
+}
+
+// channelGroupRE is the regular expression used to check the channel group before using it in
+// the search expression.
+var channelGroupRE = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	if !channelGroupRE.MatchString(args.channelGroup) {
+		return fmt.Errorf("Channel group '%s' isn't valid", args.channelGroup)
+	}
+	from := cluster.DropOpenshiftVPrefix(args.from)
+	to := cluster.DropOpenshiftVPrefix(args.to)
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Retrieve all the versions of the channel group, including the ones that are no longer
+	// enabled, as they may still be installed:
+	var versions []*cmv1.Version
+	size := 100
+	index := 1
+	for {
+		response, err := connection.ClustersMgmt().V1().Versions().List().
+			Search(fmt.Sprintf("channel_group = '%s'", args.channelGroup)).
+			Size(size).
+			Page(index).
+			Send()
+		if err != nil {
+			return fmt.Errorf("Can't retrieve versions: %v", err)
+		}
+		versions = append(versions, response.Items().Slice()...)
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+
+	// Calculate the report:
+	notes, err := cluster.ReleaseNotesBetween(versions, from, to)
+	if err != nil {
+		return err
+	}
+	report := &Report{
+		From:         from,
+		To:           to,
+		ChannelGroup: args.channelGroup,
+		Versions:     notes,
+	}
+	for _, version := range versions {
+		if cluster.VersionRawID(version) != from {
+			continue
+		}
+		for _, upgrade := range version.AvailableUpgrades() {
+			if cluster.DropOpenshiftVPrefix(upgrade) == to {
+				report.DirectUpgrade = true
+				break
+			}
+		}
+	}
+
+	// Write the report:
+	if args.json {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("Can't marshal report: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	directUpgrade := "no"
+	if report.DirectUpgrade {
+		directUpgrade = "yes"
+	}
+	summary := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(summary, "From:\t%s\n", report.From)
+	fmt.Fprintf(summary, "To:\t%s\n", report.To)
+	fmt.Fprintf(summary, "Channel group:\t%s\n", report.ChannelGroup)
+	fmt.Fprintf(summary, "Direct upgrade:\t%s\n", directUpgrade)
+	err = summary.Flush()
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "VERSION\tRELEASE NOTES\n")
+	for _, note := range report.Versions {
+		url := note.URL
+		if url == "" {
+			url = "NONE"
+		}
+		fmt.Fprintf(table, "%s\t%s\n", note.Version, url)
+	}
+	return table.Flush()
+}
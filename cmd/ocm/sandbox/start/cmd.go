@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package start
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/sandbox"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
+)
+
+var args struct {
+	address string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the sandbox server",
+	Long: "Start a local server that implements a subset of the OCM API, seeded with " +
+		"organizations, users and clusters. Changes are kept in memory and discarded when " +
+		"the server stops. The server accepts any credentials, and runs till it is " +
+		"interrupted.",
+	Example: `  # Start the sandbox server:
+  ocm sandbox start
+
+  # In other terminal, log in to it and use it:
+  ocm login --url sandbox
+  ocm list clusters`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.address,
+		"address",
+		urls.SandboxAddress,
+		"Address where the server listens. Note that the 'sandbox' alias of the "+
+			"'--url' option of the 'login' command only works with the default address.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	handler, err := sandbox.NewServer()
+	if err != nil {
+		return fmt.Errorf("Can't create sandbox server: %v", err)
+	}
+	listener, err := net.Listen("tcp", args.address)
+	if err != nil {
+		return fmt.Errorf("Can't listen on '%s': %v", args.address, err)
+	}
+	server := &http.Server{
+		Handler: handler,
+	}
+
+	// Stop the server gracefully when interrupted:
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		<-interrupts
+		_ = server.Shutdown(context.Background())
+	}()
+
+	address := "http://" + listener.Addr().String()
+	if address == urls.SandboxURL {
+		fmt.Fprintf(
+			os.Stderr,
+			"Sandbox server listening on '%s', log in to it with "+
+				"'ocm login --url sandbox'\n",
+			address,
+		)
+	} else {
+		fmt.Fprintf(
+			os.Stderr,
+			"Sandbox server listening on '%s', log in to it with "+
+				"'ocm login --url %s --token-url %s%s --client-id %s "+
+				"--client-secret %s'\n",
+			address, address, address, urls.SandboxTokenPath,
+			urls.SandboxClientID, urls.SandboxClientSecret,
+		)
+	}
+	err = server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	return err
+}
@@ -0,0 +1,313 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/openshift-online/ocm-sdk-go/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/arguments"
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/search"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
+)
+
+var args struct {
+	output  string
+	columns string
+	order   string
+}
+
+// category describes one of the kinds of objects that can be searched.
+type category struct {
+	// Name of the category, which is also the alias of the collection in the urls package.
+	name string
+
+	// Other names that can be used for the category.
+	aliases []string
+
+	// Name of the table that describes the columns.
+	table string
+
+	// Columns displayed when the user doesn't select others.
+	columns string
+}
+
+// categories are the kinds of objects that can be searched, in the order they are described in
+// the help.
+var categories = []*category{
+	{
+		name:    "clusters",
+		aliases: []string{"cluster"},
+		table:   "clusters",
+		columns: "id, name, openshift_version, cloud_provider.id, region.id, state",
+	},
+	{
+		name:    "subscriptions",
+		aliases: []string{"subscription", "subs", "sub"},
+		table:   "subscriptions",
+		columns: "id, cluster_id, display_name, plan.id, status, organization_id",
+	},
+	{
+		name:    "accounts",
+		aliases: []string{"account", "accts", "acct"},
+		table:   "accounts",
+		columns: "id, username, email, organization.id",
+	},
+	{
+		name:    "organizations",
+		aliases: []string{"organization", "orgs", "org"},
+		table:   "orgs",
+		columns: "id, external_id, name",
+	},
+}
+
+var Cmd = &cobra.Command{
+	Use:   "search CATEGORY QUERY",
+	Short: "Search clusters, subscriptions, accounts or organizations",
+	Long: fmt.Sprintf(
+		"Search the objects of a category using the search syntax of the API, and display "+
+			"the results with the columns that are most useful for that category. The "+
+			"categories are '%s'.",
+		strings.Join(categoryNames(), "', '"),
+	),
+	Example: `  # Search the clusters that are ready in AWS
+  ocm search clusters "cloud_provider.id = 'aws' and state = 'ready'"
+
+  # Search the subscriptions of an organization, as JSON
+  ocm search subscriptions "organization_id = '123'" --output json
+
+  # Search accounts by email, displaying only some columns
+  ocm search accounts "email like '%@example.com'" --columns id,email`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeCategory,
+	RunE:              run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	arguments.AddOutputFlag(flags, &args.output)
+	flags.StringVar(
+		&args.columns,
+		"columns",
+		"",
+		"Comma separated list of columns to display, using the names of the fields of the "+
+			"objects, for example 'id,name,region.id'. By default the columns depend on "+
+			"the category.",
+	)
+	flags.StringVar(
+		&args.order,
+		"order",
+		"",
+		"Order criteria, for example \"created_at desc\". By default the order is decided "+
+			"by the server.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Create a context:
+	ctx := context.Background()
+
+	// Check the arguments:
+	selected := findCategory(argv[0])
+	if selected == nil {
+		return fmt.Errorf(
+			"Unknown category '%s', valid values are '%s'",
+			argv[0], strings.Join(categoryNames(), "', '"),
+		)
+	}
+	query := argv[1]
+	err := search.Lint(query)
+	if err != nil {
+		return fmt.Errorf("Invalid search expression: %v", err)
+	}
+	format, err := output.ParseFormat(args.output)
+	if err != nil {
+		return err
+	}
+	path, err := urls.Expand([]string{selected.name})
+	if err != nil {
+		return err
+	}
+
+	// Load the configuration file:
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
+	if cfg == nil {
+		return fmt.Errorf("Not logged in, run the 'login' command")
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Config(cfg).Build()
+	if err != nil {
+		return fmt.Errorf("Can't create connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Create the list:
+	columns := selected.columns
+	if args.columns != "" {
+		columns = args.columns
+	}
+	printer, err := output.NewPrinter().
+		Writer(os.Stdout).
+		Pager(cfg.Pager).
+		Build(ctx)
+	if err != nil {
+		return err
+	}
+	defer printer.Close()
+	list, err := printer.NewList().
+		Name(selected.table).
+		Format(format).
+		Columns(columns).
+		Build(ctx)
+	if err != nil {
+		return err
+	}
+	names := strings.Split(columns, ",")
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+	}
+
+	// Send the requests till we receive a page with less items than requested:
+	size := 100
+	page := 1
+	for {
+		request := connection.Get().
+			Path(path).
+			Parameter("search", query).
+			Parameter("page", page).
+			Parameter("size", size)
+		if args.order != "" {
+			request.Parameter("order", args.order)
+		}
+		response, err := request.SendContext(ctx)
+		if err != nil {
+			return fmt.Errorf("Can't search %s: %v", selected.name, err)
+		}
+		if response.Status() >= http.StatusBadRequest {
+			apiErr, err := errors.UnmarshalErrorStatus(response.Bytes(), response.Status())
+			if err != nil {
+				return fmt.Errorf("Can't search %s: status is %d", selected.name, response.Status())
+			}
+			return fmt.Errorf("Can't search %s: %v", selected.name, apiErr)
+		}
+		items, err := parseItems(response.Bytes())
+		if err != nil {
+			return fmt.Errorf("Can't parse %s: %v", selected.name, err)
+		}
+
+		// Write the items of the page:
+		for _, item := range items {
+			row := map[string]interface{}{}
+			for _, name := range names {
+				row[name] = lookup(item, name)
+			}
+			err = list.Write(row)
+			if err != nil {
+				return err
+			}
+		}
+
+		// If the number of fetched items is less than requested, then this was the last
+		// page, otherwise process the next one:
+		if len(items) < size {
+			break
+		}
+		page++
+	}
+
+	return list.Close()
+}
+
+// findCategory returns the category that has the given name or alias, or nil if there is no
+// such category.
+func findCategory(name string) *category {
+	for _, candidate := range categories {
+		if candidate.name == name {
+			return candidate
+		}
+		for _, alias := range candidate.aliases {
+			if alias == name {
+				return candidate
+			}
+		}
+	}
+	return nil
+}
+
+// categoryNames returns the names of the categories, without the aliases.
+func categoryNames() []string {
+	result := make([]string, len(categories))
+	for i, category := range categories {
+		result[i] = category.name
+	}
+	return result
+}
+
+// completeCategory completes the first argument with the names of the categories. The query
+// can't be completed.
+func completeCategory(cmd *cobra.Command, argv []string,
+	toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(argv) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return categoryNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// parseItems returns the items of a page of a collection. Numbers are kept as they are returned
+// by the server, so that large identifiers aren't converted to floating point.
+func parseItems(body []byte) (result []map[string]interface{}, err error) {
+	var page struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	err = decoder.Decode(&page)
+	if err != nil {
+		return
+	}
+	result = page.Items
+	return
+}
+
+// lookup returns the value of the given field of the object, following the dots of the name into
+// nested objects. It returns nil if the field doesn't exist.
+func lookup(object map[string]interface{}, name string) interface{} {
+	var value interface{} = object
+	for _, part := range strings.Split(name, ".") {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = nested[part]
+	}
+	return value
+}
@@ -31,19 +31,40 @@ import (
 )
 
 var args struct {
-	header    bool
-	payload   bool
-	signature bool
-	refresh   bool
-	generate  bool
+	header      bool
+	payload     bool
+	signature   bool
+	refresh     bool
+	generate    bool
+	scopes      []string
+	audience    string
+	ephemeral   bool
+	maxLifetime time.Duration
 }
 
 var Cmd = &cobra.Command{
 	Use:   "token",
 	Short: "Generates a token",
-	Long:  "Uses the stored credentials to generate a token.",
-	Args:  cobra.NoArgs,
-	RunE:  run,
+	Long: "Uses the stored credentials to generate a token. With the '--scope' or " +
+		"'--audience' options a new access token with those scopes or for that audience " +
+		"is requested from the SSO server, so that tools that need such tokens don't have to " +
+		"implement the authentication themselves. Those tokens aren't saved in the " +
+		"configuration file.\n\n" +
+		"With the '--ephemeral' option a new short lived access token is requested and " +
+		"printed, and the configuration file isn't updated at all. Use it in scripts " +
+		"where the token may end up in logs. The lifetime of the token is decided by the " +
+		"SSO server and can't be requested: the '--max-lifetime' option only checks it, " +
+		"and if the new token is valid for longer the command fails without printing it.",
+	Example: `  # Print an access token with an additional scope
+  ocm token --scope openid --scope api.iam.service_accounts
+
+  # Print an access token for another audience
+  ocm token --audience my-backplane
+
+  # Print a new access token, failing if it is valid for more than fifteen minutes
+  ocm token --ephemeral --max-lifetime 15m`,
+	Args: cobra.NoArgs,
+	RunE: run,
 }
 
 func init() {
@@ -78,6 +99,34 @@ func init() {
 		false,
 		"Generate a new token.",
 	)
+	flags.StringSliceVar(
+		&args.scopes,
+		"scope",
+		nil,
+		"Request a new access token with this scope. Can be used multiple times to request "+
+			"multiple scopes. Note that the default 'openid' scope isn't added automatically.",
+	)
+	flags.StringVar(
+		&args.audience,
+		"audience",
+		"",
+		"Request a new access token for this audience, exchanging the current access token.",
+	)
+	flags.BoolVar(
+		&args.ephemeral,
+		"ephemeral",
+		false,
+		"Request a new short lived access token and print only that token, without "+
+			"saving anything in the configuration file.",
+	)
+	flags.DurationVar(
+		&args.maxLifetime,
+		"max-lifetime",
+		15*time.Minute,
+		"Maximum lifetime accepted for the token requested with '--ephemeral'. The lifetime "+
+			"is decided by the SSO server, this only checks it: if the new token is valid "+
+			"for longer the command fails without printing it.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -99,6 +148,19 @@ func run(cmd *cobra.Command, argv []string) error {
 	if count > 1 {
 		return fmt.Errorf("Options '--payload', '--header', '--signature', and '--generate' are mutually exclusive")
 	}
+	mint := len(args.scopes) > 0 || args.audience != "" || args.ephemeral
+	if mint && (args.refresh || args.generate) {
+		return fmt.Errorf(
+			"Options '--scope', '--audience' and '--ephemeral' can't be used with '--refresh' " +
+				"or '--generate'",
+		)
+	}
+	if cmd.Flags().Changed("max-lifetime") && !args.ephemeral {
+		return fmt.Errorf("Option '--max-lifetime' can only be used with '--ephemeral'")
+	}
+	if args.maxLifetime <= 0 {
+		return fmt.Errorf("Option '--max-lifetime' must be greater than zero")
+	}
 
 	// Create the client for the OCM API:
 	connection, err := ocm.NewConnection().Build()
@@ -130,6 +192,39 @@ func run(cmd *cobra.Command, argv []string) error {
 		selectedToken = refreshToken
 	}
 
+	// Load the configuration file:
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
+
+	// Request the token with the scopes or audience given by the user:
+	if mint {
+		selectedToken, err = cfg.MintToken(accessToken, refreshToken, args.scopes, args.audience)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check that the ephemeral token doesn't live longer than accepted:
+	if args.ephemeral {
+		expires, left, err := config.TokenLeft(selectedToken)
+		if err != nil {
+			return fmt.Errorf("Can't check expiration of new token: %v", err)
+		}
+		if !expires || left > args.maxLifetime {
+			lifetime := "doesn't expire"
+			if expires {
+				lifetime = fmt.Sprintf("is valid for %s", left.Round(time.Second))
+			}
+			return fmt.Errorf(
+				"The new token %s, which is longer than the maximum of %s given with "+
+					"the '--max-lifetime' option, so it hasn't been printed",
+				lifetime, args.maxLifetime,
+			)
+		}
+	}
+
 	// Parse the token:
 	parser := new(jwt.Parser)
 	_, parts, err := parser.ParseUnverified(selectedToken, jwt.MapClaims{})
@@ -170,10 +265,10 @@ func run(cmd *cobra.Command, argv []string) error {
 		fmt.Fprintf(os.Stdout, "%s\n", selectedToken)
 	}
 
-	// Load the configuration file:
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("Can't load config file: %v", err)
+	// Ephemeral tokens are used where the configuration may not be protected, so nothing is
+	// saved, not even the tokens that were refreshed:
+	if args.ephemeral {
+		return nil
 	}
 
 	// Save the configuration:
@@ -0,0 +1,250 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusters
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/search"
+)
+
+var args struct {
+	sortBy    string
+	limit     int
+	ascending bool
+	search    string
+	json      bool
+}
+
+// Keys that can be used to sort the clusters:
+const (
+	sortCPU    = "cpu"
+	sortMemory = "memory"
+	sortNodes  = "nodes"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "clusters",
+	Short: "Rank clusters by resource consumption",
+	Long: "Rank the active clusters by the CPU or memory used, or by the number of nodes, " +
+		"as reported by the metrics of their subscriptions. Clusters that don't report " +
+		"metrics aren't included.",
+	Example: `  # Show the ten clusters that use more CPU
+  ocm top clusters
+
+  # Show the twenty clusters that use less memory, for example to find candidates for
+  # consolidation
+  ocm top clusters --sort-by=memory --ascending --limit=20`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVar(
+		&args.sortBy,
+		"sort-by",
+		sortCPU,
+		fmt.Sprintf(
+			"Value used to rank the clusters, one of '%s', '%s' or '%s'.",
+			sortCPU, sortMemory, sortNodes,
+		),
+	)
+	fs.IntVar(
+		&args.limit,
+		"limit",
+		10,
+		"Maximum number of clusters to show. Use zero to show all the clusters.",
+	)
+	fs.BoolVar(
+		&args.ascending,
+		"ascending",
+		false,
+		"Show first the clusters that use less resources.",
+	)
+	fs.StringVar(
+		&args.search,
+		"search",
+		"",
+		"Search expression used to select the subscriptions of the clusters, for example "+
+			"\"display_name like 'prod-%'\".",
+	)
+	fs.BoolVar(
+		&args.json,
+		"json",
+		false,
+		"Output the ranking in JSON format.",
+	)
+}
+
+// Usage contains the resource consumption of a cluster. Note that the field names are part of the
+// JSON output, so don't change them without considering the consumers of that output.
+type Usage struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	CPUUsed     float64 `json:"cpu_used"`
+	CPUTotal    float64 `json:"cpu_total"`
+	MemoryUsed  float64 `json:"memory_used"`
+	MemoryTotal float64 `json:"memory_total"`
+	Nodes       float64 `json:"nodes"`
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	switch args.sortBy {
+	case sortCPU, sortMemory, sortNodes:
+	default:
+		return fmt.Errorf(
+			"Unknown sort key '%s', valid values are '%s', '%s' and '%s'",
+			args.sortBy, sortCPU, sortMemory, sortNodes,
+		)
+	}
+	if args.limit < 0 {
+		return fmt.Errorf("Option '--limit' must not be negative")
+	}
+	query := "status = 'Active'"
+	if args.search != "" {
+		err := search.Lint(args.search)
+		if err != nil {
+			return fmt.Errorf("Invalid search expression: %v", err)
+		}
+		query = fmt.Sprintf("%s and (%s)", query, args.search)
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Retrieve the metrics of all the active subscriptions:
+	usages := []*Usage{}
+	size := 100
+	index := 1
+	for {
+		response, err := connection.AccountsMgmt().V1().Subscriptions().List().
+			Search(query).
+			Size(size).
+			Page(index).
+			Send()
+		if err != nil {
+			return fmt.Errorf("Can't retrieve subscriptions: %v", err)
+		}
+		response.Items().Each(func(item *amv1.Subscription) bool {
+			usage := subscriptionUsage(item)
+			if usage != nil {
+				usages = append(usages, usage)
+			}
+			return true
+		})
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+
+	// Rank the clusters. Ties are sorted by name so that the result is stable:
+	key := func(usage *Usage) float64 {
+		switch args.sortBy {
+		case sortMemory:
+			return usage.MemoryUsed
+		case sortNodes:
+			return usage.Nodes
+		default:
+			return usage.CPUUsed
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		left, right := key(usages[i]), key(usages[j])
+		if left != right {
+			if args.ascending {
+				return left < right
+			}
+			return left > right
+		}
+		return usages[i].Name < usages[j].Name
+	})
+	if args.limit > 0 && len(usages) > args.limit {
+		usages = usages[:args.limit]
+	}
+
+	// Write the result:
+	if args.json {
+		data, err := json.Marshal(usages)
+		if err != nil {
+			return fmt.Errorf("Can't marshal ranking: %v", err)
+		}
+		return dump.Pretty(os.Stdout, data)
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "ID\tNAME\tCPU\tMEMORY (GiB)\tNODES\n")
+	for _, usage := range usages {
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%.0f\n",
+			usage.ID, usage.Name,
+			formatUsage(usage.CPUUsed, usage.CPUTotal, 1),
+			formatUsage(usage.MemoryUsed, usage.MemoryTotal, 1<<30),
+			usage.Nodes,
+		)
+	}
+	return writer.Flush()
+}
+
+// subscriptionUsage extracts the resource consumption from the metrics of the subscription. It
+// returns nil if the subscription doesn't have metrics.
+func subscriptionUsage(item *amv1.Subscription) *Usage {
+	metrics := item.Metrics()
+	if len(metrics) == 0 || metrics[0].Empty() {
+		return nil
+	}
+	name := item.DisplayName()
+	if name == "" {
+		name = item.ExternalClusterID()
+	}
+	return &Usage{
+		ID:          item.ClusterID(),
+		Name:        name,
+		CPUUsed:     metrics[0].Cpu().Used().Value(),
+		CPUTotal:    metrics[0].Cpu().Total().Value(),
+		MemoryUsed:  metrics[0].Memory().Used().Value(),
+		MemoryTotal: metrics[0].Memory().Total().Value(),
+		Nodes:       metrics[0].Nodes().Total(),
+	}
+}
+
+// formatUsage formats the used and total values of a resource, including the percentage used. The
+// values are divided by the given unit.
+func formatUsage(used, total, unit float64) string {
+	buffer := &strings.Builder{}
+	fmt.Fprintf(buffer, "%.2f/%.2f", used/unit, total/unit)
+	if total > 0 {
+		fmt.Fprintf(buffer, " (%.0f%%)", 100*used/total)
+	}
+	return buffer.String()
+}
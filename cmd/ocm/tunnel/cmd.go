@@ -22,12 +22,11 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
-	"regexp"
 	"strings"
 
 	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/hooks"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
-	clustersmgmtv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/spf13/cobra"
 )
 
@@ -68,7 +67,7 @@ func run(cmd *cobra.Command, argv []string) error {
 			"Expected exactly one cluster name, identifier or external identifier "+
 				"is required\n",
 		)
-		os.Exit(1)
+		hooks.Exit(1)
 	}
 
 	clusterKey := argv[0]
@@ -100,10 +99,11 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	fmt.Printf("Will create tunnel to cluster:\n Name: %s\n ID: %s\n", cluster.Name(), cluster.ID())
 
-	sshURL, err := generateSSHURI(cluster)
+	sshHost, err := c.SSHHost(cluster)
 	if err != nil {
 		return err
 	}
+	sshURL := c.SSHUser + "@" + sshHost
 
 	sshuttleArgs := []string{
 		"--remote", sshURL,
@@ -145,20 +145,6 @@ func run(cmd *cobra.Command, argv []string) error {
 	return nil
 }
 
-func generateSSHURI(cluster *clustersmgmtv1.Cluster) (string, error) {
-	r := regexp.MustCompile(`(?mi)^https:\/\/api\.(.*):6443`)
-	apiURL := cluster.API().URL()
-	if len(apiURL) == 0 {
-		return "", fmt.Errorf("cannot find the api URL for cluster: %s", cluster.Name())
-	}
-	base := r.FindStringSubmatch(apiURL)[1]
-	if len(base) == 0 {
-		return "", fmt.Errorf("unable to match api URL for cluster: %s", cluster.Name())
-	}
-
-	return "sre-user@rh-ssh." + base, nil
-}
-
 func resolveURL(rawurl string) ([]string, error) {
 	u, err := url.Parse(rawurl)
 	if err != nil {
@@ -0,0 +1,300 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wait
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/itchyny/gojq"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/ghactions"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/poll"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
+)
+
+var args struct {
+	condition string
+	timeout   time.Duration
+	interval  time.Duration
+}
+
+var Cmd = &cobra.Command{
+	Use:   "wait --for=CONDITION REFERENCE...",
+	Short: "Wait for a condition on one or more objects",
+	Long: "Wait till the given condition is satisfied for all the objects. The objects are " +
+		"referenced as 'KIND/ID', for example 'cluster/123' or 'subscription/456', or as " +
+		"'KIND/CLUSTER_ID/ID' for objects that belong to a cluster, for example " +
+		"'machinepool/123/worker'. The condition can be one of the following:\n\n" +
+		"  deleted              The object doesn't exist.\n" +
+		"  condition=STATE      The 'state' of the object, or its 'status' if it doesn't " +
+		"have a state, is STATE.\n" +
+		"  jq=EXPRESSION        The jq expression applied to the object is true.\n\n" +
+		"When waiting for a state the command fails immediately if the object is in the " +
+		"'error' or 'failed' state.",
+	Example: `  # Wait till a cluster is ready
+  ocm wait --for=condition=ready cluster/123 --timeout=45m
+
+  # Wait till a machine pool is deleted
+  ocm wait --for=deleted machinepool/123/worker
+
+  # Wait till the 'my-addon' add-on is installed
+  ocm wait --for='jq=.state == "ready"' addon/123/my-addon`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: run,
+}
+
+func init() {
+	fs := Cmd.Flags()
+	fs.StringVar(
+		&args.condition,
+		"for",
+		"",
+		"Condition to wait for, 'deleted', 'condition=STATE' or 'jq=EXPRESSION'.",
+	)
+	fs.DurationVar(
+		&args.timeout,
+		"timeout",
+		30*time.Minute,
+		"Maximum time to wait, for example '45m' or '2h'.",
+	)
+	fs.DurationVar(
+		&args.interval,
+		"interval",
+		30*time.Second,
+		"Time to wait between checks.",
+	)
+	ghactions.AddFlag(fs)
+	Cmd.MarkFlagRequired("for")
+}
+
+// Prefixes of the conditions:
+const (
+	conditionPrefix = "condition="
+	jqPrefix        = "jq="
+)
+
+// failedStates are the states that will never change to the state that is being waited for.
+var failedStates = []string{
+	"error",
+	"failed",
+}
+
+// condition is the condition that the command waits for.
+type condition struct {
+	deleted bool
+	state   string
+	filter  *gojq.Code
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	// Check the options:
+	cond, err := parseCondition(args.condition)
+	if err != nil {
+		return err
+	}
+	if args.timeout <= 0 {
+		return fmt.Errorf("Option '--timeout' must be positive")
+	}
+	if args.interval <= 0 {
+		return fmt.Errorf("Option '--interval' must be positive")
+	}
+	err = ghactions.CheckFlag()
+	if err != nil {
+		return err
+	}
+	paths := map[string]string{}
+	for _, reference := range argv {
+		paths[reference], err = urls.ExpandReference(reference)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the client for the OCM API:
+	connection, err := ocm.NewConnection().Build()
+	if err != nil {
+		return fmt.Errorf("Failed to create OCM connection: %v", err)
+	}
+	defer connection.Close()
+
+	// Check all the objects in each iteration, and stop checking the ones that satisfy the
+	// condition, so that the command finishes as soon as the last one does:
+	ctx, cancel := context.WithTimeout(context.Background(), args.timeout)
+	defer cancel()
+	pending := argv
+	err = poll.Until(ctx, args.interval, func(ctx context.Context) (bool, error) {
+		var remaining []string
+		for _, reference := range pending {
+			done, body, err := check(ctx, connection, reference, paths[reference], cond)
+			if err != nil {
+				return false, err
+			}
+			if done {
+				fmt.Printf("%s condition met\n", reference)
+				if ghactions.Enabled() {
+					err = report(reference, body, len(argv) == 1)
+					if err != nil {
+						return false, err
+					}
+				}
+			} else {
+				remaining = append(remaining, reference)
+			}
+		}
+		pending = remaining
+		return len(pending) == 0, nil
+	})
+	if poll.IsTimeout(err) {
+		return fmt.Errorf(
+			"Timed out after %s waiting for '%s' on %s",
+			args.timeout, args.condition, strings.Join(pending, ", "),
+		)
+	}
+	return err
+}
+
+func parseCondition(text string) (result *condition, err error) {
+	switch {
+	case text == "deleted":
+		result = &condition{
+			deleted: true,
+		}
+	case strings.HasPrefix(text, conditionPrefix) && len(text) > len(conditionPrefix):
+		result = &condition{
+			state: strings.TrimPrefix(text, conditionPrefix),
+		}
+	case strings.HasPrefix(text, jqPrefix):
+		var filter *gojq.Code
+		filter, err = dump.ParseJQ(strings.TrimPrefix(text, jqPrefix))
+		if err != nil {
+			err = fmt.Errorf("Invalid jq expression in condition '%s': %v", text, err)
+			return
+		}
+		result = &condition{
+			filter: filter,
+		}
+	default:
+		err = fmt.Errorf(
+			"Invalid condition '%s', valid conditions are 'deleted', 'condition=STATE' "+
+				"and 'jq=EXPRESSION'",
+			text,
+		)
+	}
+	return
+}
+
+// check retrieves the object and checks if it satisfies the condition. It also returns the body
+// of the object, if it exists.
+func check(ctx context.Context, connection *sdk.Connection, reference, path string,
+	cond *condition) (done bool, body []byte, err error) {
+	response, err := connection.Get().Path(path).SendContext(ctx)
+	if err != nil {
+		err = fmt.Errorf("Can't retrieve '%s': %v", reference, err)
+		return
+	}
+	status := response.Status()
+	if status == http.StatusNotFound {
+		if !cond.deleted {
+			err = fmt.Errorf("Object '%s' doesn't exist", reference)
+		}
+		done = cond.deleted
+		return
+	}
+	if status >= http.StatusBadRequest {
+		err = fmt.Errorf(
+			"Can't retrieve '%s': server returned status %d", reference, status,
+		)
+		return
+	}
+	body = response.Bytes()
+	switch {
+	case cond.deleted:
+		done = false
+	case cond.filter != nil:
+		done, err = dump.JQTest(body, cond.filter)
+		if err != nil {
+			err = fmt.Errorf("Can't evaluate condition for '%s': %v", reference, err)
+		}
+	default:
+		state := objectState(body)
+		if strings.EqualFold(state, cond.state) {
+			done = true
+			return
+		}
+		for _, failed := range failedStates {
+			if strings.EqualFold(state, failed) {
+				err = fmt.Errorf("Object '%s' is in state '%s'", reference, state)
+				return
+			}
+		}
+	}
+	return
+}
+
+// report writes the GitHub Actions annotation for an object that satisfies the condition. When
+// the object is the only one and it is a cluster it also sets the step outputs with the details
+// of the cluster.
+func report(reference string, body []byte, single bool) error {
+	ghactions.Notice(os.Stdout, fmt.Sprintf("Condition '%s' met for '%s'", args.condition, reference))
+	if !single || body == nil || !strings.HasPrefix(reference, "cluster/") {
+		return nil
+	}
+	cluster, err := cmv1.UnmarshalCluster(body)
+	if err != nil {
+		return fmt.Errorf("Can't parse cluster '%s': %v", reference, err)
+	}
+	return ghactions.SetOutputs(map[string]string{
+		"cluster_id":   cluster.ID(),
+		"cluster_name": cluster.Name(),
+		"api_url":      cluster.API().URL(),
+		"console_url":  cluster.Console().URL(),
+		"state":        string(cluster.State()),
+	})
+}
+
+// objectState returns the value of the 'state' attribute of the object, or the value of the
+// 'status' attribute if it doesn't have a state, as is the case for subscriptions.
+func objectState(body []byte) string {
+	var object struct {
+		State  string          `json:"state"`
+		Status json.RawMessage `json:"status"`
+	}
+	err := json.Unmarshal(body, &object)
+	if err != nil {
+		return ""
+	}
+	if object.State != "" {
+		return object.State
+	}
+	var status string
+	err = json.Unmarshal(object.Status, &status)
+	if err != nil {
+		return ""
+	}
+	return status
+}
@@ -20,31 +20,72 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
 	amsv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/spf13/cobra"
 
+	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
 	"github.com/openshift-online/ocm-cli/pkg/ocm"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
 )
 
+var args struct {
+	quotaSummary    bool
+	clustersSummary bool
+}
+
 var Cmd = &cobra.Command{
 	Use:   "whoami",
 	Short: "Prints user information",
-	Long:  "Prints user information.",
-	Args:  cobra.NoArgs,
-	RunE:  run,
+	Long: "Prints user information. With the '--quota-summary' or '--clusters-summary' " +
+		"options it prints instead a short summary of the user, the organization and the " +
+		"environment, together with the remaining quota or the number of visible clusters.",
+	Example: `  # Print who you are, where you are pointed at and what you can see
+  ocm whoami --quota-summary --clusters-summary`,
+	Args: cobra.NoArgs,
+	RunE: run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.BoolVar(
+		&args.quotaSummary,
+		"quota-summary",
+		false,
+		"Print a summary that includes the quota of the organization that is still available.",
+	)
+	flags.BoolVar(
+		&args.clustersSummary,
+		"clusters-summary",
+		false,
+		"Print a summary that includes the number of clusters visible to the user, per state.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
+	// Load the configuration file:
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
 
 	// Create the client for the OCM API:
-	connection, err := ocm.NewConnection().Build()
+	connection, err := ocm.NewConnection().Config(cfg).Build()
 	if err != nil {
 		return fmt.Errorf("Failed to create OCM connection: %v", err)
 	}
 	defer connection.Close()
 
+	if args.quotaSummary || args.clustersSummary {
+		return summary(connection, cfg)
+	}
+
 	// Send the request:
 	response, err := connection.AccountsMgmt().V1().CurrentAccount().Get().
 		Send()
@@ -72,3 +113,128 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	return nil
 }
+
+// summary prints the summary of the user, the environment and, depending on the options, the
+// clusters and the quota.
+func summary(connection *sdk.Connection, cfg *config.Config) error {
+	response, err := connection.AccountsMgmt().V1().CurrentAccount().Get().
+		Send()
+	if err != nil {
+		return fmt.Errorf("Can't retrieve current user information: %v", err)
+	}
+	account := response.Body()
+	org := account.Organization()
+
+	url := cfg.URL
+	if url == "" {
+		url = sdk.DefaultURL
+	}
+	if env := urls.Environment(url); env != "" {
+		url = fmt.Sprintf("%s (%s)", url, env)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "User:\t%s\n", account.Username())
+	name := strings.TrimSpace(account.FirstName() + " " + account.LastName())
+	if name != "" {
+		fmt.Fprintf(writer, "Name:\t%s\n", name)
+	}
+	if account.Email() != "" {
+		fmt.Fprintf(writer, "Email:\t%s\n", account.Email())
+	}
+	fmt.Fprintf(writer, "Organization:\t%s (%s)\n", org.Name(), org.ID())
+	fmt.Fprintf(writer, "API URL:\t%s\n", url)
+
+	if args.clustersSummary {
+		total, states, err := countClusters(connection)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(writer, "Clusters:\t%d%s\n", total, formatStates(states))
+	}
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	if args.quotaSummary {
+		quotas, err := connection.AccountsMgmt().V1().Organizations().Organization(org.ID()).
+			QuotaCost().
+			List().
+			Send()
+		if err != nil {
+			return fmt.Errorf("Can't retrieve quota: %v", err)
+		}
+		fmt.Printf("Quota:\n")
+		writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "  QUOTA ID\tCONSUMED\tALLOWED\tREMAINING\n")
+		quotas.Items().Each(func(quota *amsv1.QuotaCost) bool {
+			if quota.Allowed() == 0 && quota.Consumed() == 0 {
+				return true
+			}
+			fmt.Fprintf(
+				writer,
+				"  %s\t%d\t%d\t%d\n",
+				quota.QuotaID(), quota.Consumed(), quota.Allowed(),
+				quota.Allowed()-quota.Consumed(),
+			)
+			return true
+		})
+		err = writer.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// countClusters returns the number of clusters visible to the user and how many of them are in
+// each state.
+func countClusters(connection *sdk.Connection) (total int, states map[string]int, err error) {
+	states = map[string]int{}
+	size := 100
+	index := 1
+	for {
+		var response *cmv1.ClustersListResponse
+		response, err = connection.ClustersMgmt().V1().Clusters().List().
+			Size(size).
+			Page(index).
+			Send()
+		if err != nil {
+			err = fmt.Errorf("Can't retrieve clusters: %v", err)
+			return
+		}
+		response.Items().Each(func(cluster *cmv1.Cluster) bool {
+			total++
+			states[string(cluster.State())]++
+			return true
+		})
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+	return
+}
+
+// formatStates returns a text like ' (10 ready, 2 installing)' describing the number of clusters in
+// each state, sorted by state name.
+func formatStates(states map[string]int) string {
+	if len(states) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(states))
+	for name := range states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		if name == "" {
+			name = "unknown"
+		}
+		parts[i] = fmt.Sprintf("%d %s", states[names[i]], name)
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(parts, ", "))
+}
@@ -0,0 +1,155 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to work with the access requests of the access transparency
+// service. The SDK doesn't support that service yet, so these functions use raw requests.
+
+package accessrequest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	"github.com/openshift-online/ocm-sdk-go/errors"
+)
+
+// States of access requests:
+const (
+	StatePending  = "Pending"
+	StateApproved = "Approved"
+	StateDenied   = "Denied"
+	StateExpired  = "Expired"
+)
+
+// Decisions that can be made about an access request:
+const (
+	DecisionApproved = "Approved"
+	DecisionDenied   = "Denied"
+)
+
+const collectionPath = "/api/access_transparency/v1/access_requests"
+
+// AccessRequest is a request from an SRE to access a cluster.
+type AccessRequest struct {
+	ID             string     `json:"id,omitempty"`
+	HREF           string     `json:"href,omitempty"`
+	ClusterID      string     `json:"cluster_id,omitempty"`
+	SubscriptionID string     `json:"subscription_id,omitempty"`
+	OrganizationID string     `json:"organization_id,omitempty"`
+	RequestedBy    string     `json:"requested_by,omitempty"`
+	Justification  string     `json:"justification,omitempty"`
+	Duration       string     `json:"duration,omitempty"`
+	Deadline       *time.Time `json:"deadline,omitempty"`
+	Status         *Status    `json:"status,omitempty"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+}
+
+// Status is the status of an access request.
+type Status struct {
+	State string `json:"state,omitempty"`
+}
+
+// State returns the state of the access request, or an empty string if it doesn't have one.
+func (r *AccessRequest) State() string {
+	if r.Status == nil {
+		return ""
+	}
+	return r.Status.State
+}
+
+// decision is the body of the request used to approve or deny an access request.
+type decision struct {
+	Decision      string `json:"decision"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// page is used to decode the pages of the list of access requests.
+type page struct {
+	Size  int              `json:"size"`
+	Items []*AccessRequest `json:"items"`
+}
+
+// List retrieves all the access requests that match the given search expression. If the search
+// expression is empty all the access requests visible to the user are returned.
+func List(connection *sdk.Connection, search string) ([]*AccessRequest, error) {
+	var result []*AccessRequest
+	size := 100
+	index := 1
+	for {
+		request := connection.Get().
+			Path(collectionPath).
+			Parameter("size", size).
+			Parameter("page", index)
+		if search != "" {
+			request.Parameter("search", search)
+		}
+		body, err := send(request)
+		if err != nil {
+			return nil, fmt.Errorf("Can't retrieve access requests: %v", err)
+		}
+		list := &page{}
+		err = json.Unmarshal(body, list)
+		if err != nil {
+			return nil, fmt.Errorf("Can't parse access requests: %v", err)
+		}
+		result = append(result, list.Items...)
+		if list.Size < size {
+			break
+		}
+		index++
+	}
+	return result, nil
+}
+
+// Decide records the approval or denial of the access request with the given identifier.
+func Decide(connection *sdk.Connection, id, value, justification string) error {
+	data, err := json.Marshal(&decision{
+		Decision:      value,
+		Justification: justification,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = send(
+		connection.Post().
+			Path(fmt.Sprintf("%s/%s/decisions", collectionPath, id)).
+			Bytes(data),
+	)
+	if err != nil {
+		return fmt.Errorf("Can't record decision for access request '%s': %w", id, err)
+	}
+	return nil
+}
+
+// send sends the given request and returns the body of the response, or an error if the server
+// responded with an error status.
+func send(request *sdk.Request) ([]byte, error) {
+	response, err := request.Send()
+	if err != nil {
+		return nil, err
+	}
+	if response.Status() >= http.StatusBadRequest {
+		apiErr, err := errors.UnmarshalErrorStatus(response.Bytes(), response.Status())
+		if err != nil {
+			return nil, fmt.Errorf("status is %d", response.Status())
+		}
+		return nil, apiErr
+	}
+	return response.Bytes(), nil
+}
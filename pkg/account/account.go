@@ -19,6 +19,7 @@ package account
 import (
 	"bytes"
 	"fmt"
+	"net/http"
 
 	"github.com/openshift-online/ocm-sdk-go"
 	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
@@ -27,8 +28,18 @@ import (
 // GetRolesFromUsers gets all roles a specific user possesses.
 func GetRolesFromUsers(accounts []*amv1.Account,
 	conn *sdk.Connection) (results map[*amv1.Account][]string, error error) {
+	return GetRolesFromUsersThrottled(accounts, conn, nil)
+}
+
+// GetRolesFromUsersThrottled is like GetRolesFromUsers, but it uses the given throttle to pause
+// and try again when the server is rate limiting the requests.
+func GetRolesFromUsersThrottled(accounts []*amv1.Account, conn *sdk.Connection,
+	throttle *Throttle) (results map[*amv1.Account][]string, err error) {
 	// Prepare the results:
 	results = map[*amv1.Account][]string{}
+	if len(accounts) == 0 {
+		return
+	}
 
 	// Prepare a map of accounts indexed by identifier:
 	accountsMap := map[string]*amv1.Account{}
@@ -51,13 +62,17 @@ func GetRolesFromUsers(accounts []*amv1.Account,
 	size := 100
 
 	for {
-		// Prepare the request:
-		response, err := conn.AccountsMgmt().V1().RoleBindings().List().
-			Size(size).
-			Page(index).
-			Parameter("search", query).
-			Send()
-
+		// Send the request:
+		var response *amv1.RoleBindingsListResponse
+		err := throttle.Send(func() (int, http.Header, error) {
+			var err error
+			response, err = conn.AccountsMgmt().V1().RoleBindings().List().
+				Size(size).
+				Page(index).
+				Parameter("search", query).
+				Send()
+			return response.Status(), response.Header(), err
+		})
 		if err != nil {
 			return nil, fmt.Errorf("Can't retrieve roles: %v", err)
 		}
@@ -99,3 +114,35 @@ func stringInList(strArr []string, key string) bool {
 	}
 	return false
 }
+
+// OrganizationCache retrieves the names of organizations, remembering them so that each
+// organization is retrieved from the server only once.
+type OrganizationCache struct {
+	conn  *sdk.Connection
+	names map[string]string
+}
+
+// NewOrganizationCache creates a new empty cache that uses the given connection to retrieve the
+// organizations.
+func NewOrganizationCache(conn *sdk.Connection) *OrganizationCache {
+	return &OrganizationCache{
+		conn:  conn,
+		names: map[string]string{},
+	}
+}
+
+// Name returns the name of the organization with the given identifier.
+func (c *OrganizationCache) Name(id string) (name string, err error) {
+	name, ok := c.names[id]
+	if ok {
+		return
+	}
+	response, err := c.conn.AccountsMgmt().V1().Organizations().Organization(id).Get().Send()
+	if err != nil {
+		err = fmt.Errorf("Can't retrieve organization '%s': %v", id, err)
+		return
+	}
+	name = response.Body().Name()
+	c.names[id] = name
+	return
+}
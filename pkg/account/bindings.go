@@ -0,0 +1,329 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package account
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/bulk"
+	"github.com/openshift-online/ocm-cli/pkg/curl"
+)
+
+// RoleChange is a role that should be granted to, or revoked from, a user.
+type RoleChange struct {
+	Username string
+	Role     string
+}
+
+// ReadRoleChanges reads role changes from CSV data. Each record contains the user name and,
+// optionally, the role. The role can be omitted only when a default role is given, and then it is
+// used for all the records. A first record with the 'username' and 'role' column names is ignored.
+func ReadRoleChanges(reader io.Reader, role string) (result []*RoleChange, err error) {
+	parser := csv.NewReader(reader)
+	parser.FieldsPerRecord = -1
+	parser.TrimLeadingSpace = true
+	parser.Comment = '#'
+	for line := 1; ; line++ {
+		var record []string
+		record, err = parser.Read()
+		if errors.Is(err, io.EOF) {
+			err = nil
+			return
+		}
+		if err != nil {
+			return
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "username") {
+			continue
+		}
+		change := &RoleChange{
+			Username: strings.TrimSpace(record[0]),
+			Role:     role,
+		}
+		if len(record) > 1 && strings.TrimSpace(record[1]) != "" {
+			change.Role = strings.TrimSpace(record[1])
+		}
+		if len(record) > 2 {
+			err = fmt.Errorf("Line %d has %d columns, expected at most 2", line, len(record))
+			return
+		}
+		if change.Username == "" {
+			err = fmt.Errorf("Line %d doesn't have a user name", line)
+			return
+		}
+		if change.Role == "" {
+			err = fmt.Errorf("Line %d doesn't have a role", line)
+			return
+		}
+		result = append(result, change)
+	}
+}
+
+// RoleChanger grants and revokes organization roles. Don't create instances of this type directly;
+// use the NewRoleChanger function instead.
+type RoleChanger struct {
+	conn      *sdk.Connection
+	throttle  *Throttle
+	org       string
+	dryRun    bool
+	batchSize int
+	out       io.Writer
+	errOut    io.Writer
+}
+
+// NewRoleChanger creates a new role changer that uses the given connection and throttle. If the
+// organization isn't empty only the users of that organization are considered. In dry run mode the
+// changes are only reported. The messages describing the changes are written to the out writer,
+// and the errors to the errOut writer.
+func NewRoleChanger(conn *sdk.Connection, throttle *Throttle, org string, dryRun bool,
+	out, errOut io.Writer) *RoleChanger {
+	return &RoleChanger{
+		conn:      conn,
+		throttle:  throttle,
+		org:       org,
+		dryRun:    dryRun,
+		batchSize: 100,
+		out:       out,
+		errOut:    errOut,
+	}
+}
+
+// Grant creates the organization role bindings that don't exist yet, recording the result for each
+// change. It only returns an error when the users or their role bindings can't be retrieved.
+func (c *RoleChanger) Grant(changes []*RoleChange, results *bulk.Results) error {
+	return c.apply(changes, results, true)
+}
+
+// Revoke deletes the organization role bindings that exist, recording the result for each change.
+// It only returns an error when the users or their role bindings can't be retrieved.
+func (c *RoleChanger) Revoke(changes []*RoleChange, results *bulk.Results) error {
+	return c.apply(changes, results, false)
+}
+
+func (c *RoleChanger) apply(changes []*RoleChange, results *bulk.Results, grant bool) error {
+	action := "revoke"
+	if grant {
+		action = "grant"
+	}
+
+	// The users and their role bindings are retrieved in batches, to reduce the number of
+	// requests:
+	for start := 0; start < len(changes); start += c.batchSize {
+		end := start + c.batchSize
+		if end > len(changes) {
+			end = len(changes)
+		}
+		batch := changes[start:end]
+		usernames := make([]string, len(batch))
+		for i, change := range batch {
+			usernames[i] = change.Username
+		}
+		accounts, err := c.findAccounts(usernames)
+		if err != nil {
+			return err
+		}
+		ids := make([]string, 0, len(accounts))
+		seen := map[string]bool{}
+		for _, username := range usernames {
+			account := accounts[username]
+			if account != nil && !seen[account.ID()] {
+				ids = append(ids, account.ID())
+				seen[account.ID()] = true
+			}
+		}
+		bindings, err := c.findBindings(ids)
+		if err != nil {
+			return err
+		}
+
+		for _, change := range batch {
+			account := accounts[change.Username]
+			if account == nil {
+				err = fmt.Errorf("user '%s' doesn't exist", change.Username)
+				fmt.Fprintf(c.errOut, "%s: %v\n", change.Username, err)
+				results.Failed(change.Username, "", action+" "+change.Role, err)
+				continue
+			}
+			var existing []*amv1.RoleBinding
+			for _, binding := range bindings[account.ID()] {
+				if binding.Role().ID() == change.Role {
+					existing = append(existing, binding)
+				}
+			}
+			if grant {
+				err = c.grant(change, account, existing, results)
+			} else {
+				err = c.revoke(change, existing, results)
+			}
+			if err != nil {
+				fmt.Fprintf(c.errOut, "%s: %v\n", change.Username, err)
+				results.Failed(change.Username, "", action+" "+change.Role, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *RoleChanger) grant(change *RoleChange, account *amv1.Account,
+	existing []*amv1.RoleBinding, results *bulk.Results) error {
+	if len(existing) > 0 {
+		fmt.Fprintf(c.out, "%s: already has role '%s'\n", change.Username, change.Role)
+		results.Skipped(change.Username, "", "grant "+change.Role)
+		return nil
+	}
+	fmt.Fprintf(c.out, "%s: grant role '%s'\n", change.Username, change.Role)
+	if !c.dryRun {
+		binding, err := amv1.NewRoleBinding().
+			Type("Organization").
+			Account(amv1.NewAccount().ID(account.ID())).
+			AccountID(account.ID()).
+			Role(amv1.NewRole().ID(change.Role)).
+			RoleID(change.Role).
+			Organization(amv1.NewOrganization().ID(account.Organization().ID())).
+			OrganizationID(account.Organization().ID()).
+			Build()
+		if err != nil {
+			return err
+		}
+		err = c.throttle.Send(func() (int, http.Header, error) {
+			response, err := c.conn.AccountsMgmt().V1().RoleBindings().Add().
+				Body(binding).
+				Send()
+			return response.Status(), response.Header(), err
+		})
+		if err != nil && !errors.Is(err, curl.ErrNotSent) {
+			return err
+		}
+	}
+	results.Succeeded(change.Username, "", "grant "+change.Role)
+	return nil
+}
+
+func (c *RoleChanger) revoke(change *RoleChange, existing []*amv1.RoleBinding,
+	results *bulk.Results) error {
+	if len(existing) == 0 {
+		fmt.Fprintf(c.out, "%s: doesn't have role '%s'\n", change.Username, change.Role)
+		results.Skipped(change.Username, "", "revoke "+change.Role)
+		return nil
+	}
+	fmt.Fprintf(c.out, "%s: revoke role '%s'\n", change.Username, change.Role)
+	if !c.dryRun {
+		for _, binding := range existing {
+			err := c.throttle.Send(func() (int, http.Header, error) {
+				response, err := c.conn.AccountsMgmt().V1().RoleBindings().
+					RoleBinding(binding.ID()).
+					Delete().
+					Send()
+				return response.Status(), response.Header(), err
+			})
+			if err != nil && !errors.Is(err, curl.ErrNotSent) {
+				return err
+			}
+		}
+	}
+	results.Succeeded(change.Username, "", "revoke "+change.Role)
+	return nil
+}
+
+// findAccounts retrieves the accounts with the given user names, indexed by user name.
+func (c *RoleChanger) findAccounts(usernames []string) (result map[string]*amv1.Account,
+	err error) {
+	query := fmt.Sprintf("username in (%s)", quote(usernames))
+	if c.org != "" {
+		query = fmt.Sprintf("%s and organization_id='%s'", query, c.org)
+	}
+	result = map[string]*amv1.Account{}
+	for page := 1; ; page++ {
+		var response *amv1.AccountsListResponse
+		err = c.throttle.Send(func() (int, http.Header, error) {
+			var err error
+			response, err = c.conn.AccountsMgmt().V1().Accounts().List().
+				Search(query).
+				Size(c.batchSize).
+				Page(page).
+				Send()
+			return response.Status(), response.Header(), err
+		})
+		if err != nil {
+			err = fmt.Errorf("Can't retrieve accounts: %v", err)
+			return
+		}
+		response.Items().Each(func(account *amv1.Account) bool {
+			result[account.Username()] = account
+			return true
+		})
+		if response.Size() < c.batchSize {
+			return
+		}
+	}
+}
+
+// findBindings retrieves the organization role bindings of the given accounts, indexed by account
+// identifier.
+func (c *RoleChanger) findBindings(ids []string) (result map[string][]*amv1.RoleBinding,
+	err error) {
+	result = map[string][]*amv1.RoleBinding{}
+	if len(ids) == 0 {
+		return
+	}
+	query := fmt.Sprintf("account_id in (%s) and type='Organization'", quote(ids))
+	if c.org != "" {
+		query = fmt.Sprintf("%s and organization_id='%s'", query, c.org)
+	}
+	for page := 1; ; page++ {
+		var response *amv1.RoleBindingsListResponse
+		err = c.throttle.Send(func() (int, http.Header, error) {
+			var err error
+			response, err = c.conn.AccountsMgmt().V1().RoleBindings().List().
+				Parameter("search", query).
+				Size(c.batchSize).
+				Page(page).
+				Send()
+			return response.Status(), response.Header(), err
+		})
+		if err != nil {
+			err = fmt.Errorf("Can't retrieve roles: %v", err)
+			return
+		}
+		response.Items().Each(func(binding *amv1.RoleBinding) bool {
+			id := binding.Account().ID()
+			result[id] = append(result[id], binding)
+			return true
+		})
+		if response.Size() < c.batchSize {
+			return
+		}
+	}
+}
+
+// quote returns the given values quoted and separated by commas, to use them in an 'in' search
+// expression.
+func quote(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,187 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package account
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+)
+
+// ClusterDefaultsPrefix is the prefix of the organization labels that contain the default
+// cluster settings used by the 'create cluster --use-org-defaults' command.
+const ClusterDefaultsPrefix = "cluster_defaults."
+
+// clusterDefaultsLabels is the prefix of the organization labels that contain the labels that
+// will be added to the default machine pool of new clusters.
+const clusterDefaultsLabels = ClusterDefaultsPrefix + "labels."
+
+// ClusterDefaultsOptions are the names of the 'create cluster' options that can be part of the
+// organization defaults.
+var ClusterDefaultsOptions = []string{
+	"channel-group",
+	"compute-machine-type",
+	"host-prefix",
+	"machine-cidr",
+	"network-type",
+	"pod-cidr",
+	"service-cidr",
+}
+
+// ClusterDefaults contains the default cluster settings of an organization.
+type ClusterDefaults struct {
+	// Options contains the values of the 'create cluster' options, indexed by option name.
+	Options map[string]string
+
+	// Labels contains the labels that will be added to the default machine pool.
+	Labels map[string]string
+}
+
+// ClusterDefaultsLabelKey returns the organization label key that stores the given default. The
+// name can be one of the options in ClusterDefaultsOptions or 'label.NAME' for a machine pool
+// label.
+func ClusterDefaultsLabelKey(name string) (key string, err error) {
+	if strings.HasPrefix(name, "label.") {
+		label := strings.TrimPrefix(name, "label.")
+		if label == "" {
+			err = fmt.Errorf("Label name is required in '%s'", name)
+			return
+		}
+		key = clusterDefaultsLabels + label
+		return
+	}
+	for _, option := range ClusterDefaultsOptions {
+		if option == name {
+			key = ClusterDefaultsPrefix + strings.ReplaceAll(option, "-", "_")
+			return
+		}
+	}
+	err = fmt.Errorf(
+		"Unknown default '%s', valid values are '%s' and 'label.NAME'",
+		name, strings.Join(ClusterDefaultsOptions, "', '"),
+	)
+	return
+}
+
+// CurrentOrganization returns the identifier of the organization of the current user.
+func CurrentOrganization(conn *sdk.Connection) (id string, err error) {
+	response, err := conn.AccountsMgmt().V1().CurrentAccount().Get().Send()
+	if err != nil {
+		err = fmt.Errorf("Can't retrieve current user information: %v", err)
+		return
+	}
+	id = response.Body().Organization().ID()
+	return
+}
+
+// GetClusterDefaults retrieves the default cluster settings stored in the labels of the given
+// organization.
+func GetClusterDefaults(conn *sdk.Connection, orgID string) (result *ClusterDefaults, err error) {
+	result = &ClusterDefaults{
+		Options: map[string]string{},
+		Labels:  map[string]string{},
+	}
+	request := conn.AccountsMgmt().V1().Organizations().Organization(orgID).Labels().List()
+	size := 100
+	index := 1
+	for {
+		response, err := request.Size(size).Page(index).Send()
+		if err != nil {
+			return nil, fmt.Errorf("Can't retrieve labels of organization '%s': %v", orgID, err)
+		}
+		response.Items().Each(func(label *amv1.Label) bool {
+			key := label.Key()
+			switch {
+			case strings.HasPrefix(key, clusterDefaultsLabels):
+				result.Labels[strings.TrimPrefix(key, clusterDefaultsLabels)] = label.Value()
+			case strings.HasPrefix(key, ClusterDefaultsPrefix):
+				option := strings.TrimPrefix(key, ClusterDefaultsPrefix)
+				option = strings.ReplaceAll(option, "_", "-")
+				result.Options[option] = label.Value()
+			}
+			return true
+		})
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+	return
+}
+
+// Names returns the names of the defaults, sorted alphabetically, using the same format accepted
+// by ClusterDefaultsLabelKey.
+func (d *ClusterDefaults) Names() []string {
+	names := make([]string, 0, len(d.Options)+len(d.Labels))
+	for option := range d.Options {
+		names = append(names, option)
+	}
+	for label := range d.Labels {
+		names = append(names, "label."+label)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Value returns the value of the default with the given name.
+func (d *ClusterDefaults) Value(name string) string {
+	if strings.HasPrefix(name, "label.") {
+		return d.Labels[strings.TrimPrefix(name, "label.")]
+	}
+	return d.Options[name]
+}
+
+// SetClusterDefault creates or updates the organization label that stores the given default.
+func SetClusterDefault(conn *sdk.Connection, orgID, name, value string) error {
+	key, err := ClusterDefaultsLabelKey(name)
+	if err != nil {
+		return err
+	}
+	body, err := amv1.NewLabel().Key(key).Value(value).Build()
+	if err != nil {
+		return err
+	}
+	labels := conn.AccountsMgmt().V1().Organizations().Organization(orgID).Labels()
+	getResponse, err := labels.Labels(key).Get().Send()
+	if getResponse != nil && getResponse.Status() == http.StatusNotFound {
+		_, err = labels.Add().Body(body).Send()
+	} else if err == nil {
+		_, err = labels.Labels(key).Update().Body(body).Send()
+	}
+	if err != nil {
+		return fmt.Errorf("Can't set default '%s' of organization '%s': %w", name, orgID, err)
+	}
+	return nil
+}
+
+// DeleteClusterDefault removes the organization label that stores the given default.
+func DeleteClusterDefault(conn *sdk.Connection, orgID, name string) error {
+	key, err := ClusterDefaultsLabelKey(name)
+	if err != nil {
+		return err
+	}
+	_, err = conn.AccountsMgmt().V1().Organizations().Organization(orgID).Labels().
+		Labels(key).Delete().Send()
+	if err != nil {
+		return fmt.Errorf("Can't remove default '%s' of organization '%s': %w", name, orgID, err)
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package account
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+)
+
+// InvitationLabel is the key of the account label that marks the accounts created by the
+// 'account users invite' command. The value is the status of the invitation.
+const InvitationLabel = "invitation"
+
+// Statuses of invitations. The accepted status isn't stored in the label: an invitation is
+// accepted when the user registers with Red Hat SSO, which sets the RHIT account identifier of
+// the account.
+const (
+	InvitationPending   = "pending"
+	InvitationAccepted  = "accepted"
+	InvitationCancelled = "cancelled"
+)
+
+// emailRE is used to check email addresses. It is deliberately simple, the server does the
+// complete validation, but it makes sure that the address can be used in search queries.
+var emailRE = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+// ValidEmail checks if the given text looks like an email address.
+func ValidEmail(email string) bool {
+	return emailRE.MatchString(email)
+}
+
+// Invitation is an account created by the 'account users invite' command.
+type Invitation struct {
+	Account *amv1.Account
+	Status  string
+	Roles   []string
+}
+
+// InvitationStatus returns the status of the invitation of the given account, or the empty
+// string if the account wasn't invited. The account must have been retrieved with its labels.
+func InvitationStatus(account *amv1.Account) string {
+	for _, label := range account.Labels() {
+		if label.Key() != InvitationLabel {
+			continue
+		}
+		switch {
+		case label.Value() == InvitationCancelled:
+			return InvitationCancelled
+		case account.RhitAccountID() != "":
+			return InvitationAccepted
+		default:
+			return InvitationPending
+		}
+	}
+	return ""
+}
+
+// FindAccountByEmail retrieves the account of the given organization that has the given email
+// address, including its labels. It returns nil if there is no such account.
+func FindAccountByEmail(conn *sdk.Connection, orgID, email string) (*amv1.Account, error) {
+	if !ValidEmail(email) {
+		return nil, fmt.Errorf("Email address '%s' isn't valid", email)
+	}
+	response, err := conn.AccountsMgmt().V1().Accounts().List().
+		Search(fmt.Sprintf("email = '%s' and organization_id = '%s'", email, orgID)).
+		Parameter("fetchLabels", true).
+		Size(1).
+		Send()
+	if err != nil {
+		return nil, fmt.Errorf("Can't retrieve user with email '%s': %v", email, err)
+	}
+	if response.Items().Len() == 0 {
+		return nil, nil
+	}
+	return response.Items().Get(0), nil
+}
+
+// Invite creates the account of a new member of the organization, grants it the given roles and
+// marks it as a pending invitation. If the account exists because of a cancelled invitation it
+// is reused.
+func Invite(conn *sdk.Connection, orgID, email, username string,
+	roles []string) (account *amv1.Account, err error) {
+	account, err = FindAccountByEmail(conn, orgID, email)
+	if err != nil {
+		return
+	}
+	resource := conn.AccountsMgmt().V1().Accounts()
+	if account != nil {
+		switch InvitationStatus(account) {
+		case InvitationCancelled:
+			err = setInvitationStatus(conn, account.ID(), InvitationPending, true)
+			if err != nil {
+				return
+			}
+		case InvitationPending:
+			err = fmt.Errorf(
+				"User with email '%s' has already been invited to organization '%s'",
+				email, orgID,
+			)
+			return
+		default:
+			err = fmt.Errorf(
+				"User with email '%s' is already a member of organization '%s'",
+				email, orgID,
+			)
+			return
+		}
+	} else {
+		var body *amv1.Account
+		body, err = amv1.NewAccount().
+			Email(email).
+			Username(username).
+			Organization(amv1.NewOrganization().ID(orgID)).
+			Build()
+		if err != nil {
+			return
+		}
+		var response *amv1.AccountsAddResponse
+		response, err = resource.Add().Body(body).Send()
+		if err != nil {
+			err = fmt.Errorf("Can't create account for '%s': %w", email, err)
+			return
+		}
+		account = response.Body()
+		err = setInvitationStatus(conn, account.ID(), InvitationPending, false)
+		if err != nil {
+			return
+		}
+	}
+	for _, role := range roles {
+		var binding *amv1.RoleBinding
+		binding, err = amv1.NewRoleBinding().
+			Type("Organization").
+			Account(amv1.NewAccount().ID(account.ID())).
+			AccountID(account.ID()).
+			Role(amv1.NewRole().ID(role)).
+			RoleID(role).
+			Organization(amv1.NewOrganization().ID(orgID)).
+			OrganizationID(orgID).
+			Build()
+		if err != nil {
+			return
+		}
+		_, err = conn.AccountsMgmt().V1().RoleBindings().Add().Body(binding).Send()
+		if err != nil {
+			err = fmt.Errorf("Can't grant role '%s' to '%s': %w", role, email, err)
+			return
+		}
+	}
+	return
+}
+
+// ListInvitations retrieves the invitations of the given organization, sorted by email address.
+func ListInvitations(conn *sdk.Connection, orgID string) (result []*Invitation, err error) {
+	accounts := []*amv1.Account{}
+	size := 100
+	for page := 1; ; page++ {
+		var response *amv1.AccountsListResponse
+		response, err = conn.AccountsMgmt().V1().Accounts().List().
+			Search(fmt.Sprintf("organization_id = '%s'", orgID)).
+			Parameter("fetchLabels", true).
+			Size(size).
+			Page(page).
+			Send()
+		if err != nil {
+			err = fmt.Errorf("Can't retrieve users of organization '%s': %v", orgID, err)
+			return
+		}
+		accounts = append(accounts, response.Items().Slice()...)
+		if response.Size() < size {
+			break
+		}
+	}
+	invited := []*amv1.Account{}
+	for _, account := range accounts {
+		if InvitationStatus(account) != "" {
+			invited = append(invited, account)
+		}
+	}
+	roles, err := GetRolesFromUsers(invited, conn)
+	if err != nil {
+		return
+	}
+	for _, account := range invited {
+		result = append(result, &Invitation{
+			Account: account,
+			Status:  InvitationStatus(account),
+			Roles:   roles[account],
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Account.Email() < result[j].Account.Email()
+	})
+	return
+}
+
+// CancelInvitation removes the roles of an invited account and marks the invitation as
+// cancelled. Invitations that have already been accepted can't be cancelled, use the 'account
+// users offboard' command instead.
+func CancelInvitation(conn *sdk.Connection, account *amv1.Account) error {
+	switch InvitationStatus(account) {
+	case InvitationPending:
+	case "":
+		return fmt.Errorf("User '%s' wasn't invited", account.Email())
+	case InvitationAccepted:
+		return fmt.Errorf(
+			"Invitation of '%s' has already been accepted, use 'ocm account users offboard' "+
+				"to remove the user",
+			account.Email(),
+		)
+	default:
+		return fmt.Errorf("Invitation of '%s' has already been cancelled", account.Email())
+	}
+	response, err := conn.AccountsMgmt().V1().RoleBindings().List().
+		Parameter("search", fmt.Sprintf("account_id = '%s'", account.ID())).
+		Size(100).
+		Send()
+	if err != nil {
+		return fmt.Errorf("Can't retrieve roles of '%s': %v", account.Email(), err)
+	}
+	for _, binding := range response.Items().Slice() {
+		_, err = conn.AccountsMgmt().V1().RoleBindings().RoleBinding(binding.ID()).Delete().Send()
+		if err != nil {
+			return fmt.Errorf(
+				"Can't remove role '%s' of '%s': %w",
+				binding.Role().ID(), account.Email(), err,
+			)
+		}
+	}
+	return setInvitationStatus(conn, account.ID(), InvitationCancelled, true)
+}
+
+// setInvitationStatus creates or updates the label that contains the status of the invitation.
+func setInvitationStatus(conn *sdk.Connection, accountID, status string, exists bool) error {
+	body, err := amv1.NewLabel().Key(InvitationLabel).Value(status).Build()
+	if err != nil {
+		return err
+	}
+	labels := conn.AccountsMgmt().V1().Accounts().Account(accountID).Labels()
+	if exists {
+		_, err = labels.Labels(InvitationLabel).Update().Body(body).Send()
+	} else {
+		_, err = labels.Add().Body(body).Send()
+	}
+	if err != nil {
+		return fmt.Errorf("Can't set status of invitation of account '%s': %w", accountID, err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package account
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Progress reports the progress of a scan of a collection whose total size has been retrieved
+// before starting it, including an estimation of the remaining time. When the output is a terminal
+// each report replaces the previous one, otherwise each report is written in a separate line.
+type Progress struct {
+	out       io.Writer
+	terminal  bool
+	what      string
+	total     int
+	initial   int
+	processed int
+	start     time.Time
+	written   bool
+}
+
+// NewProgress creates an object that reports the progress of the scan of the given total number of
+// items to the given writer, usually the standard error. The initial value is the number of items
+// that were already processed, for example by a previous run that is being resumed.
+func NewProgress(out io.Writer, terminal bool, what string, total, initial int) *Progress {
+	return &Progress{
+		out:       out,
+		terminal:  terminal,
+		what:      what,
+		total:     total,
+		initial:   initial,
+		processed: initial,
+		start:     time.Now(),
+	}
+}
+
+// Set sets the number of items processed so far and writes the report. A nil progress doesn't
+// report anything.
+func (p *Progress) Set(processed int) {
+	if p == nil {
+		return
+	}
+	if processed > p.total {
+		processed = p.total
+	}
+	p.processed = processed
+	line := fmt.Sprintf(
+		"Processed %s/%s %s",
+		FormatCount(p.processed), FormatCount(p.total), p.what,
+	)
+	if p.total > 0 {
+		line = fmt.Sprintf("%s (%d%%)", line, p.processed*100/p.total)
+	}
+	done := p.processed - p.initial
+	remaining := p.total - p.processed
+	if done > 0 && remaining > 0 {
+		elapsed := time.Since(p.start)
+		estimate := time.Duration(float64(elapsed) / float64(done) * float64(remaining))
+		line = fmt.Sprintf("%s, about %s left", line, estimate.Round(time.Second))
+	}
+	if p.terminal {
+		fmt.Fprintf(p.out, "\r\033[K%s", line)
+	} else {
+		fmt.Fprintln(p.out, line)
+	}
+	p.written = true
+}
+
+// Finish reports that all the items have been processed, as the last page of a collection may
+// contain less items than the total calculated before starting the scan.
+func (p *Progress) Finish() {
+	if p == nil {
+		return
+	}
+	p.Set(p.total)
+}
+
+// Close finishes the line of the last report when the output is a terminal.
+func (p *Progress) Close() {
+	if p == nil {
+		return
+	}
+	if p.terminal && p.written {
+		fmt.Fprintln(p.out)
+	}
+}
+
+// FormatCount formats the given number with commas separating the groups of thousands, for
+// example 8431 is formatted as '8,431'.
+func FormatCount(value int) string {
+	text := strconv.Itoa(value)
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		text = text[1:]
+	}
+	for i := len(text) - 3; i > 0; i -= 3 {
+		text = text[:i] + "," + text[i:]
+	}
+	return sign + text
+}
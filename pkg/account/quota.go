@@ -0,0 +1,142 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package account
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/bulk"
+)
+
+// ExitQuotaAboveThreshold is the exit code used by the quota commands when a quota is consumed
+// above the threshold given with the '--fail-when-above' option. It is different to the exit code
+// used for other errors so that scripts can tell apart an exhausted quota from a failure to check
+// it.
+const ExitQuotaAboveThreshold = 3
+
+// QuotaMatches checks if the given reserved resource consumes quota according to the given
+// related resource of a quota cost. Empty values and 'any' in the related resource match
+// everything.
+func QuotaMatches(related *amv1.RelatedResource, reserved *amv1.ReservedResource) bool {
+	byoc := "rhinfra"
+	if reserved.BYOC() {
+		byoc = "byoc"
+	}
+	return quotaFieldMatches(related.ResourceType(), reserved.ResourceType()) &&
+		quotaFieldMatches(related.ResourceName(), reserved.ResourceName()) &&
+		quotaFieldMatches(related.BillingModel(), string(reserved.BillingModel())) &&
+		quotaFieldMatches(related.AvailabilityZoneType(), reserved.AvailabilityZoneType()) &&
+		quotaFieldMatches(related.BYOC(), byoc)
+}
+
+func quotaFieldMatches(pattern, value string) bool {
+	return pattern == "" || pattern == "any" || strings.EqualFold(pattern, value)
+}
+
+// ParseQuotaThreshold parses a threshold given as a percentage, for example '80%' or '80', and
+// returns it as a number between 0 and 100.
+func ParseQuotaThreshold(text string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(text), "%"), 64)
+	if err != nil || value <= 0 || value > 100 {
+		return 0, fmt.Errorf(
+			"Invalid threshold '%s', it must be a percentage greater than 0 and not greater "+
+				"than 100, for example '80%%'",
+			text,
+		)
+	}
+	return value, nil
+}
+
+// ListQuotaCosts retrieves all the quota costs of the given organization, including the related
+// resources.
+func ListQuotaCosts(conn *sdk.Connection, orgID string) (result []*amv1.QuotaCost, err error) {
+	size := 100
+	for page := 1; ; page++ {
+		var response *amv1.QuotaCostListResponse
+		response, err = conn.AccountsMgmt().V1().Organizations().Organization(orgID).QuotaCost().
+			List().
+			Parameter("fetchRelatedResources", true).
+			Size(size).
+			Page(page).
+			Send()
+		if err != nil {
+			err = fmt.Errorf("Failed to retrieve quota: %v", err)
+			return
+		}
+		result = append(result, response.Items().Slice()...)
+		if response.Size() < size {
+			return
+		}
+	}
+}
+
+// QuotaUsage returns the percentage of the quota that is consumed. Quota that isn't allowed but
+// is consumed anyhow, for example after a reduction of the subscriptions, counts as more than
+// completely consumed.
+func QuotaUsage(quota *amv1.QuotaCost) float64 {
+	if quota.Allowed() == 0 {
+		if quota.Consumed() > 0 {
+			return math.Inf(1)
+		}
+		return 0
+	}
+	return 100 * float64(quota.Consumed()) / float64(quota.Allowed())
+}
+
+// CheckQuotaThreshold returns an error containing a compact summary of the quotas whose usage is
+// above the given percentage, or nil if there are none. The error contains the exit code that
+// the command should use.
+func CheckQuotaThreshold(orgID string, quotas []*amv1.QuotaCost, threshold float64) error {
+	offenders := []*amv1.QuotaCost{}
+	for _, quota := range quotas {
+		if QuotaUsage(quota) > threshold {
+			offenders = append(offenders, quota)
+		}
+	}
+	if len(offenders) == 0 {
+		return nil
+	}
+	sort.SliceStable(offenders, func(i, j int) bool {
+		return QuotaUsage(offenders[i]) > QuotaUsage(offenders[j])
+	})
+	items := make([]string, len(offenders))
+	for i, quota := range offenders {
+		usage := "over allowed"
+		if quota.Allowed() > 0 {
+			usage = fmt.Sprintf("%d%%", int(QuotaUsage(quota)))
+		}
+		items[i] = fmt.Sprintf(
+			"%s (%d of %d, %s)",
+			quota.QuotaID(), quota.Consumed(), quota.Allowed(), usage,
+		)
+	}
+	return &bulk.Error{
+		Code: ExitQuotaAboveThreshold,
+		Message: fmt.Sprintf(
+			"%d quotas of organization '%s' are above %s%%: %s",
+			len(offenders), orgID, strconv.FormatFloat(threshold, 'f', -1, 64),
+			strings.Join(items, ", "),
+		),
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package account
+
+import (
+	"sort"
+)
+
+// roleLevels is the hierarchy of the roles that can be assigned to accounts, from less to more
+// privileged. Roles with the same level grant comparable privileges.
+var roleLevels = map[string]int{
+	"AuthenticatedUser":  0,
+	"ClusterViewer":      1,
+	"ClusterEditor":      2,
+	"ClusterOwner":       3,
+	"ClusterProvisioner": 3,
+	"OrganizationAdmin":  4,
+	"UHCSupport":         5,
+	"UHCAdmin":           6,
+}
+
+// RoleLevel returns the level of the given role in the role hierarchy. The boolean result will be
+// false if the role isn't part of the hierarchy.
+func RoleLevel(role string) (level int, ok bool) {
+	level, ok = roleLevels[role]
+	return
+}
+
+// MaxRoleLevel returns the highest level of the given roles in the role hierarchy, or -1 if none
+// of the roles is part of the hierarchy.
+func MaxRoleLevel(roles []string) int {
+	result := -1
+	for _, role := range roles {
+		level, ok := roleLevels[role]
+		if ok && level > result {
+			result = level
+		}
+	}
+	return result
+}
+
+// HierarchyRoles returns the roles that are part of the role hierarchy, sorted from less to more
+// privileged.
+func HierarchyRoles() []string {
+	result := make([]string, 0, len(roleLevels))
+	for role := range roleLevels {
+		result = append(result, role)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if roleLevels[result[i]] != roleLevels[result[j]] {
+			return roleLevels[result[i]] < roleLevels[result[j]]
+		}
+		return result[i] < result[j]
+	})
+	return result
+}
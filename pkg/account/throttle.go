@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package account
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default configuration of the throttle:
+const (
+	DefaultThrottlePause    = 10 * time.Second
+	DefaultThrottleAttempts = 5
+)
+
+// Throttle coordinates the goroutines that send requests to the server, so that when the server
+// reports that it is rate limiting the client all of them pause till the retry window advertised
+// by the server is over, instead of failing or making the situation worse with more requests.
+// A nil throttle sends each request only once.
+type Throttle struct {
+	out      io.Writer
+	pause    time.Duration
+	attempts int
+	lock     sync.Mutex
+	until    time.Time
+}
+
+// NewThrottle creates a new throttle that reports the pauses to the given writer, usually the
+// standard error.
+func NewThrottle(out io.Writer) *Throttle {
+	return &Throttle{
+		out:      out,
+		pause:    DefaultThrottlePause,
+		attempts: DefaultThrottleAttempts,
+	}
+}
+
+// Send calls the given function, which should send one request and return the status code, the
+// response headers and the error. If the server responds with 429, or with 403 and a Retry-After
+// header, all the goroutines using the throttle are paused and the request is sent again, up to
+// the maximum number of attempts.
+func (t *Throttle) Send(send func() (status int, header http.Header, err error)) error {
+	if t == nil {
+		_, _, err := send()
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		t.wait()
+		status, header, err := send()
+		if !throttled(status, header) || attempt >= t.attempts {
+			return err
+		}
+		t.delay(retryAfter(header, t.pause), status)
+	}
+}
+
+// wait blocks the calling goroutine till the current pause, if any, is over.
+func (t *Throttle) wait() {
+	for {
+		t.lock.Lock()
+		remaining := time.Until(t.until)
+		t.lock.Unlock()
+		if remaining <= 0 {
+			return
+		}
+		time.Sleep(remaining)
+	}
+}
+
+// delay starts or extends the pause. Only the goroutine that extends the pause reports it, so
+// that many goroutines receiving the same response don't flood the output.
+func (t *Throttle) delay(pause time.Duration, status int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	until := time.Now().Add(pause)
+	if !until.After(t.until) {
+		return
+	}
+	t.until = until
+	fmt.Fprintf(
+		t.out,
+		"Server is throttling requests (status %d), pausing for %s\n",
+		status, pause.Round(time.Second),
+	)
+}
+
+// throttled checks if the given status and headers indicate that the server is rate limiting
+// the client. Some gateways use 403 instead of 429 for that, but a plain 403 means that
+// permission is denied, so it is only considered throttling when it includes a retry window.
+func throttled(status int, header http.Header) bool {
+	switch status {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return header.Get("Retry-After") != ""
+	default:
+		return false
+	}
+}
+
+// retryAfter extracts the retry window from the Retry-After header, which can contain either a
+// number of seconds or a date. If the header is missing or can't be parsed it returns the given
+// default.
+func retryAfter(header http.Header, pause time.Duration) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return pause
+	}
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		if seconds < 0 {
+			return pause
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	date, err := http.ParseTime(value)
+	if err == nil {
+		remaining := time.Until(date)
+		if remaining < 0 {
+			return 0
+		}
+		return remaining
+	}
+	return pause
+}
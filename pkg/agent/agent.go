@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to implement the '--user-agent' command line option and
+// the 'user_agent' configuration setting, that add an identifier of the team or tool using this
+// command line tool to the 'User-Agent' header, so that the traffic of automation built on top of
+// it can be told apart from the traffic of users.
+
+package agent
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openshift-online/ocm-cli/pkg/info"
+)
+
+// Prefix is the part of the 'User-Agent' header that identifies this command line tool.
+var Prefix = "OCM-CLI/" + info.Version
+
+// EnvVar is the name of the environment variable that can be used to set the identifier instead
+// of the '--user-agent' command line flag.
+const EnvVar = "OCM_USER_AGENT"
+
+// AddFlag adds the user agent flag to the given set of command line flags.
+func AddFlag(flags *pflag.FlagSet) {
+	flags.StringVar(
+		&flag,
+		"user-agent",
+		"",
+		"Identifier of the team or tool, for example 'my-team-tool/1.0', added to the "+
+			"'User-Agent' header of the requests sent to the API. Overrides the '"+EnvVar+
+			"' environment variable and the 'user_agent' configuration setting.",
+	)
+}
+
+// flag is the value of the '--user-agent' command line flag.
+var flag string
+
+// Suffix returns the identifier that should be added to the 'User-Agent' header. The command line
+// flag takes precedence over the environment variable, and that over the given value of the
+// configuration setting.
+func Suffix(configured string) string {
+	switch {
+	case flag != "":
+		return flag
+	case os.Getenv(EnvVar) != "":
+		return os.Getenv(EnvVar)
+	default:
+		return configured
+	}
+}
+
+// Value returns the complete value of the 'User-Agent' header, or an error if the identifier
+// selected by the Suffix function isn't valid.
+func Value(configured string) (result string, err error) {
+	suffix := strings.TrimSpace(Suffix(configured))
+	if suffix == "" {
+		result = Prefix
+		return
+	}
+	err = Validate(suffix)
+	if err != nil {
+		return
+	}
+	result = Prefix + " " + suffix
+	return
+}
+
+// Validate checks that the given identifier can be used in the 'User-Agent' header, which only
+// accepts printable ASCII characters.
+func Validate(suffix string) error {
+	for _, char := range suffix {
+		if char < ' ' || char > '~' {
+			return fmt.Errorf(
+				"Invalid user agent '%s', it must contain only printable ASCII "+
+					"characters, for example 'my-team-tool/1.0'",
+				suffix,
+			)
+		}
+	}
+	return nil
+}
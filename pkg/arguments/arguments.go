@@ -19,7 +19,10 @@ limitations under the License.
 package arguments
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/url"
 	"os"
@@ -28,10 +31,23 @@ import (
 
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/spf13/pflag"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"gopkg.in/yaml.v3"
 
+	"github.com/openshift-online/ocm-cli/pkg/agent"
+	"github.com/openshift-online/ocm-cli/pkg/cache"
 	"github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/compress"
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/curl"
 	"github.com/openshift-online/ocm-cli/pkg/debug"
+	"github.com/openshift-online/ocm-cli/pkg/i18n"
+	"github.com/openshift-online/ocm-cli/pkg/impersonate"
 	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/protection"
+	"github.com/openshift-online/ocm-cli/pkg/redact"
+	"github.com/openshift-online/ocm-cli/pkg/trace"
 )
 
 type FilePath string
@@ -58,6 +74,54 @@ func AddDebugFlag(fs *pflag.FlagSet) {
 	debug.AddFlag(fs)
 }
 
+// AddContextFlag adds the '--context' flag to the given set of command line flags.
+func AddContextFlag(fs *pflag.FlagSet) {
+	config.AddContextFlag(fs)
+}
+
+// AddCacheFlags adds the '--cached', '--refresh' and '--cache-ttl' flags to the given set of
+// command line flags.
+func AddCacheFlags(fs *pflag.FlagSet) {
+	cache.AddFlags(fs)
+}
+
+// AddCurlFlag adds the '--curl' flag to the given set of command line flags.
+func AddCurlFlag(fs *pflag.FlagSet) {
+	curl.AddFlag(fs)
+}
+
+// AddImpersonateFlags adds the '--impersonate-user' and '--impersonate-account-id' flags to the
+// given set of command line flags.
+func AddImpersonateFlags(fs *pflag.FlagSet) {
+	impersonate.AddFlags(fs)
+}
+
+// AddLangFlag adds the '--lang' flag to the given set of command line flags.
+func AddLangFlag(fs *pflag.FlagSet) {
+	i18n.AddFlag(fs)
+}
+
+// AddNoCompressFlag adds the '--no-compress' flag to the given set of command line flags.
+func AddNoCompressFlag(fs *pflag.FlagSet) {
+	compress.AddFlag(fs)
+}
+
+// AddProductionFlag adds the '--i-know-this-is-production' flag to the given set of command line
+// flags.
+func AddProductionFlag(fs *pflag.FlagSet) {
+	protection.AddFlag(fs)
+}
+
+// AddTraceFlag adds the '--trace' flag to the given set of command line flags.
+func AddTraceFlag(fs *pflag.FlagSet) {
+	trace.AddFlag(fs)
+}
+
+// AddUserAgentFlag adds the '--user-agent' flag to the given set of command line flags.
+func AddUserAgentFlag(fs *pflag.FlagSet) {
+	agent.AddFlag(fs)
+}
+
 // AddParameterFlag adds the '--parameter' flag to the given set of command line flags.
 func AddParameterFlag(fs *pflag.FlagSet, values *[]string) {
 	fs.StringArrayVarP(
@@ -80,9 +144,52 @@ func AddHeaderFlag(fs *pflag.FlagSet, values *[]string) {
 		"header",
 		nil,
 		"Headers to add to the request. The value must be the name of the header "+
-			"followed by an optional equals sign and then the value of the "+
+			"followed by an optional equals sign or colon and then the value of the "+
 			"header. Can be used multiple times to specify multiple headers "+
-			"or multiple values for the same header.",
+			"or multiple values for the same header. Example: "+
+			"--header 'X-My-Header: my-value'",
+	)
+}
+
+// AddFieldsFlag adds the '--fields' flag to the given set of command line flags.
+func AddFieldsFlag(fs *pflag.FlagSet, values *[]string) {
+	fs.StringSliceVar(
+		values,
+		"fields",
+		nil,
+		"Comma separated list of fields that the server should return, so that the "+
+			"rest are omitted from the response. Nested fields use dots. Can be used "+
+			"multiple times. Example: --fields id,name,region.id",
+	)
+}
+
+// AddOutputFlag adds the '--output' flag to the given set of command line flags.
+func AddOutputFlag(fs *pflag.FlagSet, value *string) {
+	fs.StringVarP(
+		value,
+		"output",
+		"o",
+		string(output.FormatTable),
+		fmt.Sprintf(
+			"Output format, one of '%s'.",
+			strings.Join(output.Formats(), "', '"),
+		),
+	)
+}
+
+// AddRedactFlag adds the '--redact-pii' flag to the given set of command line flags.
+func AddRedactFlag(fs *pflag.FlagSet, value *bool) {
+	fs.BoolVar(
+		value,
+		"redact-pii",
+		false,
+		fmt.Sprintf(
+			"Replace personal data, like user names, email addresses and names, with "+
+				"hashes, so that the output can be shared. The same value always "+
+				"produces the same hash. Set the '%s' environment variable to use a "+
+				"secret key for the hashes.",
+			redact.KeyEnvVar,
+		),
 	)
 }
 
@@ -93,7 +200,8 @@ func AddBodyFlag(fs *pflag.FlagSet, value *string) {
 		"body",
 		"",
 		"Name of the file containing the request body. If this isn't given then "+
-			"the body will be taken from the standard input.",
+			"the body will be taken from the standard input. The body can be JSON or "+
+			"YAML, YAML is converted to JSON before sending it.",
 	)
 }
 
@@ -255,6 +363,33 @@ func AddAutoscalingFlags(fs *pflag.FlagSet, value *cluster.Autoscaling) {
 	SetQuestion(fs, "max-replicas", "Max replicas:")
 }
 
+// EC2MetadataHTTPTokensOptions are the valid values of the --ec2-metadata-http-tokens flag.
+var EC2MetadataHTTPTokensOptions = []Option{
+	{
+		Value:       cluster.EC2MetadataHTTPTokensRequired,
+		Description: "Only IMDSv2 is allowed",
+	},
+	{
+		Value:       cluster.EC2MetadataHTTPTokensOptional,
+		Description: "Both IMDSv1 and IMDSv2 are allowed",
+	},
+}
+
+// AddEC2MetadataHTTPTokensFlag adds the --ec2-metadata-http-tokens flag, that controls if the
+// instances of a machine pool require version 2 of the AWS instance metadata service.
+func AddEC2MetadataHTTPTokensFlag(fs *pflag.FlagSet, value *string) {
+	fs.StringVar(
+		value,
+		"ec2-metadata-http-tokens",
+		"",
+		fmt.Sprintf(
+			"Use of the AWS instance metadata service by the nodes. Use '%s' to allow only "+
+				"IMDSv2, or '%s' to also allow IMDSv1. Only for AWS clusters.",
+			cluster.EC2MetadataHTTPTokensRequired, cluster.EC2MetadataHTTPTokensOptional,
+		),
+	)
+}
+
 // CheckAutoscalingFlags errors if --min-replicas or --max-replicas
 // were used without --enable-autoscaling (and vice-versa with --compute-nodes)
 // It also errors if --min-replicas or --max-replicas were not supplied
@@ -304,17 +439,39 @@ func AddProviderFlag(fs *pflag.FlagSet, value *string) {
 // ApplyParameterFlag applies the value of the '--parameter' command line flag to the given
 // request.
 func ApplyParameterFlag(request interface{}, values []string) {
-	applyNVFlag(request, "Parameter", values)
+	applyNVFlag(request, "Parameter", values, ParseNameValuePair)
 }
 
 // ApplyHeaderFlag applies the value of the '--header' command line flag to the given request.
 func ApplyHeaderFlag(request interface{}, values []string) {
-	applyNVFlag(request, "Header", values)
+	applyNVFlag(request, "Header", values, ParseHeader)
+}
+
+// ApplyFieldsFlag applies the value of the '--fields' command line flag to the given request, as
+// the 'fields' query parameter.
+func ApplyFieldsFlag(request interface{}, values []string) {
+	var fields []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			fields = append(fields, value)
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+	applyNVFlag(
+		request,
+		"Parameter",
+		[]string{"fields=" + strings.Join(fields, ",")},
+		ParseNameValuePair,
+	)
 }
 
 // applyNVFlag finds the method with the given name in a request and calls it to set a collection of
-// name value pairs.
-func applyNVFlag(request interface{}, method string, values []string) {
+// name value pairs, split using the given parse function.
+func applyNVFlag(request interface{}, method string, values []string,
+	parse func(string) (string, string)) {
 	// Find the method:
 	callable := reflect.ValueOf(request).MethodByName(method)
 	if !callable.IsValid() {
@@ -324,7 +481,7 @@ func applyNVFlag(request interface{}, method string, values []string) {
 	// Split the values into name value pairs and call the method for each one:
 	for _, value := range values {
 		var name string
-		name, value = ParseNameValuePair(value)
+		name, value = parse(value)
 		args := []reflect.Value{
 			reflect.ValueOf(name),
 			reflect.ValueOf(value),
@@ -335,8 +492,17 @@ func applyNVFlag(request interface{}, method string, values []string) {
 
 // ApplyBodyFlag applies the value of the '--body' command line flag to the given request.
 func ApplyBodyFlag(request *sdk.Request, value string) error {
-	var body []byte
-	var err error
+	body, err := ReadBodyFlag(value)
+	if err != nil {
+		return err
+	}
+	request.Bytes(body)
+	return nil
+}
+
+// ReadBodyFlag reads the body indicated by the value of the '--body' command line flag, or the
+// standard input if it is empty, converting it to JSON if it is YAML.
+func ReadBodyFlag(value string) (body []byte, err error) {
 	if value != "" {
 		// #nosec G304
 		body, err = ioutil.ReadFile(value)
@@ -347,10 +513,50 @@ func ApplyBodyFlag(request *sdk.Request, value string) error {
 		body, err = ioutil.ReadAll(os.Stdin)
 	}
 	if err != nil {
-		return err
+		return
 	}
-	request.Bytes(body)
-	return nil
+	body, err = decodeText(body)
+	if err != nil {
+		return
+	}
+	body, err = yamlToJSON(body)
+	return
+}
+
+// yamlToJSON converts the given body to JSON if it is YAML. Bodies that are already valid JSON,
+// and empty bodies, are returned unchanged so that they are sent exactly as given by the user.
+func yamlToJSON(data []byte) (result []byte, err error) {
+	if len(bytes.TrimSpace(data)) == 0 || json.Valid(data) {
+		result = data
+		return
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var value interface{}
+	err = decoder.Decode(&value)
+	if err != nil {
+		err = fmt.Errorf("body isn't valid JSON or YAML: %v", err)
+		return
+	}
+	var extra interface{}
+	if decoder.Decode(&extra) != io.EOF {
+		err = fmt.Errorf("body contains multiple YAML documents, only one is supported")
+		return
+	}
+	result, err = json.Marshal(value)
+	if err != nil {
+		err = fmt.Errorf("can't convert YAML body to JSON: %v", err)
+		return
+	}
+	return
+}
+
+// decodeText converts the given text to UTF-8 without byte order mark. This is needed because
+// some editors and shells, PowerShell in particular, write files in UTF-16 or add a byte order
+// mark that the server doesn't accept.
+func decodeText(data []byte) (result []byte, err error) {
+	decoder := unicode.BOMOverride(unicode.UTF8.NewDecoder())
+	result, _, err = transform.Bytes(decoder, data)
+	return
 }
 
 // ApplyPathArg applies the value of the path given in the command line to the given request.
@@ -385,3 +591,17 @@ func ParseNameValuePair(text string) (name, value string) {
 	}
 	return
 }
+
+// ParseHeader parses the value of the '--header' flag. It accepts the 'name=value' syntax used by
+// the rest of the name value pairs, and also the 'Name: value' syntax used by HTTP and by tools
+// like curl. Header names can't contain colons or equals signs, so the first of them is the
+// separator.
+func ParseHeader(text string) (name, value string) {
+	position := strings.IndexAny(text, ":=")
+	if position != -1 && text[position] == ':' {
+		name = strings.TrimSpace(text[:position])
+		value = strings.TrimSpace(text[position+1:])
+		return
+	}
+	return ParseNameValuePair(text)
+}
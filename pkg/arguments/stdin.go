@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to read the identifiers of the objects that commands work
+// with from the standard input, so that commands can be chained in pipelines.
+
+package arguments
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// AddIDsFromStdinFlag adds the '--ids-from-stdin' flag to the given set of command line flags.
+func AddIDsFromStdinFlag(fs *pflag.FlagSet, value *bool) {
+	fs.BoolVar(
+		value,
+		"ids-from-stdin",
+		false,
+		"Read the identifiers from the standard input, one per line, instead of from the "+
+			"command line. Only the first word of each line is used, and empty lines and "+
+			"lines starting with '#' are ignored.",
+	)
+}
+
+// IDs returns the identifiers given in the command line or, if the '--ids-from-stdin' flag is
+// set, the identifiers read from the standard input. It fails if the flag is used together with
+// identifiers in the command line, or if there are no identifiers at all.
+func IDs(argv []string, fromStdin bool) ([]string, error) {
+	if !fromStdin {
+		if len(argv) == 0 {
+			return nil, fmt.Errorf("At least one identifier is required")
+		}
+		return argv, nil
+	}
+	if len(argv) > 0 {
+		return nil, fmt.Errorf(
+			"Option '--ids-from-stdin' can't be used with identifiers in the command line",
+		)
+	}
+	ids, err := ReadIDs(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("Can't read identifiers from the standard input: %v", err)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("No identifiers found in the standard input")
+	}
+	return ids, nil
+}
+
+// ReadIDs reads identifiers from the given reader, one per line. Only the first word of each line
+// is used, so that the output of commands that print tables can be used directly. Empty lines and
+// lines starting with '#' are ignored.
+func ReadIDs(reader io.Reader) ([]string, error) {
+	var ids []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		ids = append(ids, fields[0])
+	}
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,184 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bulk contains types used by the commands that apply the same operation to multiple
+// items, to report the result of each item and to calculate the exit status of the command.
+package bulk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Exit codes used by bulk commands. Zero means that all the items succeeded.
+const (
+	// ExitFailure is used when all the items failed.
+	ExitFailure = 1
+
+	// ExitPartialFailure is used when some of the items failed and some succeeded.
+	ExitPartialFailure = 2
+)
+
+// Status is the result of processing one item.
+type Status string
+
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusSkipped   Status = "skipped"
+	StatusFailed    Status = "failed"
+)
+
+// Result contains the outcome of processing one item.
+type Result struct {
+	ID     string `json:"id"`
+	Name   string `json:"name,omitempty"`
+	Action string `json:"action,omitempty"`
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Results collects the outcome of processing a set of items. It is safe for concurrent use, so
+// workers processing items in parallel can record their results directly.
+type Results struct {
+	lock  sync.Mutex
+	items []*Result
+}
+
+// NewResults creates an empty set of results.
+func NewResults() *Results {
+	return &Results{
+		items: []*Result{},
+	}
+}
+
+// Succeeded records that the given action was applied to the item.
+func (r *Results) Succeeded(id, name, action string) {
+	r.add(id, name, action, StatusSucceeded, nil)
+}
+
+// Skipped records that the item didn't need any change.
+func (r *Results) Skipped(id, name, action string) {
+	r.add(id, name, action, StatusSkipped, nil)
+}
+
+// Failed records that the action failed for the item with the given error.
+func (r *Results) Failed(id, name, action string, err error) {
+	r.add(id, name, action, StatusFailed, err)
+}
+
+func (r *Results) add(id, name, action string, status Status, err error) {
+	result := &Result{
+		ID:     id,
+		Name:   name,
+		Action: action,
+		Status: status,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	r.lock.Lock()
+	r.items = append(r.items, result)
+	r.lock.Unlock()
+}
+
+// Items returns a copy of the results recorded so far.
+func (r *Results) Items() []*Result {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	items := make([]*Result, len(r.items))
+	copy(items, r.items)
+	return items
+}
+
+// Count returns the number of items that have the given status.
+func (r *Results) Count(status Status) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.count(status)
+}
+
+func (r *Results) count(status Status) int {
+	count := 0
+	for _, item := range r.items {
+		if item.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Write writes the results to the given file in JSON format.
+func (r *Results) Write(file string) error {
+	r.lock.Lock()
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"items": r.items,
+		"summary": map[string]int{
+			"total":     len(r.items),
+			"succeeded": r.count(StatusSucceeded),
+			"skipped":   r.count(StatusSkipped),
+			"failed":    r.count(StatusFailed),
+		},
+	}, "", "  ")
+	r.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	err = os.WriteFile(file, data, 0600)
+	if err != nil {
+		return fmt.Errorf("Can't write results file '%s': %v", file, err)
+	}
+	return nil
+}
+
+// Err returns nil if no item failed. Otherwise it returns an error containing the exit code that
+// the command should use: ExitFailure if all the items failed and ExitPartialFailure if only some
+// of them failed. The message is used as the prefix of the text of the error.
+func (r *Results) Err(message string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	failed := r.count(StatusFailed)
+	if failed == 0 {
+		return nil
+	}
+	if failed == len(r.items) {
+		return &Error{
+			Code:    ExitFailure,
+			Message: fmt.Sprintf("%s: all %d items failed", message, failed),
+		}
+	}
+	return &Error{
+		Code: ExitPartialFailure,
+		Message: fmt.Sprintf(
+			"%s: %d of %d items failed",
+			message, failed, len(r.items),
+		),
+	}
+}
+
+// Error is returned by bulk commands when some of the items failed, and by other commands that
+// need a specific exit code. It contains the exit code that the command should use.
+type Error struct {
+	Code    int
+	Message string
+}
+
+// Error is the implementation of the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
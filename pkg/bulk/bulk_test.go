@@ -0,0 +1,128 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bulk
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Results", func() {
+	It("Doesn't return an error if nothing was processed", func() {
+		results := NewResults()
+		Expect(results.Err("Failed")).ToNot(HaveOccurred())
+	})
+
+	It("Doesn't return an error if no item failed", func() {
+		results := NewResults()
+		results.Succeeded("1", "one", "create")
+		results.Skipped("2", "two", "")
+		Expect(results.Err("Failed")).ToNot(HaveOccurred())
+	})
+
+	It("Returns the partial failure code if some items failed", func() {
+		results := NewResults()
+		results.Succeeded("1", "one", "create")
+		results.Failed("2", "two", "update", errors.New("my error"))
+		err := results.Err("Failed")
+		var bulkErr *Error
+		Expect(errors.As(err, &bulkErr)).To(BeTrue())
+		Expect(bulkErr.Code).To(Equal(ExitPartialFailure))
+		Expect(err.Error()).To(Equal("Failed: 1 of 2 items failed"))
+	})
+
+	It("Returns the failure code if all the items failed", func() {
+		results := NewResults()
+		results.Failed("1", "one", "create", errors.New("my error"))
+		results.Failed("2", "two", "update", errors.New("your error"))
+		err := results.Err("Failed")
+		var bulkErr *Error
+		Expect(errors.As(err, &bulkErr)).To(BeTrue())
+		Expect(bulkErr.Code).To(Equal(ExitFailure))
+		Expect(err.Error()).To(Equal("Failed: all 2 items failed"))
+	})
+
+	It("Writes the results file", func() {
+		tmpDir, err := os.MkdirTemp("", "ocm-test-*")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		file := filepath.Join(tmpDir, "results.json")
+
+		results := NewResults()
+		results.Succeeded("1", "one", "create")
+		results.Failed("2", "two", "update", errors.New("my error"))
+		err = results.Write(file)
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		var content map[string]interface{}
+		err = json.Unmarshal(data, &content)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(Equal(map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{
+					"id":     "1",
+					"name":   "one",
+					"action": "create",
+					"status": "succeeded",
+				},
+				map[string]interface{}{
+					"id":     "2",
+					"name":   "two",
+					"action": "update",
+					"status": "failed",
+					"error":  "my error",
+				},
+			},
+			"summary": map[string]interface{}{
+				"total":     2.0,
+				"succeeded": 1.0,
+				"skipped":   0.0,
+				"failed":    1.0,
+			},
+		}))
+	})
+
+	It("Records results from multiple goroutines", func() {
+		results := NewResults()
+		group := &sync.WaitGroup{}
+		for i := 0; i < 20; i++ {
+			group.Add(1)
+			go func(i int) {
+				defer group.Done()
+				for j := 0; j < 50; j++ {
+					if j%10 == 0 {
+						results.Failed("1", "one", "update", errors.New("my error"))
+					} else {
+						results.Succeeded("1", "one", "update")
+					}
+				}
+			}(i)
+		}
+		group.Wait()
+		Expect(results.Items()).To(HaveLen(1000))
+		Expect(results.Count(StatusSucceeded)).To(Equal(900))
+		Expect(results.Count(StatusFailed)).To(Equal(100))
+	})
+})
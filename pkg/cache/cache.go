@@ -0,0 +1,283 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the cache of responses and the '--cached',
+// '--refresh' and '--cache-ttl' command line options.
+
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/spf13/pflag"
+)
+
+// DefaultTTL is the default time that responses are kept in the cache.
+const DefaultTTL = 10 * time.Minute
+
+// AddFlags adds the cache flags to the given set of command line flags.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(
+		&cached,
+		"cached",
+		false,
+		"Use the responses saved in the cache for read requests, if they are newer than "+
+			"the time given by the '--cache-ttl' option, and save the new ones. If the "+
+			"server can't be reached older responses are used as well. Only for commands "+
+			"that don't change the server.",
+	)
+	flags.BoolVar(
+		&refresh,
+		"refresh",
+		false,
+		"Send all the requests to the server and save the responses of read requests in "+
+			"the cache, so that they can be used later with the '--cached' option.",
+	)
+	flags.DurationVar(
+		&ttl,
+		"cache-ttl",
+		DefaultTTL,
+		"Maximum age of the responses used from the cache.",
+	)
+}
+
+// Enabled returns a boolean flag that indicates if the cache is enabled.
+func Enabled() bool {
+	return cached || refresh
+}
+
+// Values of the cache flags:
+var (
+	cached  bool
+	refresh bool
+	ttl     time.Duration
+)
+
+// Dir returns the directory where the CLI stores cached data. It is the 'ocm' sub-directory of the
+// user cache directory, for example '~/.cache/ocm' in Linux.
+func Dir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ocm"), nil
+}
+
+// Clear removes all the data stored in the cache directory.
+func Clear() error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// entry is the content of a cache file.
+type entry struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// TransportWrapper returns a transport wrapper that saves the responses of GET requests in the
+// cache, and that returns them instead of sending the requests when the '--cached' option is
+// used. Responses are keyed by the URL and the subject of the bearer token, so responses are
+// never shared by different users. Requests sent to the token URL are never cached. Warnings,
+// like when an expired response is used because the server can't be reached, are written to the
+// given writer.
+func TransportWrapper(tokenURL string, out io.Writer) func(http.RoundTripper) http.RoundTripper {
+	return func(wrapped http.RoundTripper) http.RoundTripper {
+		return &roundTripper{
+			tokenURL: tokenURL,
+			out:      out,
+			wrapped:  wrapped,
+		}
+	}
+}
+
+type roundTripper struct {
+	tokenURL string
+	out      io.Writer
+	wrapped  http.RoundTripper
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*roundTripper)(nil)
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	if request.Method != http.MethodGet || strings.HasPrefix(request.URL.String(), t.tokenURL) {
+		return t.wrapped.RoundTrip(request)
+	}
+	file := t.file(request)
+	if file == "" {
+		return t.wrapped.RoundTrip(request)
+	}
+
+	// Use the saved response if it isn't too old:
+	var saved *entry
+	var age time.Duration
+	if cached {
+		saved, age = read(file)
+		if saved != nil && age <= ttl {
+			return saved.response(request), nil
+		}
+	}
+
+	// Send the request, and use the saved response, even if it is too old, if the server
+	// can't be reached:
+	response, err = t.wrapped.RoundTrip(request)
+	if err != nil {
+		if saved != nil {
+			fmt.Fprintf(
+				t.out,
+				"WARNING: Can't send request to '%s', using response saved %s ago: %v\n",
+				request.URL.Path, age.Round(time.Second), err,
+			)
+			return saved.response(request), nil
+		}
+		return
+	}
+	if response.StatusCode != http.StatusOK {
+		return
+	}
+
+	// Save the response, replacing the body that we consumed:
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+	write(file, &entry{
+		Status: response.StatusCode,
+		Header: response.Header,
+		Body:   body,
+	})
+	return
+}
+
+// file returns the name of the cache file for the given request, or an empty string if the
+// request can't be cached because the user can't be determined.
+func (t *roundTripper) file(request *http.Request) string {
+	subject := tokenSubject(request.Header.Get("Authorization"))
+	if subject == "" {
+		return ""
+	}
+	dir, err := Dir()
+	if err != nil {
+		return ""
+	}
+	hash := sha256.New()
+	for _, value := range []string{
+		subject,
+		request.URL.String(),
+		request.Header.Get("Accept-Encoding"),
+		request.Header.Get("Impersonate-User"),
+		request.Header.Get("Impersonate-Account-Id"),
+	} {
+		hash.Write([]byte(value))
+		hash.Write([]byte{0})
+	}
+	return filepath.Join(dir, "responses", hex.EncodeToString(hash.Sum(nil))+".json")
+}
+
+// tokenSubject extracts the subject from the bearer token contained in the given authorization
+// header. If the token doesn't have a subject the complete token is returned, so that responses
+// are at least never shared by different tokens. Note that the signature isn't verified, as that
+// is the job of the server.
+func tokenSubject(authorization string) string {
+	const prefix = "Bearer "
+	if !strings.HasPrefix(authorization, prefix) {
+		return ""
+	}
+	text := strings.TrimPrefix(authorization, prefix)
+	token, _, err := new(jwt.Parser).ParseUnverified(text, jwt.MapClaims{})
+	if err != nil {
+		return ""
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	subject, ok := claims["sub"].(string)
+	if !ok || subject == "" {
+		return text
+	}
+	return subject
+}
+
+// read reads the given cache file, and returns the entry and its age, or nil if it doesn't exist
+// or it can't be read.
+func read(file string) (result *entry, age time.Duration) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return
+	}
+	data, err := ioutil.ReadFile(file) // #nosec G304
+	if err != nil {
+		return
+	}
+	result = &entry{}
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		result = nil
+		return
+	}
+	age = time.Since(info.ModTime())
+	return
+}
+
+// write saves the given entry to the given cache file. Errors are ignored, as the cache is only
+// an optimization.
+func write(file string, value *entry) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	err = os.MkdirAll(filepath.Dir(file), 0700)
+	if err != nil {
+		return
+	}
+	ioutil.WriteFile(file, data, 0600) // #nosec G104
+}
+
+// response creates the HTTP response for the given request from the cache entry.
+func (e *entry) response(request *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       request,
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+// fakeTransport counts the requests and returns the configured responses or error.
+type fakeTransport struct {
+	calls int
+	body  string
+	err   error
+}
+
+func (t *fakeTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	t.calls++
+	if t.err != nil {
+		return nil, t.err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(t.body)),
+		Request:    request,
+	}, nil
+}
+
+func makeRequest(method, url, subject string) *http.Request {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": subject})
+	text, err := token.SignedString([]byte("secret"))
+	Expect(err).ToNot(HaveOccurred())
+	request, err := http.NewRequest(method, url, nil)
+	Expect(err).ToNot(HaveOccurred())
+	request.Header.Set("Authorization", "Bearer "+text)
+	return request
+}
+
+func readBody(response *http.Response) string {
+	defer response.Body.Close()
+	data, err := ioutil.ReadAll(response.Body)
+	Expect(err).ToNot(HaveOccurred())
+	return string(data)
+}
+
+var _ = Describe("Transport wrapper", func() {
+	var tmp string
+	var out *bytes.Buffer
+	var fake *fakeTransport
+	var transport http.RoundTripper
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = ioutil.TempDir("", "ocm-cache-*")
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("XDG_CACHE_HOME", tmp) // #nosec G104
+		cached, refresh, ttl = true, false, DefaultTTL
+		out = &bytes.Buffer{}
+		fake = &fakeTransport{body: `{"id":"123"}`}
+		transport = TransportWrapper("https://sso.example.com/token", out)(fake)
+	})
+
+	AfterEach(func() {
+		cached, refresh, ttl = false, false, DefaultTTL
+		os.Unsetenv("XDG_CACHE_HOME") // #nosec G104
+		os.RemoveAll(tmp)             // #nosec G104
+	})
+
+	It("Uses the saved response for the same request", func() {
+		for i := 0; i < 2; i++ {
+			response, err := transport.RoundTrip(makeRequest(http.MethodGet, "https://api/x?a=1", "u1"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(response.Header.Get("Content-Type")).To(Equal("application/json"))
+			Expect(readBody(response)).To(Equal(`{"id":"123"}`))
+		}
+		Expect(fake.calls).To(Equal(1))
+	})
+
+	It("Doesn't share responses between users or queries", func() {
+		requests := []*http.Request{
+			makeRequest(http.MethodGet, "https://api/x?a=1", "u1"),
+			makeRequest(http.MethodGet, "https://api/x?a=1", "u2"),
+			makeRequest(http.MethodGet, "https://api/x?a=2", "u1"),
+		}
+		for _, request := range requests {
+			response, err := transport.RoundTrip(request)
+			Expect(err).ToNot(HaveOccurred())
+			readBody(response)
+		}
+		Expect(fake.calls).To(Equal(3))
+	})
+
+	It("Doesn't cache requests other than GET", func() {
+		for i := 0; i < 2; i++ {
+			response, err := transport.RoundTrip(makeRequest(http.MethodPost, "https://api/x", "u1"))
+			Expect(err).ToNot(HaveOccurred())
+			readBody(response)
+		}
+		Expect(fake.calls).To(Equal(2))
+	})
+
+	It("Sends the request again when the saved response is too old", func() {
+		ttl = 0
+		for i := 0; i < 2; i++ {
+			response, err := transport.RoundTrip(makeRequest(http.MethodGet, "https://api/x", "u1"))
+			Expect(err).ToNot(HaveOccurred())
+			readBody(response)
+			time.Sleep(time.Millisecond)
+		}
+		Expect(fake.calls).To(Equal(2))
+	})
+
+	It("Always sends the request with refresh", func() {
+		cached, refresh = false, true
+		for i := 0; i < 2; i++ {
+			response, err := transport.RoundTrip(makeRequest(http.MethodGet, "https://api/x", "u1"))
+			Expect(err).ToNot(HaveOccurred())
+			readBody(response)
+		}
+		Expect(fake.calls).To(Equal(2))
+	})
+
+	It("Uses an old response when the server can't be reached", func() {
+		cached, refresh = false, true
+		response, err := transport.RoundTrip(makeRequest(http.MethodGet, "https://api/x", "u1"))
+		Expect(err).ToNot(HaveOccurred())
+		readBody(response)
+
+		cached, refresh, ttl = true, false, 0
+		fake.err = errors.New("connection refused")
+		time.Sleep(time.Millisecond)
+		response, err = transport.RoundTrip(makeRequest(http.MethodGet, "https://api/x", "u1"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readBody(response)).To(Equal(`{"id":"123"}`))
+		Expect(out.String()).To(ContainSubstring("WARNING: Can't send request to '/x'"))
+		Expect(out.String()).To(ContainSubstring("connection refused"))
+	})
+
+	It("Returns the error when there is no saved response", func() {
+		fake.err = errors.New("connection refused")
+		_, err := transport.RoundTrip(makeRequest(http.MethodGet, "https://api/x", "u1"))
+		Expect(err).To(MatchError("connection refused"))
+	})
+
+	It("Removes the saved responses with clear", func() {
+		response, err := transport.RoundTrip(makeRequest(http.MethodGet, "https://api/x", "u1"))
+		Expect(err).ToNot(HaveOccurred())
+		readBody(response)
+		Expect(Clear()).To(Succeed())
+		response, err = transport.RoundTrip(makeRequest(http.MethodGet, "https://api/x", "u1"))
+		Expect(err).ToNot(HaveOccurred())
+		readBody(response)
+		Expect(fake.calls).To(Equal(2))
+	})
+})
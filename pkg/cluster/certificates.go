@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// Names of the endpoints of a cluster that have certificates:
+const (
+	CertificateEndpointAPI     = "api"
+	CertificateEndpointIngress = "ingress"
+)
+
+// Certificate contains the metadata of the certificate presented by one of the endpoints of a
+// cluster. Note that the field names are part of the JSON output of the commands, so don't change
+// them without considering the consumers of that output.
+type Certificate struct {
+	Endpoint  string     `json:"endpoint"`
+	Host      string     `json:"host"`
+	Subject   string     `json:"subject,omitempty"`
+	Issuer    string     `json:"issuer,omitempty"`
+	NotBefore *time.Time `json:"not_before,omitempty"`
+	NotAfter  *time.Time `json:"not_after,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// DaysLeft returns the number of whole days left until the certificate expires, which will be
+// negative if it already expired.
+func (c *Certificate) DaysLeft(now time.Time) int {
+	if c.NotAfter == nil {
+		return 0
+	}
+	return int(c.NotAfter.Sub(now).Hours() / 24)
+}
+
+// GetCertificates returns the metadata of the certificates of the API and ingress endpoints of the
+// given cluster. The API doesn't store this information, so it is obtained connecting to the
+// addresses of the endpoints, the API URL and the console URL. Endpoints that can't be reached,
+// for example because the cluster is private, are returned with the error message.
+func GetCertificates(cluster *cmv1.Cluster, timeout time.Duration) []*Certificate {
+	result := []*Certificate{}
+	endpoints := []struct {
+		name string
+		url  string
+		port string
+	}{
+		{CertificateEndpointAPI, cluster.API().URL(), "6443"},
+		{CertificateEndpointIngress, cluster.Console().URL(), "443"},
+	}
+	for _, endpoint := range endpoints {
+		if endpoint.url == "" {
+			continue
+		}
+		certificate := &Certificate{
+			Endpoint: endpoint.name,
+		}
+		parsed, err := url.Parse(endpoint.url)
+		if err != nil || parsed.Hostname() == "" {
+			certificate.Host = endpoint.url
+			certificate.Error = fmt.Sprintf("Can't parse URL '%s'", endpoint.url)
+			result = append(result, certificate)
+			continue
+		}
+		certificate.Host = parsed.Hostname()
+		port := parsed.Port()
+		if port == "" {
+			port = endpoint.port
+		}
+		err = getCertificate(certificate, net.JoinHostPort(parsed.Hostname(), port), timeout)
+		if err != nil {
+			certificate.Error = err.Error()
+		}
+		result = append(result, certificate)
+	}
+	return result
+}
+
+// getCertificate connects to the given address and fills the certificate with the metadata of the
+// leaf certificate presented by the server.
+func getCertificate(certificate *Certificate, address string, timeout time.Duration) error {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+	}
+	// The certificate isn't verified because the purpose is to report its metadata, even if it
+	// is self signed or already expired. No data is sent over this connection.
+	// #nosec G402
+	config := &tls.Config{
+		ServerName:         certificate.Host,
+		InsecureSkipVerify: true,
+	}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, config)
+	if err != nil {
+		return fmt.Errorf("Can't connect to '%s': %v", address, err)
+	}
+	defer conn.Close()
+	peers := conn.ConnectionState().PeerCertificates
+	if len(peers) == 0 {
+		return fmt.Errorf("Server '%s' didn't present a certificate", address)
+	}
+	leaf := peers[0]
+	certificate.Subject = leaf.Subject.String()
+	certificate.Issuer = leaf.Issuer.String()
+	notBefore := leaf.NotBefore.UTC()
+	notAfter := leaf.NotAfter.UTC()
+	certificate.NotBefore = &notBefore
+	certificate.NotAfter = &notAfter
+	return nil
+}
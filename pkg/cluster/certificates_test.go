@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Certificates", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.NotFoundHandler())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Gets the certificates of the API and ingress endpoints", func() {
+		cluster, err := cmv1.NewCluster().
+			API(cmv1.NewClusterAPI().URL(server.URL)).
+			Console(cmv1.NewClusterConsole().URL(server.URL + "/console")).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		certificates := GetCertificates(cluster, time.Second)
+		Expect(certificates).To(HaveLen(2))
+		Expect(certificates[0].Endpoint).To(Equal(CertificateEndpointAPI))
+		Expect(certificates[1].Endpoint).To(Equal(CertificateEndpointIngress))
+		expected := server.Certificate()
+		for _, certificate := range certificates {
+			Expect(certificate.Error).To(BeEmpty())
+			Expect(certificate.Host).To(Equal("127.0.0.1"))
+			Expect(certificate.Issuer).To(Equal(expected.Issuer.String()))
+			Expect(certificate.NotAfter).ToNot(BeNil())
+			Expect(certificate.NotAfter.Equal(expected.NotAfter)).To(BeTrue())
+		}
+	})
+
+	It("Skips endpoints without URL", func() {
+		cluster, err := cmv1.NewCluster().
+			API(cmv1.NewClusterAPI().URL(server.URL)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		certificates := GetCertificates(cluster, time.Second)
+		Expect(certificates).To(HaveLen(1))
+		Expect(certificates[0].Endpoint).To(Equal(CertificateEndpointAPI))
+	})
+
+	It("Reports endpoints that can't be reached", func() {
+		address := server.URL
+		server.Close()
+		cluster, err := cmv1.NewCluster().
+			API(cmv1.NewClusterAPI().URL(address)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		certificates := GetCertificates(cluster, time.Second)
+		Expect(certificates).To(HaveLen(1))
+		Expect(certificates[0].Error).To(ContainSubstring("Can't connect to"))
+		Expect(certificates[0].NotAfter).To(BeNil())
+	})
+
+	It("Calculates the days left", func() {
+		now := time.Now()
+		notAfter := now.Add(36 * time.Hour)
+		certificate := &Certificate{
+			NotAfter: &notAfter,
+		}
+		Expect(certificate.DaysLeft(now)).To(Equal(1))
+	})
+})
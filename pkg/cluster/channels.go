@@ -0,0 +1,119 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// Channel groups with special support implications:
+const (
+	ChannelGroupStable    = "stable"
+	ChannelGroupFast      = "fast"
+	ChannelGroupCandidate = "candidate"
+	ChannelGroupNightly   = "nightly"
+)
+
+// CheckChannelGroup checks that the given cluster can be moved to the given channel group. That is
+// possible when the channel group contains the current version of the cluster, or at least one of
+// the versions it can be upgraded to. It returns warnings that should be shown to the user before
+// the change, for example about the support implications of the channel group.
+func CheckChannelGroup(client *cmv1.Client, cluster *cmv1.Cluster, channelGroup string) (
+	warnings []string, err error) {
+	current := cluster.Version().ChannelGroup()
+	if current == "" {
+		current = ChannelGroupStable
+	}
+	if channelGroup == current {
+		return
+	}
+	switch channelGroup {
+	case ChannelGroupFast:
+		warnings = append(warnings, fmt.Sprintf(
+			"Versions in the '%s' channel group are supported, but they are available "+
+				"before they are promoted to the '%s' channel group",
+			ChannelGroupFast, ChannelGroupStable,
+		))
+	case ChannelGroupCandidate, ChannelGroupNightly:
+		warnings = append(warnings, fmt.Sprintf(
+			"Versions in the '%s' channel group aren't supported, don't use it for "+
+				"production clusters",
+			channelGroup,
+		))
+	}
+
+	// Check if the channel group contains the current version:
+	version := cluster.OpenshiftVersion()
+	if version == "" {
+		version = cluster.Version().RawID()
+	}
+	if version == "" {
+		err = fmt.Errorf("Can't determine the version of cluster '%s'", cluster.Name())
+		return
+	}
+	found, err := versionEnabled(client, createVersionID(version, channelGroup))
+	if err != nil || found {
+		return
+	}
+
+	// Check if the channel group contains any of the versions that the cluster can be upgraded
+	// to, as then it will be able to leave the current version:
+	upgrades, err := GetAvailableUpgrades(client, GetVersionID(cluster), cluster.Product().ID())
+	if err != nil {
+		return
+	}
+	paths := []string{}
+	for _, upgrade := range upgrades {
+		found, err = versionEnabled(client, createVersionID(upgrade, channelGroup))
+		if err != nil {
+			return
+		}
+		if found {
+			paths = append(paths, upgrade)
+		}
+	}
+	if len(paths) == 0 {
+		err = fmt.Errorf(
+			"Channel group '%s' doesn't contain version '%s' of cluster '%s' or any of "+
+				"the versions it can be upgraded to",
+			channelGroup, version, cluster.Name(),
+		)
+		return
+	}
+	warnings = append(warnings, fmt.Sprintf(
+		"Channel group '%s' doesn't contain the current version '%s', but it contains "+
+			"the versions it can be upgraded to: %s",
+		channelGroup, version, strings.Join(paths, ", "),
+	))
+	return
+}
+
+// versionEnabled checks if the version with the given identifier exists and is enabled.
+func versionEnabled(client *cmv1.Client, versionID string) (bool, error) {
+	response, err := client.Versions().Version(versionID).Get().Send()
+	if response != nil && response.Status() == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("Failed to get version '%s': %v", versionID, err)
+	}
+	return response.Body().Enabled(), nil
+}
@@ -57,6 +57,7 @@ type Spec struct {
 	// Scaling config
 	ComputeMachineType string
 	ComputeNodes       int
+	ComputeLabels      map[string]string
 	Autoscaling        Autoscaling
 
 	// Network config
@@ -357,13 +358,16 @@ func CreateCluster(cmv1Client *cmv1.Client, config Spec, dryRun bool) (*cmv1.Clu
 	}
 
 	if config.ComputeMachineType != "" || config.ComputeNodes > 0 || len(config.ExistingVPC.AvailabilityZones) > 0 ||
-		config.Autoscaling.Enabled {
+		config.Autoscaling.Enabled || len(config.ComputeLabels) > 0 {
 		clusterNodesBuilder := cmv1.NewClusterNodes()
 		if config.ComputeMachineType != "" {
 			clusterNodesBuilder = clusterNodesBuilder.ComputeMachineType(
 				cmv1.NewMachineType().ID(config.ComputeMachineType),
 			)
 		}
+		if len(config.ComputeLabels) > 0 {
+			clusterNodesBuilder = clusterNodesBuilder.ComputeLabels(config.ComputeLabels)
+		}
 		clusterNodesBuilder = buildCompute(config, clusterNodesBuilder)
 
 		if len(config.ExistingVPC.AvailabilityZones) > 0 {
@@ -387,9 +391,9 @@ func CreateCluster(cmv1Client *cmv1.Client, config Spec, dryRun bool) (*cmv1.Clu
 	response, err := request.Send()
 	if err != nil {
 		if dryRun {
-			return nil, fmt.Errorf("dry run: unable to create cluster: %v", err)
+			return nil, fmt.Errorf("dry run: unable to create cluster: %w", err)
 		}
-		return nil, fmt.Errorf("unable to create cluster: %v", err)
+		return nil, fmt.Errorf("unable to create cluster: %w", err)
 	}
 
 	if response.Status() == http.StatusNoContent {
@@ -503,6 +507,23 @@ func GetIdentityProviders(client *cmv1.ClustersClient, clusterID string) ([]*cmv
 	return response.Items().Slice(), nil
 }
 
+func GetHTPasswdUsers(client *cmv1.ClustersClient, clusterID, idpID string) ([]*cmv1.HTPasswdUser, error) {
+	response, err := client.Cluster(clusterID).
+		IdentityProviders().
+		IdentityProvider(idpID).
+		HtpasswdUsers().
+		List().
+		Page(1).
+		Size(-1).
+		Send()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get users of identity provider '%s' for cluster '%s': %v",
+			idpID, clusterID, err)
+	}
+
+	return response.Items().Slice(), nil
+}
+
 func GetIngresses(client *cmv1.ClustersClient, clusterID string) ([]*cmv1.Ingress, error) {
 	ingressClient := client.Cluster(clusterID).Ingresses()
 	response, err := ingressClient.List().
@@ -555,6 +576,15 @@ func GetUpgradePolicies(client *cmv1.ClustersClient, clusterID string) ([]*cmv1.
 	return response.Items().Slice(), nil
 }
 
+// GetProvisionShard returns the provision shard where the given cluster runs.
+func GetProvisionShard(client *cmv1.ClustersClient, clusterID string) (*cmv1.ProvisionShard, error) {
+	response, err := client.Cluster(clusterID).ProvisionShard().Get().Send()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get provision shard for cluster '%s': %v", clusterID, err)
+	}
+	return response.Body(), nil
+}
+
 func GetClusterAddOns(connection *sdk.Connection, clusterID string) ([]*AddOnItem, error) {
 	// Get organization ID (used to get add-on quotas)
 	acctResponse, err := connection.AccountsMgmt().V1().CurrentAccount().
@@ -18,6 +18,8 @@ package cluster
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	sdk "github.com/openshift-online/ocm-sdk-go"
@@ -29,7 +31,14 @@ const (
 	notAvailable string = "N/A"
 )
 
+// PrintClusterDescription writes the description of the cluster to the standard output.
 func PrintClusterDescription(connection *sdk.Connection, cluster *cmv1.Cluster) error {
+	return WriteClusterDescription(os.Stdout, connection, cluster)
+}
+
+// WriteClusterDescription writes the description of the cluster to the given writer.
+func WriteClusterDescription(out io.Writer, connection *sdk.Connection,
+	cluster *cmv1.Cluster) error {
 	// Get API URL:
 	api := cluster.API()
 	apiURL, _ := api.GetURL()
@@ -158,7 +167,7 @@ func PrintClusterDescription(connection *sdk.Connection, cluster *cmv1.Cluster)
 	}
 
 	// Print short cluster description:
-	fmt.Printf("\n"+
+	fmt.Fprintf(out, "\n"+
 		"ID:			%s\n"+
 		"External ID:		%s\n"+
 		"Name:			%s\n"+
@@ -169,12 +178,12 @@ func PrintClusterDescription(connection *sdk.Connection, cluster *cmv1.Cluster)
 		cluster.State(),
 	)
 	if cluster.Status().State() == cmv1.ClusterStateError {
-		fmt.Printf("Details:		%s - %s\n",
+		fmt.Fprintf(out, "Details:		%s - %s\n",
 			cluster.Status().ProvisionErrorCode(),
 			cluster.Status().ProvisionErrorMessage(),
 		)
 	}
-	fmt.Printf("API URL:		%s\n"+
+	fmt.Fprintf(out, "API URL:		%s\n"+
 		"API Listening:		%s\n"+
 		"Console URL:		%s\n"+
 		"Masters:		%d\n"+
@@ -224,31 +233,38 @@ func PrintClusterDescription(connection *sdk.Connection, cluster *cmv1.Cluster)
 		cluster.ExpirationTimestamp().Round(time.Second).Format(time.RFC3339Nano),
 	)
 	if shard != "" {
-		fmt.Printf("Shard:			%v\n", shard)
+		fmt.Fprintf(out, "Shard:			%v\n", shard)
 	}
 	if cluster.Proxy().HTTPProxy() != "" {
-		fmt.Printf("HTTPProxy:	        %s\n", cluster.Proxy().HTTPProxy())
+		fmt.Fprintf(out, "HTTPProxy:	        %s\n", cluster.Proxy().HTTPProxy())
 	}
 	if cluster.Proxy().HTTPSProxy() != "" {
-		fmt.Printf("HTTPSProxy:	        %s\n", cluster.Proxy().HTTPSProxy())
+		fmt.Fprintf(out, "HTTPSProxy:	        %s\n", cluster.Proxy().HTTPSProxy())
 	}
 	if cluster.AdditionalTrustBundle() != "" {
-		fmt.Printf("AdditionalTrustBundle:  %s\n", cluster.AdditionalTrustBundle())
+		fmt.Fprintf(out, "AdditionalTrustBundle:  %s\n", cluster.AdditionalTrustBundle())
 	}
 	if cluster.GCPNetwork().VPCName() != "" {
-		fmt.Printf("VPC-Name:	        %s\n", cluster.GCPNetwork().VPCName())
+		fmt.Fprintf(out, "VPC-Name:	        %s\n", cluster.GCPNetwork().VPCName())
 	}
 	if cluster.GCPNetwork().ControlPlaneSubnet() != "" {
-		fmt.Printf("Control-Plane-Subnet:   %s\n", cluster.GCPNetwork().ControlPlaneSubnet())
+		fmt.Fprintf(out, "Control-Plane-Subnet:   %s\n", cluster.GCPNetwork().ControlPlaneSubnet())
 	}
 	if cluster.GCPNetwork().ComputeSubnet() != "" {
-		fmt.Printf("Compute-Subnet:	        %s\n", cluster.GCPNetwork().ComputeSubnet())
+		fmt.Fprintf(out, "Compute-Subnet:	        %s\n", cluster.GCPNetwork().ComputeSubnet())
 	}
 	if cluster.Status().LimitedSupportReasonCount() > 0 {
-		fmt.Printf("Limited Support:	%t\n", cluster.Status().LimitedSupportReasonCount() > 0)
+		fmt.Fprintf(out, "Limited Support:	%t\n", cluster.Status().LimitedSupportReasonCount() > 0)
+	}
+	notes := NotesFromLabels(sub.Labels())
+	if len(notes) > 0 {
+		fmt.Fprintf(out, "Notes:\n")
+		for _, note := range notes {
+			fmt.Fprintf(out, "  %s  %s\n", note.CreatedAt.UTC().Format(time.RFC3339), note.Text)
+		}
 	}
 
-	fmt.Println()
+	fmt.Fprintln(out)
 
 	return nil
 }
@@ -0,0 +1,139 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// ClusterDetails contains the details of a cluster that the version of the SDK that we use
+// doesn't support yet.
+type ClusterDetails struct {
+	ID         string                 `json:"id,omitempty"`
+	AWS        *ClusterAWSDetails     `json:"aws,omitempty"`
+	Hypershift *ClusterHypershiftInfo `json:"hypershift,omitempty"`
+}
+
+// ClusterAWSDetails contains the AWS specific details of a cluster that the version of the SDK
+// that we use doesn't support yet.
+type ClusterAWSDetails struct {
+	EC2MetadataHTTPTokens string `json:"ec2_metadata_http_tokens,omitempty"`
+}
+
+// ClusterHypershiftInfo indicates if the cluster has a hosted control plane, and where that
+// control plane runs.
+type ClusterHypershiftInfo struct {
+	Enabled           bool   `json:"enabled,omitempty"`
+	HCPNamespace      string `json:"hcp_namespace,omitempty"`
+	ManagementCluster string `json:"management_cluster,omitempty"`
+}
+
+// EC2MetadataHTTPTokens returns the IMDSv2 setting of the cluster, which is used by default for
+// its machine pools.
+func (d *ClusterDetails) EC2MetadataHTTPTokens() string {
+	if d == nil || d.AWS == nil || d.AWS.EC2MetadataHTTPTokens == "" {
+		return EC2MetadataHTTPTokensOptional
+	}
+	return d.AWS.EC2MetadataHTTPTokens
+}
+
+// Hosted checks if the cluster has a hosted control plane.
+func (d *ClusterDetails) Hosted() bool {
+	return d != nil && d.Hypershift != nil && d.Hypershift.Enabled
+}
+
+// ManagementCluster returns the name of the management cluster that runs the hosted control plane
+// of the cluster, or the empty string if the cluster doesn't have a hosted control plane.
+func (d *ClusterDetails) ManagementCluster() string {
+	if !d.Hosted() {
+		return ""
+	}
+	return d.Hypershift.ManagementCluster
+}
+
+// HCPNamespace returns the namespace of the management cluster that contains the hosted control
+// plane of the cluster.
+func (d *ClusterDetails) HCPNamespace() string {
+	if !d.Hosted() {
+		return ""
+	}
+	return d.Hypershift.HCPNamespace
+}
+
+// GetClusterDetails retrieves the details that the SDK doesn't support of the given cluster.
+func GetClusterDetails(connection *sdk.Connection, clusterID string) (*ClusterDetails, error) {
+	body, err := sendRawRequest(
+		connection.Get().Path("/api/clusters_mgmt/v1/clusters/" + url.PathEscape(clusterID)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get cluster '%s': %v", clusterID, err)
+	}
+	details := &ClusterDetails{}
+	err = json.Unmarshal(body, details)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse cluster '%s': %v", clusterID, err)
+	}
+	return details, nil
+}
+
+// clusterDetailsPage is used to decode a page of the list of clusters twice: once with the SDK
+// and once to get the details that the SDK doesn't support.
+type clusterDetailsPage struct {
+	Size  int             `json:"size"`
+	Items json.RawMessage `json:"items"`
+}
+
+// ListClustersWithDetails retrieves one page of the clusters that match the given search
+// criteria. It returns the clusters, the details that the SDK doesn't support for each of them,
+// in the same order, and the size of the page.
+func ListClustersWithDetails(connection *sdk.Connection, search string, page,
+	size int) ([]*cmv1.Cluster, []*ClusterDetails, int, error) {
+	request := connection.Get().
+		Path("/api/clusters_mgmt/v1/clusters").
+		Parameter("page", page).
+		Parameter("size", size)
+	if search != "" {
+		request.Parameter("search", search)
+	}
+	body, err := sendRawRequest(request)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("Can't retrieve clusters: %v", err)
+	}
+	list := &clusterDetailsPage{}
+	err = json.Unmarshal(body, list)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("Can't parse clusters: %v", err)
+	}
+	if len(list.Items) == 0 {
+		return nil, nil, list.Size, nil
+	}
+	clusters, err := cmv1.UnmarshalClusterList([]byte(list.Items))
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("Can't parse clusters: %v", err)
+	}
+	details := []*ClusterDetails{}
+	err = json.Unmarshal(list.Items, &details)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("Can't parse clusters: %v", err)
+	}
+	return clusters, details, list.Size, nil
+}
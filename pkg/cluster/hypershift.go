@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// FleetCluster is a management or service cluster of the hosted control planes, as returned by
+// the fleet management API. These clusters are only visible to privileged users.
+type FleetCluster struct {
+	ID            string        `json:"id,omitempty"`
+	Kind          string        `json:"kind,omitempty"`
+	Name          string        `json:"name,omitempty"`
+	Region        string        `json:"region,omitempty"`
+	CloudProvider string        `json:"cloud_provider,omitempty"`
+	Sector        string        `json:"sector,omitempty"`
+	Status        string        `json:"status,omitempty"`
+	Parent        *FleetCluster `json:"parent,omitempty"`
+}
+
+// ServiceCluster returns the name of the service cluster that manages the management cluster.
+func (f *FleetCluster) ServiceCluster() string {
+	if f == nil || f.Parent == nil {
+		return ""
+	}
+	return f.Parent.Name
+}
+
+// fleetClusterPage is used to decode a page of management or service clusters.
+type fleetClusterPage struct {
+	Items []*FleetCluster `json:"items"`
+}
+
+// GetManagementCluster retrieves the management cluster with the given name from the fleet
+// management API.
+func GetManagementCluster(connection *sdk.Connection, name string) (*FleetCluster, error) {
+	if !IsValidClusterKey(name) {
+		return nil, fmt.Errorf(
+			"Management cluster name '%s' isn't valid: it must contain only letters, "+
+				"digits, dashes and underscores",
+			name,
+		)
+	}
+	body, err := sendRawRequest(
+		connection.Get().
+			Path("/api/osd_fleet_mgmt/v1/management_clusters").
+			Parameter("search", fmt.Sprintf("name = '%s'", name)).
+			Parameter("size", 1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Can't retrieve management cluster '%s': %v", name, err)
+	}
+	page := &fleetClusterPage{}
+	err = json.Unmarshal(body, page)
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse management cluster '%s': %v", name, err)
+	}
+	if len(page.Items) == 0 {
+		return nil, fmt.Errorf("Management cluster '%s' doesn't exist", name)
+	}
+	return page.Items[0], nil
+}
+
+// HostedClustersSearch returns the search criteria that select the clusters whose hosted control
+// planes run in the given management cluster.
+func HostedClustersSearch(managementCluster string) string {
+	return fmt.Sprintf(
+		"hypershift.enabled = 'true' and hypershift.management_cluster = '%s'",
+		managementCluster,
+	)
+}
+
+// ListHostedClusters retrieves all the clusters whose hosted control planes run in the given
+// management cluster, and the details that the SDK doesn't support for each of them.
+func ListHostedClusters(connection *sdk.Connection,
+	managementCluster string) ([]*cmv1.Cluster, []*ClusterDetails, error) {
+	search := HostedClustersSearch(managementCluster)
+	clusters := []*cmv1.Cluster{}
+	details := []*ClusterDetails{}
+	size := 100
+	for page := 1; ; page++ {
+		pageClusters, pageDetails, _, err := ListClustersWithDetails(connection, search, page, size)
+		if err != nil {
+			return nil, nil, err
+		}
+		clusters = append(clusters, pageClusters...)
+		details = append(details, pageDetails...)
+		if len(pageClusters) < size {
+			return clusters, details, nil
+		}
+	}
+}
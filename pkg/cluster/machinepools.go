@@ -0,0 +1,233 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// Values of the setting that controls if the instances of a machine pool require version 2 of the
+// AWS instance metadata service (IMDSv2). When the server doesn't return the setting it is
+// optional.
+const (
+	EC2MetadataHTTPTokensOptional = "optional"
+	EC2MetadataHTTPTokensRequired = "required"
+)
+
+// MachinePoolDetails contains the details of a machine pool that the version of the SDK that we
+// use doesn't support yet.
+type MachinePoolDetails struct {
+	Subnets []string               `json:"subnets,omitempty"`
+	Version *NodePoolVersion       `json:"version,omitempty"`
+	Status  *NodePoolStatus        `json:"status,omitempty"`
+	AWS     *MachinePoolAWSDetails `json:"aws,omitempty"`
+}
+
+// MachinePoolAWSDetails contains the AWS specific details of a machine pool that the version of the
+// SDK that we use doesn't support yet.
+type MachinePoolAWSDetails struct {
+	EC2MetadataHTTPTokens string `json:"ec2_metadata_http_tokens,omitempty"`
+}
+
+// EC2MetadataHTTPTokens returns the IMDSv2 setting of the machine pool.
+func (d *MachinePoolDetails) EC2MetadataHTTPTokens() string {
+	if d == nil || d.AWS == nil || d.AWS.EC2MetadataHTTPTokens == "" {
+		return EC2MetadataHTTPTokensOptional
+	}
+	return d.AWS.EC2MetadataHTTPTokens
+}
+
+// machinePoolDetailsList is used to decode the details of the items of the list of machine pools.
+type machinePoolDetailsList struct {
+	Items []*struct {
+		ID string `json:"id"`
+		MachinePoolDetails
+	} `json:"items"`
+}
+
+// GetMachinePoolsDetails retrieves the details that the SDK doesn't support of all the machine
+// pools of the given cluster, indexed by machine pool identifier.
+func GetMachinePoolsDetails(connection *sdk.Connection, clusterID string) (
+	map[string]*MachinePoolDetails, error) {
+	body, err := sendRawRequest(
+		connection.Get().
+			Path(fmt.Sprintf(
+				"/api/clusters_mgmt/v1/clusters/%s/machine_pools",
+				url.PathEscape(clusterID),
+			)).
+			Parameter("size", -1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get machine pools for cluster '%s': %v", clusterID, err)
+	}
+	list := &machinePoolDetailsList{}
+	err = json.Unmarshal(body, list)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse machine pools for cluster '%s': %v", clusterID, err)
+	}
+	result := make(map[string]*MachinePoolDetails, len(list.Items))
+	for _, item := range list.Items {
+		details := item.MachinePoolDetails
+		result[item.ID] = &details
+	}
+	return result, nil
+}
+
+// GetMachinePool retrieves the machine pool with the given identifier. It returns the machine pool,
+// the details that the SDK doesn't support, and the raw JSON document returned by the server.
+func GetMachinePool(connection *sdk.Connection, clusterID, machinePoolID string) (*cmv1.MachinePool,
+	*MachinePoolDetails, []byte, error) {
+	path := fmt.Sprintf(
+		"/api/clusters_mgmt/v1/clusters/%s/machine_pools/%s",
+		url.PathEscape(clusterID), url.PathEscape(machinePoolID),
+	)
+	body, err := sendRawRequest(connection.Get().Path(path))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf(
+			"Failed to get machine pool '%s' for cluster '%s': %v",
+			machinePoolID, clusterID, err,
+		)
+	}
+	machinePool, err := cmv1.UnmarshalMachinePool(body)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("Failed to parse machine pool '%s': %v", machinePoolID, err)
+	}
+	details := &MachinePoolDetails{}
+	err = json.Unmarshal(body, details)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("Failed to parse machine pool '%s': %v", machinePoolID, err)
+	}
+	return machinePool, details, body, nil
+}
+
+// AddMachinePool creates the given machine pool. The details that the version of the SDK that we use
+// doesn't support are added to the body of the request.
+func AddMachinePool(connection *sdk.Connection, clusterID string, machinePool *cmv1.MachinePool,
+	details *MachinePoolDetails) error {
+	body, err := machinePoolBody(machinePool, details)
+	if err != nil {
+		return err
+	}
+	_, err = sendRawRequest(
+		connection.Post().
+			Path(fmt.Sprintf(
+				"/api/clusters_mgmt/v1/clusters/%s/machine_pools",
+				url.PathEscape(clusterID),
+			)).
+			Bytes(body),
+	)
+	return err
+}
+
+// UpdateMachinePool updates the given machine pool. The details that the version of the SDK that
+// we use doesn't support are added to the body of the request.
+func UpdateMachinePool(connection *sdk.Connection, clusterID string, machinePool *cmv1.MachinePool,
+	details *MachinePoolDetails) error {
+	body, err := machinePoolBody(machinePool, details)
+	if err != nil {
+		return err
+	}
+	_, err = sendRawRequest(
+		connection.Patch().
+			Path(fmt.Sprintf(
+				"/api/clusters_mgmt/v1/clusters/%s/machine_pools/%s",
+				url.PathEscape(clusterID), url.PathEscape(machinePool.ID()),
+			)).
+			Bytes(body),
+	)
+	return err
+}
+
+// machinePoolBody generates the JSON document for the given machine pool, merging the fields
+// generated by the SDK with the given details.
+func machinePoolBody(machinePool *cmv1.MachinePool, details *MachinePoolDetails) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	err := cmv1.MarshalMachinePool(machinePool, buffer)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]interface{}{}
+	err = json.Unmarshal(buffer.Bytes(), &body)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	extra := map[string]interface{}{}
+	err = json.Unmarshal(data, &extra)
+	if err != nil {
+		return nil, err
+	}
+	mergeObjects(body, extra)
+	return json.Marshal(body)
+}
+
+// mergeObjects copies the fields of the source object to the target object, merging the fields
+// that are objects in both.
+func mergeObjects(target, source map[string]interface{}) {
+	for key, value := range source {
+		targetObject, targetOK := target[key].(map[string]interface{})
+		sourceObject, sourceOK := value.(map[string]interface{})
+		if targetOK && sourceOK {
+			mergeObjects(targetObject, sourceObject)
+			continue
+		}
+		target[key] = value
+	}
+}
+
+// PrintLabels returns a comma separated list of the given labels, sorted by key.
+func PrintLabels(labels map[string]string) string {
+	output := make([]string, 0, len(labels))
+	for key, value := range labels {
+		output = append(output, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(output)
+	return strings.Join(output, ", ")
+}
+
+// PrintTaints returns a comma separated list of the given machine pool taints.
+func PrintTaints(taints []*cmv1.Taint) string {
+	output := make([]string, 0, len(taints))
+	for _, taint := range taints {
+		output = append(output, printTaint(taint.Key(), taint.Value(), taint.Effect()))
+	}
+	return strings.Join(output, ", ")
+}
+
+// PrintNodePoolTaints returns a comma separated list of the given node pool taints.
+func PrintNodePoolTaints(taints []*NodePoolTaint) string {
+	output := make([]string, 0, len(taints))
+	for _, taint := range taints {
+		output = append(output, printTaint(taint.Key, taint.Value, taint.Effect))
+	}
+	return strings.Join(output, ", ")
+}
+
+func printTaint(key, value, effect string) string {
+	return fmt.Sprintf("%s=%s:%s", key, value, effect)
+}
@@ -0,0 +1,180 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// Names of the metadata fields compared by CompareMetadata. Labels use the 'label:' prefix
+// followed by the key of the label.
+const (
+	MetadataClusterID   = "cluster_id"
+	MetadataDisplayName = "display_name"
+	MetadataExternalID  = "external_cluster_id"
+	metadataLabelPrefix = "label:"
+)
+
+// MetadataDrift is a difference between the metadata of a cluster and the metadata of its
+// subscription. The cluster is the source of truth, so fixing the drift means changing the
+// subscription.
+type MetadataDrift struct {
+	Field             string
+	ClusterValue      string
+	SubscriptionValue string
+
+	// Fixable indicates if the subscription can be changed to fix the drift. The identifier of
+	// the cluster, for example, can't be changed.
+	Fixable bool
+
+	// labelKey is the key of the label, for drifts in labels, and labelExists indicates if the
+	// subscription already has that label.
+	labelKey    string
+	labelExists bool
+}
+
+// CompareMetadata compares the name and the identifiers of the cluster with the corresponding
+// fields of the subscription. For each of the given keys it also compares the value of the
+// property of the cluster with that key with the value of the subscription label with the same
+// key. Keys of properties that the cluster doesn't have are ignored.
+func CompareMetadata(cluster *cmv1.Cluster, subscription *amv1.Subscription, labels []*amv1.Label,
+	keys []string) []*MetadataDrift {
+	drifts := []*MetadataDrift{}
+	if cluster.ID() != subscription.ClusterID() {
+		drifts = append(drifts, &MetadataDrift{
+			Field:             MetadataClusterID,
+			ClusterValue:      cluster.ID(),
+			SubscriptionValue: subscription.ClusterID(),
+		})
+	}
+	if cluster.Name() != subscription.DisplayName() {
+		drifts = append(drifts, &MetadataDrift{
+			Field:             MetadataDisplayName,
+			ClusterValue:      cluster.Name(),
+			SubscriptionValue: subscription.DisplayName(),
+			Fixable:           true,
+		})
+	}
+	if cluster.ExternalID() != "" && cluster.ExternalID() != subscription.ExternalClusterID() {
+		drifts = append(drifts, &MetadataDrift{
+			Field:             MetadataExternalID,
+			ClusterValue:      cluster.ExternalID(),
+			SubscriptionValue: subscription.ExternalClusterID(),
+			Fixable:           true,
+		})
+	}
+	values := map[string]string{}
+	for _, label := range labels {
+		values[label.Key()] = label.Value()
+	}
+	sorted := make([]string, len(keys))
+	copy(sorted, keys)
+	sort.Strings(sorted)
+	properties := cluster.Properties()
+	for _, key := range sorted {
+		expected, ok := properties[key]
+		if !ok {
+			continue
+		}
+		actual, exists := values[key]
+		if exists && actual == expected {
+			continue
+		}
+		drifts = append(drifts, &MetadataDrift{
+			Field:             metadataLabelPrefix + key,
+			ClusterValue:      expected,
+			SubscriptionValue: actual,
+			Fixable:           true,
+			labelKey:          key,
+			labelExists:       exists,
+		})
+	}
+	return drifts
+}
+
+// FixMetadata changes the subscription so that it matches the cluster, for the given drifts that
+// can be fixed.
+func FixMetadata(connection *sdk.Connection, subID string, drifts []*MetadataDrift) error {
+	subClient := connection.AccountsMgmt().V1().Subscriptions().Subscription(subID)
+	patch := amv1.NewSubscription()
+	patched := false
+	for _, drift := range drifts {
+		switch {
+		case !drift.Fixable:
+			continue
+		case drift.Field == MetadataDisplayName:
+			patch.DisplayName(drift.ClusterValue)
+			patched = true
+		case drift.Field == MetadataExternalID:
+			patch.ExternalClusterID(drift.ClusterValue)
+			patched = true
+		case drift.labelKey != "":
+			label, err := amv1.NewLabel().Key(drift.labelKey).Value(drift.ClusterValue).Build()
+			if err != nil {
+				return err
+			}
+			if drift.labelExists {
+				_, err = subClient.Labels().Labels(drift.labelKey).Update().Body(label).Send()
+			} else {
+				_, err = subClient.Labels().Add().Body(label).Send()
+			}
+			if err != nil {
+				return fmt.Errorf(
+					"Can't update label '%s' of subscription '%s': %w",
+					drift.labelKey, subID, err,
+				)
+			}
+		}
+	}
+	if !patched {
+		return nil
+	}
+	body, err := patch.Build()
+	if err != nil {
+		return err
+	}
+	_, err = subClient.Update().Body(body).Send()
+	if err != nil {
+		return fmt.Errorf("Can't update subscription '%s': %w", subID, err)
+	}
+	return nil
+}
+
+// ListSubscriptionLabels retrieves all the labels of the given subscription.
+func ListSubscriptionLabels(connection *sdk.Connection, subID string) ([]*amv1.Label, error) {
+	var labels []*amv1.Label
+	request := connection.AccountsMgmt().V1().Subscriptions().Subscription(subID).Labels().List()
+	size := 100
+	index := 1
+	for {
+		response, err := request.Size(size).Page(index).Send()
+		if err != nil {
+			return nil, fmt.Errorf("Can't retrieve labels of subscription '%s': %v", subID, err)
+		}
+		labels = append(labels, response.Items().Slice()...)
+		if response.Size() < size {
+			break
+		}
+		index++
+	}
+	return labels, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Metadata", func() {
+	makeLabels := func(values map[string]string) []*amv1.Label {
+		labels := []*amv1.Label{}
+		for key, value := range values {
+			label, err := amv1.NewLabel().Key(key).Value(value).Build()
+			Expect(err).ToNot(HaveOccurred())
+			labels = append(labels, label)
+		}
+		return labels
+	}
+
+	var cluster *cmv1.Cluster
+
+	BeforeEach(func() {
+		var err error
+		cluster, err = cmv1.NewCluster().
+			ID("123").
+			Name("my-cluster").
+			ExternalID("abc").
+			Properties(map[string]string{
+				"cost-center": "1234",
+				"team":        "payments",
+				"owner":       "me",
+			}).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Doesn't report differences when the metadata is the same", func() {
+		subscription, err := amv1.NewSubscription().
+			ClusterID("123").
+			DisplayName("my-cluster").
+			ExternalClusterID("abc").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		drifts := CompareMetadata(
+			cluster, subscription,
+			makeLabels(map[string]string{"cost-center": "1234"}),
+			[]string{"cost-center", "missing"},
+		)
+		Expect(drifts).To(BeEmpty())
+	})
+
+	It("Reports the differences", func() {
+		subscription, err := amv1.NewSubscription().
+			ClusterID("456").
+			DisplayName("old-name").
+			ExternalClusterID("def").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		drifts := CompareMetadata(
+			cluster, subscription,
+			makeLabels(map[string]string{"cost-center": "999"}),
+			[]string{"team", "cost-center"},
+		)
+		Expect(drifts).To(HaveLen(5))
+		Expect(drifts[0].Field).To(Equal(MetadataClusterID))
+		Expect(drifts[0].Fixable).To(BeFalse())
+		Expect(drifts[1].Field).To(Equal(MetadataDisplayName))
+		Expect(drifts[1].ClusterValue).To(Equal("my-cluster"))
+		Expect(drifts[1].SubscriptionValue).To(Equal("old-name"))
+		Expect(drifts[2].Field).To(Equal(MetadataExternalID))
+		Expect(drifts[3].Field).To(Equal("label:cost-center"))
+		Expect(drifts[3].ClusterValue).To(Equal("1234"))
+		Expect(drifts[3].SubscriptionValue).To(Equal("999"))
+		Expect(drifts[3].labelExists).To(BeTrue())
+		Expect(drifts[4].Field).To(Equal("label:team"))
+		Expect(drifts[4].SubscriptionValue).To(BeEmpty())
+		Expect(drifts[4].labelExists).To(BeFalse())
+	})
+})
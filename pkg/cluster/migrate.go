@@ -0,0 +1,234 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// NetworkMigration describes a day-2 change of the networking of a cluster. Empty fields mean that
+// the corresponding setting doesn't change.
+type NetworkMigration struct {
+	// API is the new listening method of the API server.
+	API cmv1.ListeningMethod
+
+	// Apps is the new listening method of the default router.
+	Apps cmv1.ListeningMethod
+
+	// Router is the listening method of an additional router that should be added, with the
+	// given route selectors.
+	Router               cmv1.ListeningMethod
+	RouterRouteSelectors map[string]string
+}
+
+// NetworkStep is one of the steps of a network migration. Steps are idempotent: the 'Done'
+// function checks if the cluster already has the configuration that the step sets, so that
+// running the migration again after a failure skips the steps that were already applied.
+type NetworkStep struct {
+	Description string
+	Done        func() (bool, error)
+	Apply       func() error
+}
+
+// PlanNetworkMigration calculates the steps needed to apply the given change to the cluster with
+// the given identifier. The additional router is added first, then the default router is
+// changed, and the API server is changed last, because making it private may cut the access of
+// the user running the migration.
+func PlanNetworkMigration(client *cmv1.ClustersClient, clusterID string,
+	migration NetworkMigration) (steps []*NetworkStep, err error) {
+	resource := client.Cluster(clusterID)
+	if migration.Router != "" {
+		steps = append(steps, &NetworkStep{
+			Description: fmt.Sprintf(
+				"Add %s router%s",
+				listeningName(migration.Router),
+				describeRouteSelectors(migration.RouterRouteSelectors),
+			),
+			Done: func() (bool, error) {
+				ingress, err := findAdditionalIngress(resource)
+				if err != nil || ingress == nil {
+					return false, err
+				}
+				if ingress.Listening() != migration.Router ||
+					!sameRouteSelectors(ingress.RouteSelectors(), migration.RouterRouteSelectors) {
+					return false, fmt.Errorf(
+						"Cluster '%s' already has additional router '%s' with a different "+
+							"configuration",
+						clusterID, ingress.ID(),
+					)
+				}
+				return true, nil
+			},
+			Apply: func() error {
+				builder := cmv1.NewIngress().
+					Default(false).
+					Listening(migration.Router)
+				if len(migration.RouterRouteSelectors) > 0 {
+					builder.RouteSelectors(migration.RouterRouteSelectors)
+				}
+				ingress, err := builder.Build()
+				if err != nil {
+					return err
+				}
+				_, err = resource.Ingresses().Add().Body(ingress).Send()
+				return err
+			},
+		})
+	}
+	if migration.Apps != "" {
+		steps = append(steps, &NetworkStep{
+			Description: fmt.Sprintf("Make the default router %s", listeningName(migration.Apps)),
+			Done: func() (bool, error) {
+				ingress, err := findDefaultIngress(resource)
+				if err != nil {
+					return false, err
+				}
+				return ingress.Listening() == migration.Apps, nil
+			},
+			Apply: func() error {
+				ingress, err := findDefaultIngress(resource)
+				if err != nil {
+					return err
+				}
+				patch, err := cmv1.NewIngress().Listening(migration.Apps).Build()
+				if err != nil {
+					return err
+				}
+				_, err = resource.Ingresses().Ingress(ingress.ID()).Update().Body(patch).Send()
+				return err
+			},
+		})
+	}
+	if migration.API != "" {
+		steps = append(steps, &NetworkStep{
+			Description: fmt.Sprintf("Make the API server %s", listeningName(migration.API)),
+			Done: func() (bool, error) {
+				response, err := resource.Get().Send()
+				if err != nil {
+					return false, err
+				}
+				return response.Body().API().Listening() == migration.API, nil
+			},
+			Apply: func() error {
+				patch, err := cmv1.NewCluster().
+					API(cmv1.NewClusterAPI().Listening(migration.API)).
+					Build()
+				if err != nil {
+					return err
+				}
+				_, err = resource.Update().Body(patch).Send()
+				return err
+			},
+		})
+	}
+	return
+}
+
+// VerifyNetworkStep waits till the 'Done' function of the step returns true, checking it with the
+// given interval, or till the timeout expires.
+func VerifyNetworkStep(step *NetworkStep, timeout, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		done, err := step.Done()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("Step '%s' wasn't applied after %s", step.Description, timeout)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func listIngresses(resource *cmv1.ClusterClient) ([]*cmv1.Ingress, error) {
+	response, err := resource.Ingresses().List().Send()
+	if err != nil {
+		return nil, fmt.Errorf("Can't retrieve routers: %v", err)
+	}
+	return response.Items().Slice(), nil
+}
+
+func findDefaultIngress(resource *cmv1.ClusterClient) (*cmv1.Ingress, error) {
+	ingresses, err := listIngresses(resource)
+	if err != nil {
+		return nil, err
+	}
+	for _, ingress := range ingresses {
+		if ingress.Default() {
+			return ingress, nil
+		}
+	}
+	return nil, fmt.Errorf("Can't find the default router")
+}
+
+func findAdditionalIngress(resource *cmv1.ClusterClient) (*cmv1.Ingress, error) {
+	ingresses, err := listIngresses(resource)
+	if err != nil {
+		return nil, err
+	}
+	for _, ingress := range ingresses {
+		if !ingress.Default() {
+			return ingress, nil
+		}
+	}
+	return nil, nil
+}
+
+func sameRouteSelectors(actual, expected map[string]string) bool {
+	if len(actual) == 0 && len(expected) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(actual, expected)
+}
+
+func describeRouteSelectors(selectors map[string]string) string {
+	if len(selectors) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" for routes matching '%s'", FormatRouteSelectors(selectors))
+}
+
+// FormatRouteSelectors returns the route selectors as a comma separated list of 'key=value'
+// pairs, sorted by key.
+func FormatRouteSelectors(selectors map[string]string) string {
+	keys := make([]string, 0, len(selectors))
+	for key := range selectors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + selectors[key]
+	}
+	return strings.Join(pairs, ",")
+}
+
+func listeningName(method cmv1.ListeningMethod) string {
+	if method == cmv1.ListeningMethodInternal {
+		return "private"
+	}
+	return "public"
+}
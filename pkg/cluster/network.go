@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"net"
+)
+
+// NetworkRange is a range of addresses used by a cluster. The name is used in the messages that
+// describe conflicts, for example "option '--machine-cidr'".
+type NetworkRange struct {
+	Name string
+	CIDR *net.IPNet
+}
+
+// reservedRange is a well known range of addresses that can't be used by the networks of
+// clusters.
+type reservedRange struct {
+	cidr        string
+	description string
+
+	// networkType is the type of network that reserves the range, or empty if the range is
+	// reserved for all types of network.
+	networkType string
+}
+
+var reservedRanges = []reservedRange{
+	{cidr: "0.0.0.0/8", description: "current network"},
+	{cidr: "127.0.0.0/8", description: "loopback addresses"},
+	{cidr: "169.254.0.0/16", description: "link-local addresses"},
+	{cidr: "224.0.0.0/4", description: "multicast addresses"},
+	{cidr: "240.0.0.0/4", description: "reserved for future use"},
+	{
+		cidr:        "100.64.0.0/16",
+		description: "used internally by OVN-Kubernetes",
+		networkType: NetworkTypeOVN,
+	},
+}
+
+// NetworkConflicts checks that the given ranges don't overlap with each other or with the well
+// known reserved ranges, and returns a message describing each conflict found. Ranges with a nil
+// CIDR are ignored. The network type is used to check the ranges reserved by some network types
+// only.
+func NetworkConflicts(ranges []NetworkRange, networkType string) []string {
+	var conflicts []string
+	for i, first := range ranges {
+		if first.CIDR == nil {
+			continue
+		}
+		for _, second := range ranges[i+1:] {
+			if second.CIDR == nil {
+				continue
+			}
+			if overlap(first.CIDR, second.CIDR) {
+				conflicts = append(conflicts, fmt.Sprintf(
+					"CIDR '%s' of %s overlaps with CIDR '%s' of %s",
+					first.CIDR, first.Name, second.CIDR, second.Name,
+				))
+			}
+		}
+		for _, reserved := range reservedRanges {
+			if reserved.networkType != "" && reserved.networkType != networkType {
+				continue
+			}
+			_, cidr, _ := net.ParseCIDR(reserved.cidr)
+			if overlap(first.CIDR, cidr) {
+				conflicts = append(conflicts, fmt.Sprintf(
+					"CIDR '%s' of %s overlaps with reserved range '%s' (%s)",
+					first.CIDR, first.Name, reserved.cidr, reserved.description,
+				))
+			}
+		}
+	}
+	return conflicts
+}
+
+// overlap checks if two ranges of addresses have any address in common. As ranges defined by
+// CIDRs are either disjoint or nested it is enough to check if one of them contains the first
+// address of the other.
+func overlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
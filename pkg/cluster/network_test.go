@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"net"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Network conflicts", func() {
+	parse := func(text string) *net.IPNet {
+		_, result, err := net.ParseCIDR(text)
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+
+	It("Accepts the default ranges", func() {
+		conflicts := NetworkConflicts([]NetworkRange{
+			{Name: "machine", CIDR: parse("10.0.0.0/16")},
+			{Name: "service", CIDR: parse("172.30.0.0/16")},
+			{Name: "pod", CIDR: parse("10.128.0.0/14")},
+		}, NetworkTypeOVN)
+		Expect(conflicts).To(BeEmpty())
+	})
+
+	It("Ignores ranges that aren't set", func() {
+		conflicts := NetworkConflicts([]NetworkRange{
+			{Name: "machine", CIDR: parse("10.0.0.0/16")},
+			{Name: "service"},
+		}, "")
+		Expect(conflicts).To(BeEmpty())
+	})
+
+	It("Reports ranges that overlap with each other", func() {
+		conflicts := NetworkConflicts([]NetworkRange{
+			{Name: "machine", CIDR: parse("10.0.0.0/16")},
+			{Name: "service", CIDR: parse("172.30.0.0/16")},
+			{Name: "pod", CIDR: parse("10.0.0.0/14")},
+		}, "")
+		Expect(conflicts).To(ConsistOf(
+			"CIDR '10.0.0.0/16' of machine overlaps with CIDR '10.0.0.0/14' of pod",
+		))
+	})
+
+	It("Reports ranges that overlap with reserved ranges", func() {
+		conflicts := NetworkConflicts([]NetworkRange{
+			{Name: "machine", CIDR: parse("169.254.0.0/20")},
+			{Name: "pod", CIDR: parse("100.64.0.0/14")},
+		}, NetworkTypeOVN)
+		Expect(conflicts).To(ConsistOf(
+			"CIDR '169.254.0.0/20' of machine overlaps with reserved range "+
+				"'169.254.0.0/16' (link-local addresses)",
+			"CIDR '100.64.0.0/14' of pod overlaps with reserved range "+
+				"'100.64.0.0/16' (used internally by OVN-Kubernetes)",
+		))
+	})
+
+	It("Checks ranges reserved by other network types only for those types", func() {
+		conflicts := NetworkConflicts([]NetworkRange{
+			{Name: "pod", CIDR: parse("100.64.0.0/14")},
+		}, NetworkTypeSDN)
+		Expect(conflicts).To(BeEmpty())
+	})
+})
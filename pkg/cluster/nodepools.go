@@ -0,0 +1,294 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	goVersion "github.com/hashicorp/go-version"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-sdk-go/errors"
+)
+
+// NodePool contains the details of a node pool of a hosted control plane cluster. The version of
+// the SDK that we use doesn't support node pools yet, so we decode the JSON documents returned by
+// the server into this type.
+type NodePool struct {
+	ID               string               `json:"id,omitempty"`
+	HREF             string               `json:"href,omitempty"`
+	Replicas         int                  `json:"replicas,omitempty"`
+	Autoscaling      *NodePoolAutoscaling `json:"autoscaling,omitempty"`
+	AutoRepair       bool                 `json:"auto_repair,omitempty"`
+	AWSNodePool      *AWSNodePool         `json:"aws_node_pool,omitempty"`
+	AvailabilityZone string               `json:"availability_zone,omitempty"`
+	Subnet           string               `json:"subnet,omitempty"`
+	Labels           map[string]string    `json:"labels,omitempty"`
+	Taints           []*NodePoolTaint     `json:"taints,omitempty"`
+	Version          *NodePoolVersion     `json:"version,omitempty"`
+	Status           *NodePoolStatus      `json:"status,omitempty"`
+}
+
+// NodePoolAutoscaling contains the autoscaling settings of a node pool.
+type NodePoolAutoscaling struct {
+	MinReplica int `json:"min_replica"`
+	MaxReplica int `json:"max_replica"`
+}
+
+// AWSNodePool contains the AWS specific settings of a node pool.
+type AWSNodePool struct {
+	InstanceType    string `json:"instance_type,omitempty"`
+	InstanceProfile string `json:"instance_profile,omitempty"`
+}
+
+// NodePoolTaint is a taint applied to the nodes of a node pool.
+type NodePoolTaint struct {
+	Key    string `json:"key,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect,omitempty"`
+}
+
+// NodePoolVersion is the version of OpenShift used by the nodes of a node pool.
+type NodePoolVersion struct {
+	ID           string `json:"id,omitempty"`
+	RawID        string `json:"raw_id,omitempty"`
+	ChannelGroup string `json:"channel_group,omitempty"`
+}
+
+// NodePoolStatus contains the current status of a node pool.
+type NodePoolStatus struct {
+	CurrentReplicas int    `json:"current_replicas"`
+	Message         string `json:"message,omitempty"`
+}
+
+// NodePoolUpgradePolicy is a policy that upgrades the nodes of a node pool independently of the
+// control plane of the cluster.
+type NodePoolUpgradePolicy struct {
+	ID           string                      `json:"id,omitempty"`
+	HREF         string                      `json:"href,omitempty"`
+	NodePoolID   string                      `json:"node_pool_id,omitempty"`
+	ScheduleType string                      `json:"schedule_type,omitempty"`
+	Schedule     string                      `json:"schedule,omitempty"`
+	UpgradeType  string                      `json:"upgrade_type,omitempty"`
+	Version      string                      `json:"version,omitempty"`
+	NextRun      *time.Time                  `json:"next_run,omitempty"`
+	State        *NodePoolUpgradePolicyState `json:"state,omitempty"`
+}
+
+// NodePoolUpgradePolicyState is the state of a node pool upgrade policy, for example 'scheduled'
+// or 'started'.
+type NodePoolUpgradePolicyState struct {
+	Value       string `json:"value,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Pending checks if the upgrade policy hasn't finished yet. Policies that don't have a state yet are
+// considered pending, as that is the case of policies that have just been created.
+func (p *NodePoolUpgradePolicy) Pending() bool {
+	if p.State == nil {
+		return true
+	}
+	switch p.State.Value {
+	case "completed", "cancelled", "failed":
+		return false
+	default:
+		return true
+	}
+}
+
+// nodePoolList is used to decode the pages of the list of node pools.
+type nodePoolList struct {
+	Page  int         `json:"page"`
+	Size  int         `json:"size"`
+	Total int         `json:"total"`
+	Items []*NodePool `json:"items"`
+}
+
+// GetNodePool retrieves the node pool with the given identifier. It returns the decoded node pool
+// and also the raw JSON document returned by the server.
+func GetNodePool(connection *sdk.Connection, clusterID, nodePoolID string) (*NodePool, []byte,
+	error) {
+	path := fmt.Sprintf(
+		"/api/clusters_mgmt/v1/clusters/%s/node_pools/%s",
+		url.PathEscape(clusterID), url.PathEscape(nodePoolID),
+	)
+	body, err := sendRawRequest(connection.Get().Path(path))
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"Failed to get node pool '%s' for cluster '%s': %v",
+			nodePoolID, clusterID, err,
+		)
+	}
+	nodePool := &NodePool{}
+	err = json.Unmarshal(body, nodePool)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to parse node pool '%s': %v", nodePoolID, err)
+	}
+	return nodePool, body, nil
+}
+
+// GetNodePools retrieves all the node pools of the given cluster.
+func GetNodePools(connection *sdk.Connection, clusterID string) ([]*NodePool, error) {
+	body, err := sendRawRequest(
+		connection.Get().
+			Path(fmt.Sprintf(
+				"/api/clusters_mgmt/v1/clusters/%s/node_pools",
+				url.PathEscape(clusterID),
+			)).
+			Parameter("size", -1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get node pools for cluster '%s': %v", clusterID, err)
+	}
+	list := &nodePoolList{}
+	err = json.Unmarshal(body, list)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse node pools for cluster '%s': %v", clusterID, err)
+	}
+	return list.Items, nil
+}
+
+// nodePoolUpgradePolicyList is used to decode the list of upgrade policies of a node pool.
+type nodePoolUpgradePolicyList struct {
+	Items []*NodePoolUpgradePolicy `json:"items"`
+}
+
+// GetNodePoolUpgradePolicies retrieves the upgrade policies of the given node pool.
+func GetNodePoolUpgradePolicies(connection *sdk.Connection, clusterID,
+	nodePoolID string) ([]*NodePoolUpgradePolicy, error) {
+	body, err := sendRawRequest(
+		connection.Get().
+			Path(nodePoolUpgradePoliciesPath(clusterID, nodePoolID)).
+			Parameter("size", -1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"Failed to get upgrade policies for node pool '%s': %v",
+			nodePoolID, err,
+		)
+	}
+	list := &nodePoolUpgradePolicyList{}
+	err = json.Unmarshal(body, list)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"Failed to parse upgrade policies for node pool '%s': %v",
+			nodePoolID, err,
+		)
+	}
+	return list.Items, nil
+}
+
+// AddNodePoolUpgradePolicy creates the given upgrade policy for a node pool and returns the
+// policy created by the server.
+func AddNodePoolUpgradePolicy(connection *sdk.Connection, clusterID, nodePoolID string,
+	policy *NodePoolUpgradePolicy) (*NodePoolUpgradePolicy, error) {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+	body, err := sendRawRequest(
+		connection.Post().
+			Path(nodePoolUpgradePoliciesPath(clusterID, nodePoolID)).
+			Bytes(data),
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"Failed to create upgrade policy for node pool '%s': %w",
+			nodePoolID, err,
+		)
+	}
+	result := &NodePoolUpgradePolicy{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse upgrade policy: %v", err)
+	}
+	return result, nil
+}
+
+// DeleteNodePoolUpgradePolicy deletes an upgrade policy of a node pool.
+func DeleteNodePoolUpgradePolicy(connection *sdk.Connection, clusterID, nodePoolID,
+	policyID string) error {
+	_, err := sendRawRequest(
+		connection.Delete().
+			Path(nodePoolUpgradePoliciesPath(clusterID, nodePoolID) + "/" +
+				url.PathEscape(policyID)),
+	)
+	if err != nil {
+		return fmt.Errorf(
+			"Failed to delete upgrade policy '%s' of node pool '%s': %w",
+			policyID, nodePoolID, err,
+		)
+	}
+	return nil
+}
+
+// GetNodePoolAvailableUpgrades returns the versions that the given node pool can be upgraded to.
+// Node pools can't run a version newer than the control plane, so the versions newer than the
+// version of the cluster are excluded.
+func GetNodePoolAvailableUpgrades(client *cmv1.Client, cluster *cmv1.Cluster,
+	nodePool *NodePool) ([]string, error) {
+	if nodePool.Version == nil || nodePool.Version.ID == "" {
+		return nil, fmt.Errorf("Node pool '%s' doesn't have a version", nodePool.ID)
+	}
+	versions, err := GetAvailableUpgrades(client, nodePool.Version.ID, cluster.Product().ID())
+	if err != nil {
+		return nil, err
+	}
+	limit, err := goVersion.NewVersion(cluster.OpenshiftVersion())
+	if err != nil {
+		return versions, nil
+	}
+	result := []string{}
+	for _, version := range versions {
+		parsed, err := goVersion.NewVersion(version)
+		if err == nil && parsed.GreaterThan(limit) {
+			continue
+		}
+		result = append(result, version)
+	}
+	return result, nil
+}
+
+// nodePoolUpgradePoliciesPath returns the path of the collection of upgrade policies of a node pool.
+// The identifiers are escaped as they may be provided by the user.
+func nodePoolUpgradePoliciesPath(clusterID, nodePoolID string) string {
+	return fmt.Sprintf(
+		"/api/clusters_mgmt/v1/clusters/%s/node_pools/%s/upgrade_policies",
+		url.PathEscape(clusterID), url.PathEscape(nodePoolID),
+	)
+}
+
+// sendRawRequest sends the given request and returns the body of the response, or an error if
+// the server responded with an error status.
+func sendRawRequest(request *sdk.Request) ([]byte, error) {
+	response, err := request.Send()
+	if err != nil {
+		return nil, err
+	}
+	if response.Status() >= http.StatusBadRequest {
+		apiErr, err := errors.UnmarshalErrorStatus(response.Bytes(), response.Status())
+		if err != nil {
+			return nil, fmt.Errorf("status is %d", response.Status())
+		}
+		return nil, apiErr
+	}
+	return response.Bytes(), nil
+}
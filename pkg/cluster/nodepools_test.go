@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Node pools", func() {
+	var apiServer *Server
+	var connection *sdk.Connection
+
+	BeforeEach(func() {
+		var err error
+		apiServer = MakeTCPServer()
+		connection, err = sdk.NewConnectionBuilder().
+			URL(apiServer.URL()).
+			Tokens(MakeTokenString("Bearer", 15*time.Minute)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		connection.Close()
+		apiServer.Close()
+	})
+
+	It("Creates a manual upgrade policy for a node pool", func() {
+		nextRun := time.Date(2026, time.October, 20, 10, 0, 0, 0, time.UTC)
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/123/node_pools/workers/upgrade_policies",
+				),
+				VerifyJSON(`{
+					"schedule_type": "manual",
+					"upgrade_type": "NodePool",
+					"version": "4.14.2",
+					"next_run": "2026-10-20T10:00:00Z"
+				}`),
+				RespondWithJSON(
+					http.StatusCreated,
+					`{
+						"kind": "NodePoolUpgradePolicy",
+						"id": "456",
+						"node_pool_id": "workers",
+						"schedule_type": "manual",
+						"version": "4.14.2",
+						"state": {
+							"value": "pending"
+						}
+					}`,
+				),
+			),
+		)
+		policy, err := AddNodePoolUpgradePolicy(connection, "123", "workers", &NodePoolUpgradePolicy{
+			ScheduleType: "manual",
+			UpgradeType:  "NodePool",
+			Version:      "4.14.2",
+			NextRun:      &nextRun,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy.ID).To(Equal("456"))
+		Expect(policy.NodePoolID).To(Equal("workers"))
+		Expect(policy.Pending()).To(BeTrue())
+	})
+
+	It("Escapes the identifiers used in paths", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/node_pools/a%2F..%2Fb",
+				),
+				RespondWithJSON(http.StatusOK, `{"id": "a/../b"}`),
+			),
+		)
+		nodePool, _, err := GetNodePool(connection, "123", "a/../b")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodePool.ID).To(Equal("a/../b"))
+	})
+
+	It("Excludes upgrades to versions newer than the control plane", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/versions/openshift-v4.14.1"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "Version",
+						"id": "openshift-v4.14.1",
+						"raw_id": "4.14.1",
+						"available_upgrades": ["4.14.2", "4.14.3", "4.15.0"]
+					}`,
+				),
+			),
+		)
+		cluster, err := cmv1.NewCluster().
+			ID("123").
+			OpenshiftVersion("4.14.3").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		versions, err := GetNodePoolAvailableUpgrades(
+			connection.ClustersMgmt().V1(),
+			cluster,
+			&NodePool{
+				ID: "workers",
+				Version: &NodePoolVersion{
+					ID: "openshift-v4.14.1",
+				},
+			},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(versions).To(Equal([]string{"4.14.2", "4.14.3"}))
+	})
+
+	DescribeTable(
+		"Checks if upgrade policies are pending",
+		func(state string, expected bool) {
+			policy := &NodePoolUpgradePolicy{}
+			if state != "" {
+				policy.State = &NodePoolUpgradePolicyState{
+					Value: state,
+				}
+			}
+			Expect(policy.Pending()).To(Equal(expected))
+		},
+		Entry("Without state", "", true),
+		Entry("Pending", "pending", true),
+		Entry("Scheduled", "scheduled", true),
+		Entry("Started", "started", true),
+		Entry("Delayed", "delayed", true),
+		Entry("Completed", "completed", false),
+		Entry("Cancelled", "cancelled", false),
+		Entry("Failed", "failed", false),
+	)
+})
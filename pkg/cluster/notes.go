@@ -0,0 +1,118 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+)
+
+// NoteLabelPrefix is the prefix of the keys of the subscription labels that contain the notes
+// added with the 'annotate' command.
+const NoteLabelPrefix = "ocm.note."
+
+// noteKeyLayout is the layout of the time used in the keys of the note labels, so that sorting
+// the keys also sorts the notes by creation time.
+const noteKeyLayout = "20060102T150405.000Z"
+
+// Note is a free-form note added to the subscription of a cluster.
+type Note struct {
+	Key       string
+	Text      string
+	CreatedAt time.Time
+}
+
+// NotesFromLabels extracts the notes from the given subscription labels, sorted by creation time.
+func NotesFromLabels(labels []*amv1.Label) []*Note {
+	notes := []*Note{}
+	for _, label := range labels {
+		if !strings.HasPrefix(label.Key(), NoteLabelPrefix) {
+			continue
+		}
+		// The server doesn't always return the creation time of the labels, but it is also
+		// encoded in the key:
+		createdAt := label.CreatedAt()
+		if createdAt.IsZero() {
+			parsed, err := time.Parse(
+				noteKeyLayout,
+				strings.TrimPrefix(label.Key(), NoteLabelPrefix),
+			)
+			if err == nil {
+				createdAt = parsed
+			}
+		}
+		notes = append(notes, &Note{
+			Key:       label.Key(),
+			Text:      label.Value(),
+			CreatedAt: createdAt,
+		})
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].Key < notes[j].Key
+	})
+	return notes
+}
+
+// ListNotes retrieves the notes of the given subscription.
+func ListNotes(connection *sdk.Connection, subID string) ([]*Note, error) {
+	labels, err := ListSubscriptionLabels(connection, subID)
+	if err != nil {
+		return nil, err
+	}
+	return NotesFromLabels(labels), nil
+}
+
+// AddNote adds a note to the given subscription, and returns the key of the label that contains
+// it.
+func AddNote(connection *sdk.Connection, subID, text string) (key string, err error) {
+	key = NoteLabelPrefix + time.Now().UTC().Format(noteKeyLayout)
+	label, err := amv1.NewLabel().Key(key).Value(text).Build()
+	if err != nil {
+		return
+	}
+	_, err = connection.AccountsMgmt().V1().Subscriptions().Subscription(subID).Labels().Add().
+		Body(label).
+		Send()
+	if err != nil {
+		err = fmt.Errorf("Can't add note to subscription '%s': %w", subID, err)
+	}
+	return
+}
+
+// DeleteNote removes the note with the given key from the given subscription.
+func DeleteNote(connection *sdk.Connection, subID, key string) error {
+	if !strings.HasPrefix(key, NoteLabelPrefix) {
+		return fmt.Errorf("Key '%s' isn't the key of a note", key)
+	}
+	response, err := connection.AccountsMgmt().V1().Subscriptions().Subscription(subID).Labels().
+		Labels(key).
+		Delete().
+		Send()
+	if response != nil && response.Status() == http.StatusNotFound {
+		return fmt.Errorf("Note '%s' doesn't exist", key)
+	}
+	if err != nil {
+		return fmt.Errorf("Can't remove note '%s': %w", key, err)
+	}
+	return nil
+}
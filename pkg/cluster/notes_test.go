@@ -0,0 +1,54 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+)
+
+var _ = Describe("Notes", func() {
+	It("Takes the creation time from the key if the label doesn't have it", func() {
+		withTime, err := amv1.NewLabel().
+			Key("ocm.note.20231124T093000.000Z").
+			Value("First").
+			CreatedAt(time.Date(2023, 11, 24, 9, 30, 1, 0, time.UTC)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		withoutTime, err := amv1.NewLabel().
+			Key("ocm.note.20231201T100000.500Z").
+			Value("Second").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		other, err := amv1.NewLabel().
+			Key("capability.cluster.manage_cluster_admin").
+			Value("true").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		notes := NotesFromLabels([]*amv1.Label{withoutTime, other, withTime})
+		Expect(notes).To(HaveLen(2))
+		Expect(notes[0].Text).To(Equal("First"))
+		Expect(notes[0].CreatedAt).To(Equal(time.Date(2023, 11, 24, 9, 30, 1, 0, time.UTC)))
+		Expect(notes[1].Text).To(Equal("Second"))
+		Expect(notes[1].CreatedAt).To(Equal(time.Date(2023, 12, 1, 10, 0, 0, 500000000, time.UTC)))
+	})
+})
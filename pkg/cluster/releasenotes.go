@@ -0,0 +1,121 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+
+	goVersion "github.com/hashicorp/go-version"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// releaseNotesURLFormat is the format of the address of the section of the OpenShift release
+// notes that describes a z-stream version. The arguments are the major and minor numbers, twice,
+// followed by the major, minor and patch numbers.
+const releaseNotesURLFormat = "https://docs.openshift.com/container-platform/%d.%d/release_notes/" +
+	"ocp-%d-%d-release-notes.html#ocp-%d-%d-%d_release-notes"
+
+// ReleaseNote contains the links that describe the changes introduced by a version.
+type ReleaseNote struct {
+	Version      string `json:"version"`
+	URL          string `json:"url,omitempty"`
+	ReleaseImage string `json:"release_image,omitempty"`
+}
+
+// ReleaseNotesURL returns the address of the release notes of the given version, for example
+// '4.14.10'. It returns an empty string if the version can't be parsed or is a pre-release, as
+// those don't have release notes.
+func ReleaseNotesURL(version string) string {
+	parsed, err := goVersion.NewVersion(DropOpenshiftVPrefix(version))
+	if err != nil || parsed.Prerelease() != "" {
+		return ""
+	}
+	segments := parsed.Segments()
+	major, minor, patch := segments[0], segments[1], segments[2]
+	return fmt.Sprintf(releaseNotesURLFormat, major, minor, major, minor, major, minor, patch)
+}
+
+// VersionRawID returns the version number of the given version, for example '4.14.10'.
+func VersionRawID(version *cmv1.Version) string {
+	if version.RawID() != "" {
+		return version.RawID()
+	}
+	return DropOpenshiftVPrefix(version.ID())
+}
+
+// ReleaseNotesBetween returns the release notes of the versions that are newer than the from
+// version and not newer than the to version, sorted from oldest to newest. Pre-release versions
+// are ignored. It returns an error if the versions can't be parsed, if the from version isn't
+// older than the to version, or if the to version isn't in the given list.
+func ReleaseNotesBetween(versions []*cmv1.Version, from, to string) ([]*ReleaseNote, error) {
+	fromVersion, err := goVersion.NewVersion(DropOpenshiftVPrefix(from))
+	if err != nil {
+		return nil, fmt.Errorf("Version '%s' isn't valid: %v", from, err)
+	}
+	toVersion, err := goVersion.NewVersion(DropOpenshiftVPrefix(to))
+	if err != nil {
+		return nil, fmt.Errorf("Version '%s' isn't valid: %v", to, err)
+	}
+	if !fromVersion.LessThan(toVersion) {
+		return nil, fmt.Errorf("Version '%s' isn't older than version '%s'", from, to)
+	}
+	type item struct {
+		version *goVersion.Version
+		note    *ReleaseNote
+	}
+	var items []item
+	found := false
+	seen := map[string]bool{}
+	for _, version := range versions {
+		rawID := VersionRawID(version)
+		parsed, err := goVersion.NewVersion(rawID)
+		if err != nil || parsed.Prerelease() != "" {
+			continue
+		}
+		if parsed.Equal(toVersion) {
+			found = true
+		}
+		if !parsed.GreaterThan(fromVersion) || parsed.GreaterThan(toVersion) {
+			continue
+		}
+		if seen[parsed.String()] {
+			continue
+		}
+		seen[parsed.String()] = true
+		items = append(items, item{
+			version: parsed,
+			note: &ReleaseNote{
+				Version:      rawID,
+				URL:          ReleaseNotesURL(rawID),
+				ReleaseImage: version.ReleaseImage(),
+			},
+		})
+	}
+	if !found {
+		return nil, fmt.Errorf("Version '%s' doesn't exist", to)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].version.LessThan(items[j].version)
+	})
+	notes := make([]*ReleaseNote, len(items))
+	for i, item := range items {
+		notes[i] = item.note
+	}
+	return notes, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Release notes", func() {
+	makeVersions := func(ids ...string) []*cmv1.Version {
+		var versions []*cmv1.Version
+		for _, id := range ids {
+			version, err := cmv1.NewVersion().
+				ID("openshift-v" + id).
+				RawID(id).
+				ReleaseImage("quay.io/openshift-release-dev/ocp-release:" + id).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			versions = append(versions, version)
+		}
+		return versions
+	}
+
+	It("Generates the address of the release notes", func() {
+		Expect(ReleaseNotesURL("4.14.10")).To(Equal(
+			"https://docs.openshift.com/container-platform/4.14/release_notes/" +
+				"ocp-4-14-release-notes.html#ocp-4-14-10_release-notes",
+		))
+		Expect(ReleaseNotesURL("openshift-v4.9.3")).To(Equal(
+			"https://docs.openshift.com/container-platform/4.9/release_notes/" +
+				"ocp-4-9-release-notes.html#ocp-4-9-3_release-notes",
+		))
+	})
+
+	It("Doesn't generate addresses for pre-releases or invalid versions", func() {
+		Expect(ReleaseNotesURL("4.15.0-rc.1")).To(BeEmpty())
+		Expect(ReleaseNotesURL("junk")).To(BeEmpty())
+	})
+
+	It("Returns the versions in the range sorted", func() {
+		versions := makeVersions("4.14.20", "4.14.9", "4.14.10", "4.14.11", "4.15.0-rc.1",
+			"4.14.21", "4.14.12")
+		notes, err := ReleaseNotesBetween(versions, "4.14.10", "4.14.20")
+		Expect(err).ToNot(HaveOccurred())
+		var ids []string
+		for _, note := range notes {
+			ids = append(ids, note.Version)
+		}
+		Expect(ids).To(Equal([]string{"4.14.11", "4.14.12", "4.14.20"}))
+		Expect(notes[0].URL).To(HaveSuffix("#ocp-4-14-11_release-notes"))
+		Expect(notes[0].ReleaseImage).To(Equal(
+			"quay.io/openshift-release-dev/ocp-release:4.14.11",
+		))
+	})
+
+	It("Rejects ranges in the wrong order", func() {
+		_, err := ReleaseNotesBetween(makeVersions("4.14.10"), "4.14.20", "4.14.10")
+		Expect(err).To(MatchError(
+			"Version '4.14.20' isn't older than version '4.14.10'",
+		))
+	})
+
+	It("Rejects versions that don't exist", func() {
+		_, err := ReleaseNotesBetween(makeVersions("4.14.10"), "4.14.1", "4.14.99")
+		Expect(err).To(MatchError("Version '4.14.99' doesn't exist"))
+	})
+
+	It("Rejects invalid versions", func() {
+		_, err := ReleaseNotesBetween(makeVersions("4.14.10"), "junk", "4.14.10")
+		Expect(err).To(MatchError(ContainSubstring("Version 'junk' isn't valid")))
+	})
+})
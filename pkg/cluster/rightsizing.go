@@ -0,0 +1,228 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// Rightsizing contains the resource usage of a cluster and the changes to its machine pools that
+// would bring that usage close to the target utilization. Note that the field names are part of
+// the JSON output of the 'cluster rightsizing' command, so don't change them without considering
+// the consumers of that output.
+type Rightsizing struct {
+	ClusterID         string                `json:"cluster_id"`
+	ClusterName       string                `json:"cluster_name"`
+	UpdatedTimestamp  string                `json:"updated_timestamp,omitempty"`
+	CPUUsed           float64               `json:"cpu_used"`
+	CPUTotal          float64               `json:"cpu_total"`
+	MemoryUsed        float64               `json:"memory_used"`
+	MemoryTotal       float64               `json:"memory_total"`
+	CPUUtilization    float64               `json:"cpu_utilization"`
+	MemoryUtilization float64               `json:"memory_utilization"`
+	Target            float64               `json:"target_utilization"`
+	Pools             []*PoolRecommendation `json:"pools"`
+}
+
+// PoolRecommendation contains the recommended size and instance type of a machine pool. The
+// replica fields are used for pools with a fixed number of replicas, and the minimum and maximum
+// fields for pools with autoscaling enabled.
+type PoolRecommendation struct {
+	ID                      string `json:"id"`
+	InstanceType            string `json:"instance_type,omitempty"`
+	Replicas                int    `json:"replicas,omitempty"`
+	MinReplicas             int    `json:"min_replicas,omitempty"`
+	MaxReplicas             int    `json:"max_replicas,omitempty"`
+	RecommendedReplicas     int    `json:"recommended_replicas,omitempty"`
+	RecommendedMinReplicas  int    `json:"recommended_min_replicas,omitempty"`
+	RecommendedMaxReplicas  int    `json:"recommended_max_replicas,omitempty"`
+	RecommendedInstanceType string `json:"recommended_instance_type,omitempty"`
+	Reason                  string `json:"reason"`
+}
+
+// Autoscaling returns true if the recommendation is for a pool with autoscaling enabled.
+func (r *PoolRecommendation) Autoscaling() bool {
+	return r.MaxReplicas > 0
+}
+
+// Changed returns true if the recommendation changes the size or the instance type of the pool.
+func (r *PoolRecommendation) Changed() bool {
+	if r.RecommendedInstanceType != "" {
+		return true
+	}
+	if r.Autoscaling() {
+		return r.RecommendedMinReplicas != r.MinReplicas ||
+			r.RecommendedMaxReplicas != r.MaxReplicas
+	}
+	return r.RecommendedReplicas != r.Replicas
+}
+
+// HasUsageMetrics checks if the given subscription metrics contain the CPU and memory usage needed
+// to calculate recommendations.
+func HasUsageMetrics(metrics *amv1.SubscriptionMetrics) bool {
+	return metrics.Cpu().Total().Value() > 0 && metrics.Memory().Total().Value() > 0
+}
+
+// rightsizingTolerance is the fraction of the target utilization below which pools are considered
+// too large.
+const rightsizingTolerance = 0.8
+
+// Rightsize compares the CPU and memory usage reported in the subscription metrics of a cluster
+// with the capacity of its machine pools, and recommends the number of replicas that would bring
+// the utilization of the most used resource close to the given target, a value between zero and
+// one. When one resource is used much more than the other it also recommends an instance type
+// of a family balanced for that resource. The metrics are a snapshot of the telemetry reported by
+// the cluster, so the result is only as good as that snapshot.
+func Rightsize(cluster *cmv1.Cluster, metrics *amv1.SubscriptionMetrics, pools []*cmv1.MachinePool,
+	target float64) *Rightsizing {
+	result := &Rightsizing{
+		ClusterID:   cluster.ID(),
+		ClusterName: cluster.Name(),
+		CPUUsed:     metrics.Cpu().Used().Value(),
+		CPUTotal:    metrics.Cpu().Total().Value(),
+		MemoryUsed:  metrics.Memory().Used().Value(),
+		MemoryTotal: metrics.Memory().Total().Value(),
+		Target:      target,
+		Pools:       []*PoolRecommendation{},
+	}
+	if updated := metrics.Cpu().UpdatedTimestamp(); !updated.IsZero() {
+		result.UpdatedTimestamp = updated.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	result.CPUUtilization = ratio(result.CPUUsed, result.CPUTotal)
+	result.MemoryUtilization = ratio(result.MemoryUsed, result.MemoryTotal)
+
+	// The most used resource decides the size of the pools:
+	resource, utilization := "CPU", result.CPUUtilization
+	if result.MemoryUtilization > utilization {
+		resource, utilization = "Memory", result.MemoryUtilization
+	}
+	factor := utilization / target
+	reason := fmt.Sprintf(
+		"%s is %.0f%% used, close to the %.0f%% target",
+		resource, 100*utilization, 100*target,
+	)
+	switch {
+	case factor > 1:
+		reason = fmt.Sprintf(
+			"%s is %.0f%% used, above the %.0f%% target",
+			resource, 100*utilization, 100*target,
+		)
+	case factor < rightsizingTolerance:
+		reason = fmt.Sprintf(
+			"%s is %.0f%% used, below the %.0f%% target",
+			resource, 100*utilization, 100*target,
+		)
+	default:
+		// Don't shrink pools that are only a bit below the target, as the usage changes
+		// over time and removing a node would likely push it above the target:
+		factor = 1
+	}
+
+	// When one resource is used at least twice as much as the other an instance type optimized
+	// for that resource fits better:
+	family, familyReason := "", ""
+	switch {
+	case result.CPUUtilization >= 2*result.MemoryUtilization:
+		family = "c"
+		familyReason = fmt.Sprintf(", memory is only %.0f%% used", 100*result.MemoryUtilization)
+	case result.MemoryUtilization >= 2*result.CPUUtilization:
+		family = "r"
+		familyReason = fmt.Sprintf(", CPU is only %.0f%% used", 100*result.CPUUtilization)
+	}
+
+	for _, pool := range pools {
+		recommendation := &PoolRecommendation{
+			ID:           pool.ID(),
+			InstanceType: pool.InstanceType(),
+			Reason:       reason,
+		}
+		zones := len(pool.AvailabilityZones())
+		if zones == 0 {
+			zones = 1
+		}
+		autoscaling, ok := pool.GetAutoscaling()
+		if ok {
+			recommendation.MinReplicas = autoscaling.MinReplicas()
+			recommendation.MaxReplicas = autoscaling.MaxReplicas()
+			recommendation.RecommendedMinReplicas = recommendation.MinReplicas
+			recommendation.RecommendedMaxReplicas = recommendation.MaxReplicas
+			if factor > 1 {
+				recommendation.RecommendedMaxReplicas = scaleReplicas(
+					recommendation.MaxReplicas, factor, zones,
+				)
+			} else {
+				recommendation.RecommendedMinReplicas = scaleReplicas(
+					recommendation.MinReplicas, factor, zones,
+				)
+			}
+		} else {
+			recommendation.Replicas = pool.Replicas()
+			recommendation.RecommendedReplicas = scaleReplicas(
+				recommendation.Replicas, factor, zones,
+			)
+		}
+		if family != "" {
+			recommendation.RecommendedInstanceType = instanceTypeFamily(
+				pool.InstanceType(), family,
+			)
+			if recommendation.RecommendedInstanceType != "" {
+				recommendation.Reason += familyReason
+			}
+		}
+		result.Pools = append(result.Pools, recommendation)
+	}
+	return result
+}
+
+// ratio returns the used fraction of a resource, or zero if the total isn't known.
+func ratio(used, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return used / total
+}
+
+// scaleReplicas multiplies the given number of replicas by the factor, rounding up to a multiple
+// of the number of availability zones, as the pools need the same number of replicas in each
+// zone. The result is never less than one replica per zone.
+func scaleReplicas(replicas int, factor float64, zones int) int {
+	result := int(math.Ceil(float64(replicas)*factor/float64(zones)-1e-9)) * zones
+	if result < zones {
+		result = zones
+	}
+	return result
+}
+
+// instanceTypeFamilyRE matches AWS general purpose instance types, like 'm5.xlarge' or
+// 'm6i.2xlarge'.
+var instanceTypeFamilyRE = regexp.MustCompile(`^m(\d+[a-z]*\.[a-z0-9]+)$`)
+
+// instanceTypeFamily returns the instance type of the given family that has the same generation
+// and size as the given general purpose instance type. For example, for 'm5.xlarge' and the
+// 'c' family it returns 'c5.xlarge'. It returns an empty string for other instance types.
+func instanceTypeFamily(instanceType, family string) string {
+	matches := instanceTypeFamilyRE.FindStringSubmatch(instanceType)
+	if matches == nil {
+		return ""
+	}
+	return family + matches[1]
+}
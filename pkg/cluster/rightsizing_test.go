@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Rightsizing", func() {
+	makeMetrics := func(cpuUsed, cpuTotal, memoryUsed, memoryTotal float64) *amv1.SubscriptionMetrics {
+		updated := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+		metrics, err := amv1.NewSubscriptionMetrics().
+			Cpu(amv1.NewClusterResource().
+				Used(amv1.NewValueUnit().Value(cpuUsed)).
+				Total(amv1.NewValueUnit().Value(cpuTotal)).
+				UpdatedTimestamp(updated)).
+			Memory(amv1.NewClusterResource().
+				Used(amv1.NewValueUnit().Value(memoryUsed)).
+				Total(amv1.NewValueUnit().Value(memoryTotal)).
+				UpdatedTimestamp(updated)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		return metrics
+	}
+
+	makePools := func(builders ...*cmv1.MachinePoolBuilder) []*cmv1.MachinePool {
+		pools := []*cmv1.MachinePool{}
+		for _, builder := range builders {
+			pool, err := builder.Build()
+			Expect(err).ToNot(HaveOccurred())
+			pools = append(pools, pool)
+		}
+		return pools
+	}
+
+	var cluster *cmv1.Cluster
+
+	BeforeEach(func() {
+		var err error
+		cluster, err = cmv1.NewCluster().ID("123").Name("my-cluster").Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Recommends fewer replicas for an underused cluster", func() {
+		result := Rightsize(
+			cluster,
+			makeMetrics(8, 32, 42, 128),
+			makePools(
+				cmv1.NewMachinePool().ID("worker").InstanceType("m5.xlarge").Replicas(9).
+					AvailabilityZones("a", "b", "c"),
+			),
+			0.7,
+		)
+		Expect(result.ClusterID).To(Equal("123"))
+		Expect(result.UpdatedTimestamp).To(Equal("2022-05-01T10:00:00Z"))
+		Expect(result.CPUUtilization).To(Equal(0.25))
+		Expect(result.Pools).To(HaveLen(1))
+		pool := result.Pools[0]
+		Expect(pool.Replicas).To(Equal(9))
+		Expect(pool.RecommendedReplicas).To(Equal(6))
+		Expect(pool.RecommendedInstanceType).To(BeEmpty())
+		Expect(pool.Reason).To(Equal("Memory is 33% used, below the 70% target"))
+		Expect(pool.Changed()).To(BeTrue())
+	})
+
+	It("Recommends a larger maximum and a compute optimized type for a busy cluster", func() {
+		result := Rightsize(
+			cluster,
+			makeMetrics(28, 32, 32, 128),
+			makePools(
+				cmv1.NewMachinePool().ID("worker").InstanceType("m5.xlarge").
+					Autoscaling(cmv1.NewMachinePoolAutoscaling().MinReplicas(2).MaxReplicas(6)),
+			),
+			0.7,
+		)
+		pool := result.Pools[0]
+		Expect(pool.Autoscaling()).To(BeTrue())
+		Expect(pool.RecommendedMinReplicas).To(Equal(2))
+		Expect(pool.RecommendedMaxReplicas).To(Equal(8))
+		Expect(pool.RecommendedInstanceType).To(Equal("c5.xlarge"))
+		Expect(pool.Reason).To(Equal(
+			"CPU is 88% used, above the 70% target, memory is only 25% used",
+		))
+	})
+
+	It("Doesn't change pools of a cluster close to the target", func() {
+		result := Rightsize(
+			cluster,
+			makeMetrics(20, 32, 84, 128),
+			makePools(
+				cmv1.NewMachinePool().ID("worker").InstanceType("m5.xlarge").Replicas(3),
+			),
+			0.7,
+		)
+		pool := result.Pools[0]
+		Expect(pool.RecommendedReplicas).To(Equal(3))
+		Expect(pool.Changed()).To(BeFalse())
+		Expect(pool.Reason).To(Equal("Memory is 66% used, close to the 70% target"))
+	})
+
+	It("Keeps at least one replica per zone", func() {
+		Expect(scaleReplicas(3, 0.1, 3)).To(Equal(3))
+		Expect(scaleReplicas(2, 0.1, 1)).To(Equal(1))
+	})
+
+	It("Only suggests instance types for general purpose AWS types", func() {
+		Expect(instanceTypeFamily("m6i.2xlarge", "r")).To(Equal("r6i.2xlarge"))
+		Expect(instanceTypeFamily("c5.xlarge", "r")).To(BeEmpty())
+		Expect(instanceTypeFamily("custom-4-16384", "r")).To(BeEmpty())
+	})
+})
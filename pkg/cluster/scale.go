@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+)
+
+// ValidateComputeNodes checks that the given number of compute nodes is supported by the default
+// machine pool of a cluster with the given CCS and multi availability zone settings.
+func ValidateComputeNodes(nodes int, ccs bool, multiAZ bool) error {
+	var min int
+	if ccs {
+		if multiAZ {
+			min = 3
+		} else {
+			min = 2
+		}
+	} else {
+		if multiAZ {
+			min = 9
+		} else {
+			min = 4
+		}
+	}
+
+	if nodes < min {
+		return fmt.Errorf("Minimum is %d nodes", min)
+	}
+
+	if multiAZ && nodes%3 != 0 {
+		return fmt.Errorf("Multi-zone clusters require nodes to be multiple of 3")
+	}
+	return nil
+}
+
+// AvailableComputeNodes calculates how many additional compute nodes with the given resource name
+// the quota costs of an organization allow. The result is -1 when one of the matching quotas
+// doesn't have a cost, which means that the number of nodes isn't limited.
+func AvailableComputeNodes(quotaCosts []*amv1.QuotaCost, resourceName string, ccs bool,
+	multiAZ bool) int {
+	byoc := "rhinfra"
+	if ccs {
+		byoc = "byoc"
+	}
+	azType := "single"
+	if multiAZ {
+		azType = "multi"
+	}
+	available := 0
+	for _, quotaCost := range quotaCosts {
+		for _, related := range quotaCost.RelatedResources() {
+			if related.ResourceType() != "compute.node" ||
+				!matchesQuotaValue(related.ResourceName(), resourceName) ||
+				!matchesQuotaValue(related.BYOC(), byoc) ||
+				!matchesQuotaValue(related.AvailabilityZoneType(), azType) {
+				continue
+			}
+			if related.Cost() == 0 {
+				return -1
+			}
+			remaining := quotaCost.Allowed() - quotaCost.Consumed()
+			if remaining > 0 {
+				available += remaining / related.Cost()
+			}
+			break
+		}
+	}
+	return available
+}
+
+// matchesQuotaValue checks if a field of a related resource of a quota matches the given value,
+// taking into account that the 'any' value matches everything.
+func matchesQuotaValue(field, value string) bool {
+	return field == "any" || field == value
+}
@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+)
+
+var _ = Describe("Scale", func() {
+	Describe("Validate compute nodes", func() {
+		DescribeTable(
+			"Checks the number of nodes",
+			func(nodes int, ccs, multiAZ bool, expected string) {
+				err := ValidateComputeNodes(nodes, ccs, multiAZ)
+				if expected == "" {
+					Expect(err).ToNot(HaveOccurred())
+				} else {
+					Expect(err).To(MatchError(expected))
+				}
+			},
+			Entry("CCS single zone", 2, true, false, ""),
+			Entry("CCS single zone below minimum", 1, true, false, "Minimum is 2 nodes"),
+			Entry("CCS multi zone", 6, true, true, ""),
+			Entry("CCS multi zone not multiple of 3", 4, true, true,
+				"Multi-zone clusters require nodes to be multiple of 3"),
+			Entry("Non CCS single zone below minimum", 3, false, false, "Minimum is 4 nodes"),
+			Entry("Non CCS multi zone below minimum", 6, false, true, "Minimum is 9 nodes"),
+		)
+	})
+
+	Describe("Available compute nodes", func() {
+		makeQuota := func(allowed, consumed int, related ...*amv1.RelatedResourceBuilder) *amv1.QuotaCost {
+			quota, err := amv1.NewQuotaCost().
+				Allowed(allowed).
+				Consumed(consumed).
+				RelatedResources(related...).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			return quota
+		}
+
+		makeRelated := func(name, byoc, azType string, cost int) *amv1.RelatedResourceBuilder {
+			return amv1.NewRelatedResource().
+				ResourceType("compute.node").
+				ResourceName(name).
+				BYOC(byoc).
+				AvailabilityZoneType(azType).
+				Cost(cost)
+		}
+
+		It("Divides the remaining quota by the cost", func() {
+			quotas := []*amv1.QuotaCost{
+				makeQuota(20, 8, makeRelated("standard-4", "rhinfra", "any", 4)),
+			}
+			Expect(AvailableComputeNodes(quotas, "standard-4", false, false)).To(Equal(3))
+		})
+
+		It("Adds the matching quotas", func() {
+			quotas := []*amv1.QuotaCost{
+				makeQuota(10, 8, makeRelated("standard-4", "byoc", "single", 1)),
+				makeQuota(5, 0, makeRelated("any", "byoc", "any", 1)),
+			}
+			Expect(AvailableComputeNodes(quotas, "standard-4", true, false)).To(Equal(7))
+		})
+
+		It("Ignores quotas that don't match", func() {
+			quotas := []*amv1.QuotaCost{
+				makeQuota(10, 0, makeRelated("standard-8", "byoc", "any", 1)),
+				makeQuota(10, 0, makeRelated("standard-4", "rhinfra", "any", 1)),
+				makeQuota(10, 0, makeRelated("standard-4", "byoc", "multi", 1)),
+			}
+			Expect(AvailableComputeNodes(quotas, "standard-4", true, false)).To(BeZero())
+		})
+
+		It("Ignores exhausted quotas", func() {
+			quotas := []*amv1.QuotaCost{
+				makeQuota(10, 12, makeRelated("standard-4", "byoc", "any", 1)),
+			}
+			Expect(AvailableComputeNodes(quotas, "standard-4", true, false)).To(BeZero())
+		})
+
+		It("Returns -1 when a matching quota has no cost", func() {
+			quotas := []*amv1.QuotaCost{
+				makeQuota(0, 0, makeRelated("any", "byoc", "any", 0)),
+			}
+			Expect(AvailableComputeNodes(quotas, "standard-4", true, true)).To(Equal(-1))
+		})
+	})
+})
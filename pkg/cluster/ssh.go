@@ -0,0 +1,57 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// SSHUser is the user name used by default to connect to the SSH host of clusters.
+const SSHUser = "sre-user"
+
+// SSHHost returns the name of the host that accepts SSH connections for the given cluster. It is
+// calculated replacing the 'api' prefix of the host of the API URL with 'rh-ssh', for example if
+// the API URL is 'https://api.my.example.com:6443' the SSH host will be 'rh-ssh.my.example.com'.
+func SSHHost(cluster *cmv1.Cluster) (string, error) {
+	apiURL := cluster.API().URL()
+	if apiURL == "" {
+		return "", fmt.Errorf("Can't find the API URL for cluster '%s'", cluster.Name())
+	}
+	parsed, err := url.Parse(apiURL)
+	if err != nil {
+		return "", fmt.Errorf("Can't parse API URL '%s': %v", apiURL, err)
+	}
+	base := strings.TrimPrefix(parsed.Hostname(), "api.")
+	if base == "" || base == parsed.Hostname() {
+		return "", fmt.Errorf(
+			"API URL '%s' of cluster '%s' doesn't start with the 'api.' prefix",
+			apiURL, cluster.Name(),
+		)
+	}
+	return "rh-ssh." + base, nil
+}
+
+// IsPrivate checks if the API of the given cluster is reachable only from the private network of
+// the cluster, either because it listens only internally or because it uses AWS PrivateLink.
+func IsPrivate(cluster *cmv1.Cluster) bool {
+	return cluster.API().Listening() == cmv1.ListeningMethodInternal ||
+		cluster.AWS().PrivateLink()
+}
@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("SSH", func() {
+	makeCluster := func(apiURL string, listening cmv1.ListeningMethod, privateLink bool) *cmv1.Cluster {
+		cluster, err := cmv1.NewCluster().
+			Name("my-cluster").
+			API(cmv1.NewClusterAPI().URL(apiURL).Listening(listening)).
+			AWS(cmv1.NewAWS().PrivateLink(privateLink)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		return cluster
+	}
+
+	DescribeTable(
+		"Calculates the SSH host",
+		func(apiURL, expected string) {
+			host, err := SSHHost(makeCluster(apiURL, cmv1.ListeningMethodExternal, false))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(host).To(Equal(expected))
+		},
+		Entry(
+			"With port",
+			"https://api.my.abcd.p1.openshiftapps.com:6443",
+			"rh-ssh.my.abcd.p1.openshiftapps.com",
+		),
+		Entry(
+			"Without port",
+			"https://api.my.example.com",
+			"rh-ssh.my.example.com",
+		),
+	)
+
+	It("Fails if the cluster doesn't have an API URL", func() {
+		_, err := SSHHost(makeCluster("", cmv1.ListeningMethodExternal, false))
+		Expect(err).To(MatchError("Can't find the API URL for cluster 'my-cluster'"))
+	})
+
+	It("Fails if the API URL doesn't have the expected prefix", func() {
+		_, err := SSHHost(makeCluster("https://my.example.com:6443", cmv1.ListeningMethodExternal, false))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("doesn't start with the 'api.' prefix"))
+	})
+
+	DescribeTable(
+		"Detects private clusters",
+		func(listening cmv1.ListeningMethod, privateLink, expected bool) {
+			cluster := makeCluster("https://api.my.example.com:6443", listening, privateLink)
+			Expect(IsPrivate(cluster)).To(Equal(expected))
+		},
+		Entry("External", cmv1.ListeningMethodExternal, false, false),
+		Entry("Internal", cmv1.ListeningMethodInternal, false, true),
+		Entry("PrivateLink", cmv1.ListeningMethodExternal, true, true),
+	)
+})
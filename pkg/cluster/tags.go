@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TagStatus indicates how a tag recorded on a cluster compares with the desired value.
+type TagStatus string
+
+const (
+	TagMatch     TagStatus = "match"
+	TagMissing   TagStatus = "missing"
+	TagDifferent TagStatus = "different"
+	TagExtra     TagStatus = "extra"
+)
+
+// TagDiff describes the difference between the desired and recorded values of a tag.
+type TagDiff struct {
+	Key      string
+	Desired  string
+	Recorded string
+	Status   TagStatus
+}
+
+// Limits of the cost allocation tags of AWS:
+const (
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+// reservedTagPrefixes are the prefixes of the tag keys that are reserved by the cloud provider
+// or by OpenShift and can't be used in user tags.
+var reservedTagPrefixes = []string{
+	"aws:",
+	"kubernetes.io/",
+	"red-hat-",
+	"sigs.k8s.io/",
+}
+
+// ValidateTags checks that the given tags can be used as cost allocation tags of a cluster.
+func ValidateTags(tags map[string]string) error {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if key == "" {
+			return fmt.Errorf("Tag key can't be empty")
+		}
+		if len(key) > maxTagKeyLength {
+			return fmt.Errorf(
+				"Tag key '%s' is longer than %d characters",
+				key, maxTagKeyLength,
+			)
+		}
+		if len(tags[key]) > maxTagValueLength {
+			return fmt.Errorf(
+				"Value of tag '%s' is longer than %d characters",
+				key, maxTagValueLength,
+			)
+		}
+		for _, prefix := range reservedTagPrefixes {
+			if strings.HasPrefix(strings.ToLower(key), prefix) {
+				return fmt.Errorf("Tag key '%s' uses reserved prefix '%s'", key, prefix)
+			}
+		}
+	}
+	return nil
+}
+
+// DiffTags compares the desired tags with the tags recorded on a cluster. The result is sorted
+// by key.
+func DiffTags(desired, recorded map[string]string) []TagDiff {
+	keys := map[string]bool{}
+	for key := range desired {
+		keys[key] = true
+	}
+	for key := range recorded {
+		keys[key] = true
+	}
+	result := make([]TagDiff, 0, len(keys))
+	for key := range keys {
+		diff := TagDiff{
+			Key: key,
+		}
+		var hasDesired, hasRecorded bool
+		diff.Desired, hasDesired = desired[key]
+		diff.Recorded, hasRecorded = recorded[key]
+		switch {
+		case !hasRecorded:
+			diff.Status = TagMissing
+		case !hasDesired:
+			diff.Status = TagExtra
+		case diff.Desired != diff.Recorded:
+			diff.Status = TagDifferent
+		default:
+			diff.Status = TagMatch
+		}
+		result = append(result, diff)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
@@ -0,0 +1,61 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Tags", func() {
+	It("Compares desired and recorded tags", func() {
+		diffs := DiffTags(
+			map[string]string{
+				"cost-center": "1234",
+				"owner":       "payments",
+				"team":        "checkout",
+			},
+			map[string]string{
+				"cost-center": "1234",
+				"owner":       "billing",
+				"environment": "prod",
+			},
+		)
+		Expect(diffs).To(Equal([]TagDiff{
+			{Key: "cost-center", Desired: "1234", Recorded: "1234", Status: TagMatch},
+			{Key: "environment", Recorded: "prod", Status: TagExtra},
+			{Key: "owner", Desired: "payments", Recorded: "billing", Status: TagDifferent},
+			{Key: "team", Desired: "checkout", Status: TagMissing},
+		}))
+	})
+
+	It("Accepts valid tags", func() {
+		Expect(ValidateTags(map[string]string{"cost-center": "1234"})).To(Succeed())
+	})
+
+	It("Rejects reserved prefixes", func() {
+		err := ValidateTags(map[string]string{"AWS:createdBy": "me"})
+		Expect(err).To(MatchError("Tag key 'AWS:createdBy' uses reserved prefix 'aws:'"))
+	})
+
+	It("Rejects long values", func() {
+		err := ValidateTags(map[string]string{"owner": strings.Repeat("x", 257)})
+		Expect(err).To(MatchError("Value of tag 'owner' is longer than 256 characters"))
+	})
+})
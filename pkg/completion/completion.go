@@ -0,0 +1,296 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package completion contains the functions that complete the values of command line arguments
+// and flags using the objects that exist in the server, like organization identifiers, role
+// identifiers or cluster names.
+//
+// The results of the lookups are cached in the user cache directory for a short time, so that
+// pressing the tab key repeatedly doesn't send the same requests to the server again and again.
+package completion
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/cache"
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+// TTL is the time that the results of the lookups are kept in the cache.
+const TTL = time.Minute
+
+// limit is the maximum number of suggestions that will be returned.
+const limit = 100
+
+// timeout is the maximum time that a lookup can take. Completion runs while the user waits in
+// front of the terminal, so it is better to return nothing than to block the shell.
+const timeout = 5 * time.Second
+
+// lookupFunc is the signature of the functions that find the suggestions for a prefix. The
+// results are in the format expected by cobra, either the value or the value followed by a tab
+// and a description.
+type lookupFunc func(ctx context.Context, connection *sdk.Connection,
+	prefix string) ([]string, error)
+
+// Organizations completes organization identifiers.
+func Organizations(cmd *cobra.Command, argv []string,
+	toComplete string) ([]string, cobra.ShellCompDirective) {
+	return complete("organizations", lookupOrganizations, toComplete)
+}
+
+// Roles completes role identifiers. Values separated by commas, as used by flags that accept
+// multiple roles, are supported.
+func Roles(cmd *cobra.Command, argv []string,
+	toComplete string) ([]string, cobra.ShellCompDirective) {
+	head := ""
+	index := strings.LastIndex(toComplete, ",")
+	if index >= 0 {
+		head = toComplete[0 : index+1]
+		toComplete = toComplete[index+1:]
+	}
+	suggestions, directive := complete("roles", lookupRoles, toComplete)
+	for i, suggestion := range suggestions {
+		suggestions[i] = head + suggestion
+	}
+	return suggestions, directive
+}
+
+// Clusters completes cluster names and identifiers.
+func Clusters(cmd *cobra.Command, argv []string,
+	toComplete string) ([]string, cobra.ShellCompDirective) {
+	return complete("clusters", lookupClusters, toComplete)
+}
+
+// FirstArg wraps a completion function so that it is used only for the first positional
+// argument of a command.
+func FirstArg(f func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)) func(
+	*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, argv []string,
+		toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(argv) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return f(cmd, argv, toComplete)
+	}
+}
+
+// complete returns the suggestions for the given prefix, from the cache if possible, otherwise
+// calling the lookup function and saving the results in the cache.
+func complete(kind string, lookup lookupFunc,
+	prefix string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := config.Load()
+	if err != nil {
+		cobra.CompErrorln(fmt.Sprintf("Can't load config file: %v", err))
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if cfg == nil {
+		cobra.CompErrorln("Not logged in, run the 'login' command")
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// Try the cache first:
+	file := cacheFile(cfg, kind, prefix)
+	suggestions, ok := readCache(file)
+	if ok {
+		return suggestions, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// Don't try to renew expired tokens, as that would ask the user for a new token in the
+	// middle of the completion:
+	armed, reason, err := cfg.Armed()
+	if err != nil || !armed {
+		cobra.CompErrorln(fmt.Sprintf("Not logged in, %s, run the 'login' command", reason))
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	connection, err := ocm.NewConnection().Config(cfg).Build()
+	if err != nil {
+		cobra.CompErrorln(fmt.Sprintf("Failed to create OCM connection: %v", err))
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer connection.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	suggestions, err = lookup(ctx, connection, prefix)
+	if err != nil {
+		cobra.CompErrorln(fmt.Sprintf("Can't find %s: %v", kind, err))
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	writeCache(file, suggestions)
+	return suggestions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func lookupOrganizations(ctx context.Context, connection *sdk.Connection,
+	prefix string) (result []string, err error) {
+	request := connection.AccountsMgmt().V1().Organizations().List().
+		Size(limit)
+	if prefix != "" {
+		request.Search(fmt.Sprintf("id like '%s%%'", quote(prefix)))
+	}
+	response, err := request.SendContext(ctx)
+	if err != nil {
+		return
+	}
+	response.Items().Each(func(org *amv1.Organization) bool {
+		result = append(result, describe(org.ID(), org.Name()))
+		return true
+	})
+	return
+}
+
+func lookupRoles(ctx context.Context, connection *sdk.Connection,
+	prefix string) (result []string, err error) {
+	request := connection.AccountsMgmt().V1().Roles().List().
+		Size(limit)
+	if prefix != "" {
+		request.Search(fmt.Sprintf("id like '%s%%'", quote(prefix)))
+	}
+	response, err := request.SendContext(ctx)
+	if err != nil {
+		return
+	}
+	response.Items().Each(func(role *amv1.Role) bool {
+		result = append(result, role.ID())
+		return true
+	})
+	return
+}
+
+func lookupClusters(ctx context.Context, connection *sdk.Connection,
+	prefix string) (result []string, err error) {
+	request := connection.ClustersMgmt().V1().Clusters().List().
+		Size(limit)
+	if prefix != "" {
+		request.Search(fmt.Sprintf(
+			"name like '%s%%' or id like '%s%%'",
+			quote(prefix), quote(prefix),
+		))
+	}
+	response, err := request.SendContext(ctx)
+	if err != nil {
+		return
+	}
+
+	// Suggest the names, as they are easier to remember, but also the identifiers when the
+	// prefix matches them:
+	response.Items().Each(func(cluster *cmv1.Cluster) bool {
+		if strings.HasPrefix(cluster.Name(), prefix) {
+			result = append(result, describe(cluster.Name(), cluster.ID()))
+		}
+		if prefix != "" && strings.HasPrefix(cluster.ID(), prefix) {
+			result = append(result, describe(cluster.ID(), cluster.Name()))
+		}
+		return true
+	})
+	return
+}
+
+// describe returns a suggestion with the given value and description.
+func describe(value, description string) string {
+	if description == "" {
+		return value
+	}
+	return value + "\t" + description
+}
+
+// quote escapes the single quotes of a value that will be used inside a search expression.
+func quote(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
+
+// cacheFile returns the name of the file where the results of the lookup of the given kind of
+// objects and prefix are cached. The name includes the URL and the credentials, so that results
+// obtained with other credentials are never used.
+func cacheFile(cfg *config.Config, kind, prefix string) string {
+	dir, err := cache.Dir()
+	if err != nil {
+		return ""
+	}
+	token := cfg.RefreshToken
+	if token == "" {
+		token = cfg.AccessToken
+	}
+	hash := sha256.New()
+	for _, value := range []string{cfg.URL, cfg.ClientID, cfg.User, token, kind, prefix} {
+		hash.Write([]byte(value))
+		hash.Write([]byte{0})
+	}
+	return filepath.Join(dir, "completion", hex.EncodeToString(hash.Sum(nil))+".json")
+}
+
+// readCache reads the suggestions from the given cache file, if it exists and it hasn't expired.
+func readCache(file string) (suggestions []string, ok bool) {
+	if file == "" {
+		return
+	}
+	info, err := os.Stat(file)
+	if err != nil || time.Since(info.ModTime()) > TTL {
+		return
+	}
+	data, err := os.ReadFile(file) // #nosec G304
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &suggestions)
+	if err != nil {
+		return
+	}
+	ok = true
+	return
+}
+
+// writeCache saves the suggestions to the given cache file, and removes the files of the cache
+// that have expired. Errors are ignored, as the cache is only an optimization.
+func writeCache(file string, suggestions []string) {
+	if file == "" {
+		return
+	}
+	dir := filepath.Dir(file)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err == nil {
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err == nil && time.Since(info.ModTime()) > TTL {
+				os.Remove(filepath.Join(dir, entry.Name())) // #nosec G104
+			}
+		}
+	}
+	if suggestions == nil {
+		suggestions = []string{}
+	}
+	data, err := json.Marshal(suggestions)
+	if err != nil {
+		return
+	}
+	os.WriteFile(file, data, 0600) // #nosec G104
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the compression of responses and the
+// '--no-compress' command line option.
+
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// AddFlag adds the no compress flag to the given set of command line flags.
+func AddFlag(flags *pflag.FlagSet) {
+	flags.BoolVar(
+		&disabled,
+		"no-compress",
+		false,
+		"Ask the server to send responses without compression. This is useful to debug "+
+			"proxies that don't handle compressed responses correctly.",
+	)
+}
+
+// Enabled returns a boolean flag that indicates if compression is enabled.
+func Enabled() bool {
+	return !disabled
+}
+
+// disabled is a boolean flag that indicates that the compression has been disabled.
+var disabled bool
+
+// TransportWrapper returns a transport wrapper that asks the server to compress the responses
+// with gzip and decompresses them, or that asks the server to not compress them at all if the
+// compression is disabled.
+func TransportWrapper() func(http.RoundTripper) http.RoundTripper {
+	return func(wrapped http.RoundTripper) http.RoundTripper {
+		return &roundTripper{
+			wrapped: wrapped,
+		}
+	}
+}
+
+type roundTripper struct {
+	wrapped http.RoundTripper
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*roundTripper)(nil)
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	// Don't change requests where the caller explicitly asked for an encoding:
+	if request.Header.Get("Accept-Encoding") != "" {
+		return t.wrapped.RoundTrip(request)
+	}
+
+	// Note that the request can't be modified, so we need to clone it:
+	request = request.Clone(request.Context())
+	if disabled {
+		request.Header.Set("Accept-Encoding", "identity")
+		return t.wrapped.RoundTrip(request)
+	}
+	request.Header.Set("Accept-Encoding", "gzip")
+	response, err = t.wrapped.RoundTrip(request)
+	if err != nil {
+		return
+	}
+	if !strings.EqualFold(response.Header.Get("Content-Encoding"), "gzip") {
+		return
+	}
+	reader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		response.Body.Close()
+		return
+	}
+	response.Body = &gzipBody{
+		reader: reader,
+		body:   response.Body,
+	}
+	response.Header.Del("Content-Encoding")
+	response.Header.Del("Content-Length")
+	response.ContentLength = -1
+	response.Uncompressed = true
+	return
+}
+
+// gzipBody is the body of a compressed response. It decompresses the data when it is read, and
+// closes the original body when it is closed. Any data not yet read is discarded before closing,
+// otherwise the HTTP client can't return the connection to the pool and has to open a new one for
+// the next request.
+type gzipBody struct {
+	reader *gzip.Reader
+	body   io.ReadCloser
+}
+
+func (b *gzipBody) Read(p []byte) (n int, err error) {
+	return b.reader.Read(p)
+}
+
+func (b *gzipBody) Close() error {
+	b.reader.Close()
+	_, _ = io.Copy(ioutil.Discard, b.body)
+	return b.body.Close()
+}
@@ -0,0 +1,346 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the authentication providers, which decide how the credentials that
+// connections use to authenticate are obtained.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+
+	"github.com/openshift-online/ocm-cli/pkg/devicecode"
+)
+
+// AuthProvider obtains the credentials that connections use to authenticate. The provider is
+// selected with the 'auth_provider' setting. When that setting is empty the provider is chosen
+// according to the credentials present in the configuration.
+type AuthProvider interface {
+	// Armed checks if the configuration contains what the provider needs to authenticate. When
+	// it doesn't it returns a human readable reason.
+	Armed(cfg *Config) (armed bool, reason string, err error)
+
+	// Configure adds the credentials to the given connection builder.
+	Configure(cfg *Config, builder *sdk.ConnectionBuilder) error
+}
+
+// Names of the builtin authentication providers:
+const (
+	AuthProviderToken             = "token"
+	AuthProviderClientCredentials = "client-credentials"
+	AuthProviderPassword          = "password"
+	AuthProviderDeviceCode        = "device-code"
+	AuthProviderExec              = "exec"
+)
+
+// authProviders contains the registered authentication providers, indexed by name.
+var (
+	authProviders     = map[string]AuthProvider{}
+	authProvidersLock = &sync.Mutex{}
+)
+
+func init() {
+	RegisterAuthProvider(AuthProviderToken, &tokenAuthProvider{})
+	RegisterAuthProvider(AuthProviderClientCredentials, &clientCredentialsAuthProvider{})
+	RegisterAuthProvider(AuthProviderPassword, &passwordAuthProvider{})
+	RegisterAuthProvider(AuthProviderDeviceCode, &deviceCodeAuthProvider{})
+	RegisterAuthProvider(AuthProviderExec, &execAuthProvider{})
+}
+
+// RegisterAuthProvider registers an authentication provider, so that it can be selected with the
+// 'auth_provider' setting. Registering a provider with the name of an existing one replaces it.
+func RegisterAuthProvider(name string, provider AuthProvider) {
+	authProvidersLock.Lock()
+	defer authProvidersLock.Unlock()
+	authProviders[name] = provider
+}
+
+// AuthProviders returns the sorted names of the registered authentication providers.
+func AuthProviders() []string {
+	authProvidersLock.Lock()
+	defer authProvidersLock.Unlock()
+	names := make([]string, 0, len(authProviders))
+	for name := range authProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// authProvider returns the authentication provider selected by the configuration.
+func (c *Config) authProvider() (provider AuthProvider, err error) {
+	name := c.AuthProvider
+	if name == "" {
+		if c.AuthCommand != "" {
+			name = AuthProviderExec
+		} else {
+			provider = &autoAuthProvider{}
+			return
+		}
+	}
+	authProvidersLock.Lock()
+	provider, ok := authProviders[name]
+	authProvidersLock.Unlock()
+	if !ok {
+		err = fmt.Errorf(
+			"Unknown authentication provider '%s', valid values are %s",
+			name, strings.Join(AuthProviders(), ", "),
+		)
+	}
+	return
+}
+
+// addClient adds the client identifier to the builder, as the SSO server needs it to refresh
+// tokens.
+func (c *Config) addClient(builder *sdk.ConnectionBuilder) {
+	if c.ClientID != "" {
+		builder.Client(c.ClientID, "")
+	}
+}
+
+// addTokens adds the tokens of the configuration to the builder, if any.
+func (c *Config) addTokens(builder *sdk.ConnectionBuilder) {
+	tokens := make([]string, 0, 2)
+	if c.AccessToken != "" {
+		tokens = append(tokens, c.AccessToken)
+	}
+	if c.RefreshToken != "" {
+		tokens = append(tokens, c.RefreshToken)
+	}
+	if len(tokens) > 0 {
+		builder.Tokens(tokens...)
+	}
+}
+
+// autoAuthProvider uses all the credentials and tokens present in the configuration. It is used
+// when the 'auth_provider' setting is empty.
+type autoAuthProvider struct{}
+
+func (p *autoAuthProvider) Armed(cfg *Config) (bool, string, error) {
+	havePassword := cfg.User != "" && cfg.Password != ""
+	haveSecret := cfg.ClientID != "" && cfg.ClientSecret != ""
+	return cfg.armed(havePassword || haveSecret)
+}
+
+func (p *autoAuthProvider) Configure(cfg *Config, builder *sdk.ConnectionBuilder) error {
+	if cfg.ClientID != "" || cfg.ClientSecret != "" {
+		builder.Client(cfg.ClientID, cfg.ClientSecret)
+	}
+	if cfg.User != "" || cfg.Password != "" {
+		builder.User(cfg.User, cfg.Password)
+	}
+	cfg.addTokens(builder)
+	return nil
+}
+
+// tokenAuthProvider uses only the access and refresh tokens, for example an offline token.
+type tokenAuthProvider struct{}
+
+func (p *tokenAuthProvider) Armed(cfg *Config) (bool, string, error) {
+	return cfg.armed(false)
+}
+
+func (p *tokenAuthProvider) Configure(cfg *Config, builder *sdk.ConnectionBuilder) error {
+	cfg.addClient(builder)
+	cfg.addTokens(builder)
+	return nil
+}
+
+// clientCredentialsAuthProvider uses the client identifier and secret, and the tokens obtained
+// with them before, if any.
+type clientCredentialsAuthProvider struct{}
+
+func (p *clientCredentialsAuthProvider) Armed(cfg *Config) (bool, string, error) {
+	return cfg.armed(cfg.ClientID != "" && cfg.ClientSecret != "")
+}
+
+func (p *clientCredentialsAuthProvider) Configure(cfg *Config, builder *sdk.ConnectionBuilder) error {
+	builder.Client(cfg.ClientID, cfg.ClientSecret)
+	cfg.addTokens(builder)
+	return nil
+}
+
+// passwordAuthProvider uses the user name and password, and the tokens obtained with them before,
+// if any.
+type passwordAuthProvider struct{}
+
+func (p *passwordAuthProvider) Armed(cfg *Config) (bool, string, error) {
+	return cfg.armed(cfg.User != "" && cfg.Password != "")
+}
+
+func (p *passwordAuthProvider) Configure(cfg *Config, builder *sdk.ConnectionBuilder) error {
+	cfg.addClient(builder)
+	builder.User(cfg.User, cfg.Password)
+	cfg.addTokens(builder)
+	return nil
+}
+
+// deviceCodeAuthProvider uses the tokens obtained with the OAuth device authorization flow. When
+// they have expired and the tool is running in a terminal it runs the flow again, so that the user
+// can approve the access with a browser instead of having to log in again.
+type deviceCodeAuthProvider struct{}
+
+// runDeviceCode runs the device authorization flow with the settings of the given configuration.
+// It is a variable so that it can be replaced in tests.
+var runDeviceCode = func(cfg *Config) (*devicecode.Tokens, error) {
+	tokenURL := cfg.TokenURL
+	if tokenURL == "" {
+		tokenURL = sdk.DefaultTokenURL
+	}
+	clientID := cfg.ClientID
+	if clientID == "" {
+		clientID = sdk.DefaultClientID
+	}
+	return devicecode.NewFlow().
+		TokenURL(tokenURL).
+		ClientID(clientID).
+		Scopes(cfg.Scopes...).
+		Insecure(cfg.Insecure).
+		Output(os.Stderr).
+		Run(context.Background())
+}
+
+func (p *deviceCodeAuthProvider) Armed(cfg *Config) (armed bool, reason string, err error) {
+	armed, reason, err = cfg.armed(false)
+	if err != nil || armed {
+		return
+	}
+	if cfg.URL != "" && interactive() {
+		armed = true
+		reason = ""
+	}
+	return
+}
+
+func (p *deviceCodeAuthProvider) Configure(cfg *Config, builder *sdk.ConnectionBuilder) error {
+	armed, _, err := cfg.armed(false)
+	if err != nil {
+		return err
+	}
+	if !armed && interactive() {
+		fmt.Fprintf(os.Stderr, "Your session has expired, approve the access again.\n")
+		tokens, err := runDeviceCode(cfg)
+		if err != nil {
+			return err
+		}
+		cfg.AccessToken = tokens.Access
+		cfg.RefreshToken = tokens.Refresh
+		err = Save(cfg)
+		if err != nil {
+			return fmt.Errorf("Can't save config file: %v", err)
+		}
+	}
+	cfg.addClient(builder)
+	cfg.addTokens(builder)
+	return nil
+}
+
+// execAuthProvider runs the credential helper given in the 'auth_command' setting to obtain the
+// tokens, similar to the exec plugins of kubeconfig files. The helper runs every time that a
+// connection is created, and the tokens that it returns aren't saved.
+type execAuthProvider struct{}
+
+func (p *execAuthProvider) Armed(cfg *Config) (armed bool, reason string, err error) {
+	switch {
+	case cfg.AuthCommand == "":
+		reason = "credential helper command isn't set"
+	case cfg.URL == "":
+		reason = "server URL isn't set"
+	default:
+		armed = true
+	}
+	return
+}
+
+func (p *execAuthProvider) Configure(cfg *Config, builder *sdk.ConnectionBuilder) error {
+	tokens, err := RunCredentialHelper(cfg)
+	if err != nil {
+		return err
+	}
+	cfg.addClient(builder)
+	builder.Tokens(tokens...)
+	return nil
+}
+
+// credentialHelperOutput is used to decode the output of credential helpers that write a JSON
+// document.
+type credentialHelperOutput struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RunCredentialHelper runs the credential helper given in the 'auth_command' setting and returns
+// the tokens that it writes to the standard output. The output can be a JSON document with
+// 'access_token' and 'refresh_token' fields, or just a token. The helper receives the URLs and the
+// client identifier in the 'OCM_URL', 'OCM_TOKEN_URL' and 'OCM_CLIENT_ID' environment variables,
+// and its standard input and error are the ones of the tool, so that it can ask the user for
+// credentials if needed.
+func RunCredentialHelper(cfg *Config) (tokens []string, err error) {
+	words := strings.Fields(cfg.AuthCommand)
+	if len(words) == 0 {
+		err = errors.New("Credential helper command isn't set")
+		return
+	}
+	// #nosec G204
+	command := exec.Command(words[0], words[1:]...)
+	command.Env = append(
+		os.Environ(),
+		"OCM_URL="+cfg.URL,
+		"OCM_TOKEN_URL="+cfg.TokenURL,
+		"OCM_CLIENT_ID="+cfg.ClientID,
+	)
+	command.Stdin = os.Stdin
+	command.Stderr = os.Stderr
+	stdout := &bytes.Buffer{}
+	command.Stdout = stdout
+	err = command.Run()
+	if err != nil {
+		err = fmt.Errorf("Credential helper '%s' failed: %v", words[0], err)
+		return
+	}
+	text := strings.TrimSpace(stdout.String())
+	if strings.HasPrefix(text, "{") {
+		output := &credentialHelperOutput{}
+		err = json.Unmarshal([]byte(text), output)
+		if err != nil {
+			err = fmt.Errorf("Can't parse output of credential helper '%s': %v", words[0], err)
+			return
+		}
+		for _, token := range []string{output.AccessToken, output.RefreshToken} {
+			if token != "" {
+				tokens = append(tokens, token)
+			}
+		}
+	} else if text != "" {
+		tokens = append(tokens, text)
+	}
+	if len(tokens) == 0 {
+		err = fmt.Errorf("Credential helper '%s' didn't return a token", words[0])
+		return
+	}
+	return
+}
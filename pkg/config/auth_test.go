@@ -0,0 +1,311 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+
+	"github.com/openshift-online/ocm-cli/pkg/devicecode"
+)
+
+// fakeAuthProvider is an authentication provider used to check that providers can be registered.
+type fakeAuthProvider struct {
+	token string
+}
+
+func (p *fakeAuthProvider) Armed(cfg *Config) (bool, string, error) {
+	return true, "", nil
+}
+
+func (p *fakeAuthProvider) Configure(cfg *Config, builder *sdk.ConnectionBuilder) error {
+	builder.Tokens(p.token)
+	return nil
+}
+
+var _ = Describe("Authentication providers", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-auth-*")
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("OCM_CONFIG", filepath.Join(tmpDir, "ocm.json"))
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("OCM_CONFIG")
+		os.RemoveAll(tmpDir)
+	})
+
+	// writeHelper writes a credential helper script and returns its path.
+	writeHelper := func(script string) string {
+		path := filepath.Join(tmpDir, "helper.sh")
+		err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0700) // #nosec G306
+		Expect(err).ToNot(HaveOccurred())
+		return path
+	}
+
+	It("Rejects unknown providers", func() {
+		cfg := &Config{
+			AuthProvider: "junk",
+			URL:          "http://my-server.example.com",
+		}
+		_, _, err := cfg.Armed()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(
+			"Unknown authentication provider 'junk', valid values are ",
+		))
+		Expect(err.Error()).To(ContainSubstring("client-credentials"))
+	})
+
+	It("Uses only tokens with the token provider", func() {
+		cfg := &Config{
+			AuthProvider: AuthProviderToken,
+			ClientID:     "my-client",
+			ClientSecret: "my-secret",
+			URL:          "http://my-server.example.com",
+			TokenURL:     "http://my-sso.example.com",
+		}
+		armed, reason, err := cfg.Armed()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(armed).To(BeFalse())
+		Expect(reason).To(Equal("credentials aren't set"))
+
+		cfg.RefreshToken = MakeTokenString("Refresh", 10*time.Hour)
+		armed, _, err = cfg.Armed()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(armed).To(BeTrue())
+	})
+
+	It("Requires the secret with the client credentials provider", func() {
+		cfg := &Config{
+			AuthProvider: AuthProviderClientCredentials,
+			ClientID:     "my-client",
+			URL:          "http://my-server.example.com",
+			TokenURL:     "http://my-sso.example.com",
+		}
+		armed, _, err := cfg.Armed()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(armed).To(BeFalse())
+
+		cfg.ClientSecret = "my-secret"
+		armed, _, err = cfg.Armed()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(armed).To(BeTrue())
+	})
+
+	It("Requires the password with the password provider", func() {
+		cfg := &Config{
+			AuthProvider: AuthProviderPassword,
+			User:         "my-user",
+			URL:          "http://my-server.example.com",
+			TokenURL:     "http://my-sso.example.com",
+		}
+		armed, _, err := cfg.Armed()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(armed).To(BeFalse())
+
+		cfg.Password = "my-password"
+		armed, _, err = cfg.Armed()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(armed).To(BeTrue())
+	})
+
+	It("Uses registered providers", func() {
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+		RegisterAuthProvider("fake", &fakeAuthProvider{
+			token: accessToken,
+		})
+		defer func() {
+			authProvidersLock.Lock()
+			delete(authProviders, "fake")
+			authProvidersLock.Unlock()
+		}()
+		Expect(AuthProviders()).To(ContainElement("fake"))
+		cfg := &Config{
+			AuthProvider: "fake",
+			URL:          "http://my-server.example.com",
+		}
+		armed, _, err := cfg.Armed()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(armed).To(BeTrue())
+		connection, err := cfg.Connection()
+		Expect(err).ToNot(HaveOccurred())
+		defer connection.Close()
+		returned, _, err := connection.Tokens()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(returned).To(Equal(accessToken))
+	})
+
+	Describe("Device code", func() {
+		var savedInteractive func() bool
+		var savedRunDeviceCode func(*Config) (*devicecode.Tokens, error)
+
+		BeforeEach(func() {
+			savedInteractive = interactive
+			savedRunDeviceCode = runDeviceCode
+		})
+
+		AfterEach(func() {
+			interactive = savedInteractive
+			runDeviceCode = savedRunDeviceCode
+		})
+
+		It("Runs the flow again when the tokens have expired", func() {
+			accessToken := MakeTokenString("Bearer", 15*time.Minute)
+			refreshToken := MakeTokenString("Refresh", 10*time.Hour)
+			interactive = func() bool {
+				return true
+			}
+			count := 0
+			runDeviceCode = func(cfg *Config) (*devicecode.Tokens, error) {
+				count++
+				return &devicecode.Tokens{
+					Access:  accessToken,
+					Refresh: refreshToken,
+				}, nil
+			}
+			cfg := &Config{
+				AuthProvider: AuthProviderDeviceCode,
+				RefreshToken: MakeTokenString("Refresh", -10*time.Hour),
+				URL:          "http://my-server.example.com",
+				TokenURL:     "http://my-sso.example.com",
+			}
+			armed, _, err := cfg.Armed()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(armed).To(BeTrue())
+			connection, err := cfg.Connection()
+			Expect(err).ToNot(HaveOccurred())
+			defer connection.Close()
+			Expect(count).To(Equal(1))
+
+			// The new tokens should have been saved:
+			saved, err := Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(saved.AccessToken).To(Equal(accessToken))
+			Expect(saved.RefreshToken).To(Equal(refreshToken))
+		})
+
+		It("Isn't armed when the tokens have expired and there is no terminal", func() {
+			interactive = func() bool {
+				return false
+			}
+			cfg := &Config{
+				AuthProvider: AuthProviderDeviceCode,
+				RefreshToken: MakeTokenString("Refresh", -10*time.Hour),
+				URL:          "http://my-server.example.com",
+				TokenURL:     "http://my-sso.example.com",
+			}
+			armed, reason, err := cfg.Armed()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(armed).To(BeFalse())
+			Expect(reason).To(Equal("refresh token is expired"))
+		})
+	})
+
+	Describe("Credential helper", func() {
+		It("Is selected when the command is set", func() {
+			cfg := &Config{
+				URL: "http://my-server.example.com",
+			}
+			armed, reason, err := cfg.Armed()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(armed).To(BeFalse())
+			Expect(reason).To(Equal("credentials aren't set"))
+
+			cfg.AuthCommand = "my-helper"
+			armed, _, err = cfg.Armed()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(armed).To(BeTrue())
+		})
+
+		It("Accepts a plain token", func() {
+			accessToken := MakeTokenString("Bearer", 15*time.Minute)
+			helper := writeHelper("echo " + accessToken + "\n")
+			tokens, err := RunCredentialHelper(&Config{
+				AuthCommand: helper,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tokens).To(Equal([]string{accessToken}))
+		})
+
+		It("Accepts a JSON document", func() {
+			helper := writeHelper(
+				`echo '{"access_token": "my-access", "refresh_token": "my-refresh"}'` + "\n",
+			)
+			tokens, err := RunCredentialHelper(&Config{
+				AuthCommand: helper,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tokens).To(Equal([]string{"my-access", "my-refresh"}))
+		})
+
+		It("Passes the arguments and the environment", func() {
+			helper := writeHelper(`echo "$1-$OCM_URL-$OCM_CLIENT_ID"` + "\n")
+			tokens, err := RunCredentialHelper(&Config{
+				AuthCommand: helper + " my-arg",
+				URL:         "http://my-server.example.com",
+				ClientID:    "my-client",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tokens).To(Equal([]string{
+				"my-arg-http://my-server.example.com-my-client",
+			}))
+		})
+
+		It("Fails if the helper fails", func() {
+			helper := writeHelper("exit 1\n")
+			_, err := RunCredentialHelper(&Config{
+				AuthCommand: helper,
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Credential helper '" + helper + "' failed"))
+		})
+
+		It("Fails if the helper doesn't return a token", func() {
+			helper := writeHelper("echo\n")
+			_, err := RunCredentialHelper(&Config{
+				AuthCommand: helper,
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("didn't return a token"))
+		})
+
+		It("Uses the token in connections", func() {
+			accessToken := MakeTokenString("Bearer", 15*time.Minute)
+			helper := writeHelper("echo " + accessToken + "\n")
+			cfg := &Config{
+				AuthCommand: helper,
+				URL:         "http://my-server.example.com",
+			}
+			connection, err := cfg.Connection()
+			Expect(err).ToNot(HaveOccurred())
+			defer connection.Close()
+			returned, _, err := connection.Tokens()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(returned).To(Equal(accessToken))
+		})
+	})
+})
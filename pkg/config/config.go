@@ -25,6 +25,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v4"
@@ -32,39 +33,78 @@ import (
 	homedir "github.com/mitchellh/go-homedir"
 	sdk "github.com/openshift-online/ocm-sdk-go"
 
+	"github.com/openshift-online/ocm-cli/pkg/agent"
+	respcache "github.com/openshift-online/ocm-cli/pkg/cache"
+	"github.com/openshift-online/ocm-cli/pkg/compress"
+	"github.com/openshift-online/ocm-cli/pkg/curl"
 	"github.com/openshift-online/ocm-cli/pkg/debug"
-	"github.com/openshift-online/ocm-cli/pkg/info"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+	"github.com/openshift-online/ocm-cli/pkg/impersonate"
+	"github.com/openshift-online/ocm-cli/pkg/keepalive"
+	"github.com/openshift-online/ocm-cli/pkg/policy"
+	"github.com/openshift-online/ocm-cli/pkg/protection"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+	"github.com/openshift-online/ocm-cli/pkg/trace"
 )
 
 // Config is the type used to store the configuration of the client.
 // There's no way to line-split or predefine tags, so...
+//
 //nolint:lll
 type Config struct {
 	// TODO(efried): Better docs for things like AccessToken
 	// TODO(efried): Dedup with flag docs in cmd/ocm/login/cmd.go:init where possible
-	AccessToken  string   `json:"access_token,omitempty" doc:"Bearer access token."`
-	ClientID     string   `json:"client_id,omitempty" doc:"OpenID client identifier."`
-	ClientSecret string   `json:"client_secret,omitempty" doc:"OpenID client secret."`
-	Insecure     bool     `json:"insecure,omitempty" doc:"Enables insecure communication with the server. This disables verification of TLS certificates and host names."`
-	Password     string   `json:"password,omitempty" doc:"User password."`
-	RefreshToken string   `json:"refresh_token,omitempty" doc:"Offline or refresh token."`
-	Scopes       []string `json:"scopes,omitempty" doc:"OpenID scope. If this option is used it will replace completely the default scopes. Can be repeated multiple times to specify multiple scopes."`
-	TokenURL     string   `json:"token_url,omitempty" doc:"OpenID token URL."`
-	URL          string   `json:"url,omitempty" doc:"URL of the API gateway. The value can be the complete URL or an alias. The valid aliases are 'production', 'staging' and 'integration'."`
-	User         string   `json:"user,omitempty" doc:"User name."`
-	Pager        string   `json:"pager,omitempty" doc:"Pager command, for example 'less'. If empty no pager will be used."`
+	AccessToken         string   `json:"access_token,omitempty" doc:"Bearer access token."`
+	ClientID            string   `json:"client_id,omitempty" doc:"OpenID client identifier."`
+	ClientSecret        string   `json:"client_secret,omitempty" doc:"OpenID client secret."`
+	Insecure            bool     `json:"insecure,omitempty" doc:"Enables insecure communication with the server. This disables verification of TLS certificates and host names."`
+	Password            string   `json:"password,omitempty" doc:"User password."`
+	RefreshToken        string   `json:"refresh_token,omitempty" doc:"Offline or refresh token."`
+	Scopes              []string `json:"scopes,omitempty" doc:"OpenID scope. If this option is used it will replace completely the default scopes. Can be repeated multiple times to specify multiple scopes."`
+	TokenURL            string   `json:"token_url,omitempty" doc:"OpenID token URL."`
+	URL                 string   `json:"url,omitempty" doc:"URL of the API gateway. The value can be the complete URL or an alias. The valid aliases are 'production', 'staging' and 'integration'."`
+	User                string   `json:"user,omitempty" doc:"User name."`
+	Pager               string   `json:"pager,omitempty" doc:"Pager command, for example 'less'. If empty no pager will be used."`
+	ReadOnly            bool     `json:"read_only,omitempty" doc:"Rejects the commands and requests that would change the server, for example in shared terminals. Can also be enabled with the 'OCM_READ_ONLY' environment variable."`
+	AuthProvider        string   `json:"auth_provider,omitempty" doc:"Authentication provider: 'token', 'client-credentials', 'password', 'device-code' or 'exec'. If empty it is selected according to the credentials present in the configuration."`
+	AuthCommand         string   `json:"auth_command,omitempty" doc:"Credential helper command used by the 'exec' authentication provider, with its arguments separated by spaces. It must write a token, or a JSON document with 'access_token' and 'refresh_token' fields, to the standard output."`
+	ProductionInterlock string   `json:"production_interlock,omitempty" doc:"How changes to clusters labeled with 'protection=production' are checked: 'confirm' asks when running in a terminal and otherwise requires the '--i-know-this-is-production' option, 'flag' always requires the option and 'off' disables the check. The default is 'off'."`
+	DefaultCluster      string   `json:"default_cluster,omitempty" doc:"Name, identifier or external identifier of the cluster that is currently being worked on, displayed by 'ocm context status' so that it can be added to the shell prompt."`
+	UserAgent           string   `json:"user_agent,omitempty" doc:"Identifier of the team or tool, for example 'my-team-tool/1.0', added to the 'User-Agent' header of the requests sent to the API so that the traffic of automation can be attributed. The '--user-agent' option and the 'OCM_USER_AGENT' environment variable take precedence."`
 }
 
-// Load loads the configuration from the configuration file. If the configuration file doesn't exist
-// it will return an empty configuration object.
+// Load loads the configuration of the selected context from the configuration file. If the
+// configuration file doesn't exist, or it doesn't contain the selected context, it will return an
+// empty configuration object. See the SelectedContext function for details about how the context is
+// selected.
+//
+// The result is cached for the life of the process: loading the configuration again in the same
+// process returns the same tokens, without reading the file that may have been modified by other
+// processes in the meantime. The cache is only updated by Save. This is intended for short lived
+// commands, so that all the connections created by the same command use the same tokens.
 func Load() (cfg *Config, err error) {
 	file, err := Location()
 	if err != nil {
 		return
 	}
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	doc, ok := cache[file]
+	if !ok {
+		doc, err = load(file)
+		if err != nil {
+			return
+		}
+		cache[file] = doc.copy()
+	}
+	cfg = doc.get(doc.selected())
+	return
+}
+
+func load(file string) (doc *document, err error) {
 	_, err = os.Stat(file)
 	if os.IsNotExist(err) {
-		cfg = &Config{}
+		doc = &document{}
 		err = nil
 		return
 	}
@@ -78,11 +118,11 @@ func Load() (cfg *Config, err error) {
 		err = fmt.Errorf("can't read config file '%s': %v", file, err)
 		return
 	}
-	cfg = &Config{}
+	doc = &document{}
 	if len(data) == 0 {
 		return
 	}
-	err = json.Unmarshal(data, cfg)
+	err = json.Unmarshal(data, doc)
 	if err != nil {
 		err = fmt.Errorf("can't parse config file '%s': %v", file, err)
 		return
@@ -90,8 +130,47 @@ func Load() (cfg *Config, err error) {
 	return
 }
 
-// Save saves the given configuration to the configuration file.
+// Save saves the given configuration to the selected context of the configuration file, keeping
+// the other contexts unchanged.
+//
+// Other processes may have saved the file after this process loaded it, for example when several
+// jobs refresh the tokens simultaneously. To avoid discarding their tokens the file is read again
+// while holding the lock, and tokens saved by other processes are preserved when they are for the
+// same server and expire later than the ones being saved. The file is written atomically, so that
+// readers never see a partially written file.
 func Save(cfg *Config) error {
+	return update(func(doc *document, loaded *document) error {
+		// Save to the context that was loaded, even if other process changed the current
+		// context in the meantime:
+		name := doc.selected()
+		if loaded != nil {
+			name = loaded.selected()
+		} else {
+			loaded = &document{}
+		}
+
+		// Merge the tokens that other processes may have saved since this process loaded
+		// the file:
+		current := doc.get(name)
+		previous := loaded.get(name)
+		result := cfg.copy()
+		if current.URL == result.URL && current.TokenURL == result.TokenURL {
+			result.AccessToken = newerToken(
+				result.AccessToken, previous.AccessToken, current.AccessToken,
+			)
+			result.RefreshToken = newerToken(
+				result.RefreshToken, previous.RefreshToken, current.RefreshToken,
+			)
+		}
+		doc.set(name, result)
+		return nil
+	})
+}
+
+// update reads the configuration file while holding the lock, calls the given function to modify
+// it and then writes the result and updates the cache. The function receives the content of the
+// file and the content that this process loaded before, which will be nil if it didn't load it.
+func update(modify func(doc *document, loaded *document) error) error {
 	file, err := Location()
 	if err != nil {
 		return err
@@ -101,20 +180,140 @@ func Save(cfg *Config) error {
 	if err != nil {
 		return fmt.Errorf("can't create directory %s: %v", dir, err)
 	}
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	unlock, err := LockFile(file)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	doc, err := load(file)
+	if err != nil {
+		return err
+	}
+	cacheLock.Lock()
+	loaded := cache[file]
+	cacheLock.Unlock()
+	err = modify(doc, loaded)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
 	if err != nil {
 		return fmt.Errorf("can't marshal config: %v", err)
 	}
-	err = ioutil.WriteFile(file, data, 0600)
+	err = WriteFile(file, data)
+	if err != nil {
+		return err
+	}
+
+	// Update the cache:
+	cacheLock.Lock()
+	cache[file] = doc.copy()
+	cacheLock.Unlock()
+
+	return nil
+}
+
+// newerToken decides which token should be saved. The saved token is the one that the caller wants
+// to save, unless another process replaced the token that this process loaded with one that
+// expires later. Empty tokens, for example after logging out, and tokens that don't expire are
+// always saved.
+func newerToken(saved, loaded, current string) string {
+	if saved == "" || current == "" || current == loaded || current == saved {
+		return saved
+	}
+	savedExpires, savedLeft, err := TokenLeft(saved)
+	if err != nil || !savedExpires {
+		return saved
+	}
+	currentExpires, currentLeft, err := TokenLeft(current)
+	if err != nil || !currentExpires {
+		return saved
+	}
+	if currentLeft > savedLeft {
+		return current
+	}
+	return saved
+}
+
+// WriteFile writes the data to a temporary file in the same directory and then renames it to
+// replace the given file, so that readers never see a partially written file. The file is only
+// readable by the owner.
+func WriteFile(file string, data []byte) (err error) {
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("can't create temporary file for '%s': %v", file, err)
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
 	if err != nil {
 		return fmt.Errorf("can't write file '%s': %v", file, err)
 	}
 	return nil
 }
 
+// LockFile acquires an exclusive lock for the given file, waiting till it is available. The lock
+// is implemented using a separate lock file, so that it survives the rename used by WriteFile to
+// replace the file. The returned function releases the lock.
+func LockFile(file string) (unlock func(), err error) {
+	path := file + ".lock"
+	// #nosec G304
+	lock, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		err = fmt.Errorf("can't open lock file '%s': %v", path, err)
+		return
+	}
+	err = lockFD(lock)
+	if err != nil {
+		lock.Close()
+		err = fmt.Errorf("can't lock file '%s': %v", path, err)
+		return
+	}
+	unlock = func() {
+		unlockFD(lock)
+		lock.Close()
+	}
+	return
+}
+
+// copy returns a copy of the configuration that can be modified without affecting the original.
+func (c *Config) copy() *Config {
+	result := *c
+	if c.Scopes != nil {
+		result.Scopes = make([]string, len(c.Scopes))
+		copy(result.Scopes, c.Scopes)
+	}
+	return &result
+}
+
+// cache contains the configuration files that have already been loaded or saved by this process,
+// indexed by their location.
+var (
+	cache     = map[string]*document{}
+	cacheLock = &sync.Mutex{}
+)
+
 // Location returns the location of the configuration file. If a configuration file
 // already exists in the HOME directory, it uses that, otherwise it prefers to
-// use the XDG config directory.
+// use the XDG config directory, or the %APPDATA% directory on Windows.
 func Location() (path string, err error) {
 	if ocmconfig := os.Getenv("OCM_CONFIG"); ocmconfig != "" {
 		return ocmconfig, nil
@@ -137,25 +336,31 @@ func Location() (path string, err error) {
 		}
 
 		// Use standard config directory
-		path = filepath.Join(configDir, "/ocm/ocm.json")
+		path = filepath.Join(configDir, "ocm", "ocm.json")
 	}
 
 	return path, nil
 }
 
 // Armed checks if the configuration contains either credentials or tokens that haven't expired, so
-// that it can be used to perform authenticated requests.
+// that it can be used to perform authenticated requests. The check is delegated to the selected
+// authentication provider.
 func (c *Config) Armed() (armed bool, reason string, err error) {
+	provider, err := c.authProvider()
+	if err != nil {
+		return
+	}
+	return provider.Armed(c)
+}
+
+// armed checks if the configuration contains the URLs, and either the credentials that the
+// caller found or tokens that haven't expired.
+func (c *Config) armed(haveCredentials bool) (armed bool, reason string, err error) {
 	// Check URLs:
 	haveURL := c.URL != ""
 	haveTokenURL := c.TokenURL != ""
 	haveURLs := haveURL && haveTokenURL
 
-	// Check credentials:
-	havePassword := c.User != "" && c.Password != ""
-	haveSecret := c.ClientID != "" && c.ClientSecret != ""
-	haveCredentials := havePassword || haveSecret
-
 	// Check tokens:
 	haveAccess := c.AccessToken != ""
 	accessUsable := false
@@ -216,10 +421,35 @@ func (c *Config) Disarm() {
 	c.TokenURL = ""
 	c.URL = ""
 	c.User = ""
+	c.AuthProvider = ""
+	c.AuthCommand = ""
+}
+
+// ReadOnlyMode checks if the read-only mode is enabled, either in this configuration or with the
+// 'OCM_READ_ONLY' environment variable.
+func (c *Config) ReadOnlyMode() bool {
+	return c.ReadOnly || readonly.EnvEnabled()
 }
 
 // Connection creates a connection using this configuration.
 func (c *Config) Connection() (connection *sdk.Connection, err error) {
+	builder, err := c.ConnectionBuilder()
+	if err != nil {
+		return
+	}
+	connection, err = builder.Build()
+	return
+}
+
+// Retry settings used for all the connections:
+const (
+	retryLimit    = 4
+	retryInterval = time.Second
+)
+
+// ConnectionBuilder creates a connection builder configured with the settings of this
+// configuration, so that callers can add other settings before building the connection.
+func (c *Config) ConnectionBuilder() (builder *sdk.ConnectionBuilder, err error) {
 	// Create the logger:
 	level := glog.Level(1)
 	if debug.Enabled() {
@@ -234,44 +464,179 @@ func (c *Config) Connection() (connection *sdk.Connection, err error) {
 		return
 	}
 
+	// Calculate the user agent, including the identifier of the team or tool if there is one:
+	userAgent, err := agent.Value(c.UserAgent)
+	if err != nil {
+		return
+	}
+
 	// Prepare the builder for the connection adding only the properties that have explicit
 	// values in the configuration, so that default values won't be overridden:
-	builder := sdk.NewConnectionBuilder()
+	builder = sdk.NewConnectionBuilder()
 	builder.Logger(logger)
-	builder.Agent("OCM-CLI/" + info.Version)
+	builder.Agent(userAgent)
 	if c.TokenURL != "" {
 		builder.TokenURL(c.TokenURL)
 	}
-	if c.ClientID != "" || c.ClientSecret != "" {
-		builder.Client(c.ClientID, c.ClientSecret)
-	}
 	if c.Scopes != nil {
 		builder.Scopes(c.Scopes...)
 	}
 	if c.URL != "" {
 		builder.URL(c.URL)
 	}
-	if c.User != "" || c.Password != "" {
-		builder.User(c.User, c.Password)
+	builder.Insecure(c.Insecure)
+
+	// When the command is only being validated nothing is sent, so a placeholder token is used
+	// instead of the credentials, and the wrapper that intercepts the requests must be the
+	// outermost one:
+	if dryrun.Enabled() {
+		var token string
+		token, err = dryrun.Token()
+		if err != nil {
+			return
+		}
+		builder.Tokens(token)
+		builder.TransportWrapper(dryrun.TransportWrapper())
+		return
+	}
+
+	// The credentials are added by the authentication provider:
+	provider, err := c.authProvider()
+	if err != nil {
+		return
+	}
+	err = provider.Configure(c, builder)
+	if err != nil {
+		return
 	}
-	tokens := make([]string, 0, 2)
-	if c.AccessToken != "" {
-		tokens = append(tokens, c.AccessToken)
+
+	// The SDK retries requests that fail with 429 or 503, and also GET requests that fail with
+	// other 5xx codes, but only twice by default, and that isn't enough for large organizations
+	// that hit the rate limits often:
+	builder.RetryLimit(retryLimit)
+	builder.RetryInterval(retryInterval)
+
+	tokenURL := c.TokenURL
+	if tokenURL == "" {
+		tokenURL = sdk.DefaultTokenURL
+	}
+	if c.ReadOnlyMode() {
+		builder.TransportWrapper(readonly.TransportWrapper(tokenURL))
+	}
+	if interactive() && c.tokenBased() {
+		builder.TransportWrapper(c.reauthWrapper(tokenURL))
 	}
 	if c.RefreshToken != "" {
-		tokens = append(tokens, c.RefreshToken)
+		builder.TransportWrapper(c.rotateWrapper(tokenURL))
 	}
-	if len(tokens) > 0 {
-		builder.Tokens(tokens...)
+	if c.ProductionInterlock == protection.ModeConfirm || c.ProductionInterlock == protection.ModeFlag {
+		var ask protection.Asker
+		if c.ProductionInterlock == protection.ModeConfirm && interactive() {
+			ask = askProduction
+		}
+		builder.TransportWrapper(protection.TransportWrapper(tokenURL, ask))
 	}
-	builder.Insecure(c.Insecure)
-
-	// Create the connection:
-	connection, err = builder.Build()
+	location, _ := Location()
+	policies, err := policy.Load(policy.Location(location))
 	if err != nil {
+		err = fmt.Errorf("Can't load policies: %v", err)
 		return
 	}
+	if len(policies) > 0 {
+		builder.TransportWrapper(policy.TransportWrapper(policies, os.Stderr))
+	}
+	builder.TransportWrapper(compress.TransportWrapper())
+	if impersonate.Enabled() {
+		builder.TransportWrapper(impersonate.TransportWrapper(tokenURL, os.Stderr))
+	}
+	if respcache.Enabled() {
+		builder.TransportWrapper(respcache.TransportWrapper(tokenURL, os.Stderr))
+	}
+	if trace.Enabled() {
+		builder.TransportWrapper(trace.TransportWrapper(os.Stderr))
+	}
+	if curl.Enabled() {
+		builder.TransportWrapper(curl.TransportWrapper(tokenURL, os.Stderr))
+	}
+
+	// This needs to be the last wrapper, because it is the one that receives the transport
+	// created by the SDK:
+	builder.TransportWrapper(keepalive.TransportWrapper())
+
+	return
+}
+
+// SetToken puts the given token in the field of the configuration that corresponds to its type,
+// as indicated by the 'typ' claim.
+func (c *Config) SetToken(textToken string) error {
+	token, err := parseToken(textToken)
+	if err != nil {
+		return err
+	}
+	typ, err := tokenType(token)
+	if err != nil {
+		return fmt.Errorf("Can't extract type from 'typ' claim of token '%s': %v", textToken, err)
+	}
+	switch typ {
+	case "Bearer":
+		c.AccessToken = textToken
+	case "Refresh", "Offline":
+		c.RefreshToken = textToken
+	case "":
+		return fmt.Errorf("Don't know how to handle empty type in token '%s'", textToken)
+	default:
+		return fmt.Errorf("Don't know how to handle token type '%s' in token '%s'", typ, textToken)
+	}
+	return nil
+}
+
+// UserName returns the name of the user, extracted from the 'preferred_username' or 'username'
+// claims of the saved tokens without contacting the server. If there are no tokens, or they don't
+// have those claims, it returns the user or client identifier used to log in.
+func (c *Config) UserName() string {
+	for _, text := range []string{c.AccessToken, c.RefreshToken} {
+		if text == "" {
+			continue
+		}
+		token, err := parseToken(text)
+		if err != nil {
+			continue
+		}
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			continue
+		}
+		for _, name := range []string{"preferred_username", "username"} {
+			value, ok := claims[name].(string)
+			if ok && value != "" {
+				return value
+			}
+		}
+	}
+	if c.User != "" {
+		return c.User
+	}
+	return c.ClientID
+}
 
+// tokenType extracts the value of the `typ` claim. It returns the value as a string, or the empty
+// string if there is no such claim.
+func tokenType(token *jwt.Token) (typ string, err error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		err = fmt.Errorf("expected map claims but got %T", claims)
+		return
+	}
+	claim, ok := claims["typ"]
+	if !ok {
+		return
+	}
+	value, ok := claim.(string)
+	if !ok {
+		err = fmt.Errorf("expected string 'typ' but got %T", claim)
+		return
+	}
+	typ = value
 	return
 }
 
@@ -306,6 +671,16 @@ func tokenUsable(token string, margin time.Duration) (usable bool, err error) {
 	return
 }
 
+// TokenLeft parses the given token and determines if it expires, and the time that remains till it
+// expires.
+func TokenLeft(token string) (expires bool, left time.Duration, err error) {
+	parsed, err := parseToken(token)
+	if err != nil {
+		return
+	}
+	return tokenExpiration(parsed)
+}
+
 // tokenExpiration determines if the given token expires, and the time that remains till it expires.
 func tokenExpiration(token *jwt.Token) (expires bool, left time.Duration, err error) {
 	claims, ok := token.Claims.(jwt.MapClaims)
@@ -19,6 +19,7 @@ package config
 import (
 	"time"
 
+	jwt "github.com/golang-jwt/jwt/v4"
 	. "github.com/onsi/ginkgo/v2" // nolint
 	. "github.com/onsi/gomega"    // nolint
 
@@ -171,3 +172,45 @@ var _ = Describe("Armed", func() {
 		Expect(reason).To(Equal("credentials aren't set"))
 	})
 })
+
+var _ = Describe("UserName", func() {
+	It("Returns the preferred user name of the access token", func() {
+		config := &Config{
+			AccessToken: MakeTokenObject(jwt.MapClaims{
+				"preferred_username": "my-user",
+			}).Raw,
+			User: "other-user",
+		}
+		Expect(config.UserName()).To(Equal("my-user"))
+	})
+
+	It("Returns the user name of the refresh token if there is no access token", func() {
+		config := &Config{
+			RefreshToken: MakeTokenObject(jwt.MapClaims{
+				"typ":      "Refresh",
+				"username": "my-user",
+			}).Raw,
+		}
+		Expect(config.UserName()).To(Equal("my-user"))
+	})
+
+	It("Returns the user if tokens don't have user name", func() {
+		config := &Config{
+			AccessToken: MakeTokenString("Bearer", 15*time.Minute),
+			User:        "my-user",
+		}
+		Expect(config.UserName()).To(Equal("my-user"))
+	})
+
+	It("Returns the client identifier if there is no user", func() {
+		config := &Config{
+			ClientID: "my-client",
+		}
+		Expect(config.UserName()).To(Equal("my-client"))
+	})
+
+	It("Returns empty string if empty", func() {
+		config := &Config{}
+		Expect(config.UserName()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,195 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types and functions used to manage multiple named contexts, each with its
+// own server and credentials, in the same configuration file.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/pflag"
+)
+
+// DefaultContext is the name of the context that uses the settings at the top level of the
+// configuration file, the only one that existed before named contexts were supported.
+const DefaultContext = "default"
+
+// ContextEnvVar is the name of the environment variable that can be used to select the context
+// instead of the '--context' command line flag.
+const ContextEnvVar = "OCM_CONTEXT"
+
+// AddContextFlag adds the '--context' flag to the given set of command line flags.
+func AddContextFlag(flags *pflag.FlagSet) {
+	flags.StringVar(
+		&contextFlag,
+		"context",
+		"",
+		"Name of the configuration context to use instead of the current one. Use "+
+			"'ocm config get-contexts' to see the available contexts.",
+	)
+}
+
+// contextFlag is the value of the '--context' command line flag.
+var contextFlag string
+
+// document is the content of the configuration file. The settings at the top level are the ones
+// of the default context, and the settings of the other contexts are in the 'contexts' field.
+type document struct {
+	Config
+	CurrentContext string             `json:"current_context,omitempty"`
+	Contexts       map[string]*Config `json:"contexts,omitempty"`
+}
+
+// selected returns the name of the context selected with the '--context' command line flag, the
+// 'OCM_CONTEXT' environment variable or the 'current_context' setting of the file, in that order.
+func (d *document) selected() string {
+	switch {
+	case contextFlag != "":
+		return contextFlag
+	case os.Getenv(ContextEnvVar) != "":
+		return os.Getenv(ContextEnvVar)
+	case d.CurrentContext != "":
+		return d.CurrentContext
+	default:
+		return DefaultContext
+	}
+}
+
+// exists checks if the document contains the given context. The default context always exists.
+func (d *document) exists(name string) bool {
+	if name == DefaultContext {
+		return true
+	}
+	_, ok := d.Contexts[name]
+	return ok
+}
+
+// get returns a copy of the configuration of the given context, or an empty configuration if
+// the context doesn't exist.
+func (d *document) get(name string) *Config {
+	if name == DefaultContext {
+		return d.Config.copy()
+	}
+	cfg, ok := d.Contexts[name]
+	if !ok {
+		return &Config{}
+	}
+	return cfg.copy()
+}
+
+// set replaces the configuration of the given context, creating it if it doesn't exist.
+func (d *document) set(name string, cfg *Config) {
+	if name == DefaultContext {
+		d.Config = *cfg.copy()
+		return
+	}
+	if d.Contexts == nil {
+		d.Contexts = map[string]*Config{}
+	}
+	d.Contexts[name] = cfg.copy()
+}
+
+// copy returns a copy of the document that can be modified without affecting the original.
+func (d *document) copy() *document {
+	result := &document{
+		Config:         *d.Config.copy(),
+		CurrentContext: d.CurrentContext,
+	}
+	if d.Contexts != nil {
+		result.Contexts = make(map[string]*Config, len(d.Contexts))
+		for name, cfg := range d.Contexts {
+			result.Contexts[name] = cfg.copy()
+		}
+	}
+	return result
+}
+
+// SelectedContext returns the name of the context that is used by this process. It is the one given with
+// the '--context' command line flag or the 'OCM_CONTEXT' environment variable, or else the one
+// selected with the 'ocm config use-context' command.
+func SelectedContext() (name string, err error) {
+	file, err := Location()
+	if err != nil {
+		return
+	}
+	doc, err := load(file)
+	if err != nil {
+		return
+	}
+	name = doc.selected()
+	return
+}
+
+// Contexts returns the sorted names of the contexts of the configuration file, always including
+// the default context.
+func Contexts() (names []string, err error) {
+	file, err := Location()
+	if err != nil {
+		return
+	}
+	doc, err := load(file)
+	if err != nil {
+		return
+	}
+	names = []string{DefaultContext}
+	for name := range doc.Contexts {
+		if name != DefaultContext {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return
+}
+
+// LoadContext loads the configuration of the given context, regardless of the selected one. It
+// returns an error if the context doesn't exist.
+func LoadContext(name string) (cfg *Config, err error) {
+	file, err := Location()
+	if err != nil {
+		return
+	}
+	doc, err := load(file)
+	if err != nil {
+		return
+	}
+	if !doc.exists(name) {
+		err = fmt.Errorf("Context '%s' doesn't exist", name)
+		return
+	}
+	cfg = doc.get(name)
+	return
+}
+
+// UseContext changes the current context of the configuration file, so that it is used by the
+// commands that don't explicitly select another one. It returns an error if the context doesn't
+// exist.
+func UseContext(name string) error {
+	return update(func(doc *document, loaded *document) error {
+		if !doc.exists(name) {
+			return fmt.Errorf("Context '%s' doesn't exist", name)
+		}
+		if name == DefaultContext {
+			doc.CurrentContext = ""
+		} else {
+			doc.CurrentContext = name
+		}
+		return nil
+	})
+}
@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Contexts", func() {
+	var tmpDir string
+	var file string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-config-*")
+		Expect(err).ToNot(HaveOccurred())
+		file = filepath.Join(tmpDir, "ocm.json")
+		os.Setenv("OCM_CONFIG", file)
+	})
+
+	AfterEach(func() {
+		contextFlag = ""
+		os.Unsetenv(ContextEnvVar)
+		os.Unsetenv("OCM_CONFIG")
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Uses the top level settings for the default context", func() {
+		Expect(Save(&Config{URL: "https://api.openshift.com"})).To(Succeed())
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(MatchJSON(`{
+			"url": "https://api.openshift.com"
+		}`))
+		name, err := SelectedContext()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(name).To(Equal(DefaultContext))
+	})
+
+	It("Saves and loads the context selected with the flag", func() {
+		Expect(Save(&Config{URL: "https://api.openshift.com"})).To(Succeed())
+		contextFlag = "staging"
+		Expect(Save(&Config{URL: "https://api.stage.openshift.com"})).To(Succeed())
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(MatchJSON(`{
+			"url": "https://api.openshift.com",
+			"contexts": {
+				"staging": {
+					"url": "https://api.stage.openshift.com"
+				}
+			}
+		}`))
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.URL).To(Equal("https://api.stage.openshift.com"))
+		contextFlag = ""
+		cfg, err = Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.URL).To(Equal("https://api.openshift.com"))
+	})
+
+	It("Selects the context with the environment variable", func() {
+		contextFlag = "staging"
+		Expect(Save(&Config{URL: "https://api.stage.openshift.com"})).To(Succeed())
+		contextFlag = ""
+		os.Setenv(ContextEnvVar, "staging")
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.URL).To(Equal("https://api.stage.openshift.com"))
+	})
+
+	It("Returns an empty configuration for a context that doesn't exist", func() {
+		Expect(Save(&Config{URL: "https://api.openshift.com"})).To(Succeed())
+		contextFlag = "junk"
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.URL).To(BeEmpty())
+	})
+
+	It("Changes the current context", func() {
+		Expect(Save(&Config{URL: "https://api.openshift.com"})).To(Succeed())
+		contextFlag = "staging"
+		Expect(Save(&Config{URL: "https://api.stage.openshift.com"})).To(Succeed())
+		contextFlag = ""
+
+		Expect(UseContext("staging")).To(Succeed())
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		var doc document
+		Expect(json.Unmarshal(data, &doc)).To(Succeed())
+		Expect(doc.CurrentContext).To(Equal("staging"))
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.URL).To(Equal("https://api.stage.openshift.com"))
+
+		// Saving now modifies the current context:
+		cfg.AccessToken = "my-token"
+		Expect(Save(cfg)).To(Succeed())
+		cfg, err = LoadContext("staging")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.AccessToken).To(Equal("my-token"))
+		cfg, err = LoadContext(DefaultContext)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.AccessToken).To(BeEmpty())
+
+		Expect(UseContext(DefaultContext)).To(Succeed())
+		cfg, err = Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.URL).To(Equal("https://api.openshift.com"))
+	})
+
+	It("Doesn't change to a context that doesn't exist", func() {
+		err := UseContext("junk")
+		Expect(err).To(MatchError("Context 'junk' doesn't exist"))
+	})
+
+	It("Lists the contexts", func() {
+		for _, name := range []string{"staging", "integration"} {
+			contextFlag = name
+			Expect(Save(&Config{})).To(Succeed())
+		}
+		names, err := Contexts()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(names).To(Equal([]string{DefaultContext, "integration", "staging"}))
+	})
+})
@@ -0,0 +1,42 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// askProduction asks the user to confirm a change to a production cluster. It is a variable so
+// that it can be replaced in tests.
+var askProduction = func(cluster string) (ok bool, err error) {
+	err = survey.AskOne(
+		&survey.Confirm{
+			Message: fmt.Sprintf(
+				"Cluster '%s' is labeled as a production cluster, do you really want "+
+					"to change it?",
+				cluster,
+			),
+			Default: false,
+		},
+		&ok,
+		survey.WithStdio(os.Stdin, os.Stderr, os.Stderr),
+	)
+	return
+}
@@ -0,0 +1,121 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+// Values used in the token exchange requests described in RFC 8693:
+const (
+	tokenExchangeGrant   = "urn:ietf:params:oauth:grant-type:token-exchange"
+	accessTokenTokenType = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// tokenResponse is used to decode the responses of the token endpoint.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// MintToken requests from the SSO server a new access token with the given scopes or for the given
+// audience, so that tools that need such tokens don't have to implement the authentication
+// themselves. When an audience is given the access token is exchanged as described in RFC 8693,
+// otherwise the refresh token or the client credentials are used to request a token with the
+// given scopes. The resulting token isn't saved to the configuration.
+func (c *Config) MintToken(accessToken, refreshToken string, scopes []string,
+	audience string) (result string, err error) {
+	clientID := c.ClientID
+	if clientID == "" {
+		clientID = sdk.DefaultClientID
+	}
+	form := url.Values{}
+	form.Set("client_id", clientID)
+	if c.ClientSecret != "" {
+		form.Set("client_secret", c.ClientSecret)
+	}
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+	switch {
+	case audience != "":
+		if accessToken == "" {
+			err = fmt.Errorf("Can't request a token for audience '%s' without an access token",
+				audience)
+			return
+		}
+		form.Set("grant_type", tokenExchangeGrant)
+		form.Set("subject_token", accessToken)
+		form.Set("subject_token_type", accessTokenTokenType)
+		form.Set("requested_token_type", accessTokenTokenType)
+		form.Set("audience", audience)
+	case refreshToken != "":
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", refreshToken)
+	case c.ClientID != "" && c.ClientSecret != "":
+		form.Set("grant_type", "client_credentials")
+	default:
+		err = fmt.Errorf("Can't request a new token without a refresh token or client " +
+			"credentials")
+		return
+	}
+
+	tokenURL := c.TokenURL
+	if tokenURL == "" {
+		tokenURL = sdk.DefaultTokenURL
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.Insecure {
+		// #nosec G402
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	client := &http.Client{Transport: transport}
+	response, err := client.PostForm(tokenURL, form)
+	if err != nil {
+		err = fmt.Errorf("Can't send token request: %v", err)
+		return
+	}
+	defer response.Body.Close()
+	body := &tokenResponse{}
+	err = json.NewDecoder(response.Body).Decode(body)
+	if err != nil && response.StatusCode < http.StatusBadRequest {
+		err = fmt.Errorf("Can't parse token response: %v", err)
+		return
+	}
+	err = nil
+	if response.StatusCode >= http.StatusBadRequest || body.AccessToken == "" {
+		reason := body.Description
+		if reason == "" {
+			reason = body.Error
+		}
+		if reason == "" {
+			reason = fmt.Sprintf("status is %d", response.StatusCode)
+		}
+		err = fmt.Errorf("SSO server rejected token request: %s", reason)
+		return
+	}
+	result = body.AccessToken
+	return
+}
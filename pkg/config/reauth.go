@@ -0,0 +1,227 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/AlecAivazis/survey/v2"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+
+	"github.com/openshift-online/ocm-cli/pkg/output"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
+)
+
+// interactive checks if the tool is running in a terminal where the user can be asked for a new
+// token. It is a variable so that it can be replaced in tests.
+var interactive = func() bool {
+	return output.IsTerminal(os.Stdin) && output.IsTerminal(os.Stderr)
+}
+
+// askToken asks the user for a new token, explaining the reason. It returns an empty string if the
+// user doesn't want to provide one. It is a variable so that it can be replaced in tests.
+var askToken = func(reason string) (token string, err error) {
+	fmt.Fprintf(
+		os.Stderr,
+		"Your session has expired: %s.\n"+
+			"Go to %s to obtain a new token and paste it below, or leave it empty to "+
+			"abort.\n",
+		reason, urls.OfflineTokenPage,
+	)
+	err = survey.AskOne(
+		&survey.Password{
+			Message: "Token:",
+		},
+		&token,
+		survey.WithStdio(os.Stdin, os.Stderr, os.Stderr),
+	)
+	if err != nil {
+		return
+	}
+	token = strings.TrimSpace(token)
+	return
+}
+
+// tokenBased checks if the configuration authenticates using tokens, as opposed to user name and
+// password or client credentials. Only in that case it makes sense to ask the user for a new token.
+func (c *Config) tokenBased() bool {
+	return (c.AccessToken != "" || c.RefreshToken != "") &&
+		c.ClientSecret == "" && c.Password == ""
+}
+
+// Reauthenticate asks the user for a new token when the tokens stored in the configuration have
+// expired, so that the operation can continue instead of failing. It only does that when the tool
+// is running in a terminal and the user had previously logged in with a token. Returns true if
+// the configuration has been updated with a usable token.
+func (c *Config) Reauthenticate(reason string) (ok bool, err error) {
+	if !interactive() || !c.tokenBased() {
+		return
+	}
+	token, err := askToken(reason)
+	if err != nil || token == "" {
+		return
+	}
+
+	// Replace the expired tokens with the new one and check that it is usable before saving
+	// it, otherwise we would be replacing the configuration with one that doesn't work:
+	c.AccessToken = ""
+	c.RefreshToken = ""
+	err = c.SetToken(token)
+	if err != nil {
+		return
+	}
+	armed, reason, err := c.Armed()
+	if err != nil {
+		return
+	}
+	if !armed {
+		err = fmt.Errorf("New token isn't usable: %s", reason)
+		return
+	}
+	err = Save(c)
+	if err != nil {
+		err = fmt.Errorf("Can't save config file: %v", err)
+		return
+	}
+	ok = true
+	return
+}
+
+// reauthWrapper returns a transport wrapper that handles the tokens expiring while the connection
+// is in use, for example during a long running watch. When the SSO server rejects the refresh token
+// it asks the user for a new one and retries the request with it, so that the connection picks the
+// new tokens and the operation continues.
+func (c *Config) reauthWrapper(tokenURL string) sdk.TransportWrapper {
+	return func(transport http.RoundTripper) http.RoundTripper {
+		return &reauthTransport{
+			cfg:       c,
+			tokenURL:  tokenURL,
+			transport: transport,
+		}
+	}
+}
+
+type reauthTransport struct {
+	cfg       *Config
+	tokenURL  string
+	transport http.RoundTripper
+
+	// lock makes sure that the user is asked only once when multiple requests fail at the same
+	// time, and token is the new token provided by the user, if any.
+	lock  sync.Mutex
+	token string
+}
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *reauthTransport) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	if request.Method != http.MethodPost || request.Body == nil || !t.isTokenURL(request.URL) {
+		return t.transport.RoundTrip(request)
+	}
+
+	// Read the body, as we may need to send it again with a different refresh token:
+	body, err := ioutil.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return
+	}
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	response, err = t.transport.RoundTrip(request)
+	if err != nil || form.Get("grant_type") != "refresh_token" {
+		return
+	}
+	if response.StatusCode != http.StatusBadRequest &&
+		response.StatusCode != http.StatusUnauthorized {
+		return
+	}
+
+	// The SSO server rejected the refresh token, so ask the user for a new one:
+	token, err := t.newToken(form.Get("refresh_token"))
+	if err != nil {
+		response.Body.Close()
+		response = nil
+		return
+	}
+	if token == "" {
+		return
+	}
+	response.Body.Close()
+	form.Set("refresh_token", token)
+	body = []byte(form.Encode())
+	retry := request.Clone(request.Context())
+	retry.Body = ioutil.NopCloser(bytes.NewReader(body))
+	retry.ContentLength = int64(len(body))
+	return t.transport.RoundTrip(retry)
+}
+
+// newToken returns the token that should replace the given rejected refresh token, asking the user
+// if needed. It returns an empty string if the user doesn't provide a new token.
+func (t *reauthTransport) newToken(rejected string) (token string, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.token != "" && t.token != rejected {
+		token = t.token
+		return
+	}
+	token, err = askToken("refresh token has been rejected by the server")
+	if err != nil || token == "" {
+		return
+	}
+	cfg := t.cfg.copy()
+	cfg.AccessToken = ""
+	cfg.RefreshToken = ""
+	err = cfg.SetToken(token)
+	if err != nil {
+		return
+	}
+	if cfg.RefreshToken == "" {
+		err = fmt.Errorf("Can't continue the session with an access token, use a refresh token")
+		return
+	}
+	err = Save(cfg)
+	if err != nil {
+		err = fmt.Errorf("Can't save config file: %v", err)
+		return
+	}
+	t.token = token
+	return
+}
+
+// isTokenURL checks if the given URL is the URL of the SSO server used to request tokens.
+func (t *reauthTransport) isTokenURL(value *url.URL) bool {
+	return sameURL(value, t.tokenURL)
+}
+
+// sameURL checks if the given URL has the same scheme, host and path than the expected one.
+func sameURL(value *url.URL, expected string) bool {
+	parsed, err := url.Parse(expected)
+	if err != nil {
+		return false
+	}
+	return value.Scheme == parsed.Scheme && value.Host == parsed.Host &&
+		value.Path == parsed.Path
+}
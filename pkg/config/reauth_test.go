@@ -0,0 +1,195 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Reauthenticate", func() {
+	var tmpDir string
+	var savedInteractive func() bool
+	var savedAskToken func(string) (string, error)
+
+	// answer configures the prompt so that it returns the given token, and returns a pointer to
+	// the number of times that the user has been asked.
+	answer := func(token string) *int {
+		count := 0
+		interactive = func() bool {
+			return true
+		}
+		askToken = func(reason string) (string, error) {
+			count++
+			return token, nil
+		}
+		return &count
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-config-*")
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("OCM_CONFIG", filepath.Join(tmpDir, "ocm.json"))
+		savedInteractive = interactive
+		savedAskToken = askToken
+	})
+
+	AfterEach(func() {
+		interactive = savedInteractive
+		askToken = savedAskToken
+		os.Unsetenv("OCM_CONFIG")
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Doesn't ask when not running in a terminal", func() {
+		count := answer(MakeTokenString("Refresh", 10*time.Hour))
+		interactive = func() bool {
+			return false
+		}
+		cfg := &Config{
+			AccessToken: MakeTokenString("Bearer", -5*time.Minute),
+			URL:         "http://my-server.example.com",
+			TokenURL:    "http://my-sso.example.com",
+		}
+		ok, err := cfg.Reauthenticate("access token is expired")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(*count).To(BeZero())
+	})
+
+	It("Doesn't ask when using client credentials", func() {
+		count := answer(MakeTokenString("Refresh", 10*time.Hour))
+		cfg := &Config{
+			ClientID:     "my-client",
+			ClientSecret: "my-secret",
+			URL:          "http://my-server.example.com",
+			TokenURL:     "http://my-sso.example.com",
+		}
+		ok, err := cfg.Reauthenticate("credentials aren't set")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(*count).To(BeZero())
+	})
+
+	It("Replaces expired tokens with the new one", func() {
+		token := MakeTokenString("Refresh", 10*time.Hour)
+		count := answer(token)
+		cfg := &Config{
+			AccessToken: MakeTokenString("Bearer", -5*time.Minute),
+			URL:         "http://my-server.example.com",
+			TokenURL:    "http://my-sso.example.com",
+		}
+		ok, err := cfg.Reauthenticate("access token is expired")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(*count).To(Equal(1))
+		Expect(cfg.AccessToken).To(BeEmpty())
+		Expect(cfg.RefreshToken).To(Equal(token))
+		saved, err := load(os.Getenv("OCM_CONFIG"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(saved.RefreshToken).To(Equal(token))
+	})
+
+	It("Rejects a new token that is also expired", func() {
+		answer(MakeTokenString("Bearer", -5*time.Minute))
+		cfg := &Config{
+			AccessToken: MakeTokenString("Bearer", -10*time.Minute),
+			URL:         "http://my-server.example.com",
+			TokenURL:    "http://my-sso.example.com",
+		}
+		ok, err := cfg.Reauthenticate("access token is expired")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("New token isn't usable"))
+		Expect(ok).To(BeFalse())
+	})
+
+	When("The refresh token is rejected in the middle of the session", func() {
+		var ssoServer *Server
+		var apiServer *Server
+		var cfg *Config
+
+		BeforeEach(func() {
+			ssoServer = MakeTCPServer()
+			apiServer = MakeTCPServer()
+			cfg = &Config{
+				AccessToken:  MakeTokenString("Bearer", -5*time.Minute),
+				RefreshToken: MakeTokenString("Refresh", 10*time.Hour),
+				URL:          apiServer.URL(),
+				TokenURL:     ssoServer.URL(),
+			}
+		})
+
+		AfterEach(func() {
+			ssoServer.Close()
+			apiServer.Close()
+		})
+
+		It("Retries with the new token and continues", func() {
+			newToken := MakeTokenString("Refresh", 20*time.Hour)
+			count := answer(newToken)
+			ssoServer.AppendHandlers(
+				CombineHandlers(
+					VerifyFormKV("refresh_token", cfg.RefreshToken),
+					RespondWithTokenError("invalid_grant", "Token is not active"),
+				),
+				CombineHandlers(
+					VerifyFormKV("refresh_token", newToken),
+					RespondWithAccessAndRefreshTokens(
+						MakeTokenString("Bearer", 15*time.Minute),
+						newToken,
+					),
+				),
+			)
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, "{}"),
+			)
+			connection, err := cfg.Connection()
+			Expect(err).ToNot(HaveOccurred())
+			defer connection.Close()
+			response, err := connection.Get().Path("/api/clusters_mgmt/v1").Send()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Status()).To(Equal(http.StatusOK))
+			Expect(*count).To(Equal(1))
+			saved, err := load(os.Getenv("OCM_CONFIG"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(saved.RefreshToken).To(Equal(newToken))
+		})
+
+		It("Fails if the user doesn't provide a new token", func() {
+			count := answer("")
+			ssoServer.AppendHandlers(
+				RespondWithTokenError("invalid_grant", "Token is not active"),
+			)
+			connection, err := cfg.Connection()
+			Expect(err).ToNot(HaveOccurred())
+			defer connection.Close()
+			_, err = connection.Get().Path("/api/clusters_mgmt/v1").Send()
+			Expect(err).To(HaveOccurred())
+			Expect(*count).To(Equal(1))
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+})
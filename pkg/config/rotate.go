@@ -0,0 +1,119 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/golang/glog"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+// rotateWrapper returns a transport wrapper that saves the tokens returned by the SSO server when
+// it rotates the refresh token, so that the next command uses the new refresh token instead of the
+// old one, which the server may no longer accept.
+func (c *Config) rotateWrapper(tokenURL string) sdk.TransportWrapper {
+	return func(transport http.RoundTripper) http.RoundTripper {
+		return &rotateTransport{
+			cfg:       c,
+			tokenURL:  tokenURL,
+			transport: transport,
+		}
+	}
+}
+
+type rotateTransport struct {
+	cfg       *Config
+	tokenURL  string
+	transport http.RoundTripper
+
+	// lock protects the refresh token that was last saved.
+	lock  sync.Mutex
+	saved string
+}
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *rotateTransport) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	if request.Method != http.MethodPost || request.Body == nil ||
+		!sameURL(request.URL, t.tokenURL) {
+		return t.transport.RoundTrip(request)
+	}
+
+	// Read the body to check the grant type:
+	body, err := ioutil.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return
+	}
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	response, err = t.transport.RoundTrip(request)
+	if err != nil || form.Get("grant_type") != "refresh_token" ||
+		response.StatusCode != http.StatusOK {
+		return
+	}
+
+	// Read the response, and put it back so that the connection can use it:
+	data, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(data))
+	var tokens struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	err = json.Unmarshal(data, &tokens)
+	if err != nil {
+		// Not our business, the connection will report the error:
+		err = nil
+		return
+	}
+	if tokens.RefreshToken == "" || tokens.RefreshToken == form.Get("refresh_token") {
+		return
+	}
+	t.save(tokens.AccessToken, tokens.RefreshToken)
+	return
+}
+
+// save saves the new tokens to the configuration file. Failing to save them shouldn't fail the
+// request that triggered the refresh, so errors are only logged.
+func (t *rotateTransport) save(accessToken, refreshToken string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.saved == refreshToken {
+		return
+	}
+	cfg := t.cfg.copy()
+	cfg.AccessToken = accessToken
+	cfg.RefreshToken = refreshToken
+	err := Save(cfg)
+	if err != nil {
+		glog.Warningf("Can't save rotated refresh token: %v", err)
+		return
+	}
+	t.saved = refreshToken
+}
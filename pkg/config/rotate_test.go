@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Refresh token rotation", func() {
+	var tmpDir string
+	var ssoServer *Server
+	var apiServer *Server
+	var cfg *Config
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-config-*")
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("OCM_CONFIG", filepath.Join(tmpDir, "ocm.json"))
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+		cfg = &Config{
+			AccessToken:  MakeTokenString("Bearer", -5*time.Minute),
+			RefreshToken: MakeTokenString("Refresh", 10*time.Hour),
+			URL:          apiServer.URL(),
+			TokenURL:     ssoServer.URL(),
+		}
+		err = Save(cfg)
+		Expect(err).ToNot(HaveOccurred())
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, "{}"),
+		)
+	})
+
+	AfterEach(func() {
+		ssoServer.Close()
+		apiServer.Close()
+		os.Unsetenv("OCM_CONFIG")
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Saves the new refresh token", func() {
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+		refreshToken := MakeTokenString("Refresh", 20*time.Hour)
+		ssoServer.AppendHandlers(
+			CombineHandlers(
+				VerifyFormKV("refresh_token", cfg.RefreshToken),
+				RespondWithAccessAndRefreshTokens(accessToken, refreshToken),
+			),
+		)
+		connection, err := cfg.Connection()
+		Expect(err).ToNot(HaveOccurred())
+		defer connection.Close()
+		response, err := connection.Get().Path("/api/clusters_mgmt/v1").Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Status()).To(Equal(http.StatusOK))
+		saved, err := load(os.Getenv("OCM_CONFIG"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(saved.AccessToken).To(Equal(accessToken))
+		Expect(saved.RefreshToken).To(Equal(refreshToken))
+	})
+
+	It("Doesn't save the configuration if the refresh token doesn't change", func() {
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+		ssoServer.AppendHandlers(
+			RespondWithAccessAndRefreshTokens(accessToken, cfg.RefreshToken),
+		)
+		connection, err := cfg.Connection()
+		Expect(err).ToNot(HaveOccurred())
+		defer connection.Close()
+		_, err = connection.Get().Path("/api/clusters_mgmt/v1").Send()
+		Expect(err).ToNot(HaveOccurred())
+		saved, err := load(os.Getenv("OCM_CONFIG"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(saved.AccessToken).To(Equal(cfg.AccessToken))
+		Expect(saved.RefreshToken).To(Equal(cfg.RefreshToken))
+	})
+})
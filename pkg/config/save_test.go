@@ -0,0 +1,203 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+// TestSaveHelper isn't a real test, it is the process started by the tests that check what happens
+// when multiple processes save the configuration file. It loads the configuration, optionally waits
+// till the file indicated by the OCM_TEST_WAIT environment variable exists, and then saves the
+// refresh token given in the OCM_TEST_TOKEN environment variable.
+func TestSaveHelper(t *testing.T) {
+	if os.Getenv("OCM_TEST_HELPER") == "" {
+		t.Skip("only used as a separate process by other tests")
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wait := os.Getenv("OCM_TEST_WAIT")
+	if wait != "" {
+		err = os.WriteFile(wait+".ready", nil, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for {
+			_, err = os.Stat(wait)
+			if err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	cfg.RefreshToken = os.Getenv("OCM_TEST_TOKEN")
+	err = Save(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// saveHelper returns the command that runs the helper process.
+func saveHelper(file, token, wait string) *exec.Cmd {
+	// #nosec G204
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSaveHelper$")
+	cmd.Env = append(
+		os.Environ(),
+		"OCM_TEST_HELPER=true",
+		"OCM_CONFIG="+file,
+		"OCM_TEST_TOKEN="+token,
+		"OCM_TEST_WAIT="+wait,
+	)
+	cmd.Stdout = GinkgoWriter
+	cmd.Stderr = GinkgoWriter
+	return cmd
+}
+
+var _ = Describe("Save", func() {
+	var tmpDir string
+	var file string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-config-*")
+		Expect(err).ToNot(HaveOccurred())
+		file = filepath.Join(tmpDir, "ocm.json")
+		os.Setenv("OCM_CONFIG", file)
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("OCM_CONFIG")
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Writes a file that only the owner can read", func() {
+		err := Save(&Config{URL: "http://my-server.example.com"})
+		Expect(err).ToNot(HaveOccurred())
+		info, err := os.Stat(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("Doesn't corrupt the file when saving concurrently", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				err := Save(&Config{
+					AccessToken: fmt.Sprintf("token-%d", i),
+				})
+				Expect(err).ToNot(HaveOccurred())
+			}(i)
+		}
+		wg.Wait()
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		var cfg Config
+		Expect(json.Unmarshal(data, &cfg)).To(Succeed())
+		Expect(cfg.AccessToken).To(HavePrefix("token-"))
+		matches, err := filepath.Glob(filepath.Join(tmpDir, "*.tmp"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matches).To(BeEmpty())
+	})
+
+	It("Returns the saved configuration when loading", func() {
+		err := Save(&Config{AccessToken: "my-token"})
+		Expect(err).ToNot(HaveOccurred())
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cfg.AccessToken).To(Equal("my-token"))
+	})
+	It("Preserves newer tokens saved by other processes", func() {
+		// Save the initial configuration that both processes will load:
+		initial := MakeTokenString("Refresh", 1*time.Hour)
+		err := Save(&Config{
+			URL:          "http://my-server.example.com",
+			RefreshToken: initial,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		// Start the first process and wait till it has loaded the configuration:
+		wait := filepath.Join(tmpDir, "wait")
+		older := MakeTokenString("Refresh", 2*time.Hour)
+		first := saveHelper(file, older, wait)
+		Expect(first.Start()).To(Succeed())
+		Eventually(func() error {
+			_, err := os.Stat(wait + ".ready")
+			return err
+		}, 10*time.Second).Should(Succeed())
+
+		// Run the second process, that saves a token that expires later:
+		newer := MakeTokenString("Refresh", 3*time.Hour)
+		Expect(saveHelper(file, newer, "").Run()).To(Succeed())
+
+		// Let the first process save its token and check that it didn't replace the newer one:
+		Expect(os.WriteFile(wait, nil, 0600)).To(Succeed())
+		Expect(first.Wait()).To(Succeed())
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		var cfg Config
+		Expect(json.Unmarshal(data, &cfg)).To(Succeed())
+		Expect(cfg.RefreshToken).To(Equal(newer))
+		Expect(cfg.URL).To(Equal("http://my-server.example.com"))
+	})
+
+	It("Doesn't corrupt the file when saving from multiple processes", func() {
+		var cmds []*exec.Cmd
+		for i := 0; i < 10; i++ {
+			cmd := saveHelper(file, MakeTokenString("Refresh", time.Duration(i+1)*time.Hour), "")
+			Expect(cmd.Start()).To(Succeed())
+			cmds = append(cmds, cmd)
+		}
+		for _, cmd := range cmds {
+			Expect(cmd.Wait()).To(Succeed())
+		}
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		var cfg Config
+		Expect(json.Unmarshal(data, &cfg)).To(Succeed())
+		Expect(cfg.RefreshToken).ToNot(BeEmpty())
+	})
+
+	It("Saves empty tokens after logging out", func() {
+		err := Save(&Config{RefreshToken: MakeTokenString("Refresh", 1*time.Hour)})
+		Expect(err).ToNot(HaveOccurred())
+		cfg, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		cfg.Disarm()
+		Expect(Save(cfg)).To(Succeed())
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Unmarshal(data, cfg)).To(Succeed())
+		Expect(cfg.RefreshToken).To(BeEmpty())
+	})
+})
@@ -0,0 +1,147 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the '--curl' command line option.
+
+package curl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// AddFlag adds the curl flag to the given set of command line flags.
+func AddFlag(flags *pflag.FlagSet) {
+	flags.BoolVar(
+		&enabled,
+		"curl",
+		false,
+		"Print to the standard error the equivalent curl command for each request sent "+
+			"to the API. Requests that only read data are still sent, but requests that "+
+			"modify data aren't. "+
+			"The access token is replaced by the 'OCM_TOKEN' environment variable.",
+	)
+}
+
+// Enabled returns a boolean flag that indicates if the curl mode is enabled.
+func Enabled() bool {
+	return enabled
+}
+
+// enabled is a boolean flag that indicates that the curl mode is enabled.
+var enabled bool
+
+// ErrNotSent is returned by the transport instead of sending requests that modify data.
+var ErrNotSent = errors.New("request wasn't sent because the curl mode is enabled")
+
+// TransportWrapper returns a transport wrapper that writes to the given writer the curl command
+// equivalent to each request. Requests sent to the token URL aren't written, as those are an
+// implementation detail of the authentication.
+func TransportWrapper(tokenURL string, out io.Writer) func(http.RoundTripper) http.RoundTripper {
+	return func(wrapped http.RoundTripper) http.RoundTripper {
+		return &roundTripper{
+			tokenURL: tokenURL,
+			out:      out,
+			wrapped:  wrapped,
+		}
+	}
+}
+
+type roundTripper struct {
+	tokenURL string
+	out      io.Writer
+	wrapped  http.RoundTripper
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*roundTripper)(nil)
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	if strings.HasPrefix(request.URL.String(), t.tokenURL) {
+		return t.wrapped.RoundTrip(request)
+	}
+	command, err := Command(request)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(t.out, "%s\n", command)
+	switch request.Method {
+	case http.MethodGet, http.MethodHead:
+		return t.wrapped.RoundTrip(request)
+	default:
+		err = ErrNotSent
+		return
+	}
+}
+
+// Command generates the curl command equivalent to the given request. The value of the
+// authorization header is replaced by a reference to the 'OCM_TOKEN' environment variable, so
+// that the command can be shared without exposing the token.
+func Command(request *http.Request) (result string, err error) {
+	lines := []string{}
+	if request.Method != http.MethodGet {
+		lines = append(lines, "--request "+request.Method)
+	}
+	names := make([]string, 0, len(request.Header))
+	for name := range request.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch http.CanonicalHeaderKey(name) {
+		case "Authorization":
+			lines = append(lines, `--header "Authorization: Bearer ${OCM_TOKEN}"`)
+		case "Accept-Encoding", "Content-Length", "User-Agent":
+			// These are set by curl itself.
+		default:
+			for _, value := range request.Header[name] {
+				lines = append(lines, "--header "+quote(name+": "+value))
+			}
+		}
+	}
+	if request.Body != nil && request.Body != http.NoBody {
+		var body []byte
+		body, err = io.ReadAll(request.Body)
+		if err != nil {
+			return
+		}
+		err = request.Body.Close()
+		if err != nil {
+			return
+		}
+		request.Body = io.NopCloser(bytes.NewReader(body))
+		if len(body) > 0 {
+			lines = append(lines, "--data "+quote(string(body)))
+		}
+	}
+	lines = append(lines, quote(request.URL.String()))
+	result = "curl \\\n  " + strings.Join(lines, " \\\n  ")
+	return
+}
+
+// quote surrounds the given text with single quotes, escaping the single quotes that it may
+// contain, so that it is safe to use in a shell command.
+func quote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", `'\''`) + "'"
+}
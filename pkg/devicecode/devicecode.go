@@ -0,0 +1,294 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package devicecode implements the OAuth device authorization grant described in RFC 8628, so
+// that users can log in with a browser, possibly in a different machine, instead of copying tokens
+// manually.
+package devicecode
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GrantType is the grant type used to request tokens with a device code.
+const GrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// defaultInterval is the polling interval used when the server doesn't specify one, as required
+// by the specification.
+const defaultInterval = 5 * time.Second
+
+// Flow contains the information and logic needed to obtain tokens using a device code. Don't create
+// instances of this type directly; use the NewFlow function instead.
+type Flow struct {
+	tokenURL  string
+	deviceURL string
+	clientID  string
+	scopes    []string
+	insecure  bool
+	out       io.Writer
+}
+
+// Tokens contains the tokens returned by the SSO server.
+type Tokens struct {
+	Access  string
+	Refresh string
+}
+
+// NewFlow creates a new device code flow. The token URL and the client identifier are mandatory.
+func NewFlow() *Flow {
+	return &Flow{}
+}
+
+// TokenURL sets the URL of the SSO server used to request tokens.
+func (f *Flow) TokenURL(value string) *Flow {
+	f.tokenURL = value
+	return f
+}
+
+// DeviceURL sets the URL of the device authorization endpoint. If not set it is calculated from the
+// token URL, replacing the trailing 'token' with 'auth/device', as that is what the Keycloak
+// servers use.
+func (f *Flow) DeviceURL(value string) *Flow {
+	f.deviceURL = value
+	return f
+}
+
+// ClientID sets the OpenID client identifier.
+func (f *Flow) ClientID(value string) *Flow {
+	f.clientID = value
+	return f
+}
+
+// Scopes sets the OpenID scopes.
+func (f *Flow) Scopes(values ...string) *Flow {
+	f.scopes = values
+	return f
+}
+
+// Insecure disables verification of TLS certificates and host names.
+func (f *Flow) Insecure(value bool) *Flow {
+	f.insecure = value
+	return f
+}
+
+// Output sets the writer where the instructions for the user will be written.
+func (f *Flow) Output(value io.Writer) *Flow {
+	f.out = value
+	return f
+}
+
+// authorization is the response of the device authorization endpoint.
+type authorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                *int   `json:"interval"`
+}
+
+// tokenResponse is the response of the token endpoint.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Run requests a device code, writes the instructions for the user to the output and then waits
+// until the user completes the authorization in the browser, returning the tokens.
+func (f *Flow) Run(ctx context.Context) (result *Tokens, err error) {
+	deviceURL := f.deviceURL
+	if deviceURL == "" {
+		deviceURL, err = DeviceURL(f.tokenURL)
+		if err != nil {
+			return
+		}
+	}
+	client := &http.Client{}
+	if f.insecure {
+		client.Transport = &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			// #nosec G402
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}
+	}
+
+	// Request the device code:
+	form := url.Values{}
+	form.Set("client_id", f.clientID)
+	if len(f.scopes) > 0 {
+		form.Set("scope", strings.Join(f.scopes, " "))
+	}
+	var auth authorization
+	status, err := post(ctx, client, deviceURL, form, &auth)
+	if err != nil {
+		err = fmt.Errorf("Can't request device code: %v", err)
+		return
+	}
+	if status != http.StatusOK || auth.DeviceCode == "" {
+		err = fmt.Errorf(
+			"Can't request device code, server responded with status %d",
+			status,
+		)
+		return
+	}
+
+	// Tell the user what to do:
+	if f.out != nil {
+		if auth.VerificationURIComplete != "" {
+			fmt.Fprintf(
+				f.out,
+				"To log in open '%s' in a browser and check that the code is '%s'.\n",
+				auth.VerificationURIComplete, auth.UserCode,
+			)
+		} else {
+			fmt.Fprintf(
+				f.out,
+				"To log in open '%s' in a browser and enter the code '%s'.\n",
+				auth.VerificationURI, auth.UserCode,
+			)
+		}
+	}
+
+	// Poll the token endpoint till the user completes the authorization or the code expires:
+	interval := defaultInterval
+	if auth.Interval != nil {
+		interval = time.Duration(*auth.Interval) * time.Second
+	}
+	var deadline <-chan time.Time
+	if auth.ExpiresIn > 0 {
+		timer := time.NewTimer(time.Duration(auth.ExpiresIn) * time.Second)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	form = url.Values{}
+	form.Set("grant_type", GrantType)
+	form.Set("device_code", auth.DeviceCode)
+	form.Set("client_id", f.clientID)
+	for {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-deadline:
+			err = fmt.Errorf("Device code expired before the authorization was completed")
+			return
+		case <-time.After(interval):
+		}
+		var response tokenResponse
+		_, err = post(ctx, client, f.tokenURL, form, &response)
+		if err != nil {
+			err = fmt.Errorf("Can't request token: %v", err)
+			return
+		}
+		switch response.Error {
+		case "":
+			if response.AccessToken == "" {
+				err = fmt.Errorf("Server didn't return an access token")
+				return
+			}
+			result = &Tokens{
+				Access:  response.AccessToken,
+				Refresh: response.RefreshToken,
+			}
+			return
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += defaultInterval
+			continue
+		case "access_denied":
+			err = fmt.Errorf("Authorization was denied")
+			return
+		case "expired_token":
+			err = fmt.Errorf("Device code expired before the authorization was completed")
+			return
+		default:
+			err = fmt.Errorf("Can't request token: %s", describe(&response))
+			return
+		}
+	}
+}
+
+// DeviceURL calculates the URL of the device authorization endpoint from the token URL.
+func DeviceURL(tokenURL string) (result string, err error) {
+	parsed, err := url.Parse(tokenURL)
+	if err != nil {
+		err = fmt.Errorf("Can't parse token URL '%s': %v", tokenURL, err)
+		return
+	}
+	if !strings.HasSuffix(parsed.Path, "/token") {
+		err = fmt.Errorf(
+			"Can't calculate device authorization URL from token URL '%s'",
+			tokenURL,
+		)
+		return
+	}
+	parsed.Path = strings.TrimSuffix(parsed.Path, "token") + "auth/device"
+	result = parsed.String()
+	return
+}
+
+func describe(response *tokenResponse) string {
+	if response.ErrorDescription != "" {
+		return fmt.Sprintf("%s: %s", response.Error, response.ErrorDescription)
+	}
+	return response.Error
+}
+
+// post sends the form to the given URL and parses the JSON response into the given value. Note
+// that it doesn't fail when the response status is an error, because the token endpoint uses
+// error responses to report that the authorization is pending.
+func post(ctx context.Context, client *http.Client, address string, form url.Values,
+	value interface{}) (status int, err error) {
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		address,
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return
+	}
+	defer response.Body.Close()
+	status = response.StatusCode
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, value)
+	if err != nil {
+		err = fmt.Errorf("Can't parse response with status %d: %v", status, err)
+	}
+	return
+}
@@ -0,0 +1,150 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devicecode
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Device code", func() {
+	var ssoServer *Server
+	var tokenURL string
+
+	BeforeEach(func() {
+		ssoServer = MakeTCPServer()
+		tokenURL = ssoServer.URL() + "/protocol/openid-connect/token"
+	})
+
+	AfterEach(func() {
+		ssoServer.Close()
+	})
+
+	// authorize returns a handler that responds to the device authorization request.
+	authorize := func() http.HandlerFunc {
+		return CombineHandlers(
+			VerifyRequest(http.MethodPost, "/protocol/openid-connect/auth/device"),
+			VerifyFormKV("client_id", "my-client"),
+			VerifyFormKV("scope", "openid offline_access"),
+			RespondWithJSON(http.StatusOK, `{
+				"device_code": "my-device-code",
+				"user_code": "ABCD-EFGH",
+				"verification_uri": "https://sso.example.com/device",
+				"expires_in": 600,
+				"interval": 0
+			}`),
+		)
+	}
+
+	// respondError returns a handler that responds to the token request with the given error.
+	respondError := func(code string) http.HandlerFunc {
+		return CombineHandlers(
+			VerifyRequest(http.MethodPost, "/protocol/openid-connect/token"),
+			VerifyFormKV("grant_type", GrantType),
+			VerifyFormKV("device_code", "my-device-code"),
+			RespondWithJSON(http.StatusBadRequest, `{"error": "`+code+`"}`),
+		)
+	}
+
+	run := func() (*Tokens, string, error) {
+		out := &bytes.Buffer{}
+		tokens, err := NewFlow().
+			TokenURL(tokenURL).
+			ClientID("my-client").
+			Scopes("openid", "offline_access").
+			Output(out).
+			Run(context.Background())
+		return tokens, out.String(), err
+	}
+
+	It("Returns the tokens after the user approves the access", func() {
+		ssoServer.AppendHandlers(
+			authorize(),
+			respondError("authorization_pending"),
+			respondError("authorization_pending"),
+			RespondWithJSON(http.StatusOK, `{
+				"access_token": "my-access",
+				"refresh_token": "my-refresh"
+			}`),
+		)
+		tokens, out, err := run()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tokens.Access).To(Equal("my-access"))
+		Expect(tokens.Refresh).To(Equal("my-refresh"))
+		Expect(out).To(Equal(
+			"To log in open 'https://sso.example.com/device' in a browser and enter " +
+				"the code 'ABCD-EFGH'.\n",
+		))
+		Expect(ssoServer.ReceivedRequests()).To(HaveLen(4))
+	})
+
+	It("Fails if the user denies the access", func() {
+		ssoServer.AppendHandlers(
+			authorize(),
+			respondError("access_denied"),
+		)
+		_, _, err := run()
+		Expect(err).To(MatchError("Authorization was denied"))
+	})
+
+	It("Fails if the code expires", func() {
+		ssoServer.AppendHandlers(
+			authorize(),
+			respondError("expired_token"),
+		)
+		_, _, err := run()
+		Expect(err).To(MatchError(
+			"Device code expired before the authorization was completed",
+		))
+	})
+
+	It("Fails if the server doesn't support device codes", func() {
+		ssoServer.AppendHandlers(
+			RespondWithJSON(http.StatusBadRequest, `{"error": "unauthorized_client"}`),
+		)
+		_, _, err := run()
+		Expect(err).To(MatchError(
+			"Can't request device code, server responded with status 400",
+		))
+	})
+
+	DescribeTable(
+		"Calculates the device authorization URL",
+		func(tokenURL, expected string) {
+			actual, err := DeviceURL(tokenURL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal(expected))
+		},
+		Entry(
+			"Keycloak",
+			"https://sso.redhat.com/auth/realms/redhat-external/protocol/openid-connect/token",
+			"https://sso.redhat.com/auth/realms/redhat-external/protocol/openid-connect/auth/device",
+		),
+	)
+
+	It("Rejects token URLs that don't end with 'token'", func() {
+		_, err := DeviceURL("https://sso.example.com/oauth")
+		Expect(err).To(HaveOccurred())
+	})
+})
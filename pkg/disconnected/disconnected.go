@@ -0,0 +1,196 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package disconnected contains the types and functions used to load the data of disconnected
+// clusters from export files and to convert it into the subscription objects of the accounts
+// management API.
+package disconnected
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// Values of the system units used to calculate the subscriptions of clusters:
+const (
+	SystemUnitsSockets = "Sockets"
+	SystemUnitsCores   = "Cores/vCPU"
+)
+
+// StatusDisconnected is the status of the subscriptions of clusters that don't report telemetry.
+const StatusDisconnected = "Disconnected"
+
+// PlanID is the identifier of the plan used to register disconnected clusters.
+const PlanID = "OCP"
+
+// uuidRE is the regular expression used to check the identifiers of the clusters.
+var uuidRE = regexp.MustCompile(
+	`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`,
+)
+
+// Export is the content of an export file.
+type Export struct {
+	Clusters []*Cluster `yaml:"clusters"`
+}
+
+// Cluster contains the data of one disconnected cluster. The identifier is the UUID of the
+// cluster, the same that is used as the external identifier of the subscription.
+type Cluster struct {
+	ClusterID        string `yaml:"cluster_id"`
+	DisplayName      string `yaml:"display_name"`
+	OpenshiftVersion string `yaml:"openshift_version"`
+	Nodes            *Nodes `yaml:"nodes"`
+	Sockets          int    `yaml:"sockets"`
+	CPUs             int    `yaml:"cpus"`
+	SystemUnits      string `yaml:"system_units"`
+}
+
+// Nodes contains the number of nodes of each role.
+type Nodes struct {
+	Master  int `yaml:"master"`
+	Infra   int `yaml:"infra"`
+	Compute int `yaml:"compute"`
+}
+
+// Load reads the clusters from the given export file. The file can be in YAML or JSON format.
+// Unknown fields are rejected, so that typos don't silently discard data.
+func Load(file string) (result []*Cluster, err error) {
+	// #nosec G304
+	data, err := os.ReadFile(file)
+	if err != nil {
+		err = fmt.Errorf("Can't read export file '%s': %v", file, err)
+		return
+	}
+	export := &Export{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err = decoder.Decode(export)
+	if err == io.EOF || err == nil && len(export.Clusters) == 0 {
+		err = fmt.Errorf("Export file '%s' doesn't contain any cluster", file)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("Can't parse export file '%s': %v", file, err)
+		return
+	}
+	seen := map[string]bool{}
+	for i, cluster := range export.Clusters {
+		err = cluster.validate()
+		if err != nil {
+			err = fmt.Errorf(
+				"Cluster %d of export file '%s' isn't valid: %v",
+				i+1, file, err,
+			)
+			return
+		}
+		key := strings.ToLower(cluster.ClusterID)
+		if seen[key] {
+			err = fmt.Errorf(
+				"Cluster '%s' appears more than once in export file '%s'",
+				cluster.ClusterID, file,
+			)
+			return
+		}
+		seen[key] = true
+	}
+	result = export.Clusters
+	return
+}
+
+func (c *Cluster) validate() error {
+	if c.ClusterID == "" {
+		return fmt.Errorf("cluster identifier is mandatory")
+	}
+	if !uuidRE.MatchString(c.ClusterID) {
+		return fmt.Errorf("cluster identifier '%s' isn't a valid UUID", c.ClusterID)
+	}
+	if c.Sockets < 0 || c.CPUs < 0 {
+		return fmt.Errorf("number of sockets and CPUs can't be negative")
+	}
+	if c.Nodes != nil && (c.Nodes.Master < 0 || c.Nodes.Infra < 0 || c.Nodes.Compute < 0) {
+		return fmt.Errorf("number of nodes can't be negative")
+	}
+	switch c.SystemUnits {
+	case "", SystemUnitsSockets, SystemUnitsCores:
+	default:
+		return fmt.Errorf(
+			"system units '%s' aren't valid, valid values are '%s' and '%s'",
+			c.SystemUnits, SystemUnitsSockets, SystemUnitsCores,
+		)
+	}
+	return nil
+}
+
+// Name returns the name used to refer to the cluster in messages: the display name if it has one
+// or else the identifier.
+func (c *Cluster) Name() string {
+	if c.DisplayName != "" {
+		return c.DisplayName
+	}
+	return c.ClusterID
+}
+
+// Registration returns the request used to register the cluster when it doesn't have a
+// subscription yet.
+func (c *Cluster) Registration() *amv1.SubscriptionRegistrationBuilder {
+	return amv1.NewSubscriptionRegistration().
+		ClusterUUID(c.ClusterID).
+		DisplayName(c.Name()).
+		PlanID(PlanID).
+		Status(StatusDisconnected)
+}
+
+// Subscription returns the patch used to update the subscription of the cluster. Only the fields
+// that are present in the export file are included.
+func (c *Cluster) Subscription() *amv1.SubscriptionBuilder {
+	builder := amv1.NewSubscription()
+	if c.DisplayName != "" {
+		builder.DisplayName(c.DisplayName)
+	}
+	if c.Sockets > 0 {
+		builder.SocketTotal(c.Sockets)
+	}
+	if c.CPUs > 0 {
+		builder.CpuTotal(c.CPUs)
+	}
+	if c.SystemUnits != "" {
+		builder.SystemUnits(c.SystemUnits)
+	}
+	if c.OpenshiftVersion != "" || c.Nodes != nil {
+		metrics := amv1.NewSubscriptionMetrics()
+		if c.OpenshiftVersion != "" {
+			metrics.OpenshiftVersion(c.OpenshiftVersion)
+		}
+		if c.Nodes != nil {
+			metrics.Nodes(
+				amv1.NewClusterMetricsNodes().
+					Master(float64(c.Nodes.Master)).
+					Infra(float64(c.Nodes.Infra)).
+					Compute(float64(c.Nodes.Compute)).
+					Total(float64(c.Nodes.Master + c.Nodes.Infra + c.Nodes.Compute)),
+			)
+		}
+		builder.Metrics(metrics)
+	}
+	return builder
+}
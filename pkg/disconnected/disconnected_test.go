@@ -0,0 +1,192 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disconnected
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	amv1 "github.com/openshift-online/ocm-sdk-go/accountsmgmt/v1"
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Load", func() {
+	var tmp string
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = os.MkdirTemp("", "ocm-test-*.d")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := os.RemoveAll(tmp)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	write := func(content string) string {
+		file := filepath.Join(tmp, "export.yaml")
+		err := os.WriteFile(file, []byte(content), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		return file
+	}
+
+	It("Loads all the fields", func() {
+		file := write(`
+clusters:
+- cluster_id: 0e9b4d3c-7a3b-4c8e-9a51-0b7f6c1e2d3a
+  display_name: lab
+  openshift_version: 4.10.3
+  nodes:
+    master: 3
+    compute: 4
+  sockets: 8
+  cpus: 32
+  system_units: Sockets
+`)
+		clusters, err := Load(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(clusters).To(HaveLen(1))
+		cluster := clusters[0]
+		Expect(cluster.ClusterID).To(Equal("0e9b4d3c-7a3b-4c8e-9a51-0b7f6c1e2d3a"))
+		Expect(cluster.Name()).To(Equal("lab"))
+		Expect(cluster.OpenshiftVersion).To(Equal("4.10.3"))
+		Expect(cluster.Nodes).To(Equal(&Nodes{Master: 3, Compute: 4}))
+		Expect(cluster.Sockets).To(Equal(8))
+		Expect(cluster.CPUs).To(Equal(32))
+		Expect(cluster.SystemUnits).To(Equal(SystemUnitsSockets))
+	})
+
+	It("Loads JSON files", func() {
+		file := write(`{
+			"clusters": [
+				{"cluster_id": "0e9b4d3c-7a3b-4c8e-9a51-0b7f6c1e2d3a", "cpus": 4}
+			]
+		}`)
+		clusters, err := Load(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(clusters).To(HaveLen(1))
+		Expect(clusters[0].Name()).To(Equal("0e9b4d3c-7a3b-4c8e-9a51-0b7f6c1e2d3a"))
+		Expect(clusters[0].CPUs).To(Equal(4))
+	})
+
+	It("Rejects files without clusters", func() {
+		_, err := Load(write(""))
+		Expect(err).To(MatchError(ContainSubstring("doesn't contain any cluster")))
+		_, err = Load(write("clusters: []"))
+		Expect(err).To(MatchError(ContainSubstring("doesn't contain any cluster")))
+	})
+
+	It("Rejects unknown fields", func() {
+		_, err := Load(write(`
+clusters:
+- cluster_id: 0e9b4d3c-7a3b-4c8e-9a51-0b7f6c1e2d3a
+  socket: 8
+`))
+		Expect(err).To(MatchError(ContainSubstring("Can't parse export file")))
+	})
+
+	It("Rejects invalid identifiers", func() {
+		_, err := Load(write(`
+clusters:
+- cluster_id: lab
+`))
+		Expect(err).To(MatchError(ContainSubstring(
+			"Cluster 1 of export file",
+		)))
+		Expect(err).To(MatchError(ContainSubstring(
+			"cluster identifier 'lab' isn't a valid UUID",
+		)))
+	})
+
+	It("Rejects duplicated clusters", func() {
+		_, err := Load(write(`
+clusters:
+- cluster_id: 0e9b4d3c-7a3b-4c8e-9a51-0b7f6c1e2d3a
+- cluster_id: 0E9B4D3C-7A3B-4C8E-9A51-0B7F6C1E2D3A
+`))
+		Expect(err).To(MatchError(ContainSubstring("appears more than once")))
+	})
+
+	It("Rejects invalid system units", func() {
+		_, err := Load(write(`
+clusters:
+- cluster_id: 0e9b4d3c-7a3b-4c8e-9a51-0b7f6c1e2d3a
+  system_units: Nodes
+`))
+		Expect(err).To(MatchError(ContainSubstring("system units 'Nodes' aren't valid")))
+	})
+
+	It("Rejects negative counts", func() {
+		_, err := Load(write(`
+clusters:
+- cluster_id: 0e9b4d3c-7a3b-4c8e-9a51-0b7f6c1e2d3a
+  nodes:
+    compute: -1
+`))
+		Expect(err).To(MatchError(ContainSubstring("number of nodes can't be negative")))
+	})
+})
+
+var _ = Describe("Subscription", func() {
+	marshal := func(builder *amv1.SubscriptionBuilder) map[string]interface{} {
+		object, err := builder.Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := &bytes.Buffer{}
+		err = amv1.MarshalSubscription(object, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		var result map[string]interface{}
+		err = json.Unmarshal(buffer.Bytes(), &result)
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+
+	It("Includes all the fields", func() {
+		cluster := &Cluster{
+			ClusterID:        "0e9b4d3c-7a3b-4c8e-9a51-0b7f6c1e2d3a",
+			DisplayName:      "lab",
+			OpenshiftVersion: "4.10.3",
+			Nodes:            &Nodes{Master: 3, Compute: 4},
+			Sockets:          8,
+			CPUs:             32,
+			SystemUnits:      SystemUnitsSockets,
+		}
+		data := marshal(cluster.Subscription())
+		Expect(data).To(MatchJQ(`.socket_total`, 8.0))
+		Expect(data).To(MatchJQ(`.cpu_total`, 32.0))
+		Expect(data).To(MatchJQ(`.system_units`, "Sockets"))
+		Expect(data).To(MatchJQ(`.display_name`, "lab"))
+		Expect(data).To(MatchJQ(`.metrics[0].openshift_version`, "4.10.3"))
+		Expect(data).To(MatchJQ(`.metrics[0].nodes.total`, 7.0))
+	})
+
+	It("Omits the fields that aren't in the export file", func() {
+		cluster := &Cluster{
+			ClusterID: "0e9b4d3c-7a3b-4c8e-9a51-0b7f6c1e2d3a",
+			CPUs:      4,
+		}
+		Expect(marshal(cluster.Subscription())).To(Equal(map[string]interface{}{
+			"kind":      "Subscription",
+			"cpu_total": 4.0,
+		}))
+	})
+})
@@ -0,0 +1,297 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types and functions used to compare the configuration of clusters with
+// a baseline and report the fields that deviate from it.
+
+package drift
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// Names of the fields that can be reported as deviations:
+const (
+	FieldChannelGroup      = "channel_group"
+	FieldHTTPProxy         = "proxy.http_proxy"
+	FieldHTTPSProxy        = "proxy.https_proxy"
+	FieldNoProxy           = "proxy.no_proxy"
+	FieldIdentityProviders = "identity_providers"
+	FieldAutoscaling       = "autoscaling.enabled"
+	FieldMinReplicas       = "autoscaling.min_replicas"
+	FieldMaxReplicas       = "autoscaling.max_replicas"
+)
+
+// identityProviderTypes maps the identity provider types used by the API to the names used in
+// baselines.
+var identityProviderTypes = map[cmv1.IdentityProviderType]string{
+	cmv1.IdentityProviderTypeGithub:   "github",
+	cmv1.IdentityProviderTypeGitlab:   "gitlab",
+	cmv1.IdentityProviderTypeGoogle:   "google",
+	cmv1.IdentityProviderTypeHtpasswd: "htpasswd",
+	cmv1.IdentityProviderTypeLDAP:     "ldap",
+	cmv1.IdentityProviderTypeOpenID:   "openid",
+}
+
+// Baseline is the configuration that clusters are expected to have. Fields that aren't set in the
+// baseline file aren't checked.
+type Baseline struct {
+	ChannelGroup      string               `yaml:"channel_group"`
+	Proxy             *ProxyBaseline       `yaml:"proxy"`
+	IdentityProviders *IDPBaseline         `yaml:"identity_providers"`
+	Autoscaling       *AutoscalingBaseline `yaml:"autoscaling"`
+}
+
+// ProxyBaseline contains the expected proxy settings. An empty string means that the setting
+// should not be used.
+type ProxyBaseline struct {
+	HTTPProxy  *string `yaml:"http_proxy"`
+	HTTPSProxy *string `yaml:"https_proxy"`
+	NoProxy    *string `yaml:"no_proxy"`
+}
+
+// IDPBaseline contains the types of identity providers that clusters must have, and the types
+// that they are allowed to have. When the list of allowed types is empty any type is allowed.
+type IDPBaseline struct {
+	Required []string `yaml:"required"`
+	Allowed  []string `yaml:"allowed"`
+}
+
+// AutoscalingBaseline contains the expected autoscaling settings of the default compute pool.
+type AutoscalingBaseline struct {
+	Enabled     *bool `yaml:"enabled"`
+	MinReplicas *int  `yaml:"min_replicas"`
+	MaxReplicas *int  `yaml:"max_replicas"`
+}
+
+// Deviation describes a field of a cluster that doesn't have the value expected by the baseline.
+type Deviation struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// Load reads the baseline from the given YAML file. Unknown fields are rejected, so that typos
+// don't silently disable checks.
+func Load(file string) (result *Baseline, err error) {
+	// #nosec G304
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		err = fmt.Errorf("Can't read baseline file '%s': %v", file, err)
+		return
+	}
+	baseline := &Baseline{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err = decoder.Decode(baseline)
+	if err == io.EOF {
+		err = fmt.Errorf("Baseline file '%s' is empty", file)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("Can't parse baseline file '%s': %v", file, err)
+		return
+	}
+	err = baseline.validate()
+	if err != nil {
+		err = fmt.Errorf("Baseline file '%s' isn't valid: %v", file, err)
+		return
+	}
+	result = baseline
+	return
+}
+
+func (b *Baseline) validate() error {
+	if b.IdentityProviders != nil {
+		for _, name := range append(b.IdentityProviders.Required, b.IdentityProviders.Allowed...) {
+			if !validIdentityProviderType(name) {
+				return fmt.Errorf(
+					"unknown identity provider type '%s', valid types are %s",
+					name, strings.Join(identityProviderTypeNames(), ", "),
+				)
+			}
+		}
+	}
+	if b.Autoscaling != nil {
+		min := b.Autoscaling.MinReplicas
+		max := b.Autoscaling.MaxReplicas
+		if min != nil && max != nil && *min > *max {
+			return fmt.Errorf(
+				"minimum replicas %d is greater than maximum replicas %d",
+				*min, *max,
+			)
+		}
+	}
+	return nil
+}
+
+// NeedsIdentityProviders returns true if checking the baseline requires the identity providers
+// of the clusters, so that callers can avoid retrieving them when they aren't needed.
+func (b *Baseline) NeedsIdentityProviders() bool {
+	return b.IdentityProviders != nil
+}
+
+// Check compares the given cluster and identity providers with the baseline and returns the
+// fields that deviate from it. The identity providers are ignored if the baseline doesn't check
+// them.
+func (b *Baseline) Check(cluster *cmv1.Cluster, idps []*cmv1.IdentityProvider) []*Deviation {
+	result := []*Deviation{}
+	add := func(field, expected, actual string) {
+		if expected != actual {
+			result = append(result, &Deviation{
+				Field:    field,
+				Expected: expected,
+				Actual:   actual,
+			})
+		}
+	}
+	if b.ChannelGroup != "" {
+		add(FieldChannelGroup, b.ChannelGroup, cluster.Version().ChannelGroup())
+	}
+	if b.Proxy != nil {
+		proxy := cluster.Proxy()
+		if b.Proxy.HTTPProxy != nil {
+			add(FieldHTTPProxy, *b.Proxy.HTTPProxy, proxy.HTTPProxy())
+		}
+		if b.Proxy.HTTPSProxy != nil {
+			add(FieldHTTPSProxy, *b.Proxy.HTTPSProxy, proxy.HTTPSProxy())
+		}
+		if b.Proxy.NoProxy != nil {
+			add(FieldNoProxy, *b.Proxy.NoProxy, proxy.NoProxy())
+		}
+	}
+	if b.IdentityProviders != nil {
+		actual := identityProviderTypeSet(idps)
+		if !b.IdentityProviders.matches(actual) {
+			add(
+				FieldIdentityProviders,
+				b.IdentityProviders.String(),
+				strings.Join(actual, ","),
+			)
+		}
+	}
+	if b.Autoscaling != nil {
+		autoscaling, enabled := cluster.Nodes().GetAutoscaleCompute()
+		if b.Autoscaling.Enabled != nil {
+			add(
+				FieldAutoscaling,
+				strconv.FormatBool(*b.Autoscaling.Enabled),
+				strconv.FormatBool(enabled),
+			)
+		}
+		if enabled && b.Autoscaling.MinReplicas != nil {
+			add(
+				FieldMinReplicas,
+				strconv.Itoa(*b.Autoscaling.MinReplicas),
+				strconv.Itoa(autoscaling.MinReplicas()),
+			)
+		}
+		if enabled && b.Autoscaling.MaxReplicas != nil {
+			add(
+				FieldMaxReplicas,
+				strconv.Itoa(*b.Autoscaling.MaxReplicas),
+				strconv.Itoa(autoscaling.MaxReplicas()),
+			)
+		}
+	}
+	return result
+}
+
+// matches checks if the given sorted list of identity provider types satisfies the baseline.
+func (b *IDPBaseline) matches(actual []string) bool {
+	present := map[string]bool{}
+	for _, name := range actual {
+		present[name] = true
+	}
+	for _, name := range b.Required {
+		if !present[name] {
+			return false
+		}
+	}
+	if len(b.Allowed) == 0 {
+		return true
+	}
+	allowed := map[string]bool{}
+	for _, name := range append(b.Allowed, b.Required...) {
+		allowed[name] = true
+	}
+	for _, name := range actual {
+		if !allowed[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// String generates a short description of the baseline, used as the expected value of the
+// deviations.
+func (b *IDPBaseline) String() string {
+	parts := []string{}
+	if len(b.Required) > 0 {
+		parts = append(parts, "required="+strings.Join(b.Required, ","))
+	}
+	if len(b.Allowed) > 0 {
+		parts = append(parts, "allowed="+strings.Join(b.Allowed, ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+// identityProviderTypeSet returns the sorted list of distinct baseline names of the types of the
+// given identity providers.
+func identityProviderTypeSet(idps []*cmv1.IdentityProvider) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, idp := range idps {
+		name, ok := identityProviderTypes[idp.Type()]
+		if !ok {
+			name = strings.ToLower(string(idp.Type()))
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func validIdentityProviderType(name string) bool {
+	for _, known := range identityProviderTypes {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+func identityProviderTypeNames() []string {
+	result := []string{}
+	for _, name := range identityProviderTypes {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
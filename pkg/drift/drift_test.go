@@ -0,0 +1,259 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Load", func() {
+	var tmp string
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = os.MkdirTemp("", "ocm-test-*.d")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := os.RemoveAll(tmp)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	write := func(content string) string {
+		file := filepath.Join(tmp, "baseline.yaml")
+		err := os.WriteFile(file, []byte(content), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		return file
+	}
+
+	It("Loads all the fields", func() {
+		file := write(`
+channel_group: stable
+proxy:
+  http_proxy: ""
+  no_proxy: .example.com
+identity_providers:
+  required: [ldap]
+  allowed: [htpasswd]
+autoscaling:
+  enabled: true
+  min_replicas: 2
+  max_replicas: 6
+`)
+		baseline, err := Load(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(baseline.ChannelGroup).To(Equal("stable"))
+		Expect(baseline.Proxy.HTTPProxy).ToNot(BeNil())
+		Expect(*baseline.Proxy.HTTPProxy).To(BeEmpty())
+		Expect(baseline.Proxy.HTTPSProxy).To(BeNil())
+		Expect(*baseline.Proxy.NoProxy).To(Equal(".example.com"))
+		Expect(baseline.IdentityProviders.Required).To(ConsistOf("ldap"))
+		Expect(baseline.IdentityProviders.Allowed).To(ConsistOf("htpasswd"))
+		Expect(*baseline.Autoscaling.Enabled).To(BeTrue())
+		Expect(*baseline.Autoscaling.MinReplicas).To(Equal(2))
+		Expect(*baseline.Autoscaling.MaxReplicas).To(Equal(6))
+		Expect(baseline.NeedsIdentityProviders()).To(BeTrue())
+	})
+
+	It("Rejects unknown fields", func() {
+		file := write("chanel_group: stable\n")
+		_, err := Load(file)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Can't parse baseline file"))
+		Expect(err.Error()).To(ContainSubstring("chanel_group"))
+	})
+
+	It("Rejects unknown identity provider types", func() {
+		file := write("identity_providers:\n  required: [kerberos]\n")
+		_, err := Load(file)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unknown identity provider type 'kerberos'"))
+	})
+
+	It("Rejects minimum replicas greater than maximum", func() {
+		file := write("autoscaling:\n  min_replicas: 4\n  max_replicas: 2\n")
+		_, err := Load(file)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("minimum replicas 4 is greater"))
+	})
+
+	It("Rejects empty files", func() {
+		file := write("")
+		_, err := Load(file)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("is empty"))
+	})
+
+	It("Fails if the file doesn't exist", func() {
+		_, err := Load(filepath.Join(tmp, "missing.yaml"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Can't read baseline file"))
+	})
+})
+
+var _ = Describe("Check", func() {
+	makeCluster := func(builder *cmv1.ClusterBuilder) *cmv1.Cluster {
+		cluster, err := builder.Build()
+		Expect(err).ToNot(HaveOccurred())
+		return cluster
+	}
+
+	makeIDP := func(kind cmv1.IdentityProviderType) *cmv1.IdentityProvider {
+		idp, err := cmv1.NewIdentityProvider().Type(kind).Build()
+		Expect(err).ToNot(HaveOccurred())
+		return idp
+	}
+
+	stringPtr := func(value string) *string { return &value }
+	boolPtr := func(value bool) *bool { return &value }
+	intPtr := func(value int) *int { return &value }
+
+	It("Doesn't report anything for an empty baseline", func() {
+		cluster := makeCluster(cmv1.NewCluster().
+			Version(cmv1.NewVersion().ChannelGroup("fast")))
+		baseline := &Baseline{}
+		Expect(baseline.Check(cluster, nil)).To(BeEmpty())
+		Expect(baseline.NeedsIdentityProviders()).To(BeFalse())
+	})
+
+	It("Reports a different channel group", func() {
+		cluster := makeCluster(cmv1.NewCluster().
+			Version(cmv1.NewVersion().ChannelGroup("fast")))
+		baseline := &Baseline{
+			ChannelGroup: "stable",
+		}
+		Expect(baseline.Check(cluster, nil)).To(ConsistOf(&Deviation{
+			Field:    FieldChannelGroup,
+			Expected: "stable",
+			Actual:   "fast",
+		}))
+	})
+
+	It("Reports proxy settings", func() {
+		cluster := makeCluster(cmv1.NewCluster().
+			Proxy(cmv1.NewProxy().
+				HTTPProxy("http://proxy.example.com").
+				NoProxy(".example.com")))
+		baseline := &Baseline{
+			Proxy: &ProxyBaseline{
+				HTTPProxy:  stringPtr(""),
+				HTTPSProxy: stringPtr(""),
+				NoProxy:    stringPtr(".example.com"),
+			},
+		}
+		Expect(baseline.Check(cluster, nil)).To(ConsistOf(&Deviation{
+			Field:    FieldHTTPProxy,
+			Expected: "",
+			Actual:   "http://proxy.example.com",
+		}))
+	})
+
+	It("Reports missing required identity providers", func() {
+		cluster := makeCluster(cmv1.NewCluster())
+		baseline := &Baseline{
+			IdentityProviders: &IDPBaseline{
+				Required: []string{"ldap"},
+			},
+		}
+		idps := []*cmv1.IdentityProvider{
+			makeIDP(cmv1.IdentityProviderTypeGithub),
+		}
+		Expect(baseline.Check(cluster, idps)).To(ConsistOf(&Deviation{
+			Field:    FieldIdentityProviders,
+			Expected: "required=ldap",
+			Actual:   "github",
+		}))
+	})
+
+	It("Reports identity providers that aren't allowed", func() {
+		cluster := makeCluster(cmv1.NewCluster())
+		baseline := &Baseline{
+			IdentityProviders: &IDPBaseline{
+				Required: []string{"ldap"},
+				Allowed:  []string{"openid"},
+			},
+		}
+		idps := []*cmv1.IdentityProvider{
+			makeIDP(cmv1.IdentityProviderTypeLDAP),
+			makeIDP(cmv1.IdentityProviderTypeHtpasswd),
+			makeIDP(cmv1.IdentityProviderTypeLDAP),
+		}
+		Expect(baseline.Check(cluster, idps)).To(ConsistOf(&Deviation{
+			Field:    FieldIdentityProviders,
+			Expected: "required=ldap allowed=openid",
+			Actual:   "htpasswd,ldap",
+		}))
+	})
+
+	It("Accepts identity providers that match", func() {
+		cluster := makeCluster(cmv1.NewCluster())
+		baseline := &Baseline{
+			IdentityProviders: &IDPBaseline{
+				Required: []string{"ldap"},
+				Allowed:  []string{"openid"},
+			},
+		}
+		idps := []*cmv1.IdentityProvider{
+			makeIDP(cmv1.IdentityProviderTypeLDAP),
+			makeIDP(cmv1.IdentityProviderTypeOpenID),
+		}
+		Expect(baseline.Check(cluster, idps)).To(BeEmpty())
+	})
+
+	It("Reports disabled autoscaling", func() {
+		cluster := makeCluster(cmv1.NewCluster().
+			Nodes(cmv1.NewClusterNodes().Compute(3)))
+		baseline := &Baseline{
+			Autoscaling: &AutoscalingBaseline{
+				Enabled:     boolPtr(true),
+				MinReplicas: intPtr(2),
+			},
+		}
+		Expect(baseline.Check(cluster, nil)).To(ConsistOf(&Deviation{
+			Field:    FieldAutoscaling,
+			Expected: "true",
+			Actual:   "false",
+		}))
+	})
+
+	It("Reports autoscaling limits", func() {
+		cluster := makeCluster(cmv1.NewCluster().
+			Nodes(cmv1.NewClusterNodes().
+				AutoscaleCompute(cmv1.NewMachinePoolAutoscaling().
+					MinReplicas(2).
+					MaxReplicas(10))))
+		baseline := &Baseline{
+			Autoscaling: &AutoscalingBaseline{
+				Enabled:     boolPtr(true),
+				MinReplicas: intPtr(2),
+				MaxReplicas: intPtr(6),
+			},
+		}
+		Expect(baseline.Check(cluster, nil)).To(ConsistOf(&Deviation{
+			Field:    FieldMaxReplicas,
+			Expected: "6",
+			Actual:   "10",
+		}))
+	})
+})
@@ -0,0 +1,223 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to implement the '--dry-run=client' command line option,
+// that checks a command line without connecting to the server: the flags and arguments are parsed
+// and validated as usual, and the first request that the command would send is printed instead
+// of sent. Commands that change the server but need to read from it first, for example to find a
+// cluster by name, can't be validated this way, as the request that changes the server depends
+// on the result of the read. This is intended for continuous integration jobs that generate command lines and need
+// to check them before running them against production.
+
+package dryrun
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift-online/ocm-cli/pkg/curl"
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+// Client is the value of the '--dry-run' flag that enables the client side validation.
+const Client = "client"
+
+// ErrNotSent is returned by the transport instead of sending the requests.
+var ErrNotSent = errors.New("request wasn't sent because the command is only being validated")
+
+// clientUsage explains the client mode in the help of the '--dry-run' flags.
+const clientUsage = "Use '--dry-run=" + Client + "' to only validate the command line, without " +
+	"connecting to the server: the first request that the command would send is printed " +
+	"instead of sent. Commands that change the server but need to read from it first, for " +
+	"example to find a cluster by name, can't be validated this way."
+
+// AddFlags adds the '--dry-run' flag, that only accepts the 'client' value, to all the runnable
+// commands of the given tree that don't have their own dry run mode. It is added to each command
+// instead of being a persistent flag of the root command because the commands that have their own
+// mode, added with BoolVar, would hide it and show the global one in their help.
+func AddFlags(root *cobra.Command) {
+	if root.Runnable() && root.Flags().Lookup("dry-run") == nil {
+		root.Flags().Var(&value{}, "dry-run", clientUsage)
+	}
+	for _, child := range root.Commands() {
+		AddFlags(child)
+	}
+}
+
+// BoolVar adds to the given set of command line flags the '--dry-run' flag of a command that has
+// its own dry run mode. The flag works as a boolean flag, and it also accepts the 'client' value,
+// which enables the client side validation and sets the boolean as well.
+func BoolVar(flags *pflag.FlagSet, target *bool, usage string) {
+	flags.Var(&value{target: target}, "dry-run", usage+" "+clientUsage)
+	flags.Lookup("dry-run").NoOptDefVal = "true"
+}
+
+// value is the implementation of the values of the '--dry-run' flags.
+type value struct {
+	target *bool
+}
+
+// Make sure that we implement the interface:
+var _ pflag.Value = (*value)(nil)
+
+func (v *value) String() string {
+	switch {
+	case v.target != nil:
+		return strconv.FormatBool(*v.target)
+	case client:
+		return Client
+	default:
+		return ""
+	}
+}
+
+func (v *value) Set(text string) error {
+	if text == Client {
+		client = true
+		if v.target != nil {
+			*v.target = true
+		}
+		return nil
+	}
+	if v.target == nil {
+		return fmt.Errorf("only '%s' is supported by this command", Client)
+	}
+	parsed, err := strconv.ParseBool(text)
+	if err != nil {
+		return fmt.Errorf("must be a boolean or '%s'", Client)
+	}
+	*v.target = parsed
+	return nil
+}
+
+func (v *value) Type() string {
+	if v.target != nil {
+		return "bool"
+	}
+	return "string"
+}
+
+// client is a boolean flag that indicates that the client side validation is enabled.
+var client bool
+
+// Enabled returns a boolean flag that indicates if the client side validation is enabled.
+func Enabled() bool {
+	return client
+}
+
+// first contains the curl command equivalent to the first request that the command tried to
+// send, if any, and firstRead indicates if that request only reads from the server.
+var (
+	first     string
+	firstRead bool
+	firstLock sync.Mutex
+)
+
+// Intercept records the given request, if it is the first one, and returns the error that should
+// be returned instead of sending it. It is used by the transport wrapper and also by the code
+// that sends requests without the connection, like the event streams.
+func Intercept(request *http.Request) error {
+	command, err := curl.Command(request)
+	if err != nil {
+		command = fmt.Sprintf("%s %s", request.Method, request.URL)
+	}
+	firstLock.Lock()
+	defer firstLock.Unlock()
+	if first == "" {
+		first = command
+		firstRead = request.Method == http.MethodGet || request.Method == http.MethodHead
+	}
+	return ErrNotSent
+}
+
+// TransportWrapper returns a transport wrapper that doesn't send any request, not even to the
+// token URL. It must be the outermost wrapper, so that nothing is sent by the other ones.
+func TransportWrapper() func(http.RoundTripper) http.RoundTripper {
+	return func(wrapped http.RoundTripper) http.RoundTripper {
+		return roundTripper{}
+	}
+}
+
+type roundTripper struct{}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = roundTripper{}
+
+// RoundTrip is the implementation of the round tripper interface.
+func (roundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	return nil, Intercept(request)
+}
+
+// Token returns a placeholder access token, so that connections can be created without valid
+// credentials and without requesting tokens from the server. It is never sent.
+func Token() (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"typ": "Bearer",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	return token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+}
+
+// Finish reports the result of the validation to the given writer and returns the error that the
+// command should return. Errors caused by not sending the requests are discarded, but other
+// errors are returned. When the executed command changes the server and the first request only
+// reads from it the command can't be validated, because the request that changes the server
+// depends on the result of that read, so an error is returned as well.
+func Finish(out io.Writer, cmd *cobra.Command, err error) error {
+	if !client {
+		return err
+	}
+	firstLock.Lock()
+	command, read := first, firstRead
+	firstLock.Unlock()
+	if command == "" {
+		return err
+	}
+	if err != nil && !notSent(err) {
+		return err
+	}
+	if read && cmd != nil && readonly.Marked(cmd) {
+		return fmt.Errorf(
+			"Command '%s' needs to read from the server before it can build the request "+
+				"that changes it, so it can't be validated without connecting to the "+
+				"server. The first request would have been:\n\n%s",
+			cmd.CommandPath(), command,
+		)
+	}
+	fmt.Fprintf(
+		out,
+		"Command is valid. No request was sent, the first one would have been:\n\n%s\n",
+		command,
+	)
+	return nil
+}
+
+// notSent checks if the given error was caused by not sending a request. Many commands add
+// context to the errors with the '%v' verb, so the message is checked as well.
+func notSent(err error) bool {
+	return errors.Is(err, ErrNotSent) || strings.Contains(err.Error(), ErrNotSent.Error())
+}
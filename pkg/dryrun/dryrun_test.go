@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/readonly"
+)
+
+var _ = Describe("Finish", func() {
+	var out *bytes.Buffer
+	var reader *cobra.Command
+	var writer *cobra.Command
+
+	// send intercepts a request with the given method, and returns the error that the command
+	// would return, adding context with the '%v' verb as most commands do.
+	send := func(method string) error {
+		request, err := http.NewRequest(method, "https://api.example.com/api/my_object", nil)
+		Expect(err).ToNot(HaveOccurred())
+		return fmt.Errorf("Can't send request: %v", Intercept(request))
+	}
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		reader = &cobra.Command{Use: "reader"}
+		writer = &cobra.Command{Use: "writer"}
+		readonly.Mark(writer)
+		client = true
+	})
+
+	AfterEach(func() {
+		client = false
+		first = ""
+		firstRead = false
+	})
+
+	It("Reports the first request", func() {
+		err := Finish(out, writer, send(http.MethodPost))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(HavePrefix("Command is valid."))
+		Expect(out.String()).To(ContainSubstring("--request POST"))
+	})
+
+	It("Accepts reads of commands that don't change the server", func() {
+		err := Finish(out, reader, send(http.MethodGet))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(HavePrefix("Command is valid."))
+	})
+
+	It("Rejects reads of commands that change the server", func() {
+		err := Finish(out, writer, send(http.MethodGet))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(
+			"Command 'writer' needs to read from the server",
+		))
+		Expect(err.Error()).To(ContainSubstring("https://api.example.com/api/my_object"))
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("Only checks the first request", func() {
+		Expect(send(http.MethodPost)).To(HaveOccurred())
+		err := Finish(out, writer, send(http.MethodGet))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("--request POST"))
+	})
+
+	It("Returns errors that aren't caused by not sending requests", func() {
+		Expect(send(http.MethodPost)).To(HaveOccurred())
+		err := Finish(out, writer, errors.New("my error"))
+		Expect(err).To(MatchError("my error"))
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("Returns errors when no request was intercepted", func() {
+		err := Finish(out, writer, errors.New("my error"))
+		Expect(err).To(MatchError("my error"))
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("Doesn't change errors when disabled", func() {
+		client = false
+		err := Finish(out, writer, send(http.MethodPost))
+		Expect(errors.Is(err, ErrNotSent)).To(BeFalse())
+		Expect(err.Error()).To(ContainSubstring(ErrNotSent.Error()))
+		Expect(out.String()).To(BeEmpty())
+	})
+})
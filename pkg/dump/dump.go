@@ -20,7 +20,6 @@ package dump
 import (
 	"encoding/json"
 	"io"
-	"runtime"
 
 	"github.com/nwidger/jsoncolor"
 	"github.com/openshift-online/ocm-cli/pkg/output"
@@ -39,7 +38,7 @@ func Pretty(stream io.Writer, body []byte) error {
 	if err != nil {
 		return dumpBytes(stream, body)
 	}
-	if output.IsTerminal(stream) && !isWindows() {
+	if output.SupportsColor(stream) {
 		return dumpColor(stream, data)
 	}
 	return dumpMonochrome(stream, data)
@@ -69,7 +68,7 @@ func Single(stream io.Writer, body []byte) error {
 	if err != nil {
 		return dumpBytes(stream, body)
 	}
-	if output.IsTerminal(stream) && !isWindows() {
+	if output.SupportsColor(stream) {
 		return dumpColorSingleLine(stream, data)
 	}
 	return dumpMonochromeSingleLine(stream, data)
@@ -98,8 +97,3 @@ func dumpBytes(stream io.Writer, data []byte) error {
 	_, err = stream.Write([]byte("\n"))
 	return err
 }
-
-// isWindows checks if the operating system is Windows.
-func isWindows() bool {
-	return runtime.GOOS == "windows"
-}
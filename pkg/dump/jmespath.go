@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dump
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+// ParseJMESPath checks that the given text is a valid JMESPath expression and compiles it.
+func ParseJMESPath(expression string) (*jmespath.JMESPath, error) {
+	return jmespath.Compile(expression)
+}
+
+// JMESPath applies the given compiled JMESPath expression to the JSON document contained in the
+// body and writes the result to the given stream. Like the JQ function, results that are strings
+// are written without quotes, and other results are written like the Pretty function does, or
+// like the Single function when the single flag is true.
+func JMESPath(stream io.Writer, body []byte, expression *jmespath.JMESPath, single bool) error {
+	var input interface{}
+	err := json.Unmarshal(body, &input)
+	if err != nil {
+		return fmt.Errorf("can't parse JSON document: %v", err)
+	}
+	result, err := expression.Search(input)
+	if err != nil {
+		return err
+	}
+	text, ok := result.(string)
+	if ok {
+		_, err = fmt.Fprintln(stream, text)
+		return err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if single {
+		return Single(stream, data)
+	}
+	return Pretty(stream, data)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dump
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/itchyny/gojq"
+)
+
+// ParseJQ checks that the given text is a valid jq filter and compiles it.
+func ParseJQ(filter string) (code *gojq.Code, err error) {
+	query, err := gojq.Parse(filter)
+	if err != nil {
+		return
+	}
+	code, err = gojq.Compile(query)
+	return
+}
+
+// JQ applies the given compiled jq filter to the JSON document contained in the body and writes
+// the results to the given stream, one after the other. Like the '--raw-output' option of jq,
+// results that are strings are written without quotes, so that they can be easily used in shell
+// pipelines. Other results are written like the Pretty function does, or like the Single function
+// when the single flag is true.
+func JQ(stream io.Writer, body []byte, code *gojq.Code, single bool) error {
+	input, err := parseJSON(body)
+	if err != nil {
+		return err
+	}
+	iterator := code.Run(input)
+	for {
+		result, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		switch typed := result.(type) {
+		case error:
+			return typed
+		case string:
+			_, err = fmt.Fprintln(stream, typed)
+		default:
+			var data []byte
+			data, err = json.Marshal(typed)
+			if err != nil {
+				return err
+			}
+			if single {
+				err = Single(stream, data)
+			} else {
+				err = Pretty(stream, data)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JQTest applies the given compiled jq filter to the JSON document contained in the body and
+// checks if the first result is true. Like in jq, all values are true except 'false' and 'null'.
+// A filter that doesn't produce any result is false.
+func JQTest(body []byte, code *gojq.Code) (bool, error) {
+	input, err := parseJSON(body)
+	if err != nil {
+		return false, err
+	}
+	result, ok := code.Run(input).Next()
+	if !ok {
+		return false, nil
+	}
+	switch typed := result.(type) {
+	case error:
+		return false, typed
+	case nil:
+		return false, nil
+	case bool:
+		return typed, nil
+	default:
+		return true, nil
+	}
+}
+
+func parseJSON(body []byte) (result interface{}, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	err = decoder.Decode(&result)
+	if err != nil {
+		err = fmt.Errorf("can't parse JSON document: %v", err)
+	}
+	return
+}
@@ -0,0 +1,303 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package examples contains the registry of examples of the commands. The examples are loaded
+// from the 'examples.yaml' file, which is embedded in the binary. They are added to the help of
+// the commands and displayed by the 'examples' command. The 'Check' function verifies that all the
+// examples use commands, flags and arguments that exist, so that they don't get out of date when
+// the commands change.
+package examples
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed examples.yaml
+var registryData []byte
+
+// Example is one example of a command.
+type Example struct {
+	// Description is a short human readable explanation of what the example does.
+	Description string `yaml:"description"`
+
+	// Run is the complete command line, including the name of the binary.
+	Run string `yaml:"run"`
+
+	// Output is an optional sample of what the command writes.
+	Output string `yaml:"output,omitempty"`
+}
+
+// Group contains the examples of one command.
+type Group struct {
+	// Command is the path of the command, without the name of the binary, for example
+	// 'list clusters'.
+	Command string `yaml:"command"`
+
+	// Examples is the list of examples of the command.
+	Examples []*Example `yaml:"examples"`
+}
+
+// Registry contains the examples of all the commands.
+type Registry struct {
+	groups []*Group
+	index  map[string]*Group
+}
+
+// Parse parses a registry from the given YAML data.
+func Parse(data []byte) (result *Registry, err error) {
+	var groups []*Group
+	err = yaml.Unmarshal(data, &groups)
+	if err != nil {
+		err = fmt.Errorf("Can't parse examples: %v", err)
+		return
+	}
+	index := map[string]*Group{}
+	for _, group := range groups {
+		if _, ok := index[group.Command]; ok {
+			err = fmt.Errorf("Examples of command '%s' are defined more than once", group.Command)
+			return
+		}
+		index[group.Command] = group
+	}
+	result = &Registry{
+		groups: groups,
+		index:  index,
+	}
+	return
+}
+
+var (
+	loadedRegistry *Registry
+	loadErr        error
+	loadOnce       sync.Once
+)
+
+// Load returns the registry loaded from the embedded 'examples.yaml' file.
+func Load() (*Registry, error) {
+	loadOnce.Do(func() {
+		loadedRegistry, loadErr = Parse(registryData)
+	})
+	return loadedRegistry, loadErr
+}
+
+// Commands returns the sorted paths of the commands that have examples.
+func (r *Registry) Commands() []string {
+	result := make([]string, 0, len(r.groups))
+	for _, group := range r.groups {
+		result = append(result, group.Command)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// For returns the examples of the given command.
+func (r *Registry) For(cmd *cobra.Command) []*Example {
+	group, ok := r.index[Path(cmd)]
+	if !ok {
+		return nil
+	}
+	return group.Examples
+}
+
+// Path returns the path of the command without the name of the binary.
+func Path(cmd *cobra.Command) string {
+	return strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+}
+
+// Format returns the text of the given examples, in the same format used in the help of the
+// commands. The sample output is included only if requested.
+func Format(examples []*Example, withOutput bool) string {
+	buffer := &strings.Builder{}
+	for i, example := range examples {
+		if i > 0 {
+			buffer.WriteString("\n")
+		}
+		fmt.Fprintf(buffer, "  # %s\n  %s\n", example.Description, example.Run)
+		if withOutput && example.Output != "" {
+			for _, line := range strings.Split(strings.TrimRight(example.Output, "\n"), "\n") {
+				fmt.Fprintf(buffer, "  > %s\n", line)
+			}
+		}
+	}
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// Apply adds the examples of the registry to the help of the commands of the given tree. It does
+// nothing when called again for the same tree.
+func (r *Registry) Apply(root *cobra.Command) {
+	if root.Annotations[appliedAnnotation] != "" {
+		return
+	}
+	if root.Annotations == nil {
+		root.Annotations = map[string]string{}
+	}
+	root.Annotations[appliedAnnotation] = "true"
+	walk(root, func(cmd *cobra.Command) {
+		// Skip the examples that use the same flags as one that is already part of the
+		// help of the command, as they would only repeat it with different values:
+		builtin := map[string]bool{}
+		for _, line := range lines(cmd.Example) {
+			builtin[flags(cmd, line)] = true
+		}
+		var examples []*Example
+		for _, example := range r.For(cmd) {
+			if !builtin[flags(cmd, example.Run)] {
+				examples = append(examples, example)
+			}
+		}
+		if len(examples) == 0 {
+			return
+		}
+		text := Format(examples, false)
+		if cmd.Example != "" {
+			text = cmd.Example + "\n\n" + text
+		}
+		cmd.Example = text
+	})
+}
+
+// lines returns the command lines of the given help examples, joining the lines that are
+// continued with a backslash and skipping the comments.
+func lines(text string) []string {
+	var result []string
+	current := ""
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if current == "" && (line == "" || strings.HasPrefix(line, "#")) {
+			continue
+		}
+		if strings.HasSuffix(line, "\\") {
+			current += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		result = append(result, current+line)
+		current = ""
+	}
+	if current != "" {
+		result = append(result, current)
+	}
+	return result
+}
+
+// flags returns the sorted names of the flags used in the given command line, using the long
+// names also for the flags given with the shorthand, separated by commas.
+func flags(cmd *cobra.Command, line string) string {
+	words, err := shellquote.Split(line)
+	if err != nil {
+		words = strings.Fields(line)
+	}
+	names := map[string]bool{}
+	for _, word := range words {
+		if len(word) < 2 || word[0] != '-' {
+			continue
+		}
+		name := strings.TrimLeft(word, "-")
+		if i := strings.Index(name, "="); i >= 0 {
+			name = name[:i]
+		}
+		if name == "" {
+			continue
+		}
+		if !strings.HasPrefix(word, "--") {
+			flag := cmd.Flags().ShorthandLookup(name[:1])
+			if flag != nil {
+				name = flag.Name
+			}
+		}
+		names[name] = true
+	}
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return strings.Join(result, ",")
+}
+
+// appliedAnnotation is the annotation that Apply adds to the root command to remember that the
+// examples have already been added.
+const appliedAnnotation = "examples_applied"
+
+// Check verifies that all the examples of the registry are valid for the given command tree. It
+// returns one error for each example that isn't.
+func (r *Registry) Check(root *cobra.Command) []error {
+	var errs []error
+	for _, group := range r.groups {
+		if len(group.Examples) == 0 {
+			errs = append(errs, fmt.Errorf("Command '%s' has no examples", group.Command))
+			continue
+		}
+		for _, example := range group.Examples {
+			err := check(root, group.Command, example)
+			if err != nil {
+				errs = append(errs, fmt.Errorf(
+					"Example '%s' of command '%s' isn't valid: %v",
+					example.Run, group.Command, err,
+				))
+			}
+		}
+	}
+	return errs
+}
+
+// check verifies that the command line of the given example runs the given command with flags
+// and arguments that it accepts.
+func check(root *cobra.Command, command string, example *Example) error {
+	if example.Description == "" {
+		return fmt.Errorf("description is empty")
+	}
+	words, err := shellquote.Split(example.Run)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 || words[0] != root.Name() {
+		return fmt.Errorf("it doesn't start with '%s'", root.Name())
+	}
+	for _, word := range words {
+		switch word {
+		case "|", "&&", "||", ";", ">", "<":
+			return fmt.Errorf("it contains '%s', but it should be a single command", word)
+		}
+	}
+	cmd, rest, err := root.Find(words[1:])
+	if err != nil {
+		return err
+	}
+	if Path(cmd) != command {
+		return fmt.Errorf("it runs command '%s'", Path(cmd))
+	}
+	err = cmd.ParseFlags(rest)
+	if err != nil {
+		return err
+	}
+	return cmd.ValidateArgs(cmd.Flags().Args())
+}
+
+// walk calls the given function for the command and all its descendants.
+func walk(cmd *cobra.Command, f func(*cobra.Command)) {
+	f(cmd)
+	for _, child := range cmd.Commands() {
+		walk(child, f)
+	}
+}
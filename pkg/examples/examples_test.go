@@ -0,0 +1,196 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package examples
+
+import (
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	"github.com/spf13/cobra"
+)
+
+var _ = Describe("Examples", func() {
+	// makeTree creates a small command tree similar to the one of the real tool.
+	makeTree := func() *cobra.Command {
+		root := &cobra.Command{
+			Use: "ocm",
+		}
+		list := &cobra.Command{
+			Use: "list",
+		}
+		clusters := &cobra.Command{
+			Use:     "clusters",
+			Aliases: []string{"cluster"},
+			Example: "  # List the clusters\n  ocm list clusters",
+			Args:    cobra.NoArgs,
+			Run:     func(cmd *cobra.Command, argv []string) {},
+		}
+		clusters.Flags().String("columns", "", "")
+		clusters.Flags().StringArrayP("parameter", "p", nil, "")
+		get := &cobra.Command{
+			Use:  "get PATH",
+			Args: cobra.ExactArgs(1),
+			Run:  func(cmd *cobra.Command, argv []string) {},
+		}
+		get.Flags().String("jq", "", "")
+		list.AddCommand(clusters)
+		root.AddCommand(list, get)
+		return root
+	}
+
+	It("Parses the embedded registry", func() {
+		registry, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(registry.Commands()).To(ContainElement("list clusters"))
+	})
+
+	It("Rejects commands defined more than once", func() {
+		_, err := Parse([]byte(`[
+			{ "command": "get", "examples": [] },
+			{ "command": "get", "examples": [] }
+		]`))
+		Expect(err).To(MatchError("Examples of command 'get' are defined more than once"))
+	})
+
+	It("Accepts valid examples", func() {
+		registry, err := Parse([]byte(`
+- command: list clusters
+  examples:
+  - description: List clusters in a region
+    run: ocm list clusters -p search="region.id = 'us-east-1'" --columns id,name
+- command: get
+  examples:
+  - description: Get the identifiers of the clusters
+    run: ocm get /api/clusters_mgmt/v1/clusters --jq .items[].id
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(registry.Check(makeTree())).To(BeEmpty())
+	})
+
+	DescribeTable(
+		"Rejects invalid examples",
+		func(command, run, expected string) {
+			registry, err := Parse([]byte(`
+- command: ` + command + `
+  examples:
+  - description: Something
+    run: ` + run + `
+`))
+			Expect(err).ToNot(HaveOccurred())
+			errs := registry.Check(makeTree())
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Error()).To(ContainSubstring(expected))
+		},
+		Entry(
+			"Unknown flag",
+			"list clusters", "ocm list clusters --junk",
+			"unknown flag: --junk",
+		),
+		Entry(
+			"Alias instead of the command",
+			"list cluster", "ocm list cluster",
+			"it runs command 'list clusters'",
+		),
+		Entry(
+			"Unexpected arguments",
+			"list clusters", "ocm list clusters my-cluster",
+			"unknown command \"my-cluster\"",
+		),
+		Entry(
+			"Missing arguments",
+			"get", "ocm get",
+			"accepts 1 arg(s), received 0",
+		),
+		Entry(
+			"Pipe",
+			"get", "ocm get /api/clusters_mgmt/v1/clusters | jq .",
+			"it contains '|', but it should be a single command",
+		),
+		Entry(
+			"Other binary",
+			"get", "oc get pods",
+			"it doesn't start with 'ocm'",
+		),
+	)
+
+	It("Adds the examples to the help once", func() {
+		registry, err := Parse([]byte(`
+- command: list clusters
+  examples:
+  - description: List the clusters
+    run: ocm list clusters
+  - description: List only the names
+    run: ocm list clusters --columns name
+    output: |
+      NAME
+      my-cluster
+`))
+		Expect(err).ToNot(HaveOccurred())
+		root := makeTree()
+		registry.Apply(root)
+		registry.Apply(root)
+		clusters, _, err := root.Find([]string{"list", "clusters"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(clusters.Example).To(Equal(
+			"  # List the clusters\n" +
+				"  ocm list clusters\n" +
+				"\n" +
+				"  # List only the names\n" +
+				"  ocm list clusters --columns name",
+		))
+	})
+
+	It("Doesn't add examples that use the same flags as the ones of the help", func() {
+		registry, err := Parse([]byte(`
+- command: list clusters
+  examples:
+  - description: List the clusters of a region
+    run: ocm list clusters -p search="region.id = 'us-east-1'" --columns id
+  - description: List only the names
+    run: ocm list clusters --columns name
+`))
+		Expect(err).ToNot(HaveOccurred())
+		root := makeTree()
+		clusters, _, err := root.Find([]string{"list", "clusters"})
+		Expect(err).ToNot(HaveOccurred())
+		clusters.Example = "  # List the clusters of a region\n" +
+			"  ocm list clusters --columns id,name \\\n" +
+			"  --parameter search=\"region.id = 'eu-west-1'\""
+		help := clusters.Example
+		registry.Apply(root)
+		Expect(clusters.Example).To(Equal(
+			help + "\n" +
+				"\n" +
+				"  # List only the names\n" +
+				"  ocm list clusters --columns name",
+		))
+	})
+
+	It("Formats the sample output", func() {
+		text := Format([]*Example{{
+			Description: "List only the names",
+			Run:         "ocm list clusters --columns name",
+			Output:      "NAME\nmy-cluster\n",
+		}}, true)
+		Expect(text).To(Equal(
+			"  # List only the names\n" +
+				"  ocm list clusters --columns name\n" +
+				"  > NAME\n" +
+				"  > my-cluster",
+		))
+	})
+})
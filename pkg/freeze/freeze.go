@@ -0,0 +1,269 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package freeze contains the types and functions used to store the records of the upgrade
+// freezes created by the 'ocm cluster upgrade-policies pause' command. The API doesn't support
+// pausing upgrade policies, so they are deleted and saved in the record, and created again when
+// the freeze ends. Records are kept after the freeze ends, so that they can be audited later.
+package freeze
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+)
+
+// States of the clusters of a freeze:
+const (
+	// StatePending means that the upgrade policies of the cluster were saved but haven't been
+	// deleted yet. Clusters stay in this state if the command is interrupted.
+	StatePending = "pending"
+
+	// StatePaused means that the upgrade policies of the cluster were deleted and need to be
+	// created again when the freeze ends.
+	StatePaused = "paused"
+
+	// StateFailed means that none of the upgrade policies of the cluster could be deleted.
+	StateFailed = "failed"
+
+	// StateResumed means that the upgrade policies of the cluster were created again.
+	StateResumed = "resumed"
+)
+
+// Freeze is the record of the upgrade policies paused by one execution of the 'pause' command.
+type Freeze struct {
+	ID        string     `json:"id"`
+	Reason    string     `json:"reason,omitempty"`
+	Search    string     `json:"search"`
+	Shard     string     `json:"shard,omitempty"`
+	PausedAt  time.Time  `json:"paused_at"`
+	PausedBy  string     `json:"paused_by,omitempty"`
+	ResumedAt *time.Time `json:"resumed_at,omitempty"`
+	ResumedBy string     `json:"resumed_by,omitempty"`
+	Clusters  []*Cluster `json:"clusters"`
+}
+
+// Cluster contains the upgrade policies of one cluster of a freeze, in the JSON format used by
+// the API, so that they can be created again with the same schedules.
+type Cluster struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name,omitempty"`
+	Shard    string            `json:"shard,omitempty"`
+	State    string            `json:"state"`
+	Policies []json.RawMessage `json:"policies"`
+}
+
+// Active returns true if the freeze hasn't been resumed yet.
+func (f *Freeze) Active() bool {
+	return f.ResumedAt == nil
+}
+
+// Count returns the number of clusters of the freeze that are in the given state.
+func (f *Freeze) Count(state string) int {
+	count := 0
+	for _, cluster := range f.Clusters {
+		if cluster.State == state {
+			count++
+		}
+	}
+	return count
+}
+
+// NewID generates the identifier of a freeze created at the given time.
+func NewID(now time.Time) string {
+	return now.UTC().Format("20060102-150405")
+}
+
+// Find returns the freeze with the given identifier, or nil if there is no such freeze.
+func Find(freezes []*Freeze, id string) *Freeze {
+	for _, item := range freezes {
+		if item.ID == id {
+			return item
+		}
+	}
+	return nil
+}
+
+// MarshalPolicies converts the given upgrade policies to the JSON format used by the API, so that
+// they can be saved in a freeze record.
+func MarshalPolicies(policies []*cmv1.UpgradePolicy) (result []json.RawMessage, err error) {
+	result = make([]json.RawMessage, len(policies))
+	for i, policy := range policies {
+		buffer := &bytes.Buffer{}
+		err = cmv1.MarshalUpgradePolicy(policy, buffer)
+		if err != nil {
+			return
+		}
+		result[i] = json.RawMessage(bytes.TrimSpace(buffer.Bytes()))
+	}
+	return
+}
+
+// UnmarshalPolicy converts an upgrade policy saved in a freeze record into an object that can be
+// used to create it again. The identifier and the link are removed, as the server assigns new
+// ones.
+func UnmarshalPolicy(data json.RawMessage) (result *cmv1.UpgradePolicy, err error) {
+	var fields map[string]interface{}
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return
+	}
+	delete(fields, "id")
+	delete(fields, "href")
+	cleaned, err := json.Marshal(fields)
+	if err != nil {
+		return
+	}
+	return cmv1.UnmarshalUpgradePolicy(cleaned)
+}
+
+// SamePolicy checks if two upgrade policies have the same schedule, so that a policy that has
+// already been created again isn't duplicated when a resume is retried.
+func SamePolicy(a, b *cmv1.UpgradePolicy) bool {
+	return a.ScheduleType() == b.ScheduleType() &&
+		a.Schedule() == b.Schedule() &&
+		a.UpgradeType() == b.UpgradeType() &&
+		a.Version() == b.Version() &&
+		a.NextRun().Equal(b.NextRun())
+}
+
+// DescribePolicy returns a short description of the schedule of an upgrade policy, to use in
+// the output of the commands.
+func DescribePolicy(policy *cmv1.UpgradePolicy) string {
+	if policy.ScheduleType() == "automatic" {
+		return fmt.Sprintf("automatic upgrades with schedule '%s'", policy.Schedule())
+	}
+	return fmt.Sprintf(
+		"%s upgrade to version '%s' at %s",
+		policy.ScheduleType(), policy.Version(),
+		policy.NextRun().UTC().Format("2006-01-02 15:04 MST"),
+	)
+}
+
+// Location returns the location of the file where the freeze records are stored. The
+// 'OCM_FREEZES' environment variable can be used to change it.
+func Location() (path string, err error) {
+	if path = os.Getenv("OCM_FREEZES"); path != "" {
+		return
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return
+	}
+	path = filepath.Join(configDir, "ocm", "freezes.json")
+	return
+}
+
+// Load loads the freeze records from the file. If the file doesn't exist it returns an empty
+// list.
+func Load() (result []*Freeze, err error) {
+	file, err := Location()
+	if err != nil {
+		return
+	}
+	return load(file)
+}
+
+func load(file string) (result []*Freeze, err error) {
+	// #nosec G304
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		err = nil
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("can't read freezes file '%s': %v", file, err)
+		return
+	}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		err = fmt.Errorf("can't parse freezes file '%s': %v", file, err)
+		return
+	}
+	return
+}
+
+// Update loads the freeze records, calls the given function to modify them and saves the result,
+// sorted by identifier. The file is locked during the whole process. Nothing is saved if the
+// function returns an error.
+func Update(modify func(freezes []*Freeze) ([]*Freeze, error)) error {
+	file, err := Location()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(file)
+	err = os.MkdirAll(dir, os.FileMode(0755))
+	if err != nil {
+		return fmt.Errorf("can't create directory %s: %v", dir, err)
+	}
+	unlock, err := config.LockFile(file)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	freezes, err := load(file)
+	if err != nil {
+		return err
+	}
+	freezes, err = modify(freezes)
+	if err != nil {
+		return err
+	}
+	sort.Slice(freezes, func(i, j int) bool {
+		return freezes[i].ID < freezes[j].ID
+	})
+	data, err := json.MarshalIndent(freezes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("can't marshal freezes: %v", err)
+	}
+	return config.WriteFile(file, data)
+}
+
+// Create adds a new freeze record. If there is already a record with the same identifier, for
+// example because other execution of the 'pause' command started in the same second, a suffix is
+// added to the identifier of the new record, so that the existing one isn't replaced.
+func Create(freeze *Freeze) error {
+	return Update(func(freezes []*Freeze) ([]*Freeze, error) {
+		base := freeze.ID
+		for i := 2; Find(freezes, freeze.ID) != nil; i++ {
+			freeze.ID = fmt.Sprintf("%s-%d", base, i)
+		}
+		return append(freezes, freeze), nil
+	})
+}
+
+// Save replaces the record with the same identifier as the given freeze, or adds it if it doesn't
+// exist yet.
+func Save(freeze *Freeze) error {
+	return Update(func(freezes []*Freeze) ([]*Freeze, error) {
+		for i, item := range freezes {
+			if item.ID == freeze.ID {
+				freezes[i] = freeze
+				return freezes, nil
+			}
+		}
+		return append(freezes, freeze), nil
+	})
+}
@@ -0,0 +1,130 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package freeze
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Save", func() {
+	var tmpDir string
+	var file string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-freezes-*")
+		Expect(err).ToNot(HaveOccurred())
+		file = filepath.Join(tmpDir, "freezes.json")
+		os.Setenv("OCM_FREEZES", file)
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("OCM_FREEZES")
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Returns an empty list if the file doesn't exist", func() {
+		freezes, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(freezes).To(BeEmpty())
+	})
+
+	It("Adds new records sorted by identifier", func() {
+		Expect(Save(&Freeze{ID: "20221002-100000", Search: "b"})).To(Succeed())
+		Expect(Save(&Freeze{ID: "20221001-100000", Search: "a"})).To(Succeed())
+		freezes, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(freezes).To(HaveLen(2))
+		Expect(freezes[0].ID).To(Equal("20221001-100000"))
+		Expect(freezes[1].ID).To(Equal("20221002-100000"))
+		info, err := os.Stat(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("Replaces the record with the same identifier", func() {
+		Expect(Save(&Freeze{ID: "20221001-100000", Search: "a"})).To(Succeed())
+		now := time.Now()
+		Expect(Save(&Freeze{ID: "20221001-100000", Search: "a", ResumedAt: &now})).To(Succeed())
+		freezes, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(freezes).To(HaveLen(1))
+		Expect(freezes[0].Active()).To(BeFalse())
+	})
+
+	It("Doesn't replace existing records when creating one with the same identifier", func() {
+		first := &Freeze{ID: "20221001-100000", Search: "a"}
+		second := &Freeze{ID: "20221001-100000", Search: "b"}
+		third := &Freeze{ID: "20221001-100000", Search: "c"}
+		Expect(Create(first)).To(Succeed())
+		Expect(Create(second)).To(Succeed())
+		Expect(Create(third)).To(Succeed())
+		Expect(first.ID).To(Equal("20221001-100000"))
+		Expect(second.ID).To(Equal("20221001-100000-2"))
+		Expect(third.ID).To(Equal("20221001-100000-3"))
+		freezes, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(freezes).To(HaveLen(3))
+		Expect(Find(freezes, "20221001-100000").Search).To(Equal("a"))
+		Expect(Find(freezes, "20221001-100000-2").Search).To(Equal("b"))
+		Expect(Find(freezes, "20221001-100000-3").Search).To(Equal("c"))
+	})
+})
+
+var _ = Describe("Policies", func() {
+	It("Removes the identifier when restoring a saved policy", func() {
+		policy, err := cmv1.NewUpgradePolicy().
+			ID("456").
+			HREF("/api/clusters_mgmt/v1/clusters/123/upgrade_policies/456").
+			ScheduleType("automatic").
+			Schedule("0 2 * * 1").
+			UpgradeType("OSD").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		saved, err := MarshalPolicies([]*cmv1.UpgradePolicy{policy})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(saved).To(HaveLen(1))
+		var fields map[string]interface{}
+		Expect(json.Unmarshal(saved[0], &fields)).To(Succeed())
+		Expect(fields).To(HaveKeyWithValue("id", "456"))
+
+		restored, err := UnmarshalPolicy(saved[0])
+		Expect(err).ToNot(HaveOccurred())
+		_, ok := restored.GetID()
+		Expect(ok).To(BeFalse())
+		_, ok = restored.GetHREF()
+		Expect(ok).To(BeFalse())
+		Expect(restored.Schedule()).To(Equal("0 2 * * 1"))
+		Expect(SamePolicy(restored, policy)).To(BeTrue())
+	})
+
+	It("Detects policies with different schedules", func() {
+		a, err := cmv1.NewUpgradePolicy().ScheduleType("automatic").Schedule("0 2 * * 1").Build()
+		Expect(err).ToNot(HaveOccurred())
+		b, err := cmv1.NewUpgradePolicy().ScheduleType("automatic").Schedule("0 3 * * 1").Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(SamePolicy(a, b)).To(BeFalse())
+	})
+})
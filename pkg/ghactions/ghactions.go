@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to write the output of commands in the format understood
+// by GitHub Actions: workflow commands like '::notice::' and '::error::' that are displayed as
+// annotations, '::add-mask::' commands that hide secrets in the logs, and step outputs that other
+// steps of the workflow can use.
+
+package ghactions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/spf13/pflag"
+)
+
+// Format is the value of the '--output' flag that enables the GitHub Actions output.
+const Format = "github-actions"
+
+// OutputEnvVar is the name of the environment variable that GitHub Actions sets to the file where
+// the step outputs are written.
+const OutputEnvVar = "GITHUB_OUTPUT"
+
+var format string
+
+// AddFlag adds the '--output' flag to the given set of command line flags.
+func AddFlag(flags *pflag.FlagSet) {
+	flags.StringVar(
+		&format,
+		"output",
+		"",
+		fmt.Sprintf(
+			"Output format. Use '%s' to write annotations for the result and errors, mask "+
+				"secrets, and set step outputs in the file given by the '%s' environment "+
+				"variable.",
+			Format, OutputEnvVar,
+		),
+	)
+}
+
+// CheckFlag checks the value of the '--output' flag.
+func CheckFlag() error {
+	if format != "" && format != Format {
+		return fmt.Errorf(
+			"Unknown output format '%s', the only supported value is '%s'",
+			format, Format,
+		)
+	}
+	return nil
+}
+
+// Enabled returns true if the GitHub Actions output was selected with the '--output' flag.
+func Enabled() bool {
+	return format == Format
+}
+
+// Notice writes a notice annotation.
+func Notice(out io.Writer, message string) {
+	fmt.Fprintf(out, "::notice::%s\n", escapeData(message))
+}
+
+// Error writes an error annotation.
+func Error(out io.Writer, message string) {
+	fmt.Fprintf(out, "::error::%s\n", escapeData(message))
+}
+
+// Mask asks GitHub Actions to replace the given secret with asterisks in the rest of the log. Each
+// line of multi-line values is masked separately, as that is what GitHub Actions expects.
+func Mask(out io.Writer, secret string) {
+	for _, line := range strings.Split(secret, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			fmt.Fprintf(out, "::add-mask::%s\n", escapeData(line))
+		}
+	}
+}
+
+// SetOutputs adds the given step outputs to the file given by the 'GITHUB_OUTPUT' environment
+// variable. It does nothing if that variable isn't set, as happens when not running inside
+// GitHub Actions.
+func SetOutputs(outputs map[string]string) error {
+	path := os.Getenv(OutputEnvVar)
+	if path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("Can't open step outputs file '%s': %v", path, err)
+	}
+	err = WriteOutputs(file, outputs)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("Can't write step outputs file '%s': %v", path, err)
+	}
+	return file.Close()
+}
+
+// WriteOutputs writes the given step outputs, sorted by name, using the syntax of the file given
+// by the 'GITHUB_OUTPUT' environment variable. Multi-line values use a random delimiter.
+func WriteOutputs(out io.Writer, outputs map[string]string) error {
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := outputs[name]
+		var err error
+		if strings.ContainsAny(value, "\r\n") {
+			delimiter := "ghadelimiter_" + uuid.NewString()
+			_, err = fmt.Fprintf(out, "%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter)
+		} else {
+			_, err = fmt.Fprintf(out, "%s=%s\n", name, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeData escapes the characters that have a special meaning in the data of workflow commands.
+func escapeData(value string) string {
+	value = strings.ReplaceAll(value, "%", "%25")
+	value = strings.ReplaceAll(value, "\r", "%0D")
+	value = strings.ReplaceAll(value, "\n", "%0A")
+	return value
+}
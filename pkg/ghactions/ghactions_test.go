@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ghactions
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("GitHub Actions", func() {
+	AfterEach(func() {
+		format = ""
+	})
+
+	It("Is disabled by default", func() {
+		Expect(CheckFlag()).To(Succeed())
+		Expect(Enabled()).To(BeFalse())
+	})
+
+	It("Is enabled by the flag", func() {
+		format = Format
+		Expect(CheckFlag()).To(Succeed())
+		Expect(Enabled()).To(BeTrue())
+	})
+
+	It("Rejects unknown formats", func() {
+		format = "json"
+		Expect(CheckFlag()).To(MatchError(
+			"Unknown output format 'json', the only supported value is 'github-actions'",
+		))
+	})
+
+	It("Escapes the data of annotations", func() {
+		buffer := &bytes.Buffer{}
+		Notice(buffer, "100% done\nsecond line")
+		Error(buffer, "Failed\r\n")
+		Expect(buffer.String()).To(Equal(
+			"::notice::100%25 done%0Asecond line\n" +
+				"::error::Failed%0D%0A\n",
+		))
+	})
+
+	It("Masks each line of secrets", func() {
+		buffer := &bytes.Buffer{}
+		Mask(buffer, "-----BEGIN KEY-----\nabc\n\n-----END KEY-----\n")
+		Mask(buffer, "")
+		Expect(buffer.String()).To(Equal(
+			"::add-mask::-----BEGIN KEY-----\n" +
+				"::add-mask::abc\n" +
+				"::add-mask::-----END KEY-----\n",
+		))
+	})
+
+	It("Writes outputs sorted by name", func() {
+		buffer := &bytes.Buffer{}
+		err := WriteOutputs(buffer, map[string]string{
+			"cluster_id": "123",
+			"api_url":    "https://api.example.com:6443",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer.String()).To(Equal(
+			"api_url=https://api.example.com:6443\n" +
+				"cluster_id=123\n",
+		))
+	})
+
+	It("Uses a delimiter for multi-line outputs", func() {
+		buffer := &bytes.Buffer{}
+		err := WriteOutputs(buffer, map[string]string{
+			"notes": "first\nsecond",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		lines := strings.Split(buffer.String(), "\n")
+		Expect(lines).To(HaveLen(5))
+		Expect(lines[0]).To(HavePrefix("notes<<ghadelimiter_"))
+		delimiter := strings.TrimPrefix(lines[0], "notes<<")
+		Expect(lines[1:]).To(Equal([]string{"first", "second", delimiter, ""}))
+	})
+
+	It("Appends outputs to the file given by the environment variable", func() {
+		tmp, err := os.MkdirTemp("", "ocm-test-*")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmp)
+		path := filepath.Join(tmp, "output")
+		err = os.WriteFile(path, []byte("previous=value\n"), 0600)
+		Expect(err).ToNot(HaveOccurred())
+
+		old, ok := os.LookupEnv(OutputEnvVar)
+		defer func() {
+			if ok {
+				os.Setenv(OutputEnvVar, old)
+			} else {
+				os.Unsetenv(OutputEnvVar)
+			}
+		}()
+		os.Setenv(OutputEnvVar, path)
+
+		err = SetOutputs(map[string]string{
+			"cluster_id": "123",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("previous=value\ncluster_id=123\n"))
+	})
+
+	It("Ignores outputs when not running in GitHub Actions", func() {
+		old, ok := os.LookupEnv(OutputEnvVar)
+		defer func() {
+			if ok {
+				os.Setenv(OutputEnvVar, old)
+			}
+		}()
+		os.Unsetenv(OutputEnvVar)
+		Expect(SetOutputs(map[string]string{"cluster_id": "123"})).To(Succeed())
+	})
+})
@@ -0,0 +1,164 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hints contains the functions used to translate common errors returned by the API into
+// explanations that tell the user what went wrong and what commands can be used to fix it.
+package hints
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	sdkerrors "github.com/openshift-online/ocm-sdk-go/errors"
+
+	"github.com/openshift-online/ocm-cli/pkg/i18n"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
+)
+
+// Hint is the explanation of an error.
+type Hint struct {
+	// Explanation is a human readable description of the cause of the error.
+	Explanation string
+
+	// Commands are the commands that the user can run to investigate or fix the error.
+	Commands []string
+}
+
+// String generates the text that is displayed to the user.
+func (h *Hint) String() string {
+	buffer := &strings.Builder{}
+	buffer.WriteString(i18n.T(h.Explanation))
+	if len(h.Commands) > 0 {
+		fmt.Fprintf(buffer, "\n\n%s\n", i18n.T("Try:"))
+		for _, command := range h.Commands {
+			fmt.Fprintf(buffer, "  %s\n", command)
+		}
+	}
+	return strings.TrimRight(buffer.String(), "\n")
+}
+
+// rule describes how to recognize an error and the hint that corresponds to it. All the fields
+// that aren't zero must match.
+type rule struct {
+	status int
+	code   string
+	reason string
+	hint   *Hint
+}
+
+// rules is the list of known errors. The first rule that matches wins, so more specific rules
+// must be before the generic ones.
+var rules = []rule{
+	{
+		code: "ACCT-MGMT-11",
+		hint: &Hint{
+			Explanation: fmt.Sprintf(
+				"You need to accept the terms and conditions before using this "+
+					"service. Log in to %s with your Red Hat account and you will "+
+					"be asked to accept them.",
+				urls.ConsolePage,
+			),
+		},
+	},
+	{
+		code:   "CLUSTERS-MGMT-400",
+		reason: "quota",
+		hint: &Hint{
+			Explanation: "The organization doesn't have enough quota for the requested " +
+				"resources. Check the quota that is available, or contact the " +
+				"administrator of the organization to obtain more.",
+			Commands: []string{
+				"ocm list quota",
+			},
+		},
+	},
+	{
+		status: 401,
+		hint: &Hint{
+			Explanation: "The credentials weren't accepted by the server, probably " +
+				"because they have expired or have been revoked.",
+			Commands: []string{
+				"ocm login --token=...",
+			},
+		},
+	},
+	{
+		status: 403,
+		hint: &Hint{
+			Explanation: "Your account doesn't have the roles or capabilities required " +
+				"for this operation. Check the roles of your account and ask the " +
+				"administrator of the organization to grant the missing ones.",
+			Commands: []string{
+				"ocm account status",
+				"ocm whoami",
+			},
+		},
+	},
+}
+
+// Find returns the hint that corresponds to the given error, or nil if the error isn't one of the
+// well known ones.
+func Find(err error) *Hint {
+	if err == nil {
+		return nil
+	}
+	status, code, reason := details(err)
+	for _, rule := range rules {
+		if rule.status != 0 && rule.status != status {
+			continue
+		}
+		if rule.code != "" && rule.code != code {
+			continue
+		}
+		if rule.reason != "" && !strings.Contains(strings.ToLower(reason), rule.reason) {
+			continue
+		}
+		return rule.hint
+	}
+	return nil
+}
+
+// Regular expressions used to extract the details from the text generated by the SDK for API
+// errors, needed because most commands wrap errors using the '%v' verb:
+var (
+	statusRE = regexp.MustCompile(`status is (\d+)`)
+	codeRE   = regexp.MustCompile(`code is '([^']+)'`)
+)
+
+// details extracts the HTTP status, error code and reason from the error.
+func details(err error) (status int, code string, reason string) {
+	var apiErr *sdkerrors.Error
+	if errors.As(err, &apiErr) {
+		status = apiErr.Status()
+		code = apiErr.Code()
+		reason = apiErr.Reason()
+		return
+	}
+	message := err.Error()
+	match := statusRE.FindStringSubmatch(message)
+	if match != nil {
+		status, _ = strconv.Atoi(match[1])
+	}
+	match = codeRE.FindStringSubmatch(message)
+	if match != nil {
+		code = match[1]
+	}
+	reason = message
+	return
+}
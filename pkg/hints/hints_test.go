@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hints
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	sdkerrors "github.com/openshift-online/ocm-sdk-go/errors"
+)
+
+var _ = Describe("Find", func() {
+	It("Returns nil for unknown errors", func() {
+		Expect(Find(errors.New("something failed"))).To(BeNil())
+	})
+
+	It("Recognizes terms errors", func() {
+		apiErr, err := sdkerrors.NewError().
+			Status(400).
+			Code("ACCT-MGMT-11").
+			Reason("Terms must be accepted").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		hint := Find(fmt.Errorf("Can't create cluster: %w", apiErr))
+		Expect(hint).ToNot(BeNil())
+		Expect(hint.Explanation).To(ContainSubstring("terms and conditions"))
+	})
+
+	It("Recognizes quota errors wrapped with '%v'", func() {
+		err := fmt.Errorf(
+			"Can't create cluster: %v",
+			"status is 400, identifier is '400', code is 'CLUSTERS-MGMT-400' and "+
+				"operation identifier is '123': The organization has insufficient "+
+				"quota for this cluster",
+		)
+		hint := Find(err)
+		Expect(hint).ToNot(BeNil())
+		Expect(hint.Commands).To(ConsistOf("ocm list quota"))
+	})
+
+	It("Doesn't use the quota hint for other bad requests", func() {
+		err := errors.New(
+			"status is 400, identifier is '400' and code is 'CLUSTERS-MGMT-400': " +
+				"Invalid name",
+		)
+		Expect(Find(err)).To(BeNil())
+	})
+
+	It("Recognizes forbidden errors", func() {
+		err := errors.New("status is 403, identifier is '403': Forbidden")
+		hint := Find(err)
+		Expect(hint).ToNot(BeNil())
+		Expect(hint.String()).To(ContainSubstring("ocm account status"))
+	})
+})
@@ -0,0 +1,321 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to run the hooks configured by the administrators before
+// and after the commands.
+
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// Phases when hooks can run:
+const (
+	PhasePre  = "pre"
+	PhasePost = "post"
+)
+
+// redacted replaces the values of the flags that contain secrets in the description of the
+// invocation sent to the hooks.
+const redacted = "REDACTED"
+
+// sensitiveFlags are the words that, when they appear in the name of a flag, indicate that its
+// value is a secret that shouldn't be passed to the hooks.
+var sensitiveFlags = []string{
+	"password",
+	"secret",
+	"token",
+}
+
+// Hook is an external command that runs before or after some of the commands of the tool. It
+// receives in the standard input a JSON document describing the invocation. When a hook that
+// runs before the command fails the command isn't executed.
+type Hook struct {
+	Name     string   `yaml:"name"`
+	Phase    string   `yaml:"phase"`
+	Commands []string `yaml:"commands"`
+	Run      []string `yaml:"run"`
+
+	file string
+}
+
+// Invocation is the description of a command sent to the hooks. Note that the field names are
+// part of the input of the hooks, so don't change them without considering the existing hooks.
+type Invocation struct {
+	Phase   string            `json:"phase"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Flags   map[string]string `json:"flags"`
+	Time    time.Time         `json:"time"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// current contains the hooks loaded for the command that is running and the description of that
+// command, saved by the Start function so that the post hooks can run when the command finishes.
+var current struct {
+	hooks      []*Hook
+	invocation *Invocation
+}
+
+// Start loads the hooks from the given directory and runs the pre hooks that apply to the given
+// command, writing their output to the standard error. An error is returned if the hooks can't be
+// loaded or if any of them fails, and then the command shouldn't be executed.
+func Start(dir string, cmd *cobra.Command, argv []string) error {
+	hooks, err := Load(dir)
+	if err != nil {
+		return fmt.Errorf("Can't load hooks: %v", err)
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	current.hooks = hooks
+	current.invocation = NewInvocation(cmd, argv)
+	return RunPre(current.hooks, current.invocation, os.Stderr)
+}
+
+// Finish runs the post hooks of the command started with the Start function, passing them the
+// given result of the command. It does nothing if there is no such command, or if the post hooks
+// have already run.
+func Finish(result error) {
+	if current.invocation == nil {
+		return
+	}
+	RunPost(current.hooks, current.invocation, result, os.Stderr)
+	current.invocation = nil
+}
+
+// Exit runs the post hooks and then terminates the process with the given exit code. Commands
+// that need to exit without returning an error should use this instead of os.Exit, otherwise
+// the post hooks would not run.
+func Exit(code int) {
+	Finish(fmt.Errorf("Command exited with code %d", code))
+	os.Exit(code)
+}
+
+// Location returns the directory containing the hooks. It is the value of the 'OCM_HOOKS_DIR'
+// environment variable, or the 'hooks' directory next to the given configuration file. Returns
+// an empty string if neither is available.
+func Location(configFile string) string {
+	dir := os.Getenv("OCM_HOOKS_DIR")
+	if dir != "" || configFile == "" {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(configFile), "hooks")
+}
+
+// Load loads the hooks from the '.yaml' and '.yml' files of the given directory. Each file can
+// contain multiple hooks separated by '---'. Returns an empty list if the directory doesn't exist.
+func Load(dir string) (hooks []*Hook, err error) {
+	if dir == "" {
+		return
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	names := []string{}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var loaded []*Hook
+		loaded, err = loadFile(filepath.Join(dir, name))
+		if err != nil {
+			return
+		}
+		hooks = append(hooks, loaded...)
+	}
+	return
+}
+
+func loadFile(file string) (hooks []*Hook, err error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		hook := &Hook{}
+		err = decoder.Decode(hook)
+		if err == io.EOF {
+			err = nil
+			return
+		}
+		if err != nil {
+			err = fmt.Errorf("Can't parse hook file '%s': %v", file, err)
+			return
+		}
+		hook.file = file
+		err = hook.check()
+		if err != nil {
+			return
+		}
+		hooks = append(hooks, hook)
+	}
+}
+
+// check checks that the hook is valid and fills the default values.
+func (h *Hook) check() error {
+	if h.Name == "" {
+		return fmt.Errorf("Hook in file '%s' doesn't have a name", h.file)
+	}
+	if len(h.Run) == 0 {
+		return fmt.Errorf("Hook '%s' in file '%s' doesn't have a command to run", h.Name, h.file)
+	}
+	switch h.Phase {
+	case "":
+		h.Phase = PhasePre
+	case PhasePre, PhasePost:
+	default:
+		return fmt.Errorf(
+			"Phase '%s' of hook '%s' in file '%s' isn't valid, it should be '%s' or '%s'",
+			h.Phase, h.Name, h.file, PhasePre, PhasePost,
+		)
+	}
+	for i, command := range h.Commands {
+		h.Commands[i] = strings.Join(strings.Fields(command), " ")
+	}
+	return nil
+}
+
+// matches checks if the hook applies to the given command. Hooks without commands apply to all
+// of them, and hooks for a command also apply to its subcommands, so a hook for 'cluster'
+// also runs for 'cluster logs'.
+func (h *Hook) matches(command string) bool {
+	if len(h.Commands) == 0 {
+		return true
+	}
+	for _, candidate := range h.Commands {
+		if command == candidate || strings.HasPrefix(command, candidate+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// NewInvocation creates the description of the given command, containing its path without the
+// name of the tool, the positional arguments and the flags explicitly set by the user. The values
+// of flags that may contain secrets, like tokens, are redacted.
+func NewInvocation(cmd *cobra.Command, argv []string) *Invocation {
+	command := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name())
+	invocation := &Invocation{
+		Command: strings.TrimSpace(command),
+		Args:    argv,
+		Flags:   map[string]string{},
+		Time:    time.Now().UTC(),
+	}
+	if invocation.Args == nil {
+		invocation.Args = []string{}
+	}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		value := flag.Value.String()
+		for _, word := range sensitiveFlags {
+			if strings.Contains(flag.Name, word) {
+				value = redacted
+				break
+			}
+		}
+		invocation.Flags[flag.Name] = value
+	})
+	return invocation
+}
+
+// RunPre runs the hooks of the pre phase that apply to the invocation. It stops and returns an
+// error as soon as one of them fails, and then the command shouldn't be executed. The output of
+// the hooks is written to the given writer.
+func RunPre(hooks []*Hook, invocation *Invocation, out io.Writer) error {
+	invocation.Phase = PhasePre
+	for _, hook := range hooks {
+		if hook.Phase != PhasePre || !hook.matches(invocation.Command) {
+			continue
+		}
+		err := hook.run(invocation, out)
+		if err != nil {
+			return fmt.Errorf("Command rejected by hook '%s': %v", hook.Name, err)
+		}
+	}
+	return nil
+}
+
+// RunPost runs the hooks of the post phase that apply to the invocation, passing them the error
+// of the command if it failed. The command has already been executed, so failures of these hooks
+// are only reported as warnings written to the given writer, together with their output.
+func RunPost(hooks []*Hook, invocation *Invocation, result error, out io.Writer) {
+	invocation.Phase = PhasePost
+	invocation.Error = ""
+	if result != nil {
+		invocation.Error = result.Error()
+	}
+	for _, hook := range hooks {
+		if hook.Phase != PhasePost || !hook.matches(invocation.Command) {
+			continue
+		}
+		err := hook.run(invocation, out)
+		if err != nil {
+			fmt.Fprintf(out, "Warning: hook '%s' failed: %v\n", hook.Name, err)
+		}
+	}
+}
+
+// run runs the hook sending the description of the invocation to its standard input. The output
+// is written to the given writer, so that it doesn't mix with the output of the command.
+func (h *Hook) run(invocation *Invocation, out io.Writer) error {
+	data, err := json.Marshal(invocation)
+	if err != nil {
+		return err
+	}
+	// #nosec G204
+	process := exec.Command(h.Run[0], h.Run[1:]...)
+	process.Env = append(
+		os.Environ(),
+		"OCM_HOOK_NAME="+h.Name,
+		"OCM_HOOK_PHASE="+invocation.Phase,
+		"OCM_HOOK_COMMAND="+invocation.Command,
+	)
+	process.Stdin = bytes.NewReader(data)
+	process.Stdout = out
+	process.Stderr = out
+	err = process.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("exited with code %d", exitErr.ExitCode())
+	}
+	return err
+}
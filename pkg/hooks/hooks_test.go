@@ -0,0 +1,185 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+	"github.com/spf13/cobra"
+)
+
+var _ = Describe("Hooks", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-hooks-*")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	write := func(name, content string) {
+		err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0600)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("Returns nothing if the directory doesn't exist", func() {
+		hooks, err := Load(filepath.Join(tmpDir, "missing"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hooks).To(BeEmpty())
+	})
+
+	It("Loads multiple hooks from the same file", func() {
+		write("hooks.yaml", `
+name: ticket
+commands:
+- delete  cluster
+run:
+- /usr/local/bin/check-ticket
+---
+name: audit
+phase: post
+run:
+- /usr/local/bin/audit
+- --verbose
+`)
+		write("README.md", "Not a hook")
+		hooks, err := Load(tmpDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hooks).To(HaveLen(2))
+		Expect(hooks[0].Name).To(Equal("ticket"))
+		Expect(hooks[0].Phase).To(Equal(PhasePre))
+		Expect(hooks[0].Commands).To(Equal([]string{"delete cluster"}))
+		Expect(hooks[1].Name).To(Equal("audit"))
+		Expect(hooks[1].Phase).To(Equal(PhasePost))
+		Expect(hooks[1].Run).To(Equal([]string{"/usr/local/bin/audit", "--verbose"}))
+	})
+
+	It("Rejects hooks without a command to run", func() {
+		write("bad.yaml", "name: bad\n")
+		_, err := Load(tmpDir)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("doesn't have a command to run"))
+	})
+
+	It("Rejects hooks with invalid phases", func() {
+		write("bad.yaml", "name: bad\nphase: during\nrun: [/bin/true]\n")
+		_, err := Load(tmpDir)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Phase 'during' of hook 'bad'"))
+	})
+
+	DescribeTable(
+		"Matches commands",
+		func(commands []string, command string, expected bool) {
+			hook := &Hook{
+				Commands: commands,
+			}
+			Expect(hook.matches(command)).To(Equal(expected))
+		},
+		Entry("All commands", nil, "delete cluster", true),
+		Entry("Same command", []string{"delete cluster"}, "delete cluster", true),
+		Entry("Subcommand", []string{"cluster"}, "cluster logs", true),
+		Entry("Different command", []string{"delete cluster"}, "delete idp", false),
+		Entry("Partial word", []string{"cluster"}, "clusters", false),
+	)
+
+	It("Describes the invocation redacting secrets", func() {
+		var token, output string
+		root := &cobra.Command{Use: "ocm"}
+		cmd := &cobra.Command{Use: "login"}
+		cmd.Flags().StringVar(&token, "token", "", "")
+		cmd.Flags().StringVar(&output, "output", "", "")
+		root.AddCommand(cmd)
+		err := cmd.Flags().Parse([]string{"--token", "my-token", "--output", "json", "arg"})
+		Expect(err).ToNot(HaveOccurred())
+		invocation := NewInvocation(cmd, cmd.Flags().Args())
+		Expect(invocation.Command).To(Equal("login"))
+		Expect(invocation.Args).To(Equal([]string{"arg"}))
+		Expect(invocation.Flags).To(Equal(map[string]string{
+			"token":  "REDACTED",
+			"output": "json",
+		}))
+	})
+
+	It("Passes the invocation to the pre hooks and stops when one fails", func() {
+		input := filepath.Join(tmpDir, "input.json")
+		hooks := []*Hook{
+			{
+				Name:  "save",
+				Phase: PhasePre,
+				Run:   []string{"sh", "-c", "cat > " + input + "; echo saved"},
+			},
+			{
+				Name:     "ticket",
+				Phase:    PhasePre,
+				Commands: []string{"delete"},
+				Run:      []string{"sh", "-c", "exit 3"},
+			},
+		}
+		out := &bytes.Buffer{}
+		invocation := &Invocation{
+			Command: "delete cluster",
+			Args:    []string{"123"},
+		}
+		err := RunPre(hooks, invocation, out)
+		Expect(err).To(MatchError("Command rejected by hook 'ticket': exited with code 3"))
+		Expect(out.String()).To(Equal("saved\n"))
+		data, err := os.ReadFile(input)
+		Expect(err).ToNot(HaveOccurred())
+		var received Invocation
+		err = json.Unmarshal(data, &received)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(received.Phase).To(Equal(PhasePre))
+		Expect(received.Command).To(Equal("delete cluster"))
+		Expect(received.Args).To(Equal([]string{"123"}))
+	})
+
+	It("Passes the error to the post hooks and only warns when they fail", func() {
+		hooks := []*Hook{
+			{
+				Name:  "pre",
+				Phase: PhasePre,
+				Run:   []string{"sh", "-c", "echo pre"},
+			},
+			{
+				Name:  "audit",
+				Phase: PhasePost,
+				Run:   []string{"sh", "-c", "echo $OCM_HOOK_PHASE; grep -q '\"error\":\"boom\"'"},
+			},
+			{
+				Name:  "broken",
+				Phase: PhasePost,
+				Run:   []string{"sh", "-c", "exit 1"},
+			},
+		}
+		out := &bytes.Buffer{}
+		RunPost(hooks, &Invocation{Command: "get"}, errors.New("boom"), out)
+		Expect(out.String()).To(Equal(
+			"post\nWarning: hook 'broken' failed: exited with code 1\n",
+		))
+	})
+})
@@ -0,0 +1,291 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package i18n contains the functions used to translate the help and the common error messages
+// of the command line tool. Translations are loaded from the message catalogs in the 'catalogs'
+// directory, one YAML file per language, named after the language code, for example 'es.yaml'.
+// Each catalog maps the original English messages to their translations. Messages that aren't
+// in the catalog, or languages that don't have a catalog, are written in English.
+//
+// The language is selected with the '--lang' command line option. If that isn't used it is
+// detected from the 'LC_ALL', 'LC_MESSAGES' and 'LANG' environment variables, in that order.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed catalogs
+var catalogFS embed.FS
+
+// DefaultLanguage is the language of the original messages.
+const DefaultLanguage = "en"
+
+var lang string
+
+// AddFlag adds the '--lang' flag to the given set of command line flags.
+func AddFlag(flags *pflag.FlagSet) {
+	flags.StringVar(
+		&lang,
+		"lang",
+		"",
+		fmt.Sprintf(
+			"Language of the help and of the common error messages, one of %s. By "+
+				"default it is detected from the 'LC_ALL', 'LC_MESSAGES' and 'LANG' "+
+				"environment variables.",
+			strings.Join(Languages(), ", "),
+		),
+	)
+}
+
+// Languages returns the codes of the languages that have a catalog, including the default one.
+func Languages() []string {
+	result := []string{DefaultLanguage}
+	entries, err := catalogFS.ReadDir("catalogs")
+	if err != nil {
+		return result
+	}
+	for _, entry := range entries {
+		result = append(result, strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Language returns the code of the language selected with the '--lang' flag or detected from the
+// environment.
+func Language() string {
+	if lang != "" {
+		return normalize(lang)
+	}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value != "" {
+			return normalize(value)
+		}
+	}
+	return DefaultLanguage
+}
+
+// normalize extracts the language code from a locale name like 'es_ES.UTF-8'. The 'C' and 'POSIX'
+// locales are English.
+func normalize(locale string) string {
+	code := strings.ToLower(locale)
+	for _, separator := range []string{".", "@", "_", "-"} {
+		index := strings.Index(code, separator)
+		if index >= 0 {
+			code = code[:index]
+		}
+	}
+	switch code {
+	case "", "c", "posix":
+		return DefaultLanguage
+	}
+	return code
+}
+
+// catalog contains the translated messages of a language, and the patterns created from the
+// messages that contain formatting verbs.
+type catalog struct {
+	messages map[string]string
+	patterns []*pattern
+}
+
+// pattern is a regular expression that matches the messages generated from a format, for example
+// 'Can't load cluster: (.*)' for the 'Can't load cluster: %v' format, and the translation of that
+// format.
+type pattern struct {
+	re          *regexp.Regexp
+	translation string
+}
+
+// verbRE matches the formatting verbs that can be used in the messages of catalogs that are
+// translated with the Translate function. Only '%s' and '%v' are supported, as the values that
+// replace them are always text.
+var verbRE = regexp.MustCompile(`%[sv]`)
+
+// catalogs contains the catalogs that have already been loaded, indexed by language code. Catalogs
+// that don't exist or can't be loaded are stored as nil.
+var (
+	catalogs     = map[string]*catalog{}
+	catalogsLock = &sync.Mutex{}
+)
+
+// loadCatalog returns the catalog of the given language, or nil if there is no such catalog.
+func loadCatalog(language string) *catalog {
+	catalogsLock.Lock()
+	defer catalogsLock.Unlock()
+	result, ok := catalogs[language]
+	if ok {
+		return result
+	}
+	defer func() {
+		catalogs[language] = result
+	}()
+	data, err := catalogFS.ReadFile(path.Join("catalogs", language+".yaml"))
+	if err != nil {
+		return nil
+	}
+	messages := map[string]string{}
+	err = yaml.Unmarshal(data, &messages)
+	if err != nil {
+		return nil
+	}
+	result = &catalog{
+		messages: messages,
+	}
+
+	// Create the patterns, longest messages first so that the most specific ones are tried
+	// first:
+	formats := []string{}
+	for message := range messages {
+		if verbRE.MatchString(message) {
+			formats = append(formats, message)
+		}
+	}
+	sort.Slice(formats, func(i, j int) bool {
+		if len(formats[i]) != len(formats[j]) {
+			return len(formats[i]) > len(formats[j])
+		}
+		return formats[i] < formats[j]
+	})
+	for _, format := range formats {
+		parts := verbRE.Split(format, -1)
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		result.patterns = append(result.patterns, &pattern{
+			re:          regexp.MustCompile("^" + strings.Join(parts, "(.*)") + "$"),
+			translation: messages[format],
+		})
+	}
+	return result
+}
+
+// T returns the translation of the given message to the current language, or the message itself
+// if there is no translation.
+func T(message string) string {
+	language := Language()
+	if language == DefaultLanguage {
+		return message
+	}
+	catalog := loadCatalog(language)
+	if catalog == nil {
+		return message
+	}
+	translation := catalog.messages[message]
+	if translation == "" {
+		return message
+	}
+	return translation
+}
+
+// Sprintf translates the given format and then formats it with the given arguments.
+func Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Translate translates a complete message, like the text of an error. Messages that are in the
+// catalog are translated directly. Otherwise the message is matched against the catalog messages
+// that contain '%s' or '%v' verbs, and the values that replaced those verbs are translated as
+// well. For example, if the catalog contains 'Can't load cluster: %v' and 'Not logged in' then
+// the 'Can't load cluster: Not logged in' message will be completely translated.
+func Translate(message string) string {
+	language := Language()
+	if language == DefaultLanguage {
+		return message
+	}
+	catalog := loadCatalog(language)
+	if catalog == nil {
+		return message
+	}
+	return catalog.translate(message, 0)
+}
+
+// maxTranslateDepth is the maximum number of nested formats that are translated.
+const maxTranslateDepth = 5
+
+func (c *catalog) translate(message string, depth int) string {
+	translation := c.messages[message]
+	if translation != "" {
+		return translation
+	}
+	if depth >= maxTranslateDepth {
+		return message
+	}
+	for _, pattern := range c.patterns {
+		matches := pattern.re.FindStringSubmatch(message)
+		if matches == nil {
+			continue
+		}
+		args := make([]interface{}, len(matches)-1)
+		for i, match := range matches[1:] {
+			args[i] = c.translate(match, depth+1)
+		}
+		return fmt.Sprintf(pattern.translation, args...)
+	}
+	return message
+}
+
+// usageHeadings are the texts of the usage template of cobra that are translated.
+var usageHeadings = []string{
+	"Usage:",
+	"Aliases:",
+	"Examples:",
+	"Available Commands:",
+	"Flags:",
+	"Global Flags:",
+	"Additional help topics:",
+	`Use "{{.CommandPath}} [command] --help" for more information about a command.`,
+}
+
+// Localize translates the short and long descriptions, the usage of the flags and the usage
+// template of the given command and all its sub-commands to the current language.
+func Localize(cmd *cobra.Command) {
+	if Language() == DefaultLanguage {
+		return
+	}
+	template := cmd.UsageTemplate()
+	for _, heading := range usageHeadings {
+		template = strings.ReplaceAll(template, heading, T(heading))
+	}
+	cmd.SetUsageTemplate(template)
+	localize(cmd)
+}
+
+func localize(cmd *cobra.Command) {
+	cmd.Short = T(cmd.Short)
+	cmd.Long = T(cmd.Long)
+	translate := func(flag *pflag.Flag) {
+		flag.Usage = T(flag.Usage)
+	}
+	cmd.LocalFlags().VisitAll(translate)
+	cmd.PersistentFlags().VisitAll(translate)
+	for _, child := range cmd.Commands() {
+		localize(child)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package i18n
+
+import (
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Language", func() {
+	AfterEach(func() {
+		lang = ""
+	})
+
+	DescribeTable(
+		"Normalizes locale names",
+		func(locale, expected string) {
+			Expect(normalize(locale)).To(Equal(expected))
+		},
+		Entry("Language only", "es", "es"),
+		Entry("With territory and encoding", "es_ES.UTF-8", "es"),
+		Entry("With modifier", "de_DE@euro", "de"),
+		Entry("Upper case", "ES-es", "es"),
+		Entry("C locale", "C.UTF-8", "en"),
+		Entry("POSIX locale", "POSIX", "en"),
+	)
+
+	It("Prefers the flag to the environment", func() {
+		lang = "es"
+		Expect(Language()).To(Equal("es"))
+	})
+
+	It("Includes the default language in the list of languages", func() {
+		Expect(Languages()).To(ContainElements("en", "es"))
+	})
+})
+
+var _ = Describe("Translation", func() {
+	BeforeEach(func() {
+		lang = "es"
+	})
+
+	AfterEach(func() {
+		lang = ""
+	})
+
+	It("Translates known messages", func() {
+		Expect(T("Usage:")).To(Equal("Uso:"))
+	})
+
+	It("Returns unknown messages unchanged", func() {
+		Expect(T("Something else")).To(Equal("Something else"))
+		Expect(Translate("Something else")).To(Equal("Something else"))
+	})
+
+	It("Returns messages unchanged for languages without catalog", func() {
+		lang = "xx"
+		Expect(T("Usage:")).To(Equal("Usage:"))
+	})
+
+	It("Translates nested formats", func() {
+		Expect(Translate(
+			"Failed to create OCM connection: Not logged in, credentials aren't set, " +
+				"run the 'login' command",
+		)).To(Equal(
+			"No se pudo crear la conexión con OCM: No ha iniciado sesión, no hay " +
+				"credenciales, ejecute el comando 'login'",
+		))
+	})
+
+	It("Keeps the values that aren't in the catalog", func() {
+		Expect(Translate("Failed to create OCM connection: boom")).To(Equal(
+			"No se pudo crear la conexión con OCM: boom",
+		))
+	})
+
+	It("Localizes the help of commands", func() {
+		root := &cobra.Command{
+			Use:   "ocm",
+			Short: "Command line tool for api.openshift.com.",
+		}
+		child := &cobra.Command{
+			Use:   "login",
+			Short: "Log in",
+			Run:   func(*cobra.Command, []string) {},
+		}
+		child.Flags().Bool("debug", false, "Enable debug mode.")
+		root.AddCommand(child)
+		Localize(root)
+		Expect(root.Short).To(Equal("Herramienta de línea de comandos para api.openshift.com."))
+		Expect(child.Short).To(Equal("Iniciar sesión"))
+		Expect(child.Flags().Lookup("debug").Usage).To(Equal("Activar el modo de depuración."))
+		Expect(child.UsageTemplate()).To(ContainSubstring("Opciones:"))
+	})
+})
+
+var _ = Describe("Catalogs", func() {
+	It("Use the same formatting verbs as the original messages", func() {
+		entries, err := catalogFS.ReadDir("catalogs")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).ToNot(BeEmpty())
+		for _, entry := range entries {
+			data, err := catalogFS.ReadFile(path.Join("catalogs", entry.Name()))
+			Expect(err).ToNot(HaveOccurred())
+			messages := map[string]string{}
+			err = yaml.Unmarshal(data, &messages)
+			Expect(err).ToNot(HaveOccurred(), entry.Name())
+			for message, translation := range messages {
+				Expect(translation).ToNot(BeEmpty(), message)
+				Expect(strings.Count(translation, "%")).To(
+					Equal(strings.Count(message, "%")),
+					"%s: %s", entry.Name(), message,
+				)
+				Expect(verbRE.FindAllString(translation, -1)).To(
+					Equal(verbRE.FindAllString(message, -1)),
+					"%s: %s", entry.Name(), message,
+				)
+			}
+		}
+	})
+})
@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the '--impersonate-user' and
+// '--impersonate-account-id' command line options.
+
+package impersonate
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+)
+
+// Names of the headers used to ask the server to process the requests on behalf of another user.
+// The server only honours them when the authenticated user is allowed to impersonate.
+const (
+	UserHeader      = "Impersonate-User"
+	AccountIDHeader = "Impersonate-Account-Id"
+)
+
+// AddFlags adds the impersonation flags to the given set of command line flags.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(
+		&user,
+		"impersonate-user",
+		"",
+		"Send the requests on behalf of the user with the given user name. Only "+
+			"available to users that are authorized to impersonate other users.",
+	)
+	flags.StringVar(
+		&accountID,
+		"impersonate-account-id",
+		"",
+		"Send the requests on behalf of the account with the given identifier. Only "+
+			"available to users that are authorized to impersonate other users.",
+	)
+}
+
+// Enabled returns a boolean flag that indicates if impersonation is enabled.
+func Enabled() bool {
+	return user != "" || accountID != ""
+}
+
+// Description returns a human readable description of the impersonated user, for example
+// "user 'joe'" or "account '123'".
+func Description() string {
+	var parts []string
+	if user != "" {
+		parts = append(parts, fmt.Sprintf("user '%s'", user))
+	}
+	if accountID != "" {
+		parts = append(parts, fmt.Sprintf("account '%s'", accountID))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// user and accountID are the values of the impersonation flags.
+var (
+	user      string
+	accountID string
+)
+
+// TransportWrapper returns a transport wrapper that adds the impersonation headers to the
+// requests. The first time that a request is sent it writes a warning to the given writer, so
+// that it is always clear that the results correspond to other user. Requests sent to the token
+// URL aren't changed, as authentication is always done with the real credentials.
+func TransportWrapper(tokenURL string, out io.Writer) func(http.RoundTripper) http.RoundTripper {
+	return func(wrapped http.RoundTripper) http.RoundTripper {
+		return &roundTripper{
+			tokenURL: tokenURL,
+			out:      out,
+			wrapped:  wrapped,
+		}
+	}
+}
+
+type roundTripper struct {
+	tokenURL string
+	out      io.Writer
+	wrapped  http.RoundTripper
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*roundTripper)(nil)
+
+// warning makes sure that the warning is written only once, even if multiple connections are
+// created.
+var warning sync.Once
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	if strings.HasPrefix(request.URL.String(), t.tokenURL) {
+		return t.wrapped.RoundTrip(request)
+	}
+	warning.Do(func() {
+		fmt.Fprintf(
+			t.out,
+			"WARNING: Impersonating %s, requests are processed on behalf of "+
+				"that user\n",
+			Description(),
+		)
+	})
+
+	// Note that the request can't be modified, so we need to clone it:
+	request = request.Clone(request.Context())
+	if user != "" {
+		request.Header.Set(UserHeader, user)
+	}
+	if accountID != "" {
+		request.Header.Set(AccountIDHeader, accountID)
+	}
+	return t.wrapped.RoundTrip(request)
+}
@@ -0,0 +1,221 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package junit contains the types and functions used to write the results of checks as JUnit
+// XML reports, so that CI systems and release pipelines can display them as test results.
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Suite is a named group of test cases, for example all the checks done by a command.
+type Suite struct {
+	Name  string
+	Cases []*Case
+}
+
+// Case is the result of one check. The case passed if both the failure and the skip reason are
+// empty.
+type Case struct {
+	// Class is used by most CI systems to group the cases inside the suite.
+	Class string
+
+	// Name identifies the check.
+	Name string
+
+	// Duration is the time that it took to run the check.
+	Duration time.Duration
+
+	// Failure is a short description of the failure, and Details the complete text.
+	Failure string
+	Details string
+
+	// Skipped is the reason why the check wasn't executed.
+	Skipped string
+
+	// Output is additional text produced by the check, for example warnings.
+	Output string
+}
+
+// NewSuite creates a new empty suite with the given name.
+func NewSuite(name string) *Suite {
+	return &Suite{
+		Name: name,
+	}
+}
+
+// Add adds a case to the suite, and returns it so that the caller can fill its details.
+func (s *Suite) Add(class, name string) *Case {
+	result := &Case{
+		Class: class,
+		Name:  name,
+	}
+	s.Cases = append(s.Cases, result)
+	return result
+}
+
+// Failures returns the number of failed cases.
+func (s *Suite) Failures() int {
+	count := 0
+	for _, item := range s.Cases {
+		if item.Failure != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// Skips returns the number of skipped cases.
+func (s *Suite) Skips() int {
+	count := 0
+	for _, item := range s.Cases {
+		if item.Failure == "" && item.Skipped != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// Duration returns the sum of the durations of the cases.
+func (s *Suite) Duration() time.Duration {
+	var total time.Duration
+	for _, item := range s.Cases {
+		total += item.Duration
+	}
+	return total
+}
+
+// Write writes the XML report containing the given suites.
+func Write(out io.Writer, suites ...*Suite) error {
+	report := &xmlSuites{}
+	var total time.Duration
+	for _, suite := range suites {
+		converted := &xmlSuite{
+			Name:     suite.Name,
+			Tests:    len(suite.Cases),
+			Failures: suite.Failures(),
+			Skipped:  suite.Skips(),
+			Time:     seconds(suite.Duration()),
+		}
+		for _, item := range suite.Cases {
+			converted.Cases = append(converted.Cases, convertCase(item))
+		}
+		report.Tests += converted.Tests
+		report.Failures += converted.Failures
+		report.Skipped += converted.Skipped
+		total += suite.Duration()
+		report.Suites = append(report.Suites, converted)
+	}
+	report.Time = seconds(total)
+	_, err := io.WriteString(out, xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, "\n")
+	return err
+}
+
+// WriteFile writes the XML report containing the given suites to the given file.
+func WriteFile(path string, suites ...*Suite) error {
+	// #nosec G304
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Can't create JUnit report file '%s': %v", path, err)
+	}
+	err = Write(file, suites...)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("Can't write JUnit report file '%s': %v", path, err)
+	}
+	return file.Close()
+}
+
+func convertCase(item *Case) *xmlCase {
+	converted := &xmlCase{
+		Class:  item.Class,
+		Name:   item.Name,
+		Time:   seconds(item.Duration),
+		Output: item.Output,
+	}
+	if item.Failure != "" {
+		converted.Failure = &xmlFailure{
+			Message: item.Failure,
+			Type:    "failure",
+			Text:    item.Details,
+		}
+	} else if item.Skipped != "" {
+		converted.Skipped = &xmlSkipped{
+			Message: item.Skipped,
+		}
+	}
+	return converted
+}
+
+func seconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
+
+// The following types describe the XML format. They follow the format used by Jenkins, which is
+// the one that most CI systems understand.
+
+type xmlSuites struct {
+	XMLName  xml.Name    `xml:"testsuites"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Suites   []*xmlSuite `xml:"testsuite"`
+}
+
+type xmlSuite struct {
+	Name     string     `xml:"name,attr"`
+	Tests    int        `xml:"tests,attr"`
+	Failures int        `xml:"failures,attr"`
+	Errors   int        `xml:"errors,attr"`
+	Skipped  int        `xml:"skipped,attr"`
+	Time     string     `xml:"time,attr"`
+	Cases    []*xmlCase `xml:"testcase"`
+}
+
+type xmlCase struct {
+	Class   string      `xml:"classname,attr"`
+	Name    string      `xml:"name,attr"`
+	Time    string      `xml:"time,attr"`
+	Failure *xmlFailure `xml:"failure,omitempty"`
+	Skipped *xmlSkipped `xml:"skipped,omitempty"`
+	Output  string      `xml:"system-out,omitempty"`
+}
+
+type xmlFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type xmlSkipped struct {
+	Message string `xml:"message,attr"`
+}
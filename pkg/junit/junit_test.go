@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package junit
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Write", func() {
+	It("Writes passed, failed and skipped cases", func() {
+		suite := NewSuite("ocm lint")
+		passed := suite.Add("lint", "cluster.yaml")
+		passed.Duration = 1500 * time.Millisecond
+		passed.Output = "cluster.yaml: warning: something"
+		failed := suite.Add("lint", "pool.yaml")
+		failed.Failure = "Found 1 errors"
+		failed.Details = "pool.yaml: error: <bad>"
+		skipped := suite.Add("lint", "idp.yaml")
+		skipped.Skipped = "Not checked"
+
+		buffer := &bytes.Buffer{}
+		err := Write(buffer, suite)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer.String()).To(Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" skipped="1" time="1.500">
+  <testsuite name="ocm lint" tests="3" failures="1" errors="0" skipped="1" time="1.500">
+    <testcase classname="lint" name="cluster.yaml" time="1.500">
+      <system-out>cluster.yaml: warning: something</system-out>
+    </testcase>
+    <testcase classname="lint" name="pool.yaml" time="0.000">
+      <failure message="Found 1 errors" type="failure">pool.yaml: error: &lt;bad&gt;</failure>
+    </testcase>
+    <testcase classname="lint" name="idp.yaml" time="0.000">
+      <skipped message="Not checked"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`))
+	})
+
+	It("Writes multiple suites", func() {
+		first := NewSuite("first")
+		first.Add("a", "one")
+		second := NewSuite("second")
+		second.Add("b", "two").Failure = "Failed"
+
+		buffer := &bytes.Buffer{}
+		err := Write(buffer, first, second)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer.String()).To(ContainSubstring(
+			`<testsuites tests="2" failures="1" skipped="0" time="0.000">`,
+		))
+		Expect(buffer.String()).To(ContainSubstring(`<testsuite name="first"`))
+		Expect(buffer.String()).To(ContainSubstring(`<testsuite name="second"`))
+	})
+
+	It("Counts a failed case with skip reason only as failure", func() {
+		suite := NewSuite("my-suite")
+		item := suite.Add("my-class", "my-case")
+		item.Failure = "Failed"
+		item.Skipped = "Skipped"
+		Expect(suite.Failures()).To(Equal(1))
+		Expect(suite.Skips()).To(BeZero())
+	})
+})
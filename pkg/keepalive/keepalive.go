@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains a transport wrapper that tunes the pool of connections of the transport used
+// to send requests to the API.
+
+package keepalive
+
+import (
+	"net/http"
+	"time"
+)
+
+// maxIdleConnsPerHost is the number of idle connections to the API server kept open for reuse.
+// The default of the Go HTTP transport is only two, so commands that send requests from multiple
+// goroutines, like the ones that scan all the clusters or all the accounts, would close most of
+// the connections after each request and open new ones, with a new TLS handshake, for the next.
+const maxIdleConnsPerHost = 32
+
+// idleConnTimeout is the time that an idle connection is kept open. It is long enough to cover the
+// pauses introduced by the throttling of the account management requests.
+const idleConnTimeout = 90 * time.Second
+
+// TransportWrapper returns a transport wrapper that adjusts the pool of connections of the
+// underlying transport so that they are reused by all the pages and goroutines of a command. It
+// must be the last wrapper added to the connection, as that is the one that receives the actual
+// transport. Other kinds of round trippers are returned unchanged.
+func TransportWrapper() func(http.RoundTripper) http.RoundTripper {
+	return func(wrapped http.RoundTripper) http.RoundTripper {
+		transport, ok := wrapped.(*http.Transport)
+		if !ok {
+			return wrapped
+		}
+		if transport.MaxIdleConns != 0 && transport.MaxIdleConns < maxIdleConnsPerHost {
+			transport.MaxIdleConns = maxIdleConnsPerHost
+		}
+		transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+		if transport.IdleConnTimeout == 0 {
+			transport.IdleConnTimeout = idleConnTimeout
+		}
+		return transport
+	}
+}
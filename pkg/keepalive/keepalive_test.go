@@ -0,0 +1,54 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keepalive
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Transport wrapper", func() {
+	It("Increases the number of idle connections per host", func() {
+		transport := &http.Transport{}
+		result := TransportWrapper()(transport)
+		Expect(result).To(BeIdenticalTo(transport))
+		Expect(transport.MaxIdleConnsPerHost).To(Equal(maxIdleConnsPerHost))
+		Expect(transport.IdleConnTimeout).To(Equal(idleConnTimeout))
+	})
+
+	It("Doesn't reduce the total number of idle connections", func() {
+		transport := &http.Transport{
+			MaxIdleConns: 100,
+		}
+		TransportWrapper()(transport)
+		Expect(transport.MaxIdleConns).To(Equal(100))
+	})
+
+	It("Returns other round trippers unchanged", func() {
+		wrapped := &fakeRoundTripper{}
+		result := TransportWrapper()(wrapped)
+		Expect(result).To(BeIdenticalTo(wrapped))
+	})
+})
+
+type fakeRoundTripper struct{}
+
+func (t *fakeRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	return nil, nil
+}
@@ -0,0 +1,454 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to check cluster, machine pool and identity provider
+// specification files without sending them to the API.
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"gopkg.in/yaml.v3"
+
+	c "github.com/openshift-online/ocm-cli/pkg/cluster"
+)
+
+// Severity indicates how serious is a problem.
+type Severity string
+
+const (
+	// Error is used for problems that will make the API reject the object.
+	Error Severity = "error"
+
+	// Warning is used for objects that will be accepted but don't follow the recommended
+	// practices.
+	Warning Severity = "warning"
+)
+
+// Kinds of objects supported:
+const (
+	KindCluster          = "Cluster"
+	KindMachinePool      = "MachinePool"
+	KindIdentityProvider = "IdentityProvider"
+)
+
+// Problem describes a problem found in a specification file.
+type Problem struct {
+	File     string
+	Severity Severity
+	Message  string
+}
+
+// String returns the text used to report the problem.
+func (p *Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.File, p.Severity, p.Message)
+}
+
+// Linter checks specification files. Don't create objects of this type directly, use the
+// NewLinter function instead.
+type Linter struct {
+	versions map[string]bool
+	problems []*Problem
+	file     string
+}
+
+// NewLinter creates a new linter.
+func NewLinter() *Linter {
+	return &Linter{}
+}
+
+// Versions sets the list of versions that are available. If this isn't called the version of
+// clusters isn't checked. The versions can be given with or without the 'openshift-v' prefix.
+func (l *Linter) Versions(values []string) *Linter {
+	l.versions = map[string]bool{}
+	for _, value := range values {
+		l.versions[c.DropOpenshiftVPrefix(value)] = true
+	}
+	return l
+}
+
+// Problems returns the problems found so far.
+func (l *Linter) Problems() []*Problem {
+	return l.problems
+}
+
+// Errors returns the number of problems found so far with the error severity.
+func (l *Linter) Errors() int {
+	count := 0
+	for _, problem := range l.problems {
+		if problem.Severity == Error {
+			count++
+		}
+	}
+	return count
+}
+
+// File checks the given specification file. The content can be YAML or JSON, and can contain
+// multiple objects separated by '---'. The kind of each object is taken from its 'kind' field,
+// or else from the name of the file or directory, as written by the 'import cluster' command.
+func (l *Linter) File(file string, data []byte) {
+	l.file = file
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var object map[string]interface{}
+		err := decoder.Decode(&object)
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			l.report(Error, "can't parse file: %v", err)
+			return
+		}
+		if object == nil {
+			continue
+		}
+		l.object(object)
+	}
+}
+
+func (l *Linter) object(object map[string]interface{}) {
+	kind, _ := object["kind"].(string)
+	if kind == "" {
+		kind = guessKind(l.file)
+	}
+	if kind == "" {
+		l.report(
+			Error,
+			"can't determine the kind of object, add a 'kind' field with value '%s', "+
+				"'%s' or '%s'",
+			KindCluster, KindMachinePool, KindIdentityProvider,
+		)
+		return
+	}
+	data, err := json.Marshal(object)
+	if err != nil {
+		l.report(Error, "can't convert object to JSON: %v", err)
+		return
+	}
+	switch kind {
+	case KindCluster:
+		cluster, err := cmv1.UnmarshalCluster(data)
+		if l.model(kind, err) {
+			l.unknown(object, func(writer io.Writer) error {
+				return cmv1.MarshalCluster(cluster, writer)
+			})
+			l.cluster(cluster)
+		}
+	case KindMachinePool:
+		pool, err := cmv1.UnmarshalMachinePool(data)
+		if l.model(kind, err) {
+			l.unknown(object, func(writer io.Writer) error {
+				return cmv1.MarshalMachinePool(pool, writer)
+			})
+			l.machinePool(pool)
+		}
+	case KindIdentityProvider:
+		idp, err := cmv1.UnmarshalIdentityProvider(data)
+		if l.model(kind, err) {
+			l.unknown(object, func(writer io.Writer) error {
+				return cmv1.MarshalIdentityProvider(idp, writer)
+			})
+			l.identityProvider(idp)
+		}
+	default:
+		l.report(Error, "kind '%s' isn't supported", kind)
+	}
+}
+
+// guessKind returns the kind of object that corresponds to the name of the file, as written by
+// the 'import cluster' command, or an empty string if it can't be determined.
+func guessKind(file string) string {
+	switch filepath.Base(filepath.Dir(file)) {
+	case "machine_pools":
+		return KindMachinePool
+	case "identity_providers":
+		return KindIdentityProvider
+	}
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	if name == "cluster" {
+		return KindCluster
+	}
+	return ""
+}
+
+// model reports the error returned when the object is converted to the SDK model, and returns
+// true if there was no such error.
+func (l *Linter) model(kind string, err error) bool {
+	if err != nil {
+		l.report(Error, "object doesn't match the '%s' type: %v", kind, err)
+		return false
+	}
+	return true
+}
+
+// unknown reports the fields of the given object that are ignored when it is converted to the
+// SDK model, because they don't exist in the model. It does that using the given function to
+// convert the model back to JSON and comparing the result with the original object.
+func (l *Linter) unknown(object map[string]interface{}, marshal func(io.Writer) error) {
+	buffer := &bytes.Buffer{}
+	err := marshal(buffer)
+	if err != nil {
+		return
+	}
+	var known interface{}
+	err = json.Unmarshal(buffer.Bytes(), &known)
+	if err != nil {
+		return
+	}
+	fields := []string{}
+	compare("", object, known, &fields)
+	sort.Strings(fields)
+	for _, field := range fields {
+		l.report(Error, "unknown field '%s'", field)
+	}
+}
+
+// compare adds to the list the paths of the fields of the original value that don't exist in the
+// known value.
+func compare(prefix string, original, known interface{}, fields *[]string) {
+	switch typed := original.(type) {
+	case map[string]interface{}:
+		knownMap, _ := known.(map[string]interface{})
+		for name, value := range typed {
+			if value == nil {
+				continue
+			}
+			path := name
+			if prefix != "" {
+				path = prefix + "." + name
+			}
+			knownValue, ok := knownMap[name]
+			if !ok {
+				*fields = append(*fields, path)
+				continue
+			}
+			compare(path, value, knownValue, fields)
+		}
+	case []interface{}:
+		knownList, _ := known.([]interface{})
+		for i, value := range typed {
+			if i < len(knownList) {
+				compare(fmt.Sprintf("%s[%d]", prefix, i), value, knownList[i], fields)
+			}
+		}
+	}
+}
+
+func (l *Linter) cluster(cluster *cmv1.Cluster) {
+	if cluster.Name() == "" {
+		l.report(Error, "field 'name' is required")
+	}
+	if cluster.Region().ID() == "" {
+		l.report(Error, "field 'region.id' is required")
+	}
+
+	// Check the network:
+	cidrs := []struct {
+		field string
+		value string
+	}{
+		{"network.machine_cidr", cluster.Network().MachineCIDR()},
+		{"network.service_cidr", cluster.Network().ServiceCIDR()},
+		{"network.pod_cidr", cluster.Network().PodCIDR()},
+	}
+	ranges := make([]c.NetworkRange, len(cidrs))
+	for i, cidr := range cidrs {
+		ranges[i].Name = fmt.Sprintf("field '%s'", cidr.field)
+		if cidr.value == "" {
+			continue
+		}
+		_, parsed, err := net.ParseCIDR(cidr.value)
+		if err != nil {
+			l.report(Error, "value '%s' of field '%s' isn't a valid CIDR", cidr.value, cidr.field)
+			continue
+		}
+		ranges[i].CIDR = parsed
+	}
+	for _, conflict := range c.NetworkConflicts(ranges, cluster.Network().Type()) {
+		l.report(Error, "%s", conflict)
+	}
+	hostPrefix, ok := cluster.Network().GetHostPrefix()
+	if ok && (hostPrefix < 23 || hostPrefix > 26) {
+		l.report(Error, "value %d of field 'network.host_prefix' should be between 23 and 26",
+			hostPrefix)
+	}
+
+	// Check the availability zones and the number of nodes:
+	zones := cluster.Nodes().AvailabilityZones()
+	if cluster.MultiAZ() {
+		if len(zones) != 0 && len(zones) != 3 {
+			l.report(
+				Error, "multi-AZ clusters need 3 availability zones, but %d are given",
+				len(zones),
+			)
+		}
+		compute, ok := cluster.Nodes().GetCompute()
+		if ok && compute%3 != 0 {
+			l.report(
+				Error, "number of compute nodes of multi-AZ clusters should be a "+
+					"multiple of 3, but it is %d",
+				compute,
+			)
+		}
+	} else if len(zones) > 1 {
+		l.report(
+			Error, "single AZ clusters need 1 availability zone, but %d are given, "+
+				"set 'multi_az' to 'true' to use multiple zones",
+			len(zones),
+		)
+	}
+	autoscaling, ok := cluster.Nodes().GetAutoscaleCompute()
+	if ok {
+		if _, ok := cluster.Nodes().GetCompute(); ok {
+			l.report(Error, "fields 'nodes.compute' and 'nodes.autoscale_compute' can't "+
+				"be used together")
+		}
+		l.autoscaling("nodes.autoscale_compute", autoscaling)
+	}
+
+	// Check the version:
+	version := cluster.Version().ID()
+	if l.versions != nil && version != "" && !l.versions[c.DropOpenshiftVPrefix(version)] {
+		l.report(Error, "version '%s' isn't available", version)
+	}
+	if version == "" {
+		l.report(Warning, "field 'version.id' isn't set, the default version will be used")
+	}
+}
+
+func (l *Linter) machinePool(pool *cmv1.MachinePool) {
+	if pool.ID() == "" {
+		l.report(Error, "field 'id' is required")
+	}
+	if pool.InstanceType() == "" {
+		l.report(Error, "field 'instance_type' is required")
+	}
+	_, hasReplicas := pool.GetReplicas()
+	autoscaling, hasAutoscaling := pool.GetAutoscaling()
+	switch {
+	case hasReplicas && hasAutoscaling:
+		l.report(Error, "fields 'replicas' and 'autoscaling' can't be used together")
+	case !hasReplicas && !hasAutoscaling:
+		l.report(Error, "one of fields 'replicas' or 'autoscaling' is required")
+	case hasAutoscaling:
+		l.autoscaling("autoscaling", autoscaling)
+	}
+	for i, taint := range pool.Taints() {
+		if taint.Key() == "" {
+			l.report(Error, "field 'taints[%d].key' is required", i)
+		}
+		switch taint.Effect() {
+		case "NoSchedule", "PreferNoSchedule", "NoExecute":
+		default:
+			l.report(
+				Error, "value '%s' of field 'taints[%d].effect' should be 'NoSchedule', "+
+					"'PreferNoSchedule' or 'NoExecute'",
+				taint.Effect(), i,
+			)
+		}
+	}
+}
+
+func (l *Linter) autoscaling(field string, autoscaling *cmv1.MachinePoolAutoscaling) {
+	min := autoscaling.MinReplicas()
+	max := autoscaling.MaxReplicas()
+	if min > max {
+		l.report(
+			Error, "value %d of field '%s.min_replicas' is greater than value %d of "+
+				"field '%s.max_replicas'",
+			min, field, max, field,
+		)
+	}
+}
+
+// idpFields contains the names of the fields that contain the settings of each type of identity
+// provider.
+var idpFields = map[cmv1.IdentityProviderType]string{
+	cmv1.IdentityProviderTypeGithub:   "github",
+	cmv1.IdentityProviderTypeGitlab:   "gitlab",
+	cmv1.IdentityProviderTypeGoogle:   "google",
+	cmv1.IdentityProviderTypeHtpasswd: "htpasswd",
+	cmv1.IdentityProviderTypeLDAP:     "ldap",
+	cmv1.IdentityProviderTypeOpenID:   "open_id",
+}
+
+func (l *Linter) identityProvider(idp *cmv1.IdentityProvider) {
+	if idp.Name() == "" {
+		l.report(Error, "field 'name' is required")
+	}
+	field, ok := idpFields[idp.Type()]
+	if !ok {
+		types := make([]string, 0, len(idpFields))
+		for typ := range idpFields {
+			types = append(types, string(typ))
+		}
+		sort.Strings(types)
+		l.report(
+			Error, "value '%s' of field 'type' should be one of '%s'",
+			idp.Type(), strings.Join(types, "', '"),
+		)
+		return
+	}
+	present := map[string]bool{}
+	_, present["github"] = idp.GetGithub()
+	_, present["gitlab"] = idp.GetGitlab()
+	_, present["google"] = idp.GetGoogle()
+	_, present["htpasswd"] = idp.GetHtpasswd()
+	_, present["ldap"] = idp.GetLDAP()
+	_, present["open_id"] = idp.GetOpenID()
+	if !present[field] {
+		l.report(Error, "field '%s' is required for identity providers of type '%s'",
+			field, idp.Type())
+	}
+	others := make([]string, 0, len(idpFields))
+	for _, other := range idpFields {
+		others = append(others, other)
+	}
+	sort.Strings(others)
+	for _, other := range others {
+		if other != field && present[other] {
+			l.report(Warning, "field '%s' is ignored for identity providers of type '%s'",
+				other, idp.Type())
+		}
+	}
+	switch idp.MappingMethod() {
+	case "", cmv1.IdentityProviderMappingMethodAdd, cmv1.IdentityProviderMappingMethodClaim,
+		cmv1.IdentityProviderMappingMethodGenerate, cmv1.IdentityProviderMappingMethodLookup:
+	default:
+		l.report(
+			Error, "value '%s' of field 'mapping_method' should be 'add', 'claim', "+
+				"'generate' or 'lookup'",
+			idp.MappingMethod(),
+		)
+	}
+}
+
+func (l *Linter) report(severity Severity, format string, args ...interface{}) {
+	l.problems = append(l.problems, &Problem{
+		File:     l.file,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Linter", func() {
+	messages := func(linter *Linter) []string {
+		result := []string{}
+		for _, problem := range linter.Problems() {
+			result = append(result, problem.String())
+		}
+		return result
+	}
+
+	It("Accepts a valid cluster", func() {
+		linter := NewLinter().Versions([]string{"4.10.3"})
+		linter.File("cluster.yaml", []byte(`
+kind: Cluster
+name: mycluster
+region:
+  id: us-east-1
+version:
+  id: openshift-v4.10.3
+multi_az: true
+nodes:
+  compute: 6
+  availability_zones:
+  - us-east-1a
+  - us-east-1b
+  - us-east-1c
+network:
+  machine_cidr: 10.0.0.0/16
+  service_cidr: 172.30.0.0/16
+  pod_cidr: 10.128.0.0/14
+  host_prefix: 23
+`))
+		Expect(messages(linter)).To(BeEmpty())
+		Expect(linter.Errors()).To(BeZero())
+	})
+
+	It("Reports problems of clusters", func() {
+		linter := NewLinter().Versions([]string{"4.10.3"})
+		linter.File("cluster.yaml", []byte(`
+name: mycluster
+region:
+  id: us-east-1
+version:
+  id: openshift-v4.9.0
+multi_az: true
+nodes:
+  compute: 4
+  availability_zones:
+  - us-east-1a
+  - us-east-1b
+network:
+  machine_cidr: 10.0.0.0/16
+  service_cidr: 172.30.0.0/16
+  pod_cidr: 10.0.128.0/17
+  host_prefix: 28
+  color: blue
+`))
+		Expect(messages(linter)).To(ConsistOf(
+			"cluster.yaml: error: unknown field 'network.color'",
+			"cluster.yaml: error: CIDR '10.0.0.0/16' of field 'network.machine_cidr' "+
+				"overlaps with CIDR '10.0.128.0/17' of field 'network.pod_cidr'",
+			"cluster.yaml: error: value 28 of field 'network.host_prefix' should be "+
+				"between 23 and 26",
+			"cluster.yaml: error: multi-AZ clusters need 3 availability zones, but 2 "+
+				"are given",
+			"cluster.yaml: error: number of compute nodes of multi-AZ clusters should "+
+				"be a multiple of 3, but it is 4",
+			"cluster.yaml: error: version 'openshift-v4.9.0' isn't available",
+		))
+	})
+
+	It("Reports values with the wrong type", func() {
+		linter := NewLinter()
+		linter.File("cluster.yaml", []byte("name: mycluster\nmulti_az: yes please\n"))
+		Expect(linter.Errors()).To(Equal(1))
+		Expect(linter.Problems()[0].Message).To(HavePrefix(
+			"object doesn't match the 'Cluster' type",
+		))
+	})
+
+	It("Reports problems of machine pools", func() {
+		linter := NewLinter()
+		linter.File("machine_pools/gpu.yaml", []byte(`
+id: gpu
+instance_type: g4dn.xlarge
+autoscaling:
+  min_replicas: 4
+  max_replicas: 2
+taints:
+- key: nvidia.com/gpu
+  effect: NoScheduling
+`))
+		Expect(messages(linter)).To(ConsistOf(
+			"machine_pools/gpu.yaml: error: value 4 of field 'autoscaling.min_replicas' "+
+				"is greater than value 2 of field 'autoscaling.max_replicas'",
+			"machine_pools/gpu.yaml: error: value 'NoScheduling' of field "+
+				"'taints[0].effect' should be 'NoSchedule', 'PreferNoSchedule' or "+
+				"'NoExecute'",
+		))
+	})
+
+	It("Reports problems of identity providers", func() {
+		linter := NewLinter()
+		linter.File("idps.yaml", []byte(`
+kind: IdentityProvider
+name: github
+type: GithubIdentityProvider
+mapping_method: merge
+gitlab:
+  url: https://gitlab.com
+---
+kind: IdentityProvider
+name: google
+type: GoogleIdentityProvider
+google:
+  client_id: my-client
+`))
+		Expect(messages(linter)).To(ConsistOf(
+			"idps.yaml: error: field 'github' is required for identity providers of "+
+				"type 'GithubIdentityProvider'",
+			"idps.yaml: warning: field 'gitlab' is ignored for identity providers of "+
+				"type 'GithubIdentityProvider'",
+			"idps.yaml: error: value 'merge' of field 'mapping_method' should be 'add', "+
+				"'claim', 'generate' or 'lookup'",
+		))
+	})
+
+	It("Reports objects of unknown kind", func() {
+		linter := NewLinter()
+		linter.File("other.yaml", []byte("name: something\n"))
+		Expect(linter.Errors()).To(Equal(1))
+		Expect(linter.Problems()[0].Message).To(HavePrefix(
+			"can't determine the kind of object",
+		))
+	})
+})
@@ -0,0 +1,245 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to write API objects as YAML manifests, preserving the
+// comments that users add to them when the manifests are written again.
+
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Change indicates what happened to a manifest file when it was written.
+type Change string
+
+const (
+	Created   Change = "created"
+	Updated   Change = "updated"
+	Unchanged Change = "unchanged"
+)
+
+// volatileFields contains the names of the top level fields that change without user
+// intervention, and that are removed from manifests so that they don't change every time that
+// they are written.
+var volatileFields = map[string]bool{
+	"activity_timestamp": true,
+	"health_state":       true,
+	"status":             true,
+}
+
+// Parse parses the given JSON document, as returned by the API, and converts it into a YAML node,
+// preserving the order of the fields. Links to other objects ('href' fields) and fields that
+// change without user intervention are removed.
+func Parse(data []byte) (result *yaml.Node, err error) {
+	document := &yaml.Node{}
+	err = yaml.Unmarshal(data, document)
+	if err != nil {
+		return
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) != 1 {
+		err = errors.New("expected exactly one object")
+		return
+	}
+	result = document.Content[0]
+	if result.Kind != yaml.MappingNode {
+		err = errors.New("expected an object")
+		return
+	}
+	dropVolatile(result)
+	clean(result)
+	return
+}
+
+// Items parses the given JSON list, as returned by the API, and returns the items converted into
+// YAML nodes with the same transformations applied by the Parse function.
+func Items(data []byte) (result []*yaml.Node, err error) {
+	list, err := Parse(data)
+	if err != nil {
+		return
+	}
+	items := Field(list, "items")
+	if items == nil {
+		return
+	}
+	if items.Kind != yaml.SequenceNode {
+		err = errors.New("expected 'items' to be a list")
+		return
+	}
+	for _, item := range items.Content {
+		if item.Kind != yaml.MappingNode {
+			err = errors.New("expected items to be objects")
+			return
+		}
+		dropVolatile(item)
+		result = append(result, item)
+	}
+	return
+}
+
+// Field returns the value of the given field of an object, or nil if there is no such field.
+func Field(node *yaml.Node, name string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == name {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// dropVolatile removes from the given object the fields that change without user intervention.
+func dropVolatile(node *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); {
+		if volatileFields[node.Content[i].Value] {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			continue
+		}
+		i += 2
+	}
+}
+
+// clean removes the 'href' fields and the styles that the JSON syntax sets, so that the node is
+// written using the block syntax of YAML.
+func clean(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); {
+			if node.Content[i].Value == "href" {
+				node.Content = append(node.Content[:i], node.Content[i+2:]...)
+				continue
+			}
+			i += 2
+		}
+	}
+	for _, child := range node.Content {
+		clean(child)
+	}
+}
+
+// Write writes the given object to the given file, creating the directories if needed. If the
+// file already exists the comments that it contains are copied to the fields that still exist.
+func Write(path string, object *yaml.Node) (change Change, err error) {
+	document := &yaml.Node{
+		Kind:    yaml.DocumentNode,
+		Content: []*yaml.Node{object},
+	}
+	change = Created
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		change = Updated
+		old := &yaml.Node{}
+		err = yaml.Unmarshal(existing, old)
+		if err != nil {
+			err = fmt.Errorf("can't parse existing manifest '%s': %v", path, err)
+			return
+		}
+		mergeComments(old, document)
+	case errors.Is(err, os.ErrNotExist):
+		err = nil
+	default:
+		return
+	}
+	buffer := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+	err = encoder.Encode(document)
+	if err != nil {
+		return
+	}
+	err = encoder.Close()
+	if err != nil {
+		return
+	}
+	if change == Updated && bytes.Equal(existing, buffer.Bytes()) {
+		change = Unchanged
+		return
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return
+	}
+	err = os.WriteFile(path, buffer.Bytes(), 0644)
+	return
+}
+
+// mergeComments copies the comments from the old node to the new one, recursively. Fields of
+// objects are matched by name, and items of lists are matched by the value of their 'id' field
+// when they have one, or else by position.
+func mergeComments(old, new *yaml.Node) {
+	if new.HeadComment == "" {
+		new.HeadComment = old.HeadComment
+	}
+	if new.LineComment == "" {
+		new.LineComment = old.LineComment
+	}
+	if new.FootComment == "" {
+		new.FootComment = old.FootComment
+	}
+	if old.Kind != new.Kind {
+		return
+	}
+	switch new.Kind {
+	case yaml.DocumentNode:
+		if len(old.Content) > 0 && len(new.Content) > 0 {
+			mergeComments(old.Content[0], new.Content[0])
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(new.Content); i += 2 {
+			for j := 0; j+1 < len(old.Content); j += 2 {
+				if old.Content[j].Value == new.Content[i].Value {
+					mergeComments(old.Content[j], new.Content[i])
+					mergeComments(old.Content[j+1], new.Content[i+1])
+					break
+				}
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range new.Content {
+			match := matchItem(old.Content, item, i)
+			if match != nil {
+				mergeComments(match, item)
+			}
+		}
+	}
+}
+
+// matchItem finds the item of the old list that corresponds to the given item of the new list.
+func matchItem(items []*yaml.Node, item *yaml.Node, index int) *yaml.Node {
+	id := Field(item, "id")
+	if id != nil {
+		for _, candidate := range items {
+			other := Field(candidate, "id")
+			if other != nil && other.Value == id.Value {
+				return candidate
+			}
+		}
+		return nil
+	}
+	if index < len(items) {
+		return items[index]
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Manifest", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "manifest-*")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := os.RemoveAll(dir)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Removes links and volatile fields preserving the order", func() {
+		object, err := Parse([]byte(`{
+			"kind": "Cluster",
+			"id": "123",
+			"href": "/api/clusters_mgmt/v1/clusters/123",
+			"name": "my-cluster",
+			"status": {
+				"state": "ready"
+			},
+			"region": {
+				"kind": "CloudRegionLink",
+				"id": "us-east-1",
+				"href": "/api/clusters_mgmt/v1/cloud_providers/aws/regions/us-east-1"
+			},
+			"properties": {
+				"enabled": "true"
+			}
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+		path := filepath.Join(dir, "cluster.yaml")
+		change, err := Write(path, object)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(change).To(Equal(Created))
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(`kind: Cluster
+id: "123"
+name: my-cluster
+region:
+  kind: CloudRegionLink
+  id: us-east-1
+properties:
+  enabled: "true"
+`))
+	})
+
+	It("Preserves comments when writing again", func() {
+		path := filepath.Join(dir, "machine_pools", "infra.yaml")
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		Expect(err).ToNot(HaveOccurred())
+		err = os.WriteFile(path, []byte(`# Machines for the router and the registry.
+id: infra
+replicas: 2 # Increase before the migration.
+taints:
+  # Keep the regular workloads away.
+  - key: infra
+    effect: NoSchedule
+`), 0644)
+		Expect(err).ToNot(HaveOccurred())
+		object, err := Parse([]byte(`{
+			"id": "infra",
+			"replicas": 3,
+			"taints": [
+				{
+					"key": "infra",
+					"effect": "NoSchedule"
+				}
+			]
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+		change, err := Write(path, object)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(change).To(Equal(Updated))
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(`# Machines for the router and the registry.
+id: infra
+replicas: 3 # Increase before the migration.
+taints:
+  # Keep the regular workloads away.
+  - key: infra
+    effect: NoSchedule
+`))
+
+		// Writing the same object again shouldn't change the file:
+		change, err = Write(path, object)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(change).To(Equal(Unchanged))
+	})
+
+	It("Extracts the items of a list", func() {
+		items, err := Items([]byte(`{
+			"kind": "MachinePoolList",
+			"items": [
+				{
+					"id": "a",
+					"href": "/a",
+					"status": {}
+				},
+				{
+					"id": "b"
+				}
+			]
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(items).To(HaveLen(2))
+		Expect(Field(items[0], "id").Value).To(Equal("a"))
+		Expect(Field(items[0], "href")).To(BeNil())
+		Expect(Field(items[0], "status")).To(BeNil())
+		Expect(Field(items[1], "id").Value).To(Equal("b"))
+	})
+})
@@ -19,12 +19,14 @@ import (
 	sdk "github.com/openshift-online/ocm-sdk-go"
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
 )
 
 // ConnectionBuilder contains the information and logic needed to build a connection to OCM. Don't
 // create instances of this type directly; use the NewConnection function instead.
 type ConnectionBuilder struct {
-	cfg *config.Config
+	cfg          *config.Config
+	alternatives map[string]string
 }
 
 // NewConnection creates a builder that can then be used to configure and build an OCM connection.
@@ -39,6 +41,16 @@ func (b *ConnectionBuilder) Config(value *config.Config) *ConnectionBuilder {
 	return b
 }
 
+// AlternativeURL sets an alternative base URL for the requests whose path starts with the given
+// prefix, for example for the APIs that aren't served by the OCM API server.
+func (b *ConnectionBuilder) AlternativeURL(prefix, base string) *ConnectionBuilder {
+	if b.alternatives == nil {
+		b.alternatives = map[string]string{}
+	}
+	b.alternatives[prefix] = base
+	return b
+}
+
 // Build uses the information stored in the builder to create a new OCM connection.
 func (b *ConnectionBuilder) Build() (result *sdk.Connection, err error) {
 	if b.cfg == nil {
@@ -47,23 +59,42 @@ func (b *ConnectionBuilder) Build() (result *sdk.Connection, err error) {
 		if err != nil {
 			return
 		}
+		if b.cfg == nil && dryrun.Enabled() {
+			b.cfg = &config.Config{}
+		}
 		if b.cfg == nil {
 			err = fmt.Errorf("Not logged in, run the 'login' command")
 			return
 		}
 	}
 
-	// Check that the configuration has credentials or tokens that haven't have expired:
+	// Check that the configuration has credentials or tokens that haven't have expired. This
+	// isn't needed when the command is only being validated, as nothing will be sent:
 	armed, reason, err := b.cfg.Armed()
 	if err != nil {
 		return
 	}
-	if !armed {
+	if !armed && !dryrun.Enabled() {
+		// Give the user the chance to provide a new token instead of aborting the
+		// operation:
+		armed, err = b.cfg.Reauthenticate(reason)
+		if err != nil {
+			return
+		}
+	}
+	if !armed && !dryrun.Enabled() {
 		err = fmt.Errorf("Not logged in, %s, run the 'login' command", reason)
 		return
 	}
 
-	result, err = b.cfg.Connection()
+	builder, err := b.cfg.ConnectionBuilder()
+	if err != nil {
+		return
+	}
+	if b.alternatives != nil {
+		builder.AlternativeURLs(b.alternatives)
+	}
+	result, err = builder.Build()
 	if err != nil {
 		return
 	}
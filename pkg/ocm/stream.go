@@ -0,0 +1,246 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	sdkerrors "github.com/openshift-online/ocm-sdk-go/errors"
+
+	"github.com/openshift-online/ocm-cli/pkg/dryrun"
+)
+
+// Event is a message received from a streaming endpoint.
+type Event struct {
+	// Type is the value of the 'event' field of server sent events. It is always empty for
+	// streams of JSON documents.
+	Type string
+
+	// ID is the value of the 'id' field of server sent events. It is always empty for streams
+	// of JSON documents.
+	ID string
+
+	// Data contains the data of the event, usually a JSON document.
+	Data []byte
+}
+
+// ErrStreamingUnsupported is returned by the Stream function when the server doesn't support
+// streaming for the requested endpoint, so that callers can fall back to polling.
+var ErrStreamingUnsupported = errors.New("Server doesn't support streaming for this endpoint")
+
+// Content types of the streams:
+const (
+	eventStreamContentType = "text/event-stream"
+	jsonStreamContentType  = "application/x-ndjson"
+)
+
+// Stream sends a GET request to the given path asking the server for a stream of events, and calls
+// the handler for each event received, till the server closes the stream, the context is done or
+// the handler returns an error. Both server sent events and newline delimited JSON documents are
+// supported.
+//
+// The SDK reads complete response bodies before returning them, so the request is sent with a
+// separate HTTP client that uses the same URL, token and TLS settings than the connection.
+func Stream(ctx context.Context, connection *sdk.Connection, path string, query url.Values,
+	handler func(event *Event) error) error {
+	// Get a valid access token:
+	accessToken, _, err := connection.TokensContext(ctx)
+	if err != nil {
+		return fmt.Errorf("Can't get access token: %v", err)
+	}
+
+	// Prepare the request:
+	address, err := streamURL(connection, path, query)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", eventStreamContentType+", "+jsonStreamContentType)
+	request.Header.Set("Cache-Control", "no-cache")
+	if accessToken != "" {
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	if connection.Agent() != "" {
+		request.Header.Set("User-Agent", connection.Agent())
+	}
+	if dryrun.Enabled() {
+		return dryrun.Intercept(request)
+	}
+
+	// Send the request:
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// #nosec G402
+	transport.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: connection.Insecure(),
+		RootCAs:            connection.TrustedCAs(),
+	}
+	client := &http.Client{Transport: transport}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	// Servers that don't know the endpoint, or that only know how to return complete
+	// responses, are reported so that the caller can fall back to polling:
+	switch response.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotAcceptable,
+		http.StatusNotImplemented:
+		return ErrStreamingUnsupported
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		body, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return err
+		}
+		apiErr, err := sdkerrors.UnmarshalErrorStatus(body, response.StatusCode)
+		if err != nil {
+			return fmt.Errorf("Stream request failed with status %d", response.StatusCode)
+		}
+		return apiErr
+	}
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	switch mediaType {
+	case eventStreamContentType:
+		return readEvents(response.Body, handler)
+	case jsonStreamContentType:
+		return readDocuments(response.Body, handler)
+	}
+
+	// Any other content type means that the server ignored the request for a stream and
+	// returned a regular response:
+	return ErrStreamingUnsupported
+}
+
+// streamURL calculates the complete URL for the given path, taking into account the alternative
+// URLs of the connection.
+func streamURL(connection *sdk.Connection, path string, query url.Values) (result string,
+	err error) {
+	base := connection.URL()
+	prefix := ""
+	for alternativePrefix, alternativeBase := range connection.AlternativeURLs() {
+		if strings.HasPrefix(path, alternativePrefix) && len(alternativePrefix) > len(prefix) {
+			prefix = alternativePrefix
+			base = alternativeBase
+		}
+	}
+	parsed, err := url.Parse(strings.TrimSuffix(base, "/") + path)
+	if err != nil {
+		err = fmt.Errorf("Can't parse URL for path '%s': %v", path, err)
+		return
+	}
+	if len(query) > 0 {
+		parsed.RawQuery = query.Encode()
+	}
+	result = parsed.String()
+	return
+}
+
+// readEvents reads server sent events from the given reader and calls the handler for each of
+// them. Comments, used by servers to keep the connection alive, are ignored.
+func readEvents(reader io.Reader, handler func(event *Event) error) error {
+	scanner := newStreamScanner(reader)
+	event := &Event{}
+	data := &bytes.Buffer{}
+	dispatch := func() error {
+		if data.Len() == 0 {
+			event.Type = ""
+			return nil
+		}
+		event.Data = bytes.TrimSuffix(data.Bytes(), []byte("\n"))
+		err := handler(event)
+		event = &Event{ID: event.ID}
+		data = &bytes.Buffer{}
+		return err
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			err := dispatch()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field := line
+		value := ""
+		colon := strings.Index(line, ":")
+		if colon >= 0 {
+			field = line[0:colon]
+			value = strings.TrimPrefix(line[colon+1:], " ")
+		}
+		switch field {
+		case "event":
+			event.Type = value
+		case "id":
+			event.ID = value
+		case "data":
+			data.WriteString(value)
+			data.WriteString("\n")
+		}
+	}
+	err := scanner.Err()
+	if err != nil {
+		return err
+	}
+	return dispatch()
+}
+
+// readDocuments reads JSON documents, one per line, from the given reader and calls the handler
+// for each of them.
+func readDocuments(reader io.Reader, handler func(event *Event) error) error {
+	scanner := newStreamScanner(reader)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		data := make([]byte, len(line))
+		copy(data, line)
+		err := handler(&Event{Data: data})
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// newStreamScanner creates a line scanner with a buffer large enough for events that contain
+// complete objects.
+func newStreamScanner(reader io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	return scanner
+}
@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Stream", func() {
+	It("Reads server sent events", func() {
+		var events []*Event
+		err := readEvents(
+			strings.NewReader(
+				": keep alive\n\n"+
+					"event: created\n"+
+					"id: 1\n"+
+					"data: {\"id\":\n"+
+					"data:\"123\"}\n"+
+					"\n"+
+					"data: second\n",
+			),
+			func(event *Event) error {
+				events = append(events, event)
+				return nil
+			},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Type).To(Equal("created"))
+		Expect(events[0].ID).To(Equal("1"))
+		Expect(string(events[0].Data)).To(Equal("{\"id\":\n\"123\"}"))
+		Expect(events[1].Type).To(BeEmpty())
+		Expect(events[1].ID).To(Equal("1"))
+		Expect(string(events[1].Data)).To(Equal("second"))
+	})
+
+	It("Reads JSON documents", func() {
+		var events []*Event
+		err := readDocuments(
+			strings.NewReader("{\"id\":\"123\"}\n\n{\"id\":\"456\"}"),
+			func(event *Event) error {
+				events = append(events, event)
+				return nil
+			},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(string(events[0].Data)).To(Equal(`{"id":"123"}`))
+		Expect(string(events[1].Data)).To(Equal(`{"id":"456"}`))
+	})
+
+	It("Stops when the handler fails", func() {
+		count := 0
+		err := readEvents(
+			strings.NewReader("data: first\n\ndata: second\n\n"),
+			func(event *Event) error {
+				count++
+				return errors.New("boom")
+			},
+		)
+		Expect(err).To(MatchError("boom"))
+		Expect(count).To(Equal(1))
+	})
+})
@@ -0,0 +1,317 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to validate request bodies against the OpenAPI
+// specifications published by the services.
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxDepth is the maximum number of references that are followed while validating a value, to
+// avoid infinite loops with recursive schemas.
+const maxDepth = 64
+
+// Spec is an OpenAPI specification.
+type Spec struct {
+	doc map[string]interface{}
+}
+
+// SpecPath returns the path of the OpenAPI specification of the service that serves the given
+// path. For example, for '/api/clusters_mgmt/v1/clusters' it returns
+// '/api/clusters_mgmt/v1/openapi'.
+func SpecPath(path string) (result string, err error) {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(segments) < 3 || segments[0] != "api" {
+		err = fmt.Errorf(
+			"path '%s' doesn't belong to a service, it should start with "+
+				"'/api/SERVICE/VERSION'",
+			path,
+		)
+		return
+	}
+	result = fmt.Sprintf("/api/%s/%s/openapi", segments[1], segments[2])
+	return
+}
+
+// Parse parses an OpenAPI specification.
+func Parse(data []byte) (result *Spec, err error) {
+	doc := map[string]interface{}{}
+	err = json.Unmarshal(data, &doc)
+	if err != nil {
+		err = fmt.Errorf("can't parse OpenAPI specification: %v", err)
+		return
+	}
+	result = &Spec{
+		doc: doc,
+	}
+	return
+}
+
+// RequestSchema returns the schema of the JSON body of the requests sent with the given method
+// to the given path. The boolean result will be false if the specification doesn't contain that
+// operation or if the operation doesn't have a JSON body.
+func (s *Spec) RequestSchema(method, path string) (schema map[string]interface{}, ok bool) {
+	paths, _ := s.doc["paths"].(map[string]interface{})
+	keys := make([]string, 0, len(paths))
+	for key := range paths {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	path = strings.TrimSuffix(path, "/")
+	for _, key := range keys {
+		if !templateRE(key).MatchString(path) {
+			continue
+		}
+		item, _ := paths[key].(map[string]interface{})
+		operation, _ := item[strings.ToLower(method)].(map[string]interface{})
+		body, _ := s.resolve(operation["requestBody"])
+		content, _ := body["content"].(map[string]interface{})
+		media, _ := content["application/json"].(map[string]interface{})
+		schema, ok = media["schema"].(map[string]interface{})
+		if ok {
+			return
+		}
+	}
+	return
+}
+
+// templateRE converts a path template of the specification, like
+// '/api/clusters_mgmt/v1/clusters/{cluster_id}', into a regular expression. Specifications may
+// contain the complete paths or only the part after the version, so both are accepted.
+func templateRE(template string) *regexp.Regexp {
+	parts := strings.Split(strings.TrimSuffix(template, "/"), "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			parts[i] = `[^/]+`
+		} else {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+	}
+	return regexp.MustCompile(`^(/api/[^/]+/[^/]+)?` + strings.Join(parts, "/") + `$`)
+}
+
+// resolve follows the reference contained in the given schema, if any.
+func (s *Spec) resolve(value interface{}) (result map[string]interface{}, ok bool) {
+	result, ok = value.(map[string]interface{})
+	for depth := 0; ok && depth < maxDepth; depth++ {
+		ref, isRef := result["$ref"].(string)
+		if !isRef {
+			return
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			ok = false
+			return
+		}
+		var current interface{} = s.doc
+		for _, name := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			name = strings.ReplaceAll(strings.ReplaceAll(name, "~1", "/"), "~0", "~")
+			object, _ := current.(map[string]interface{})
+			current = object[name]
+		}
+		result, ok = current.(map[string]interface{})
+	}
+	return
+}
+
+// Validate checks the given JSON document against the given schema, and returns the list of
+// problems found, each one starting with the JSON path of the value that has the problem.
+func (s *Spec) Validate(schema map[string]interface{}, data []byte) (problems []string, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	err = decoder.Decode(&value)
+	if err != nil {
+		err = fmt.Errorf("body isn't valid JSON: %v", err)
+		return
+	}
+	s.validate("$", value, schema, 0, &problems)
+	return
+}
+
+func (s *Spec) validate(path string, value interface{}, schema map[string]interface{}, depth int,
+	problems *[]string) {
+	if depth > maxDepth {
+		return
+	}
+	schema, ok := s.resolve(schema)
+	if !ok {
+		return
+	}
+	report := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	// Null values are accepted for all types, as the server treats them as missing values:
+	if value == nil {
+		return
+	}
+
+	// Check all the schemas when they are combined:
+	allOf, _ := schema["allOf"].([]interface{})
+	for _, item := range allOf {
+		nested, _ := item.(map[string]interface{})
+		s.validate(path, value, nested, depth+1, problems)
+	}
+
+	// Check the type:
+	typ, _ := schema["type"].(string)
+	if typ == "" {
+		_, hasProperties := schema["properties"]
+		if hasProperties {
+			typ = "object"
+		}
+	}
+	if typ != "" && !hasType(value, typ) {
+		report("expected %s but got %s", article(typ), article(typeOf(value)))
+		return
+	}
+
+	// Check the allowed values:
+	enum, _ := schema["enum"].([]interface{})
+	if len(enum) > 0 {
+		found := false
+		allowed := make([]string, len(enum))
+		for i, item := range enum {
+			allowed[i] = fmt.Sprintf("%v", item)
+			if allowed[i] == fmt.Sprintf("%v", value) {
+				found = true
+			}
+		}
+		if !found {
+			report(
+				"value '%v' isn't allowed, valid values are '%s'",
+				value, strings.Join(allowed, "', '"),
+			)
+		}
+	}
+
+	// Check the formats:
+	format, _ := schema["format"].(string)
+	text, isText := value.(string)
+	if isText && format == "date-time" {
+		_, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			report("value '%s' isn't a valid RFC 3339 date and time", text)
+		}
+	}
+
+	// Check the items of arrays:
+	items, isArray := value.([]interface{})
+	if isArray {
+		itemSchema, _ := schema["items"].(map[string]interface{})
+		for i, item := range items {
+			s.validate(fmt.Sprintf("%s[%d]", path, i), item, itemSchema, depth+1, problems)
+		}
+	}
+
+	// Check the fields of objects:
+	object, isObject := value.(map[string]interface{})
+	if isObject {
+		properties, _ := schema["properties"].(map[string]interface{})
+		required, _ := schema["required"].([]interface{})
+		for _, item := range required {
+			name, _ := item.(string)
+			_, present := object[name]
+			if name != "" && !present {
+				report("field '%s' is required", name)
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		additional := schema["additionalProperties"]
+		for _, name := range names {
+			fieldPath := path + "." + name
+			property, known := properties[name].(map[string]interface{})
+			switch {
+			case known:
+				s.validate(fieldPath, object[name], property, depth+1, problems)
+			case additional == true:
+				// Any field is accepted.
+			case additional != nil && additional != false:
+				nested, _ := additional.(map[string]interface{})
+				s.validate(fieldPath, object[name], nested, depth+1, problems)
+			case len(properties) > 0:
+				// The server ignores fields that it doesn't know, so these are usually
+				// typos that are worth reporting:
+				report("unknown field '%s'", name)
+			}
+		}
+	}
+}
+
+// hasType checks if the given value has the given OpenAPI type.
+func hasType(value interface{}, typ string) bool {
+	switch typ {
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := number.Int64()
+		return err == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return typeOf(value) == typ
+	}
+}
+
+// typeOf returns the name of the OpenAPI type of the given value.
+func typeOf(value interface{}) string {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		_, err := typed.Int64()
+		if err == nil {
+			return "integer"
+		}
+		return "number"
+	default:
+		return "null"
+	}
+}
+
+// article adds the right indefinite article to the given type name.
+func article(typ string) string {
+	switch typ {
+	case "array", "object", "integer":
+		return "an " + typ
+	case "null":
+		return typ
+	default:
+		return "a " + typ
+	}
+}
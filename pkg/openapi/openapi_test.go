@@ -0,0 +1,195 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Validation", func() {
+	var spec *Spec
+
+	BeforeEach(func() {
+		var err error
+		spec, err = Parse([]byte(`{
+			"openapi": "3.0.0",
+			"paths": {
+				"/api/clusters_mgmt/v1/clusters": {
+					"post": {
+						"requestBody": {
+							"content": {
+								"application/json": {
+									"schema": {
+										"$ref": "#/components/schemas/Cluster"
+									}
+								}
+							}
+						}
+					}
+				},
+				"/api/clusters_mgmt/v1/clusters/{cluster_id}/machine_pools": {
+					"post": {
+						"requestBody": {
+							"$ref": "#/components/requestBodies/MachinePool"
+						}
+					}
+				}
+			},
+			"components": {
+				"requestBodies": {
+					"MachinePool": {
+						"content": {
+							"application/json": {
+								"schema": {
+									"type": "object",
+									"required": ["id"],
+									"properties": {
+										"id": {
+											"type": "string"
+										},
+										"labels": {
+											"type": "object",
+											"additionalProperties": {
+												"type": "string"
+											}
+										}
+									}
+								}
+							}
+						}
+					}
+				},
+				"schemas": {
+					"Cluster": {
+						"type": "object",
+						"required": ["name"],
+						"properties": {
+							"name": {
+								"type": "string"
+							},
+							"expiration_timestamp": {
+								"type": "string",
+								"format": "date-time"
+							},
+							"nodes": {
+								"$ref": "#/components/schemas/ClusterNodes"
+							},
+							"billing_model": {
+								"type": "string",
+								"enum": ["standard", "marketplace"]
+							},
+							"properties": {
+								"type": "object",
+								"additionalProperties": true
+							}
+						}
+					},
+					"ClusterNodes": {
+						"properties": {
+							"compute": {
+								"type": "integer"
+							},
+							"availability_zones": {
+								"type": "array",
+								"items": {
+									"type": "string"
+								}
+							}
+						}
+					}
+				}
+			}
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Calculates the path of the specification", func() {
+		path, err := SpecPath("/api/clusters_mgmt/v1/clusters/123")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(path).To(Equal("/api/clusters_mgmt/v1/openapi"))
+		_, err = SpecPath("/clusters")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Accepts a valid body", func() {
+		schema, ok := spec.RequestSchema("POST", "/api/clusters_mgmt/v1/clusters")
+		Expect(ok).To(BeTrue())
+		problems, err := spec.Validate(schema, []byte(`{
+			"name": "my-cluster",
+			"expiration_timestamp": "2024-01-01T00:00:00Z",
+			"nodes": {
+				"compute": 3,
+				"availability_zones": ["us-east-1a"]
+			},
+			"billing_model": "standard",
+			"properties": {
+				"anything": true
+			}
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(problems).To(BeEmpty())
+	})
+
+	It("Reports the problems with their paths", func() {
+		schema, ok := spec.RequestSchema("POST", "/api/clusters_mgmt/v1/clusters")
+		Expect(ok).To(BeTrue())
+		problems, err := spec.Validate(schema, []byte(`{
+			"expiration_timestamp": "tomorrow",
+			"nodes": {
+				"compute": "3",
+				"availability_zones": ["us-east-1a", 2]
+			},
+			"billing_model": "free",
+			"regoin": "us-east-1"
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(problems).To(Equal([]string{
+			"$: field 'name' is required",
+			"$.billing_model: value 'free' isn't allowed, valid values are " +
+				"'standard', 'marketplace'",
+			"$.expiration_timestamp: value 'tomorrow' isn't a valid RFC 3339 date and time",
+			"$.nodes.availability_zones[1]: expected a string but got an integer",
+			"$.nodes.compute: expected an integer but got a string",
+			"$: unknown field 'regoin'",
+		}))
+	})
+
+	It("Finds the schema of paths with identifiers", func() {
+		schema, ok := spec.RequestSchema(
+			"POST", "/api/clusters_mgmt/v1/clusters/123/machine_pools",
+		)
+		Expect(ok).To(BeTrue())
+		problems, err := spec.Validate(schema, []byte(`{
+			"labels": {
+				"a": 1
+			}
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(problems).To(Equal([]string{
+			"$: field 'id' is required",
+			"$.labels.a: expected a string but got an integer",
+		}))
+	})
+
+	It("Doesn't find the schema of unknown operations", func() {
+		_, ok := spec.RequestSchema("PATCH", "/api/clusters_mgmt/v1/clusters/123")
+		Expect(ok).To(BeFalse())
+		_, ok = spec.RequestSchema("POST", "/api/clusters_mgmt/v1/versions")
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,269 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the code that writes lists of objects as tables, JSON or YAML.
+
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gitlab.com/c0b/go-ordered-json"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the format used to write lists of objects.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+)
+
+// Formats returns the names of the supported formats.
+func Formats() []string {
+	return []string{
+		string(FormatTable),
+		string(FormatJSON),
+		string(FormatYAML),
+	}
+}
+
+// ParseFormat checks that the given text is the name of a supported format and converts it.
+func ParseFormat(text string) (result Format, err error) {
+	for _, format := range Formats() {
+		if text == format {
+			result = Format(text)
+			return
+		}
+	}
+	err = fmt.Errorf(
+		"Unknown output format '%s', valid values are '%s'",
+		text, strings.Join(Formats(), "', '"),
+	)
+	return
+}
+
+// ListBuilder contains the data and logic needed to create a new list.
+type ListBuilder struct {
+	printer *Printer
+	name    string
+	format  Format
+	columns []string
+	known   []string
+}
+
+// List writes a list of objects in the format selected by the user. In the table format each
+// object is written as soon as it is received. In the JSON and YAML formats the objects are
+// accumulated and written as an array when the list is closed, so that the result is a valid
+// document.
+type List struct {
+	format  Format
+	printer *Printer
+	columns []string
+	table   *Table
+	rows    []*ordered.OrderedMap
+	nodes   []*yaml.Node
+}
+
+// NewList creates a new builder that can then be used to configure and create a list.
+func (p *Printer) NewList() *ListBuilder {
+	return &ListBuilder{
+		printer: p,
+		format:  FormatTable,
+	}
+}
+
+// Name sets the name of the list. This is mandatory. When the format is table it is also used to
+// load the descriptions of the columns, like for tables.
+func (b *ListBuilder) Name(value string) *ListBuilder {
+	b.name = value
+	return b
+}
+
+// Format sets the output format. The default is to write a table.
+func (b *ListBuilder) Format(value Format) *ListBuilder {
+	b.format = value
+	return b
+}
+
+// Columns sets the columns that will be written, as a comma separated list of column names. In
+// the JSON and YAML formats the column names are used as the names of the fields.
+func (b *ListBuilder) Columns(value string) *ListBuilder {
+	b.columns = nil
+	for _, column := range strings.Split(value, ",") {
+		column = strings.TrimSpace(column)
+		if column != "" {
+			b.columns = append(b.columns, column)
+		}
+	}
+	return b
+}
+
+// Known sets the names of the columns that are available. This is optional, and when it is used
+// the list will check that the selected columns are available.
+func (b *ListBuilder) Known(values ...string) *ListBuilder {
+	b.known = values
+	return b
+}
+
+// Build uses the configuration stored in the builder to create a new list. In the table format
+// this also writes the headers.
+func (b *ListBuilder) Build(ctx context.Context) (result *List, err error) {
+	// Check parameters:
+	if b.printer == nil {
+		err = fmt.Errorf("printer is mandatory")
+		return
+	}
+	if b.name == "" {
+		err = fmt.Errorf("name is mandatory")
+		return
+	}
+	if len(b.columns) == 0 {
+		err = fmt.Errorf("at least one column is required")
+		return
+	}
+	if b.known != nil {
+		for _, column := range b.columns {
+			found := false
+			for _, known := range b.known {
+				if column == known {
+					found = true
+					break
+				}
+			}
+			if !found {
+				err = fmt.Errorf(
+					"Unknown column '%s', valid values are '%s'",
+					column, strings.Join(b.known, "', '"),
+				)
+				return
+			}
+		}
+	}
+
+	// Create the list:
+	list := &List{
+		format:  b.format,
+		printer: b.printer,
+		columns: b.columns,
+	}
+	switch b.format {
+	case FormatTable:
+		list.table, err = b.printer.NewTable().
+			Name(b.name).
+			Columns(strings.Join(b.columns, ",")).
+			Build(ctx)
+		if err != nil {
+			return
+		}
+		err = list.table.WriteHeaders()
+		if err != nil {
+			return
+		}
+	case FormatJSON, FormatYAML:
+	default:
+		err = fmt.Errorf("unknown format '%s'", b.format)
+		return
+	}
+
+	result = list
+	return
+}
+
+// Write writes an object. The values of the columns are taken from the given map, using the
+// column name as the key. Columns without a value are written as empty.
+func (l *List) Write(values map[string]interface{}) error {
+	switch l.format {
+	case FormatTable:
+		row := make([]interface{}, len(l.columns))
+		for i, column := range l.columns {
+			value := values[column]
+			switch typed := value.(type) {
+			case nil:
+				value = ""
+			case []string:
+				value = strings.Join(typed, " ")
+			}
+			row[i] = value
+		}
+		return l.table.WriteRow(row)
+	case FormatJSON:
+		row := ordered.NewOrderedMap()
+		for _, column := range l.columns {
+			row.Set(column, values[column])
+		}
+		l.rows = append(l.rows, row)
+	case FormatYAML:
+		node := &yaml.Node{
+			Kind: yaml.MappingNode,
+		}
+		for _, column := range l.columns {
+			value := &yaml.Node{}
+			err := value.Encode(values[column])
+			if err != nil {
+				return err
+			}
+			node.Content = append(
+				node.Content,
+				&yaml.Node{
+					Kind:  yaml.ScalarNode,
+					Value: column,
+				},
+				value,
+			)
+		}
+		l.nodes = append(l.nodes, node)
+	}
+	return nil
+}
+
+// Close writes the pending objects and releases the resources used by the list.
+func (l *List) Close() error {
+	switch l.format {
+	case FormatTable:
+		return l.table.Close()
+	case FormatJSON:
+		rows := l.rows
+		if rows == nil {
+			rows = []*ordered.OrderedMap{}
+		}
+		encoder := json.NewEncoder(l.printer)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	case FormatYAML:
+		document := &yaml.Node{
+			Kind:    yaml.SequenceNode,
+			Content: l.nodes,
+		}
+		if len(l.nodes) == 0 {
+			document.Style = yaml.FlowStyle
+		}
+		encoder := yaml.NewEncoder(l.printer)
+		encoder.SetIndent(2)
+		err := encoder.Encode(document)
+		if err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	return nil
+}
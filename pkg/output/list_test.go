@@ -0,0 +1,150 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("List", func() {
+	var ctx context.Context
+	var buffer *bytes.Buffer
+	var printer *Printer
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		buffer = &bytes.Buffer{}
+		printer, err = NewPrinter().
+			Writer(buffer).
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	write := func(list *List) {
+		err := list.Write(map[string]interface{}{
+			"id":    "a1",
+			"name":  "alice",
+			"roles": []string{"OrganizationAdmin", "ClusterEditor"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		err = list.Write(map[string]interface{}{
+			"id":   "a2",
+			"name": "bob",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		err = list.Close()
+		Expect(err).ToNot(HaveOccurred())
+		err = printer.Close()
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("Writes a table", func() {
+		list, err := printer.NewList().
+			Name("my_list").
+			Columns("name,id,roles").
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		write(list)
+		lines := strings.Split(buffer.String(), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(line, " ")
+		}
+		Expect(lines).To(Equal([]string{
+			"NAME   ID  ROLES",
+			"alice  a1  OrganizationAdmin ClusterEditor",
+			"bob    a2",
+			"",
+		}))
+	})
+
+	It("Writes JSON preserving the order of the columns", func() {
+		list, err := printer.NewList().
+			Name("my_list").
+			Format(FormatJSON).
+			Columns("name,id,roles").
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		write(list)
+		Expect(buffer.String()).To(Equal(`[
+  {
+    "name": "alice",
+    "id": "a1",
+    "roles": [
+      "OrganizationAdmin",
+      "ClusterEditor"
+    ]
+  },
+  {
+    "name": "bob",
+    "id": "a2",
+    "roles": null
+  }
+]
+`))
+	})
+
+	It("Writes YAML preserving the order of the columns", func() {
+		list, err := printer.NewList().
+			Name("my_list").
+			Format(FormatYAML).
+			Columns("name,roles").
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		write(list)
+		Expect(buffer.String()).To(Equal("" +
+			"- name: alice\n" +
+			"  roles:\n" +
+			"    - OrganizationAdmin\n" +
+			"    - ClusterEditor\n" +
+			"- name: bob\n" +
+			"  roles: null\n",
+		))
+	})
+
+	It("Writes an empty JSON array if there are no objects", func() {
+		list, err := printer.NewList().
+			Name("my_list").
+			Format(FormatJSON).
+			Columns("name").
+			Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(list.Close()).To(Succeed())
+		Expect(buffer.String()).To(Equal("[]\n"))
+	})
+
+	It("Rejects unknown columns", func() {
+		_, err := printer.NewList().
+			Name("my_list").
+			Columns("name,junk").
+			Known("id", "name").
+			Build(ctx)
+		Expect(err).To(MatchError("Unknown column 'junk', valid values are 'id', 'name'"))
+	})
+
+	It("Rejects unknown formats", func() {
+		_, err := ParseFormat("xml")
+		Expect(err).To(MatchError(
+			"Unknown output format 'xml', valid values are 'table', 'json', 'yaml'",
+		))
+	})
+})
@@ -31,9 +31,12 @@ import (
 
 // PrinterBuilder contains the data and logic needed to create new printers.
 type PrinterBuilder struct {
-	writer io.Writer
-	digger *data.Digger
-	pager  string
+	writer  io.Writer
+	digger  *data.Digger
+	pager   string
+	symbols *bool
+	color   *bool
+	unicode *bool
 }
 
 // Printer knows how to write output text.
@@ -51,6 +54,12 @@ type Printer struct {
 	width  int
 	height int
 
+	// Flags indicating if the values of styled columns should be decorated with symbols and
+	// colors, and if the symbols can use characters that aren't ASCII.
+	symbols bool
+	color   bool
+	unicode bool
+
 	// Command used to display output page by page:
 	pagerCmd    *exec.Cmd
 	pagerStop   chan int
@@ -89,6 +98,30 @@ func (b *PrinterBuilder) Pager(value string) *PrinterBuilder {
 	return b
 }
 
+// Symbols indicates if the values of styled columns, like the states of clusters, should be
+// prefixed with a symbol. This is optional. By default symbols are used if the output is a
+// terminal.
+func (b *PrinterBuilder) Symbols(value bool) *PrinterBuilder {
+	b.symbols = &value
+	return b
+}
+
+// Color indicates if the values of styled columns, like the states of clusters, should be
+// displayed with colors. This is optional. By default colors are used if the output is a terminal
+// that supports them and the 'NO_COLOR' environment variable isn't set.
+func (b *PrinterBuilder) Color(value bool) *PrinterBuilder {
+	b.color = &value
+	return b
+}
+
+// Unicode indicates if the symbols used for styled columns can contain characters that aren't
+// ASCII. This is optional. By default those characters are used if the locale uses the UTF-8
+// encoding.
+func (b *PrinterBuilder) Unicode(value bool) *PrinterBuilder {
+	b.unicode = &value
+	return b
+}
+
 // Build uses the data stored in the builder to create a new printer.
 func (b *PrinterBuilder) Build(ctx context.Context) (result *Printer, err error) {
 	// Check parameters:
@@ -119,6 +152,20 @@ func (b *PrinterBuilder) Build(ctx context.Context) (result *Printer, err error)
 		return
 	}
 
+	// Decide how to decorate styled columns:
+	symbols := terminal
+	if b.symbols != nil {
+		symbols = *b.symbols
+	}
+	color := terminal && colorEnabled() && SupportsColor(b.writer)
+	if b.color != nil {
+		color = *b.color
+	}
+	unicode := unicodeLocale()
+	if b.unicode != nil {
+		unicode = *b.unicode
+	}
+
 	// If paging is enabled, a pager is available and the output is a terminal, then start that
 	// pager in the background and redirect all the output to it:
 	writer := b.writer
@@ -165,6 +212,9 @@ func (b *PrinterBuilder) Build(ctx context.Context) (result *Printer, err error)
 		terminal:    terminal,
 		width:       width,
 		height:      height,
+		symbols:     symbols,
+		color:       color,
+		unicode:     unicode,
 		pagerCmd:    pagerCmd,
 		pagerStop:   pagerStop,
 		pagerReader: pagerReader,
@@ -0,0 +1,160 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the code that decorates the values of table columns that describe states or
+// severities, using colors and symbols.
+
+package output
+
+import (
+	"os"
+	"strings"
+)
+
+// Styles that can be assigned to table columns, using the 'style' field of the column in the table
+// description:
+const (
+	// StyleState is for columns that contain states of objects, like the state of a cluster.
+	StyleState = "state"
+
+	// StyleSeverity is for columns that contain severities of messages, like the severity of a
+	// service log entry.
+	StyleSeverity = "severity"
+)
+
+// level is the classification of a value of a styled column that determines the color and the
+// symbol used to display it.
+type level int
+
+const (
+	levelNone level = iota
+	levelOK
+	levelInfo
+	levelWarning
+	levelError
+)
+
+// symbolWidth is the number of characters that the symbol and the space that follows it take.
+const symbolWidth = 2
+
+// stateLevels contains the levels of the states of clusters and other objects. States that aren't
+// here aren't decorated.
+var stateLevels = map[string]level{
+	"ready":         levelOK,
+	"installing":    levelWarning,
+	"pending":       levelWarning,
+	"validating":    levelWarning,
+	"waiting":       levelWarning,
+	"updating":      levelWarning,
+	"resuming":      levelWarning,
+	"powering_down": levelWarning,
+	"uninstalling":  levelWarning,
+	"hibernating":   levelInfo,
+	"error":         levelError,
+	"failed":        levelError,
+	"unknown":       levelError,
+}
+
+// severityLevels contains the levels of the severities of messages.
+var severityLevels = map[string]level{
+	"debug":    levelInfo,
+	"info":     levelInfo,
+	"warning":  levelWarning,
+	"major":    levelError,
+	"error":    levelError,
+	"critical": levelError,
+	"fatal":    levelError,
+}
+
+// classify returns the level of the given value of a column with the given style.
+func classify(style, value string) level {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch style {
+	case StyleState:
+		return stateLevels[value]
+	case StyleSeverity:
+		return severityLevels[value]
+	default:
+		return levelNone
+	}
+}
+
+// symbol returns the symbol used for the level. When unicode is false the symbol is a plain ASCII
+// character, for terminals that can't display other characters.
+func (l level) symbol(unicode bool) string {
+	switch l {
+	case levelOK:
+		if unicode {
+			return "✔"
+		}
+		return "+"
+	case levelInfo:
+		if unicode {
+			return "•"
+		}
+		return "-"
+	case levelWarning:
+		if unicode {
+			return "▲"
+		}
+		return "~"
+	case levelError:
+		if unicode {
+			return "✖"
+		}
+		return "!"
+	default:
+		return " "
+	}
+}
+
+// color returns the ANSI escape sequence that sets the color used for the level, or an empty string
+// if the level isn't displayed with a color.
+func (l level) color() string {
+	switch l {
+	case levelOK:
+		return "\x1b[32m"
+	case levelWarning:
+		return "\x1b[33m"
+	case levelError:
+		return "\x1b[31m"
+	default:
+		return ""
+	}
+}
+
+// colorReset is the ANSI escape sequence that restores the default color.
+const colorReset = "\x1b[0m"
+
+// colorEnabled checks if colors should be used, honouring the 'NO_COLOR' environment variable
+// described in https://no-color.org.
+func colorEnabled() bool {
+	return os.Getenv("NO_COLOR") == ""
+}
+
+// unicodeLocale checks if the locale, as given by the 'LC_ALL', 'LC_CTYPE' and 'LANG' environment
+// variables, uses the UTF-8 encoding. The first of those variables that has a value wins.
+func unicodeLocale() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		value = strings.ToLower(value)
+		return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+	}
+	return false
+}
@@ -56,7 +56,14 @@ type Table struct {
 	// it without wasting space.
 	learning      bool
 	learningLimit int
-	learningRows  [][]string
+	learningRows  []*tableRow
+}
+
+// tableRow contains the text of the cells of a row, and a flag indicating if it is the row that
+// contains the headers.
+type tableRow struct {
+	cells  []string
+	header bool
 }
 
 // tableYAML is used to load a table description from a YAML document.
@@ -86,6 +93,10 @@ type Column struct {
 	// This can be the actual value of the column or, more generally, a function that will be
 	// called to obtain the actual value.
 	value reflect.Value
+
+	// Style used to decorate the values of the column, for example `state`. Empty if the values
+	// aren't decorated.
+	style string
 }
 
 // columnYAML is used to load a column description from a YAML document.
@@ -93,6 +104,7 @@ type columnYAML struct {
 	Name   *string `yaml:"name"`
 	Header *string `yaml:"header"`
 	Width  *int    `yaml:"width"`
+	Style  *string `yaml:"style"`
 }
 
 // NewTable creates a new builder that can then be used to configure and create a table.
@@ -215,36 +227,13 @@ func (b *TableBuilder) loadTable(columnNames []string) (result *Table, err error
 		learningLimit: b.learningLimit,
 	}
 
-	// Check if there is an asset corresponding to the table. If there is no asset then return
-	// the empty table description:
-	assetPath := fmt.Sprintf("tables/%s.yaml", b.name)
-	assetFile, err := assetFS.Open(assetPath)
-	if err != nil {
-		result = table
-		return
-	}
-	defer assetFile.Close()
-	assetData, err := io.ReadAll(assetFile)
-	if err != nil {
-		return
-	}
-
-	// Parse the YAML document from the asset:
-	var tableData tableYAML
-	err = yaml.Unmarshal(assetData, &tableData)
+	// Load the descriptions of the columns from the asset corresponding to the table, if there
+	// is such asset:
+	columnsFromAsset, err := b.loadColumns()
 	if err != nil {
 		return
 	}
 
-	// Load the descriptions of the columns from the asset:
-	columnsFromAsset := make([]*Column, len(tableData.Columns))
-	for i, columnData := range tableData.Columns {
-		columnsFromAsset[i], err = b.loadColumn(i, columnData)
-		if err != nil {
-			return
-		}
-	}
-
 	// Create the list of columns using the descriptions loaded from the asset, or else default
 	// descriptions for the columns that aren't described in the asset:
 	table.columns = make([]*Column, len(columnNames))
@@ -268,6 +257,39 @@ func (b *TableBuilder) loadTable(columnNames []string) (result *Table, err error
 	return
 }
 
+// loadColumns loads the descriptions of the columns from the asset corresponding to the table.
+// Returns an empty list if there is no such asset.
+func (b *TableBuilder) loadColumns() (result []*Column, err error) {
+	assetPath := fmt.Sprintf("tables/%s.yaml", b.name)
+	assetFile, err := assetFS.Open(assetPath)
+	if err != nil {
+		err = nil
+		return
+	}
+	defer assetFile.Close()
+	assetData, err := io.ReadAll(assetFile)
+	if err != nil {
+		return
+	}
+
+	// Parse the YAML document from the asset:
+	var tableData tableYAML
+	err = yaml.Unmarshal(assetData, &tableData)
+	if err != nil {
+		return
+	}
+
+	// Load the descriptions of the columns:
+	result = make([]*Column, len(tableData.Columns))
+	for i, columnData := range tableData.Columns {
+		result[i], err = b.loadColumn(i, columnData)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
 // loadColumnYAML copies the column data from the YAML document to the object.
 func (b *TableBuilder) loadColumn(i int, columnData *columnYAML) (result *Column, err error) {
 	// Check that the name of the column has been specified:
@@ -287,9 +309,15 @@ func (b *TableBuilder) loadColumn(i int, columnData *columnYAML) (result *Column
 	if columnData.Header != nil {
 		column.header = *columnData.Header
 	}
+	if columnData.Style != nil {
+		column.style = *columnData.Style
+	}
 	if columnData.Width != nil {
 		column.learn = false
 		column.width = *columnData.Width
+		if column.style != "" && b.printer.symbols {
+			column.width += symbolWidth
+		}
 	} else {
 		column.learn = true
 	}
@@ -337,6 +365,11 @@ func (b *TableBuilder) defaultColumnValue(columnName string) reflect.Value {
 
 // WriteRow writes a row of a table using the given values.
 func (t *Table) WriteRow(rowValues []interface{}) error {
+	return t.addRow(rowValues, false)
+}
+
+// addRow writes a row of the table, or accumulates it for learning.
+func (t *Table) addRow(rowValues []interface{}, header bool) error {
 	// Check that the number of values matches the number of columns:
 	valueCount := len(rowValues)
 	columnCount := len(t.columns)
@@ -358,9 +391,13 @@ func (t *Table) WriteRow(rowValues []interface{}) error {
 		}
 		rowData[i] = columnData
 	}
+	row := &tableRow{
+		cells:  rowData,
+		header: header,
+	}
 
 	// Try to accumulate the row for learning:
-	accumulated, err := t.accumulateRow(rowData)
+	accumulated, err := t.accumulateRow(row)
 	if err != nil {
 		return err
 	}
@@ -369,7 +406,7 @@ func (t *Table) WriteRow(rowValues []interface{}) error {
 	}
 
 	// Write the column data:
-	err = t.writeRow(rowData)
+	err = t.writeRow(row)
 	if err != nil {
 		return err
 	}
@@ -383,7 +420,7 @@ func (t *Table) WriteRow(rowValues []interface{}) error {
 //
 // When enough rows have been accumulated it will perform the learning process and display the
 // accumulated rows.
-func (t *Table) accumulateRow(rowData []string) (accumulated bool, err error) {
+func (t *Table) accumulateRow(row *tableRow) (accumulated bool, err error) {
 	// Do nothing if we aren't learning:
 	if !t.learning {
 		accumulated = false
@@ -394,7 +431,7 @@ func (t *Table) accumulateRow(rowData []string) (accumulated bool, err error) {
 	// passed row and continue. Actual learning will happen latter, when we have accumulated
 	// enough rows.
 	if len(t.learningRows) < t.learningLimit {
-		t.learningRows = append(t.learningRows, rowData)
+		t.learningRows = append(t.learningRows, row)
 		accumulated = true
 		return
 	}
@@ -416,8 +453,8 @@ func (t *Table) accumulateRow(rowData []string) (accumulated bool, err error) {
 func (t *Table) completeLearning() error {
 	var err error
 	t.learnColumnWidths()
-	for _, rowData := range t.learningRows {
-		err = t.writeRow(rowData)
+	for _, row := range t.learningRows {
+		err = t.writeRow(row)
 		if err != nil {
 			return err
 		}
@@ -435,8 +472,11 @@ func (t *Table) learnColumnWidths() {
 			continue
 		}
 		learnedWidth := len(column.Header())
-		for j := range t.learningRows {
-			actualWidth := len(t.learningRows[j][i])
+		for _, row := range t.learningRows {
+			actualWidth := len(row.cells[i])
+			if t.decorated(column, row) {
+				actualWidth += symbolWidth
+			}
 			if actualWidth > learnedWidth {
 				learnedWidth = actualWidth
 			}
@@ -445,9 +485,10 @@ func (t *Table) learnColumnWidths() {
 	}
 }
 
-func (t *Table) writeRow(rowData []string) error {
+func (t *Table) writeRow(row *tableRow) error {
 	// Prepare a buffer to write the columns (sum of the widths of the columns plus two
 	// characters to separate columns, and the new line):
+	rowData := row.cells
 	rowWidth := 2 * len(rowData)
 	for _, column := range t.columns {
 		rowWidth += column.Width()
@@ -460,19 +501,41 @@ func (t *Table) writeRow(rowData []string) error {
 		if i > 0 {
 			rowBuffer.WriteString("  ")
 		}
+		column := t.columns[i]
+		desiredWidth := column.Width()
+
+		// Values of styled columns are prefixed with a symbol and displayed with a color,
+		// without the padding:
+		color := ""
+		if t.decorated(column, row) {
+			level := classify(column.style, columnValue)
+			if t.printer.color {
+				color = level.color()
+			}
+			if color != "" {
+				rowBuffer.WriteString(color)
+			}
+			rowBuffer.WriteString(level.symbol(t.printer.unicode))
+			rowBuffer.WriteString(" ")
+			desiredWidth -= symbolWidth
+			if desiredWidth < 0 {
+				desiredWidth = 0
+			}
+		}
+
 		actualWidth := len(columnValue)
-		desiredWidth := t.columns[i].Width()
 		switch {
 		case actualWidth > desiredWidth:
 			rowBuffer.WriteString(columnValue[0:desiredWidth])
-		case actualWidth < desiredWidth:
-			rowBuffer.WriteString(columnValue)
-			for j := 0; j < desiredWidth-actualWidth; j++ {
-				rowBuffer.WriteString(" ")
-			}
 		default:
 			rowBuffer.WriteString(columnValue)
 		}
+		if color != "" {
+			rowBuffer.WriteString(colorReset)
+		}
+		for j := 0; j < desiredWidth-actualWidth; j++ {
+			rowBuffer.WriteString(" ")
+		}
 	}
 	rowBuffer.WriteString("\n")
 
@@ -481,13 +544,19 @@ func (t *Table) writeRow(rowData []string) error {
 	return err
 }
 
+// decorated checks if the value of the given column in the given row should be decorated with a
+// symbol and a color.
+func (t *Table) decorated(column *Column, row *tableRow) bool {
+	return column.style != "" && !row.header && t.printer.symbols
+}
+
 // WriteHeaders writes the headers of the columns of the table.
 func (t *Table) WriteHeaders() error {
 	headers := make([]interface{}, len(t.columns))
 	for i, column := range t.columns {
 		headers[i] = column.Header()
 	}
-	return t.WriteRow(headers)
+	return t.addRow(headers, true)
 }
 
 // WriteObject writes a row of a table extracting the values of the columns from the given object.
@@ -303,4 +303,60 @@ var _ = Describe("Table", func() {
 		Expect(lines[1]).To(Equal(`123   my_github`))
 		Expect(lines[2]).To(Equal(`456   your_gith`))
 	})
+
+	When("Decorating styled columns", func() {
+		// writeStates writes a table with the states of two clusters using a printer built
+		// with the given builder, and returns the lines of the result:
+		writeStates := func(builder *PrinterBuilder) []string {
+			printer, err := builder.
+				Writer(buffer).
+				Build(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			defer printer.Close()
+			table, err := printer.NewTable().
+				Name("clusters").
+				Columns("name", "state").
+				Build(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(table.WriteHeaders()).To(Succeed())
+			for _, state := range []cmv1.ClusterState{
+				cmv1.ClusterStateReady,
+				cmv1.ClusterStateError,
+			} {
+				object, err := cmv1.NewCluster().
+					Name("my-cluster").
+					State(state).
+					Build()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(table.WriteObject(object)).To(Succeed())
+			}
+			Expect(table.Close()).To(Succeed())
+			return strings.Split(buffer.String(), "\n")
+		}
+
+		It("Doesn't decorate values by default if the output isn't a terminal", func() {
+			lines := writeStates(NewPrinter())
+			Expect(lines[1]).To(MatchRegexp(`^my-cluster\s+ready\s*$`))
+			Expect(lines[2]).To(MatchRegexp(`^my-cluster\s+error\s*$`))
+		})
+
+		It("Adds symbols and colors to the values but not to the headers", func() {
+			lines := writeStates(NewPrinter().Symbols(true).Color(true).Unicode(true))
+			Expect(lines[0]).To(MatchRegexp(`^NAME\s+STATE\s*$`))
+			Expect(lines[1]).To(ContainSubstring("\x1b[32m✔ ready\x1b[0m"))
+			Expect(lines[2]).To(ContainSubstring("\x1b[31m✖ error\x1b[0m"))
+		})
+
+		It("Uses ASCII symbols if the terminal doesn't support unicode", func() {
+			lines := writeStates(NewPrinter().Symbols(true).Color(false).Unicode(false))
+			Expect(lines[1]).To(MatchRegexp(`^my-cluster\s+\+ ready\s*$`))
+			Expect(lines[2]).To(MatchRegexp(`^my-cluster\s+! error\s*$`))
+		})
+
+		It("Keeps the columns aligned", func() {
+			lines := writeStates(NewPrinter().Symbols(true).Color(false).Unicode(false))
+			Expect(strings.Index(lines[0], "STATE")).To(Equal(strings.Index(lines[1], "+")))
+			Expect(len(lines[0])).To(Equal(len(lines[1])))
+		})
+	})
 })
@@ -32,3 +32,17 @@ func IsTerminal(writer io.Writer) bool {
 	fd := int(file.Fd())
 	return term.IsTerminal(fd)
 }
+
+// SupportsColor determines if the given writer is a terminal that understands the ANSI escape
+// sequences used to change colors. On Windows this tries to enable the virtual terminal
+// processing of the console, which is required for the escape sequences to work.
+func SupportsColor(writer io.Writer) bool {
+	file, ok := writer.(*os.File)
+	if !ok {
+		return false
+	}
+	if !term.IsTerminal(int(file.Fd())) {
+		return false
+	}
+	return enableVirtualTerminal(file)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pagination contains functions to retrieve all the pages of the collections returned by
+// the API.
+package pagination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+// DefaultSize is the number of items requested in each page when no other size is given.
+const DefaultSize = 100
+
+// RequestFunc is the type of the functions that create the request for a page. The page and size
+// parameters are added by the Collect function, so they shouldn't be added by this function.
+type RequestFunc func() *sdk.Request
+
+// Collect sends the requests created by the given function, adding the 'page' and 'size'
+// parameters, and merges the items of all the pages into one document like the one returned for
+// the first page, but containing all the items. If the response isn't a collection, or if the
+// server returns an error, the status and body of the first response that isn't a page are
+// returned without changes.
+func Collect(ctx context.Context, newRequest RequestFunc, size int) (status int, body []byte,
+	err error) {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	var first map[string]interface{}
+	var items []interface{}
+	page := 1
+	for {
+		var response *sdk.Response
+		response, err = newRequest().
+			Parameter("page", page).
+			Parameter("size", size).
+			SendContext(ctx)
+		if err != nil {
+			return
+		}
+		status = response.Status()
+		body = response.Bytes()
+		if status >= http.StatusBadRequest {
+			return
+		}
+		document, pageItems, ok := parsePage(body)
+		if !ok {
+			if first == nil {
+				return
+			}
+			break
+		}
+		if first == nil {
+			first = document
+		}
+		items = append(items, pageItems...)
+		// The server may return less items than requested, so use the total if available:
+		total, ok := number(first["total"])
+		if len(pageItems) == 0 || ok && len(items) >= total || !ok && len(pageItems) < size {
+			break
+		}
+		page++
+	}
+
+	// Replace the items of the first page with all the items:
+	first["items"] = items
+	first["page"] = 1
+	first["size"] = len(items)
+	if _, ok := first["total"]; !ok {
+		first["total"] = len(items)
+	}
+	body, err = json.Marshal(first)
+	return
+}
+
+// parsePage checks if the given body is a page of a collection, and returns the parsed document
+// and the items.
+func parsePage(body []byte) (document map[string]interface{}, items []interface{}, ok bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	err := decoder.Decode(&document)
+	if err != nil {
+		return
+	}
+	if _, ok = document["page"]; !ok {
+		return
+	}
+	items, ok = document["items"].([]interface{})
+	if !ok && document["items"] == nil {
+		// Empty collections don't always contain the items attribute:
+		ok = true
+	}
+	return
+}
+
+// number converts the given JSON value to an integer.
+func number(value interface{}) (result int, ok bool) {
+	typed, ok := value.(json.Number)
+	if !ok {
+		return
+	}
+	converted, err := typed.Int64()
+	if err != nil {
+		ok = false
+		return
+	}
+	result = int(converted)
+	return
+}
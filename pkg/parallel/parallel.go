@@ -0,0 +1,52 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package parallel contains functions used to run operations concurrently, for example to send
+// requests for multiple objects at the same time.
+package parallel
+
+import (
+	"sync"
+)
+
+// Each calls the given function once for each index from zero to count-1, running at most the
+// given number of calls at the same time. It returns when all the calls have finished. The
+// function is responsible for storing its results, usually in the position of a slice given by
+// the index, which doesn't need synchronization.
+func Each(count, workers int, fn func(index int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > count {
+		workers = count
+	}
+	indexes := make(chan int)
+	group := &sync.WaitGroup{}
+	group.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer group.Done()
+			for index := range indexes {
+				fn(index)
+			}
+		}()
+	}
+	for index := 0; index < count; index++ {
+		indexes <- index
+	}
+	close(indexes)
+	group.Wait()
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parallel
+
+import (
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Each", func() {
+	It("Calls the function for each index", func() {
+		results := make([]int, 50)
+		Each(len(results), 8, func(index int) {
+			results[index] = index * 2
+		})
+		for i, result := range results {
+			Expect(result).To(Equal(i * 2))
+		}
+	})
+
+	It("Doesn't run more calls than workers at the same time", func() {
+		var running, peak int32
+		Each(20, 3, func(index int) {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		})
+		Expect(peak).To(BeNumerically("<=", 3))
+		Expect(peak).To(BeNumerically(">", 1))
+	})
+
+	It("Works without items and with invalid number of workers", func() {
+		calls := 0
+		Each(0, 5, func(index int) {
+			calls++
+		})
+		Each(2, 0, func(index int) {
+			calls++
+		})
+		Expect(calls).To(Equal(2))
+	})
+})
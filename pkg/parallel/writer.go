@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parallel
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Writer serializes the writes of multiple goroutines to an underlying writer, so that the text
+// written by each call to the Write method is never mixed with the text written by other calls.
+// Code that prints messages with fmt.Fprintf, where each call writes one or more complete lines,
+// can share one writer between workers. Code that builds a line or a group of related lines with
+// multiple calls should use a block, see the Block method.
+type Writer struct {
+	lock sync.Mutex
+	out  io.Writer
+}
+
+// NewWriter creates a writer that writes to the given writer, usually os.Stdout or os.Stderr.
+func NewWriter(out io.Writer) *Writer {
+	return &Writer{
+		out: out,
+	}
+}
+
+// Write is the implementation of the io.Writer interface.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.out.Write(p)
+}
+
+// Block creates a buffer that collects the output generated for one item. Nothing is written to
+// the writer till the Flush method of the block is called, and then all the text is written in one
+// call, so the lines of the block appear together even if other workers are writing at the same
+// time.
+func (w *Writer) Block() *Block {
+	return &Block{
+		writer: w,
+	}
+}
+
+// Block collects text that will be written to a writer in one call. Blocks aren't safe for
+// concurrent use, each worker should create its own.
+type Block struct {
+	writer *Writer
+	buffer bytes.Buffer
+}
+
+// Write is the implementation of the io.Writer interface.
+func (b *Block) Write(p []byte) (n int, err error) {
+	return b.buffer.Write(p)
+}
+
+// Flush writes the collected text to the writer and empties the block. If the text doesn't end
+// with a new line character one is added, so that it isn't joined with the first line written by
+// other workers.
+func (b *Block) Flush() error {
+	if b.buffer.Len() == 0 {
+		return nil
+	}
+	data := b.buffer.Bytes()
+	if data[len(data)-1] != '\n' {
+		b.buffer.WriteByte('\n')
+		data = b.buffer.Bytes()
+	}
+	_, err := b.writer.Write(data)
+	b.buffer.Reset()
+	return err
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parallel
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+// slowWriter writes the data one byte at a time, yielding between bytes, so that unsynchronized
+// writes from multiple goroutines are very likely to be mixed.
+type slowWriter struct {
+	lock   sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (w *slowWriter) Write(p []byte) (n int, err error) {
+	for _, b := range p {
+		w.lock.Lock()
+		w.buffer.WriteByte(b)
+		w.lock.Unlock()
+		n++
+	}
+	return
+}
+
+var _ = Describe("Writer", func() {
+	It("Doesn't mix lines written by multiple workers", func() {
+		out := &slowWriter{}
+		writer := NewWriter(out)
+		Each(100, 10, func(index int) {
+			fmt.Fprintf(writer, "line %d of worker output\n", index)
+		})
+		lines := strings.Split(strings.TrimSuffix(out.buffer.String(), "\n"), "\n")
+		Expect(lines).To(HaveLen(100))
+		for _, line := range lines {
+			Expect(line).To(MatchRegexp(`^line \d+ of worker output$`))
+		}
+	})
+
+	It("Writes the lines of a block together", func() {
+		out := &slowWriter{}
+		writer := NewWriter(out)
+		Each(50, 10, func(index int) {
+			block := writer.Block()
+			fmt.Fprintf(block, "item %d:", index)
+			fmt.Fprintf(block, " first\n")
+			fmt.Fprintf(block, "  item %d: second\n", index)
+			err := block.Flush()
+			Expect(err).ToNot(HaveOccurred())
+		})
+		lines := strings.Split(strings.TrimSuffix(out.buffer.String(), "\n"), "\n")
+		Expect(lines).To(HaveLen(100))
+		for i := 0; i < len(lines); i += 2 {
+			var index int
+			_, err := fmt.Sscanf(lines[i], "item %d: first", &index)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lines[i+1]).To(Equal(fmt.Sprintf("  item %d: second", index)))
+		}
+	})
+
+	It("Adds the missing new line when flushing a block", func() {
+		out := &bytes.Buffer{}
+		block := NewWriter(out).Block()
+		fmt.Fprint(block, "partial")
+		Expect(out.String()).To(BeEmpty())
+		err := block.Flush()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(Equal("partial\n"))
+	})
+
+	It("Doesn't write empty blocks", func() {
+		out := &bytes.Buffer{}
+		err := NewWriter(out).Block().Flush()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(BeEmpty())
+	})
+})
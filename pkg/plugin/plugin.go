@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
 )
 
 // Handler is capable of parsing command line arguments
@@ -90,9 +93,43 @@ func HandlePluginCommand(pluginHandler Handler, cmdArgs []string) (found bool, e
 	}
 
 	// invoke cmd binary relaying the current environment and args given
-	if err := pluginHandler.Execute(foundBinaryPath, cmdArgs[len(remainingArgs):], os.Environ()); err != nil {
+	if err := pluginHandler.Execute(foundBinaryPath, cmdArgs[len(remainingArgs):], Environment()); err != nil {
 		return true, err
 	}
 
 	return true, nil
 }
+
+// Environment returns the environment variables for plugins: the environment of the current
+// process with the 'OCM_CONFIG' and 'OCM_CONTEXT' variables pointing to the configuration file
+// and context in use, so that plugins use the same credentials without having to find them. For
+// example, a plugin can run 'ocm token' to get an access token.
+func Environment() []string {
+	result := os.Environ()
+	location, err := config.Location()
+	if err == nil {
+		abs, err := filepath.Abs(location)
+		if err == nil {
+			location = abs
+		}
+		result = setEnv(result, "OCM_CONFIG", location)
+	}
+	context, err := config.SelectedContext()
+	if err == nil {
+		result = setEnv(result, config.ContextEnvVar, context)
+	}
+	return result
+}
+
+// setEnv sets the value of a variable in the given environment, replacing the existing value if
+// any.
+func setEnv(environment []string, name, value string) []string {
+	prefix := name + "="
+	result := make([]string, 0, len(environment)+1)
+	for _, item := range environment {
+		if !strings.HasPrefix(item, prefix) {
+			result = append(result, item)
+		}
+	}
+	return append(result, prefix+value)
+}
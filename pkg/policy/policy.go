@@ -0,0 +1,361 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to check the requests sent to the API against the local
+// policies before they are sent.
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/itchyny/gojq"
+	"gopkg.in/yaml.v3"
+
+	"github.com/openshift-online/ocm-cli/pkg/dump"
+)
+
+// Actions supported by policies:
+const (
+	ActionDeny = "deny"
+	ActionWarn = "warn"
+)
+
+// mutatingMethods are the methods checked when the policy doesn't explicitly list them.
+var mutatingMethods = []string{
+	http.MethodDelete,
+	http.MethodPatch,
+	http.MethodPost,
+	http.MethodPut,
+}
+
+// Policy is a check applied to the requests before they are sent to the API. The condition is a
+// jq filter that is evaluated against a document containing the 'method', 'path', 'query' and
+// 'body' of the request. When the result is true the request violates the policy, and it is
+// blocked or a warning is written, depending on the action.
+type Policy struct {
+	Name      string   `yaml:"name"`
+	Methods   []string `yaml:"methods"`
+	Paths     []string `yaml:"paths"`
+	Condition string   `yaml:"condition"`
+	Action    string   `yaml:"action"`
+	Message   string   `yaml:"message"`
+
+	file string
+	code *gojq.Code
+}
+
+// Violation describes a request that doesn't comply with a policy.
+type Violation struct {
+	Policy *Policy
+}
+
+// Message returns the message of the policy, or a description of the condition if the policy
+// doesn't have a message.
+func (v *Violation) Message() string {
+	if v.Policy.Message != "" {
+		return v.Policy.Message
+	}
+	return fmt.Sprintf("condition '%s' is true", v.Policy.Condition)
+}
+
+// Error is the implementation of the error interface.
+func (v *Violation) Error() string {
+	return fmt.Sprintf("Request denied by policy '%s': %s", v.Policy.Name, v.Message())
+}
+
+// Location returns the directory containing the policies. It is the value of the
+// 'OCM_POLICY_DIR' environment variable, or the 'policies' directory next to the given
+// configuration file. Returns an empty string if neither is available.
+func Location(configFile string) string {
+	dir := os.Getenv("OCM_POLICY_DIR")
+	if dir != "" || configFile == "" {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(configFile), "policies")
+}
+
+// Load loads the policies from the '.yaml' and '.yml' files of the given directory. Each file
+// can contain multiple policies separated by '---'. Returns an empty list if the directory
+// doesn't exist.
+func Load(dir string) (policies []*Policy, err error) {
+	if dir == "" {
+		return
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	names := []string{}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var loaded []*Policy
+		loaded, err = loadFile(filepath.Join(dir, name))
+		if err != nil {
+			return
+		}
+		policies = append(policies, loaded...)
+	}
+	return
+}
+
+func loadFile(file string) (policies []*Policy, err error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		policy := &Policy{}
+		err = decoder.Decode(policy)
+		if err == io.EOF {
+			err = nil
+			return
+		}
+		if err != nil {
+			err = fmt.Errorf("Can't parse policy file '%s': %v", file, err)
+			return
+		}
+		policy.file = file
+		err = policy.check()
+		if err != nil {
+			return
+		}
+		policies = append(policies, policy)
+	}
+}
+
+// check checks that the policy is valid, compiles the condition and fills the default values.
+func (p *Policy) check() (err error) {
+	if p.Name == "" {
+		return fmt.Errorf("Policy in file '%s' doesn't have a name", p.file)
+	}
+	if p.Condition == "" {
+		return fmt.Errorf("Policy '%s' in file '%s' doesn't have a condition", p.Name, p.file)
+	}
+	p.code, err = dump.ParseJQ(p.Condition)
+	if err != nil {
+		return fmt.Errorf(
+			"Condition of policy '%s' in file '%s' isn't valid: %v",
+			p.Name, p.file, err,
+		)
+	}
+	switch p.Action {
+	case "":
+		p.Action = ActionDeny
+	case ActionDeny, ActionWarn:
+	default:
+		return fmt.Errorf(
+			"Action '%s' of policy '%s' in file '%s' isn't valid, it should be '%s' or '%s'",
+			p.Action, p.Name, p.file, ActionDeny, ActionWarn,
+		)
+	}
+	if len(p.Methods) == 0 {
+		p.Methods = mutatingMethods
+	}
+	for i, method := range p.Methods {
+		p.Methods[i] = strings.ToUpper(method)
+	}
+	for _, pattern := range p.Paths {
+		_, err = path.Match(pattern, "/")
+		if err != nil {
+			return fmt.Errorf(
+				"Path '%s' of policy '%s' in file '%s' isn't valid: %v",
+				pattern, p.Name, p.file, err,
+			)
+		}
+	}
+	return nil
+}
+
+// Applies returns true if the policy applies to requests with the given method and path.
+func (p *Policy) Applies(method, requestPath string) bool {
+	found := false
+	for _, candidate := range p.Methods {
+		if candidate == method {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	if len(p.Paths) == 0 {
+		return true
+	}
+	for _, pattern := range p.Paths {
+		matched, _ := path.Match(pattern, requestPath)
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Violated evaluates the condition of the policy against the given input document. The
+// condition is considered true when any of its results is neither false nor null.
+func (p *Policy) Violated(input interface{}) (violated bool, err error) {
+	iterator := p.code.Run(input)
+	for {
+		result, ok := iterator.Next()
+		if !ok {
+			return
+		}
+		switch typed := result.(type) {
+		case error:
+			err = fmt.Errorf("Can't evaluate condition of policy '%s': %v", p.Name, typed)
+			return
+		case nil:
+		case bool:
+			violated = violated || typed
+		default:
+			violated = true
+		}
+	}
+}
+
+// Check evaluates the policies that apply to the given request. It writes a warning to the given
+// writer for each violated policy with the 'warn' action, and returns a *Violation error for the
+// first violated policy with the 'deny' action.
+func Check(policies []*Policy, out io.Writer, method, requestPath string, query map[string][]string,
+	body []byte) error {
+	var input map[string]interface{}
+	for _, policy := range policies {
+		if !policy.Applies(method, requestPath) {
+			continue
+		}
+		if input == nil {
+			var err error
+			input, err = makeInput(method, requestPath, query, body)
+			if err != nil {
+				return err
+			}
+		}
+		violated, err := policy.Violated(input)
+		if err != nil {
+			return err
+		}
+		if !violated {
+			continue
+		}
+		violation := &Violation{
+			Policy: policy,
+		}
+		if policy.Action == ActionDeny {
+			return violation
+		}
+		fmt.Fprintf(
+			out, "WARNING: Request violates policy '%s': %s\n",
+			policy.Name, violation.Message(),
+		)
+	}
+	return nil
+}
+
+// makeInput creates the document that the conditions of the policies are evaluated against.
+func makeInput(method, requestPath string, query map[string][]string,
+	body []byte) (input map[string]interface{}, err error) {
+	values := map[string]interface{}{}
+	for name, list := range query {
+		items := make([]interface{}, len(list))
+		for i, item := range list {
+			items[i] = item
+		}
+		values[name] = items
+	}
+	input = map[string]interface{}{
+		"method": method,
+		"path":   requestPath,
+		"query":  values,
+		"body":   nil,
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		var decoded interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		err = decoder.Decode(&decoded)
+		if err != nil {
+			err = fmt.Errorf("Can't parse request body to check policies: %v", err)
+			return
+		}
+		input["body"] = decoded
+	}
+	return
+}
+
+// TransportWrapper returns a transport wrapper that checks the requests against the given
+// policies before sending them. Warnings are written to the given writer.
+func TransportWrapper(policies []*Policy, out io.Writer) func(http.RoundTripper) http.RoundTripper {
+	return func(wrapped http.RoundTripper) http.RoundTripper {
+		return &roundTripper{
+			policies: policies,
+			out:      out,
+			wrapped:  wrapped,
+		}
+	}
+}
+
+type roundTripper struct {
+	policies []*Policy
+	out      io.Writer
+	wrapped  http.RoundTripper
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*roundTripper)(nil)
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	var body []byte
+	if request.Body != nil && request.Body != http.NoBody {
+		body, err = ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return
+		}
+		request = request.Clone(request.Context())
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		request.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	err = Check(t.policies, t.out, request.Method, request.URL.Path, request.URL.Query(), body)
+	if err != nil {
+		return
+	}
+	return t.wrapped.RoundTrip(request)
+}
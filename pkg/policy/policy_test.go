@@ -0,0 +1,144 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Policies", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-policy-*")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	write := func(name, content string) {
+		err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0600)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("Returns nothing if the directory doesn't exist", func() {
+		policies, err := Load(filepath.Join(tmpDir, "missing"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policies).To(BeEmpty())
+	})
+
+	It("Loads multiple policies from the same file", func() {
+		write("regions.yaml", `
+name: approved-regions
+paths:
+- /api/clusters_mgmt/v1/clusters
+condition: .body.region.id | IN("us-east-1", "eu-west-1") | not
+message: Clusters must be created in approved regions
+---
+name: no-deletes
+methods:
+- delete
+condition: "true"
+action: warn
+`)
+		write("README.md", "Not a policy")
+		policies, err := Load(tmpDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policies).To(HaveLen(2))
+		Expect(policies[0].Name).To(Equal("approved-regions"))
+		Expect(policies[0].Action).To(Equal(ActionDeny))
+		Expect(policies[0].Methods).To(ConsistOf("DELETE", "PATCH", "POST", "PUT"))
+		Expect(policies[1].Name).To(Equal("no-deletes"))
+		Expect(policies[1].Methods).To(ConsistOf("DELETE"))
+	})
+
+	It("Rejects policies with invalid conditions", func() {
+		write("bad.yaml", "name: bad\ncondition: .body |||\n")
+		_, err := Load(tmpDir)
+		Expect(err).To(MatchError(ContainSubstring("Condition of policy 'bad'")))
+	})
+
+	It("Rejects policies with unknown actions", func() {
+		write("bad.yaml", "name: bad\ncondition: \"true\"\naction: block\n")
+		_, err := Load(tmpDir)
+		Expect(err).To(MatchError(ContainSubstring("Action 'block' of policy 'bad'")))
+	})
+
+	It("Denies requests that violate a policy", func() {
+		write("regions.yaml", `
+name: approved-regions
+paths:
+- /api/clusters_mgmt/v1/clusters
+condition: .body.region.id | IN("us-east-1", "eu-west-1") | not
+message: Clusters must be created in approved regions
+`)
+		policies, err := Load(tmpDir)
+		Expect(err).ToNot(HaveOccurred())
+		out := &bytes.Buffer{}
+
+		err = Check(policies, out, "POST", "/api/clusters_mgmt/v1/clusters", nil,
+			[]byte(`{"region": {"id": "us-east-1"}}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		err = Check(policies, out, "POST", "/api/clusters_mgmt/v1/clusters", nil,
+			[]byte(`{"region": {"id": "ap-south-1"}}`))
+		var violation *Violation
+		Expect(errors.As(err, &violation)).To(BeTrue())
+		Expect(violation.Policy.Name).To(Equal("approved-regions"))
+		Expect(err.Error()).To(Equal(
+			"Request denied by policy 'approved-regions': " +
+				"Clusters must be created in approved regions",
+		))
+
+		// Other paths and methods aren't checked:
+		err = Check(policies, out, "POST", "/api/clusters_mgmt/v1/clusters/123/groups", nil,
+			[]byte(`{"region": {"id": "ap-south-1"}}`))
+		Expect(err).ToNot(HaveOccurred())
+		err = Check(policies, out, "GET", "/api/clusters_mgmt/v1/clusters", nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("Writes warnings for policies with the warn action", func() {
+		write("deletes.yaml", `
+name: no-deletes
+paths:
+- /api/clusters_mgmt/v1/clusters/*
+condition: .query.deprovision != ["false"]
+action: warn
+message: Clusters should be deleted by the automation
+`)
+		policies, err := Load(tmpDir)
+		Expect(err).ToNot(HaveOccurred())
+		out := &bytes.Buffer{}
+		err = Check(policies, out, "DELETE", "/api/clusters_mgmt/v1/clusters/123", nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(Equal(
+			"WARNING: Request violates policy 'no-deletes': " +
+				"Clusters should be deleted by the automation\n",
+		))
+	})
+})
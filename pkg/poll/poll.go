@@ -0,0 +1,61 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package poll contains the functions used to repeat a check till a condition is satisfied, for
+// example till a cluster is ready or a machine pool is deleted.
+package poll
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Func is the type of the functions that check if the condition that is being waited for is
+// satisfied.
+type Func func(ctx context.Context) (done bool, err error)
+
+// Until calls the check function immediately and then repeatedly, waiting the given interval
+// between calls, till it returns true or an error, or till the context is cancelled or its
+// deadline expires. In that case it returns the error of the context, even if the check function
+// failed because the context was done while it was sending a request.
+func Until(ctx context.Context, interval time.Duration, check Func) error {
+	for {
+		done, err := check(ctx)
+		if err == nil && done {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// IsTimeout checks if the given error was returned by the Until function because the deadline of
+// the context expired.
+func IsTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
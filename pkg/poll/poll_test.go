@@ -0,0 +1,68 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poll
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Until", func() {
+	It("Returns when the check is satisfied", func() {
+		calls := 0
+		err := Until(context.Background(), time.Millisecond, func(ctx context.Context) (bool, error) {
+			calls++
+			return calls == 3, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calls).To(Equal(3))
+	})
+
+	It("Returns the error of the check", func() {
+		calls := 0
+		err := Until(context.Background(), time.Millisecond, func(ctx context.Context) (bool, error) {
+			calls++
+			return false, errors.New("my error")
+		})
+		Expect(err).To(MatchError("my error"))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("Returns a timeout error when the deadline expires", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := Until(ctx, time.Millisecond, func(ctx context.Context) (bool, error) {
+			return false, nil
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(IsTimeout(err)).To(BeTrue())
+	})
+
+	It("Returns a timeout error when the check fails because of the deadline", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := Until(ctx, time.Millisecond, func(ctx context.Context) (bool, error) {
+			<-ctx.Done()
+			return false, errors.New("request cancelled")
+		})
+		Expect(IsTimeout(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,264 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to implement the interlock that protects production
+// clusters from accidental changes.
+
+package protection
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+)
+
+// Key and value of the subscription label that marks a cluster as a production cluster.
+const (
+	LabelKey   = "protection"
+	LabelValue = "production"
+)
+
+// FlagName is the name of the command line flag that allows changes to production clusters.
+const FlagName = "i-know-this-is-production"
+
+// Modes of the interlock, selected for each context with the 'production_interlock'
+// configuration setting:
+const (
+	// ModeOff disables the interlock. This is the default, as checking the labels needs an
+	// additional request for each change.
+	ModeOff = "off"
+
+	// ModeConfirm asks the user for confirmation when running in a terminal, and otherwise
+	// requires the flag.
+	ModeConfirm = "confirm"
+
+	// ModeFlag always requires the flag, even when running in a terminal.
+	ModeFlag = "flag"
+)
+
+// Modes returns the valid values of the 'production_interlock' configuration setting.
+func Modes() []string {
+	return []string{ModeOff, ModeConfirm, ModeFlag}
+}
+
+// ValidMode checks if the given value is a valid mode. The empty string is valid and means the
+// default mode, which is off.
+func ValidMode(mode string) bool {
+	if mode == "" {
+		return true
+	}
+	for _, valid := range Modes() {
+		if mode == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// AddFlag adds the flag that allows changes to production clusters to the given set of command
+// line flags.
+func AddFlag(flags *pflag.FlagSet) {
+	flags.BoolVar(
+		&confirmed,
+		FlagName,
+		false,
+		"Allow changes to clusters labeled with '"+LabelKey+"="+LabelValue+"' without asking "+
+			"for confirmation.",
+	)
+}
+
+// Confirmed returns true if the user used the flag that allows changes to production clusters.
+func Confirmed() bool {
+	return confirmed
+}
+
+// confirmed is the value of the flag.
+var confirmed bool
+
+// Error is returned when a request tries to change a production cluster and the user didn't
+// confirm it.
+type Error struct {
+	Cluster string
+}
+
+// Error is the implementation of the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf(
+		"Cluster '%s' is labeled as a production cluster, refusing to change it. Use the "+
+			"'--%s' option if you really want to change it",
+		e.Cluster, FlagName,
+	)
+}
+
+// Asker asks the user to confirm a change to the production cluster with the given name. It
+// returns true if the user confirms.
+type Asker func(cluster string) (bool, error)
+
+// clusterPathRE matches the paths of the cluster resources, and extracts the identifier of the
+// cluster.
+var clusterPathRE = regexp.MustCompile(`^/api/clusters_mgmt/v1/clusters/([a-zA-Z0-9_-]+)(/|$)`)
+
+// TransportWrapper returns a transport wrapper that checks the requests that may change a
+// cluster, and rejects them if the cluster is labeled as a production cluster, unless the user
+// used the flag or confirmed it with the given function. If the function is nil the flag is
+// required. Only the 'GET', 'HEAD' and 'OPTIONS' methods and dry run requests are always allowed.
+func TransportWrapper(tokenURL string, ask Asker) func(http.RoundTripper) http.RoundTripper {
+	return func(wrapped http.RoundTripper) http.RoundTripper {
+		return &roundTripper{
+			tokenURL: tokenURL,
+			ask:      ask,
+			wrapped:  wrapped,
+			allowed:  map[string]bool{},
+		}
+	}
+}
+
+type roundTripper struct {
+	tokenURL string
+	ask      Asker
+	wrapped  http.RoundTripper
+
+	// allowed contains the identifiers of the clusters that have already been checked, so that
+	// the user isn't asked again. The lock also makes sure that only one question is asked at a
+	// time.
+	lock    sync.Mutex
+	allowed map[string]bool
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*roundTripper)(nil)
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	switch {
+	case confirmed:
+	case strings.HasPrefix(request.URL.String(), t.tokenURL):
+	case request.Method == http.MethodGet:
+	case request.Method == http.MethodHead:
+	case request.Method == http.MethodOptions:
+	case request.URL.Query().Get("dryRun") == "true":
+	default:
+		err = t.check(request)
+		if err != nil {
+			if request.Body != nil {
+				request.Body.Close()
+			}
+			return
+		}
+	}
+	return t.wrapped.RoundTrip(request)
+}
+
+// check returns an error if the request changes a production cluster and the user doesn't
+// confirm it.
+func (t *roundTripper) check(request *http.Request) error {
+	matches := clusterPathRE.FindStringSubmatch(request.URL.Path)
+	if matches == nil {
+		return nil
+	}
+	id := matches[1]
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.allowed[id] {
+		return nil
+	}
+	name, protected, err := t.lookup(request, id)
+	if err != nil {
+		return fmt.Errorf("Can't check if cluster '%s' is a production cluster: %v", id, err)
+	}
+	if protected {
+		if t.ask == nil {
+			return &Error{Cluster: name}
+		}
+		ok, err := t.ask(name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return &Error{Cluster: name}
+		}
+	}
+	t.allowed[id] = true
+	return nil
+}
+
+// lookup retrieves the subscription of the cluster, including its labels, and checks if it is
+// labeled as a production cluster. It also returns the name of the cluster, or the identifier if
+// the subscription doesn't have a name.
+func (t *roundTripper) lookup(request *http.Request, id string) (name string, protected bool,
+	err error) {
+	name = id
+	query := url.Values{}
+	query.Set("search", fmt.Sprintf("cluster_id = '%s'", id))
+	query.Set("fetchLabels", "true")
+	query.Set("size", "1")
+	address := *request.URL
+	address.Path = "/api/accounts_mgmt/v1/subscriptions"
+	address.RawQuery = query.Encode()
+	lookup, err := http.NewRequestWithContext(request.Context(), http.MethodGet, address.String(), nil)
+	if err != nil {
+		return
+	}
+	for key, values := range request.Header {
+		if !strings.HasPrefix(http.CanonicalHeaderKey(key), "Content-") {
+			lookup.Header[key] = values
+		}
+	}
+	response, err := t.wrapped.RoundTrip(lookup)
+	if err != nil {
+		return
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		err = fmt.Errorf("status is %d", response.StatusCode)
+		return
+	}
+	var list struct {
+		Items []struct {
+			DisplayName string `json:"display_name"`
+			Labels      []struct {
+				Key   string `json:"key"`
+				Value string `json:"value"`
+			} `json:"labels"`
+		} `json:"items"`
+	}
+	err = json.Unmarshal(body, &list)
+	if err != nil || len(list.Items) == 0 {
+		return
+	}
+	item := list.Items[0]
+	if item.DisplayName != "" {
+		name = item.DisplayName
+	}
+	for _, label := range item.Labels {
+		if label.Key == LabelKey && label.Value == LabelValue {
+			protected = true
+			break
+		}
+	}
+	return
+}
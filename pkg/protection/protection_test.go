@@ -0,0 +1,170 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protection
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Protection", func() {
+	It("Accepts valid modes", func() {
+		Expect(ValidMode("")).To(BeTrue())
+		Expect(ValidMode(ModeOff)).To(BeTrue())
+		Expect(ValidMode(ModeConfirm)).To(BeTrue())
+		Expect(ValidMode(ModeFlag)).To(BeTrue())
+		Expect(ValidMode("junk")).To(BeFalse())
+	})
+
+	Describe("Transport", func() {
+		var server *httptest.Server
+		var lookups, changes int32
+
+		BeforeEach(func() {
+			atomic.StoreInt32(&lookups, 0)
+			atomic.StoreInt32(&changes, 0)
+			server = httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != "/api/accounts_mgmt/v1/subscriptions" {
+						atomic.AddInt32(&changes, 1)
+						w.WriteHeader(http.StatusOK)
+						return
+					}
+					atomic.AddInt32(&lookups, 1)
+					Expect(r.Header.Get("Authorization")).To(Equal("Bearer my-token"))
+					Expect(r.URL.Query().Get("fetchLabels")).To(Equal("true"))
+					var labels string
+					switch r.URL.Query().Get("search") {
+					case "cluster_id = 'prod'":
+						labels = `[{"key": "protection", "value": "production"}]`
+					case "cluster_id = 'dev'":
+						labels = `[{"key": "protection", "value": "none"}]`
+					default:
+						w.Header().Set("Content-Type", "application/json")
+						fmt.Fprint(w, `{"items": []}`)
+						return
+					}
+					w.Header().Set("Content-Type", "application/json")
+					fmt.Fprintf(w, `{"items": [{"display_name": "my-cluster", "labels": %s}]}`, labels)
+				},
+			))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		makeClient := func(ask Asker) *http.Client {
+			return &http.Client{
+				Transport: TransportWrapper(server.URL+"/token", ask)(http.DefaultTransport),
+			}
+		}
+
+		send := func(client *http.Client, method, path string) error {
+			request, err := http.NewRequest(method, server.URL+path, strings.NewReader("{}"))
+			Expect(err).ToNot(HaveOccurred())
+			request.Header.Set("Authorization", "Bearer my-token")
+			response, err := client.Do(request)
+			if err == nil {
+				response.Body.Close()
+			}
+			return err
+		}
+
+		It("Rejects changes to production clusters", func() {
+			client := makeClient(nil)
+			err := send(client, http.MethodDelete, "/api/clusters_mgmt/v1/clusters/prod")
+			Expect(err).To(HaveOccurred())
+			var protectionErr *Error
+			Expect(errors.As(err, &protectionErr)).To(BeTrue())
+			Expect(protectionErr.Cluster).To(Equal("my-cluster"))
+			Expect(err.Error()).To(ContainSubstring("--i-know-this-is-production"))
+			Expect(atomic.LoadInt32(&changes)).To(BeZero())
+		})
+
+		It("Rejects changes to the sub-resources of production clusters", func() {
+			client := makeClient(nil)
+			err := send(
+				client,
+				http.MethodPost,
+				"/api/clusters_mgmt/v1/clusters/prod/machine_pools",
+			)
+			Expect(err).To(HaveOccurred())
+			Expect(atomic.LoadInt32(&changes)).To(BeZero())
+		})
+
+		It("Accepts changes to other clusters", func() {
+			client := makeClient(nil)
+			Expect(send(client, http.MethodPatch, "/api/clusters_mgmt/v1/clusters/dev")).To(Succeed())
+			Expect(send(client, http.MethodPatch, "/api/clusters_mgmt/v1/clusters/new")).To(Succeed())
+			Expect(atomic.LoadInt32(&changes)).To(BeNumerically("==", 2))
+		})
+
+		It("Doesn't check requests that don't change anything", func() {
+			client := makeClient(nil)
+			Expect(send(client, http.MethodGet, "/api/clusters_mgmt/v1/clusters/prod")).To(Succeed())
+			Expect(send(
+				client,
+				http.MethodDelete,
+				"/api/clusters_mgmt/v1/clusters/prod?dryRun=true",
+			)).To(Succeed())
+			Expect(send(client, http.MethodPost, "/api/clusters_mgmt/v1/clusters")).To(Succeed())
+			Expect(atomic.LoadInt32(&lookups)).To(BeZero())
+		})
+
+		It("Asks for confirmation only once for each cluster", func() {
+			asked := 0
+			client := makeClient(func(cluster string) (bool, error) {
+				Expect(cluster).To(Equal("my-cluster"))
+				asked++
+				return true, nil
+			})
+			path := "/api/clusters_mgmt/v1/clusters/prod"
+			Expect(send(client, http.MethodPatch, path)).To(Succeed())
+			Expect(send(client, http.MethodPatch, path)).To(Succeed())
+			Expect(asked).To(Equal(1))
+			Expect(atomic.LoadInt32(&lookups)).To(BeNumerically("==", 1))
+			Expect(atomic.LoadInt32(&changes)).To(BeNumerically("==", 2))
+		})
+
+		It("Rejects the change if the user doesn't confirm", func() {
+			client := makeClient(func(cluster string) (bool, error) {
+				return false, nil
+			})
+			err := send(client, http.MethodDelete, "/api/clusters_mgmt/v1/clusters/prod")
+			Expect(err).To(HaveOccurred())
+			Expect(atomic.LoadInt32(&changes)).To(BeZero())
+		})
+
+		It("Accepts the change when the flag is used", func() {
+			confirmed = true
+			defer func() {
+				confirmed = false
+			}()
+			client := makeClient(nil)
+			Expect(send(client, http.MethodDelete, "/api/clusters_mgmt/v1/clusters/prod")).To(Succeed())
+			Expect(atomic.LoadInt32(&lookups)).To(BeZero())
+		})
+	})
+})
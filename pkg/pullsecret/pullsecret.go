@@ -0,0 +1,162 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pullsecret contains functions used to validate and compare pull secrets, the docker
+// configuration JSON documents that contain the credentials used by clusters to pull images.
+package pullsecret
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Credentials are the credentials for one registry.
+type Credentials struct {
+	Auth     string `json:"auth,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
+
+// PullSecret contains the credentials for each registry, indexed by registry name.
+type PullSecret map[string]*Credentials
+
+// Parse parses and validates a pull secret. It must be a JSON object with an 'auths' field
+// containing the credentials for at least one registry, and each credential must contain either
+// the base64 encoded 'auth' field, with the user name and password separated by a colon, or
+// the 'username' and 'password' fields.
+func Parse(data []byte) (result PullSecret, err error) {
+	var document struct {
+		Auths map[string]*Credentials `json:"auths"`
+	}
+	err = json.Unmarshal(data, &document)
+	if err != nil {
+		err = fmt.Errorf("pull secret isn't a valid docker configuration JSON document: %v", err)
+		return
+	}
+	if len(document.Auths) == 0 {
+		err = fmt.Errorf("pull secret doesn't contain credentials for any registry")
+		return
+	}
+	registries := make([]string, 0, len(document.Auths))
+	for registry := range document.Auths {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+	for _, registry := range registries {
+		err = validate(registry, document.Auths[registry])
+		if err != nil {
+			return
+		}
+	}
+	result = document.Auths
+	return
+}
+
+func validate(registry string, credentials *Credentials) error {
+	if strings.TrimSpace(registry) == "" {
+		return fmt.Errorf("pull secret contains a registry with an empty name")
+	}
+	if credentials == nil {
+		return fmt.Errorf("credentials for registry '%s' are empty", registry)
+	}
+	if credentials.Auth == "" {
+		if credentials.Username == "" || credentials.Password == "" {
+			return fmt.Errorf(
+				"credentials for registry '%s' must contain the 'auth' field or "+
+					"the 'username' and 'password' fields",
+				registry,
+			)
+		}
+		return nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(credentials.Auth)
+	if err != nil {
+		return fmt.Errorf(
+			"field 'auth' of the credentials for registry '%s' isn't valid base64",
+			registry,
+		)
+	}
+	if strings.Index(string(decoded), ":") < 1 {
+		return fmt.Errorf(
+			"field 'auth' of the credentials for registry '%s' must contain the user "+
+				"name and the password separated by a colon",
+			registry,
+		)
+	}
+	return nil
+}
+
+// Change describes how the credentials of a registry changed between two pull secrets.
+type Change string
+
+// Kinds of changes:
+const (
+	Added     Change = "added"
+	Removed   Change = "removed"
+	Changed   Change = "changed"
+	Unchanged Change = "unchanged"
+)
+
+// RegistryChange describes how the credentials of a registry changed.
+type RegistryChange struct {
+	Registry string
+	Change   Change
+}
+
+// Diff compares two pull secrets and returns the changes for each registry, sorted by registry
+// name. Only the registry names are returned, never the credentials.
+func Diff(current, updated PullSecret) []RegistryChange {
+	var names []string
+	for name := range current {
+		names = append(names, name)
+	}
+	for name := range updated {
+		if _, ok := current[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	result := make([]RegistryChange, 0, len(names))
+	for _, name := range names {
+		before, inCurrent := current[name]
+		after, inUpdated := updated[name]
+		change := Unchanged
+		switch {
+		case !inCurrent:
+			change = Added
+		case !inUpdated:
+			change = Removed
+		case !equal(before, after):
+			change = Changed
+		}
+		result = append(result, RegistryChange{
+			Registry: name,
+			Change:   change,
+		})
+	}
+	return result
+}
+
+func equal(a, b *Credentials) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
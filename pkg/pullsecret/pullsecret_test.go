@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pullsecret
+
+import (
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Parse", func() {
+	It("Accepts 'auth' and 'username' credentials", func() {
+		result, err := Parse([]byte(`{
+			"auths": {
+				"quay.io": {
+					"auth": "bXl1c2VyOm15cGFzcw==",
+					"email": "me@example.com"
+				},
+				"registry.example.com": {
+					"username": "myuser",
+					"password": "mypass"
+				}
+			}
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(HaveLen(2))
+		Expect(result["quay.io"].Email).To(Equal("me@example.com"))
+		Expect(result["registry.example.com"].Username).To(Equal("myuser"))
+	})
+
+	It("Rejects documents that aren't JSON", func() {
+		_, err := Parse([]byte(`junk`))
+		Expect(err).To(MatchError(ContainSubstring(
+			"pull secret isn't a valid docker configuration JSON document",
+		)))
+	})
+
+	It("Rejects documents without registries", func() {
+		_, err := Parse([]byte(`{"auths": {}}`))
+		Expect(err).To(MatchError("pull secret doesn't contain credentials for any registry"))
+	})
+
+	It("Rejects 'auth' fields that aren't base64", func() {
+		_, err := Parse([]byte(`{"auths": {"quay.io": {"auth": "***"}}}`))
+		Expect(err).To(MatchError(
+			"field 'auth' of the credentials for registry 'quay.io' isn't valid base64",
+		))
+	})
+
+	It("Rejects 'auth' fields without colon", func() {
+		// This is the base64 encoding of 'myuser':
+		_, err := Parse([]byte(`{"auths": {"quay.io": {"auth": "bXl1c2Vy"}}}`))
+		Expect(err).To(MatchError(ContainSubstring(
+			"must contain the user name and the password separated by a colon",
+		)))
+	})
+
+	It("Rejects credentials without password", func() {
+		_, err := Parse([]byte(`{"auths": {"quay.io": {"username": "myuser"}}}`))
+		Expect(err).To(MatchError(
+			"credentials for registry 'quay.io' must contain the 'auth' field or the " +
+				"'username' and 'password' fields",
+		))
+	})
+})
+
+var _ = Describe("Diff", func() {
+	It("Classifies the changes of each registry", func() {
+		current := PullSecret{
+			"a.io": {Auth: "YTpi"},
+			"b.io": {Auth: "YTpi"},
+			"c.io": {Auth: "YTpi"},
+		}
+		updated := PullSecret{
+			"a.io": {Auth: "YTpi"},
+			"b.io": {Auth: "YTpj"},
+			"d.io": {Auth: "YTpi"},
+		}
+		Expect(Diff(current, updated)).To(Equal([]RegistryChange{
+			{Registry: "a.io", Change: Unchanged},
+			{Registry: "b.io", Change: Changed},
+			{Registry: "c.io", Change: Removed},
+			{Registry: "d.io", Change: Added},
+		}))
+	})
+})
@@ -0,0 +1,157 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to implement the read-only mode, where commands that
+// change the server are rejected, so that shared terminals and demo environments can't
+// accidentally change anything.
+
+package readonly
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// EnvVar is the name of the environment variable that enables the read-only mode.
+const EnvVar = "OCM_READ_ONLY"
+
+// annotation is the key of the annotation used to mark the commands that change the server.
+const annotation = "ocm.openshift.com/mutating"
+
+// flagAnnotation is the key of the annotation used to mark the commands that change the server
+// only when some flags are used. The value is the comma separated list of names of the flags.
+const flagAnnotation = "ocm.openshift.com/mutating-flag"
+
+// EnvEnabled checks if the read-only mode is enabled with the environment variable. Values that
+// can't be parsed as booleans also enable it, as failing to detect the mode would be worse than
+// rejecting a command.
+func EnvEnabled() bool {
+	value := os.Getenv(EnvVar)
+	if value == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(value)
+	return err != nil || enabled
+}
+
+// Mark marks the given command, and all its sub-commands, as commands that change the server, so
+// that they are rejected before doing anything when the read-only mode is enabled.
+func Mark(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[annotation] = "true"
+}
+
+// MarkFlag marks the given command as a command that changes the server only when the given
+// flag is used, like the '--apply' option of commands that otherwise only check objects. It can
+// be called multiple times for commands that have several of those flags.
+func MarkFlag(cmd *cobra.Command, name string) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	if names := cmd.Annotations[flagAnnotation]; names != "" {
+		name = names + "," + name
+	}
+	cmd.Annotations[flagAnnotation] = name
+}
+
+// Marked checks if the given command, or any of its parents, has been marked as a command that
+// changes the server, or if the command has been marked with MarkFlag and the flag is used.
+func Marked(cmd *cobra.Command) bool {
+	if names := cmd.Annotations[flagAnnotation]; names != "" {
+		for _, name := range strings.Split(names, ",") {
+			flag := cmd.Flags().Lookup(name)
+			if flag != nil && flag.Changed && flag.Value.String() != "false" {
+				return true
+			}
+		}
+	}
+	for current := cmd; current != nil; current = current.Parent() {
+		if current.Annotations[annotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns an error if the read-only mode is enabled and the given command changes the
+// server. Commands that have a '--dry-run' flag are allowed when that flag is set, as the server
+// doesn't change anything in that case.
+func Check(cmd *cobra.Command, enabled bool) error {
+	if !enabled || !Marked(cmd) {
+		return nil
+	}
+	flag := cmd.Flags().Lookup("dry-run")
+	if flag != nil && flag.Value.String() == "true" {
+		return nil
+	}
+	return fmt.Errorf(
+		"Command '%s' changes the server and read-only mode is enabled. %s",
+		cmd.CommandPath(), disableHint,
+	)
+}
+
+// disableHint explains how to disable the read-only mode.
+const disableHint = "To disable it unset the '" + EnvVar + "' environment variable and " +
+	"the 'read_only' configuration setting."
+
+// TransportWrapper returns a transport wrapper that rejects the requests that may change the
+// server, without sending them. Only the 'GET', 'HEAD' and 'OPTIONS' methods are allowed, as
+// well as dry run requests. Requests sent to the token URL are always allowed, as they are
+// needed for authentication.
+func TransportWrapper(tokenURL string) func(http.RoundTripper) http.RoundTripper {
+	return func(wrapped http.RoundTripper) http.RoundTripper {
+		return &roundTripper{
+			tokenURL: tokenURL,
+			wrapped:  wrapped,
+		}
+	}
+}
+
+type roundTripper struct {
+	tokenURL string
+	wrapped  http.RoundTripper
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*roundTripper)(nil)
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	switch {
+	case strings.HasPrefix(request.URL.String(), t.tokenURL):
+	case request.Method == http.MethodGet:
+	case request.Method == http.MethodHead:
+	case request.Method == http.MethodOptions:
+	case request.URL.Query().Get("dryRun") == "true":
+	default:
+		if request.Body != nil {
+			request.Body.Close()
+		}
+		err = fmt.Errorf(
+			"Read-only mode is enabled, refusing to send '%s' request to '%s'. %s",
+			request.Method, request.URL.Path, disableHint,
+		)
+		return
+	}
+	return t.wrapped.RoundTrip(request)
+}
@@ -0,0 +1,166 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readonly
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Read only", func() {
+	Describe("Environment variable", func() {
+		AfterEach(func() {
+			os.Unsetenv(EnvVar)
+		})
+
+		DescribeTable(
+			"Parses the value",
+			func(value string, expected bool) {
+				os.Setenv(EnvVar, value)
+				Expect(EnvEnabled()).To(Equal(expected))
+			},
+			Entry("Empty", "", false),
+			Entry("One", "1", true),
+			Entry("True", "true", true),
+			Entry("Zero", "0", false),
+			Entry("False", "false", false),
+			Entry("Junk", "yes please", true),
+		)
+	})
+
+	Describe("Commands", func() {
+		var parent *cobra.Command
+		var child *cobra.Command
+
+		BeforeEach(func() {
+			parent = &cobra.Command{Use: "delete"}
+			child = &cobra.Command{Use: "cluster"}
+			child.Flags().Bool("dry-run", false, "")
+			parent.AddCommand(child)
+		})
+
+		It("Accepts commands that aren't marked", func() {
+			Expect(Check(child, true)).To(Succeed())
+		})
+
+		It("Accepts marked commands when disabled", func() {
+			Mark(parent)
+			Expect(Check(child, false)).To(Succeed())
+		})
+
+		It("Rejects commands marked with a flag only when the flag is used", func() {
+			child.Flags().Bool("apply", false, "")
+			MarkFlag(child, "apply")
+			Expect(Check(child, true)).To(Succeed())
+			Expect(child.Flags().Set("apply", "false")).To(Succeed())
+			Expect(Check(child, true)).To(Succeed())
+			Expect(child.Flags().Set("apply", "true")).To(Succeed())
+			Expect(Check(child, true)).ToNot(Succeed())
+		})
+
+		It("Rejects commands marked with several flags when any of them is used", func() {
+			child.Flags().StringArray("set", nil, "")
+			child.Flags().StringArray("unset", nil, "")
+			MarkFlag(child, "set")
+			MarkFlag(child, "unset")
+			Expect(Check(child, true)).To(Succeed())
+			Expect(child.Flags().Set("unset", "x")).To(Succeed())
+			Expect(Check(child, true)).ToNot(Succeed())
+		})
+
+		It("Rejects sub-commands of marked commands", func() {
+			Mark(parent)
+			err := Check(child, true)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix(
+				"Command 'delete cluster' changes the server and read-only mode is enabled",
+			))
+		})
+
+		It("Accepts dry runs", func() {
+			Mark(parent)
+			Expect(child.Flags().Set("dry-run", "true")).To(Succeed())
+			Expect(Check(child, true)).To(Succeed())
+		})
+	})
+
+	Describe("Transport", func() {
+		var server *httptest.Server
+		var client *http.Client
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				},
+			))
+			client = &http.Client{
+				Transport: TransportWrapper(server.URL + "/token")(http.DefaultTransport),
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		send := func(method, path string) error {
+			request, err := http.NewRequest(method, server.URL+path, strings.NewReader("{}"))
+			Expect(err).ToNot(HaveOccurred())
+			response, err := client.Do(request)
+			if err == nil {
+				response.Body.Close()
+			}
+			return err
+		}
+
+		It("Sends requests that don't change anything", func() {
+			Expect(send(http.MethodGet, "/api/clusters_mgmt/v1/clusters")).To(Succeed())
+			Expect(send(http.MethodHead, "/api/clusters_mgmt/v1/clusters")).To(Succeed())
+		})
+
+		It("Sends requests to the token URL", func() {
+			Expect(send(http.MethodPost, "/token")).To(Succeed())
+		})
+
+		It("Sends dry run requests", func() {
+			Expect(send(http.MethodPost, "/api/clusters_mgmt/v1/clusters?dryRun=true")).To(Succeed())
+		})
+
+		It("Rejects requests that may change the server", func() {
+			for _, method := range []string{
+				http.MethodDelete,
+				http.MethodPatch,
+				http.MethodPost,
+				http.MethodPut,
+			} {
+				err := send(method, "/api/clusters_mgmt/v1/clusters/123")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(
+					"Read-only mode is enabled, refusing to send '" + method +
+						"' request to '/api/clusters_mgmt/v1/clusters/123'",
+				))
+			}
+		})
+	})
+})
@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redact contains functions used to replace personal data, like user names and email
+// addresses, with hashes, so that reports can be shared without exposing that data.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+)
+
+// KeyEnvVar is the name of the environment variable that contains the optional key used to
+// calculate the hashes. Without a key anyone can check if a report contains a known email address
+// calculating its hash, so it is recommended to use one when sharing reports with third parties.
+const KeyEnvVar = "OCM_REDACT_KEY"
+
+// hashLength is the number of hexadecimal digits of the hashes.
+const hashLength = 12
+
+// Redactor replaces personal data with hashes. The same value always produces the same hash when
+// using the same key, so redacted reports can still be compared and correlated. Don't create
+// instances of this type directly; use the New or NewFromEnv functions instead.
+type Redactor struct {
+	key []byte
+}
+
+// New creates a redactor that uses the given key to calculate the hashes.
+func New(key string) *Redactor {
+	return &Redactor{
+		key: []byte(key),
+	}
+}
+
+// NewFromEnv creates a redactor that uses the key from the 'OCM_REDACT_KEY' environment
+// variable, if any.
+func NewFromEnv() *Redactor {
+	return New(os.Getenv(KeyEnvVar))
+}
+
+// Hash returns the hash of the given value. Empty values are returned unchanged, so that it is
+// still visible that there is no value.
+func (r *Redactor) Hash(value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:hashLength]
+}
+
+// Username replaces a user name.
+func (r *Redactor) Username(value string) string {
+	return r.prefixed("user-", value)
+}
+
+// Name replaces the name of a person or of an organization.
+func (r *Redactor) Name(value string) string {
+	return r.prefixed("name-", value)
+}
+
+// Email replaces an email address with another one that has a hashed local part and the reserved
+// 'redacted.invalid' domain, so that it still looks like an email address. Addresses are compared
+// ignoring case, so addresses that differ only in case produce the same result.
+func (r *Redactor) Email(value string) string {
+	if value == "" {
+		return ""
+	}
+	return r.Hash(strings.ToLower(strings.TrimSpace(value))) + "@redacted.invalid"
+}
+
+func (r *Redactor) prefixed(prefix, value string) string {
+	if value == "" {
+		return ""
+	}
+	return prefix + r.Hash(value)
+}
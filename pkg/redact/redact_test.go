@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Redactor", func() {
+	It("Returns the same hash for the same value", func() {
+		redactor := New("my-key")
+		Expect(redactor.Username("alice")).To(Equal(New("my-key").Username("alice")))
+	})
+
+	It("Returns different hashes for different values", func() {
+		redactor := New("my-key")
+		Expect(redactor.Username("alice")).ToNot(Equal(redactor.Username("bob")))
+	})
+
+	It("Returns different hashes for different keys", func() {
+		Expect(New("my-key").Hash("alice")).ToNot(Equal(New("your-key").Hash("alice")))
+	})
+
+	It("Adds a prefix to user names and names", func() {
+		redactor := New("")
+		Expect(redactor.Username("alice")).To(MatchRegexp(`^user-[0-9a-f]{12}$`))
+		Expect(redactor.Name("Alice")).To(MatchRegexp(`^name-[0-9a-f]{12}$`))
+	})
+
+	It("Replaces email addresses ignoring case", func() {
+		redactor := New("")
+		email := redactor.Email("alice@example.com")
+		Expect(email).To(MatchRegexp(`^[0-9a-f]{12}@redacted\.invalid$`))
+		Expect(redactor.Email("Alice@Example.COM")).To(Equal(email))
+	})
+
+	It("Preserves empty values", func() {
+		redactor := New("my-key")
+		Expect(redactor.Hash("")).To(BeEmpty())
+		Expect(redactor.Username("")).To(BeEmpty())
+		Expect(redactor.Name("")).To(BeEmpty())
+		Expect(redactor.Email("")).To(BeEmpty())
+	})
+})
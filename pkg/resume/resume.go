@@ -0,0 +1,176 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package resume contains the types and functions used to remember the progress of long
+// paginated scans and of sequences of steps, so that an operation that was interrupted can
+// continue where it stopped instead of starting again from the beginning.
+package resume
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+)
+
+// Scan stores the progress of a paginated scan in a state file. The name of the file is derived
+// from the parts that identify the scan, typically the name of the command, the URL of the server
+// and the search query, so that different scans don't interfere with each other.
+type Scan struct {
+	key  string
+	file string
+	seen map[string]bool
+}
+
+// state is the content of the state file.
+type state struct {
+	Key     string    `json:"key"`
+	Page    int       `json:"page,omitempty"`
+	Seen    []string  `json:"seen,omitempty"`
+	Step    int       `json:"step,omitempty"`
+	Updated time.Time `json:"updated"`
+}
+
+// Location returns the directory where the state files are stored. The 'OCM_RESUME_DIR'
+// environment variable can be used to change it.
+func Location() string {
+	if dir := os.Getenv("OCM_RESUME_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "ocm-resume")
+}
+
+// NewScan creates the object that stores the progress of the scan identified by the given parts.
+func NewScan(parts ...string) *Scan {
+	key, file := stateFile(parts)
+	return &Scan{
+		key:  key,
+		file: file,
+	}
+}
+
+// Page returns the page where the scan should continue. If there is no saved progress it returns
+// the first page.
+func (s *Scan) Page() (page int, err error) {
+	page = 1
+	saved, err := readState(s.file, s.key)
+	if err != nil || saved == nil {
+		return
+	}
+	if saved.Page > 1 {
+		page = saved.Page
+		if s.seen != nil {
+			for _, id := range saved.Seen {
+				s.seen[id] = true
+			}
+		}
+	}
+	return
+}
+
+// Track makes the scan save the given set of identifiers together with the page, and restore the
+// saved ones into it when the Page method is called. It is intended for scans that skip the
+// items that were already processed in previous pages, so that they are also skipped when the
+// scan is resumed. It should be called before the Page method.
+func (s *Scan) Track(seen map[string]bool) {
+	s.seen = seen
+}
+
+// Save remembers that the scan should continue in the given page. It should be called after
+// processing completely the previous page.
+func (s *Scan) Save(page int) error {
+	var seen []string
+	for id := range s.seen {
+		seen = append(seen, id)
+	}
+	sort.Strings(seen)
+	return writeState(s.file, &state{
+		Key:  s.key,
+		Page: page,
+		Seen: seen,
+	})
+}
+
+// Done removes the saved progress, to be called when the scan finishes.
+func (s *Scan) Done() error {
+	return removeState(s.file)
+}
+
+// stateFile calculates the key and the name of the state file from the parts that identify the
+// operation.
+func stateFile(parts []string) (key, file string) {
+	key = strings.Join(parts, "\x00")
+	sum := sha256.Sum256([]byte(key))
+	file = filepath.Join(Location(), hex.EncodeToString(sum[:])+".json")
+	return
+}
+
+// readState reads the state file. It returns nil if the file doesn't exist or if it belongs to a
+// different operation.
+func readState(file, key string) (result *state, err error) {
+	// #nosec G304
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		err = nil
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("can't read state file '%s': %v", file, err)
+		return
+	}
+	saved := &state{}
+	err = json.Unmarshal(data, saved)
+	if err != nil {
+		err = fmt.Errorf("can't parse state file '%s': %v", file, err)
+		return
+	}
+	if saved.Key == key {
+		result = saved
+	}
+	return
+}
+
+// writeState writes the state file, creating the directory if needed.
+func writeState(file string, saved *state) error {
+	dir := filepath.Dir(file)
+	err := os.MkdirAll(dir, os.FileMode(0700))
+	if err != nil {
+		return fmt.Errorf("can't create directory %s: %v", dir, err)
+	}
+	saved.Updated = time.Now().UTC()
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("can't marshal state: %v", err)
+	}
+	return config.WriteFile(file, data)
+}
+
+// removeState removes the state file, if it exists.
+func removeState(file string) error {
+	err := os.Remove(file)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("can't remove state file '%s': %v", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resume
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Scan", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-resume-*")
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("OCM_RESUME_DIR", tmpDir)
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("OCM_RESUME_DIR")
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Starts in the first page if there is no saved progress", func() {
+		page, err := NewScan("users", "my-org").Page()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(Equal(1))
+	})
+
+	It("Continues in the saved page", func() {
+		Expect(NewScan("users", "my-org").Save(7)).To(Succeed())
+		page, err := NewScan("users", "my-org").Page()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(Equal(7))
+	})
+
+	It("Doesn't mix the progress of different scans", func() {
+		Expect(NewScan("users", "my-org").Save(7)).To(Succeed())
+		page, err := NewScan("users", "your-org").Page()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(Equal(1))
+	})
+
+	It("Restores the tracked identifiers when resumed", func() {
+		scan := NewScan("users", "my-org")
+		scan.Track(map[string]bool{"a1": true, "a2": true})
+		Expect(scan.Save(7)).To(Succeed())
+		seen := map[string]bool{}
+		scan = NewScan("users", "my-org")
+		scan.Track(seen)
+		page, err := scan.Page()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(Equal(7))
+		Expect(seen).To(Equal(map[string]bool{"a1": true, "a2": true}))
+	})
+
+	It("Forgets the progress when the scan is done", func() {
+		scan := NewScan("users", "my-org")
+		Expect(scan.Save(7)).To(Succeed())
+		Expect(scan.Done()).To(Succeed())
+		page, err := scan.Page()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(page).To(Equal(1))
+		Expect(scan.Done()).To(Succeed())
+	})
+})
+
+var _ = Describe("Steps", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-resume-*")
+		Expect(err).ToNot(HaveOccurred())
+		os.Setenv("OCM_RESUME_DIR", tmpDir)
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("OCM_RESUME_DIR")
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Has no completed steps if there is no saved progress", func() {
+		count, err := NewSteps("migrate", "my-cluster").Completed()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(BeZero())
+	})
+
+	It("Returns the saved number of completed steps", func() {
+		Expect(NewSteps("migrate", "my-cluster").Save(2)).To(Succeed())
+		count, err := NewSteps("migrate", "my-cluster").Completed()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(2))
+	})
+
+	It("Doesn't mix the progress of different sequences", func() {
+		Expect(NewSteps("migrate", "my-cluster").Save(2)).To(Succeed())
+		count, err := NewSteps("migrate", "your-cluster").Completed()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(BeZero())
+	})
+
+	It("Forgets the progress when the steps are done", func() {
+		steps := NewSteps("migrate", "my-cluster")
+		Expect(steps.Save(2)).To(Succeed())
+		Expect(steps.Done()).To(Succeed())
+		count, err := steps.Completed()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(BeZero())
+	})
+})
@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resume
+
+// Steps stores the progress of a sequence of steps in a state file, so that a sequence that
+// failed in the middle can be run again skipping the steps that were already completed. As with
+// scans, the name of the file is derived from the parts that identify the sequence.
+type Steps struct {
+	key  string
+	file string
+}
+
+// NewSteps creates the object that stores the progress of the sequence of steps identified by the
+// given parts.
+func NewSteps(parts ...string) *Steps {
+	key, file := stateFile(parts)
+	return &Steps{
+		key:  key,
+		file: file,
+	}
+}
+
+// Completed returns the number of steps that were completed. If there is no saved progress it
+// returns zero.
+func (s *Steps) Completed() (count int, err error) {
+	saved, err := readState(s.file, s.key)
+	if err != nil || saved == nil {
+		return
+	}
+	count = saved.Step
+	return
+}
+
+// Save remembers that the given number of steps have been completed.
+func (s *Steps) Save(count int) error {
+	return writeState(s.file, &state{
+		Key:  s.key,
+		Step: count,
+	})
+}
+
+// Done removes the saved progress, to be called when all the steps have been completed.
+func (s *Steps) Done() error {
+	return removeState(s.file)
+}
@@ -0,0 +1,410 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the data that the sandbox server contains when it starts.
+
+package sandbox
+
+// seed contains the initial objects of the sandbox server, indexed by the path of the collection
+// that contains them. The 'kind' and 'href' attributes are added automatically.
+const seed = `{
+	"/api/accounts_mgmt/v1/organizations": [
+		{
+			"id": "org-1",
+			"name": "Sandbox",
+			"external_id": "1000001"
+		},
+		{
+			"id": "org-2",
+			"name": "Sandbox Partner",
+			"external_id": "1000002"
+		}
+	],
+	"/api/accounts_mgmt/v1/accounts": [
+		{
+			"id": "acc-1",
+			"username": "alice",
+			"email": "alice@example.com",
+			"first_name": "Alice",
+			"last_name": "Sandbox",
+			"organization_id": "org-1",
+			"organization": {
+				"kind": "Organization",
+				"id": "org-1",
+				"name": "Sandbox"
+			}
+		},
+		{
+			"id": "acc-2",
+			"username": "bob",
+			"email": "bob@example.com",
+			"first_name": "Bob",
+			"last_name": "Sandbox",
+			"organization_id": "org-1",
+			"organization": {
+				"kind": "Organization",
+				"id": "org-1",
+				"name": "Sandbox"
+			}
+		},
+		{
+			"id": "acc-3",
+			"username": "carol",
+			"email": "carol@example.com",
+			"first_name": "Carol",
+			"last_name": "Partner",
+			"organization_id": "org-2",
+			"organization": {
+				"kind": "Organization",
+				"id": "org-2",
+				"name": "Sandbox Partner"
+			}
+		}
+	],
+	"/api/accounts_mgmt/v1/roles": [
+		{
+			"id": "OrganizationAdmin",
+			"name": "Organization Admin"
+		},
+		{
+			"id": "ClusterEditor",
+			"name": "Cluster Editor"
+		},
+		{
+			"id": "ClusterViewer",
+			"name": "Cluster Viewer"
+		}
+	],
+	"/api/accounts_mgmt/v1/role_bindings": [
+		{
+			"id": "rb-1",
+			"type": "Organization",
+			"account_id": "acc-1",
+			"role_id": "OrganizationAdmin",
+			"organization_id": "org-1",
+			"account": {
+				"kind": "AccountLink",
+				"id": "acc-1"
+			},
+			"role": {
+				"kind": "RoleLink",
+				"id": "OrganizationAdmin"
+			},
+			"organization": {
+				"kind": "OrganizationLink",
+				"id": "org-1"
+			}
+		},
+		{
+			"id": "rb-2",
+			"type": "Organization",
+			"account_id": "acc-2",
+			"role_id": "ClusterEditor",
+			"organization_id": "org-1",
+			"account": {
+				"kind": "AccountLink",
+				"id": "acc-2"
+			},
+			"role": {
+				"kind": "RoleLink",
+				"id": "ClusterEditor"
+			},
+			"organization": {
+				"kind": "OrganizationLink",
+				"id": "org-1"
+			}
+		},
+		{
+			"id": "rb-3",
+			"type": "Organization",
+			"account_id": "acc-3",
+			"role_id": "OrganizationAdmin",
+			"organization_id": "org-2",
+			"account": {
+				"kind": "AccountLink",
+				"id": "acc-3"
+			},
+			"role": {
+				"kind": "RoleLink",
+				"id": "OrganizationAdmin"
+			},
+			"organization": {
+				"kind": "OrganizationLink",
+				"id": "org-2"
+			}
+		}
+	],
+	"/api/accounts_mgmt/v1/subscriptions": [
+		{
+			"id": "sub-1",
+			"cluster_id": "cls-1",
+			"external_cluster_id": "e4b9f2d6-0d0c-4c1a-9d55-0a3c1f7e0001",
+			"display_name": "sandbox-aws",
+			"status": "Active",
+			"organization_id": "org-1",
+			"plan": {
+				"kind": "Plan",
+				"id": "OSD"
+			},
+			"creator": {
+				"kind": "Account",
+				"id": "acc-1",
+				"username": "alice"
+			}
+		},
+		{
+			"id": "sub-2",
+			"cluster_id": "cls-2",
+			"external_cluster_id": "e4b9f2d6-0d0c-4c1a-9d55-0a3c1f7e0002",
+			"display_name": "sandbox-gcp",
+			"status": "Active",
+			"organization_id": "org-1",
+			"plan": {
+				"kind": "Plan",
+				"id": "OSD"
+			},
+			"creator": {
+				"kind": "Account",
+				"id": "acc-2",
+				"username": "bob"
+			}
+		},
+		{
+			"id": "sub-3",
+			"cluster_id": "cls-3",
+			"external_cluster_id": "e4b9f2d6-0d0c-4c1a-9d55-0a3c1f7e0003",
+			"display_name": "sandbox-hibernating",
+			"status": "Active",
+			"organization_id": "org-2",
+			"plan": {
+				"kind": "Plan",
+				"id": "OSD"
+			},
+			"creator": {
+				"kind": "Account",
+				"id": "acc-3",
+				"username": "carol"
+			}
+		}
+	],
+	"/api/clusters_mgmt/v1/clusters": [
+		{
+			"id": "cls-1",
+			"name": "sandbox-aws",
+			"external_id": "e4b9f2d6-0d0c-4c1a-9d55-0a3c1f7e0001",
+			"state": "ready",
+			"product": {
+				"kind": "ProductLink",
+				"id": "osd"
+			},
+			"cloud_provider": {
+				"kind": "CloudProviderLink",
+				"id": "aws"
+			},
+			"region": {
+				"kind": "CloudRegionLink",
+				"id": "us-east-1"
+			},
+			"openshift_version": "4.10.20",
+			"version": {
+				"kind": "VersionLink",
+				"id": "openshift-v4.10.20",
+				"channel_group": "stable"
+			},
+			"multi_az": false,
+			"ccs": {
+				"enabled": true
+			},
+			"nodes": {
+				"compute": 2,
+				"compute_machine_type": {
+					"id": "m5.xlarge"
+				}
+			},
+			"network": {
+				"type": "OVNKubernetes",
+				"machine_cidr": "10.0.0.0/16",
+				"service_cidr": "172.30.0.0/16",
+				"pod_cidr": "10.128.0.0/14",
+				"host_prefix": 23
+			},
+			"api": {
+				"url": "https://api.sandbox-aws.example.com:6443",
+				"listening": "external"
+			},
+			"console": {
+				"url": "https://console-openshift-console.apps.sandbox-aws.example.com"
+			},
+			"subscription": {
+				"kind": "SubscriptionLink",
+				"id": "sub-1"
+			},
+			"creation_timestamp": "2022-06-01T10:00:00Z"
+		},
+		{
+			"id": "cls-2",
+			"name": "sandbox-gcp",
+			"external_id": "e4b9f2d6-0d0c-4c1a-9d55-0a3c1f7e0002",
+			"state": "ready",
+			"product": {
+				"kind": "ProductLink",
+				"id": "osd"
+			},
+			"cloud_provider": {
+				"kind": "CloudProviderLink",
+				"id": "gcp"
+			},
+			"region": {
+				"kind": "CloudRegionLink",
+				"id": "us-east1"
+			},
+			"openshift_version": "4.11.0",
+			"version": {
+				"kind": "VersionLink",
+				"id": "openshift-v4.11.0",
+				"channel_group": "stable"
+			},
+			"multi_az": true,
+			"ccs": {
+				"enabled": false
+			},
+			"nodes": {
+				"compute": 3,
+				"compute_machine_type": {
+					"id": "custom-4-16384"
+				}
+			},
+			"api": {
+				"url": "https://api.sandbox-gcp.example.com:6443",
+				"listening": "external"
+			},
+			"console": {
+				"url": "https://console-openshift-console.apps.sandbox-gcp.example.com"
+			},
+			"subscription": {
+				"kind": "SubscriptionLink",
+				"id": "sub-2"
+			},
+			"creation_timestamp": "2022-07-15T14:30:00Z"
+		},
+		{
+			"id": "cls-3",
+			"name": "sandbox-hibernating",
+			"external_id": "e4b9f2d6-0d0c-4c1a-9d55-0a3c1f7e0003",
+			"state": "hibernating",
+			"product": {
+				"kind": "ProductLink",
+				"id": "osd"
+			},
+			"cloud_provider": {
+				"kind": "CloudProviderLink",
+				"id": "aws"
+			},
+			"region": {
+				"kind": "CloudRegionLink",
+				"id": "eu-west-1"
+			},
+			"openshift_version": "4.10.20",
+			"version": {
+				"kind": "VersionLink",
+				"id": "openshift-v4.10.20",
+				"channel_group": "stable"
+			},
+			"multi_az": false,
+			"ccs": {
+				"enabled": true
+			},
+			"nodes": {
+				"compute": 2,
+				"compute_machine_type": {
+					"id": "m5.xlarge"
+				}
+			},
+			"api": {
+				"url": "https://api.sandbox-hibernating.example.com:6443",
+				"listening": "external"
+			},
+			"console": {
+				"url": "https://console-openshift-console.apps.sandbox-hibernating.example.com"
+			},
+			"subscription": {
+				"kind": "SubscriptionLink",
+				"id": "sub-3"
+			},
+			"creation_timestamp": "2022-08-20T08:15:00Z"
+		}
+	],
+	"/api/clusters_mgmt/v1/clusters/cls-1/machine_pools": [
+		{
+			"id": "infra",
+			"replicas": 2,
+			"instance_type": "r5.xlarge",
+			"labels": {
+				"node-role.kubernetes.io/infra": ""
+			}
+		}
+	],
+	"/api/clusters_mgmt/v1/versions": [
+		{
+			"id": "openshift-v4.10.20",
+			"raw_id": "4.10.20",
+			"enabled": true,
+			"default": false,
+			"channel_group": "stable"
+		},
+		{
+			"id": "openshift-v4.11.0",
+			"raw_id": "4.11.0",
+			"enabled": true,
+			"default": true,
+			"channel_group": "stable"
+		}
+	],
+	"/api/clusters_mgmt/v1/cloud_providers": [
+		{
+			"id": "aws",
+			"name": "aws",
+			"display_name": "AWS"
+		},
+		{
+			"id": "gcp",
+			"name": "gcp",
+			"display_name": "GCP"
+		}
+	],
+	"/api/clusters_mgmt/v1/cloud_providers/aws/regions": [
+		{
+			"id": "us-east-1",
+			"display_name": "US East, N. Virginia",
+			"enabled": true,
+			"supports_multi_az": true
+		},
+		{
+			"id": "eu-west-1",
+			"display_name": "EU, Ireland",
+			"enabled": true,
+			"supports_multi_az": true
+		}
+	],
+	"/api/clusters_mgmt/v1/cloud_providers/gcp/regions": [
+		{
+			"id": "us-east1",
+			"display_name": "US East, South Carolina",
+			"enabled": true,
+			"supports_multi_az": true
+		}
+	]
+}`
@@ -0,0 +1,375 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sandbox contains a simple in-memory implementation of the OCM API, seeded with a few
+// organizations, users and clusters, so that the commands can be exercised without connecting to a
+// real environment.
+package sandbox
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+
+	"github.com/openshift-online/ocm-cli/pkg/search"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
+)
+
+// currentAccount is the identifier of the account that the sandbox server considers authenticated,
+// regardless of the credentials used.
+const currentAccount = "acc-1"
+
+// Server is an HTTP handler that implements a subset of the OCM API. Objects are stored in memory
+// and organized in collections, indexed by their paths. Collections support listing, with the
+// 'search', 'page' and 'size' parameters, and creating objects. Objects support retrieving,
+// updating and deleting. Collections nested inside objects, like the machine pools of a cluster,
+// are created the first time that they are used.
+type Server struct {
+	lock        sync.Mutex
+	key         []byte
+	serial      int
+	collections map[string][]map[string]interface{}
+}
+
+// NewServer creates a new sandbox server containing the seed objects.
+func NewServer() (result *Server, err error) {
+	key := make([]byte, 32)
+	_, err = rand.Read(key)
+	if err != nil {
+		return
+	}
+	var collections map[string][]map[string]interface{}
+	err = json.Unmarshal([]byte(seed), &collections)
+	if err != nil {
+		return
+	}
+	for collectionPath, items := range collections {
+		for _, item := range items {
+			decorate(collectionPath, item)
+		}
+	}
+	result = &Server{
+		key:         key,
+		collections: collections,
+	}
+	return
+}
+
+// Make sure that we implement the interface:
+var _ http.Handler = (*Server)(nil)
+
+// ServeHTTP is the implementation of the HTTP handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	requestPath := strings.TrimSuffix(r.URL.Path, "/")
+	if requestPath == urls.SandboxTokenPath {
+		s.token(w, r)
+		return
+	}
+	if requestPath == "/api/accounts_mgmt/v1/current_account" {
+		requestPath = "/api/accounts_mgmt/v1/accounts/" + currentAccount
+	}
+
+	// Check if the path is a collection, or if it is a collection nested inside an object, that
+	// is created the first time it is used:
+	_, ok := s.collections[requestPath]
+	if !ok {
+		_, parent := s.find(path.Dir(requestPath))
+		if parent != nil {
+			switch path.Base(requestPath) {
+			case "hibernate":
+				s.action(w, r, parent, "hibernating")
+				return
+			case "resume":
+				s.action(w, r, parent, "resuming")
+				return
+			}
+			s.collections[requestPath] = nil
+			ok = true
+		}
+	}
+	if ok {
+		switch r.Method {
+		case http.MethodGet:
+			s.list(w, r, requestPath)
+		case http.MethodPost:
+			s.add(w, r, requestPath)
+		default:
+			sendError(w, http.StatusMethodNotAllowed, "Method '%s' isn't allowed", r.Method)
+		}
+		return
+	}
+
+	// Check if the path is an object:
+	index, item := s.find(requestPath)
+	if item == nil {
+		sendError(w, http.StatusNotFound, "Object '%s' doesn't exist", requestPath)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		sendJSON(w, http.StatusOK, item)
+	case http.MethodPatch:
+		var patch map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&patch)
+		if err != nil {
+			sendError(w, http.StatusBadRequest, "Can't parse body: %v", err)
+			return
+		}
+		merge(item, patch)
+		decorate(path.Dir(requestPath), item)
+		sendJSON(w, http.StatusOK, item)
+	case http.MethodDelete:
+		collectionPath := path.Dir(requestPath)
+		items := s.collections[collectionPath]
+		s.collections[collectionPath] = append(items[:index:index], items[index+1:]...)
+		sendEmpty(w)
+	default:
+		sendError(w, http.StatusMethodNotAllowed, "Method '%s' isn't allowed", r.Method)
+	}
+}
+
+// find returns the object that corresponds to the given path and its position inside its
+// collection, or nil if there is no such object.
+func (s *Server) find(objectPath string) (index int, result map[string]interface{}) {
+	items, ok := s.collections[path.Dir(objectPath)]
+	if !ok {
+		return
+	}
+	id := path.Base(objectPath)
+	for i, item := range items {
+		if item["id"] == id {
+			index = i
+			result = item
+			return
+		}
+	}
+	return
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, collectionPath string) {
+	query := r.URL.Query()
+	page, err := intParameter(query.Get("page"), 1)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "Invalid page: %v", err)
+		return
+	}
+	size, err := intParameter(query.Get("size"), 100)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "Invalid size: %v", err)
+		return
+	}
+	matches := []map[string]interface{}{}
+	for _, item := range s.collections[collectionPath] {
+		var match bool
+		match, err = search.Match(query.Get("search"), item)
+		if err != nil {
+			sendError(w, http.StatusBadRequest, "Invalid search: %v", err)
+			return
+		}
+		if match {
+			matches = append(matches, item)
+		}
+	}
+	start := (page - 1) * size
+	if start > len(matches) || start < 0 {
+		start = len(matches)
+	}
+	end := start + size
+	if end > len(matches) {
+		end = len(matches)
+	}
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"kind":  kind(collectionPath) + "List",
+		"href":  collectionPath,
+		"page":  page,
+		"size":  end - start,
+		"total": len(matches),
+		"items": matches[start:end],
+	})
+}
+
+func (s *Server) add(w http.ResponseWriter, r *http.Request, collectionPath string) {
+	var item map[string]interface{}
+	err := json.NewDecoder(r.Body).Decode(&item)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, "Can't parse body: %v", err)
+		return
+	}
+	id, _ := item["id"].(string)
+	if id == "" {
+		// Labels are identified by their keys:
+		id, _ = item["key"].(string)
+	}
+	if id == "" {
+		s.serial++
+		id = fmt.Sprintf("sandbox-%d", s.serial)
+	}
+	item["id"] = id
+	_, existing := s.find(collectionPath + "/" + id)
+	if existing != nil {
+		sendError(w, http.StatusConflict, "Object '%s' already exists", id)
+		return
+	}
+	if path.Base(collectionPath) == "clusters" {
+		if _, ok := item["state"]; !ok {
+			item["state"] = "installing"
+		}
+	}
+	if _, ok := item["creation_timestamp"]; !ok {
+		item["creation_timestamp"] = time.Now().UTC().Format(time.RFC3339)
+	}
+	decorate(collectionPath, item)
+	s.collections[collectionPath] = append(s.collections[collectionPath], item)
+	sendJSON(w, http.StatusCreated, item)
+}
+
+// action implements the actions that change the state of a cluster, like hibernating it.
+func (s *Server) action(w http.ResponseWriter, r *http.Request, item map[string]interface{},
+	state string) {
+	if r.Method != http.MethodPost {
+		sendError(w, http.StatusMethodNotAllowed, "Method '%s' isn't allowed", r.Method)
+		return
+	}
+	item["state"] = state
+	sendEmpty(w)
+}
+
+// token implements the token endpoint. It accepts any credentials and always returns tokens for
+// the same account.
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		sendError(w, http.StatusMethodNotAllowed, "Method '%s' isn't allowed", r.Method)
+		return
+	}
+	_, account := s.find("/api/accounts_mgmt/v1/accounts/" + currentAccount)
+	now := time.Now()
+	makeToken := func(typ string, duration time.Duration) (string, error) {
+		claims := jwt.MapClaims{
+			"typ":                typ,
+			"iat":                now.Unix(),
+			"exp":                now.Add(duration).Unix(),
+			"sub":                currentAccount,
+			"username":           account["username"],
+			"preferred_username": account["username"],
+			"email":              account["email"],
+			"given_name":         account["first_name"],
+			"family_name":        account["last_name"],
+		}
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key)
+	}
+	accessToken, err := makeToken("Bearer", 15*time.Minute)
+	if err != nil {
+		sendError(w, http.StatusInternalServerError, "Can't create token: %v", err)
+		return
+	}
+	refreshToken, err := makeToken("Refresh", 10*time.Hour)
+	if err != nil {
+		sendError(w, http.StatusInternalServerError, "Can't create token: %v", err)
+		return
+	}
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+		"token_type":    "bearer",
+		"expires_in":    900,
+	})
+}
+
+// decorate adds the 'kind' and 'href' attributes to an object of the given collection.
+func decorate(collectionPath string, item map[string]interface{}) {
+	item["kind"] = kind(collectionPath)
+	item["href"] = fmt.Sprintf("%s/%v", collectionPath, item["id"])
+}
+
+// kind calculates the kind of the objects of a collection from the last segment of its path, for
+// example 'MachinePool' for '.../machine_pools'.
+func kind(collectionPath string) string {
+	name := path.Base(collectionPath)
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		name = strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "s"):
+		name = strings.TrimSuffix(name, "s")
+	}
+	var buffer strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		if word != "" {
+			buffer.WriteString(strings.ToUpper(word[:1]))
+			buffer.WriteString(word[1:])
+		}
+	}
+	return buffer.String()
+}
+
+// merge copies the attributes of the patch into the object, recursively merging nested objects.
+func merge(item, patch map[string]interface{}) {
+	for name, value := range patch {
+		nestedPatch, ok := value.(map[string]interface{})
+		if ok {
+			nestedItem, ok := item[name].(map[string]interface{})
+			if ok {
+				merge(nestedItem, nestedPatch)
+				continue
+			}
+		}
+		item[name] = value
+	}
+}
+
+// intParameter parses the value of an integer query parameter, returning the default if it is
+// empty.
+func intParameter(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func sendJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(body)
+}
+
+// sendEmpty sends a response without body. Note that the content type is needed anyhow, as the
+// SDK checks it for all the responses.
+func sendEmpty(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func sendError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	sendJSON(w, status, map[string]interface{}{
+		"kind":   "Error",
+		"id":     strconv.Itoa(status),
+		"href":   fmt.Sprintf("/api/sandbox/v1/errors/%d", status),
+		"code":   fmt.Sprintf("SANDBOX-%d", status),
+		"reason": fmt.Sprintf(format, args...),
+	})
+}
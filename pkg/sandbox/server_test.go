@@ -0,0 +1,154 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandbox
+
+import (
+	"net/http/httptest"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	"github.com/openshift-online/ocm-cli/pkg/cluster"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
+)
+
+var _ = Describe("Server", func() {
+	var server *httptest.Server
+	var connection *sdk.Connection
+
+	BeforeEach(func() {
+		handler, err := NewServer()
+		Expect(err).ToNot(HaveOccurred())
+		server = httptest.NewServer(handler)
+		connection, err = sdk.NewConnectionBuilder().
+			URL(server.URL).
+			TokenURL(server.URL+urls.SandboxTokenPath).
+			Client(urls.SandboxClientID, urls.SandboxClientSecret).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		connection.Close()
+		server.Close()
+	})
+
+	It("Returns the current account", func() {
+		response, err := connection.AccountsMgmt().V1().CurrentAccount().Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().Username()).To(Equal("alice"))
+		Expect(response.Body().Organization().ID()).To(Equal("org-1"))
+	})
+
+	It("Lists clusters matching a search", func() {
+		response, err := connection.ClustersMgmt().V1().Clusters().List().
+			Search("cloud_provider.id = 'aws' and state = 'ready'").
+			Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Total()).To(Equal(1))
+		Expect(response.Items().Get(0).Name()).To(Equal("sandbox-aws"))
+	})
+
+	It("Pages lists", func() {
+		response, err := connection.ClustersMgmt().V1().Clusters().List().
+			Page(2).
+			Size(2).
+			Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Total()).To(Equal(3))
+		Expect(response.Size()).To(Equal(1))
+		Expect(response.Items().Get(0).ID()).To(Equal("cls-3"))
+	})
+
+	It("Finds clusters by name", func() {
+		object, err := cluster.GetCluster(connection, "sandbox-gcp")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(object.ID()).To(Equal("cls-2"))
+		Expect(object.MultiAZ()).To(BeTrue())
+	})
+
+	It("Creates, updates and deletes clusters", func() {
+		resource := connection.ClustersMgmt().V1().Clusters()
+		spec, err := cmv1.NewCluster().
+			Name("my-cluster").
+			Region(cmv1.NewCloudRegion().ID("us-east-1")).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		addResponse, err := resource.Add().Body(spec).Send()
+		Expect(err).ToNot(HaveOccurred())
+		created := addResponse.Body()
+		Expect(created.ID()).ToNot(BeEmpty())
+		Expect(created.State()).To(Equal(cmv1.ClusterStateInstalling))
+
+		patch, err := cmv1.NewCluster().
+			DisplayName("My cluster").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		updateResponse, err := resource.Cluster(created.ID()).Update().Body(patch).Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updateResponse.Body().DisplayName()).To(Equal("My cluster"))
+		Expect(updateResponse.Body().Region().ID()).To(Equal("us-east-1"))
+
+		_, err = resource.Cluster(created.ID()).Delete().Send()
+		Expect(err).ToNot(HaveOccurred())
+		getResponse, err := resource.Cluster(created.ID()).Get().Send()
+		Expect(err).To(HaveOccurred())
+		Expect(getResponse.Status()).To(Equal(404))
+	})
+
+	It("Hibernates and resumes clusters", func() {
+		resource := connection.ClustersMgmt().V1().Clusters()
+		_, err := resource.Cluster("cls-1").Hibernate().Send()
+		Expect(err).ToNot(HaveOccurred())
+		response, err := resource.Cluster("cls-1").Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().State()).To(Equal(cmv1.ClusterStateHibernating))
+
+		_, err = resource.Cluster("cls-3").Resume().Send()
+		Expect(err).ToNot(HaveOccurred())
+		response, err = resource.Cluster("cls-3").Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().State()).To(Equal(cmv1.ClusterStateResuming))
+	})
+
+	It("Creates nested collections when they are used", func() {
+		pools := connection.ClustersMgmt().V1().Clusters().Cluster("cls-2").MachinePools()
+		listResponse, err := pools.List().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(listResponse.Total()).To(BeZero())
+
+		pool, err := cmv1.NewMachinePool().
+			ID("gpu").
+			Replicas(1).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = pools.Add().Body(pool).Send()
+		Expect(err).ToNot(HaveOccurred())
+		getResponse, err := pools.MachinePool("gpu").Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getResponse.Body().Replicas()).To(Equal(1))
+	})
+
+	It("Returns not found for objects that don't exist", func() {
+		response, err := connection.ClustersMgmt().V1().Clusters().Cluster("junk").Get().Send()
+		Expect(err).To(HaveOccurred())
+		Expect(response.Status()).To(Equal(404))
+	})
+})
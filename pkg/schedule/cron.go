@@ -0,0 +1,193 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed cron expression with the usual five fields: minute, hour, day of month, month
+// and day of week. Don't create instances of this type directly, use the ParseCron function
+// instead.
+type Cron struct {
+	text     string
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	// These indicate if the day of month or day of week fields were restricted, as in that case
+	// the cron semantics are that the time matches if either of them matches.
+	daysRestricted     bool
+	weekdaysRestricted bool
+}
+
+// cronField describes the valid range of values of one of the fields of a cron expression.
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// ParseCron parses a cron expression like '0 20 * * 1-5'. Each field supports '*', single values,
+// ranges like '1-5', steps like '*/15' or '0-30/10', and comma separated lists of those. Day of
+// week 7 is the same than 0, Sunday.
+func ParseCron(text string) (result *Cron, err error) {
+	chunks := strings.Fields(text)
+	if len(chunks) != len(cronFields) {
+		err = fmt.Errorf(
+			"cron expression '%s' should have %d fields but it has %d",
+			text, len(cronFields), len(chunks),
+		)
+		return
+	}
+	bits := make([]uint64, len(chunks))
+	for i, chunk := range chunks {
+		bits[i], err = parseCronField(chunk, cronFields[i])
+		if err != nil {
+			err = fmt.Errorf("cron expression '%s' isn't valid: %v", text, err)
+			return
+		}
+	}
+
+	// Sunday can be 0 or 7:
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+		bits[4] &^= 1 << 7
+	}
+
+	result = &Cron{
+		text:               strings.Join(chunks, " "),
+		minutes:            bits[0],
+		hours:              bits[1],
+		days:               bits[2],
+		months:             bits[3],
+		weekdays:           bits[4],
+		daysRestricted:     chunks[2] != "*",
+		weekdaysRestricted: chunks[4] != "*",
+	}
+	return
+}
+
+func parseCronField(text string, field cronField) (result uint64, err error) {
+	for _, item := range strings.Split(text, ",") {
+		step := 1
+		if slash := strings.Index(item, "/"); slash >= 0 {
+			step, err = strconv.Atoi(item[slash+1:])
+			if err != nil || step <= 0 {
+				err = fmt.Errorf("step '%s' of %s isn't valid", item[slash+1:], field.name)
+				return
+			}
+			item = item[:slash]
+		}
+		first, last := field.min, field.max
+		if item != "*" {
+			dash := strings.Index(item, "-")
+			if dash >= 0 {
+				first, err = parseCronValue(item[:dash], field)
+				if err != nil {
+					return
+				}
+				last, err = parseCronValue(item[dash+1:], field)
+				if err != nil {
+					return
+				}
+				if first > last {
+					err = fmt.Errorf("range '%s' of %s is empty", item, field.name)
+					return
+				}
+			} else {
+				first, err = parseCronValue(item, field)
+				if err != nil {
+					return
+				}
+				last = first
+			}
+		}
+		for value := first; value <= last; value += step {
+			result |= 1 << uint(value)
+		}
+	}
+	return
+}
+
+func parseCronValue(text string, field cronField) (result int, err error) {
+	result, err = strconv.Atoi(text)
+	if err != nil || result < field.min || result > field.max {
+		err = fmt.Errorf(
+			"value '%s' of %s should be a number between %d and %d",
+			text, field.name, field.min, field.max,
+		)
+	}
+	return
+}
+
+// Matches checks if the given time matches the cron expression. Seconds are ignored.
+func (c *Cron) Matches(t time.Time) bool {
+	if c.minutes&(1<<uint(t.Minute())) == 0 {
+		return false
+	}
+	if c.hours&(1<<uint(t.Hour())) == 0 {
+		return false
+	}
+	if c.months&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	dayMatches := c.days&(1<<uint(t.Day())) != 0
+	weekdayMatches := c.weekdays&(1<<uint(t.Weekday())) != 0
+	if c.daysRestricted && c.weekdaysRestricted {
+		return dayMatches || weekdayMatches
+	}
+	return dayMatches && weekdayMatches
+}
+
+// Due checks if the cron expression matches any minute after the given last run time and not after
+// the given current time. If the last run time is zero only the current minute is checked. To
+// avoid running very old occurrences at most one day is checked.
+func (c *Cron) Due(lastRun, now time.Time) bool {
+	now = now.Truncate(time.Minute)
+	if lastRun.IsZero() {
+		return c.Matches(now)
+	}
+	start := lastRun.Truncate(time.Minute).Add(time.Minute)
+	if limit := now.Add(-24 * time.Hour); start.Before(limit) {
+		start = limit
+	}
+	for t := start; !t.After(now); t = t.Add(time.Minute) {
+		if c.Matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the text of the cron expression.
+func (c *Cron) String() string {
+	return c.text
+}
@@ -0,0 +1,69 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Cron", func() {
+	// Monday, 10 January 2022:
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2022, time.January, 10, hour, minute, 0, 0, time.UTC)
+	}
+
+	DescribeTable(
+		"Rejects invalid expressions",
+		func(text string) {
+			_, err := ParseCron(text)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("Too few fields", "0 20 * *"),
+		Entry("Out of range", "60 * * * *"),
+		Entry("Not a number", "a * * * *"),
+		Entry("Empty range", "0 20-10 * * *"),
+		Entry("Zero step", "*/0 * * * *"),
+	)
+
+	DescribeTable(
+		"Matches times",
+		func(text string, t time.Time, expected bool) {
+			cron, err := ParseCron(text)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cron.Matches(t)).To(Equal(expected))
+		},
+		Entry("Every minute", "* * * * *", monday(3, 7), true),
+		Entry("Exact time", "0 20 * * *", monday(20, 0), true),
+		Entry("Other time", "0 20 * * *", monday(20, 1), false),
+		Entry("Weekdays", "0 20 * * 1-5", monday(20, 0), true),
+		Entry("Weekends", "0 20 * * 6,7", monday(20, 0), false),
+		Entry("Step", "*/15 * * * *", monday(3, 45), true),
+		Entry("Step miss", "*/15 * * * *", monday(3, 46), false),
+		Entry("Day of month or week", "0 20 1 * 1", monday(20, 0), true),
+	)
+
+	It("Is due if an occurrence was missed since the last run", func() {
+		cron, err := ParseCron("0 20 * * *")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cron.Due(monday(19, 58), monday(20, 1))).To(BeTrue())
+		Expect(cron.Due(monday(20, 0), monday(20, 5))).To(BeFalse())
+		Expect(cron.Due(time.Time{}, monday(20, 0))).To(BeTrue())
+	})
+})
@@ -0,0 +1,133 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schedule contains the types and functions used to store and evaluate the schedules that
+// hibernate and resume clusters periodically.
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+)
+
+// Actions that can be scheduled:
+const (
+	ActionHibernate = "hibernate"
+	ActionResume    = "resume"
+)
+
+// Schedule describes an action that should be applied to a cluster periodically.
+type Schedule struct {
+	ClusterID   string    `json:"cluster_id"`
+	ClusterName string    `json:"cluster_name,omitempty"`
+	Action      string    `json:"action"`
+	Cron        string    `json:"cron"`
+	LastRun     time.Time `json:"last_run,omitempty"`
+}
+
+// Key returns a string that identifies the schedule, combining the cluster and the action.
+func (s *Schedule) Key() string {
+	return s.ClusterID + "/" + s.Action
+}
+
+// Location returns the location of the file where the schedules are stored. The 'OCM_SCHEDULES'
+// environment variable can be used to change it.
+func Location() (path string, err error) {
+	if path = os.Getenv("OCM_SCHEDULES"); path != "" {
+		return
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return
+	}
+	path = filepath.Join(configDir, "ocm", "schedules.json")
+	return
+}
+
+// Load loads the schedules from the file. If the file doesn't exist it returns an empty list.
+func Load() (result []*Schedule, err error) {
+	file, err := Location()
+	if err != nil {
+		return
+	}
+	return load(file)
+}
+
+func load(file string) (result []*Schedule, err error) {
+	// #nosec G304
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		err = nil
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("can't read schedules file '%s': %v", file, err)
+		return
+	}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		err = fmt.Errorf("can't parse schedules file '%s': %v", file, err)
+		return
+	}
+	return
+}
+
+// Update loads the schedules, calls the given function to modify them and saves the result, sorted
+// by cluster and action. The file is locked during the whole process, so that concurrent updates,
+// for example adding a schedule while the 'run' command is updating the last run times, don't
+// discard each other changes. Nothing is saved if the function returns an error.
+func Update(modify func(schedules []*Schedule) ([]*Schedule, error)) error {
+	file, err := Location()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(file)
+	err = os.MkdirAll(dir, os.FileMode(0755))
+	if err != nil {
+		return fmt.Errorf("can't create directory %s: %v", dir, err)
+	}
+	unlock, err := config.LockFile(file)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	schedules, err := load(file)
+	if err != nil {
+		return err
+	}
+	schedules, err = modify(schedules)
+	if err != nil {
+		return err
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].ClusterID != schedules[j].ClusterID {
+			return schedules[i].ClusterID < schedules[j].ClusterID
+		}
+		return schedules[i].Action < schedules[j].Action
+	})
+	data, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("can't marshal schedules: %v", err)
+	}
+	return config.WriteFile(file, data)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Update", func() {
+	var tmpDir string
+	var file string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-schedules-*")
+		Expect(err).ToNot(HaveOccurred())
+		file = filepath.Join(tmpDir, "schedules.json")
+		os.Setenv("OCM_SCHEDULES", file)
+	})
+
+	AfterEach(func() {
+		os.Unsetenv("OCM_SCHEDULES")
+		os.RemoveAll(tmpDir)
+	})
+
+	add := func(clusterID, action string) error {
+		return Update(func(schedules []*Schedule) ([]*Schedule, error) {
+			return append(schedules, &Schedule{
+				ClusterID: clusterID,
+				Action:    action,
+				Cron:      "0 20 * * *",
+			}), nil
+		})
+	}
+
+	It("Writes a sorted file that only the owner can read", func() {
+		Expect(add("b", ActionResume)).To(Succeed())
+		Expect(add("a", ActionResume)).To(Succeed())
+		Expect(add("a", ActionHibernate)).To(Succeed())
+		info, err := os.Stat(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		schedules, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schedules).To(HaveLen(3))
+		Expect(schedules[0].Key()).To(Equal("a/hibernate"))
+		Expect(schedules[1].Key()).To(Equal("a/resume"))
+		Expect(schedules[2].Key()).To(Equal("b/resume"))
+	})
+
+	It("Doesn't save anything if the function fails", func() {
+		Expect(add("a", ActionResume)).To(Succeed())
+		err := Update(func(schedules []*Schedule) ([]*Schedule, error) {
+			return nil, fmt.Errorf("my-error")
+		})
+		Expect(err).To(MatchError("my-error"))
+		schedules, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schedules).To(HaveLen(1))
+	})
+
+	It("Doesn't lose concurrent updates", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(add(fmt.Sprintf("cluster-%02d", i), ActionHibernate)).To(Succeed())
+			}(i)
+		}
+		wg.Wait()
+		schedules, err := Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schedules).To(HaveLen(20))
+	})
+})
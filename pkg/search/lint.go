@@ -0,0 +1,187 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package search contains functions to work with the search expressions supported by the
+// `search` parameter of the collections of the API.
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Lint checks a search expression for common mistakes, like unbalanced quotes or parenthesis, or
+// connectives without operands. It doesn't fully parse the expression, the server is still the
+// authority on what is valid, but it gives an early and more precise error message than the one
+// returned by the server.
+func Lint(query string) error {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("search expression is empty")
+	}
+	depth := 0
+	var previous *token
+	for i := range tokens {
+		current := &tokens[i]
+		switch {
+		case current.text == "(":
+			depth++
+		case current.text == ")":
+			if depth == 0 {
+				return fmt.Errorf(
+					"unexpected closing parenthesis at position %d",
+					current.position,
+				)
+			}
+			if previous != nil && previous.text == "(" {
+				return fmt.Errorf(
+					"empty parenthesis at position %d",
+					previous.position,
+				)
+			}
+			if previous != nil && previous.isOperator() {
+				return fmt.Errorf(
+					"missing operand after '%s' at position %d",
+					previous.text, previous.position,
+				)
+			}
+			depth--
+		case current.isConnective():
+			if previous == nil || previous.text == "(" || previous.isOperator() {
+				return fmt.Errorf(
+					"missing operand before '%s' at position %d",
+					current.text, current.position,
+				)
+			}
+		}
+		previous = current
+	}
+	if depth > 0 {
+		return fmt.Errorf("missing closing parenthesis")
+	}
+	if previous.isOperator() {
+		return fmt.Errorf(
+			"missing operand after '%s' at position %d",
+			previous.text, previous.position,
+		)
+	}
+	return nil
+}
+
+// token is a piece of a search expression together with the position where it starts.
+type token struct {
+	text     string
+	position int
+	quoted   bool
+}
+
+// isConnective checks if the token is one of the binary logical connectives.
+func (t *token) isConnective() bool {
+	if t.quoted {
+		return false
+	}
+	switch strings.ToLower(t.text) {
+	case "and", "or":
+		return true
+	}
+	return false
+}
+
+// isOperator checks if the token is a connective or an operator that requires an operand after
+// it.
+func (t *token) isOperator() bool {
+	if t.quoted {
+		return false
+	}
+	switch strings.ToLower(t.text) {
+	case "and", "or", "not", "like", "ilike", "in", "is", "=", "!=", "<>", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// tokenize splits the search expression into tokens. It only returns an error if there are
+// unterminated string literals.
+func tokenize(query string) (result []token, err error) {
+	runes := []rune(query)
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'':
+			start := i
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\'' {
+					// Two consecutive quotes are an escaped quote:
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i += 2
+						continue
+					}
+					closed = true
+					i++
+					break
+				}
+				i++
+			}
+			if !closed {
+				err = fmt.Errorf("unterminated string starting at position %d", start)
+				return
+			}
+			result = append(result, token{
+				text:     string(runes[start:i]),
+				position: start,
+				quoted:   true,
+			})
+		case r == '(' || r == ')' || r == ',':
+			result = append(result, token{
+				text:     string(r),
+				position: i,
+			})
+			i++
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			for i < len(runes) && strings.ContainsRune("=!<>", runes[i]) {
+				i++
+			}
+			result = append(result, token{
+				text:     string(runes[start:i]),
+				position: start,
+			})
+		default:
+			start := i
+			for i < len(runes) {
+				c := runes[i]
+				if unicode.IsSpace(c) || strings.ContainsRune("'(),=!<>", c) {
+					break
+				}
+				i++
+			}
+			result = append(result, token{
+				text:     string(runes[start:i]),
+				position: start,
+			})
+		}
+	}
+	return
+}
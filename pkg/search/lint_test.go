@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package search
+
+import (
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Lint", func() {
+	DescribeTable(
+		"Accepts valid expressions",
+		func(query string) {
+			Expect(Lint(query)).To(Succeed())
+		},
+		Entry("Simple comparison", "username = 'myuser'"),
+		Entry("Like", "email like '%@example.com'"),
+		Entry("Date", "created_at > '2024-01-01'"),
+		Entry("Escaped quote", "name = 'O''Brien'"),
+		Entry("Connectives", "(a = 'x' or b = 'y') and not c = 'z'"),
+		Entry("In", "id in ('1', '2')"),
+		Entry("Connective inside string", "name = 'and'"),
+	)
+
+	DescribeTable(
+		"Rejects invalid expressions",
+		func(query string, message string) {
+			err := Lint(query)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(message))
+		},
+		Entry("Empty", "  ", "empty"),
+		Entry("Unterminated string", "email like '%@example.com", "unterminated string"),
+		Entry("Missing closing parenthesis", "(a = 'x'", "missing closing parenthesis"),
+		Entry("Unexpected closing parenthesis", "a = 'x')", "unexpected closing"),
+		Entry("Empty parenthesis", "a in ()", "empty parenthesis"),
+		Entry("Leading connective", "and a = 'x'", "missing operand before 'and'"),
+		Entry("Trailing connective", "a = 'x' and", "missing operand after 'and'"),
+		Entry("Double connective", "a = 'x' and or b = 'y'", "missing operand before 'or'"),
+		Entry("Trailing operator", "a =", "missing operand after '='"),
+	)
+})
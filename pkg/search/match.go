@@ -0,0 +1,360 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Match evaluates a search expression against an object, typically the result of decoding a JSON
+// document. Field names can contain dots to select nested fields, for example 'region.id'. It
+// supports the comparison operators, 'like', 'ilike', 'in', 'is null' and the logical connectives.
+// Values are compared as numbers when both sides are numbers, and as strings otherwise. It is
+// intended for filtering objects locally, the server is still the authority on the semantics of
+// search expressions. An empty expression matches all objects.
+func Match(query string, object map[string]interface{}) (result bool, err error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return
+	}
+	if len(tokens) == 0 {
+		result = true
+		return
+	}
+	m := &matcher{
+		tokens: tokens,
+		object: object,
+	}
+	result, err = m.or()
+	if err != nil {
+		return
+	}
+	if m.index < len(m.tokens) {
+		current := m.tokens[m.index]
+		err = fmt.Errorf(
+			"unexpected '%s' at position %d",
+			current.text, current.position,
+		)
+	}
+	return
+}
+
+// matcher contains the state of the evaluation of a search expression.
+type matcher struct {
+	tokens []token
+	index  int
+	object map[string]interface{}
+}
+
+// peek returns the next token, converted to lower case if it isn't quoted, or an empty string if
+// there are no more tokens.
+func (m *matcher) peek() string {
+	if m.index >= len(m.tokens) {
+		return ""
+	}
+	current := m.tokens[m.index]
+	if current.quoted {
+		return current.text
+	}
+	return strings.ToLower(current.text)
+}
+
+// next returns the next token and advances to the following one.
+func (m *matcher) next() (result *token, err error) {
+	if m.index >= len(m.tokens) {
+		err = fmt.Errorf("unexpected end of search expression")
+		return
+	}
+	result = &m.tokens[m.index]
+	m.index++
+	return
+}
+
+// expect checks that the next token is the given keyword or symbol and advances to the following
+// one.
+func (m *matcher) expect(text string) error {
+	current, err := m.next()
+	if err != nil {
+		return err
+	}
+	if current.quoted || strings.ToLower(current.text) != text {
+		return fmt.Errorf(
+			"expected '%s' at position %d but found '%s'",
+			text, current.position, current.text,
+		)
+	}
+	return nil
+}
+
+func (m *matcher) or() (result bool, err error) {
+	result, err = m.and()
+	for err == nil && m.peek() == "or" {
+		m.index++
+		var right bool
+		right, err = m.and()
+		result = result || right
+	}
+	return
+}
+
+func (m *matcher) and() (result bool, err error) {
+	result, err = m.not()
+	for err == nil && m.peek() == "and" {
+		m.index++
+		var right bool
+		right, err = m.not()
+		result = result && right
+	}
+	return
+}
+
+func (m *matcher) not() (result bool, err error) {
+	switch m.peek() {
+	case "not":
+		m.index++
+		result, err = m.not()
+		result = !result
+	case "(":
+		m.index++
+		result, err = m.or()
+		if err != nil {
+			return
+		}
+		err = m.expect(")")
+	default:
+		result, err = m.comparison()
+	}
+	return
+}
+
+func (m *matcher) comparison() (result bool, err error) {
+	field, err := m.next()
+	if err != nil {
+		return
+	}
+	if field.quoted {
+		err = fmt.Errorf(
+			"expected field name at position %d but found '%s'",
+			field.position, field.text,
+		)
+		return
+	}
+	value := lookup(m.object, field.text)
+	operator, err := m.next()
+	if err != nil {
+		return
+	}
+	negate := false
+	text := strings.ToLower(operator.text)
+	if text == "not" && !operator.quoted {
+		negate = true
+		operator, err = m.next()
+		if err != nil {
+			return
+		}
+		text = strings.ToLower(operator.text)
+	}
+	switch {
+	case operator.quoted:
+		err = fmt.Errorf(
+			"expected operator at position %d but found '%s'",
+			operator.position, operator.text,
+		)
+	case text == "is" && !negate:
+		if m.peek() == "not" {
+			m.index++
+			negate = true
+		}
+		err = m.expect("null")
+		result = value == nil
+	case text == "in":
+		result, err = m.in(value)
+	case text == "like" || text == "ilike":
+		var pattern string
+		pattern, err = m.literal()
+		if err == nil {
+			result, err = like(format(value), pattern, text == "ilike")
+		}
+	case !negate && isComparison(text):
+		var literal string
+		literal, err = m.literal()
+		if err == nil {
+			result = compareResult(text, compare(value, literal))
+		}
+	default:
+		err = fmt.Errorf(
+			"unsupported operator '%s' at position %d",
+			operator.text, operator.position,
+		)
+	}
+	if negate {
+		result = !result
+	}
+	return
+}
+
+func (m *matcher) in(value interface{}) (result bool, err error) {
+	err = m.expect("(")
+	if err != nil {
+		return
+	}
+	for {
+		var literal string
+		literal, err = m.literal()
+		if err != nil {
+			return
+		}
+		if compare(value, literal) == 0 {
+			result = true
+		}
+		if m.peek() != "," {
+			break
+		}
+		m.index++
+	}
+	err = m.expect(")")
+	return
+}
+
+// literal returns the value of the next token, removing the quotes and escaped quotes from
+// strings.
+func (m *matcher) literal() (result string, err error) {
+	current, err := m.next()
+	if err != nil {
+		return
+	}
+	if !current.quoted {
+		if strings.ContainsAny(current.text, "(),") {
+			err = fmt.Errorf(
+				"expected value at position %d but found '%s'",
+				current.position, current.text,
+			)
+			return
+		}
+		result = current.text
+		return
+	}
+	result = current.text[1 : len(current.text)-1]
+	result = strings.ReplaceAll(result, "''", "'")
+	return
+}
+
+// lookup returns the value of the given field of the object, following the dots of the name into
+// nested objects. It returns nil if the field doesn't exist.
+func lookup(object map[string]interface{}, name string) interface{} {
+	var value interface{} = object
+	for _, part := range strings.Split(name, ".") {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = nested[part]
+	}
+	return value
+}
+
+// format converts a value to the text that is used to compare it.
+func format(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(typed)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
+
+// compare compares a value with a literal, as numbers if both are numbers and as strings
+// otherwise.
+func compare(value interface{}, literal string) int {
+	text := format(value)
+	left, leftErr := strconv.ParseFloat(text, 64)
+	right, rightErr := strconv.ParseFloat(literal, 64)
+	if leftErr == nil && rightErr == nil {
+		switch {
+		case left < right:
+			return -1
+		case left > right:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(text, literal)
+}
+
+// isComparison checks if the given text is one of the comparison operators.
+func isComparison(text string) bool {
+	switch text {
+	case "=", "!=", "<>", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+// compareResult converts the result of a comparison into the result of the given operator.
+func compareResult(operator string, comparison int) bool {
+	switch operator {
+	case "=":
+		return comparison == 0
+	case "!=", "<>":
+		return comparison != 0
+	case "<":
+		return comparison < 0
+	case "<=":
+		return comparison <= 0
+	case ">":
+		return comparison > 0
+	default:
+		return comparison >= 0
+	}
+}
+
+// like checks if the text matches the given pattern, where '%' matches any sequence of characters
+// and '_' matches any single character.
+func like(text, pattern string, insensitive bool) (result bool, err error) {
+	var buffer strings.Builder
+	if insensitive {
+		buffer.WriteString("(?i)")
+	}
+	buffer.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			buffer.WriteString(".*")
+		case '_':
+			buffer.WriteString(".")
+		default:
+			buffer.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	buffer.WriteString("$")
+	expression, err := regexp.Compile(buffer.String())
+	if err != nil {
+		return
+	}
+	result = expression.MatchString(text)
+	return
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package search
+
+import (
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Match", func() {
+	object := map[string]interface{}{
+		"id":    "123",
+		"name":  "my-cluster",
+		"state": "ready",
+		"nodes": map[string]interface{}{
+			"compute": 3.0,
+		},
+		"region": map[string]interface{}{
+			"id": "us-east-1",
+		},
+		"multi_az": false,
+		"owner":    "O'Brien",
+	}
+
+	DescribeTable(
+		"Evaluates expressions",
+		func(query string, expected bool) {
+			result, err := Match(query, object)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(expected))
+		},
+		Entry("Empty", "", true),
+		Entry("Equal", "name = 'my-cluster'", true),
+		Entry("Not equal", "name != 'my-cluster'", false),
+		Entry("Nested field", "region.id = 'us-east-1'", true),
+		Entry("Missing field", "flavour.id = 'x'", false),
+		Entry("Number", "nodes.compute >= 3", true),
+		Entry("Number as string", "nodes.compute = '3'", true),
+		Entry("Boolean", "multi_az = false", true),
+		Entry("Escaped quote", "owner = 'O''Brien'", true),
+		Entry("Like", "name like 'my-%'", true),
+		Entry("Like single character", "name like 'my-cluste_'", true),
+		Entry("Like is case sensitive", "name like 'MY-%'", false),
+		Entry("Ilike", "name ilike 'MY-%'", true),
+		Entry("Not like", "name not like 'my-%'", false),
+		Entry("In", "state in ('installing', 'ready')", true),
+		Entry("Not in", "state not in ('installing', 'ready')", false),
+		Entry("Is null", "flavour is null", true),
+		Entry("Is not null", "name is not null", true),
+		Entry("And", "id = '123' and state = 'error'", false),
+		Entry("Or", "id = '456' or state = 'ready'", true),
+		Entry("Not", "not state = 'ready'", false),
+		Entry(
+			"Parenthesis",
+			"(name = 'x' or id = '123') and state in ('Reserved', 'ready')",
+			true,
+		),
+		Entry("Case insensitive keywords", "id = '123' AND state IN ('ready')", true),
+	)
+
+	DescribeTable(
+		"Rejects invalid expressions",
+		func(query string, message string) {
+			_, err := Match(query, object)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(message))
+		},
+		Entry("Missing value", "name =", "unexpected end"),
+		Entry("Missing parenthesis", "(name = 'x'", "unexpected end"),
+		Entry("Extra token", "name = 'x' 'y'", "unexpected ''y''"),
+		Entry("Unknown operator", "name ~ 'x'", "unsupported operator '~'"),
+		Entry("Quoted field", "'name' = 'x'", "expected field name"),
+	)
+})
@@ -0,0 +1,272 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to work with the service accounts of the SSO server. The SDK
+// doesn't support that API, so these functions use raw requests sent to the SSO server.
+
+package serviceaccount
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/ocm"
+)
+
+// tokenSuffix is the suffix of the path of the token endpoint of the SSO server. What goes before
+// it is the path of the realm, and the service accounts API is inside that realm.
+const tokenSuffix = "/protocol/openid-connect/token"
+
+// ServiceAccount is a service account of the SSO server.
+type ServiceAccount struct {
+	ID          string `json:"id,omitempty"`
+	ClientID    string `json:"clientId,omitempty"`
+	Secret      string `json:"secret,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	CreatedBy   string `json:"createdBy,omitempty"`
+	CreatedAt   int64  `json:"createdAt,omitempty"`
+}
+
+// Created returns the creation time of the service account, or the zero time if it isn't known.
+func (a *ServiceAccount) Created() time.Time {
+	if a.CreatedAt == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(a.CreatedAt)
+}
+
+// APIURL calculates the base URL of the SSO server, the path of the service accounts API and the
+// prefix of that path that should be sent to the SSO server, from the URL of the token endpoint.
+// The prefix is needed because the connection only accepts prefixes made of segments that contain
+// letters, digits and underscores, and realm names often contain dashes. It fails if the token URL
+// isn't the one of a realm of an SSO server that could support service accounts.
+func APIURL(tokenURL string) (base, prefix, path string, err error) {
+	parsed, err := url.Parse(tokenURL)
+	if err != nil {
+		return
+	}
+	realm := strings.TrimSuffix(parsed.Path, tokenSuffix)
+	if realm == parsed.Path || realm == "" {
+		err = fmt.Errorf(
+			"service accounts aren't supported by the SSO server, the token URL '%s' "+
+				"doesn't end with '%s'",
+			tokenURL, tokenSuffix,
+		)
+		return
+	}
+	for _, segment := range strings.Split(strings.TrimPrefix(realm, "/"), "/") {
+		if !segmentRE.MatchString(segment) {
+			break
+		}
+		prefix += "/" + segment
+	}
+	if prefix == "" {
+		err = fmt.Errorf(
+			"service accounts aren't supported by the SSO server, the path of the token "+
+				"URL '%s' must start with a segment containing only letters, digits and "+
+				"underscores",
+			tokenURL,
+		)
+		return
+	}
+	base = fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host)
+	path = realm + "/apis/service_accounts/v1"
+	return
+}
+
+// segmentRE matches the path segments that can be used in the prefixes of alternative URLs.
+var segmentRE = regexp.MustCompile(`^\w+$`)
+
+// NewConnection loads the configuration and creates a connection that sends the requests for the
+// service accounts API to the SSO server. It returns the connection and the path of the API.
+func NewConnection() (connection *sdk.Connection, path string, err error) {
+	cfg, err := config.Load()
+	if err != nil {
+		err = fmt.Errorf("Can't load config file: %v", err)
+		return
+	}
+	if cfg == nil {
+		err = fmt.Errorf("Not logged in, run the 'login' command")
+		return
+	}
+	tokenURL := cfg.TokenURL
+	if tokenURL == "" {
+		tokenURL = sdk.DefaultTokenURL
+	}
+	base, prefix, path, err := APIURL(tokenURL)
+	if err != nil {
+		err = fmt.Errorf("Can't manage service accounts: %v", err)
+		return
+	}
+	connection, err = ocm.NewConnection().
+		Config(cfg).
+		AlternativeURL(prefix, base).
+		Build()
+	if err != nil {
+		err = fmt.Errorf("Failed to create OCM connection: %v", err)
+		return
+	}
+	return
+}
+
+// List retrieves all the service accounts visible to the user.
+func List(connection *sdk.Connection, path string) ([]*ServiceAccount, error) {
+	var result []*ServiceAccount
+	size := 100
+	first := 0
+	for {
+		body, err := send(
+			connection.Get().
+				Path(path).
+				Parameter("first", first).
+				Parameter("max", size),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Can't retrieve service accounts: %v", err)
+		}
+		var items []*ServiceAccount
+		err = json.Unmarshal(body, &items)
+		if err != nil {
+			return nil, fmt.Errorf("Can't parse service accounts: %v", err)
+		}
+		result = append(result, items...)
+		if len(items) < size {
+			break
+		}
+		first += size
+	}
+	return result, nil
+}
+
+// Create creates a service account. The result contains the secret, which can't be retrieved
+// later.
+func Create(connection *sdk.Connection, path, name, description string) (*ServiceAccount, error) {
+	data, err := json.Marshal(&ServiceAccount{
+		Name:        name,
+		Description: description,
+	})
+	if err != nil {
+		return nil, err
+	}
+	body, err := send(connection.Post().Path(path).Bytes(data))
+	if err != nil {
+		return nil, fmt.Errorf("Can't create service account '%s': %w", name, err)
+	}
+	return parse(body)
+}
+
+// ResetSecret replaces the secret of the service account with the given identifier. The result
+// contains the new secret, which can't be retrieved later.
+func ResetSecret(connection *sdk.Connection, path, id string) (*ServiceAccount, error) {
+	body, err := send(
+		connection.Post().Path(fmt.Sprintf("%s/%s/resetSecret", path, url.PathEscape(id))),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Can't reset secret of service account '%s': %w", id, err)
+	}
+	return parse(body)
+}
+
+// Delete deletes the service account with the given identifier.
+func Delete(connection *sdk.Connection, path, id string) error {
+	_, err := send(
+		connection.Delete().Path(fmt.Sprintf("%s/%s", path, url.PathEscape(id))),
+	)
+	if err != nil {
+		return fmt.Errorf("Can't delete service account '%s': %w", id, err)
+	}
+	return nil
+}
+
+// PrintCredentials prints the identifiers of the given service account and its secret. If a file
+// name is given the secret is written to that file, readable only by the owner, instead of to the
+// standard output. Either way the user is reminded that the secret can't be retrieved later.
+func PrintCredentials(account *ServiceAccount, file string) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "ID:\t%s\n", account.ID)
+	fmt.Fprintf(writer, "Client ID:\t%s\n", account.ClientID)
+	fmt.Fprintf(writer, "Name:\t%s\n", account.Name)
+	if file == "" {
+		fmt.Fprintf(writer, "Client secret:\t%s\n", account.Secret)
+	} else {
+		err := os.WriteFile(file, []byte(account.Secret+"\n"), 0600)
+		if err != nil {
+			return fmt.Errorf("Can't write secret to file '%s': %v", file, err)
+		}
+		fmt.Fprintf(writer, "Client secret:\tsaved to '%s'\n", file)
+	}
+	err := writer.Flush()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(
+		os.Stderr,
+		"The secret is only shown once, store it now. To get a new one use the "+
+			"'ocm account sa rotate-secret %s' command.\n",
+		account.ID,
+	)
+	return nil
+}
+
+// parse parses the body of a response that contains a service account.
+func parse(body []byte) (*ServiceAccount, error) {
+	result := &ServiceAccount{}
+	err := json.Unmarshal(body, result)
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse service account: %v", err)
+	}
+	return result, nil
+}
+
+// ssoError is used to decode the errors returned by the SSO server, which don't use the format of
+// the OCM API.
+type ssoError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// send sends the given request and returns the body of the response, or an error if the server
+// responded with an error status.
+func send(request *sdk.Request) ([]byte, error) {
+	response, err := request.Send()
+	if err != nil {
+		return nil, err
+	}
+	if response.Status() >= http.StatusBadRequest {
+		detail := &ssoError{}
+		err = json.Unmarshal(response.Bytes(), detail)
+		switch {
+		case err == nil && detail.Description != "":
+			return nil, fmt.Errorf("status is %d: %s", response.Status(), detail.Description)
+		case err == nil && detail.Error != "":
+			return nil, fmt.Errorf("status is %d: %s", response.Status(), detail.Error)
+		default:
+			return nil, fmt.Errorf("status is %d", response.Status())
+		}
+	}
+	return response.Bytes(), nil
+}
@@ -0,0 +1,292 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to load the templates of service logs and to render them
+// with the values of their variables.
+
+package servicelog
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed templates
+var builtinFS embed.FS
+
+// TemplatesEnvVar is the name of the environment variable that contains the list of directories
+// where the templates are loaded from.
+const TemplatesEnvVar = "OCM_SERVICE_LOG_TEMPLATES"
+
+// Builtin is the source of the templates that are part of the binary.
+const Builtin = "builtin"
+
+// Names of the variables that are always available to templates, calculated from the cluster that
+// the service log is posted to:
+const (
+	VarCluster     = "cluster"
+	VarClusterID   = "cluster_id"
+	VarClusterName = "cluster_name"
+)
+
+// severities are the valid values of the severity of service logs.
+var severities = []string{"Debug", "Info", "Warning", "Error", "Fatal"}
+
+// Template is a template of a service log. The name is the name of the file that contains it,
+// without the extension.
+type Template struct {
+	Name        string       `yaml:"-"`
+	Source      string       `yaml:"-"`
+	Description string       `yaml:"description"`
+	Variables   []*Variable  `yaml:"variables"`
+	Log         *LogTemplate `yaml:"log"`
+}
+
+// Variable describes a variable that can be used in a template. Variables without a default
+// value are mandatory.
+type Variable struct {
+	Name        string  `yaml:"name"`
+	Description string  `yaml:"description"`
+	Default     *string `yaml:"default"`
+}
+
+// LogTemplate contains the fields of the service log. The summary and the description are Go
+// templates that receive the values of the variables.
+type LogTemplate struct {
+	Severity     string `yaml:"severity"`
+	ServiceName  string `yaml:"service_name"`
+	Summary      string `yaml:"summary"`
+	Description  string `yaml:"description"`
+	InternalOnly bool   `yaml:"internal_only"`
+}
+
+// LogEntry is the result of rendering a template.
+type LogEntry struct {
+	Severity     string `json:"severity"`
+	ServiceName  string `json:"service_name"`
+	Summary      string `json:"summary"`
+	Description  string `json:"description"`
+	InternalOnly bool   `json:"internal_only"`
+}
+
+// Locations returns the directories containing the templates of the user. They are the
+// directories given in the 'OCM_SERVICE_LOG_TEMPLATES' environment variable, separated by the
+// path list separator of the platform, or the 'service-log-templates' directory next to the given
+// configuration file.
+func Locations(configFile string) []string {
+	value := os.Getenv(TemplatesEnvVar)
+	if value != "" {
+		return filepath.SplitList(value)
+	}
+	if configFile == "" {
+		return nil
+	}
+	return []string{filepath.Join(filepath.Dir(configFile), "service-log-templates")}
+}
+
+// LoadTemplates loads the builtin templates and then the templates from the '.yaml' and '.yml'
+// files of the given directories. Templates loaded later replace the ones with the same name
+// loaded before, so users can replace the builtin templates. Directories that don't exist are
+// ignored.
+func LoadTemplates(dirs []string) (result map[string]*Template, err error) {
+	templates := map[string]*Template{}
+	err = loadDir(templates, builtinFS, "templates", Builtin)
+	if err != nil {
+		return
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		_, err = os.Stat(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			err = nil
+			continue
+		}
+		if err != nil {
+			return
+		}
+		err = loadDir(templates, os.DirFS(dir), ".", dir)
+		if err != nil {
+			return
+		}
+	}
+	result = templates
+	return
+}
+
+func loadDir(templates map[string]*Template, fsys fs.FS, dir, source string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return err
+		}
+		file := entry.Name()
+		if source != Builtin {
+			file = filepath.Join(source, file)
+		}
+		template, err := parseTemplate(data)
+		if err != nil {
+			return fmt.Errorf("Can't parse template file '%s': %v", file, err)
+		}
+		template.Name = strings.TrimSuffix(entry.Name(), ext)
+		template.Source = source
+		templates[template.Name] = template
+	}
+	return nil
+}
+
+func parseTemplate(data []byte) (result *Template, err error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	result = &Template{}
+	err = decoder.Decode(result)
+	if err != nil {
+		return
+	}
+	if result.Log == nil {
+		err = errors.New("field 'log' is mandatory")
+		return
+	}
+	if result.Log.Summary == "" {
+		err = errors.New("field 'log.summary' is mandatory")
+		return
+	}
+	if !validSeverity(result.Log.Severity) {
+		err = fmt.Errorf(
+			"severity '%s' isn't valid, valid values are %s",
+			result.Log.Severity, strings.Join(severities, ", "),
+		)
+		return
+	}
+	for _, variable := range result.Variables {
+		if variable.Name == "" {
+			err = errors.New("variables must have a name")
+			return
+		}
+		if reservedVariable(variable.Name) {
+			err = fmt.Errorf("variable '%s' is reserved", variable.Name)
+			return
+		}
+	}
+	return
+}
+
+// Names returns the sorted names of the given templates.
+func Names(templates map[string]*Template) []string {
+	result := make([]string, 0, len(templates))
+	for name := range templates {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Render renders the template with the given values of the variables. It fails if a mandatory
+// variable doesn't have a value, or if a value is given for a variable that the template doesn't
+// declare, as that is most likely a typo.
+func (t *Template) Render(vars map[string]string) (result *LogEntry, err error) {
+	values := map[string]string{}
+	declared := map[string]bool{}
+	for _, variable := range t.Variables {
+		declared[variable.Name] = true
+		value, ok := vars[variable.Name]
+		if !ok {
+			if variable.Default == nil {
+				err = fmt.Errorf(
+					"Template '%s' requires variable '%s'",
+					t.Name, variable.Name,
+				)
+				return
+			}
+			value = *variable.Default
+		}
+		values[variable.Name] = value
+	}
+	for name, value := range vars {
+		if reservedVariable(name) {
+			values[name] = value
+			continue
+		}
+		if !declared[name] {
+			err = fmt.Errorf(
+				"Template '%s' doesn't have a variable named '%s'",
+				t.Name, name,
+			)
+			return
+		}
+	}
+	summary, err := t.execute("summary", t.Log.Summary, values)
+	if err != nil {
+		return
+	}
+	description, err := t.execute("description", t.Log.Description, values)
+	if err != nil {
+		return
+	}
+	result = &LogEntry{
+		Severity:     t.Log.Severity,
+		ServiceName:  t.Log.ServiceName,
+		Summary:      summary,
+		Description:  description,
+		InternalOnly: t.Log.InternalOnly,
+	}
+	return
+}
+
+func (t *Template) execute(field, text string, values map[string]string) (string, error) {
+	parsed, err := template.New(field).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("Can't parse %s of template '%s': %v", field, t.Name, err)
+	}
+	buffer := &bytes.Buffer{}
+	err = parsed.Execute(buffer, values)
+	if err != nil {
+		return "", fmt.Errorf("Can't render %s of template '%s': %v", field, t.Name, err)
+	}
+	return strings.TrimSpace(buffer.String()), nil
+}
+
+func validSeverity(value string) bool {
+	for _, severity := range severities {
+		if value == severity {
+			return true
+		}
+	}
+	return false
+}
+
+func reservedVariable(name string) bool {
+	return name == VarCluster || name == VarClusterID || name == VarClusterName
+}
@@ -0,0 +1,222 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicelog
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Locations", func() {
+	It("Uses the directory next to the configuration file", func() {
+		os.Unsetenv(TemplatesEnvVar)
+		Expect(Locations("/home/me/.config/ocm/ocm.json")).To(Equal([]string{
+			"/home/me/.config/ocm/service-log-templates",
+		}))
+	})
+
+	It("Uses the directories from the environment", func() {
+		os.Setenv(TemplatesEnvVar, "/a"+string(os.PathListSeparator)+"/b")
+		defer os.Unsetenv(TemplatesEnvVar)
+		Expect(Locations("/home/me/.config/ocm/ocm.json")).To(Equal([]string{"/a", "/b"}))
+	})
+})
+
+var _ = Describe("Load templates", func() {
+	var tmp string
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = os.MkdirTemp("", "ocm-test-*.d")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := os.RemoveAll(tmp)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	write := func(name, content string) {
+		err := os.WriteFile(filepath.Join(tmp, name), []byte(content), 0600)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("Loads the builtin templates", func() {
+		templates, err := LoadTemplates(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(Names(templates)).To(ContainElements(
+			"incident-start",
+			"incident-update",
+			"incident-resolved",
+			"maintenance-scheduled",
+		))
+		Expect(templates["incident-start"].Source).To(Equal(Builtin))
+	})
+
+	It("Ignores directories that don't exist", func() {
+		_, err := LoadTemplates([]string{filepath.Join(tmp, "missing")})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Loads user templates and replaces builtin ones", func() {
+		write("incident-start.yaml", `
+log:
+  severity: Error
+  summary: Custom
+`)
+		write("upgrade.yml", `
+description: Upgrade notice
+log:
+  severity: Info
+  summary: Upgrade
+`)
+		write("README.md", "Not a template")
+		templates, err := LoadTemplates([]string{tmp})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(templates["incident-start"].Source).To(Equal(tmp))
+		Expect(templates["incident-start"].Log.Severity).To(Equal("Error"))
+		Expect(templates["upgrade"].Description).To(Equal("Upgrade notice"))
+		Expect(templates).ToNot(HaveKey("README"))
+	})
+
+	It("Rejects invalid severities", func() {
+		write("bad.yaml", "log:\n  severity: Critical\n  summary: Bad\n")
+		_, err := LoadTemplates([]string{tmp})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("bad.yaml"))
+		Expect(err.Error()).To(ContainSubstring("severity 'Critical' isn't valid"))
+	})
+
+	It("Rejects unknown fields", func() {
+		write("bad.yaml", "log:\n  severity: Info\n  summary: Bad\n  sumary: Typo\n")
+		_, err := LoadTemplates([]string{tmp})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("sumary"))
+	})
+
+	It("Rejects reserved variables", func() {
+		write("bad.yaml", `
+variables:
+- name: cluster_id
+log:
+  severity: Info
+  summary: Bad
+`)
+		_, err := LoadTemplates([]string{tmp})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("variable 'cluster_id' is reserved"))
+	})
+})
+
+var _ = Describe("Render", func() {
+	var template *Template
+
+	BeforeEach(func() {
+		defaultValue := "soon"
+		template = &Template{
+			Name: "test",
+			Variables: []*Variable{
+				{
+					Name: "impact",
+				},
+				{
+					Name:    "when",
+					Default: &defaultValue,
+				},
+			},
+			Log: &LogTemplate{
+				Severity:    "Warning",
+				ServiceName: "SREManualAction",
+				Summary:     "Incident: {{ .impact }}",
+				Description: "Cluster {{ .cluster_name }}: {{ .impact }}, update {{ .when }}\n",
+			},
+		}
+	})
+
+	It("Renders the summary and the description", func() {
+		entry, err := template.Render(map[string]string{
+			"impact":       "console down",
+			"cluster_name": "mycluster",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entry).To(Equal(&LogEntry{
+			Severity:    "Warning",
+			ServiceName: "SREManualAction",
+			Summary:     "Incident: console down",
+			Description: "Cluster mycluster: console down, update soon",
+		}))
+	})
+
+	It("Uses the given value instead of the default", func() {
+		entry, err := template.Render(map[string]string{
+			"impact":       "console down",
+			"when":         "in one hour",
+			"cluster_name": "mycluster",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entry.Description).To(HaveSuffix("update in one hour"))
+	})
+
+	It("Fails if a mandatory variable is missing", func() {
+		_, err := template.Render(map[string]string{
+			"cluster_name": "mycluster",
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Template 'test' requires variable 'impact'"))
+	})
+
+	It("Fails if an unknown variable is given", func() {
+		_, err := template.Render(map[string]string{
+			"impact":       "console down",
+			"impcat":       "typo",
+			"cluster_name": "mycluster",
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Template 'test' doesn't have a variable named 'impcat'"))
+	})
+
+	It("Fails if the template uses an undeclared variable", func() {
+		template.Log.Summary = "{{ .other }}"
+		_, err := template.Render(map[string]string{
+			"impact": "console down",
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Can't render summary of template 'test'"))
+	})
+
+	It("Renders all the builtin templates", func() {
+		templates, err := LoadTemplates(nil)
+		Expect(err).ToNot(HaveOccurred())
+		for _, template := range templates {
+			vars := map[string]string{
+				VarCluster:     "mycluster",
+				VarClusterID:   "123",
+				VarClusterName: "mycluster",
+			}
+			for _, variable := range template.Variables {
+				vars[variable.Name] = "value"
+			}
+			entry, err := template.Render(vars)
+			Expect(err).ToNot(HaveOccurred(), template.Name)
+			Expect(entry.Summary).ToNot(BeEmpty(), template.Name)
+			Expect(entry.Description).To(ContainSubstring("mycluster"), template.Name)
+		}
+	})
+})
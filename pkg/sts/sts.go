@@ -0,0 +1,191 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sts contains functions used to check the AWS STS operator roles of clusters and to
+// calculate the IAM changes needed to bring them up to date.
+package sts
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// Status of an operator role:
+const (
+	// StatusUpdate means that the role exists and its policy should be updated.
+	StatusUpdate = "update"
+
+	// StatusMissing means that the server requires a policy for an operator, but the cluster
+	// doesn't have a role for it.
+	StatusMissing = "missing"
+
+	// StatusUnused means that the cluster has a role that doesn't correspond to any of the
+	// policies required by the server.
+	StatusUnused = "unused"
+)
+
+// OperatorRole describes an operator role of a cluster and the IAM changes that it needs.
+type OperatorRole struct {
+	Namespace string
+	Name      string
+	RoleARN   string
+	PolicyID  string
+	Policy    string
+	Status    string
+
+	// Commands contains the AWS CLI commands, without the 'aws' prefix, that apply the changes.
+	// They reference the policy document in a file named after the policy identifier.
+	Commands [][]string
+}
+
+// PolicyFile returns the name of the file, inside the given directory, that should contain the
+// policy document for the role.
+func (r *OperatorRole) PolicyFile(dir string) string {
+	return filepath.Join(dir, r.PolicyID+".json")
+}
+
+// PolicyID returns the identifier that the server uses for the policy of the operator with the
+// given namespace and name.
+func PolicyID(namespace, name string) string {
+	return strings.ReplaceAll(namespace+"_"+name, "-", "_") + "_policy"
+}
+
+// Plan compares the operator roles of the cluster with the operator policies required by the
+// server and returns the changes needed for each role, sorted by policy identifier. Policies are
+// assumed to have the same name than the role, as created by the 'rosa' tool. The policy documents
+// are referenced as files inside the given directory.
+func Plan(cluster *cmv1.Cluster, policies []*cmv1.AWSSTSPolicy,
+	dir string) (result []*OperatorRole, err error) {
+	sts := cluster.AWS().STS()
+	if sts.RoleARN() == "" {
+		err = fmt.Errorf(
+			"cluster '%s' doesn't use AWS STS, so it has no operator roles",
+			cluster.Name(),
+		)
+		return
+	}
+	// Index the required policies:
+	required := map[string]*cmv1.AWSSTSPolicy{}
+	for _, policy := range policies {
+		if policy.Type() == "OperatorRole" {
+			required[policy.ID()] = policy
+		}
+	}
+
+	// Check the roles that exist:
+	found := map[string]bool{}
+	for _, role := range sts.OperatorIAMRoles() {
+		item := &OperatorRole{
+			Namespace: role.Namespace(),
+			Name:      role.Name(),
+			RoleARN:   role.RoleARN(),
+			PolicyID:  PolicyID(role.Namespace(), role.Name()),
+		}
+		policy, ok := required[item.PolicyID]
+		if !ok {
+			item.Status = StatusUnused
+			result = append(result, item)
+			continue
+		}
+		found[item.PolicyID] = true
+		arn, err := parseARN(role.RoleARN())
+		if err != nil {
+			return nil, err
+		}
+		item.Status = StatusUpdate
+		item.Policy = policy.Details()
+		item.Commands = [][]string{{
+			"iam", "create-policy-version",
+			"--policy-arn", arn.policy(arn.name),
+			"--policy-document", "file://" + item.PolicyFile(dir),
+			"--set-as-default",
+		}}
+		result = append(result, item)
+	}
+
+	// Add the required policies that don't have a role. The server doesn't give us the names
+	// of the service accounts that should be trusted by the role, so we can only create the
+	// policy:
+	for id, policy := range required {
+		if found[id] {
+			continue
+		}
+		item := &OperatorRole{
+			PolicyID: id,
+			Policy:   policy.Details(),
+			Status:   StatusMissing,
+		}
+		item.Commands = [][]string{{
+			"iam", "create-policy",
+			"--policy-name", roleName(sts.OperatorRolePrefix(), id),
+			"--policy-document", "file://" + item.PolicyFile(dir),
+		}}
+		result = append(result, item)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].PolicyID < result[j].PolicyID
+	})
+	return
+}
+
+// roleName calculates the name of the role for the given policy, using the same convention than
+// the 'rosa' tool: the prefix and the namespace and name of the operator, separated by dashes
+// and truncated to the 64 characters that AWS allows.
+func roleName(prefix, policyID string) string {
+	name := strings.TrimSuffix(policyID, "_policy")
+	name = strings.ReplaceAll(name, "_", "-")
+	if prefix != "" {
+		name = prefix + "-" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// arn contains the parts of an IAM role ARN that we need.
+type arn struct {
+	partition string
+	account   string
+	name      string
+}
+
+var arnRE = regexp.MustCompile(`^arn:([^:]+):iam::(\d+):role/(?:.*/)?([^/]+)$`)
+
+func parseARN(text string) (result arn, err error) {
+	matches := arnRE.FindStringSubmatch(text)
+	if matches == nil {
+		err = fmt.Errorf("'%s' isn't a valid IAM role ARN", text)
+		return
+	}
+	result = arn{
+		partition: matches[1],
+		account:   matches[2],
+		name:      matches[3],
+	}
+	return
+}
+
+// policy returns the ARN of the policy with the given name in the same account than the role.
+func (a arn) policy(name string) string {
+	return fmt.Sprintf("arn:%s:iam::%s:policy/%s", a.partition, a.account, name)
+}
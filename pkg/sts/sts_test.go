@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sts
+
+import (
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Plan", func() {
+	var policies []*cmv1.AWSSTSPolicy
+
+	BeforeEach(func() {
+		var err error
+		policies, err = cmv1.UnmarshalAWSSTSPolicyList(`[
+			{
+				"id": "openshift_ingress_operator_cloud_credentials_policy",
+				"type": "OperatorRole",
+				"details": "{\"Version\": \"2012-10-17\"}"
+			},
+			{
+				"id": "openshift_cluster_csi_drivers_ebs_cloud_credentials_policy",
+				"type": "OperatorRole",
+				"details": "{\"Version\": \"2012-10-17\"}"
+			},
+			{
+				"id": "sts_installer_permission_policy",
+				"type": "AccountRole"
+			}
+		]`)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Calculates the changes for each role", func() {
+		cluster, err := cmv1.UnmarshalCluster(`{
+			"name": "mycluster",
+			"aws": {
+				"sts": {
+					"role_arn": "arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role",
+					"operator_role_prefix": "mycluster-a1b2",
+					"operator_iam_roles": [
+						{
+							"namespace": "openshift-ingress-operator",
+							"name": "cloud-credentials",
+							"role_arn": "arn:aws:iam::123456789012:role/mycluster-a1b2-openshift-ingress-operator-cloud-credentials"
+						},
+						{
+							"namespace": "openshift-old",
+							"name": "cloud-credentials",
+							"role_arn": "arn:aws:iam::123456789012:role/mycluster-a1b2-openshift-old-cloud-credentials"
+						}
+					]
+				}
+			}
+		}`)
+		Expect(err).ToNot(HaveOccurred())
+		roles, err := Plan(cluster, policies, "policies")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(roles).To(HaveLen(3))
+
+		Expect(roles[0].PolicyID).To(Equal("openshift_cluster_csi_drivers_ebs_cloud_credentials_policy"))
+		Expect(roles[0].Status).To(Equal(StatusMissing))
+		Expect(roles[0].Commands).To(Equal([][]string{{
+			"iam", "create-policy",
+			"--policy-name", "mycluster-a1b2-openshift-cluster-csi-drivers-ebs-cloud-credentia",
+			"--policy-document", "file://policies/openshift_cluster_csi_drivers_ebs_cloud_credentials_policy.json",
+		}}))
+
+		Expect(roles[1].PolicyID).To(Equal("openshift_ingress_operator_cloud_credentials_policy"))
+		Expect(roles[1].Status).To(Equal(StatusUpdate))
+		Expect(roles[1].Policy).To(Equal(`{"Version": "2012-10-17"}`))
+		Expect(roles[1].Commands).To(Equal([][]string{{
+			"iam", "create-policy-version",
+			"--policy-arn", "arn:aws:iam::123456789012:policy/mycluster-a1b2-openshift-ingress-operator-cloud-credentials",
+			"--policy-document", "file://policies/openshift_ingress_operator_cloud_credentials_policy.json",
+			"--set-as-default",
+		}}))
+
+		Expect(roles[2].PolicyID).To(Equal("openshift_old_cloud_credentials_policy"))
+		Expect(roles[2].Status).To(Equal(StatusUnused))
+		Expect(roles[2].Commands).To(BeEmpty())
+	})
+
+	It("Rejects clusters that don't use STS", func() {
+		cluster, err := cmv1.UnmarshalCluster(`{"name": "mycluster"}`)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = Plan(cluster, policies, "policies")
+		Expect(err).To(MatchError(
+			"cluster 'mycluster' doesn't use AWS STS, so it has no operator roles",
+		))
+	})
+})
@@ -0,0 +1,247 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains a minimal writer for the subset of the HCL syntax that is needed to
+// generate Terraform configurations.
+
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// block is an HCL block, like a resource or a variable. The body contains attributes and nested
+// blocks, in the order they will be written.
+type block struct {
+	kind   string
+	labels []string
+	body   []interface{}
+}
+
+// attribute is an HCL attribute, for example 'name = "my-cluster"'.
+type attribute struct {
+	name  string
+	value interface{}
+}
+
+// object is an HCL object with attributes written in the given order.
+type object []attribute
+
+// expression is a Terraform expression that is written as is, for example a reference to a
+// variable or to an attribute of another resource.
+type expression string
+
+// newBlock creates a new block with the given kind and labels.
+func newBlock(kind string, labels ...string) *block {
+	return &block{
+		kind:   kind,
+		labels: labels,
+	}
+}
+
+// add adds an attribute to the block.
+func (b *block) add(name string, value interface{}) *block {
+	b.body = append(b.body, attribute{name: name, value: value})
+	return b
+}
+
+// addIf adds an attribute to the block only if the value isn't empty.
+func (b *block) addIf(name string, value interface{}) *block {
+	if !empty(value) {
+		b.add(name, value)
+	}
+	return b
+}
+
+// nest adds a nested block.
+func (b *block) nest(nested *block) *block {
+	b.body = append(b.body, nested)
+	return b
+}
+
+// add adds an attribute to the object.
+func (o object) add(name string, value interface{}) object {
+	return append(o, attribute{name: name, value: value})
+}
+
+// addIf adds an attribute to the object only if the value isn't empty.
+func (o object) addIf(name string, value interface{}) object {
+	if empty(value) {
+		return o
+	}
+	return o.add(name, value)
+}
+
+// empty checks if the given value is the zero value of its type, or an empty collection.
+func empty(value interface{}) bool {
+	switch typed := value.(type) {
+	case string:
+		return typed == ""
+	case expression:
+		return typed == ""
+	case int:
+		return typed == 0
+	case bool:
+		return !typed
+	case []string:
+		return len(typed) == 0
+	case map[string]string:
+		return len(typed) == 0
+	case object:
+		return len(typed) == 0
+	case []object:
+		return len(typed) == 0
+	default:
+		return value == nil
+	}
+}
+
+// write writes the block to the given writer.
+func (b *block) write(out io.Writer) {
+	writeBody(out, []interface{}{b}, 0)
+}
+
+// writeBody writes the attributes and nested blocks with the given indentation level. The equal
+// signs of consecutive attributes are aligned, like 'terraform fmt' does.
+func writeBody(out io.Writer, body []interface{}, level int) {
+	indent := strings.Repeat("  ", level)
+	for i := 0; i < len(body); {
+		// Blocks and runs of attributes are separated by blank lines:
+		if i > 0 {
+			fmt.Fprintf(out, "\n")
+		}
+
+		// Blocks are written with their own body indented:
+		nested, ok := body[i].(*block)
+		if ok {
+			fmt.Fprintf(out, "%s%s", indent, nested.kind)
+			for _, label := range nested.labels {
+				fmt.Fprintf(out, " %s", quote(label))
+			}
+			fmt.Fprintf(out, " {\n")
+			writeBody(out, nested.body, level+1)
+			fmt.Fprintf(out, "%s}\n", indent)
+			i++
+			continue
+		}
+
+		// Find the run of consecutive attributes and the length of the longest name:
+		j := i
+		width := 0
+		for j < len(body) {
+			attr, ok := body[j].(attribute)
+			if !ok {
+				break
+			}
+			if len(attr.name) > width {
+				width = len(attr.name)
+			}
+			j++
+		}
+		for _, item := range body[i:j] {
+			attr := item.(attribute)
+			fmt.Fprintf(
+				out, "%s%-*s = %s\n",
+				indent, width, attr.name, value(attr.value, level),
+			)
+		}
+		i = j
+	}
+}
+
+// value returns the HCL representation of the given value, for an attribute written with the
+// given indentation level.
+func value(v interface{}, level int) string {
+	switch typed := v.(type) {
+	case string:
+		return quote(typed)
+	case expression:
+		return string(typed)
+	case int:
+		return fmt.Sprintf("%d", typed)
+	case bool:
+		return fmt.Sprintf("%t", typed)
+	case []string:
+		items := make([]string, len(typed))
+		for i, item := range typed {
+			items[i] = quote(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]string:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		attrs := make(object, len(keys))
+		for i, key := range keys {
+			attrs[i] = attribute{name: quote(key), value: typed[key]}
+		}
+		return value(attrs, level)
+	case object:
+		buffer := &strings.Builder{}
+		buffer.WriteString("{\n")
+		body := make([]interface{}, len(typed))
+		for i, attr := range typed {
+			body[i] = attr
+		}
+		writeBody(buffer, body, level+1)
+		buffer.WriteString(strings.Repeat("  ", level) + "}")
+		return buffer.String()
+	case []object:
+		indent := strings.Repeat("  ", level+1)
+		buffer := &strings.Builder{}
+		buffer.WriteString("[\n")
+		for _, item := range typed {
+			buffer.WriteString(indent + value(item, level+1) + ",\n")
+		}
+		buffer.WriteString(strings.Repeat("  ", level) + "]")
+		return buffer.String()
+	default:
+		return quote(fmt.Sprintf("%v", typed))
+	}
+}
+
+// quote returns the given text as an HCL string literal, escaping the characters that would
+// otherwise start template sequences.
+func quote(text string) string {
+	quoted := fmt.Sprintf("%q", text)
+	quoted = strings.ReplaceAll(quoted, "${", "$${")
+	quoted = strings.ReplaceAll(quoted, "%{", "%%{")
+	return quoted
+}
+
+// identifier converts the given text into a valid Terraform identifier, replacing the characters
+// that aren't allowed with underscores.
+func identifier(text string) string {
+	buffer := &strings.Builder{}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			buffer.WriteRune(r)
+		default:
+			buffer.WriteRune('_')
+		}
+	}
+	result := buffer.String()
+	if result == "" || (result[0] >= '0' && result[0] <= '9') {
+		result = "_" + result
+	}
+	return result
+}
@@ -0,0 +1,312 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used to export clusters as Terraform configurations that
+// use the RHCS provider.
+
+package terraform
+
+import (
+	"fmt"
+	"io"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// Name of the Terraform provider and of the resource types:
+const (
+	providerSource      = "terraform-redhat/rhcs"
+	clusterResource     = "rhcs_cluster_rosa_classic"
+	machinePoolResource = "rhcs_machine_pool"
+	idpResource         = "rhcs_identity_provider"
+)
+
+// defaultMachinePool is the identifier of the machine pool that is created with the cluster, and
+// that is managed with the compute attributes of the cluster resource.
+const defaultMachinePool = "worker"
+
+// Export contains the cluster and the related objects that will be written as a Terraform
+// configuration.
+type Export struct {
+	Cluster           *cmv1.Cluster
+	MachinePools      []*cmv1.MachinePool
+	IdentityProviders []*cmv1.IdentityProvider
+
+	// HTPasswdUsers contains the users of the htpasswd identity providers, indexed by the
+	// identifier of the identity provider.
+	HTPasswdUsers map[string][]*cmv1.HTPasswdUser
+
+	// Imports indicates if 'import' blocks should be generated, so that Terraform adopts the
+	// existing objects instead of trying to create new ones.
+	Imports bool
+}
+
+// Write writes the Terraform configuration to the given writer. The API doesn't return secrets,
+// like client secrets or passwords, so those are replaced by references to sensitive variables
+// that need to be provided when running Terraform.
+func (e *Export) Write(out io.Writer) error {
+	cluster := e.Cluster
+	if cluster.Product().ID() != "rosa" {
+		return fmt.Errorf(
+			"Cluster '%s' is a '%s' cluster, but only ROSA classic clusters can be exported",
+			cluster.Name(), cluster.Product().ID(),
+		)
+	}
+	prefix := identifier(cluster.Name())
+	ref := clusterResource + "." + prefix
+
+	var variables, resources, imports []*block
+	importBlock := func(to, id string) {
+		imports = append(
+			imports,
+			newBlock("import").
+				add("to", expression(to)).
+				add("id", id),
+		)
+	}
+
+	// Cluster:
+	resources = append(resources, e.clusterBlock(prefix))
+	importBlock(ref, cluster.ID())
+
+	// Machine pools, except the default one:
+	for _, pool := range e.MachinePools {
+		if pool.ID() == defaultMachinePool {
+			continue
+		}
+		name := identifier(cluster.Name() + "_" + pool.ID())
+		resources = append(resources, e.machinePoolBlock(name, ref, pool))
+		importBlock(machinePoolResource+"."+name, cluster.ID()+","+pool.ID())
+	}
+
+	// Identity providers:
+	for _, idp := range e.IdentityProviders {
+		name := identifier(cluster.Name() + "_" + idp.Name())
+		resource, secrets, err := e.idpBlock(name, ref, idp)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, resource)
+		variables = append(variables, secrets...)
+		importBlock(idpResource+"."+name, cluster.ID()+","+idp.Name())
+	}
+
+	// Write the blocks:
+	fmt.Fprintf(
+		out,
+		"# Generated by 'ocm generate terraform' from cluster '%s' (%s).\n\n",
+		cluster.Name(), cluster.ID(),
+	)
+	newBlock("terraform").
+		nest(newBlock("required_providers").
+			add("rhcs", object{{name: "source", value: providerSource}})).
+		write(out)
+	blocks := append(variables, resources...)
+	if e.Imports {
+		blocks = append(blocks, imports...)
+	}
+	for _, b := range blocks {
+		fmt.Fprintf(out, "\n")
+		b.write(out)
+	}
+	return nil
+}
+
+// clusterBlock creates the resource for the cluster.
+func (e *Export) clusterBlock(name string) *block {
+	cluster := e.Cluster
+	aws := cluster.AWS()
+	nodes := cluster.Nodes()
+	network := cluster.Network()
+
+	result := newBlock("resource", clusterResource, name).
+		add("name", cluster.Name()).
+		add("cloud_region", cluster.Region().ID()).
+		addIf("aws_account_id", aws.AccountID()).
+		addIf("availability_zones", nodes.AvailabilityZones()).
+		add("multi_az", cluster.MultiAZ()).
+		addIf("version", cluster.Version().RawID()).
+		addIf("channel_group", cluster.Version().ChannelGroup()).
+		addIf("compute_machine_type", nodes.ComputeMachineType().ID())
+	autoscaling, ok := nodes.GetAutoscaleCompute()
+	if ok {
+		result.
+			add("autoscaling_enabled", true).
+			add("min_replicas", autoscaling.MinReplicas()).
+			add("max_replicas", autoscaling.MaxReplicas())
+	} else {
+		result.add("replicas", nodes.Compute())
+	}
+	result.
+		addIf("default_mp_labels", nodes.ComputeLabels()).
+		addIf("machine_cidr", network.MachineCIDR()).
+		addIf("service_cidr", network.ServiceCIDR()).
+		addIf("pod_cidr", network.PodCIDR()).
+		addIf("host_prefix", network.HostPrefix()).
+		addIf("aws_subnet_ids", aws.SubnetIDs()).
+		addIf("aws_private_link", aws.PrivateLink()).
+		addIf("private", cluster.API().Listening() == cmv1.ListeningMethodInternal).
+		addIf("fips", cluster.FIPS()).
+		addIf("etcd_encryption", cluster.EtcdEncryption()).
+		addIf("kms_key_arn", aws.KMSKeyArn()).
+		addIf("tags", aws.Tags())
+	creator := cluster.Properties()["rosa_creator_arn"]
+	if creator != "" {
+		result.add("properties", map[string]string{
+			"rosa_creator_arn": creator,
+		})
+	}
+	sts := aws.STS()
+	if sts.RoleARN() != "" {
+		result.add("sts", object{}.
+			addIf("role_arn", sts.RoleARN()).
+			addIf("support_role_arn", sts.SupportRoleARN()).
+			addIf("instance_iam_roles", object{}.
+				addIf("master_role_arn", sts.InstanceIAMRoles().MasterRoleARN()).
+				addIf("worker_role_arn", sts.InstanceIAMRoles().WorkerRoleARN())).
+			addIf("operator_role_prefix", sts.OperatorRolePrefix()))
+	}
+	return result
+}
+
+// machinePoolBlock creates the resource for a machine pool.
+func (e *Export) machinePoolBlock(name, cluster string, pool *cmv1.MachinePool) *block {
+	result := newBlock("resource", machinePoolResource, name).
+		add("cluster", expression(cluster+".id")).
+		add("name", pool.ID()).
+		add("machine_type", pool.InstanceType())
+	autoscaling, ok := pool.GetAutoscaling()
+	if ok {
+		result.
+			add("autoscaling_enabled", true).
+			add("min_replicas", autoscaling.MinReplicas()).
+			add("max_replicas", autoscaling.MaxReplicas())
+	} else {
+		result.add("replicas", pool.Replicas())
+	}
+	if e.Cluster.MultiAZ() && len(pool.AvailabilityZones()) == 1 {
+		result.add("availability_zone", pool.AvailabilityZones()[0])
+	}
+	result.addIf("labels", pool.Labels())
+	taints := make([]object, 0, len(pool.Taints()))
+	for _, taint := range pool.Taints() {
+		taints = append(taints, object{}.
+			addIf("key", taint.Key()).
+			addIf("value", taint.Value()).
+			addIf("schedule_type", taint.Effect()))
+	}
+	result.addIf("taints", taints)
+	return result
+}
+
+// idpBlock creates the resource for an identity provider, and the variables for the secrets that
+// it needs.
+func (e *Export) idpBlock(name, cluster string, idp *cmv1.IdentityProvider) (result *block,
+	variables []*block, err error) {
+	secret := func(suffix, description string, typ string) expression {
+		variable := name + "_" + suffix
+		variables = append(
+			variables,
+			newBlock("variable", variable).
+				add("description", fmt.Sprintf(
+					"%s of identity provider '%s' of cluster '%s'.",
+					description, idp.Name(), e.Cluster.Name(),
+				)).
+				add("type", expression(typ)).
+				add("sensitive", true),
+		)
+		return expression("var." + variable)
+	}
+
+	result = newBlock("resource", idpResource, name).
+		add("cluster", expression(cluster+".id")).
+		add("name", idp.Name()).
+		addIf("mapping_method", string(idp.MappingMethod()))
+	switch idp.Type() {
+	case cmv1.IdentityProviderTypeGithub:
+		github := idp.Github()
+		result.add("github", object{}.
+			addIf("client_id", github.ClientID()).
+			addIf("client_secret", secret("client_secret", "Client secret", "string")).
+			addIf("organizations", github.Organizations()).
+			addIf("teams", github.Teams()).
+			addIf("hostname", github.Hostname()).
+			addIf("ca", github.CA()))
+	case cmv1.IdentityProviderTypeGitlab:
+		gitlab := idp.Gitlab()
+		result.add("gitlab", object{}.
+			addIf("client_id", gitlab.ClientID()).
+			addIf("client_secret", secret("client_secret", "Client secret", "string")).
+			addIf("url", gitlab.URL()).
+			addIf("ca", gitlab.CA()))
+	case cmv1.IdentityProviderTypeGoogle:
+		google := idp.Google()
+		result.add("google", object{}.
+			addIf("client_id", google.ClientID()).
+			addIf("client_secret", secret("client_secret", "Client secret", "string")).
+			addIf("hosted_domain", google.HostedDomain()))
+	case cmv1.IdentityProviderTypeLDAP:
+		ldap := idp.LDAP()
+		attributes := ldap.Attributes()
+		var password expression
+		if ldap.BindDN() != "" {
+			password = secret("bind_password", "Bind password", "string")
+		}
+		result.add("ldap", object{}.
+			addIf("url", ldap.URL()).
+			addIf("bind_dn", ldap.BindDN()).
+			addIf("bind_password", password).
+			addIf("insecure", ldap.Insecure()).
+			addIf("ca", ldap.CA()).
+			addIf("attributes", object{}.
+				addIf("id", attributes.ID()).
+				addIf("email", attributes.Email()).
+				addIf("name", attributes.Name()).
+				addIf("preferred_username", attributes.PreferredUsername())))
+	case cmv1.IdentityProviderTypeOpenID:
+		openid := idp.OpenID()
+		claims := openid.Claims()
+		result.add("openid", object{}.
+			addIf("client_id", openid.ClientID()).
+			addIf("client_secret", secret("client_secret", "Client secret", "string")).
+			addIf("issuer", openid.Issuer()).
+			addIf("ca", openid.CA()).
+			addIf("extra_scopes", openid.ExtraScopes()).
+			addIf("extra_authorize_parameters", openid.ExtraAuthorizeParameters()).
+			addIf("claims", object{}.
+				addIf("email", claims.Email()).
+				addIf("name", claims.Name()).
+				addIf("preferred_username", claims.PreferredUsername())))
+	case cmv1.IdentityProviderTypeHtpasswd:
+		passwords := secret("passwords", "Passwords of the users, indexed by user name,", "map(string)")
+		users := []object{}
+		for _, user := range e.HTPasswdUsers[idp.ID()] {
+			users = append(users, object{}.
+				add("username", user.Username()).
+				add("password", expression(
+					fmt.Sprintf("%s[%s]", passwords, quote(user.Username())),
+				)))
+		}
+		result.add("htpasswd", object{}.
+			addIf("users", users))
+	default:
+		err = fmt.Errorf(
+			"Identity provider '%s' has type '%s', which can't be exported",
+			idp.Name(), idp.Type(),
+		)
+	}
+	return
+}
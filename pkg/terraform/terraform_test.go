@@ -0,0 +1,277 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+var _ = Describe("Export", func() {
+	It("Writes the cluster, machine pools and identity providers", func() {
+		cluster, err := cmv1.UnmarshalCluster(`{
+			"id": "123",
+			"name": "my-cluster",
+			"product": {
+				"id": "rosa"
+			},
+			"region": {
+				"id": "us-east-1"
+			},
+			"multi_az": false,
+			"version": {
+				"raw_id": "4.14.1",
+				"channel_group": "stable"
+			},
+			"nodes": {
+				"compute": 3,
+				"compute_machine_type": {
+					"id": "m5.xlarge"
+				},
+				"availability_zones": ["us-east-1a"]
+			},
+			"network": {
+				"machine_cidr": "10.0.0.0/16",
+				"host_prefix": 23
+			},
+			"aws": {
+				"account_id": "456",
+				"sts": {
+					"role_arn": "arn:aws:iam::456:role/Installer",
+					"support_role_arn": "arn:aws:iam::456:role/Support",
+					"instance_iam_roles": {
+						"master_role_arn": "arn:aws:iam::456:role/ControlPlane",
+						"worker_role_arn": "arn:aws:iam::456:role/Worker"
+					},
+					"operator_role_prefix": "my-cluster-x1y2"
+				}
+			},
+			"properties": {
+				"rosa_creator_arn": "arn:aws:iam::456:user/admin",
+				"rosa_cli_version": "1.2.30"
+			}
+		}`)
+		Expect(err).ToNot(HaveOccurred())
+		pools, err := cmv1.UnmarshalMachinePoolList(`[
+			{
+				"id": "worker",
+				"instance_type": "m5.xlarge",
+				"replicas": 3
+			},
+			{
+				"id": "infra",
+				"instance_type": "r5.xlarge",
+				"autoscaling": {
+					"min_replicas": 2,
+					"max_replicas": 4
+				},
+				"labels": {
+					"node-role.kubernetes.io/infra": ""
+				},
+				"taints": [
+					{
+						"key": "infra",
+						"value": "true",
+						"effect": "NoSchedule"
+					}
+				]
+			}
+		]`)
+		Expect(err).ToNot(HaveOccurred())
+		idps, err := cmv1.UnmarshalIdentityProviderList(`[
+			{
+				"id": "i1",
+				"name": "GitHub",
+				"type": "GithubIdentityProvider",
+				"mapping_method": "claim",
+				"github": {
+					"client_id": "my-client",
+					"organizations": ["my-org"]
+				}
+			},
+			{
+				"id": "i2",
+				"name": "local",
+				"type": "HTPasswdIdentityProvider"
+			}
+		]`)
+		Expect(err).ToNot(HaveOccurred())
+		users, err := cmv1.UnmarshalHTPasswdUserList(`[
+			{
+				"id": "u1",
+				"username": "alice"
+			}
+		]`)
+		Expect(err).ToNot(HaveOccurred())
+
+		export := &Export{
+			Cluster:           cluster,
+			MachinePools:      pools,
+			IdentityProviders: idps,
+			HTPasswdUsers: map[string][]*cmv1.HTPasswdUser{
+				"i2": users,
+			},
+			Imports: true,
+		}
+		buffer := &bytes.Buffer{}
+		err = export.Write(buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer.String()).To(Equal(`# Generated by 'ocm generate terraform' from cluster 'my-cluster' (123).
+
+terraform {
+  required_providers {
+    rhcs = {
+      source = "terraform-redhat/rhcs"
+    }
+  }
+}
+
+variable "my_cluster_github_client_secret" {
+  description = "Client secret of identity provider 'GitHub' of cluster 'my-cluster'."
+  type        = string
+  sensitive   = true
+}
+
+variable "my_cluster_local_passwords" {
+  description = "Passwords of the users, indexed by user name, of identity provider 'local' of cluster 'my-cluster'."
+  type        = map(string)
+  sensitive   = true
+}
+
+resource "rhcs_cluster_rosa_classic" "my_cluster" {
+  name                 = "my-cluster"
+  cloud_region         = "us-east-1"
+  aws_account_id       = "456"
+  availability_zones   = ["us-east-1a"]
+  multi_az             = false
+  version              = "4.14.1"
+  channel_group        = "stable"
+  compute_machine_type = "m5.xlarge"
+  replicas             = 3
+  machine_cidr         = "10.0.0.0/16"
+  host_prefix          = 23
+  properties           = {
+    "rosa_creator_arn" = "arn:aws:iam::456:user/admin"
+  }
+  sts                  = {
+    role_arn             = "arn:aws:iam::456:role/Installer"
+    support_role_arn     = "arn:aws:iam::456:role/Support"
+    instance_iam_roles   = {
+      master_role_arn = "arn:aws:iam::456:role/ControlPlane"
+      worker_role_arn = "arn:aws:iam::456:role/Worker"
+    }
+    operator_role_prefix = "my-cluster-x1y2"
+  }
+}
+
+resource "rhcs_machine_pool" "my_cluster_infra" {
+  cluster             = rhcs_cluster_rosa_classic.my_cluster.id
+  name                = "infra"
+  machine_type        = "r5.xlarge"
+  autoscaling_enabled = true
+  min_replicas        = 2
+  max_replicas        = 4
+  labels              = {
+    "node-role.kubernetes.io/infra" = ""
+  }
+  taints              = [
+    {
+      key           = "infra"
+      value         = "true"
+      schedule_type = "NoSchedule"
+    },
+  ]
+}
+
+resource "rhcs_identity_provider" "my_cluster_github" {
+  cluster        = rhcs_cluster_rosa_classic.my_cluster.id
+  name           = "GitHub"
+  mapping_method = "claim"
+  github         = {
+    client_id     = "my-client"
+    client_secret = var.my_cluster_github_client_secret
+    organizations = ["my-org"]
+  }
+}
+
+resource "rhcs_identity_provider" "my_cluster_local" {
+  cluster  = rhcs_cluster_rosa_classic.my_cluster.id
+  name     = "local"
+  htpasswd = {
+    users = [
+      {
+        username = "alice"
+        password = var.my_cluster_local_passwords["alice"]
+      },
+    ]
+  }
+}
+
+import {
+  to = rhcs_cluster_rosa_classic.my_cluster
+  id = "123"
+}
+
+import {
+  to = rhcs_machine_pool.my_cluster_infra
+  id = "123,infra"
+}
+
+import {
+  to = rhcs_identity_provider.my_cluster_github
+  id = "123,GitHub"
+}
+
+import {
+  to = rhcs_identity_provider.my_cluster_local
+  id = "123,local"
+}
+`))
+	})
+
+	It("Rejects clusters that aren't ROSA", func() {
+		cluster, err := cmv1.UnmarshalCluster(`{
+			"id": "123",
+			"name": "my-cluster",
+			"product": {
+				"id": "osd"
+			}
+		}`)
+		Expect(err).ToNot(HaveOccurred())
+		export := &Export{
+			Cluster: cluster,
+		}
+		err = export.Write(&bytes.Buffer{})
+		Expect(err).To(MatchError(
+			"Cluster 'my-cluster' is a 'osd' cluster, but only ROSA classic clusters " +
+				"can be exported",
+		))
+	})
+
+	It("Escapes template sequences in strings", func() {
+		Expect(quote(`a "${b}" %{c}`)).To(Equal(`"a \"$${b}\" %%{c}"`))
+	})
+
+	It("Converts names to identifiers", func() {
+		Expect(identifier("My-Cluster.1")).To(Equal("my_cluster_1"))
+		Expect(identifier("1st")).To(Equal("_1st"))
+	})
+})
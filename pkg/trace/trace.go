@@ -0,0 +1,151 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains functions used to implement the '--trace' command line option.
+
+package trace
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// AddFlag adds the trace flag to the given set of command line flags.
+func AddFlag(flags *pflag.FlagSet) {
+	flags.BoolVar(
+		&enabled,
+		"trace",
+		false,
+		"Print to the standard error the method, path, page number, status, latency and "+
+			"connection reuse of each request sent to the API, and the total time and number "+
+			"of connections opened by the command at the end.",
+	)
+}
+
+// Enabled returns a boolean flag that indicates if the trace mode is enabled.
+func Enabled() bool {
+	return enabled
+}
+
+// enabled is a boolean flag that indicates that the trace mode is enabled.
+var enabled bool
+
+// stats contains the number of requests sent, the total time spent waiting for them and the
+// number of network connections that were opened and reused to send them. It is shared by all the
+// connections created by the command, and protected by the lock because requests may be sent from
+// multiple goroutines.
+var stats struct {
+	lock        sync.Mutex
+	requests    int
+	latency     time.Duration
+	connections int
+	reused      int
+}
+
+// TransportWrapper returns a transport wrapper that writes to the given writer a line for each
+// request sent.
+func TransportWrapper(out io.Writer) func(http.RoundTripper) http.RoundTripper {
+	return func(wrapped http.RoundTripper) http.RoundTripper {
+		return &roundTripper{
+			out:     out,
+			wrapped: wrapped,
+		}
+	}
+}
+
+type roundTripper struct {
+	out     io.Writer
+	wrapped http.RoundTripper
+}
+
+// Make sure that we implement the interface:
+var _ http.RoundTripper = (*roundTripper)(nil)
+
+// RoundTrip is the implementation of the round tripper interface.
+func (t *roundTripper) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	// Find out if the request is sent using a new connection or one reused from a previous
+	// request. This will not be called at all if the request isn't actually sent, for example
+	// when the response is taken from the cache.
+	conn := "-"
+	request = request.WithContext(httptrace.WithClientTrace(
+		request.Context(),
+		&httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				if info.Reused {
+					conn = "reused"
+				} else {
+					conn = "new"
+				}
+			},
+		},
+	))
+
+	start := time.Now()
+	response, err = t.wrapped.RoundTrip(request)
+	latency := time.Since(start)
+
+	// Update the statistics:
+	stats.lock.Lock()
+	stats.requests++
+	stats.latency += latency
+	switch conn {
+	case "new":
+		stats.connections++
+	case "reused":
+		stats.reused++
+	}
+	stats.lock.Unlock()
+
+	// Write the line:
+	page := request.URL.Query().Get("page")
+	if page == "" {
+		page = "-"
+	}
+	status := "-"
+	if response != nil {
+		status = fmt.Sprintf("%d", response.StatusCode)
+	}
+	fmt.Fprintf(
+		t.out,
+		"TRACE %s %s page=%s status=%s latency=%s conn=%s\n",
+		request.Method, request.URL.Path, page, status, round(latency), conn,
+	)
+	return
+}
+
+// Summary writes to the given writer the number of requests sent, the time spent waiting for
+// them, the given total time of the command and the number of connections opened and reused.
+func Summary(out io.Writer, elapsed time.Duration) {
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+	fmt.Fprintf(
+		out,
+		"TRACE requests=%d latency=%s total=%s connections=%d reused=%d\n",
+		stats.requests, round(stats.latency), round(elapsed), stats.connections, stats.reused,
+	)
+}
+
+// round rounds durations to milliseconds, as more precision isn't useful for requests sent over
+// the network.
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
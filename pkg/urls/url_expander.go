@@ -18,6 +18,8 @@ package urls
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 )
 
 // Resources that return a list of multiple items
@@ -88,6 +90,65 @@ func Expand(argv []string) (string, error) {
 	return preParsePath, nil
 }
 
+// ExpandID returns the full URI of the object with the given identifier. The resource can be the
+// alias of an individual resource, like 'cluster', the alias of a list of resources, like
+// 'clusters', or the path of a collection, like '/api/clusters_mgmt/v1/clusters'.
+func ExpandID(resource, id string) (string, error) {
+	id = url.PathEscape(id)
+	if path, ok := individualResourceURLs[resource]; ok {
+		return fmt.Sprintf(path, id), nil
+	}
+	if path, ok := listResourceURLs[resource]; ok {
+		return path + "/" + id, nil
+	}
+	if strings.HasPrefix(resource, "/") {
+		return strings.TrimSuffix(resource, "/") + "/" + id, nil
+	}
+	return "", fmt.Errorf("Unknown resource '%s'", resource)
+}
+
+// Resources of clusters, that require the identifier of the cluster and the identifier of the
+// object.
+var clusterResourceURLs = map[string]string{
+	"addon":        "/api/clusters_mgmt/v1/clusters/%s/addons/%s",
+	"idp":          "/api/clusters_mgmt/v1/clusters/%s/identity_providers/%s",
+	"ingress":      "/api/clusters_mgmt/v1/clusters/%s/ingresses/%s",
+	"machinepool":  "/api/clusters_mgmt/v1/clusters/%s/machine_pools/%s",
+	"machine_pool": "/api/clusters_mgmt/v1/clusters/%s/machine_pools/%s",
+	"user":         "/api/clusters_mgmt/v1/clusters/%s/groups/dedicated-admins/users/%s",
+}
+
+// ExpandReference returns the full URI of the object referenced by the given text, which has the
+// form 'KIND/ID' for objects like clusters and subscriptions, for example 'cluster/123', or the
+// form 'KIND/CLUSTER_ID/ID' for objects that belong to a cluster, for example
+// 'machinepool/123/worker'.
+func ExpandReference(reference string) (string, error) {
+	parts := strings.Split(reference, "/")
+	for _, part := range parts {
+		if part == "" {
+			parts = nil
+			break
+		}
+	}
+	switch len(parts) {
+	case 2:
+		path, ok := individualResourceURLs[parts[0]]
+		if ok && strings.HasPrefix(path, "/api/") {
+			return fmt.Sprintf(path, url.PathEscape(parts[1])), nil
+		}
+	case 3:
+		path, ok := clusterResourceURLs[parts[0]]
+		if ok {
+			return fmt.Sprintf(path, url.PathEscape(parts[1]), url.PathEscape(parts[2])), nil
+		}
+	}
+	return "", fmt.Errorf(
+		"Reference '%s' isn't valid, it should be 'KIND/ID' or 'KIND/CLUSTER_ID/ID', "+
+			"for example 'cluster/123' or 'machinepool/123/worker'",
+		reference,
+	)
+}
+
 func Resources() []string {
 	resources := make([]string, 0)
 	for r := range listResourceURLs {
@@ -425,4 +425,42 @@ var _ = Describe("Resources", func() {
 			},
 		),
 	)
+
+	DescribeTable(
+		"Expand reference",
+		func(reference, expected string) {
+			path, err := ExpandReference(reference)
+			if expected == "" {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(path).To(Equal(expected))
+			}
+		},
+		Entry(
+			"Cluster",
+			"cluster/123",
+			"/api/clusters_mgmt/v1/clusters/123",
+		),
+		Entry(
+			"Subscription",
+			"subscription/456",
+			"/api/accounts_mgmt/v1/subscriptions/456",
+		),
+		Entry(
+			"Machine pool",
+			"machinepool/123/worker",
+			"/api/clusters_mgmt/v1/clusters/123/machine_pools/worker",
+		),
+		Entry(
+			"Escapes identifiers",
+			"cluster/a b",
+			"/api/clusters_mgmt/v1/clusters/a%20b",
+		),
+		Entry("Unknown kind", "junk/123", ""),
+		Entry("Missing identifier", "cluster", ""),
+		Entry("Empty identifier", "cluster/", ""),
+		Entry("Missing cluster", "machinepool/worker", ""),
+		Entry("Not an API resource", "ingress/123", ""),
+	)
 })
@@ -18,5 +18,47 @@ limitations under the License.
 
 package urls
 
+import (
+	"strings"
+)
+
+// URLs of the well known OCM environments:
+const (
+	ProductionURL  = "https://api.openshift.com"
+	StagingURL     = "https://api.stage.openshift.com"
+	IntegrationURL = "https://api.integration.openshift.com"
+)
+
+// Details of the local sandbox server started with the 'ocm sandbox start' command. It accepts any
+// credentials, but these are used by default when logging in to it.
+const (
+	SandboxAddress      = "127.0.0.1:9900"
+	SandboxURL          = "http://" + SandboxAddress
+	SandboxTokenPath    = "/auth/realms/redhat-external/protocol/openid-connect/token"
+	SandboxTokenURL     = SandboxURL + SandboxTokenPath
+	SandboxClientID     = "sandbox"
+	SandboxClientSecret = "sandbox" // #nosec G101
+)
+
 // OfflineTokenPage is the URL of the page used to generate offline access tokens.
 const OfflineTokenPage = "https://console.redhat.com/openshift/token" // #nosec G101
+
+// ConsolePage is the URL of the web console.
+const ConsolePage = "https://console.redhat.com/openshift"
+
+// Environment returns the name of the well known environment that corresponds to the given API
+// URL, or an empty string if it isn't one of them.
+func Environment(url string) string {
+	switch strings.TrimSuffix(url, "/") {
+	case ProductionURL:
+		return "production"
+	case StagingURL:
+		return "staging"
+	case IntegrationURL:
+		return "integration"
+	case SandboxURL:
+		return "sandbox"
+	default:
+		return ""
+	}
+}
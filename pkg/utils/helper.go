@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/url"
+	"regexp"
+	"strconv"
+	"time"
 )
 
 func ValidateHTTPProxy(val interface{}) error {
@@ -60,3 +63,46 @@ func ValidateAdditionalTrustBundle(val interface{}) error {
 	}
 	return fmt.Errorf("can only validate strings, got %v", val)
 }
+
+// relativeDaysRE is the regular expression used to parse durations given as a number of days or
+// weeks, like '7d' or '2w', which aren't supported by time.ParseDuration.
+var relativeDaysRE = regexp.MustCompile(`^(\d+)([dw])$`)
+
+// ParseTime parses the value of options like '--since' and '--until'. The value can be a time in
+// RFC3339 format, a date like '2022-01-01' or a duration that is subtracted from the given current
+// time. Durations can use the syntax of time.ParseDuration, like '2h', or a number of days or
+// weeks, like '7d' or '2w'. An empty value results in the zero time.
+func ParseTime(value string, now time.Time) (result time.Time, err error) {
+	if value == "" {
+		return
+	}
+	duration, err := time.ParseDuration(value)
+	if err == nil {
+		result = now.Add(-duration)
+		return
+	}
+	matches := relativeDaysRE.FindStringSubmatch(value)
+	if matches != nil {
+		var count int
+		count, err = strconv.Atoi(matches[1])
+		if err == nil {
+			if matches[2] == "w" {
+				count *= 7
+			}
+			result = now.AddDate(0, 0, -count)
+			return
+		}
+	}
+	result, err = time.Parse(time.RFC3339, value)
+	if err == nil {
+		return
+	}
+	result, err = time.Parse("2006-01-02", value)
+	if err != nil {
+		err = fmt.Errorf(
+			"'%s' isn't a valid RFC3339 time, date or duration",
+			value,
+		)
+	}
+	return
+}
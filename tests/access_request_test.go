@@ -0,0 +1,213 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Access requests", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	// pendingList is the list of access requests returned by the server in most tests:
+	const pendingList = `{
+		"kind": "AccessRequestList",
+		"page": 1,
+		"size": 1,
+		"total": 1,
+		"items": [
+			{
+				"kind": "AccessRequest",
+				"id": "abc",
+				"cluster_id": "123",
+				"requested_by": "jdoe",
+				"justification": "Fix ingress",
+				"deadline": "2026-10-20T10:00:00Z",
+				"status": {
+					"state": "Pending"
+				}
+			}
+		]
+	}`
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Lists the pending access requests", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/access_transparency/v1/access_requests",
+					"page=1&search=status.state+%3D+%27Pending%27&size=100",
+				),
+				RespondWithJSON(http.StatusOK, pendingList),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("access-request", "list", "--pending").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[0]).To(MatchRegexp(
+			`^ID\s+CLUSTER ID\s+REQUESTED BY\s+STATE\s+DEADLINE\s+JUSTIFICATION$`,
+		))
+		Expect(lines[1]).To(MatchRegexp(
+			`^abc\s+123\s+jdoe\s+Pending\s+2026-10-20T10:00:00Z\s+Fix ingress$`,
+		))
+	})
+
+	It("Approves an access request", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/access_transparency/v1/access_requests/abc/decisions",
+				),
+				VerifyJSON(`{
+					"decision": "Approved",
+					"justification": "Go ahead"
+				}`),
+				RespondWithJSON(http.StatusCreated, `{}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("access-request", "approve", "abc", "--justification", "Go ahead").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal("Access request 'abc' approved\n"))
+	})
+
+	It("Rejects decisions in read-only mode", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_READ_ONLY", "true").
+			Args("access-request", "approve", "abc", "--justification", "Go ahead").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("read-only mode is enabled"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Requires a justification to deny an access request", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("access-request", "deny", "abc").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("justification"))
+	})
+
+	It("Describes the deny command", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("access-request", "deny", "--help").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(HavePrefix("Deny the access request"))
+	})
+
+	It("Runs the hook for new pending access requests when watching", func() {
+		tmpDir, err := os.MkdirTemp("", "ocm-test-*")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		hookFile := filepath.Join(tmpDir, "hook.txt")
+
+		// The second time the list is requested the server fails, so that the command
+		// finishes:
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, pendingList),
+			RespondWithJSON(http.StatusOK, pendingList),
+			RespondWithJSON(
+				http.StatusBadRequest,
+				`{
+					"kind": "Error",
+					"id": "400",
+					"reason": "Boom"
+				}`,
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"access-request", "list",
+				"--watch",
+				"--interval", "10ms",
+				"--hook", "echo $OCM_ACCESS_REQUEST_ID >> "+hookFile,
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Boom"))
+		Expect(result.OutLines()).To(HaveLen(2))
+		data, err := os.ReadFile(hookFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("abc\n"))
+	})
+})
@@ -0,0 +1,453 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Account invitations", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// currentAccount is the response for the account of the current user.
+	currentAccount := CombineHandlers(
+		VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/current_account"),
+		RespondWithJSON(http.StatusOK, `{
+			"kind": "Account",
+			"id": "111",
+			"organization": {
+				"kind": "Organization",
+				"id": "333"
+			}
+		}`),
+	)
+
+	// noAccounts is the response to a search of accounts that doesn't find anything.
+	noAccounts := RespondWithJSON(http.StatusOK, `{
+		"kind": "AccountList",
+		"page": 1,
+		"size": 0,
+		"total": 0,
+		"items": []
+	}`)
+
+	// invitedAccount is the response to a search that finds the invited account with the given
+	// status label.
+	invitedAccount := func(status string) http.HandlerFunc {
+		return RespondWithJSON(http.StatusOK, `{
+			"kind": "AccountList",
+			"page": 1,
+			"size": 1,
+			"total": 1,
+			"items": [
+				{
+					"kind": "Account",
+					"id": "444",
+					"email": "jdoe@example.com",
+					"username": "jdoe@example.com",
+					"labels": [
+						{
+							"kind": "Label",
+							"key": "invitation",
+							"value": "`+status+`"
+						}
+					]
+				}
+			]
+		}`)
+	}
+
+	When("Inviting a user", func() {
+		It("Creates the account, the label and the role bindings", func() {
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/roles/ClusterEditor"),
+					RespondWithJSON(http.StatusOK, `{
+						"kind": "Role",
+						"id": "ClusterEditor"
+					}`),
+				),
+				currentAccount,
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+					VerifyFormKV(
+						"search",
+						"email = 'jdoe@example.com' and organization_id = '333'",
+					),
+					noAccounts,
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodPost, "/api/accounts_mgmt/v1/accounts"),
+					VerifyJSON(`{
+						"kind": "Account",
+						"email": "jdoe@example.com",
+						"username": "jdoe",
+						"organization": {
+							"kind": "Organization",
+							"id": "333"
+						}
+					}`),
+					RespondWithJSON(http.StatusCreated, `{
+						"kind": "Account",
+						"id": "444"
+					}`),
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodPost, "/api/accounts_mgmt/v1/accounts/444/labels"),
+					VerifyJSON(`{
+						"kind": "Label",
+						"key": "invitation",
+						"value": "pending"
+					}`),
+					RespondWithJSON(http.StatusCreated, "{}"),
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodPost, "/api/accounts_mgmt/v1/role_bindings"),
+					VerifyJSON(`{
+						"kind": "RoleBinding",
+						"type": "Organization",
+						"account": {
+							"kind": "Account",
+							"id": "444"
+						},
+						"account_id": "444",
+						"role": {
+							"kind": "Role",
+							"id": "ClusterEditor"
+						},
+						"role_id": "ClusterEditor",
+						"organization": {
+							"kind": "Organization",
+							"id": "333"
+						},
+						"organization_id": "333"
+					}`),
+					RespondWithJSON(http.StatusCreated, "{}"),
+				),
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"account", "users", "invite", "jdoe@example.com",
+					"--roles", "ClusterEditor",
+					"--username", "jdoe",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.OutString()).To(Equal("Invited 'jdoe@example.com' to organization '333'\n"))
+		})
+
+		It("Only prints the creation of the account in curl mode", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Role",
+					"id": "ClusterEditor"
+				}`),
+				currentAccount,
+				noAccounts,
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"account", "users", "invite", "jdoe@example.com",
+					"--roles", "ClusterEditor",
+					"--curl",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("--request POST"))
+			Expect(result.OutString()).To(BeEmpty())
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		It("Rejects roles that don't exist", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusNotFound, `{
+					"kind": "Error",
+					"id": "404",
+					"reason": "Not found"
+				}`),
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Args("account", "users", "invite", "jdoe@example.com", "--roles", "Junk").
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("Role 'Junk' doesn't exist"))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("Rejects users that have already been invited", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{}`),
+				currentAccount,
+				invitedAccount("pending"),
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Args("account", "users", "invite", "jdoe@example.com", "--roles", "ClusterEditor").
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"User with email 'jdoe@example.com' has already been invited to organization '333'",
+			))
+		})
+
+		It("Rejects invalid email addresses", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("account", "users", "invite", "jdoe' or '1'='1", "--roles", "ClusterEditor").
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("isn't valid"))
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+
+	When("Listing invitations", func() {
+		BeforeEach(func() {
+			apiServer.AppendHandlers(
+				currentAccount,
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+					VerifyFormKV("search", "organization_id = '333'"),
+					VerifyFormKV("fetchLabels", "true"),
+					RespondWithJSON(http.StatusOK, `{
+						"kind": "AccountList",
+						"page": 1,
+						"size": 3,
+						"total": 3,
+						"items": [
+							{
+								"kind": "Account",
+								"id": "111",
+								"email": "admin@example.com",
+								"username": "admin"
+							},
+							{
+								"kind": "Account",
+								"id": "444",
+								"email": "jdoe@example.com",
+								"username": "jdoe",
+								"created_at": "2026-10-01T10:00:00Z",
+								"labels": [
+									{
+										"kind": "Label",
+										"key": "invitation",
+										"value": "pending"
+									}
+								]
+							},
+							{
+								"kind": "Account",
+								"id": "555",
+								"email": "asmith@example.com",
+								"username": "asmith",
+								"rhit_account_id": "12345",
+								"created_at": "2026-09-01T10:00:00Z",
+								"labels": [
+									{
+										"kind": "Label",
+										"key": "invitation",
+										"value": "pending"
+									}
+								]
+							}
+						]
+					}`),
+				),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "RoleBindingList",
+					"page": 1,
+					"size": 2,
+					"total": 2,
+					"items": [
+						{
+							"kind": "RoleBinding",
+							"account": {
+								"kind": "Account",
+								"id": "444"
+							},
+							"role": {
+								"kind": "Role",
+								"id": "ClusterEditor"
+							}
+						},
+						{
+							"kind": "RoleBinding",
+							"account": {
+								"kind": "Account",
+								"id": "555"
+							},
+							"role": {
+								"kind": "Role",
+								"id": "ClusterViewer"
+							}
+						}
+					]
+				}`),
+			)
+		})
+
+		It("Lists only the pending invitations", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("account", "users", "invitations").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutLines()).To(Equal([]string{
+				"EMAIL             USERNAME  STATUS   ROLES          CREATED",
+				"jdoe@example.com  jdoe      pending  ClusterEditor  2026-10-01",
+			}))
+		})
+
+		It("Lists all the invitations", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("account", "users", "invitations", "--all").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutLines()).To(Equal([]string{
+				"EMAIL               USERNAME  STATUS    ROLES          CREATED",
+				"asmith@example.com  asmith    accepted  ClusterViewer  2026-09-01",
+				"jdoe@example.com    jdoe      pending   ClusterEditor  2026-10-01",
+			}))
+		})
+	})
+
+	When("Cancelling an invitation", func() {
+		It("Removes the roles and marks the invitation as cancelled", func() {
+			apiServer.AppendHandlers(
+				currentAccount,
+				invitedAccount("pending"),
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/role_bindings"),
+					VerifyFormKV("search", "account_id = '444'"),
+					RespondWithJSON(http.StatusOK, `{
+						"kind": "RoleBindingList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "RoleBinding",
+								"id": "666",
+								"role": {
+									"kind": "Role",
+									"id": "ClusterEditor"
+								}
+							}
+						]
+					}`),
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodDelete, "/api/accounts_mgmt/v1/role_bindings/666"),
+					RespondWithJSON(http.StatusOK, "{}"),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPatch,
+						"/api/accounts_mgmt/v1/accounts/444/labels/invitation",
+					),
+					VerifyJSON(`{
+						"kind": "Label",
+						"key": "invitation",
+						"value": "cancelled"
+					}`),
+					RespondWithJSON(http.StatusOK, "{}"),
+				),
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Args("account", "users", "invitations", "cancel", "jdoe@example.com").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(Equal(
+				"Cancelled invitation of 'jdoe@example.com' to organization '333'\n",
+			))
+		})
+
+		It("Refuses to cancel cancelled invitations", func() {
+			apiServer.AppendHandlers(
+				currentAccount,
+				invitedAccount("cancelled"),
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Args("account", "users", "invitations", "cancel", "jdoe@example.com").
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Invitation of 'jdoe@example.com' has already been cancelled",
+			))
+		})
+	})
+})
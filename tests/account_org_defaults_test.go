@@ -0,0 +1,204 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Account org-defaults", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Sets a default and shows the resulting defaults", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/current_account"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "Account",
+						"id": "a1",
+						"organization": {
+							"kind": "Organization",
+							"id": "o1"
+						}
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/accounts_mgmt/v1/organizations/o1/labels/cluster_defaults.channel_group",
+				),
+				RespondWithJSON(http.StatusNotFound, `{"kind": "Error", "id": "404"}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/accounts_mgmt/v1/organizations/o1/labels"),
+				VerifyJQ(`.key`, "cluster_defaults.channel_group"),
+				VerifyJQ(`.value`, "stable"),
+				RespondWithJSON(
+					http.StatusCreated,
+					`{
+						"kind": "Label",
+						"key": "cluster_defaults.channel_group",
+						"value": "stable"
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/organizations/o1/labels"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "LabelList",
+						"page": 1,
+						"size": 4,
+						"total": 4,
+						"items": [
+							{
+								"kind": "Label",
+								"key": "cluster_defaults.channel_group",
+								"value": "stable"
+							},
+							{
+								"kind": "Label",
+								"key": "cluster_defaults.machine_cidr",
+								"value": "10.0.0.0/16"
+							},
+							{
+								"kind": "Label",
+								"key": "cluster_defaults.labels.team",
+								"value": "payments"
+							},
+							{
+								"kind": "Label",
+								"key": "sts_user_role",
+								"value": "true"
+							}
+						]
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "org-defaults", "--set", "channel-group=stable").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(4))
+		Expect(lines[0]).To(MatchRegexp(`^NAME\s+VALUE$`))
+		Expect(lines[1]).To(MatchRegexp(`^channel-group\s+stable$`))
+		Expect(lines[2]).To(MatchRegexp(`^label\.team\s+payments$`))
+		Expect(lines[3]).To(MatchRegexp(`^machine-cidr\s+10\.0\.0\.0/16$`))
+	})
+
+	It("Only prints the change in curl mode", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Account",
+					"id": "a1",
+					"organization": {
+						"kind": "Organization",
+						"id": "o1"
+					}
+				}`,
+			),
+			RespondWithJSON(http.StatusNotFound, `{"kind": "Error", "id": "404"}`),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "org-defaults", "--set", "channel-group=stable", "--curl").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("--request POST"))
+		Expect(result.OutString()).To(BeEmpty())
+	})
+
+	It("Rejects changes in read-only mode without sending requests", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_READ_ONLY", "true").
+			Args("account", "org-defaults", "--unset", "channel-group").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("read-only mode is enabled"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Rejects unknown defaults without sending requests", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "org-defaults", "--set", "region=us-east-1").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Unknown default 'region'"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+})
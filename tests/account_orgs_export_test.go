@@ -0,0 +1,242 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Account orgs export", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server with two organizations, the second one has no quota:
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/organizations",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "OrganizationList",
+					"page": 1,
+					"size": 2,
+					"total": 2,
+					"items": [
+						{
+							"kind": "Organization",
+							"id": "123",
+							"name": "my_org",
+							"external_id": "456"
+						},
+						{
+							"kind": "Organization",
+							"id": "789",
+							"name": "your_org"
+						}
+					]
+				}`,
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/accounts",
+			func(w http.ResponseWriter, r *http.Request) {
+				total := 3
+				if r.URL.Query().Get("search") == "organization.id = '789'" {
+					total = 1
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(
+					w,
+					`{"kind": "AccountList", "page": 1, "size": 1, "total": %d, "items": []}`,
+					total,
+				)
+			},
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/organizations/123/quota_cost",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "QuotaCostList",
+					"page": 1,
+					"size": 2,
+					"total": 2,
+					"items": [
+						{
+							"quota_id": "cluster|byoc|osd",
+							"allowed": 10,
+							"consumed": 4
+						},
+						{
+							"quota_id": "addon|rhods",
+							"allowed": 2,
+							"consumed": 0
+						}
+					]
+				}`,
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/organizations/789/quota_cost",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "QuotaCostList",
+					"page": 1,
+					"size": 0,
+					"total": 0,
+					"items": []
+				}`,
+			),
+		)
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Writes the number of accounts in CSV format", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "orgs", "--export").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutLines()).To(Equal([]string{
+			"id,name,external_id,accounts,error",
+			"123,my_org,456,3,",
+			"789,your_org,,1,",
+		}))
+	})
+
+	It("Adds the quota columns with '--with-quota'", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "orgs", "--export", "--with-quota").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutLines()).To(Equal([]string{
+			"id,name,external_id,accounts," +
+				"addon|rhods.allowed,addon|rhods.consumed," +
+				"cluster|byoc|osd.allowed,cluster|byoc|osd.consumed,error",
+			"123,my_org,456,3,2,0,10,4,",
+			"789,your_org,,1,,,,,",
+		}))
+	})
+
+	It("Writes the report in JSON format", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "orgs", "--export", "--with-quota", "--format", "json").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		var reports []map[string]interface{}
+		err := json.Unmarshal([]byte(result.OutString()), &reports)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reports).To(HaveLen(2))
+		Expect(reports[0]["id"]).To(Equal("123"))
+		Expect(reports[0]["accounts"]).To(BeNumerically("==", 3))
+		Expect(reports[0]["quota"]).To(HaveLen(2))
+		Expect(reports[1]["id"]).To(Equal("789"))
+		Expect(reports[1]).ToNot(HaveKey("quota"))
+	})
+
+	It("Reports the organizations whose details can't be retrieved", func() {
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/organizations/789/quota_cost",
+			RespondWithJSON(
+				http.StatusForbidden,
+				`{
+					"kind": "Error",
+					"id": "403",
+					"reason": "Forbidden"
+				}`,
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "orgs", "--export", "--with-quota").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[1]).To(HavePrefix("123,my_org,456,3,2,0,10,4,"))
+		Expect(lines[2]).To(ContainSubstring("Can't retrieve quota cost"))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Failed to retrieve the details of 1 organizations",
+		))
+	})
+
+	It("Rejects '--with-quota' without '--export'", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "orgs", "--with-quota").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Option '--with-quota' can only be used with '--export'",
+		))
+	})
+})
@@ -19,6 +19,8 @@ package tests
 import (
 	"context"
 	"net/http"
+	"os"
+	"path/filepath"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"    // nolint
@@ -26,6 +28,8 @@ import (
 	. "github.com/onsi/gomega/ghttp" // nolint
 
 	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+
+	"github.com/openshift-online/ocm-cli/pkg/redact"
 )
 
 var _ = Describe("Account orgs", func() {
@@ -144,5 +148,203 @@ var _ = Describe("Account orgs", func() {
 				`^\s*456\s+your_org\s*$`,
 			))
 		})
+
+		It("Replaces the names with hashes when redacting", func() {
+			// Prepare the server:
+			apiServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "OrganizationList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "Organization",
+								"id": "123",
+								"name": "Jane Doe"
+							}
+						]
+					}`,
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Env(redact.KeyEnvVar, "my-key").
+				Args("account", "orgs", "--redact-pii").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.OutString()).ToNot(ContainSubstring("Jane"))
+			lines := result.OutLines()
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[1]).To(MatchRegexp(
+				`^\s*123\s+%s\s*$`, redact.New("my-key").Name("Jane Doe"),
+			))
+		})
+
+		It("Watches for new organizations and runs the hook", func() {
+			tmpDir, err := os.MkdirTemp("", "ocm-test-*")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(tmpDir)
+			hookFile := filepath.Join(tmpDir, "hook.txt")
+
+			// The server doesn't support streaming, so the list is polled. The third time
+			// the list is requested the server fails, so that the command finishes:
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyFormKV("watch", "true"),
+					RespondWithJSON(
+						http.StatusNotFound,
+						`{
+							"kind": "Error",
+							"id": "404",
+							"reason": "Not found"
+						}`,
+					),
+				),
+				CombineHandlers(
+					VerifyFormKV("order", "created_at asc"),
+					RespondWithJSON(
+						http.StatusOK,
+						`{
+							"kind": "OrganizationList",
+							"page": 1,
+							"size": 1,
+							"total": 1,
+							"items": [
+								{
+									"kind": "Organization",
+									"id": "123",
+									"name": "my-partner",
+									"created_at": "2099-01-01T00:00:00Z"
+								}
+							]
+						}`,
+					),
+				),
+				CombineHandlers(
+					VerifyFormKV(
+						"search",
+						"(created_at > '2099-01-01T00:00:00Z') and (name like '%-partner')",
+					),
+					RespondWithJSON(
+						http.StatusOK,
+						`{
+							"kind": "OrganizationList",
+							"page": 1,
+							"size": 2,
+							"total": 2,
+							"items": [
+								{
+									"kind": "Organization",
+									"id": "123",
+									"name": "my-partner",
+									"created_at": "2099-01-01T00:00:00Z"
+								},
+								{
+									"kind": "Organization",
+									"id": "456",
+									"name": "your-partner",
+									"created_at": "2099-01-02T00:00:00Z"
+								}
+							]
+						}`,
+					),
+				),
+				RespondWithJSON(
+					http.StatusBadRequest,
+					`{
+						"kind": "Error",
+						"id": "400",
+						"reason": "Boom"
+					}`,
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"account", "orgs",
+					"--watch-new",
+					"--search", "name like '%-partner'",
+					"--interval", "10ms",
+					"--hook", "echo $OCM_ORG_ID $OCM_ORG_NAME >> "+hookFile,
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("Boom"))
+			lines := result.OutLines()
+			Expect(lines).To(HaveLen(3))
+			Expect(lines[0]).To(MatchRegexp(`^\s*ID\s+NAME\s*$`))
+			Expect(lines[1]).To(MatchRegexp(`^\s*123\s+my-partner\s*$`))
+			Expect(lines[2]).To(MatchRegexp(`^\s*456\s+your-partner\s*$`))
+			data, err := os.ReadFile(hookFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("123 my-partner\n456 your-partner\n"))
+		})
+
+		It("Watches for new organizations using the stream sent by the server", func() {
+			// The server sends one organization and closes the stream, then the command
+			// polls the list and the server fails, so that the command finishes:
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyFormKV("watch", "true"),
+					func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Set("Content-Type", "application/x-ndjson")
+						_, _ = w.Write([]byte(
+							`{"kind": "Organization", "id": "123", "name": "my-partner", ` +
+								`"created_at": "2099-01-01T00:00:00Z"}` + "\n",
+						))
+					},
+				),
+				CombineHandlers(
+					VerifyFormKV(
+						"search",
+						"created_at > '2099-01-01T00:00:00Z'",
+					),
+					RespondWithJSON(
+						http.StatusBadRequest,
+						`{
+							"kind": "Error",
+							"id": "400",
+							"reason": "Boom"
+						}`,
+					),
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"account", "orgs",
+					"--watch-new",
+					"--interval", "10ms",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("Boom"))
+			lines := result.OutLines()
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(MatchRegexp(`^\s*ID\s+NAME\s*$`))
+			Expect(lines[1]).To(MatchRegexp(`^\s*123\s+my-partner\s*$`))
+		})
+
+		It("Rejects the hook without the watch option", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("account", "orgs", "--hook", "true").
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Option '--hook' can only be used with '--watch-new'",
+			))
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		})
 	})
 })
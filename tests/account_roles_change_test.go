@@ -0,0 +1,288 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Account roles grant and revoke", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// accounts returns a handler that responds with the accounts alice (a1) and bob (a2).
+	accounts := func(query string) http.HandlerFunc {
+		return CombineHandlers(
+			VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+			VerifyFormKV("search", query),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "AccountList",
+					"page": 1,
+					"size": 2,
+					"total": 2,
+					"items": [
+						{
+							"kind": "Account",
+							"id": "a1",
+							"username": "alice",
+							"organization": {
+								"kind": "Organization",
+								"id": "o1"
+							}
+						},
+						{
+							"kind": "Account",
+							"id": "a2",
+							"username": "bob",
+							"organization": {
+								"kind": "Organization",
+								"id": "o1"
+							}
+						}
+					]
+				}`,
+			),
+		)
+	}
+
+	// bindings returns a handler that responds with a role binding that gives the
+	// 'ClusterEditor' role to bob.
+	bindings := func(query string) http.HandlerFunc {
+		return CombineHandlers(
+			VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/role_bindings"),
+			VerifyFormKV("search", query),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "RoleBindingList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "RoleBinding",
+							"id": "rb2",
+							"type": "Organization",
+							"account": {
+								"kind": "AccountLink",
+								"id": "a2"
+							},
+							"role": {
+								"kind": "RoleLink",
+								"id": "ClusterEditor"
+							}
+						}
+					]
+				}`,
+			),
+		)
+	}
+
+	It("Grants the role to the users that don't have it", func() {
+		apiServer.AppendHandlers(
+			accounts("username in ('alice', 'bob')"),
+			bindings("account_id in ('a1', 'a2') and type='Organization'"),
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/accounts_mgmt/v1/role_bindings"),
+				VerifyJQ(`.type`, "Organization"),
+				VerifyJQ(`.account.id`, "a1"),
+				VerifyJQ(`.role.id`, "ClusterEditor"),
+				VerifyJQ(`.organization.id`, "o1"),
+				RespondWithJSON(http.StatusCreated, `{}`),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"account", "roles", "grant", "ClusterEditor",
+				"--user", "alice",
+				"--user", "bob",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutLines()).To(Equal([]string{
+			"alice: grant role 'ClusterEditor'",
+			"bob: already has role 'ClusterEditor'",
+			"",
+			"Users: 2, granted: 1, skipped: 1, failed: 0",
+		}))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	When("Reading the users from a file", func() {
+		var tmpDir string
+		var file string
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = os.MkdirTemp("", "ocm-test-*")
+			Expect(err).ToNot(HaveOccurred())
+			file = filepath.Join(tmpDir, "users.csv")
+			err = os.WriteFile(file, []byte(
+				"username,role\n"+
+					"alice\n"+
+					"bob,ClusterEditor\n"+
+					"carol\n",
+			), 0600)
+			Expect(err).ToNot(HaveOccurred())
+			apiServer.AppendHandlers(
+				accounts("username in ('alice', 'bob', 'carol') and organization_id='o1'"),
+				bindings(
+					"account_id in ('a1', 'a2') and type='Organization' and "+
+						"organization_id='o1'",
+				),
+			)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("Shows the changes without applying them in dry run mode", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"account", "roles", "revoke", "ClusterEditor",
+					"--from-file", file,
+					"--org", "o1",
+					"--dry-run",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(Equal(2))
+			Expect(result.OutLines()).To(Equal([]string{
+				"alice: doesn't have role 'ClusterEditor'",
+				"bob: revoke role 'ClusterEditor'",
+				"",
+				"Users: 3, to be revoked: 1, skipped: 1, failed: 1",
+			}))
+			Expect(result.ErrString()).To(ContainSubstring(
+				"carol: user 'carol' doesn't exist",
+			))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("Revokes the roles and writes the results file", func() {
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodDelete,
+						"/api/accounts_mgmt/v1/role_bindings/rb2",
+					),
+					RespondWithJSON(http.StatusNoContent, ""),
+				),
+			)
+			results := filepath.Join(tmpDir, "results.json")
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"account", "roles", "revoke", "ClusterEditor",
+					"--from-file", file,
+					"--org", "o1",
+					"--results", results,
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(Equal(2))
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Failed to revoke roles: 1 of 3 items failed",
+			))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(3))
+			data, err := os.ReadFile(results)
+			Expect(err).ToNot(HaveOccurred())
+			var content struct {
+				Items []struct {
+					ID     string `json:"id"`
+					Action string `json:"action"`
+					Status string `json:"status"`
+				} `json:"items"`
+			}
+			err = json.Unmarshal(data, &content)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content.Items).To(HaveLen(3))
+			Expect(content.Items[0].ID).To(Equal("alice"))
+			Expect(content.Items[0].Status).To(Equal("skipped"))
+			Expect(content.Items[1].ID).To(Equal("bob"))
+			Expect(content.Items[1].Action).To(Equal("revoke ClusterEditor"))
+			Expect(content.Items[1].Status).To(Equal("succeeded"))
+			Expect(content.Items[2].ID).To(Equal("carol"))
+			Expect(content.Items[2].Status).To(Equal("failed"))
+		})
+	})
+
+	It("Requires the role when using the user option", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "roles", "grant", "--user", "alice").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Role is mandatory when using the '--user' option",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,249 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Account service accounts", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	// The path of the service accounts API inside the realm of the SSO server:
+	const saPath = "/auth/realms/my-external-realm/apis/service_accounts/v1"
+
+	// The service account returned by the server when creating it or rotating its secret:
+	const created = `{
+		"id": "456",
+		"clientId": "my-client-id",
+		"secret": "my-client-secret",
+		"name": "my-pipeline",
+		"createdBy": "jdoe",
+		"createdAt": 1640995200000
+	}`
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL()+"/auth/realms/my-external-realm/protocol/openid-connect/token",
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Creates a service account and prints the secret", func() {
+		ssoServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, saPath),
+				VerifyJSON(`{
+					"name": "my-pipeline",
+					"description": "My pipeline"
+				}`),
+				RespondWithJSON(http.StatusCreated, created),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "sa", "create", "my-pipeline", "--description", "My pipeline").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchRegexp(`Client ID:\s+my-client-id`))
+		Expect(result.OutString()).To(MatchRegexp(`Client secret:\s+my-client-secret`))
+		Expect(result.ErrString()).To(ContainSubstring("only shown once"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Writes the secret to a file", func() {
+		ssoServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, saPath+"/456/resetSecret"),
+				RespondWithJSON(http.StatusOK, created),
+			),
+		)
+
+		tmpDir, err := os.MkdirTemp("", "ocm-test-*")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		file := filepath.Join(tmpDir, "secret.txt")
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "sa", "rotate-secret", "456", "--secret-file", file).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).ToNot(ContainSubstring("my-client-secret"))
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("my-client-secret\n"))
+		info, err := os.Stat(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("Lists service accounts", func() {
+		ssoServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, saPath, "first=0&max=100"),
+				RespondWithJSON(http.StatusOK, `[
+					{
+						"id": "456",
+						"clientId": "my-client-id",
+						"name": "my-pipeline",
+						"createdBy": "jdoe",
+						"createdAt": 1640995200000
+					}
+				]`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "sa", "list").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[1]).To(MatchRegexp(
+			`^456\s+my-client-id\s+my-pipeline\s+jdoe\s+2022-01-01T00:00:00Z$`,
+		))
+	})
+
+	It("Deletes service accounts", func() {
+		ssoServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodDelete, saPath+"/456"),
+				RespondWith(
+					http.StatusNoContent,
+					nil,
+					http.Header{"Content-Type": []string{"application/json"}},
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "sa", "delete", "456").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring("Deleted service account '456'"))
+	})
+
+	DescribeTable(
+		"Rejects changes in read-only mode and with the cache",
+		func(args ...string) {
+			result := NewCommand().
+				ConfigString(config).
+				Env("OCM_READ_ONLY", "true").
+				Args(args...).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("read-only mode is enabled"))
+
+			result = NewCommand().
+				ConfigString(config).
+				Args(append(args, "--cached")...).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"can only be used with commands that don't change the server",
+			))
+		},
+		Entry("Create", "account", "sa", "create", "my-pipeline"),
+		Entry("Rotate", "account", "sa", "rotate-secret", "456"),
+		Entry("Delete", "account", "sa", "delete", "456"),
+	)
+
+	It("Reports the errors of the SSO server", func() {
+		ssoServer.AppendHandlers(
+			RespondWithJSON(http.StatusForbidden, `{
+				"error": "forbidden",
+				"error_description": "Service accounts limit reached"
+			}`),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "sa", "create", "my-pipeline").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Service accounts limit reached"))
+	})
+
+	It("Fails if the SSO server doesn't support service accounts", func() {
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(MakeTokenString("Bearer", 15*time.Minute)),
+		)
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+
+		result = NewCommand().
+			ConfigString(result.ConfigString()).
+			Args("account", "sa", "list").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("service accounts aren't supported"))
+	})
+})
@@ -0,0 +1,277 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Account users offboard", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// prepareUsers prepares the server so that it returns the accounts of 'bob' and 'alice',
+	// with the given subscriptions owned by 'bob':
+	prepareUsers := func(subscriptions string) {
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/accounts",
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Query().Get("search") {
+				case "username = 'bob'":
+					fmt.Fprint(w, `{
+						"kind": "AccountList",
+						"items": [{
+							"id": "111",
+							"username": "bob",
+							"organization": {"id": "org1"}
+						}]
+					}`)
+				case "username = 'alice'":
+					fmt.Fprint(w, `{
+						"kind": "AccountList",
+						"items": [{
+							"id": "222",
+							"username": "alice",
+							"organization": {"id": "org1"}
+						}]
+					}`)
+				default:
+					fmt.Fprint(w, `{"kind": "AccountList", "items": []}`)
+				}
+			},
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/subscriptions",
+			CombineHandlers(
+				VerifyFormKV(
+					"search",
+					"creator_id = '111' and status in "+
+						"('Active', 'Disconnected', 'Reserved', 'Stale')",
+				),
+				RespondWithJSON(http.StatusOK, subscriptions),
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/role_bindings",
+			CombineHandlers(
+				VerifyFormKV("search", "account_id = '111'"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "RoleBindingList",
+						"items": [
+							{
+								"id": "rb1",
+								"type": "Organization",
+								"role": {"id": "OrganizationAdmin"}
+							},
+							{
+								"id": "rb2",
+								"type": "Subscription",
+								"role": {"id": "ClusterEditor"},
+								"subscription": {"id": "sub2"}
+							}
+						]
+					}`,
+				),
+			),
+		)
+	}
+
+	ownedSubscriptions := `{
+		"kind": "SubscriptionList",
+		"items": [{
+			"id": "sub1",
+			"display_name": "my-cluster",
+			"status": "Active"
+		}]
+	}`
+
+	It("Transfers the clusters and removes the roles", func() {
+		prepareUsers(ownedSubscriptions)
+		apiServer.RouteToHandler(
+			http.MethodPatch,
+			"/api/accounts_mgmt/v1/subscriptions/sub1",
+			CombineHandlers(
+				VerifyJQ(`.creator.id`, "222"),
+				RespondWithJSON(http.StatusOK, `{"id": "sub1"}`),
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodDelete,
+			"/api/accounts_mgmt/v1/role_bindings/rb1",
+			RespondWithJSON(http.StatusNoContent, ""),
+		)
+		apiServer.RouteToHandler(
+			http.MethodDelete,
+			"/api/accounts_mgmt/v1/role_bindings/rb2",
+			RespondWithJSON(http.StatusNoContent, ""),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "offboard", "bob", "--successor", "alice").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		lines := result.OutLines()
+		Expect(lines).To(ContainElement("Transfer cluster 'my-cluster' to 'alice'"))
+		Expect(lines).To(ContainElement(
+			MatchRegexp(`^sub1\s+my-cluster\s+transfer to alice\s+succeeded$`),
+		))
+		Expect(lines).To(ContainElement(
+			MatchRegexp(`^rb1\s+Organization\s+remove OrganizationAdmin role\s+succeeded$`),
+		))
+		Expect(lines).To(ContainElement(
+			MatchRegexp(`^rb2\s+Subscription sub2\s+remove ClusterEditor role\s+succeeded$`),
+		))
+		Expect(lines[len(lines)-1]).To(Equal(
+			"Clusters transferred: 1, roles removed: 2, failed: 0",
+		))
+	})
+
+	It("Doesn't change anything in dry run mode", func() {
+		prepareUsers(ownedSubscriptions)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"account", "users", "offboard", "bob",
+				"--successor", "alice",
+				"--dry-run",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(ContainElement(
+			MatchRegexp(`^sub1\s+my-cluster\s+transfer to alice\s+pending$`),
+		))
+		Expect(lines[len(lines)-1]).To(Equal(
+			"Clusters to be transferred: 1, roles to be removed: 2, failed: 0",
+		))
+		for _, request := range apiServer.ReceivedRequests() {
+			Expect(request.Method).To(Equal(http.MethodGet))
+		}
+	})
+
+	It("Requires a successor when the user owns clusters", func() {
+		prepareUsers(ownedSubscriptions)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "offboard", "bob").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"User 'bob' owns 1 clusters, use the '--successor' option",
+		))
+		for _, request := range apiServer.ReceivedRequests() {
+			Expect(request.Method).To(Equal(http.MethodGet))
+		}
+	})
+
+	It("Reports partial failures", func() {
+		prepareUsers(`{"kind": "SubscriptionList", "items": []}`)
+		apiServer.RouteToHandler(
+			http.MethodDelete,
+			"/api/accounts_mgmt/v1/role_bindings/rb1",
+			RespondWithJSON(http.StatusNoContent, ""),
+		)
+		apiServer.RouteToHandler(
+			http.MethodDelete,
+			"/api/accounts_mgmt/v1/role_bindings/rb2",
+			RespondWithJSON(
+				http.StatusForbidden,
+				`{"kind": "Error", "id": "403", "reason": "Forbidden"}`,
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "offboard", "bob").
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(2))
+		Expect(result.OutLines()).To(ContainElement(
+			MatchRegexp(`^rb2\s+Subscription sub2\s+remove ClusterEditor role\s+failed$`),
+		))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Failed to offboard user 'bob': 1 of 2 items failed",
+		))
+	})
+
+	It("Fails if the user doesn't exist", func() {
+		prepareUsers(ownedSubscriptions)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "offboard", "carol").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("User 'carol' doesn't exist"))
+	})
+})
@@ -0,0 +1,1078 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+
+	"github.com/openshift-online/ocm-cli/pkg/redact"
+)
+
+var _ = Describe("Account users", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Shows the organization of each user when searching by role", func() {
+		// Note that the role bindings are filtered in the server, that the accounts are then
+		// retrieved by identifier, and that the organization is retrieved only once even if
+		// there are two users in the same organization:
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/role_bindings"),
+				VerifyFormKV("search", "role_id in ('OrganizationAdmin')"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "RoleBindingList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a1"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "OrganizationAdmin"
+								}
+							},
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a2"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "OrganizationAdmin"
+								}
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+				VerifyFormKV("search", "id in ('a1', 'a2')"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "AccountList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"kind": "Account",
+								"id": "a1",
+								"username": "alice",
+								"organization": {
+									"kind": "OrganizationLink",
+									"id": "o1"
+								}
+							},
+							{
+								"kind": "Account",
+								"id": "a2",
+								"username": "bob",
+								"organization": {
+									"kind": "OrganizationLink",
+									"id": "o1"
+								}
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/organizations/o1"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "Organization",
+						"id": "o1",
+						"name": "My Org"
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/role_bindings"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "RoleBindingList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a1"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "OrganizationAdmin"
+								}
+							},
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a2"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "OrganizationAdmin"
+								}
+							}
+						]
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "--roles", "OrganizationAdmin").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(MatchRegexp(`^USER\s+USER ID\s+ORG ID\s+ORG NAME\s+ROLES\s*$`))
+		Expect(lines[1:]).To(ConsistOf(
+			MatchRegexp(`^alice\s+a1\s+o1\s+My Org\s+OrganizationAdmin\s*$`),
+			MatchRegexp(`^bob\s+a2\s+o1\s+My Org\s+OrganizationAdmin\s*$`),
+		))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(4))
+	})
+
+	It("Shows each user once in the order of the role bindings", func() {
+		binding := func(account, role string) string {
+			return fmt.Sprintf(
+				`{
+					"kind": "RoleBinding",
+					"account": {"kind": "AccountLink", "id": "%s"},
+					"role": {"kind": "RoleLink", "id": "%s"}
+				}`,
+				account, role,
+			)
+		}
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/role_bindings"),
+				VerifyFormKV(
+					"search",
+					"role_id in ('OrganizationAdmin', 'ClusterEditor') and "+
+						"organization_id='o1'",
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					fmt.Sprintf(
+						`{"kind": "RoleBindingList", "page": 1, "size": 3, "items": [%s]}`,
+						strings.Join([]string{
+							binding("a2", "ClusterEditor"),
+							binding("a1", "OrganizationAdmin"),
+							binding("a2", "OrganizationAdmin"),
+						}, ","),
+					),
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+				VerifyFormKV("search", "id in ('a2', 'a1') and (organization_id='o1')"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "AccountList",
+						"page": 1,
+						"size": 2,
+						"items": [
+							{"kind": "Account", "id": "a1", "username": "alice"},
+							{"kind": "Account", "id": "a2", "username": "bob"}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/role_bindings"),
+				VerifyFormKV("search", "account_id in ('a2', 'a1')"),
+				RespondWithJSON(
+					http.StatusOK,
+					fmt.Sprintf(
+						`{"kind": "RoleBindingList", "page": 1, "size": 3, "items": [%s]}`,
+						strings.Join([]string{
+							binding("a2", "OrganizationAdmin"),
+							binding("a2", "ClusterEditor"),
+							binding("a1", "OrganizationAdmin"),
+						}, ","),
+					),
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"account", "users",
+				"--org", "o1",
+				"--roles", "OrganizationAdmin",
+				"--roles", "ClusterEditor",
+			).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[1]).To(MatchRegexp(`^bob\s+a2\s+ClusterEditor OrganizationAdmin\s*$`))
+		Expect(lines[2]).To(MatchRegexp(`^alice\s+a1\s+OrganizationAdmin\s*$`))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	It("Pauses and resumes when the server throttles the role requests", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "AccountList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "Account",
+								"id": "a1",
+								"username": "alice"
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/role_bindings"),
+				RespondWith(
+					http.StatusForbidden,
+					`{
+						"kind": "Error",
+						"id": "403",
+						"reason": "Too many requests"
+					}`,
+					http.Header{
+						"Content-Type": []string{"application/json"},
+						"Retry-After":  []string{"1"},
+					},
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/role_bindings"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "RoleBindingList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a1"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "OrganizationAdmin"
+								}
+							}
+						]
+					}`,
+				),
+			),
+		)
+
+		start := time.Now()
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "--org", "o1").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+		Expect(result.ErrString()).To(Equal(
+			"Server is throttling requests (status 403), pausing for 1s\n",
+		))
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[1]).To(MatchRegexp(`^alice\s+a1\s+OrganizationAdmin\s*$`))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	It("Doesn't pause when permission is denied", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+				RespondWithJSON(
+					http.StatusForbidden,
+					`{
+						"kind": "Error",
+						"id": "403",
+						"reason": "Forbidden"
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "--org", "o1").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).ToNot(ContainSubstring("pausing"))
+		Expect(result.ErrString()).To(ContainSubstring("Can't retrieve accounts"))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("Shows only users with the minimum role level", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "AccountList",
+						"page": 1,
+						"size": 3,
+						"total": 3,
+						"items": [
+							{
+								"kind": "Account",
+								"id": "a1",
+								"username": "alice"
+							},
+							{
+								"kind": "Account",
+								"id": "a2",
+								"username": "bob"
+							},
+							{
+								"kind": "Account",
+								"id": "a3",
+								"username": "carol"
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/role_bindings"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "RoleBindingList",
+						"page": 1,
+						"size": 4,
+						"total": 4,
+						"items": [
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a1"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "ClusterViewer"
+								}
+							},
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a2"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "ClusterEditor"
+								}
+							},
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a2"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "OrganizationAdmin"
+								}
+							},
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a3"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "UHCAdmin"
+								}
+							}
+						]
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "--org", "o1", "--min-role-level", "OrganizationAdmin").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[1:]).To(ConsistOf(
+			MatchRegexp(`^bob\s+a2\s+`),
+			MatchRegexp(`^carol\s+a3\s+UHCAdmin\s*$`),
+		))
+	})
+
+	It("Rejects unknown minimum role levels", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "--min-role-level", "Junk").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Unknown role 'Junk' for option '--min-role-level', valid values are: " +
+				"AuthenticatedUser, ClusterViewer, ClusterEditor",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Resumes an interrupted scan from the page where it stopped", func() {
+		// Use a temporary directory for the state files:
+		tmpDir, err := os.MkdirTemp("", "ocm-resume-*")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+
+		// Prepare a first page that is full, so that the command requests the second one:
+		items := make([]string, 100)
+		for i := range items {
+			items[i] = fmt.Sprintf(`{"kind": "Account", "id": "a%d", "username": "u%d"}`, i, i)
+		}
+		firstPage := fmt.Sprintf(
+			`{"kind": "AccountList", "page": 1, "size": 100, "items": [%s]}`,
+			strings.Join(items, ","),
+		)
+		noRoles := `{"kind": "RoleBindingList", "page": 1, "size": 0, "items": []}`
+
+		// The first run fails when retrieving the second page:
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyFormKV("page", "1"),
+				RespondWithJSON(http.StatusOK, firstPage),
+			),
+			RespondWithJSON(http.StatusOK, noRoles),
+			CombineHandlers(
+				VerifyFormKV("page", "2"),
+				RespondWithJSON(http.StatusBadRequest, `{"kind": "Error"}`),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_RESUME_DIR", tmpDir).
+			Args("account", "users", "--org", "o1").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+
+		// The second run starts directly with the second page:
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyFormKV("page", "2"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "AccountList",
+						"page": 2,
+						"size": 1,
+						"items": [
+							{
+								"kind": "Account",
+								"id": "a100",
+								"username": "u100"
+							}
+						]
+					}`,
+				),
+			),
+			RespondWithJSON(http.StatusOK, noRoles),
+		)
+		result = NewCommand().
+			ConfigString(config).
+			Env("OCM_RESUME_DIR", tmpDir).
+			Args("account", "users", "--org", "o1", "--resume").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(Equal("Resuming from page 2\n"))
+
+		// The state file is removed when the scan finishes:
+		files, err := os.ReadDir(tmpDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+
+	It("Doesn't repeat users when resuming a scan by role", func() {
+		// Use a temporary directory for the state files:
+		tmpDir, err := os.MkdirTemp("", "ocm-resume-*")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+
+		// Prepare a first page of role bindings that is full, so that the command requests
+		// the second one:
+		binding := func(account, role string) string {
+			return fmt.Sprintf(
+				`{
+					"kind": "RoleBinding",
+					"account": {"kind": "AccountLink", "id": "%s"},
+					"role": {"kind": "RoleLink", "id": "%s"}
+				}`,
+				account, role,
+			)
+		}
+		bindings := make([]string, 100)
+		accounts := make([]string, 100)
+		for i := range bindings {
+			bindings[i] = binding(fmt.Sprintf("a%d", i), "OrganizationAdmin")
+			accounts[i] = fmt.Sprintf(`{"kind": "Account", "id": "a%d", "username": "u%d"}`, i, i)
+		}
+		noRoles := `{"kind": "RoleBindingList", "page": 1, "size": 0, "items": []}`
+
+		// The first run fails when retrieving the second page of role bindings:
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/role_bindings"),
+				VerifyFormKV("page", "1"),
+				RespondWithJSON(
+					http.StatusOK,
+					fmt.Sprintf(
+						`{"kind": "RoleBindingList", "page": 1, "size": 100, "items": [%s]}`,
+						strings.Join(bindings, ","),
+					),
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+				RespondWithJSON(
+					http.StatusOK,
+					fmt.Sprintf(
+						`{"kind": "AccountList", "page": 1, "size": 100, "items": [%s]}`,
+						strings.Join(accounts, ","),
+					),
+				),
+			),
+			RespondWithJSON(http.StatusOK, noRoles),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/role_bindings"),
+				VerifyFormKV("page", "2"),
+				RespondWithJSON(http.StatusBadRequest, `{"kind": "Error"}`),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_RESUME_DIR", tmpDir).
+			Args("account", "users", "--org", "o1", "--roles", "OrganizationAdmin,ClusterEditor").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+
+		// The second page contains another role of a user that was already written, and it
+		// shouldn't be written again:
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/role_bindings"),
+				VerifyFormKV("page", "2"),
+				RespondWithJSON(
+					http.StatusOK,
+					fmt.Sprintf(
+						`{"kind": "RoleBindingList", "page": 2, "size": 2, "items": [%s]}`,
+						strings.Join([]string{
+							binding("a0", "ClusterEditor"),
+							binding("a100", "ClusterEditor"),
+						}, ","),
+					),
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+				VerifyFormKV("search", "id in ('a100') and (organization_id='o1')"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "AccountList",
+						"page": 1,
+						"size": 1,
+						"items": [
+							{
+								"kind": "Account",
+								"id": "a100",
+								"username": "u100"
+							}
+						]
+					}`,
+				),
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				fmt.Sprintf(
+					`{"kind": "RoleBindingList", "page": 1, "size": 1, "items": [%s]}`,
+					binding("a100", "ClusterEditor"),
+				),
+			),
+		)
+		result = NewCommand().
+			ConfigString(config).
+			Env("OCM_RESUME_DIR", tmpDir).
+			Args(
+				"account", "users", "--org", "o1", "--roles", "OrganizationAdmin,ClusterEditor",
+				"--resume",
+			).
+			Run(ctx)
+		Expect(result.ErrString()).To(Equal("Resuming from page 2\n"))
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[1]).To(MatchRegexp(`^u100\s+a100\s+ClusterEditor\s*$`))
+	})
+
+	It("Reports the progress of the scan when requested", func() {
+		// Prepare two pages, the first one full so that the command requests the second one:
+		page := func(number, first, count int) string {
+			items := make([]string, count)
+			for i := range items {
+				items[i] = fmt.Sprintf(
+					`{"kind": "Account", "id": "a%d", "username": "u%d"}`,
+					first+i, first+i,
+				)
+			}
+			return fmt.Sprintf(
+				`{"kind": "AccountList", "page": %d, "size": %d, "total": 150, "items": [%s]}`,
+				number, count, strings.Join(items, ","),
+			)
+		}
+		noRoles := `{"kind": "RoleBindingList", "page": 1, "size": 0, "items": []}`
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+				VerifyFormKV("size", "1"),
+				VerifyFormKV("search", "organization_id='o1'"),
+				RespondWithJSON(http.StatusOK, page(1, 0, 1)),
+			),
+			CombineHandlers(
+				VerifyFormKV("page", "1"),
+				VerifyFormKV("size", "100"),
+				RespondWithJSON(http.StatusOK, page(1, 0, 100)),
+			),
+			RespondWithJSON(http.StatusOK, noRoles),
+			CombineHandlers(
+				VerifyFormKV("page", "2"),
+				RespondWithJSON(http.StatusOK, page(2, 100, 50)),
+			),
+			RespondWithJSON(http.StatusOK, noRoles),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "--org", "o1", "--progress").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrLines()).To(ConsistOf(
+			MatchRegexp(`^Processed 100/150 accounts \(66%\), about \S+ left$`),
+			MatchRegexp(`^Processed 150/150 accounts \(100%\)$`),
+		))
+	})
+
+	It("Doesn't report the progress by default when the output isn't a terminal", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyFormKV("page", "1"),
+				VerifyFormKV("size", "100"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{"kind": "AccountList", "page": 1, "size": 0, "total": 0, "items": []}`,
+				),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "--org", "o1").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+	})
+
+	Context("Output format", func() {
+		BeforeEach(func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "AccountList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "Account",
+								"id": "a1",
+								"username": "alice",
+								"email": "alice@example.com",
+								"organization": {
+									"kind": "OrganizationLink",
+									"id": "o1"
+								}
+							}
+						]
+					}`,
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "RoleBindingList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a1"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "OrganizationMember"
+								}
+							},
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a1"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "OrganizationAdmin"
+								}
+							}
+						]
+					}`,
+				),
+			)
+		})
+
+		It("Writes JSON", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("account", "users", "--org", "o1", "--output", "json").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.OutString()).To(MatchJSON(`[
+				{
+					"username": "alice",
+					"id": "a1",
+					"roles": [
+						"OrganizationAdmin",
+						"OrganizationMember"
+					]
+				}
+			]`))
+		})
+
+		It("Replaces personal data with hashes when redacting", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Env(redact.KeyEnvVar, "my-key").
+				Args(
+					"account", "users",
+					"--org", "o1",
+					"--output", "json",
+					"--columns", "username,id,email",
+					"--redact-pii",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			redactor := redact.New("my-key")
+			Expect(result.OutString()).To(MatchJSON(fmt.Sprintf(
+				`[
+					{
+						"username": "%s",
+						"id": "a1",
+						"email": "%s"
+					}
+				]`,
+				redactor.Username("alice"),
+				redactor.Email("alice@example.com"),
+			)))
+		})
+
+		It("Writes YAML with the selected columns", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"account", "users",
+					"--org", "o1",
+					"--output", "yaml",
+					"--columns", "username,email",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.OutString()).To(MatchYAML(
+				"- username: alice\n" +
+					"  email: alice@example.com\n",
+			))
+		})
+
+		It("Writes a table with the selected columns", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("account", "users", "--org", "o1", "--columns", "email,roles").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			lines := result.OutLines()
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(MatchRegexp(`^EMAIL\s+ROLES\s*$`))
+			Expect(lines[1]).To(MatchRegexp(
+				`^alice@example.com\s+OrganizationAdmin OrganizationMember\s*$`,
+			))
+		})
+	})
+
+	Context("Emails only", func() {
+		BeforeEach(func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "AccountList",
+						"page": 1,
+						"size": 4,
+						"total": 4,
+						"items": [
+							{
+								"kind": "Account",
+								"id": "a1",
+								"username": "alice",
+								"email": "alice@example.com"
+							},
+							{
+								"kind": "Account",
+								"id": "a2",
+								"username": "alice-admin",
+								"email": "Alice@Example.com"
+							},
+							{
+								"kind": "Account",
+								"id": "a3",
+								"username": "bob",
+								"email": "bob@example.com"
+							},
+							{
+								"kind": "Account",
+								"id": "a4",
+								"username": "robot"
+							}
+						]
+					}`,
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "RoleBindingList",
+						"page": 1,
+						"size": 4,
+						"total": 4,
+						"items": [
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a1"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "OrganizationMember"
+								}
+							},
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a2"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "OrganizationAdmin"
+								}
+							},
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a3"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "OrganizationMember"
+								}
+							},
+							{
+								"kind": "RoleBinding",
+								"account": {
+									"kind": "AccountLink",
+									"id": "a4"
+								},
+								"role": {
+									"kind": "RoleLink",
+									"id": "OrganizationMember"
+								}
+							}
+						]
+					}`,
+				),
+			)
+		})
+
+		It("Writes one address per line without duplicates", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("account", "users", "--org", "o1", "--emails-only").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.OutString()).To(Equal(
+				"alice@example.com\n" +
+					"bob@example.com\n",
+			))
+		})
+
+		It("Writes the addresses with the given separator", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"account", "users",
+					"--org", "o1",
+					"--emails-only",
+					"--separator", ",",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.OutString()).To(Equal("alice@example.com,bob@example.com\n"))
+		})
+	})
+
+	It("Rejects the emails only mode with an output format", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "--emails-only", "--output", "json").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Option '--emails-only' can't be used with '--output' or '--columns'",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Rejects an unknown output format", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "--output", "xml").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Unknown output format 'xml', valid values are 'table', 'json', 'yaml'",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Rejects an unknown column", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "users", "--org", "o1", "--columns", "username,color").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Unknown column 'color'"))
+	})
+})
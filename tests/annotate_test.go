@@ -0,0 +1,267 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Annotate", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server so that the cluster is found:
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"subscription": {
+						"kind": "SubscriptionLink",
+						"id": "111"
+					}
+				}`,
+			),
+		)
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Adds a note", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/accounts_mgmt/v1/subscriptions/111/labels"),
+				VerifyJQ(`.key | startswith("ocm.note.")`, true),
+				VerifyJQ(`.value`, "Resized for Black Friday, revert after"),
+				RespondWithJSON(http.StatusCreated, `{
+					"kind": "Label",
+					"key": "ocm.note.20231124T093000.000Z",
+					"value": "Resized for Black Friday, revert after"
+				}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("annotate", "my-cluster", "Resized for Black Friday, revert after").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutString()).To(MatchRegexp(
+			`^Added note 'ocm\.note\.\d{8}T\d{6}\.\d{3}Z' to cluster 'my-cluster'\n$`,
+		))
+	})
+
+	It("Only prints the request in curl mode", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("annotate", "my-cluster", "Resized for Black Friday, revert after", "--curl").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("--request POST"))
+		Expect(result.OutString()).To(BeEmpty())
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Is rejected in read-only mode before sending requests", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_READ_ONLY", "true").
+			Args("annotate", "my-cluster", "Resized for Black Friday, revert after").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("read-only mode is enabled"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Lists the notes", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/subscriptions/111/labels"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "LabelList",
+					"page": 1,
+					"size": 3,
+					"total": 3,
+					"items": [
+						{
+							"kind": "Label",
+							"key": "ocm.note.20231201T100000.000Z",
+							"value": "Reverted",
+							"created_at": "2023-12-01T10:00:00Z"
+						},
+						{
+							"kind": "Label",
+							"key": "capability.cluster.manage_cluster_admin",
+							"value": "true"
+						},
+						{
+							"kind": "Label",
+							"key": "ocm.note.20231124T093000.000Z",
+							"value": "Resized for Black Friday, revert after",
+							"created_at": "2023-11-24T09:30:00Z"
+						}
+					]
+				}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("annotate", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(MatchRegexp(`^KEY\s+CREATED\s+NOTE$`))
+		Expect(lines[1]).To(MatchRegexp(
+			`^ocm\.note\.20231124T093000\.000Z\s+2023-11-24T09:30:00Z\s+Resized for Black ` +
+				`Friday, revert after$`,
+		))
+		Expect(lines[2]).To(MatchRegexp(
+			`^ocm\.note\.20231201T100000\.000Z\s+2023-12-01T10:00:00Z\s+Reverted$`,
+		))
+	})
+
+	It("Removes a note", func() {
+		key := "ocm.note.20231124T093000.000Z"
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodDelete,
+					"/api/accounts_mgmt/v1/subscriptions/111/labels/"+key,
+				),
+				RespondWithJSON(http.StatusNoContent, `{}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("annotate", "my-cluster", "--remove", key).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal(
+			"Removed note '" + key + "' from cluster 'my-cluster'\n",
+		))
+	})
+
+	It("Refuses to remove labels that aren't notes", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("annotate", "my-cluster", "--remove", "capability.cluster.manage_cluster_admin").
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(1))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Key 'capability.cluster.manage_cluster_admin' isn't the key of a note",
+		))
+	})
+
+	It("Shows the notes in the description of the cluster", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/subscriptions/111"),
+				VerifyFormKV("fetchLabels", "true"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Subscription",
+					"id": "111",
+					"labels": [
+						{
+							"kind": "Label",
+							"key": "ocm.note.20231124T093000.000Z",
+							"value": "Resized for Black Friday, revert after",
+							"created_at": "2023-11-24T09:30:00Z"
+						}
+					]
+				}`),
+			),
+			RespondWithJSON(http.StatusNotFound, `{
+				"kind": "Error",
+				"id": "404"
+			}`),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("describe", "cluster", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring(
+			"Notes:\n  2023-11-24T09:30:00Z  Resized for Black Friday, revert after\n",
+		))
+	})
+})
@@ -0,0 +1,270 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("API", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Merges all the pages of a collection", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+				VerifyFormKV("search", "state = 'ready'"),
+				VerifyFormKV("page", "1"),
+				VerifyFormKV("size", "2"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "ClusterList",
+					"page": 1,
+					"size": 2,
+					"total": 3,
+					"items": [
+						{
+							"kind": "Cluster",
+							"id": "123",
+							"name": "my-cluster"
+						},
+						{
+							"kind": "Cluster",
+							"id": "456",
+							"name": "your-cluster"
+						}
+					]
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+				VerifyFormKV("page", "2"),
+				VerifyFormKV("size", "2"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "ClusterList",
+					"page": 2,
+					"size": 1,
+					"total": 3,
+					"items": [
+						{
+							"kind": "Cluster",
+							"id": "789",
+							"name": "our-cluster"
+						}
+					]
+				}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"api", "get", "/api/clusters_mgmt/v1/clusters",
+				"--parameter", "search=state = 'ready'",
+				"--parameter", "size=2",
+				"--single",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutString()).To(MatchJSON(`{
+			"kind": "ClusterList",
+			"page": 1,
+			"size": 3,
+			"total": 3,
+			"items": [
+				{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster"
+				},
+				{
+					"kind": "Cluster",
+					"id": "456",
+					"name": "your-cluster"
+				},
+				{
+					"kind": "Cluster",
+					"id": "789",
+					"name": "our-cluster"
+				}
+			]
+		}`))
+	})
+
+	It("Filters the response with a JMESPath expression", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "ClusterList",
+					"page": 1,
+					"size": 2,
+					"total": 2,
+					"items": [
+						{
+							"kind": "Cluster",
+							"id": "123",
+							"name": "my-cluster"
+						},
+						{
+							"kind": "Cluster",
+							"id": "456",
+							"name": "your-cluster"
+						}
+					]
+				}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("api", "get", "clusters", "--query", "items[?id=='456'].name | [0]").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal("your-cluster\n"))
+	})
+
+	It("Doesn't follow pages if the page is given", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+				VerifyFormKV("page", "2"),
+				VerifyFormKV("size", "1"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "ClusterList",
+					"page": 2,
+					"size": 1,
+					"total": 3,
+					"items": [
+						{
+							"kind": "Cluster",
+							"id": "456"
+						}
+					]
+				}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"api", "get", "clusters",
+				"--parameter", "page=2",
+				"--parameter", "size=1",
+				"--query", "items[].id",
+				"--single",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal(`["456"]` + "\n"))
+	})
+
+	It("Sends the body of a POST request", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/clusters/123/hibernate"),
+				VerifyJSON(`{}`),
+				RespondWithJSON(http.StatusAccepted, `{}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("api", "post", "/api/clusters_mgmt/v1/clusters/123/hibernate").
+			InString(`{}`).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+	})
+
+	It("Fails if the server returns an error", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodDelete, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusNotFound, `{
+					"kind": "Error",
+					"id": "404",
+					"reason": "Cluster '123' not found"
+				}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("api", "delete", "/api/clusters_mgmt/v1/clusters/123").
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(1))
+		Expect(result.OutString()).To(BeEmpty())
+		Expect(result.ErrString()).To(ContainSubstring("Cluster '123' not found"))
+		Expect(result.ErrString()).To(ContainSubstring("Request failed with status code 404"))
+	})
+
+	It("Rejects invalid JMESPath expressions", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("api", "get", "clusters", "--query", "items[").
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(1))
+		Expect(result.ErrString()).To(ContainSubstring("Invalid JMESPath expression 'items['"))
+	})
+})
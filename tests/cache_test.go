@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cache", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+	var cache string
+
+	BeforeEach(func() {
+		var err error
+
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Create a temporary directory for the cache, so that we don't use or modify the
+		// cache of the user running the tests:
+		cache, err = ioutil.TempDir("", "ocm-test-*.d")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+
+		// Delete the cache:
+		err := os.RemoveAll(cache)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	get := func(flags ...string) *CommandResult {
+		return NewCommand().
+			ConfigString(config).
+			Env("XDG_CACHE_HOME", cache).
+			Args(append([]string{"get", "/api/clusters_mgmt/v1/clusters/123"}, flags...)...).
+			Run(ctx)
+	}
+
+	It("Uses the saved response with --cached", func() {
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123",
+			RespondWithJSON(http.StatusOK, `{ "id": "123" }`),
+		)
+		for i := 0; i < 2; i++ {
+			result := get("--cached")
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(MatchJSON(`{ "id": "123" }`))
+		}
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("Sends the request again with --refresh", func() {
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123",
+			RespondWithJSON(http.StatusOK, `{ "id": "123" }`),
+		)
+		Expect(get("--cached").ExitCode()).To(BeZero())
+		Expect(get("--refresh").ExitCode()).To(BeZero())
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Doesn't use the cache without the flags", func() {
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123",
+			RespondWithJSON(http.StatusOK, `{ "id": "123" }`),
+		)
+		Expect(get("--cached").ExitCode()).To(BeZero())
+		Expect(get().ExitCode()).To(BeZero())
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Sends the request again after clearing the cache", func() {
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123",
+			RespondWithJSON(http.StatusOK, `{ "id": "123" }`),
+		)
+		Expect(get("--cached").ExitCode()).To(BeZero())
+		result := NewCommand().
+			Env("XDG_CACHE_HOME", cache).
+			Args("cache", "clear").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring("Cleared cache directory"))
+		Expect(get("--cached").ExitCode()).To(BeZero())
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Rejects --cached for commands that change the server", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Env("XDG_CACHE_HOME", cache).
+			Args("delete", "--cached", "/api/clusters_mgmt/v1/clusters/123").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Options '--cached' and '--refresh' can only be used with commands that " +
+				"don't change the server",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,175 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster certificates", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// prepareCluster prepares the server so that the cluster is found with the given API and
+	// console URLs:
+	prepareCluster := func(apiURL, consoleURL string) {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"api": {
+						"url": "`+apiURL+`"
+					},
+					"console": {
+						"url": "`+consoleURL+`"
+					}
+				}`,
+			),
+		)
+	}
+
+	It("Prints the certificates of the endpoints", func() {
+		endpoint := httptest.NewTLSServer(http.NotFoundHandler())
+		defer endpoint.Close()
+		prepareCluster(endpoint.URL, endpoint.URL+"/console")
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "certificates", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(MatchRegexp(`^ENDPOINT\s+HOST\s+ISSUER\s+NOT AFTER\s+DAYS LEFT$`))
+		Expect(lines[1]).To(MatchRegexp(`^api\s+127\.0\.0\.1\s+O=Acme Co\s+\S+\s+\d+$`))
+		Expect(lines[2]).To(MatchRegexp(`^ingress\s+127\.0\.0\.1\s+O=Acme Co\s+\S+\s+\d+$`))
+	})
+
+	It("Prints the certificates in JSON format", func() {
+		endpoint := httptest.NewTLSServer(http.NotFoundHandler())
+		defer endpoint.Close()
+		prepareCluster(endpoint.URL, endpoint.URL+"/console")
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "certificates", "--json", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchJSON(`[
+			{
+				"endpoint": "api",
+				"host": "127.0.0.1",
+				"subject": "O=Acme Co",
+				"issuer": "O=Acme Co",
+				"not_before": "1970-01-01T00:00:00Z",
+				"not_after": "2084-01-29T16:00:00Z"
+			},
+			{
+				"endpoint": "ingress",
+				"host": "127.0.0.1",
+				"subject": "O=Acme Co",
+				"issuer": "O=Acme Co",
+				"not_before": "1970-01-01T00:00:00Z",
+				"not_after": "2084-01-29T16:00:00Z"
+			}
+		]`))
+	})
+
+	It("Fails if an endpoint can't be reached", func() {
+		endpoint := httptest.NewTLSServer(http.NotFoundHandler())
+		defer endpoint.Close()
+		closed := httptest.NewTLSServer(http.NotFoundHandler())
+		closed.Close()
+		prepareCluster(endpoint.URL, closed.URL)
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "certificates", "--timeout", "1s", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[2]).To(MatchRegexp(`^ingress\s+127\.0\.0\.1\s+-\s+-\s+-$`))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Can't get certificate of endpoint 'ingress'",
+		))
+		Expect(result.ErrString()).To(ContainSubstring("Failed to get 1 of 2 certificates"))
+	})
+})
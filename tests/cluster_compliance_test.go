@@ -0,0 +1,234 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster compliance report", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	BeforeEach(func() {
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters",
+			CombineHandlers(
+				VerifyFormKV("page", "1"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "ClusterList",
+						"page": 1,
+						"size": 3,
+						"total": 3,
+						"items": [
+							{
+								"kind": "Cluster",
+								"id": "123",
+								"name": "my-cluster",
+								"state": "ready",
+								"cloud_provider": {
+									"id": "aws"
+								},
+								"region": {
+									"id": "us-east-1"
+								},
+								"fips": true,
+								"etcd_encryption": true,
+								"api": {
+									"listening": "internal"
+								},
+								"aws": {
+									"private_link": true,
+									"kms_key_arn": "arn:aws:kms:us-east-1:111:key/abc",
+									"sts": {
+										"role_arn": "arn:aws:iam::111:role/installer"
+									},
+									"ec2_metadata_http_tokens": "required"
+								}
+							},
+							{
+								"kind": "Cluster",
+								"id": "456",
+								"name": "your-cluster",
+								"state": "ready",
+								"cloud_provider": {
+									"id": "aws"
+								},
+								"region": {
+									"id": "us-west-2"
+								},
+								"hypershift": {
+									"enabled": true
+								}
+							},
+							{
+								"kind": "Cluster",
+								"id": "789",
+								"name": "gcp-cluster",
+								"state": "installing",
+								"cloud_provider": {
+									"id": "gcp"
+								},
+								"region": {
+									"id": "us-east1"
+								}
+							}
+						]
+					}`,
+				),
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123/machine_pools",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "MachinePoolList",
+					"page": 1,
+					"size": 2,
+					"total": 2,
+					"items": [
+						{
+							"kind": "MachinePool",
+							"id": "worker",
+							"aws": {
+								"ec2_metadata_http_tokens": "required"
+							}
+						},
+						{
+							"kind": "MachinePool",
+							"id": "gpu"
+						}
+					]
+				}`,
+			),
+		)
+	})
+
+	It("Writes the report in CSV format", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "compliance", "report").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutLines()).To(Equal([]string{
+			"id,name,cloud_provider,region,state,fips,etcd_encryption,private_api," +
+				"private_link,sts,kms_key_arn,imdsv2,imdsv2_optional_pools,error",
+			"123,my-cluster,aws,us-east-1,ready,true,true,true,true,true," +
+				"arn:aws:kms:us-east-1:111:key/abc,mixed,gpu,",
+			"456,your-cluster,aws,us-west-2,ready,false,false,false,false,false,,optional,,",
+			"789,gcp-cluster,gcp,us-east1,installing,false,false,false,false,false,,,,",
+		}))
+	})
+
+	It("Writes the report in JSON format", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "compliance", "report", "--format", "json").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		var reports []interface{}
+		err := json.Unmarshal([]byte(result.OutString()), &reports)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reports).To(HaveLen(3))
+		Expect(reports[0]).To(MatchJQ(`.imdsv2`, "mixed"))
+		Expect(reports[0]).To(MatchJQ(`.machine_pools`, []interface{}{
+			map[string]interface{}{
+				"id":                       "gpu",
+				"ec2_metadata_http_tokens": "optional",
+			},
+			map[string]interface{}{
+				"id":                       "worker",
+				"ec2_metadata_http_tokens": "required",
+			},
+		}))
+		Expect(reports[1]).To(MatchJQ(`has("machine_pools")`, false))
+		Expect(reports[2]).To(MatchJQ(`has("imdsv2")`, false))
+	})
+
+	It("Reports clusters whose machine pools can't be retrieved", func() {
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123/machine_pools",
+			RespondWithJSON(http.StatusForbidden, `{"kind": "Error", "id": "403"}`),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "compliance", "report").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.OutLines()).To(HaveLen(4))
+		Expect(result.OutLines()[1]).To(ContainSubstring(
+			"Failed to get machine pools for cluster '123'",
+		))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Failed to retrieve the details of 1 clusters",
+		))
+	})
+})
@@ -0,0 +1,366 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster copy IDPs", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	// respondWithCluster prepares the server so that the given cluster is found:
+	respondWithCluster := func(id, name string) {
+		apiServer.AppendHandlers(
+			RespondWithJSONTemplate(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "sub-{{ .ID }}",
+							"cluster_id": "{{ .ID }}"
+						}
+					]
+				}`,
+				"ID", id,
+			),
+			RespondWithJSONTemplate(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "{{ .ID }}",
+					"name": "{{ .Name }}",
+					"state": "ready"
+				}`,
+				"ID", id,
+				"Name", name,
+			),
+		)
+	}
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server so that both clusters are found:
+		respondWithCluster("123", "my-cluster")
+		respondWithCluster("456", "your-cluster")
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Copies identity providers without secrets and skips the existing ones", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "IdentityProviderList",
+					"page": 1,
+					"size": 2,
+					"total": 2,
+					"items": [
+						{
+							"kind": "IdentityProvider",
+							"id": "789",
+							"name": "corp",
+							"type": "LDAPIdentityProvider",
+							"mapping_method": "claim",
+							"ldap": {
+								"url": "ldap://ldap.example.com/ou=users",
+								"insecure": true
+							}
+						},
+						{
+							"kind": "IdentityProvider",
+							"id": "790",
+							"name": "sso",
+							"type": "GithubIdentityProvider",
+							"mapping_method": "claim",
+							"github": {
+								"client_id": "my-client"
+							}
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "IdentityProviderList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "IdentityProvider",
+							"id": "791",
+							"name": "sso",
+							"type": "GithubIdentityProvider"
+						}
+					]
+				}`,
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/456/identity_providers",
+				),
+				VerifyJQ(`.id`, nil),
+				VerifyJQ(`.name`, "corp"),
+				VerifyJQ(`.ldap.url`, "ldap://ldap.example.com/ou=users"),
+				RespondWithJSON(
+					http.StatusCreated,
+					`{
+						"kind": "IdentityProvider",
+						"id": "792",
+						"name": "corp",
+						"type": "LDAPIdentityProvider"
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"cluster", "copy-idps",
+				"--from", "my-cluster",
+				"--to", "your-cluster",
+				"--skip-groups",
+			).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(Equal([]string{
+			"Identity provider 'corp' has been created",
+			"Identity provider 'sso' already exists, skipping it",
+		}))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(7))
+	})
+
+	It("Skips identity providers that need secrets when not running in a terminal", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "IdentityProviderList",
+					"page": 1,
+					"size": 2,
+					"total": 2,
+					"items": [
+						{
+							"kind": "IdentityProvider",
+							"id": "789",
+							"name": "local",
+							"type": "HTPasswdIdentityProvider",
+							"mapping_method": "claim",
+							"htpasswd": {}
+						},
+						{
+							"kind": "IdentityProvider",
+							"id": "790",
+							"name": "sso",
+							"type": "GithubIdentityProvider",
+							"mapping_method": "claim",
+							"github": {
+								"client_id": "my-client"
+							}
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "IdentityProviderList",
+					"page": 1,
+					"size": 0,
+					"total": 0,
+					"items": []
+				}`,
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"cluster", "copy-idps",
+				"--from", "my-cluster",
+				"--to", "your-cluster",
+				"--skip-groups",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(BeEmpty())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Warning: identity provider 'local' needs secrets",
+		))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Warning: identity provider 'sso' needs secrets",
+		))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(6))
+	})
+
+	It("Adds the members of the groups that are missing", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "IdentityProviderList",
+					"page": 1,
+					"size": 0,
+					"total": 0,
+					"items": []
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "IdentityProviderList",
+					"page": 1,
+					"size": 0,
+					"total": 0,
+					"items": []
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "GroupList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Group",
+							"id": "dedicated-admins",
+							"users": {
+								"items": [
+									{
+										"kind": "User",
+										"id": "alice"
+									},
+									{
+										"kind": "User",
+										"id": "bob"
+									}
+								]
+							}
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "GroupList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Group",
+							"id": "dedicated-admins",
+							"users": {
+								"items": [
+									{
+										"kind": "User",
+										"id": "alice"
+									}
+								]
+							}
+						}
+					]
+				}`,
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/456/groups/dedicated-admins/users",
+				),
+				VerifyJQ(`.id`, "bob"),
+				RespondWithJSON(
+					http.StatusCreated,
+					`{
+						"kind": "User",
+						"id": "bob"
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"cluster", "copy-idps",
+				"--from", "my-cluster",
+				"--to", "your-cluster",
+			).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(Equal([]string{
+			"User 'bob' has been added to group 'dedicated-admins'",
+		}))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(9))
+	})
+})
@@ -0,0 +1,210 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster cost-tags", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server so that the cluster is found:
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"cloud_provider": {
+						"id": "aws"
+					},
+					"ccs": {
+						"enabled": true
+					},
+					"aws": {
+						"tags": {
+							"cost-center": "1234",
+							"owner": "billing"
+						}
+					}
+				}`,
+			),
+		)
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Shows the recorded tags", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "cost-tags", "my-cluster").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(MatchRegexp(`^KEY\s+VALUE$`))
+		Expect(lines[1]).To(MatchRegexp(`^cost-center\s+1234$`))
+		Expect(lines[2]).To(MatchRegexp(`^owner\s+billing$`))
+	})
+
+	It("Fails if the recorded tags don't have the desired values", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"cluster", "cost-tags", "my-cluster",
+				"--tag", "cost-center=1234",
+				"--tag", "owner=payments",
+				"--tag", "team=checkout",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"2 tags of cluster 'my-cluster' don't have the desired values",
+		))
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(4))
+		Expect(lines[0]).To(MatchRegexp(`^KEY\s+DESIRED\s+RECORDED\s+STATUS$`))
+		Expect(lines[1]).To(MatchRegexp(`^cost-center\s+1234\s+1234\s+match$`))
+		Expect(lines[2]).To(MatchRegexp(`^owner\s+payments\s+billing\s+different$`))
+		Expect(lines[3]).To(MatchRegexp(`^team\s+checkout\s+missing$`))
+	})
+
+	It("Updates the tags", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPatch, "/api/clusters_mgmt/v1/clusters/123"),
+				VerifyJQ(`.aws.tags.owner`, "payments"),
+				VerifyJQ(`.aws.tags["cost-center"]`, "1234"),
+				RespondWithJSON(http.StatusOK, `{"kind": "Cluster", "id": "123"}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "cost-tags", "my-cluster", "--tag", "owner=payments", "--apply").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring(
+			"Updated 1 tags of cluster 'my-cluster'",
+		))
+	})
+
+	It("Only prints the update request in curl mode", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "cost-tags", "my-cluster", "--tag", "owner=payments", "--apply", "--curl").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("--request PATCH"))
+		Expect(result.OutString()).ToNot(ContainSubstring("Updated"))
+	})
+
+	It("Rejects '--apply' in read-only mode, but not the comparison", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_READ_ONLY", "true").
+			Args("cluster", "cost-tags", "my-cluster", "--tag", "owner=payments", "--apply").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("read-only mode is enabled"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+
+		result = NewCommand().
+			ConfigString(config).
+			Env("OCM_READ_ONLY", "true").
+			Args("cluster", "cost-tags", "my-cluster").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+	})
+
+	It("Rejects reserved tags without sending requests", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "cost-tags", "my-cluster", "--tag", "aws:owner=me").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Tag key 'aws:owner' uses reserved prefix 'aws:'",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,229 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster events", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server:
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"creation_timestamp": "2024-01-01T10:00:00Z"
+				}`,
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/upgrade_policies",
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "UpgradePolicyList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "UpgradePolicy",
+								"id": "456",
+								"version": "4.14.1",
+								"next_run": "2024-01-03T10:00:00Z"
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/upgrade_policies/456/state",
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "UpgradePolicyState",
+						"value": "scheduled",
+						"description": "Upgrade scheduled"
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/limited_support_reasons",
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "LimitedSupportReasonList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "LimitedSupportReason",
+								"id": "789",
+								"summary": "Cluster is out of support",
+								"creation_timestamp": "2024-01-04T10:00:00Z"
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/service_logs/v1/cluster_logs"),
+				VerifyFormKV("search", "cluster_id = '123'"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "ClusterLogList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "ClusterLog",
+								"id": "abc",
+								"severity": "Info",
+								"summary": "Cluster installed",
+								"timestamp": "2024-01-02T10:00:00Z"
+							}
+						]
+					}`,
+				),
+			),
+		)
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Shows the events in chronological order", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "events", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(5))
+		Expect(lines[0]).To(MatchRegexp(`^TIME\s+SOURCE\s+SEVERITY\s+SUMMARY$`))
+		Expect(lines[1]).To(MatchRegexp(
+			`^2024-01-01T10:00:00Z\s+cluster\s+-\s+Cluster created$`,
+		))
+		Expect(lines[2]).To(MatchRegexp(
+			`^2024-01-02T10:00:00Z\s+service_log\s+Info\s+Cluster installed$`,
+		))
+		Expect(lines[3]).To(MatchRegexp(
+			`^2024-01-03T10:00:00Z\s+upgrade\s+-\s+Upgrade to version '4.14.1' is scheduled$`,
+		))
+		Expect(lines[4]).To(MatchRegexp(
+			`^2024-01-04T10:00:00Z\s+limited_support\s+Warning\s+Cluster is out of support$`,
+		))
+	})
+
+	It("Filters the events by time and writes JSON", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"cluster", "events", "my-cluster",
+				"--since", "2024-01-02T00:00:00Z",
+				"--until", "2024-01-03T00:00:00Z",
+				"--json",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutString()).To(MatchJSON(`[
+			{
+				"time": "2024-01-02T10:00:00Z",
+				"source": "service_log",
+				"severity": "Info",
+				"summary": "Cluster installed"
+			}
+		]`))
+	})
+})
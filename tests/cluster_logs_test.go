@@ -0,0 +1,337 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster logs", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server so that the cluster is found:
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"state": "ready"
+				}`,
+			),
+		)
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Lists the available logs", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/logs"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "LogList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "Log",
+								"id": "install",
+								"href": "/api/clusters_mgmt/v1/clusters/123/logs/install"
+							}
+						]
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "logs", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[1]).To(MatchRegexp(
+			`^install\s+/api/clusters_mgmt/v1/clusters/123/logs/install$`,
+		))
+	})
+
+	It("Filters the lines of a log by time", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/logs/install",
+					"tail=100",
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "Log",
+						"id": "install",
+						"content": "`+
+						`time=\"2022-01-01T09:00:00Z\" level=info msg=first\n`+
+						`time=\"2022-01-01T10:00:00Z\" level=info msg=second\n`+
+						`  continuation of second\n`+
+						`time=\"2022-01-01T11:00:00Z\" level=info msg=third\n`+
+						`"
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"cluster", "logs", "my-cluster", "install",
+				"--tail", "100",
+				"--since", "2022-01-01T09:30:00Z",
+				"--until", "2022-01-01T10:30:00Z",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutLines()).To(Equal([]string{
+			`time="2022-01-01T10:00:00Z" level=info msg=second`,
+			`  continuation of second`,
+		}))
+	})
+
+	It("Rejects an invalid time range", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "logs", "my-cluster", "install", "--since", "yesterday").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Invalid value for option '--since'",
+		))
+	})
+
+	It("Rejects a time range that ends before it starts", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"cluster", "logs", "my-cluster", "install",
+				"--since", "2022-01-01T11:00:00Z",
+				"--until", "2022-01-01T10:00:00Z",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Value of option '--since' must not be later than '--until'",
+		))
+	})
+
+	DescribeTable(
+		"Rejects download options without a log identifier",
+		func(flag string, value string) {
+			result := NewCommand().
+				ConfigString(config).
+				Args("cluster", "logs", "my-cluster", flag, value).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Option '%s' can only be used with a log identifier", flag,
+			))
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		},
+		Entry("Since", "--since", "2h"),
+		Entry("Until", "--until", "1h"),
+		Entry("Tail", "--tail", "10"),
+		Entry("Output", "--output", "my.log"),
+	)
+
+	It("Downloads logs that aren't install or uninstall logs", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/logs/audit"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "Log",
+						"id": "audit",
+						"content": "my audit line\n"
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "logs", "my-cluster", "audit").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(Equal([]string{"my audit line"}))
+	})
+
+	It("Follows a log using the stream sent by the server", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/logs/install",
+					"follow=true&tail=10",
+				),
+				VerifyHeaderKV("Accept", "text/event-stream, application/x-ndjson"),
+				func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "text/event-stream")
+					fmt.Fprint(w, ": keep alive\n\n")
+					fmt.Fprint(w, "data: first\ndata: second\n\n")
+					fmt.Fprint(w, "id: 2\ndata: third\n\n")
+				},
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "logs", "my-cluster", "install", "--follow", "--tail", "10").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutLines()).To(Equal([]string{"first", "second", "third"}))
+	})
+
+	It("Follows a log polling it when the server doesn't support streaming", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyFormKV("follow", "true"),
+				RespondWithJSON(
+					http.StatusNotFound,
+					`{
+						"kind": "Error",
+						"id": "404",
+						"reason": "Not found"
+					}`,
+				),
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Log",
+					"id": "install",
+					"content": "first\nsecond\nthi"
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Log",
+					"id": "install",
+					"content": "first\nsecond\nthird\nfourth\n"
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusBadRequest,
+				`{
+					"kind": "Error",
+					"id": "400",
+					"reason": "Boom"
+				}`,
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"cluster", "logs", "my-cluster", "install",
+				"--follow",
+				"--tail", "1",
+				"--interval", "10ms",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Boom"))
+		Expect(result.OutLines()).To(Equal([]string{"second", "third", "fourth"}))
+	})
+
+	It("Rejects '--follow' together with '--until'", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "logs", "my-cluster", "install", "--follow", "--until", "1h").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Options '--follow' and '--until' can't be used together",
+		))
+	})
+})
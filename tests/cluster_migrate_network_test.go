@@ -0,0 +1,357 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster migrate network", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+	var resumeDir string
+
+	BeforeEach(func() {
+		var err error
+
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the directory for the saved progress:
+		resumeDir, err = os.MkdirTemp("", "ocm-test-*.d")
+		Expect(err).ToNot(HaveOccurred())
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+
+		// Remove the saved progress:
+		err := os.RemoveAll(resumeDir)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	// clusterJSON returns the cluster with the given state and API listening method.
+	clusterJSON := func(state, listening string) string {
+		return fmt.Sprintf(`{
+			"kind": "Cluster",
+			"id": "123",
+			"name": "my-cluster",
+			"state": "%s",
+			"api": {
+				"listening": "%s"
+			}
+		}`, state, listening)
+	}
+
+	// ingressesJSON returns the list of ingresses containing only the default one, with the given
+	// listening method.
+	ingressesJSON := func(listening string) string {
+		return fmt.Sprintf(`{
+			"kind": "IngressList",
+			"page": 1,
+			"size": 1,
+			"total": 1,
+			"items": [
+				{
+					"kind": "Ingress",
+					"id": "a1b2",
+					"default": true,
+					"listening": "%s"
+				}
+			]
+		}`, listening)
+	}
+
+	// prepareCluster prepares the server so that the cluster is found.
+	prepareCluster := func(state, listening string) {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{
+				"kind": "SubscriptionList",
+				"page": 1,
+				"size": 1,
+				"total": 1,
+				"items": [
+					{
+						"kind": "Subscription",
+						"id": "111",
+						"cluster_id": "123"
+					}
+				]
+			}`),
+			RespondWithJSON(http.StatusOK, clusterJSON(state, listening)),
+		)
+	}
+
+	// prepareAppsStep prepares the server for the step that makes the default router private.
+	prepareAppsStep := func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, ingressesJSON("external")),
+			RespondWithJSON(http.StatusOK, ingressesJSON("external")),
+			CombineHandlers(
+				VerifyRequest(http.MethodPatch, "/api/clusters_mgmt/v1/clusters/123/ingresses/a1b2"),
+				VerifyJSON(`{
+					"kind": "Ingress",
+					"listening": "internal"
+				}`),
+				RespondWithJSON(http.StatusOK, "{}"),
+			),
+			RespondWithJSON(http.StatusOK, ingressesJSON("internal")),
+		)
+	}
+
+	It("Applies the steps in order", func() {
+		prepareCluster("ready", "external")
+		prepareAppsStep()
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, clusterJSON("ready", "external")),
+			CombineHandlers(
+				VerifyRequest(http.MethodPatch, "/api/clusters_mgmt/v1/clusters/123"),
+				VerifyJSON(`{
+					"kind": "Cluster",
+					"api": {
+						"listening": "internal"
+					}
+				}`),
+				RespondWithJSON(http.StatusOK, "{}"),
+			),
+			RespondWithJSON(http.StatusOK, clusterJSON("ready", "internal")),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_RESUME_DIR", resumeDir).
+			Args("cluster", "migrate-network", "my-cluster", "--api", "private", "--apps", "private").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutLines()).To(Equal([]string{
+			"Step 1 of 2: Make the default router private",
+			"Step 2 of 2: Make the API server private",
+			"Network migration of cluster 'my-cluster' completed",
+		}))
+	})
+
+	It("Resumes after a failed step", func() {
+		// First run, the second step fails:
+		prepareCluster("ready", "external")
+		prepareAppsStep()
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, clusterJSON("ready", "external")),
+			RespondWithJSON(http.StatusInternalServerError, `{
+				"kind": "Error",
+				"id": "500",
+				"reason": "Something failed"
+			}`),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_RESUME_DIR", resumeDir).
+			Args("cluster", "migrate-network", "my-cluster", "--api", "private", "--apps", "private").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Step 2 of 2 failed"))
+		Expect(result.ErrString()).To(ContainSubstring("run the same command again"))
+
+		// Second run, only the second step is applied:
+		prepareCluster("ready", "external")
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, clusterJSON("ready", "external")),
+			CombineHandlers(
+				VerifyRequest(http.MethodPatch, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, "{}"),
+			),
+			RespondWithJSON(http.StatusOK, clusterJSON("ready", "internal")),
+		)
+		result = NewCommand().
+			ConfigString(config).
+			Env("OCM_RESUME_DIR", resumeDir).
+			Args("cluster", "migrate-network", "my-cluster", "--api", "private", "--apps", "private").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(Equal([]string{
+			"Resuming network migration of cluster 'my-cluster' after step 1 of 2",
+			"Step 1 of 2: Make the default router private: completed in a previous run",
+			"Step 2 of 2: Make the API server private",
+			"Network migration of cluster 'my-cluster' completed",
+		}))
+	})
+
+	It("Skips the steps that are already in place", func() {
+		prepareCluster("ready", "internal")
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, clusterJSON("ready", "internal")),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_RESUME_DIR", resumeDir).
+			Args("cluster", "migrate-network", "my-cluster", "--api", "private").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(Equal([]string{
+			"Step 1 of 1: Make the API server private: already in place",
+			"Network migration of cluster 'my-cluster' completed",
+		}))
+	})
+
+	It("Adds an additional router", func() {
+		prepareCluster("ready", "external")
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, ingressesJSON("external")),
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/clusters/123/ingresses"),
+				VerifyJSON(`{
+					"kind": "Ingress",
+					"default": false,
+					"listening": "external",
+					"route_selectors": {
+						"exposure": "public"
+					}
+				}`),
+				RespondWithJSON(http.StatusCreated, "{}"),
+			),
+			RespondWithJSON(http.StatusOK, `{
+				"kind": "IngressList",
+				"page": 1,
+				"size": 2,
+				"total": 2,
+				"items": [
+					{
+						"kind": "Ingress",
+						"id": "a1b2",
+						"default": true,
+						"listening": "external"
+					},
+					{
+						"kind": "Ingress",
+						"id": "c3d4",
+						"default": false,
+						"listening": "external",
+						"route_selectors": {
+							"exposure": "public"
+						}
+					}
+				]
+			}`),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_RESUME_DIR", resumeDir).
+			Args(
+				"cluster", "migrate-network", "my-cluster",
+				"--add-router", "public",
+				"--router-label-match", "exposure=public",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(Equal([]string{
+			"Step 1 of 1: Add public router for routes matching 'exposure=public'",
+			"Network migration of cluster 'my-cluster' completed",
+		}))
+	})
+
+	It("Doesn't change the cluster in dry run mode", func() {
+		prepareCluster("ready", "external")
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, clusterJSON("ready", "external")),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_RESUME_DIR", resumeDir).
+			Args("cluster", "migrate-network", "my-cluster", "--api", "private", "--dry-run").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(Equal([]string{
+			"Step 1 of 1: Make the API server private: would be applied",
+		}))
+	})
+
+	It("Rejects clusters that aren't ready", func() {
+		prepareCluster("installing", "external")
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_RESUME_DIR", resumeDir).
+			Args("cluster", "migrate-network", "my-cluster", "--api", "private").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Cluster 'my-cluster' is in state 'installing'",
+		))
+	})
+
+	It("Rejects invalid exposure", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "migrate-network", "my-cluster", "--api", "hidden").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Invalid value 'hidden' for option '--api'",
+		))
+	})
+
+	It("Requires at least one change", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "migrate-network", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("At least one of"))
+	})
+})
@@ -0,0 +1,300 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Production protection", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	When("Marking clusters", func() {
+		BeforeEach(func() {
+			// Prepare the server so that the cluster is found:
+			apiServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "SubscriptionList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "Subscription",
+								"id": "111",
+								"cluster_id": "123"
+							}
+						]
+					}`,
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "Cluster",
+						"id": "123",
+						"name": "my-cluster",
+						"subscription": {
+							"kind": "SubscriptionLink",
+							"id": "111"
+						}
+					}`,
+				),
+			)
+		})
+
+		It("Adds the label", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "LabelList",
+					"page": 1,
+					"size": 0,
+					"total": 0,
+					"items": []
+				}`),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPost,
+						"/api/accounts_mgmt/v1/subscriptions/111/labels",
+					),
+					VerifyJQ(`.key`, "protection"),
+					VerifyJQ(`.value`, "production"),
+					RespondWithJSON(http.StatusCreated, `{}`),
+				),
+			)
+			result := NewCommand().
+				ConfigString(config).
+				Args("cluster", "protect", "my-cluster").
+				Run(ctx)
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(Equal(
+				"Cluster 'my-cluster' is now marked as a production cluster\n",
+			))
+		})
+
+		It("Only prints the request in curl mode", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "LabelList",
+					"page": 1,
+					"size": 0,
+					"total": 0,
+					"items": []
+				}`),
+			)
+			result := NewCommand().
+				ConfigString(config).
+				Args("cluster", "protect", "my-cluster", "--curl").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("--request POST"))
+			Expect(result.OutString()).To(BeEmpty())
+		})
+
+		It("Doesn't add the label twice", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "LabelList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Label",
+							"key": "protection",
+							"value": "production"
+						}
+					]
+				}`),
+			)
+			result := NewCommand().
+				ConfigString(config).
+				Args("cluster", "protect", "my-cluster").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(Equal(
+				"Cluster 'my-cluster' is already marked as a production cluster\n",
+			))
+		})
+
+		It("Removes the label", func() {
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodDelete,
+						"/api/accounts_mgmt/v1/subscriptions/111/labels/protection",
+					),
+					RespondWithJSON(http.StatusOK, `{}`),
+				),
+			)
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"cluster", "protect", "my-cluster",
+					"--remove",
+					"--i-know-this-is-production",
+				).
+				Run(ctx)
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(Equal(
+				"Cluster 'my-cluster' is no longer marked as a production cluster\n",
+			))
+		})
+	})
+
+	It("Requires the flag to remove the label", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "protect", "my-cluster", "--remove").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Removing the production mark requires the '--i-know-this-is-production' option",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	When("The interlock is enabled", func() {
+		BeforeEach(func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("config", "set", "production_interlock", "flag").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			config = result.ConfigString()
+		})
+
+		It("Rejects changes to production clusters", func() {
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/subscriptions"),
+					VerifyFormKV("search", "cluster_id = '123'"),
+					VerifyFormKV("fetchLabels", "true"),
+					RespondWithJSON(http.StatusOK, `{
+						"kind": "SubscriptionList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "Subscription",
+								"id": "111",
+								"display_name": "my-cluster",
+								"labels": [
+									{
+										"kind": "Label",
+										"key": "protection",
+										"value": "production"
+									}
+								]
+							}
+						]
+					}`),
+				),
+			)
+			result := NewCommand().
+				ConfigString(config).
+				Args("delete", "/api/clusters_mgmt/v1/clusters/123").
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Cluster 'my-cluster' is labeled as a production cluster",
+			))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("Accepts changes to production clusters with the flag", func() {
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodDelete, "/api/clusters_mgmt/v1/clusters/123"),
+					RespondWithJSON(http.StatusOK, `{}`),
+				),
+			)
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"delete", "/api/clusters_mgmt/v1/clusters/123",
+					"--i-know-this-is-production",
+				).
+				Run(ctx)
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.ExitCode()).To(BeZero())
+		})
+
+		It("Rejects invalid modes", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("config", "set", "production_interlock", "junk").
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Unknown production interlock mode 'junk'",
+			))
+		})
+	})
+})
@@ -0,0 +1,184 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster pull secret rotate", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+	var tmpDir string
+	var file string
+
+	BeforeEach(func() {
+		// Create the file containing the new pull secret:
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-pull-secret-*")
+		Expect(err).ToNot(HaveOccurred())
+		file = filepath.Join(tmpDir, "pull-secret.json")
+		err = os.WriteFile(file, []byte(`{
+			"auths": {
+				"cloud.openshift.com": {"auth": "bXl1c2VyOm15cGFzcw=="},
+				"quay.io": {"auth": "bXl1c2VyOm5ld3Bhc3M="},
+				"registry.example.com": {"auth": "bXl1c2VyOm15cGFzcw=="}
+			}
+		}`), 0600)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server so that the cluster is found:
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"state": "ready"
+				}`,
+			),
+		)
+	})
+
+	AfterEach(func() {
+		// Remove the temporary files:
+		os.RemoveAll(tmpDir)
+
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Shows the registries that change", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/accounts_mgmt/v1/access_token"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"auths": {
+							"cloud.openshift.com": {"auth": "bXl1c2VyOm15cGFzcw=="},
+							"quay.io": {"auth": "bXl1c2VyOm15cGFzcw=="},
+							"registry.redhat.io": {"auth": "bXl1c2VyOm15cGFzcw=="}
+						}
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "pull-secret", "rotate", "my-cluster", "--file", file).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(9))
+		Expect(lines[0]).To(MatchRegexp(`^REGISTRY\s+CHANGE$`))
+		Expect(lines[1]).To(MatchRegexp(`^cloud.openshift.com\s+unchanged$`))
+		Expect(lines[2]).To(MatchRegexp(`^quay.io\s+changed$`))
+		Expect(lines[3]).To(MatchRegexp(`^registry.example.com\s+added$`))
+		Expect(lines[4]).To(MatchRegexp(`^registry.redhat.io\s+removed$`))
+		Expect(lines[8]).To(Equal(
+			"  oc set data secret/pull-secret -n openshift-config " +
+				"--from-file=.dockerconfigjson=" + file,
+		))
+	})
+
+	It("Is rejected in read-only mode", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_READ_ONLY", "true").
+			Args("cluster", "pull-secret", "rotate", "my-cluster", "--file", file).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("read-only mode is enabled"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Rejects invalid pull secrets", func() {
+		err := os.WriteFile(file, []byte(`{"auths": {}}`), 0600)
+		Expect(err).ToNot(HaveOccurred())
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "pull-secret", "rotate", "my-cluster", "--file", file).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"pull secret doesn't contain credentials for any registry",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,225 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster rightsizing", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+	var updated string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Use recent metrics, so that there are no warnings about outdated recommendations:
+		updated = time.Now().UTC().Add(-time.Hour).Format("2006-01-02T15:04:05Z")
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server so that the cluster, its subscription and its machine pools are
+		// found:
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"subscription": {
+						"kind": "SubscriptionLink",
+						"id": "111"
+					}
+				}`,
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/subscriptions/111"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "Subscription",
+						"id": "111",
+						"metrics": [
+							{
+								"cpu": {
+									"used": { "value": 28, "unit": "" },
+									"total": { "value": 32, "unit": "" },
+									"updated_timestamp": "`+updated+`"
+								},
+								"memory": {
+									"used": { "value": 34359738368, "unit": "B" },
+									"total": { "value": 137438953472, "unit": "B" },
+									"updated_timestamp": "`+updated+`"
+								}
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/machine_pools"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "MachinePoolList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"kind": "MachinePool",
+								"id": "worker",
+								"instance_type": "m5.xlarge",
+								"replicas": 3,
+								"availability_zones": ["us-east-1a", "us-east-1b", "us-east-1c"]
+							},
+							{
+								"kind": "MachinePool",
+								"id": "batch",
+								"instance_type": "m5.2xlarge",
+								"autoscaling": {
+									"min_replicas": 2,
+									"max_replicas": 6
+								}
+							}
+						]
+					}`,
+				),
+			),
+		)
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Writes the report", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "rightsizing", "--cluster", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutString()).To(Equal(
+			"Cluster:  my-cluster (123)\n" +
+				"Metrics:  " + updated + "\n" +
+				"CPU:      28.00/32.00 cores (88%)\n" +
+				"Memory:   32.00/128.00 GiB (25%)\n" +
+				"Target:   70%\n" +
+				"\n" +
+				"POOL    INSTANCE TYPE  REPLICAS  RECOMMENDED     REASON\n" +
+				"worker  m5.xlarge      3         6 c5.xlarge     CPU is 88% used, above the " +
+				"70% target, memory is only 25% used\n" +
+				"batch   m5.2xlarge     2-6       2-8 c5.2xlarge  CPU is 88% used, above the " +
+				"70% target, memory is only 25% used\n",
+		))
+	})
+
+	It("Writes the report in JSON format", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "rightsizing", "--cluster", "my-cluster", "--target", "90", "--json").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchJSON(`{
+			"cluster_id": "123",
+			"cluster_name": "my-cluster",
+			"updated_timestamp": "` + updated + `",
+			"cpu_used": 28,
+			"cpu_total": 32,
+			"memory_used": 34359738368,
+			"memory_total": 137438953472,
+			"cpu_utilization": 0.875,
+			"memory_utilization": 0.25,
+			"target_utilization": 0.9,
+			"pools": [
+				{
+					"id": "worker",
+					"instance_type": "m5.xlarge",
+					"replicas": 3,
+					"recommended_replicas": 3,
+					"recommended_instance_type": "c5.xlarge",
+					"reason": "CPU is 88% used, close to the 90% target, memory is only 25% used"
+				},
+				{
+					"id": "batch",
+					"instance_type": "m5.2xlarge",
+					"min_replicas": 2,
+					"max_replicas": 6,
+					"recommended_min_replicas": 2,
+					"recommended_max_replicas": 6,
+					"recommended_instance_type": "c5.2xlarge",
+					"reason": "CPU is 88% used, close to the 90% target, memory is only 25% used"
+				}
+			]
+		}`))
+	})
+})
@@ -0,0 +1,214 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster rotate operator roles", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+	var tmpDir string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Create a temporary directory for the policy documents:
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-operator-roles-*")
+		Expect(err).ToNot(HaveOccurred())
+
+		// Prepare the server:
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"aws": {
+						"sts": {
+							"role_arn": "arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role",
+							"operator_role_prefix": "my-cluster",
+							"operator_iam_roles": [
+								{
+									"namespace": "openshift-ingress-operator",
+									"name": "cloud-credentials",
+									"role_arn": "arn:aws:iam::123456789012:role/my-cluster-openshift-ingress-operator-cloud-credentials"
+								}
+							]
+						}
+					}
+				}`,
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/aws_inquiries/sts_policies"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "STSPolicyList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"id": "openshift_ingress_operator_cloud_credentials_policy",
+								"type": "OperatorRole",
+								"details": "{\"Version\": \"2012-10-17\"}"
+							},
+							{
+								"id": "openshift_image_registry_installer_cloud_credentials_policy",
+								"type": "OperatorRole",
+								"details": "{}"
+							}
+						]
+					}`,
+				),
+			),
+		)
+	})
+
+	AfterEach(func() {
+		// Remove the temporary directory:
+		os.RemoveAll(tmpDir)
+
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Prints the status of the roles and the commands", func() {
+		dir := filepath.Join(tmpDir, "policies")
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "rotate-operator-roles", "my-cluster", "--dir", dir).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(10))
+		Expect(lines[0]).To(MatchRegexp(`^POLICY\s+STATUS\s+ROLE$`))
+		Expect(lines[1]).To(MatchRegexp(
+			`^openshift_image_registry_installer_cloud_credentials_policy\s+missing\s+-$`,
+		))
+		Expect(lines[2]).To(MatchRegexp(
+			`^openshift_ingress_operator_cloud_credentials_policy\s+update\s+arn:aws:iam::`,
+		))
+		Expect(lines[6]).To(Equal(
+			"  aws iam create-policy " +
+				"--policy-name my-cluster-openshift-image-registry-installer-cloud-credentials " +
+				"--policy-document file://" + dir +
+				"/openshift_image_registry_installer_cloud_credentials_policy.json",
+		))
+		Expect(lines[7]).To(Equal(
+			"  aws iam create-policy-version " +
+				"--policy-arn arn:aws:iam::123456789012:policy/" +
+				"my-cluster-openshift-ingress-operator-cloud-credentials " +
+				"--policy-document file://" + dir +
+				"/openshift_ingress_operator_cloud_credentials_policy.json " +
+				"--set-as-default",
+		))
+		Expect(lines[9]).To(ContainSubstring("rosa create operator-roles --cluster my-cluster"))
+
+		// Check the policy document:
+		data, err := os.ReadFile(
+			filepath.Join(dir, "openshift_ingress_operator_cloud_credentials_policy.json"),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"Version": "2012-10-17"}`))
+	})
+
+	It("Executes the commands", func() {
+		// Create a fake 'aws' command that writes its arguments:
+		bin := filepath.Join(tmpDir, "bin")
+		Expect(os.Mkdir(bin, 0755)).To(Succeed())
+		err := os.WriteFile(
+			filepath.Join(bin, "aws"),
+			[]byte("#!/bin/sh\necho \"fake aws $2\"\n"),
+			0700, // #nosec G306
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH")).
+			Args(
+				"cluster", "rotate-operator-roles", "my-cluster",
+				"--dir", filepath.Join(tmpDir, "policies"),
+				"--execute",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring("fake aws create-policy\n"))
+		Expect(result.OutString()).To(ContainSubstring("fake aws create-policy-version\n"))
+	})
+})
@@ -0,0 +1,291 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster scale", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// prepareCluster prepares the server so that the cluster is found, with the given nodes
+	// section.
+	prepareCluster := func(nodes string) {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				fmt.Sprintf(`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"ccs": {
+						"enabled": true
+					},
+					"multi_az": false,
+					"nodes": %s
+				}`, nodes),
+			),
+		)
+	}
+
+	// prepareQuota prepares the server so that it returns the machine type and a quota with the
+	// given allowed and consumed values.
+	prepareQuota := func(allowed, consumed int) {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/machine_types"),
+				VerifyFormKV("search", "id = 'm5.xlarge'"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "MachineTypeList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "MachineType",
+							"id": "m5.xlarge",
+							"generic_name": "standard-4"
+						}
+					]
+				}`),
+			),
+			RespondWithJSON(http.StatusOK, `{
+				"kind": "Account",
+				"id": "222",
+				"organization": {
+					"kind": "Organization",
+					"id": "333"
+				}
+			}`),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/organizations/333/quota_cost"),
+				RespondWithJSON(http.StatusOK, fmt.Sprintf(`{
+					"kind": "QuotaCostList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "QuotaCost",
+							"quota_id": "compute.node|standard-4",
+							"allowed": %d,
+							"consumed": %d,
+							"related_resources": [
+								{
+									"resource_type": "compute.node",
+									"resource_name": "standard-4",
+									"byoc": "byoc",
+									"availability_zone_type": "any",
+									"cost": 1
+								}
+							]
+						}
+					]
+				}`, allowed, consumed)),
+			),
+		)
+	}
+
+	fixedNodes := `{
+		"compute": 3,
+		"compute_machine_type": {
+			"kind": "MachineTypeLink",
+			"id": "m5.xlarge"
+		}
+	}`
+
+	It("Scales up the cluster", func() {
+		prepareCluster(fixedNodes)
+		prepareQuota(10, 5)
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPatch, "/api/clusters_mgmt/v1/clusters/123"),
+				VerifyJQ(`.nodes.compute`, 5.0),
+				RespondWithJSON(http.StatusOK, `{}`),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "scale", "my-cluster", "--compute", "5").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal(
+			"Scaling cluster 'my-cluster' from 3 to 5 compute nodes\n",
+		))
+	})
+
+	It("Scales down the cluster without checking the quota", func() {
+		prepareCluster(fixedNodes)
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPatch, "/api/clusters_mgmt/v1/clusters/123"),
+				VerifyJQ(`.nodes.compute`, 2.0),
+				RespondWithJSON(http.StatusOK, `{}`),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "scale", "my-cluster", "--compute", "2").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal(
+			"Scaling cluster 'my-cluster' from 3 to 2 compute nodes\n",
+		))
+	})
+
+	It("Doesn't change the cluster in dry run mode", func() {
+		prepareCluster(fixedNodes)
+		prepareQuota(10, 5)
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "scale", "my-cluster", "--compute", "5", "--dry-run").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal(
+			"Cluster 'my-cluster' can be scaled from 3 to 5 compute nodes\n",
+		))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(5))
+	})
+
+	It("Does nothing if the number of nodes doesn't change", func() {
+		prepareCluster(fixedNodes)
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "scale", "my-cluster", "--compute", "3").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal(
+			"Cluster 'my-cluster' already has 3 compute nodes\n",
+		))
+	})
+
+	It("Fails if there isn't enough quota", func() {
+		prepareCluster(fixedNodes)
+		prepareQuota(10, 9)
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "scale", "my-cluster", "--compute", "5").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Organization '333' doesn't have enough quota to add 2 compute nodes of type " +
+				"'m5.xlarge' to cluster 'my-cluster', only 1 are available",
+		))
+	})
+
+	It("Fails if the number of nodes isn't valid", func() {
+		prepareCluster(fixedNodes)
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "scale", "my-cluster", "--compute", "1").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Can't scale cluster 'my-cluster' to 1 compute nodes: Minimum is 2 nodes",
+		))
+	})
+
+	It("Fails if autoscaling is enabled", func() {
+		prepareCluster(`{
+			"autoscale_compute": {
+				"min_replicas": 2,
+				"max_replicas": 6
+			},
+			"compute_machine_type": {
+				"kind": "MachineTypeLink",
+				"id": "m5.xlarge"
+			}
+		}`)
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "scale", "my-cluster", "--compute", "4").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Cluster 'my-cluster' has autoscaling enabled for the default machine pool",
+		))
+	})
+})
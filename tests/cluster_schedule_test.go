@@ -0,0 +1,195 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster schedule", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+	var tmpDir string
+	var file string
+
+	// respondWithCluster prepares the server so that the cluster is found:
+	respondWithCluster := func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"state": "ready"
+				}`,
+			),
+		)
+	}
+
+	// loadSchedules reads the schedules saved by the command:
+	loadSchedules := func() []map[string]interface{} {
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		var result []map[string]interface{}
+		Expect(json.Unmarshal(data, &result)).To(Succeed())
+		return result
+	}
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Create the directory for the schedules file:
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-schedules-*")
+		Expect(err).ToNot(HaveOccurred())
+		file = filepath.Join(tmpDir, "schedules.json")
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+
+		// Remove the schedules:
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Sets the last run time when the schedule is created", func() {
+		respondWithCluster()
+		before := time.Now().Add(-time.Second)
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_SCHEDULES", file).
+			Args("cluster", "schedule", "hibernate", "my-cluster", "--cron", "0 20 * * 1-5").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		schedules := loadSchedules()
+		Expect(schedules).To(HaveLen(1))
+		Expect(schedules[0]["cluster_id"]).To(Equal("123"))
+		Expect(schedules[0]["action"]).To(Equal("hibernate"))
+		Expect(schedules[0]["cron"]).To(Equal("0 20 * * 1-5"))
+		lastRun, err := time.Parse(time.RFC3339Nano, schedules[0]["last_run"].(string))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lastRun).To(BeTemporally(">=", before))
+	})
+
+	It("Keeps the last run time when the schedule doesn't change", func() {
+		lastRun := "2022-01-10T20:00:00Z"
+		Expect(os.WriteFile(file, []byte(`[{
+			"cluster_id": "123",
+			"cluster_name": "my-cluster",
+			"action": "hibernate",
+			"cron": "0 20 * * 1-5",
+			"last_run": "`+lastRun+`"
+		}]`), 0600)).To(Succeed())
+		respondWithCluster()
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_SCHEDULES", file).
+			Args("cluster", "schedule", "hibernate", "my-cluster", "--cron", "0 20 * * 1-5").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		schedules := loadSchedules()
+		Expect(schedules).To(HaveLen(1))
+		Expect(schedules[0]["last_run"]).To(Equal(lastRun))
+	})
+
+	It("Doesn't run a schedule created after its last occurrence", func() {
+		respondWithCluster()
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_SCHEDULES", file).
+			Args("cluster", "schedule", "resume", "my-cluster", "--cron", "* * * * *").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		requests := len(apiServer.ReceivedRequests())
+
+		// The cron expression matches every minute, but as the schedule has just been created
+		// there is nothing to run unless the minute has changed in the meantime:
+		created := loadSchedules()[0]["last_run"].(string)
+		createdTime, err := time.Parse(time.RFC3339Nano, created)
+		Expect(err).ToNot(HaveOccurred())
+		if createdTime.Truncate(time.Minute) != time.Now().Truncate(time.Minute) {
+			Skip("minute changed while running the test")
+		}
+		result = NewCommand().
+			ConfigString(config).
+			Env("OCM_SCHEDULES", file).
+			Args("cluster", "schedule", "run").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(requests))
+	})
+})
@@ -0,0 +1,149 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster ssh", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// prepareCluster prepares the server so that the cluster is found with the given API
+	// settings:
+	prepareCluster := func(api string) {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"api": `+api+`
+				}`,
+			),
+		)
+	}
+
+	It("Prints the command for a public cluster", func() {
+		prepareCluster(`{
+			"url": "https://api.my-cluster.abcd.p1.openshiftapps.com:6443",
+			"listening": "external"
+		}`)
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "ssh", "--dry-run", "my-cluster", "--", "uptime").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal(
+			"ssh sre-user@rh-ssh.my-cluster.abcd.p1.openshiftapps.com uptime\n",
+		))
+	})
+	It("Uses the jump host for a private cluster", func() {
+		prepareCluster(`{
+			"url": "https://api.my-cluster.abcd.p1.openshiftapps.com:6443",
+			"listening": "internal"
+		}`)
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_SSH_JUMP", "me@bastion.example.com").
+			Args("cluster", "ssh", "--dry-run", "--user", "me", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal(
+			"ssh -J me@bastion.example.com me@rh-ssh.my-cluster.abcd.p1.openshiftapps.com\n",
+		))
+	})
+
+	It("Fails for a private cluster without jump host", func() {
+		prepareCluster(`{
+			"url": "https://api.my-cluster.abcd.p1.openshiftapps.com:6443",
+			"listening": "internal"
+		}`)
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "ssh", "--dry-run", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Cluster 'my-cluster' is private, use the '--jump' option",
+		))
+	})
+})
@@ -0,0 +1,409 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster upgrade policies freeze", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+	var tmpDir string
+	var file string
+
+	// policy is an automatic upgrade policy of cluster '123':
+	policy := `{
+		"kind": "UpgradePolicy",
+		"id": "456",
+		"href": "/api/clusters_mgmt/v1/clusters/123/upgrade_policies/456",
+		"cluster_id": "123",
+		"schedule_type": "automatic",
+		"schedule": "0 2 * * 1",
+		"upgrade_type": "OSD"
+	}`
+
+	// respondWithClusters prepares the server so that the search finds the given clusters:
+	respondWithClusters := func(ids ...string) {
+		items := make([]map[string]interface{}, len(ids))
+		for i, id := range ids {
+			items[i] = map[string]interface{}{
+				"kind":  "Cluster",
+				"id":    id,
+				"name":  "my-cluster-" + id,
+				"state": "ready",
+			}
+		}
+		data, err := json.Marshal(map[string]interface{}{
+			"kind":  "ClusterList",
+			"page":  1,
+			"size":  len(items),
+			"total": len(items),
+			"items": items,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+				VerifyFormKV("search", "name like 'my-%'"),
+				RespondWithJSON(http.StatusOK, string(data)),
+			),
+		)
+	}
+
+	// respondWithShard prepares the server so that the cluster runs in the given shard:
+	respondWithShard := func(id, server string) {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/"+id+"/provision_shard"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "ProvisionShard",
+					"id": "shard-`+id+`",
+					"hive_config": {
+						"server": "`+server+`"
+					}
+				}`),
+			),
+		)
+	}
+
+	// respondWithPolicies prepares the server so that the cluster has the given policies:
+	respondWithPolicies := func(id string, policies ...string) {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/"+id+"/upgrade_policies"),
+				RespondWithJSON(http.StatusOK, fmt.Sprintf(
+					`{
+						"kind": "UpgradePolicyList",
+						"page": 1,
+						"size": %d,
+						"total": %d,
+						"items": [%s]
+					}`,
+					len(policies), len(policies), strings.Join(policies, ","),
+				)),
+			),
+		)
+	}
+
+	// loadFreezes reads the freeze records saved by the commands:
+	loadFreezes := func() []map[string]interface{} {
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		var result []map[string]interface{}
+		Expect(json.Unmarshal(data, &result)).To(Succeed())
+		return result
+	}
+
+	// writeFreeze saves a freeze record with cluster '123' paused:
+	writeFreeze := func() {
+		Expect(os.WriteFile(file, []byte(`[{
+			"id": "20221001-100000",
+			"reason": "Quarter end",
+			"search": "name like 'my-%'",
+			"paused_at": "2022-10-01T10:00:00Z",
+			"paused_by": "jdoe",
+			"clusters": [
+				{
+					"id": "123",
+					"name": "my-cluster-123",
+					"shard": "https://hive-1.example.com:6443",
+					"state": "paused",
+					"policies": [`+policy+`]
+				}
+			]
+		}]`), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Create the directory for the freezes file:
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-freezes-*")
+		Expect(err).ToNot(HaveOccurred())
+		file = filepath.Join(tmpDir, "freezes.json")
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+
+		// Remove the freezes:
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Doesn't delete anything in dry run mode", func() {
+		respondWithClusters("123")
+		respondWithShard("123", "https://hive-1.example.com:6443")
+		respondWithPolicies("123", policy)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_FREEZES", file).
+			Args("cluster", "upgrade-policies", "pause", "--search", "name like 'my-%'", "--dry-run").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring("Shard 'https://hive-1.example.com:6443':"))
+		Expect(result.OutString()).To(ContainSubstring(
+			"my-cluster-123: pause automatic upgrades with schedule '0 2 * * 1'",
+		))
+		Expect(result.OutString()).To(ContainSubstring("to be paused: 1"))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(3))
+		_, err := os.Stat(file)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("Doesn't save a freeze record in curl mode", func() {
+		respondWithClusters("123")
+		respondWithShard("123", "https://hive-1.example.com:6443")
+		respondWithPolicies("123", policy)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_FREEZES", file).
+			Args("cluster", "upgrade-policies", "pause", "--search", "name like 'my-%'", "--curl").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("--request DELETE"))
+		Expect(result.OutString()).ToNot(ContainSubstring("Freeze '"))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(3))
+		_, err := os.Stat(file)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("Deletes the policies and saves them in the freeze record", func() {
+		respondWithClusters("123", "789")
+		respondWithShard("123", "https://hive-1.example.com:6443")
+		respondWithPolicies("123", policy)
+		respondWithShard("789", "https://hive-2.example.com:6443")
+		respondWithPolicies("789")
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodDelete, "/api/clusters_mgmt/v1/clusters/123/upgrade_policies/456"),
+				RespondWithJSON(http.StatusNoContent, "{}"),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_FREEZES", file).
+			Args(
+				"cluster", "upgrade-policies", "pause",
+				"--search", "name like 'my-%'",
+				"--reason", "Quarter end",
+			).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring("Clusters: 2, paused: 1, skipped: 1, failed: 0"))
+		Expect(result.OutString()).To(MatchRegexp(
+			`Freeze '\d{8}-\d{6}' saved, use 'ocm cluster upgrade-policies resume \d{8}-\d{6}'`,
+		))
+
+		freezes := loadFreezes()
+		Expect(freezes).To(HaveLen(1))
+		Expect(freezes[0]["reason"]).To(Equal("Quarter end"))
+		Expect(freezes[0]["search"]).To(Equal("name like 'my-%'"))
+		Expect(freezes[0]).ToNot(HaveKey("resumed_at"))
+		clusters := freezes[0]["clusters"].([]interface{})
+		Expect(clusters).To(HaveLen(1))
+		cluster := clusters[0].(map[string]interface{})
+		Expect(cluster["id"]).To(Equal("123"))
+		Expect(cluster["shard"]).To(Equal("https://hive-1.example.com:6443"))
+		Expect(cluster["state"]).To(Equal("paused"))
+		policies := cluster["policies"].([]interface{})
+		Expect(policies).To(HaveLen(1))
+		Expect(policies[0]).To(HaveKeyWithValue("schedule", "0 2 * * 1"))
+	})
+
+	It("Pauses only the clusters of the given shard", func() {
+		respondWithClusters("123", "789")
+		respondWithShard("123", "https://hive-1.example.com:6443")
+		respondWithShard("789", "https://hive-2.example.com:6443")
+		respondWithPolicies("789", policy)
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodDelete, "/api/clusters_mgmt/v1/clusters/789/upgrade_policies/456"),
+				RespondWithJSON(http.StatusNoContent, "{}"),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_FREEZES", file).
+			Args(
+				"cluster", "upgrade-policies", "pause",
+				"--search", "name like 'my-%'",
+				"--shard", "shard-789",
+			).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).ToNot(ContainSubstring("my-cluster-123"))
+		freezes := loadFreezes()
+		Expect(freezes).To(HaveLen(1))
+		Expect(freezes[0]["shard"]).To(Equal("shard-789"))
+		Expect(freezes[0]["clusters"]).To(HaveLen(1))
+	})
+
+	It("Creates again the policies with the original schedule", func() {
+		writeFreeze()
+		respondWithPolicies("123")
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/clusters/123/upgrade_policies"),
+				VerifyJSONRepresenting(map[string]interface{}{
+					"kind":          "UpgradePolicy",
+					"cluster_id":    "123",
+					"schedule_type": "automatic",
+					"schedule":      "0 2 * * 1",
+					"upgrade_type":  "OSD",
+				}),
+				RespondWithJSON(http.StatusCreated, policy),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_FREEZES", file).
+			Args("cluster", "upgrade-policies", "resume").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring(
+			"my-cluster-123: resume automatic upgrades with schedule '0 2 * * 1'",
+		))
+		Expect(result.OutString()).To(ContainSubstring("Freeze '20221001-100000' resumed"))
+
+		// The record is kept for audit:
+		freezes := loadFreezes()
+		Expect(freezes).To(HaveLen(1))
+		Expect(freezes[0]).To(HaveKey("resumed_at"))
+		cluster := freezes[0]["clusters"].([]interface{})[0].(map[string]interface{})
+		Expect(cluster["state"]).To(Equal("resumed"))
+	})
+
+	It("Doesn't duplicate policies that already exist", func() {
+		writeFreeze()
+		respondWithPolicies("123", policy)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_FREEZES", file).
+			Args("cluster", "upgrade-policies", "resume", "20221001-100000").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring("already exists"))
+		Expect(result.OutString()).To(ContainSubstring("skipped: 1"))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("Keeps the freeze active if a cluster fails", func() {
+		writeFreeze()
+		respondWithPolicies("123")
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusBadRequest, `{
+				"kind": "Error",
+				"reason": "Cluster is being upgraded"
+			}`),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_FREEZES", file).
+			Args("cluster", "upgrade-policies", "resume").
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(1))
+		Expect(result.ErrString()).To(ContainSubstring("Cluster is being upgraded"))
+		freezes := loadFreezes()
+		Expect(freezes[0]).ToNot(HaveKey("resumed_at"))
+	})
+
+	It("Fails if there are no active freezes", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_FREEZES", file).
+			Args("cluster", "upgrade-policies", "resume").
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(1))
+		Expect(result.ErrString()).To(ContainSubstring("There are no active freezes"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Lists the freezes", func() {
+		writeFreeze()
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_FREEZES", file).
+			Args("cluster", "upgrade-policies", "freezes").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[0]).To(MatchRegexp(`^ID\s+STATE\s+CLUSTERS\s+PAUSED AT\s+PAUSED BY\s+REASON$`))
+		Expect(lines[1]).To(MatchRegexp(
+			`^20221001-100000\s+active\s+1\s+2022-10-01 10:00\s+jdoe\s+Quarter end$`,
+		))
+	})
+})
@@ -0,0 +1,205 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Cluster uuid-labels sync", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server so that the cluster, its subscription and its labels are found:
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/subscriptions",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"external_id": "abc",
+					"subscription": {
+						"kind": "SubscriptionLink",
+						"id": "111"
+					},
+					"properties": {
+						"cost-center": "1234"
+					}
+				}`,
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/subscriptions/111",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Subscription",
+					"id": "111",
+					"cluster_id": "123",
+					"display_name": "old-name",
+					"external_cluster_id": "abc"
+				}`,
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/subscriptions/111/labels",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "LabelList",
+					"page": 1,
+					"size": 0,
+					"total": 0,
+					"items": []
+				}`,
+			),
+		)
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Reports the differences", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "uuid-labels", "sync", "--label", "cost-center", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.OutLines()).To(Equal([]string{
+			"CLUSTER  FIELD              CLUSTER VALUE  SUBSCRIPTION VALUE  STATUS",
+			"123      display_name       my-cluster     old-name            different",
+			"123      label:cost-center  1234                               different",
+		}))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Found 2 differences, use the '--fix' option to fix them",
+		))
+		for _, request := range apiServer.ReceivedRequests() {
+			Expect(request.Method).To(Equal(http.MethodGet))
+		}
+	})
+
+	It("Rejects '--fix' in read-only mode", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_READ_ONLY", "true").
+			Args("cluster", "uuid-labels", "sync", "--fix", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("read-only mode is enabled"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Fixes the differences", func() {
+		apiServer.RouteToHandler(
+			http.MethodPatch,
+			"/api/accounts_mgmt/v1/subscriptions/111",
+			CombineHandlers(
+				VerifyJSON(`{
+					"kind": "Subscription",
+					"display_name": "my-cluster"
+				}`),
+				RespondWithJSON(http.StatusOK, `{}`),
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodPost,
+			"/api/accounts_mgmt/v1/subscriptions/111/labels",
+			CombineHandlers(
+				VerifyJQ(`.key`, "cost-center"),
+				VerifyJQ(`.value`, "1234"),
+				RespondWithJSON(http.StatusCreated, `{}`),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args("cluster", "uuid-labels", "sync", "--label", "cost-center", "--fix", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(Equal([]string{
+			"CLUSTER  FIELD              CLUSTER VALUE  SUBSCRIPTION VALUE  STATUS",
+			"123      display_name       my-cluster     old-name            fixed",
+			"123      label:cost-center  1234                               fixed",
+		}))
+	})
+})
@@ -0,0 +1,220 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Dynamic completion", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+	var cache string
+
+	BeforeEach(func() {
+		var err error
+
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Create a temporary directory for the cache, so that we don't use or modify the
+		// cache of the user running the tests:
+		cache, err = ioutil.TempDir("", "ocm-test-*.d")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+
+		// Delete the cache:
+		err := os.RemoveAll(cache)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Completes organization identifiers", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/organizations"),
+				VerifyFormKV("search", "id like 'my%'"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "OrganizationList",
+					"page": 1,
+					"size": 2,
+					"total": 2,
+					"items": [
+						{
+							"kind": "Organization",
+							"id": "my-org",
+							"name": "My organization"
+						},
+						{
+							"kind": "Organization",
+							"id": "my-other-org",
+							"name": "My other organization"
+						}
+					]
+				}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("XDG_CACHE_HOME", cache).
+			Args("__complete", "account", "users", "--org", "my").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(Equal([]string{
+			"my-org\tMy organization",
+			"my-other-org\tMy other organization",
+			":4",
+		}))
+	})
+
+	It("Completes the last of a list of roles", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/roles"),
+				VerifyFormKV("search", "id like 'Cl%'"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "RoleList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Role",
+							"id": "ClusterEditor",
+							"name": "ClusterEditor"
+						}
+					]
+				}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("XDG_CACHE_HOME", cache).
+			Args("__complete", "account", "users", "--roles", "OrganizationAdmin,Cl").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(Equal([]string{
+			"OrganizationAdmin,ClusterEditor",
+			":4",
+		}))
+	})
+
+	It("Completes cluster names and uses the cache", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+				VerifyFormKV("search", "name like 'my%' or id like 'my%'"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "ClusterList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Cluster",
+							"id": "123",
+							"name": "my-cluster"
+						}
+					]
+				}`),
+			),
+		)
+
+		// The first time the result should be obtained from the server, and the second time
+		// from the cache, as there is only one handler:
+		for i := 0; i < 2; i++ {
+			result := NewCommand().
+				ConfigString(config).
+				Env("XDG_CACHE_HOME", cache).
+				Args("__complete", "cluster", "events", "my").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutLines()).To(Equal([]string{
+				"my-cluster\t123",
+				":4",
+			}))
+		}
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("Doesn't complete the second argument", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Env("XDG_CACHE_HOME", cache).
+			Args("__complete", "cluster", "events", "my-cluster", "").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(Equal([]string{
+			":4",
+		}))
+	})
+
+	It("Doesn't complete if not logged in", func() {
+		result := NewCommand().
+			Env("XDG_CACHE_HOME", cache).
+			Args("__complete", "account", "users", "--org", "").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(Equal([]string{
+			":4",
+		}))
+		Expect(result.ErrString()).To(ContainSubstring("Not logged in"))
+	})
+})
@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Compression", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Decompresses gzip responses", func() {
+		buffer := &bytes.Buffer{}
+		writer := gzip.NewWriter(buffer)
+		_, err := writer.Write([]byte(`{"kind": "ClusterList", "items": []}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyHeaderKV("Accept-Encoding", "gzip"),
+				RespondWith(
+					http.StatusOK,
+					buffer.Bytes(),
+					http.Header{
+						"Content-Type":     {"application/json"},
+						"Content-Encoding": {"gzip"},
+					},
+				),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"get",
+				"/api/clusters_mgmt/v1/clusters",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchJSON(`{"kind": "ClusterList", "items": []}`))
+	})
+
+	It("Asks for uncompressed responses with '--no-compress'", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyHeaderKV("Accept-Encoding", "identity"),
+				RespondWithJSON(http.StatusOK, `{"kind": "ClusterList", "items": []}`),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"get", "--no-compress",
+				"/api/clusters_mgmt/v1/clusters",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchJSON(`{"kind": "ClusterList", "items": []}`))
+	})
+})
@@ -0,0 +1,180 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Config contexts", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var prodServer *Server
+	var stageServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		prodServer = MakeTCPServer()
+		stageServer = MakeTCPServer()
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(MakeTokenString("Bearer", 15*time.Minute)),
+			RespondWithAccessToken(MakeTokenString("Bearer", 15*time.Minute)),
+		)
+
+		// Login to the production server in the default context:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", prodServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Login to the staging server in other context:
+		result = NewCommand().
+			ConfigString(config).
+			Args(
+				"login",
+				"--context", "staging",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", stageServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		prodServer.Close()
+		stageServer.Close()
+	})
+
+	// clusters responds with an empty list of clusters.
+	clusters := CombineHandlers(
+		VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+		RespondWithJSON(
+			http.StatusOK,
+			`{
+				"kind": "ClusterList",
+				"page": 1,
+				"size": 0,
+				"total": 0,
+				"items": []
+			}`,
+		),
+	)
+
+	It("Lists the contexts", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("config", "get-contexts").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(MatchRegexp(`^CURRENT\s+NAME\s+URL\s+STATUS$`))
+		Expect(lines[1]).To(MatchRegexp(`^\*\s+default\s+%s\s+logged in$`, prodServer.URL()))
+		Expect(lines[2]).To(MatchRegexp(`^\s+staging\s+%s\s+logged in$`, stageServer.URL()))
+	})
+
+	It("Uses the context given with the flag", func() {
+		stageServer.AppendHandlers(clusters)
+		result := NewCommand().
+			ConfigString(config).
+			Args("get", "--context", "staging", "/api/clusters_mgmt/v1/clusters").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(stageServer.ReceivedRequests()).To(HaveLen(1))
+		Expect(prodServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Uses the context given with the environment variable", func() {
+		stageServer.AppendHandlers(clusters)
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_CONTEXT", "staging").
+			Args("get", "/api/clusters_mgmt/v1/clusters").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(stageServer.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("Changes the current context", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("config", "use-context", "staging").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		stageServer.AppendHandlers(clusters)
+		result = NewCommand().
+			ConfigString(config).
+			Args("get", "/api/clusters_mgmt/v1/clusters").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(stageServer.ReceivedRequests()).To(HaveLen(1))
+		Expect(prodServer.ReceivedRequests()).To(BeEmpty())
+
+		// The default context can still be used explicitly:
+		prodServer.AppendHandlers(clusters)
+		result = NewCommand().
+			ConfigString(config).
+			Args("get", "--context", "default", "/api/clusters_mgmt/v1/clusters").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(prodServer.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("Fails to change to a context that doesn't exist", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("config", "use-context", "junk").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(Equal("Error: Context 'junk' doesn't exist\n"))
+	})
+})
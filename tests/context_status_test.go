@@ -0,0 +1,142 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Context status", func() {
+	var ctx context.Context
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the configuration, with a token that contains the user name:
+		accessToken := MakeTokenObject(jwt.MapClaims{
+			"typ":                "Bearer",
+			"exp":                time.Now().Add(15 * time.Minute).Unix(),
+			"preferred_username": "my-user",
+		})
+		config = EvaluateTemplate(
+			`{
+				"access_token": "{{ .Token }}",
+				"url": "https://api.openshift.com",
+				"token_url": "https://sso.redhat.com/auth/realms/redhat-external/protocol/openid-connect/token",
+				"default_cluster": "my-cluster"
+			}`,
+			"Token", accessToken.Raw,
+		)
+	})
+
+	It("Prints the summary in one line", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("context", "status").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutString()).To(Equal("default:production my-user my-cluster\n"))
+	})
+
+	It("Prints the summary in JSON format", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("context", "status", "--json").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		var status map[string]interface{}
+		err := json.Unmarshal([]byte(result.OutString()), &status)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status).To(Equal(map[string]interface{}{
+			"context":     "default",
+			"environment": "production",
+			"url":         "https://api.openshift.com",
+			"user":        "my-user",
+			"logged_in":   true,
+			"cluster":     "my-cluster",
+		}))
+	})
+
+	It("Uses the custom format", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("context", "status", "--format", "{{ .User }}@{{ .Environment }}").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal("my-user@production\n"))
+	})
+
+	It("Rejects invalid format", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("context", "status", "--format", "{{ .User").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Invalid format"))
+	})
+
+	It("Says that the user is logged out if the token is expired", func() {
+		result := NewCommand().
+			ConfigString(
+				`{
+					"access_token": "{{ .Token }}",
+					"url": "https://api.stage.openshift.com"
+				}`,
+				"Token", MakeTokenString("Bearer", -5*time.Minute),
+			).
+			Args("context", "status").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal("default:staging (logged out)\n"))
+	})
+
+	It("Uses the host name for unknown environments", func() {
+		result := NewCommand().
+			ConfigString(
+				`{
+					"client_id": "my-client",
+					"client_secret": "my-secret",
+					"url": "https://my-server.example.com:8000",
+					"token_url": "https://my-sso.example.com"
+				}`,
+			).
+			Args("context", "status").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal("default:my-server.example.com my-client\n"))
+	})
+
+	It("Prints nothing if there is no configuration", func() {
+		result := NewCommand().
+			Args("context", "status").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(BeEmpty())
+		Expect(result.ErrString()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Curl", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+	})
+
+	When("Config file contains valid credentials", func() {
+		var ssoServer *Server
+		var apiServer *Server
+		var config string
+
+		BeforeEach(func() {
+			// Create the servers:
+			ssoServer = MakeTCPServer()
+			apiServer = MakeTCPServer()
+
+			// Create the token:
+			accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+			// Prepare the server:
+			ssoServer.AppendHandlers(
+				RespondWithAccessToken(accessToken),
+			)
+
+			// Login:
+			result := NewCommand().
+				Args(
+					"login",
+					"--client-id", "my-client",
+					"--client-secret", "my-secret",
+					"--token-url", ssoServer.URL(),
+					"--url", apiServer.URL(),
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			config = result.ConfigString()
+		})
+
+		AfterEach(func() {
+			// Close the servers:
+			ssoServer.Close()
+			apiServer.Close()
+		})
+
+		It("Prints the command and doesn't send requests that modify data", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"post", "--curl",
+					"/api/clusters_mgmt/v1/clusters",
+				).
+				InString(`{"name":"my'cluster"}`).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(BeEmpty())
+			Expect(result.ErrString()).To(ContainSubstring("--request POST"))
+			Expect(result.ErrString()).To(ContainSubstring(
+				`--header "Authorization: Bearer ${OCM_TOKEN}"`,
+			))
+			Expect(result.ErrString()).To(ContainSubstring(
+				`--data '{"name":"my'\''cluster"}'`,
+			))
+			Expect(result.ErrString()).To(ContainSubstring(
+				"'" + apiServer.URL() + "/api/clusters_mgmt/v1/clusters'",
+			))
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("Prints the command and sends requests that read data", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{"kind": "ClusterList", "items": []}`),
+			)
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"get", "--curl",
+					"/api/clusters_mgmt/v1/clusters",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"'" + apiServer.URL() + "/api/clusters_mgmt/v1/clusters'",
+			))
+			Expect(result.OutString()).To(MatchJSON(`{"kind": "ClusterList", "items": []}`))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("Doesn't fail when a command doesn't send a request that modifies data", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "SubscriptionList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "Subscription",
+								"id": "111",
+								"cluster_id": "123"
+							}
+						]
+					}`,
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "Cluster",
+						"id": "123",
+						"name": "my-cluster"
+					}`,
+				),
+			)
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"delete", "machinepool", "--curl",
+					"--cluster", "my-cluster",
+					"mp1",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("--request DELETE"))
+			Expect(result.ErrString()).To(ContainSubstring(
+				"'" + apiServer.URL() + "/api/clusters_mgmt/v1/clusters/123/machine_pools/mp1'",
+			))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+})
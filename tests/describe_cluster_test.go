@@ -18,7 +18,9 @@ package tests
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"    // nolint
@@ -367,5 +369,69 @@ var _ = Describe("Describe clusters", func() {
 				"There are 2 subscriptions with cluster identifier or name 'test'",
 			))
 		})
+
+		It("Describes multiple clusters as a JSON array", func() {
+			// Prepare the server. The requests for the clusters are sent concurrently,
+			// so the responses depend on the request instead of on the order:
+			apiServer.RouteToHandler(
+				http.MethodGet,
+				"/api/accounts_mgmt/v1/subscriptions",
+				func(w http.ResponseWriter, r *http.Request) {
+					search := r.URL.Query().Get("search")
+					w.Header().Set("Content-Type", "application/json")
+					switch {
+					case strings.Contains(search, "'one'"):
+						fmt.Fprint(w, `{"kind": "SubscriptionList", "total": 1, `+
+							`"items": [{"id": "s1", "cluster_id": "111"}]}`)
+					case strings.Contains(search, "'two'"):
+						fmt.Fprint(w, `{"kind": "SubscriptionList", "total": 1, `+
+							`"items": [{"id": "s2", "cluster_id": "222"}]}`)
+					default:
+						fmt.Fprint(w, `{"kind": "SubscriptionList", "total": 0, "items": []}`)
+					}
+				},
+			)
+			apiServer.RouteToHandler(
+				http.MethodGet,
+				"/api/clusters_mgmt/v1/clusters",
+				RespondWithJSON(
+					http.StatusOK,
+					`{"kind": "ClusterList", "total": 0, "items": []}`,
+				),
+			)
+			apiServer.RouteToHandler(
+				http.MethodGet,
+				"/api/clusters_mgmt/v1/clusters/111",
+				RespondWithJSON(
+					http.StatusOK,
+					`{"kind": "Cluster", "id": "111", "name": "one"}`,
+				),
+			)
+			apiServer.RouteToHandler(
+				http.MethodGet,
+				"/api/clusters_mgmt/v1/clusters/222",
+				RespondWithJSON(
+					http.StatusOK,
+					`{"kind": "Cluster", "id": "222", "name": "two"}`,
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args("describe", "clusters", "two", "missing", "one", "--json").
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.OutString()).To(MatchJSON(`[
+				{"kind": "Cluster", "id": "222", "name": "two"},
+				{"kind": "Cluster", "id": "111", "name": "one"}
+			]`))
+			Expect(result.ErrString()).To(ContainSubstring(
+				"There are no subscriptions or clusters with identifier or name 'missing'",
+			))
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Failed to describe 1 of 3 clusters",
+			))
+		})
 	})
 })
@@ -0,0 +1,287 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Describe pools", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server so that the cluster is found:
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"state": "ready",
+					"version": {
+						"id": "openshift-v4.14.3",
+						"raw_id": "4.14.3"
+					}
+				}`,
+			),
+		)
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Describes a machine pool", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/machine_pools/mp1",
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "MachinePool",
+						"id": "mp1",
+						"instance_type": "m5.xlarge",
+						"autoscaling": {
+							"min_replicas": 2,
+							"max_replicas": 4
+						},
+						"labels": {
+							"zone": "b",
+							"app": "a"
+						},
+						"taints": [
+							{
+								"key": "dedicated",
+								"value": "gpu",
+								"effect": "NoSchedule"
+							}
+						],
+						"availability_zones": [
+							"us-east-1a",
+							"us-east-1b"
+						],
+						"subnets": [
+							"subnet-1",
+							"subnet-2"
+						]
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("describe", "machinepool", "--cluster", "my-cluster", "mp1").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		out := result.OutString()
+		Expect(out).To(MatchRegexp(`ID:\s+mp1\n`))
+		Expect(out).To(MatchRegexp(`Replicas:\s+2-4\n`))
+		Expect(out).To(MatchRegexp(`Labels:\s+app=a, zone=b\n`))
+		Expect(out).To(MatchRegexp(`Taints:\s+dedicated=gpu:NoSchedule\n`))
+		Expect(out).To(MatchRegexp(`Availability zones:\s+us-east-1a, us-east-1b\n`))
+		Expect(out).To(MatchRegexp(`Subnets:\s+subnet-1, subnet-2\n`))
+		Expect(out).To(MatchRegexp(`Version:\s+4.14.3\n`))
+		Expect(out).To(MatchRegexp(`Current replicas:\s+N/A\n`))
+	})
+
+	It("Describes the status and version reported for a machine pool", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "MachinePool",
+					"id": "mp1",
+					"replicas": 3,
+					"version": {
+						"id": "openshift-v4.14.1",
+						"raw_id": "4.14.1"
+					},
+					"status": {
+						"current_replicas": 2,
+						"message": "Scaling up"
+					}
+				}`,
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("describe", "machinepool", "--cluster", "my-cluster", "mp1").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		out := result.OutString()
+		Expect(out).To(MatchRegexp(`Version:\s+4.14.1\n`))
+		Expect(out).To(MatchRegexp(`Replicas:\s+3\n`))
+		Expect(out).To(MatchRegexp(`Current replicas:\s+2\n`))
+		Expect(out).To(MatchRegexp(`Message:\s+Scaling up\n`))
+	})
+
+	It("Describes a node pool", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/node_pools/workers",
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "NodePool",
+						"id": "workers",
+						"replicas": 3,
+						"aws_node_pool": {
+							"instance_type": "m5.xlarge"
+						},
+						"availability_zone": "us-east-1a",
+						"subnet": "subnet-123",
+						"version": {
+							"id": "openshift-v4.14.1",
+							"raw_id": "4.14.1"
+						},
+						"status": {
+							"current_replicas": 2,
+							"message": "Scaling up"
+						}
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("describe", "nodepool", "--cluster", "my-cluster", "workers").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		out := result.OutString()
+		Expect(out).To(MatchRegexp(`ID:\s+workers\n`))
+		Expect(out).To(MatchRegexp(`Version:\s+4.14.1\n`))
+		Expect(out).To(MatchRegexp(`Replicas:\s+3\n`))
+		Expect(out).To(MatchRegexp(`Current replicas:\s+2\n`))
+		Expect(out).To(MatchRegexp(`Subnet:\s+subnet-123\n`))
+		Expect(out).To(MatchRegexp(`Message:\s+Scaling up\n`))
+	})
+
+	It("Prints the node pool JSON returned by the server", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "NodePool",
+					"id": "workers",
+					"tuning_configs": ["my-config"]
+				}`,
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("describe", "nodepool", "--cluster", "my-cluster", "--json", "workers").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchJSON(`{
+			"kind": "NodePool",
+			"id": "workers",
+			"tuning_configs": ["my-config"]
+		}`))
+	})
+
+	It("Fails if the node pool doesn't exist", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusNotFound,
+				`{
+					"kind": "Error",
+					"id": "404",
+					"code": "CLUSTERS-MGMT-404",
+					"reason": "Node pool 'workers' not found"
+				}`,
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("describe", "nodepool", "--cluster", "my-cluster", "workers").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Node pool 'workers' not found"))
+	})
+})
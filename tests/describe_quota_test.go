@@ -0,0 +1,254 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Describe quota", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Shows the resources, SKU rules and consumers of the quota", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/organizations/o1/quota_cost"),
+				VerifyFormKV("search", "quota_id = 'cluster|byoc|moa|marketplace'"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "QuotaCostList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"quota_id": "cluster|byoc|moa|marketplace",
+								"allowed": 10,
+								"consumed": 2,
+								"related_resources": [
+									{
+										"resource_type": "cluster.aws",
+										"resource_name": "any",
+										"product": "ROSA",
+										"billing_model": "marketplace",
+										"byoc": "byoc",
+										"availability_zone_type": "any",
+										"cost": 1
+									}
+								]
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/sku_rules"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "SkuRulesList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "SkuRule",
+								"id": "s1",
+								"sku": "MW00530",
+								"quota_id": "cluster|byoc|moa|marketplace",
+								"allowed": 1
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/subscriptions"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "SubscriptionList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"kind": "Subscription",
+								"id": "sub1",
+								"cluster_id": "123",
+								"display_name": "my-cluster"
+							},
+							{
+								"kind": "Subscription",
+								"id": "sub2",
+								"cluster_id": "456",
+								"display_name": "your-cluster"
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/accounts_mgmt/v1/subscriptions/sub1/reserved_resources",
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "ReservedResourceList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"resource_type": "cluster.aws",
+								"resource_name": "m5.xlarge",
+								"billing_model": "marketplace",
+								"byoc": true,
+								"availability_zone_type": "multi",
+								"count": 2
+							},
+							{
+								"resource_type": "compute.node.aws",
+								"resource_name": "m5.xlarge",
+								"billing_model": "marketplace",
+								"byoc": true,
+								"availability_zone_type": "multi",
+								"count": 3
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/accounts_mgmt/v1/subscriptions/sub2/reserved_resources",
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "ReservedResourceList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"resource_type": "cluster.aws",
+								"resource_name": "m5.xlarge",
+								"billing_model": "standard",
+								"byoc": true,
+								"availability_zone_type": "single",
+								"count": 1
+							}
+						]
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("describe", "quota", "cluster|byoc|moa|marketplace", "--org", "o1").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		out := result.OutString()
+		Expect(out).To(MatchRegexp(`Allowed:\s+10\n`))
+		Expect(out).To(MatchRegexp(`Consumed:\s+2\n`))
+		Expect(out).To(MatchRegexp(
+			`\ncluster.aws\s+any\s+ROSA\s+marketplace\s+byoc\s+any\s+1\n`,
+		))
+		Expect(out).To(MatchRegexp(`\nMW00530\s+1\n`))
+		Expect(out).To(MatchRegexp(`\nsub1\s+123\s+my-cluster\s+m5.xlarge\s+2\s+2\n`))
+		Expect(out).ToNot(ContainSubstring("sub2"))
+		Expect(out).ToNot(ContainSubstring("compute.node.aws"))
+	})
+
+	It("Fails if the quota doesn't exist", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "QuotaCostList",
+					"page": 1,
+					"size": 0,
+					"total": 0,
+					"items": []
+				}`,
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("describe", "quota", "junk", "--org", "o1").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Quota 'junk' doesn't exist in organization 'o1'",
+		))
+	})
+})
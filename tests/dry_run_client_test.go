@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Client dry run", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+	})
+
+	When("Not logged in", func() {
+		It("Prints the request instead of sending it", func() {
+			result := NewCommand().
+				Args("post", "--dry-run=client", "/api/my_service/v1/my_object").
+				InString("name: my-object\n").
+				Run(ctx)
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(HavePrefix(
+				"Command is valid. No request was sent, the first one would have been:",
+			))
+			Expect(result.OutString()).To(ContainSubstring("--request POST"))
+			Expect(result.OutString()).To(ContainSubstring(`--data '{"name":"my-object"}'`))
+			Expect(result.OutString()).To(ContainSubstring(
+				"'https://api.openshift.com/api/my_service/v1/my_object'",
+			))
+		})
+
+		It("Reports validation errors", func() {
+			result := NewCommand().
+				Args("post", "--dry-run=client", "/api/my_service/v1/my_object").
+				InString("name: first\n---\nname: second\n").
+				Run(ctx)
+			Expect(result.ExitCode()).To(Equal(1))
+			Expect(result.OutString()).To(BeEmpty())
+			Expect(result.ErrString()).ToNot(BeEmpty())
+		})
+
+		It("Reports invalid flags", func() {
+			result := NewCommand().
+				Args("cluster", "scale", "my-cluster", "--compute", "many", "--dry-run=client").
+				Run(ctx)
+			Expect(result.ExitCode()).To(Equal(1))
+			Expect(result.ErrString()).To(ContainSubstring(`invalid argument "many"`))
+		})
+
+		It("Rejects other modes", func() {
+			result := NewCommand().
+				Args("get", "--dry-run=server", "/api/clusters_mgmt/v1/clusters").
+				Run(ctx)
+			Expect(result.ExitCode()).To(Equal(1))
+			Expect(result.ErrString()).To(ContainSubstring("only 'client' is supported"))
+		})
+
+		It("Rejects commands that need to read from the server before changing it", func() {
+			result := NewCommand().
+				Args("cluster", "scale", "my-cluster", "--compute", "3", "--dry-run=client").
+				Run(ctx)
+			Expect(result.ExitCode()).To(Equal(1))
+			Expect(result.OutString()).To(BeEmpty())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Command 'ocm cluster scale' needs to read from the server before it can " +
+					"build the request that changes it",
+			))
+			Expect(result.ErrString()).To(ContainSubstring("/api/accounts_mgmt/v1/subscriptions?"))
+			Expect(result.ErrString()).ToNot(ContainSubstring("--request PATCH"))
+		})
+
+		It("Validates commands that change the server in read-only mode", func() {
+			result := NewCommand().
+				Env("OCM_READ_ONLY", "true").
+				Args("delete", "--dry-run=client", "/api/clusters_mgmt/v1/clusters/123").
+				Run(ctx)
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(ContainSubstring("--request DELETE"))
+		})
+	})
+
+	When("Logged in", func() {
+		var ssoServer *Server
+		var apiServer *Server
+		var config string
+
+		BeforeEach(func() {
+			// Create the servers:
+			ssoServer = MakeTCPServer()
+			apiServer = MakeTCPServer()
+
+			// Create the token:
+			accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+			// Prepare the server:
+			ssoServer.AppendHandlers(
+				RespondWithAccessToken(accessToken),
+			)
+
+			// Login:
+			result := NewCommand().
+				Args(
+					"login",
+					"--client-id", "my-client",
+					"--client-secret", "my-secret",
+					"--token-url", ssoServer.URL(),
+					"--url", apiServer.URL(),
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			config = result.ConfigString()
+		})
+
+		AfterEach(func() {
+			// Close the servers:
+			ssoServer.Close()
+			apiServer.Close()
+		})
+
+		It("Doesn't send any request to the servers", func() {
+			requests := len(ssoServer.ReceivedRequests())
+			result := NewCommand().
+				ConfigString(config).
+				Args("get", "--dry-run=client", "/api/clusters_mgmt/v1/clusters").
+				Run(ctx)
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(ContainSubstring(apiServer.URL() + "/api/clusters_mgmt/v1/clusters"))
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+			Expect(ssoServer.ReceivedRequests()).To(HaveLen(requests))
+		})
+	})
+})
@@ -0,0 +1,233 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Edit cluster", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server so that the cluster is found:
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/subscriptions",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"openshift_version": "4.10.3",
+					"product": {
+						"kind": "ProductLink",
+						"id": "osd"
+					},
+					"version": {
+						"kind": "Version",
+						"id": "openshift-v4.10.3",
+						"raw_id": "4.10.3",
+						"channel_group": "stable"
+					}
+				}`,
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/versions/openshift-v4.10.3",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Version",
+					"id": "openshift-v4.10.3",
+					"enabled": true,
+					"available_upgrades": ["4.10.5", "4.10.6"]
+				}`,
+			),
+		)
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// respondWithVersion responds with an enabled version with the given identifier.
+	respondWithVersion := func(id string) http.HandlerFunc {
+		return RespondWithJSON(
+			http.StatusOK,
+			`{
+				"kind": "Version",
+				"id": "`+id+`",
+				"enabled": true
+			}`,
+		)
+	}
+
+	// routeToPatch prepares the server to accept the update of the channel group.
+	routeToPatch := func(channelGroup string) {
+		apiServer.RouteToHandler(
+			http.MethodPatch,
+			"/api/clusters_mgmt/v1/clusters/123",
+			CombineHandlers(
+				VerifyJQ(`.version.channel_group`, channelGroup),
+				RespondWithJSON(http.StatusOK, `{}`),
+			),
+		)
+	}
+
+	// patched checks if the cluster was updated.
+	patched := func() bool {
+		for _, request := range apiServer.ReceivedRequests() {
+			if request.Method == http.MethodPatch {
+				return true
+			}
+		}
+		return false
+	}
+
+	It("Changes the channel group when it contains the current version", func() {
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/versions/openshift-v4.10.3-fast",
+			respondWithVersion("openshift-v4.10.3-fast"),
+		)
+		routeToPatch("fast")
+		result := NewCommand().
+			ConfigString(config).
+			Args("edit", "cluster", "--cluster", "my-cluster", "--channel-group", "fast").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Warning: Versions in the 'fast' channel group are supported",
+		))
+		Expect(patched()).To(BeTrue())
+	})
+
+	It("Changes the channel group when it contains an upgrade", func() {
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/versions/openshift-v4.10.3-candidate",
+			RespondWithJSON(http.StatusNotFound, `{}`),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/versions/openshift-v4.10.5-candidate",
+			RespondWithJSON(http.StatusNotFound, `{}`),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/versions/openshift-v4.10.6-candidate",
+			respondWithVersion("openshift-v4.10.6-candidate"),
+		)
+		routeToPatch("candidate")
+		result := NewCommand().
+			ConfigString(config).
+			Args("edit", "cluster", "--cluster", "my-cluster", "--channel-group", "candidate").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrLines()).To(ConsistOf(
+			"Warning: Versions in the 'candidate' channel group aren't supported, don't "+
+				"use it for production clusters",
+			"Warning: Channel group 'candidate' doesn't contain the current version "+
+				"'4.10.3', but it contains the versions it can be upgraded to: 4.10.6",
+		))
+		Expect(patched()).To(BeTrue())
+	})
+
+	It("Rejects a channel group without the version or its upgrades", func() {
+		for _, version := range []string{"4.10.3", "4.10.5", "4.10.6"} {
+			apiServer.RouteToHandler(
+				http.MethodGet,
+				"/api/clusters_mgmt/v1/versions/openshift-v"+version+"-eus",
+				RespondWithJSON(http.StatusNotFound, `{}`),
+			)
+		}
+		result := NewCommand().
+			ConfigString(config).
+			Args("edit", "cluster", "--cluster", "my-cluster", "--channel-group", "eus").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Channel group 'eus' doesn't contain version '4.10.3' of cluster " +
+				"'my-cluster' or any of the versions it can be upgraded to",
+		))
+		Expect(patched()).To(BeFalse())
+	})
+})
@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Examples", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("All the examples are valid", func() {
+		result := NewCommand().
+			Args("examples", "--check").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(HavePrefix("Checked examples of "))
+	})
+
+	It("Lists the commands that have examples", func() {
+		result := NewCommand().
+			Args("examples").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(ContainElement("ocm list clusters"))
+	})
+
+	It("Displays the examples of a command with the sample output", func() {
+		result := NewCommand().
+			Args("examples", "list", "clusters").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring(
+			"  # List the clusters that are running in a region\n" +
+				"  ocm list clusters --parameter search=\"region.id = 'us-east-1'\"\n",
+		))
+		Expect(result.OutString()).To(ContainSubstring(
+			"  > my-cluster  4.10.3             installing\n",
+		))
+	})
+
+	It("Adds the examples to the help", func() {
+		result := NewCommand().
+			Args("list", "clusters", "--help").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring(
+			"  # Count the clusters of each version\n" +
+				"  ocm list clusters --group-by version\n",
+		))
+		Expect(result.OutString()).ToNot(ContainSubstring("  > "))
+	})
+
+	It("Fails for commands that don't exist", func() {
+		result := NewCommand().
+			Args("examples", "junk").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Command 'junk' doesn't exist"))
+	})
+})
@@ -0,0 +1,425 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Fleet apply IDP", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+	var tmpDir string
+	var file string
+
+	BeforeEach(func() {
+		var err error
+
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Write the identity provider file:
+		tmpDir, err = os.MkdirTemp("", "ocm-test-*")
+		Expect(err).ToNot(HaveOccurred())
+		file = filepath.Join(tmpDir, "idp.json")
+		err = os.WriteFile(file, []byte(`{
+			"name": "sso",
+			"type": "GithubIdentityProvider",
+			"mapping_method": "claim",
+			"github": {
+				"client_id": "new-client",
+				"client_secret": "my-secret",
+				"organizations": ["my-org"]
+			}
+		}`), 0600)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Prepare the server:
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "ClusterList",
+					"page": 1,
+					"size": 2,
+					"total": 2,
+					"items": [
+						{
+							"kind": "Cluster",
+							"id": "123",
+							"name": "my-cluster"
+						},
+						{
+							"kind": "Cluster",
+							"id": "456",
+							"name": "your-cluster"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "IdentityProviderList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "IdentityProvider",
+							"id": "789",
+							"name": "sso",
+							"type": "GithubIdentityProvider",
+							"mapping_method": "claim",
+							"github": {
+								"client_id": "old-client",
+								"organizations": ["my-org"]
+							}
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "IdentityProviderList",
+					"page": 1,
+					"size": 0,
+					"total": 0,
+					"items": []
+				}`,
+			),
+		)
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+
+		// Remove the temporary files:
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Shows the changes without applying them in dry run mode", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"fleet", "apply-idp",
+				"--file", file,
+				"--search", "name like 'my-%'",
+				"--dry-run",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(5))
+		Expect(lines[0]).To(Equal("my-cluster: update identity provider 'sso'"))
+		Expect(lines[1]).To(Equal(`  github.client_id: "old-client" -> "new-client"`))
+		Expect(lines[2]).To(Equal("your-cluster: create identity provider 'sso'"))
+		Expect(lines[4]).To(Equal(
+			"Clusters: 2, to be created: 1, to be updated: 1, skipped: 0, failed: 0",
+		))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(3))
+	})
+	It("Keeps the lines of each cluster together when running in parallel", func() {
+		// The clusters are processed in any order, so the identity providers are routed by
+		// path instead of using the order of the handlers:
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+			apiServer.GetHandler(1),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/456/identity_providers",
+			apiServer.GetHandler(2),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"fleet", "apply-idp",
+				"--file", file,
+				"--search", "name like 'my-%'",
+				"--dry-run",
+				"--parallel", "2",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(5))
+		Expect(lines[:3]).To(ContainElement("your-cluster: create identity provider 'sso'"))
+		index := 0
+		for index < 2 && lines[index] != "my-cluster: update identity provider 'sso'" {
+			index++
+		}
+		Expect(lines[index]).To(Equal("my-cluster: update identity provider 'sso'"))
+		Expect(lines[index+1]).To(Equal(`  github.client_id: "old-client" -> "new-client"`))
+		Expect(lines[4]).To(Equal(
+			"Clusters: 2, to be created: 1, to be updated: 1, skipped: 0, failed: 0",
+		))
+	})
+
+	It("Rejects invalid number of parallel workers", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"fleet", "apply-idp",
+				"--file", file,
+				"--search", "name like 'my-%'",
+				"--parallel", "0",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Option '--parallel' must be at least 1",
+		))
+	})
+
+	When("Only the secrets change", func() {
+		BeforeEach(func() {
+			err := os.WriteFile(file, []byte(`{
+				"name": "sso",
+				"type": "GithubIdentityProvider",
+				"mapping_method": "claim",
+				"github": {
+					"client_id": "old-client",
+					"client_secret": "new-secret",
+					"organizations": ["my-org"]
+				}
+			}`), 0600)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Doesn't detect the changes", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"fleet", "apply-idp",
+					"--file", file,
+					"--search", "name like 'my-%'",
+					"--dry-run",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			lines := result.OutLines()
+			Expect(lines).To(HaveLen(4))
+			Expect(lines[0]).To(Equal("my-cluster: identity provider 'sso' is up to date"))
+			Expect(lines[3]).To(Equal(
+				"Clusters: 2, to be created: 1, to be updated: 0, skipped: 1, failed: 0",
+			))
+		})
+
+		It("Updates the identity provider when forced", func() {
+			// The update is sent before retrieving the identity providers of the second
+			// cluster, so it needs to replace the handler for that:
+			apiServer.SetHandler(
+				2,
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPatch,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/789",
+					),
+					VerifyJQ(`.github.client_secret`, "new-secret"),
+					RespondWithJSON(http.StatusOK, `{}`),
+				),
+			)
+			apiServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "IdentityProviderList",
+						"page": 1,
+						"size": 0,
+						"total": 0,
+						"items": []
+					}`,
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPost,
+						"/api/clusters_mgmt/v1/clusters/456/identity_providers",
+					),
+					RespondWithJSON(http.StatusCreated, `{}`),
+				),
+			)
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"fleet", "apply-idp",
+					"--file", file,
+					"--search", "name like 'my-%'",
+					"--force-update",
+				).
+				Run(ctx)
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.ExitCode()).To(BeZero())
+			lines := result.OutLines()
+			Expect(lines).To(HaveLen(4))
+			Expect(lines[0]).To(Equal("my-cluster: update identity provider 'sso' (forced)"))
+			Expect(lines[1]).To(Equal("your-cluster: create identity provider 'sso'"))
+			Expect(lines[3]).To(Equal(
+				"Clusters: 2, created: 1, updated: 1, skipped: 0, failed: 0",
+			))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(5))
+		})
+	})
+
+	When("Some of the clusters fail", func() {
+		BeforeEach(func() {
+			// The update of the first cluster fails, and the identity providers of the
+			// second cluster are retrieved after that:
+			apiServer.SetHandler(
+				2,
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodPatch,
+						"/api/clusters_mgmt/v1/clusters/123/identity_providers/789",
+					),
+					RespondWithJSON(http.StatusBadRequest, `{
+						"kind": "Error",
+						"reason": "Update failed"
+					}`),
+				),
+			)
+			apiServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "IdentityProviderList",
+						"page": 1,
+						"size": 0,
+						"total": 0,
+						"items": []
+					}`,
+				),
+			)
+		})
+
+		It("Exits with code 2 and writes the results file", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusCreated, `{}`),
+			)
+			results := filepath.Join(tmpDir, "results.json")
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"fleet", "apply-idp",
+					"--file", file,
+					"--search", "name like 'my-%'",
+					"--results", results,
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(Equal(2))
+			Expect(result.ErrString()).To(ContainSubstring("my-cluster: "))
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Failed to apply identity provider: 1 of 2 items failed",
+			))
+			data, err := os.ReadFile(results)
+			Expect(err).ToNot(HaveOccurred())
+			var content struct {
+				Items []struct {
+					ID     string `json:"id"`
+					Name   string `json:"name"`
+					Action string `json:"action"`
+					Status string `json:"status"`
+					Error  string `json:"error"`
+				} `json:"items"`
+				Summary map[string]int `json:"summary"`
+			}
+			err = json.Unmarshal(data, &content)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content.Items).To(HaveLen(2))
+			Expect(content.Items[0].ID).To(Equal("123"))
+			Expect(content.Items[0].Name).To(Equal("my-cluster"))
+			Expect(content.Items[0].Action).To(Equal("update"))
+			Expect(content.Items[0].Status).To(Equal("failed"))
+			Expect(content.Items[0].Error).To(ContainSubstring("Update failed"))
+			Expect(content.Items[1].ID).To(Equal("456"))
+			Expect(content.Items[1].Action).To(Equal("create"))
+			Expect(content.Items[1].Status).To(Equal("succeeded"))
+			Expect(content.Items[1].Error).To(BeEmpty())
+			Expect(content.Summary).To(Equal(map[string]int{
+				"total":     2,
+				"succeeded": 1,
+				"skipped":   0,
+				"failed":    1,
+			}))
+		})
+
+		It("Exits with code 1 when all the clusters fail", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusBadRequest, `{
+					"kind": "Error",
+					"reason": "Create failed"
+				}`),
+			)
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"fleet", "apply-idp",
+					"--file", file,
+					"--search", "name like 'my-%'",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(Equal(1))
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Failed to apply identity provider: all 2 items failed",
+			))
+		})
+	})
+})
@@ -0,0 +1,173 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Fleet certificates", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	var endpoint *httptest.Server
+
+	BeforeEach(func() {
+		// Prepare a server that plays the role of the endpoints of the first cluster, and
+		// give the second cluster an address where nothing is listening:
+		endpoint = httptest.NewTLSServer(http.NotFoundHandler())
+		closed := httptest.NewTLSServer(http.NotFoundHandler())
+		closed.Close()
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+				VerifyFormKV("search", "state = 'ready'"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "ClusterList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"kind": "Cluster",
+								"id": "123",
+								"name": "my-cluster",
+								"api": {
+									"url": "`+endpoint.URL+`"
+								},
+								"console": {
+									"url": "`+endpoint.URL+`/console"
+								}
+							},
+							{
+								"kind": "Cluster",
+								"id": "456",
+								"name": "your-cluster",
+								"api": {
+									"url": "`+closed.URL+`"
+								}
+							}
+						]
+					}`,
+				),
+			),
+		)
+	})
+
+	AfterEach(func() {
+		endpoint.Close()
+	})
+
+	It("Reports unreachable clusters and succeeds if nothing expires", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("fleet", "certificates", "--timeout", "1s").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(6))
+		Expect(lines[0]).To(MatchRegexp(`^Total clusters:\s+2$`))
+		Expect(lines[1]).To(MatchRegexp(`^Expiring:\s+0 \(within 30 days\)$`))
+		Expect(lines[2]).To(MatchRegexp(`^Unreachable:\s+1$`))
+		Expect(lines[4]).To(MatchRegexp(`^CLUSTER ID\s+NAME\s+ENDPOINT\s+ISSUER`))
+		Expect(lines[5]).To(MatchRegexp(`^456\s+your-cluster\s+api\s+-\s+-\s+unreachable$`))
+	})
+
+	It("Fails if certificates will expire soon", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("fleet", "certificates", "--timeout", "1s", "--days", "36500", "--json").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Found 2 certificates that will expire within 36500 days",
+		))
+		var report map[string]interface{}
+		err := json.Unmarshal([]byte(result.OutString()), &report)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report).To(MatchJQ(`.total`, 2.0))
+		Expect(report).To(MatchJQ(`.expiring`, 2.0))
+		Expect(report).To(MatchJQ(`.unreachable`, 1.0))
+		Expect(report).To(MatchJQ(
+			`[.certificates[] | [.cluster_id, .endpoint, .error != null]]`,
+			[]interface{}{
+				[]interface{}{"123", "api", false},
+				[]interface{}{"123", "ingress", false},
+				[]interface{}{"456", "api", true},
+			},
+		))
+	})
+
+	It("Rejects a negative number of days", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("fleet", "certificates", "--days", "-1").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Option '--days' must not be negative"))
+	})
+})
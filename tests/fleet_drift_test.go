@@ -0,0 +1,304 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Fleet drift", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	var tmpDir string
+	var baseline string
+
+	BeforeEach(func() {
+		// Create the baseline file:
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-drift-*")
+		Expect(err).ToNot(HaveOccurred())
+		baseline = filepath.Join(tmpDir, "baseline.yaml")
+		err = os.WriteFile(baseline, []byte(`
+channel_group: stable
+proxy:
+  http_proxy: ""
+identity_providers:
+  required: [ldap]
+autoscaling:
+  enabled: true
+  max_replicas: 6
+`), 0600)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		// Remove the temporary files:
+		os.RemoveAll(tmpDir)
+	})
+
+	// prepareClusters configures the API server to return two clusters, the first one matching
+	// the baseline and the second one deviating from it.
+	prepareClusters := func(search string) {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+				VerifyFormKV("search", search),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "ClusterList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"kind": "Cluster",
+								"id": "123",
+								"name": "my-cluster",
+								"version": {
+									"channel_group": "stable"
+								},
+								"nodes": {
+									"autoscale_compute": {
+										"min_replicas": 3,
+										"max_replicas": 6
+									}
+								}
+							},
+							{
+								"kind": "Cluster",
+								"id": "456",
+								"name": "your-cluster",
+								"version": {
+									"channel_group": "fast"
+								},
+								"proxy": {
+									"http_proxy": "http://proxy.example.com"
+								},
+								"nodes": {
+									"compute": 3
+								}
+							}
+						]
+					}`,
+				),
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "IdentityProviderList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "IdentityProvider",
+							"id": "789",
+							"name": "corporate",
+							"type": "LDAPIdentityProvider"
+						}
+					]
+				}`,
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/456/identity_providers",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "IdentityProviderList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "IdentityProvider",
+							"id": "012",
+							"name": "github",
+							"type": "GithubIdentityProvider"
+						}
+					]
+				}`,
+			),
+		)
+	}
+
+	It("Reports the deviations as a table", func() {
+		prepareClusters("state = 'ready'")
+		result := NewCommand().
+			ConfigString(config).
+			Args("fleet", "drift", "--baseline", baseline).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Found 4 deviations in 1 clusters"))
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(9))
+		Expect(lines[0]).To(MatchRegexp(`^Total clusters:\s+2$`))
+		Expect(lines[1]).To(MatchRegexp(`^Drifted clusters:\s+1$`))
+		Expect(lines[2]).To(MatchRegexp(`^Deviations:\s+4$`))
+		Expect(lines[4]).To(MatchRegexp(`^CLUSTER ID\s+NAME\s+FIELD\s+EXPECTED\s+ACTUAL$`))
+		Expect(lines[5]).To(MatchRegexp(`^456\s+your-cluster\s+channel_group\s+stable\s+fast$`))
+		Expect(lines[6]).To(MatchRegexp(
+			`^456\s+your-cluster\s+proxy.http_proxy\s+-\s+http://proxy.example.com$`,
+		))
+		Expect(lines[7]).To(MatchRegexp(
+			`^456\s+your-cluster\s+identity_providers\s+required=ldap\s+github$`,
+		))
+		Expect(lines[8]).To(MatchRegexp(
+			`^456\s+your-cluster\s+autoscaling.enabled\s+true\s+false$`,
+		))
+	})
+
+	It("Reports the deviations in JSON format", func() {
+		prepareClusters("state = 'ready' and (cloud_provider.id = 'aws')")
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"fleet", "drift",
+				"--baseline", baseline,
+				"--search", "cloud_provider.id = 'aws'",
+				"--json",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		var report map[string]interface{}
+		err := json.Unmarshal([]byte(result.OutString()), &report)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report).To(MatchJQ(`.total`, 2.0))
+		Expect(report).To(MatchJQ(`.drifted`, 1.0))
+		Expect(report).To(MatchJQ(`.deviations`, 4.0))
+		Expect(report).To(MatchJQ(`[.clusters[] | .id]`, []interface{}{"456"}))
+		Expect(report).To(MatchJQ(
+			`[.clusters[0].deviations[] | .field]`,
+			[]interface{}{
+				"channel_group",
+				"proxy.http_proxy",
+				"identity_providers",
+				"autoscaling.enabled",
+			},
+		))
+	})
+
+	It("Succeeds if there are no deviations", func() {
+		err := os.WriteFile(baseline, []byte("channel_group: stable\n"), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "ClusterList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Cluster",
+							"id": "123",
+							"name": "my-cluster",
+							"version": {
+								"channel_group": "stable"
+							}
+						}
+					]
+				}`,
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args("fleet", "drift", "--baseline", baseline).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[2]).To(MatchRegexp(`^Deviations:\s+0$`))
+	})
+
+	It("Rejects invalid baselines", func() {
+		err := os.WriteFile(baseline, []byte("channel: stable\n"), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		result := NewCommand().
+			ConfigString(config).
+			Args("fleet", "drift", "--baseline", baseline).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Can't parse baseline file"))
+	})
+
+	It("Requires the baseline", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("fleet", "drift").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(`"baseline" not set`))
+	})
+})
@@ -0,0 +1,190 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Fleet health", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+	})
+
+	When("Config file contains valid credentials", func() {
+		var ssoServer *Server
+		var apiServer *Server
+		var config string
+
+		BeforeEach(func() {
+			// Create the servers:
+			ssoServer = MakeTCPServer()
+			apiServer = MakeTCPServer()
+
+			// Create the token:
+			accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+			// Prepare the server:
+			ssoServer.AppendHandlers(
+				RespondWithAccessToken(accessToken),
+			)
+
+			// Login:
+			result := NewCommand().
+				Args(
+					"login",
+					"--client-id", "my-client",
+					"--client-secret", "my-secret",
+					"--token-url", ssoServer.URL(),
+					"--url", apiServer.URL(),
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			config = result.ConfigString()
+
+			// Prepare the server:
+			apiServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "VersionList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"kind": "Version",
+								"id": "openshift-v4.9.1",
+								"enabled": true
+							},
+							{
+								"kind": "Version",
+								"id": "openshift-v4.10.3",
+								"enabled": true,
+								"default": true
+							}
+						]
+					}`,
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "ClusterList",
+						"page": 1,
+						"size": 3,
+						"total": 3,
+						"items": [
+							{
+								"kind": "Cluster",
+								"id": "123",
+								"name": "healthy",
+								"openshift_version": "4.10.3",
+								"state": "ready"
+							},
+							{
+								"kind": "Cluster",
+								"id": "456",
+								"name": "old",
+								"openshift_version": "4.8.2",
+								"state": "ready",
+								"status": {
+									"limited_support_reason_count": 1
+								}
+							},
+							{
+								"kind": "Cluster",
+								"id": "789",
+								"name": "broken",
+								"openshift_version": "4.9.1",
+								"state": "error"
+							}
+						]
+					}`,
+				),
+			)
+		})
+
+		AfterEach(func() {
+			// Close the servers:
+			ssoServer.Close()
+			apiServer.Close()
+		})
+
+		It("Reports only the clusters with issues", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("fleet", "health").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			lines := result.OutLines()
+			Expect(lines).To(HaveLen(9))
+			Expect(lines[0]).To(MatchRegexp(`^Total clusters:\s+3$`))
+			Expect(lines[1]).To(MatchRegexp(`^Not ready:\s+1$`))
+			Expect(lines[2]).To(MatchRegexp(`^Limited support:\s+1$`))
+			Expect(lines[3]).To(MatchRegexp(`^Outdated:\s+1 \(latest version is 4\.10\.3\)$`))
+			Expect(lines[4]).To(MatchRegexp(`^Expiring:\s+0 \(within 7 days\)$`))
+			Expect(lines[6]).To(MatchRegexp(`^ID\s+NAME\s+VERSION\s+STATE\s+ISSUES$`))
+			Expect(lines[7]).To(MatchRegexp(`^456\s+old\s+4\.8\.2\s+ready\s+limited_support, outdated$`))
+			Expect(lines[8]).To(MatchRegexp(`^789\s+broken\s+4\.9\.1\s+error\s+not_ready$`))
+		})
+
+		It("Generates JSON output", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("fleet", "health", "--json").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.OutString()).To(MatchJSON(`{
+				"total": 3,
+				"not_ready": 1,
+				"limited_support": 1,
+				"outdated": 1,
+				"expiring": 0,
+				"latest_version": "4.10.3",
+				"clusters": [
+					{
+						"id": "456",
+						"name": "old",
+						"state": "ready",
+						"version": "4.8.2",
+						"issues": ["limited_support", "outdated"]
+					},
+					{
+						"id": "789",
+						"name": "broken",
+						"state": "error",
+						"version": "4.9.1",
+						"issues": ["not_ready"]
+					}
+				]
+			}`))
+		})
+	})
+})
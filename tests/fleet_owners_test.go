@@ -0,0 +1,215 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Fleet owners", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	BeforeEach(func() {
+		// Prepare the server with three clusters: the first one owned by its creator, the
+		// second one transferred to another account, and the third one created by an account
+		// that has been banned and owned by an account that has been deleted:
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/subscriptions",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 3,
+					"total": 3,
+					"items": [
+						{
+							"id": "sub1",
+							"cluster_id": "123",
+							"display_name": "my-cluster",
+							"creator": {"id": "acc1"}
+						},
+						{
+							"id": "sub2",
+							"cluster_id": "456",
+							"display_name": "your-cluster",
+							"creator": {"id": "acc1"}
+						},
+						{
+							"id": "sub3",
+							"cluster_id": "789",
+							"display_name": "old-cluster",
+							"creator": {"id": "acc3"}
+						}
+					]
+				}`,
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/role_bindings",
+			CombineHandlers(
+				VerifyFormKV("search", "type = 'Subscription' and role_id = 'ClusterOwner'"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "RoleBindingList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"account": {"id": "acc2"},
+								"subscription": {"id": "sub2"}
+							},
+							{
+								"account": {"id": "acc4"},
+								"subscription": {"id": "sub3"}
+							}
+						]
+					}`,
+				),
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/accounts",
+			CombineHandlers(
+				VerifyFormKV("search", "id in ('acc1', 'acc2', 'acc3', 'acc4')"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "AccountList",
+						"page": 1,
+						"size": 3,
+						"total": 3,
+						"items": [
+							{
+								"id": "acc1",
+								"username": "alice",
+								"email": "alice@example.com"
+							},
+							{
+								"id": "acc2",
+								"username": "bob",
+								"email": "bob@example.com"
+							},
+							{
+								"id": "acc3",
+								"username": "carol",
+								"email": "carol@example.com",
+								"banned": true
+							}
+						]
+					}`,
+				),
+			),
+		)
+	})
+
+	It("Writes the creators and owners of the clusters", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("fleet", "owners").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(4))
+		Expect(lines[0]).To(MatchRegexp(
+			`^CLUSTER ID\s+NAME\s+CREATOR\s+CREATOR EMAIL\s+OWNER\s+OWNER EMAIL\s+FLAGS$`,
+		))
+		Expect(lines[1]).To(MatchRegexp(
+			`^123\s+my-cluster\s+alice\s+alice@example.com\s+alice\s+alice@example.com\s*$`,
+		))
+		Expect(lines[2]).To(MatchRegexp(
+			`^456\s+your-cluster\s+alice\s+alice@example.com\s+bob\s+bob@example.com\s*$`,
+		))
+		Expect(lines[3]).To(MatchRegexp(
+			`^789\s+old-cluster\s+carol\s+carol@example.com\s+acc4\s+` +
+				`creator banned, owner deleted$`,
+		))
+	})
+
+	It("Writes only the clusters of deactivated accounts in JSON format", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("fleet", "owners", "--deactivated", "--json").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchJSON(`[
+			{
+				"cluster_id": "789",
+				"name": "old-cluster",
+				"subscription_id": "sub3",
+				"creator": "carol",
+				"creator_email": "carol@example.com",
+				"owner": "acc4",
+				"owner_email": "",
+				"flags": ["creator banned", "owner deleted"]
+			}
+		]`))
+	})
+})
@@ -0,0 +1,249 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Fleet versions", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	When("Clusters use versions with different end of life dates", func() {
+		var soon time.Time
+
+		BeforeEach(func() {
+			// The end of life of version 4.9.1 is in ten days, plus some margin so that the
+			// number of days left doesn't change while the test runs. The end of life of
+			// version 4.10.3 is far in the future, and version 4.8.2 no longer exists.
+			now := time.Now().UTC()
+			soon = now.Add(10*24*time.Hour + time.Hour).Truncate(time.Second)
+			later := now.Add(400 * 24 * time.Hour).Truncate(time.Second)
+
+			apiServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "ClusterList",
+						"page": 1,
+						"size": 4,
+						"total": 4,
+						"items": [
+							{
+								"kind": "Cluster",
+								"id": "123",
+								"name": "old",
+								"version": {
+									"kind": "VersionLink",
+									"id": "openshift-v4.9.1"
+								}
+							},
+							{
+								"kind": "Cluster",
+								"id": "456",
+								"name": "new",
+								"version": {
+									"kind": "VersionLink",
+									"id": "openshift-v4.10.3"
+								}
+							},
+							{
+								"kind": "Cluster",
+								"id": "789",
+								"name": "also-old",
+								"version": {
+									"kind": "VersionLink",
+									"id": "openshift-v4.9.1"
+								}
+							},
+							{
+								"kind": "Cluster",
+								"id": "999",
+								"name": "ancient",
+								"version": {
+									"kind": "VersionLink",
+									"id": "openshift-v4.8.2"
+								}
+							}
+						]
+					}`,
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/versions/openshift-v4.8.2"),
+					RespondWithJSON(
+						http.StatusNotFound,
+						`{
+							"kind": "Error",
+							"id": "404",
+							"reason": "Version 'openshift-v4.8.2' not found"
+						}`,
+					),
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/versions/openshift-v4.9.1"),
+					RespondWithJSON(
+						http.StatusOK,
+						fmt.Sprintf(
+							`{
+								"kind": "Version",
+								"id": "openshift-v4.9.1",
+								"end_of_life_timestamp": "%s"
+							}`,
+							soon.Format(time.RFC3339),
+						),
+					),
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/versions/openshift-v4.10.3"),
+					RespondWithJSON(
+						http.StatusOK,
+						fmt.Sprintf(
+							`{
+								"kind": "Version",
+								"id": "openshift-v4.10.3",
+								"end_of_life_timestamp": "%s"
+							}`,
+							later.Format(time.RFC3339),
+						),
+					),
+				),
+			)
+		})
+
+		It("Shows the number of clusters and days left for each version", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("fleet", "versions").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			lines := result.OutLines()
+			Expect(lines).To(HaveLen(4))
+			Expect(lines[0]).To(MatchRegexp(`^VERSION\s+CLUSTERS\s+END OF LIFE\s+DAYS LEFT$`))
+			Expect(lines[1]).To(MatchRegexp(`^4\.8\.2\s+1\s+NONE\s+NONE$`))
+			Expect(lines[2]).To(MatchRegexp(
+				`^4\.9\.1\s+2\s+%s\s+10$`, soon.Format("2006-01-02"),
+			))
+			Expect(lines[3]).To(MatchRegexp(`^4\.10\.3\s+1\s+\S+\s+399$`))
+		})
+
+		It("Reports the clusters whose version reaches the end of life soon", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("fleet", "versions", "--eol-report", "--days", "30", "--json").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.OutString()).To(MatchJSON(fmt.Sprintf(
+				`[
+					{
+						"id": "123",
+						"name": "old",
+						"version": "4.9.1",
+						"end_of_life": "%[1]s",
+						"days_left": 10
+					},
+					{
+						"id": "789",
+						"name": "also-old",
+						"version": "4.9.1",
+						"end_of_life": "%[1]s",
+						"days_left": 10
+					}
+				]`,
+				soon.Format(time.RFC3339),
+			)))
+		})
+
+		It("Doesn't report clusters outside of the number of days", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("fleet", "versions", "--eol-report", "--days", "5").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutLines()).To(HaveLen(1))
+		})
+	})
+
+	It("Rejects a negative number of days", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("fleet", "versions", "--eol-report", "--days", "-1").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("must not be negative"))
+	})
+
+	It("Rejects the number of days without the end of life report", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("fleet", "versions", "--days", "30").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"can only be used with '--eol-report'",
+		))
+	})
+})
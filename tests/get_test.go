@@ -116,6 +116,68 @@ var _ = Describe("Get", func() {
 			Expect(result.OutString()).To(MatchJSON(`{ "my_field": "my_value" }`))
 		})
 
+		It("Filters the response with the --jq flag", func() {
+			// Prepare the server:
+			apiServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"items": [
+							{ "id": "123", "state": "ready" },
+							{ "id": "456", "state": "error" },
+							{ "id": "789", "state": "ready" }
+						]
+					}`,
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"get", "/api/my_service/v1/my_objects",
+					"--jq", `.items[] | select(.state == "ready") | .id`,
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.OutLines()).To(Equal([]string{"123", "789"}))
+		})
+
+		It("Writes non string results of the --jq flag as JSON", func() {
+			// Prepare the server:
+			apiServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusOK,
+					`{ "items": [ { "id": "123", "size": 12345678901234567890 } ] }`,
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"get", "/api/my_service/v1/my_objects",
+					"--jq", ".items[0]",
+					"--single",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(Equal(
+				`{"id":"123","size":12345678901234567890}` + "\n",
+			))
+		})
+
+		It("Rejects invalid --jq expressions before sending the request", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("get", "/api/my_service/v1/my_objects", "--jq", ".items[").
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("Invalid jq expression"))
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		})
+
 		It("Honours the --parameter flag", func() {
 			// Prepare the server:
 			apiServer.AppendHandlers(
@@ -182,6 +244,51 @@ var _ = Describe("Get", func() {
 			Expect(result.ErrString()).To(BeEmpty())
 		})
 
+		It("Honours the --header flag with the colon syntax", func() {
+			// Prepare the server:
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyHeaderKV("X-My-Header", "my=value"),
+					RespondWithJSON(http.StatusOK, `{}`),
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"get",
+					"--header", "X-My-Header: my=value",
+					"/api/my_service/v1/my_object",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+		})
+
+		It("Honours the --fields flag", func() {
+			// Prepare the server:
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyFormKV("fields", "id,name,region.id"),
+					RespondWithJSON(http.StatusOK, `{}`),
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"get",
+					"--fields", "id,name",
+					"--fields", "region.id",
+					"/api/my_service/v1/my_object",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+		})
+
 		It("Indents by default", func() {
 			// Prepare the server:
 			apiServer.AppendHandlers(
@@ -267,5 +374,89 @@ var _ = Describe("Get", func() {
 				`,
 			)))
 		})
+
+		It("Repeats the request till the --until expression is true", func() {
+			// Prepare the server:
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{ "id": "123", "state": "installing" }`),
+				RespondWithJSON(http.StatusOK, `{ "id": "123", "state": "installing" }`),
+				RespondWithJSON(http.StatusOK, `{ "id": "123", "state": "ready" }`),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"get", "/api/my_service/v1/my_objects/123",
+					"--poll", "10ms",
+					"--until", `.state == "ready"`,
+					"--jq", ".state",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.OutLines()).To(Equal([]string{"ready"}))
+			Expect(apiServer.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		It("Stops polling when the server returns an error", func() {
+			// Prepare the server:
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{ "id": "123", "state": "installing" }`),
+				RespondWithJSON(http.StatusNotFound, `{ "kind": "Error", "id": "404" }`),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"get", "/api/my_service/v1/my_objects/123",
+					"--poll", "10ms",
+					"--until", `.state == "ready"`,
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(MatchJSON(`{ "kind": "Error", "id": "404" }`))
+		})
+
+		It("Fails when the --until expression isn't true before the timeout", func() {
+			// Prepare the server:
+			apiServer.RouteToHandler(
+				http.MethodGet,
+				"/api/my_service/v1/my_objects/123",
+				RespondWithJSON(http.StatusOK, `{ "id": "123", "state": "installing" }`),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"get", "/api/my_service/v1/my_objects/123",
+					"--poll", "10ms",
+					"--until", `.state == "ready"`,
+					"--timeout", "100ms",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.OutString()).To(BeEmpty())
+			Expect(result.ErrString()).To(ContainSubstring(
+				`Timed out after 100ms waiting for '.state == "ready"'`,
+			))
+		})
+
+		It("Rejects --until without --poll", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"get", "/api/my_service/v1/my_objects/123",
+					"--until", `.state == "ready"`,
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Option '--until' requires the '--poll' option",
+			))
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		})
 	})
 })
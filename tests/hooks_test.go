@@ -0,0 +1,190 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Hooks", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	var hooksDir string
+
+	BeforeEach(func() {
+		var err error
+		hooksDir, err = os.MkdirTemp("", "ocm-hooks-*")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(hooksDir)
+	})
+
+	writeHooks := func(content string) {
+		err := os.WriteFile(filepath.Join(hooksDir, "hooks.yaml"), []byte(content), 0600)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	// readInvocation reads the description of the invocation saved by a hook:
+	readInvocation := func(file string) map[string]interface{} {
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		var result map[string]interface{}
+		err = json.Unmarshal(data, &result)
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+
+	It("Doesn't run the command if a pre hook fails", func() {
+		writeHooks(`
+name: ticket
+commands:
+- delete
+run:
+- sh
+- -c
+- echo "A ticket number is required"; exit 1
+`)
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_HOOKS_DIR", hooksDir).
+			Args("delete", "/api/clusters_mgmt/v1/clusters/123").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("A ticket number is required"))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Command rejected by hook 'ticket': exited with code 1",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Runs the hooks before and after the command", func() {
+		pre := filepath.Join(hooksDir, "pre.json")
+		post := filepath.Join(hooksDir, "post.json")
+		writeHooks(`
+name: pre
+run:
+- sh
+- -c
+- cat > ` + pre + `
+---
+name: post
+phase: post
+run:
+- sh
+- -c
+- cat > ` + post + `
+`)
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{"kind": "Cluster", "id": "123"}`),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_HOOKS_DIR", hooksDir).
+			Args("get", "--parameter", "search=x", "/api/clusters_mgmt/v1/clusters/123").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchJSON(`{"kind": "Cluster", "id": "123"}`))
+
+		data := readInvocation(pre)
+		Expect(data).To(MatchJQ(`.phase`, "pre"))
+		Expect(data).To(MatchJQ(`.command`, "get"))
+		Expect(data).To(MatchJQ(`.args`, []interface{}{"/api/clusters_mgmt/v1/clusters/123"}))
+		Expect(data).To(MatchJQ(`.flags.parameter`, "[search=x]"))
+
+		data = readInvocation(post)
+		Expect(data).To(MatchJQ(`.phase`, "post"))
+		Expect(data).To(MatchJQ(`has("error")`, false))
+	})
+
+	It("Passes the error of the command to the post hooks", func() {
+		post := filepath.Join(hooksDir, "post.json")
+		writeHooks(`
+name: post
+phase: post
+commands:
+- get
+run:
+- sh
+- -c
+- cat > ` + post + `
+`)
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusNotFound, `{"kind": "Error", "id": "404"}`),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_HOOKS_DIR", hooksDir).
+			Args("get", "/api/clusters_mgmt/v1/clusters/123").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		data := readInvocation(post)
+		Expect(data).To(MatchJQ(`.phase`, "post"))
+		Expect(data).To(MatchJQ(`.error`, "Command exited with code 1"))
+	})
+})
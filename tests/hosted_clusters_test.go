@@ -0,0 +1,289 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Hosted control planes", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// managementCluster is the response of the fleet management API for the management cluster.
+	managementCluster := CombineHandlers(
+		VerifyRequest(http.MethodGet, "/api/osd_fleet_mgmt/v1/management_clusters"),
+		VerifyFormKV("search", "name = 'hs-mc-1'"),
+		RespondWithJSON(http.StatusOK, `{
+			"kind": "ManagementClusterList",
+			"page": 1,
+			"size": 1,
+			"total": 1,
+			"items": [
+				{
+					"kind": "ManagementCluster",
+					"id": "456",
+					"name": "hs-mc-1",
+					"region": "us-east-1",
+					"sector": "main",
+					"status": "ready",
+					"parent": {
+						"kind": "ServiceCluster",
+						"id": "789",
+						"name": "hs-sc-1"
+					}
+				}
+			]
+		}`),
+	)
+
+	When("Showing the management cluster of a cluster", func() {
+		// prepareCluster prepares the server so that the cluster is found, with the given
+		// hypershift settings.
+		prepareCluster := func(hypershift string) {
+			cluster := `{
+				"kind": "Cluster",
+				"id": "123",
+				"name": "my-cluster",
+				"hypershift": ` + hypershift + `
+			}`
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`),
+				RespondWithJSON(http.StatusOK, cluster),
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+					RespondWithJSON(http.StatusOK, cluster),
+				),
+			)
+		}
+
+		It("Shows the management and service clusters", func() {
+			prepareCluster(`{
+				"enabled": true,
+				"hcp_namespace": "ocm-production-123-my-cluster",
+				"management_cluster": "hs-mc-1"
+			}`)
+			apiServer.AppendHandlers(managementCluster)
+
+			result := NewCommand().
+				ConfigString(config).
+				Args("cluster", "management-cluster", "my-cluster").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.OutLines()).To(Equal([]string{
+				"Cluster:             my-cluster (123)",
+				"Management cluster:  hs-mc-1",
+				"Service cluster:     hs-sc-1",
+				"HCP namespace:       ocm-production-123-my-cluster",
+				"Region:              us-east-1",
+				"Sector:              main",
+				"Status:              ready",
+			}))
+		})
+
+		It("Shows the management cluster if the fleet management API isn't accessible", func() {
+			prepareCluster(`{
+				"enabled": true,
+				"management_cluster": "hs-mc-1"
+			}`)
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusForbidden, `{
+					"kind": "Error",
+					"id": "403",
+					"reason": "Forbidden"
+				}`),
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Args("cluster", "mc", "my-cluster", "--json").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Warning: Can't retrieve management cluster 'hs-mc-1'",
+			))
+			Expect(result.OutString()).To(MatchJSON(`{
+				"cluster_id": "123",
+				"cluster_name": "my-cluster",
+				"management_cluster": "hs-mc-1"
+			}`))
+		})
+
+		It("Rejects clusters without hosted control plane", func() {
+			prepareCluster(`{}`)
+
+			result := NewCommand().
+				ConfigString(config).
+				Args("cluster", "management-cluster", "my-cluster").
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Cluster 'my-cluster' doesn't have a hosted control plane",
+			))
+		})
+	})
+
+	When("Listing the clusters hosted by a management cluster", func() {
+		It("Lists the clusters", func() {
+			apiServer.AppendHandlers(
+				managementCluster,
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+					VerifyFormKV(
+						"search",
+						"hypershift.enabled = 'true' and "+
+							"hypershift.management_cluster = 'hs-mc-1'",
+					),
+					RespondWithJSON(http.StatusOK, `{
+						"kind": "ClusterList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"kind": "Cluster",
+								"id": "123",
+								"name": "my-cluster",
+								"state": "ready",
+								"openshift_version": "4.12.5",
+								"hypershift": {
+									"enabled": true,
+									"hcp_namespace": "ocm-production-123-my-cluster",
+									"management_cluster": "hs-mc-1"
+								}
+							},
+							{
+								"kind": "Cluster",
+								"id": "124",
+								"name": "your-cluster",
+								"state": "installing",
+								"openshift_version": "4.13.0",
+								"hypershift": {
+									"enabled": true,
+									"management_cluster": "hs-mc-1"
+								}
+							}
+						]
+					}`),
+				),
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Args("fleet", "hosted-clusters", "hs-mc-1").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutLines()).To(Equal([]string{
+				"Management cluster 'hs-mc-1' of service cluster 'hs-sc-1' hosts 2 clusters",
+				"",
+				"ID   NAME          STATE       VERSION  HCP NAMESPACE",
+				"123  my-cluster    ready       4.12.5   ocm-production-123-my-cluster",
+				"124  your-cluster  installing  4.13.0   ",
+			}))
+		})
+
+		It("Fails if the management cluster doesn't exist", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "ManagementClusterList",
+					"page": 1,
+					"size": 0,
+					"total": 0,
+					"items": []
+				}`),
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Args("fleet", "hosted-clusters", "hs-mc-9").
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Management cluster 'hs-mc-9' doesn't exist",
+			))
+		})
+
+		It("Rejects invalid management cluster names", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("fleet", "hosted-clusters", "hs-mc-1' or name != '").
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("isn't valid"))
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,62 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+)
+
+var _ = Describe("Languages", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("Translates the help selected with the flag", func() {
+		result := NewCommand().
+			Args("--lang", "es", "--help").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring("Comandos disponibles:"))
+		Expect(result.OutString()).To(ContainSubstring("Iniciar sesión"))
+		Expect(result.OutString()).To(ContainSubstring(
+			`Use "ocm [comando] --help" para obtener más información sobre un comando.`,
+		))
+	})
+
+	It("Translates errors in the language of the environment", func() {
+		result := NewCommand().
+			Env("LANG", "es_ES.UTF-8").
+			Args("whoami").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("No ha iniciado sesión"))
+	})
+
+	It("Uses English for languages without catalog", func() {
+		result := NewCommand().
+			Env("LANG", "xx_XX.UTF-8").
+			Args("--help").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring("Available Commands:"))
+	})
+})
@@ -0,0 +1,249 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Identifiers from standard input", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	// respondWithCluster returns the handlers that the server needs to resolve a cluster key to
+	// the cluster with the given identifier:
+	respondWithCluster := func(id string) []http.HandlerFunc {
+		return []http.HandlerFunc{
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "sub-`+id+`",
+							"cluster_id": "`+id+`"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "`+id+`",
+					"name": "cluster-`+id+`",
+					"state": "ready"
+				}`,
+			),
+		}
+	}
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Hibernates the clusters read from the standard input", func() {
+		// Prepare the server:
+		apiServer.AppendHandlers(respondWithCluster("123")...)
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/clusters/123/hibernate"),
+				RespondWithJSON(http.StatusOK, `{}`),
+			),
+		)
+		apiServer.AppendHandlers(respondWithCluster("456")...)
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/clusters/456/hibernate"),
+				RespondWithJSON(http.StatusOK, `{}`),
+			),
+		)
+
+		// Run the command:
+		result := NewCommand().
+			ConfigString(config).
+			Args("hibernate", "cluster", "--ids-from-stdin").
+			InString("# Clusters to hibernate\n123 my-first-cluster\n\n456\n").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(6))
+	})
+
+	It("Continues with the rest of the clusters when one fails", func() {
+		// Prepare the server:
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 0,
+					"total": 0,
+					"items": []
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "ClusterList",
+					"page": 1,
+					"size": 0,
+					"total": 0,
+					"items": []
+				}`,
+			),
+		)
+		apiServer.AppendHandlers(respondWithCluster("456")...)
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/clusters/456/resume"),
+				RespondWithJSON(http.StatusOK, `{}`),
+			),
+		)
+
+		// Run the command:
+		result := NewCommand().
+			ConfigString(config).
+			Args("resume", "cluster", "--ids-from-stdin").
+			InString("missing\n456\n").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Failed to get cluster 'missing'"))
+		Expect(result.ErrString()).To(ContainSubstring("Failed to resume 1 of 2 clusters"))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(5))
+	})
+
+	It("Describes the clusters read from the standard input", func() {
+		// Prepare the server. The clusters are retrieved one at a time, so that the
+		// responses are returned in order:
+		apiServer.AppendHandlers(respondWithCluster("123")...)
+		apiServer.AppendHandlers(respondWithCluster("456")...)
+
+		// Run the command:
+		result := NewCommand().
+			ConfigString(config).
+			Args("describe", "cluster", "--ids-from-stdin", "--json", "--parallel", "1").
+			InString("123\n456\n").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring(`"cluster-123"`))
+		Expect(result.OutString()).To(ContainSubstring(`"cluster-456"`))
+	})
+
+	It("Deletes the objects read from the standard input", func() {
+		// Prepare the server:
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodDelete, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWith(
+					http.StatusNoContent,
+					nil,
+					http.Header{"Content-Type": []string{"application/json"}},
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodDelete, "/api/clusters_mgmt/v1/clusters/456"),
+				RespondWith(
+					http.StatusNoContent,
+					nil,
+					http.Header{"Content-Type": []string{"application/json"}},
+				),
+			),
+		)
+
+		// Run the command:
+		result := NewCommand().
+			ConfigString(config).
+			Args("delete", "cluster", "--ids-from-stdin").
+			InString("123\n456\n").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Rejects identifiers in both the command line and the standard input", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("hibernate", "cluster", "--ids-from-stdin", "123").
+			InString("456\n").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Option '--ids-from-stdin' can't be used with identifiers in the command line",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Fails if the standard input doesn't contain identifiers", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("describe", "cluster", "--ids-from-stdin").
+			InString("# Nothing here\n\n").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"No identifiers found in the standard input",
+		))
+	})
+})
@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Impersonation", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Sends the impersonation headers and warns about it", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyHeaderKV("Impersonate-User", "joe"),
+				VerifyHeaderKV("Impersonate-Account-Id", "123"),
+				RespondWithJSON(http.StatusOK, `{"kind": "ClusterList", "items": []}`),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"get",
+				"--impersonate-user", "joe",
+				"--impersonate-account-id", "123",
+				"/api/clusters_mgmt/v1/clusters",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchJSON(`{"kind": "ClusterList", "items": []}`))
+		Expect(result.ErrLines()).To(ConsistOf(
+			"WARNING: Impersonating user 'joe' and account '123', requests are " +
+				"processed on behalf of that user",
+		))
+	})
+
+	It("Doesn't send the impersonation headers without the options", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{"kind": "ClusterList", "items": []}`),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"get",
+				"/api/clusters_mgmt/v1/clusters",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		requests := apiServer.ReceivedRequests()
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Header.Get("Impersonate-User")).To(BeEmpty())
+		Expect(requests[0].Header.Get("Impersonate-Account-Id")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,233 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Import cluster", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+	var dir string
+
+	// respondWithImport returns the handlers that the server needs to import the cluster with
+	// the given machine pools:
+	respondWithImport := func(pools string) []http.HandlerFunc {
+		emptyList := RespondWithJSON(http.StatusOK, `{"kind": "List", "items": []}`)
+		return []http.HandlerFunc{
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "456",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster"
+				}`,
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "Cluster",
+						"id": "123",
+						"href": "/api/clusters_mgmt/v1/clusters/123",
+						"name": "my-cluster",
+						"status": {
+							"state": "ready"
+						}
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/machine_pools"),
+				RespondWithJSON(http.StatusOK, pools),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "IdentityProviderList",
+						"items": [
+							{
+								"kind": "IdentityProvider",
+								"id": "i1",
+								"name": "My GitHub",
+								"type": "GithubIdentityProvider"
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/ingresses"),
+				emptyList,
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/upgrade_policies",
+				),
+				emptyList,
+			),
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the directory for the manifests:
+		dir, err = os.MkdirTemp("", "ocm-import-*")
+		Expect(err).ToNot(HaveOccurred())
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+
+		// Remove the manifests:
+		err := os.RemoveAll(dir)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Writes the manifests and prunes the ones of deleted objects", func() {
+		// Import the cluster with two machine pools:
+		apiServer.AppendHandlers(respondWithImport(`{
+			"kind": "MachinePoolList",
+			"items": [
+				{
+					"kind": "MachinePool",
+					"id": "worker",
+					"replicas": 2
+				},
+				{
+					"kind": "MachinePool",
+					"id": "infra",
+					"replicas": 3
+				}
+			]
+		}`)...)
+		result := NewCommand().
+			ConfigString(config).
+			Args("import", "cluster", "my-cluster", "--dir", dir).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(ConsistOf(
+			"Manifest '"+filepath.Join(dir, "cluster.yaml")+"' created",
+			"Manifest '"+filepath.Join(dir, "machine_pools", "worker.yaml")+"' created",
+			"Manifest '"+filepath.Join(dir, "machine_pools", "infra.yaml")+"' created",
+			"Manifest '"+filepath.Join(dir, "identity_providers", "My_GitHub.yaml")+"' created",
+		))
+		data, err := os.ReadFile(filepath.Join(dir, "cluster.yaml"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("kind: Cluster\nid: \"123\"\nname: my-cluster\n"))
+
+		// Add a comment and import again after deleting one of the machine pools:
+		worker := filepath.Join(dir, "machine_pools", "worker.yaml")
+		err = os.WriteFile(
+			worker,
+			[]byte("kind: MachinePool\nid: worker\nreplicas: 2 # Keep small.\n"),
+			0644,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		apiServer.AppendHandlers(respondWithImport(`{
+			"kind": "MachinePoolList",
+			"items": [
+				{
+					"kind": "MachinePool",
+					"id": "worker",
+					"replicas": 4
+				}
+			]
+		}`)...)
+		result = NewCommand().
+			ConfigString(config).
+			Args("import", "cluster", "my-cluster", "--dir", dir, "--prune").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(ConsistOf(
+			"Manifest '"+worker+"' updated",
+			"Manifest '"+filepath.Join(dir, "machine_pools", "infra.yaml")+"' removed",
+		))
+		data, err = os.ReadFile(worker)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("kind: MachinePool\nid: worker\nreplicas: 4 # Keep small.\n"))
+	})
+})
@@ -0,0 +1,200 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Lint", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+	var tmpDir string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Create the specification files:
+		var err error
+		tmpDir, err = os.MkdirTemp("", "ocm-lint-*")
+		Expect(err).ToNot(HaveOccurred())
+		err = os.Mkdir(filepath.Join(tmpDir, "machine_pools"), 0700)
+		Expect(err).ToNot(HaveOccurred())
+		err = os.WriteFile(
+			filepath.Join(tmpDir, "cluster.yaml"),
+			[]byte(`
+name: mycluster
+region:
+  id: us-east-1
+version:
+  id: openshift-v4.10.3
+network:
+  machine_cidr: 10.0.0.0/16
+  service_cidr: 172.30.0.0/16
+  pod_cidr: 10.128.0.0/14
+`),
+			0600,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		err = os.WriteFile(
+			filepath.Join(tmpDir, "machine_pools", "workers.yaml"),
+			[]byte(`
+id: workers
+instance_type: m5.xlarge
+replicas: 3
+`),
+			0600,
+		)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+
+		// Remove the specification files:
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Checks a directory without contacting the API", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("lint", tmpDir).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(BeEmpty())
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Fails if there are errors", func() {
+		file := filepath.Join(tmpDir, "machine_pools", "workers.yaml")
+		err := os.WriteFile(file, []byte("id: workers\ninstance_type: m5.xlarge\n"), 0600)
+		Expect(err).ToNot(HaveOccurred())
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("lint", tmpDir).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.OutLines()).To(ConsistOf(
+			file + ": error: one of fields 'replicas' or 'autoscaling' is required",
+		))
+		Expect(result.ErrString()).To(ContainSubstring("Found 1 errors and 0 warnings"))
+	})
+
+	It("Writes a JUnit report", func() {
+		file := filepath.Join(tmpDir, "machine_pools", "workers.yaml")
+		err := os.WriteFile(file, []byte("id: workers\ninstance_type: m5.xlarge\n"), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		junitFile := filepath.Join(tmpDir, "lint.xml")
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("lint", "--junit", junitFile, tmpDir).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		data, err := os.ReadFile(junitFile)
+		Expect(err).ToNot(HaveOccurred())
+		report := string(data)
+		Expect(report).To(ContainSubstring(
+			`<testsuite name="ocm lint" tests="2" failures="1" errors="0" skipped="0"`,
+		))
+		Expect(report).To(MatchRegexp(
+			`<testcase classname="lint" name="%s" time="[0-9.]+"></testcase>`,
+			filepath.Join(tmpDir, "cluster.yaml"),
+		))
+		Expect(report).To(ContainSubstring(
+			`<failure message="Found 1 errors" type="failure">` + file +
+				`: error: one of fields &#39;replicas&#39; or &#39;autoscaling&#39; is required` +
+				`</failure>`,
+		))
+	})
+
+	It("Checks the versions when contacting the API", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/versions"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "VersionList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "Version",
+								"id": "openshift-v4.11.0",
+								"enabled": true
+							}
+						]
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("lint", "--online", tmpDir).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.OutLines()).To(ConsistOf(
+			filepath.Join(tmpDir, "cluster.yaml") +
+				": error: version 'openshift-v4.10.3' isn't available",
+		))
+	})
+})
@@ -19,6 +19,7 @@ package tests
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"    // nolint
@@ -226,5 +227,204 @@ var _ = Describe("List clusters", func() {
 				`^\s*123\s+e30bac0b-b337-47d7-a378-2c302b4c868a\s+my_cluster\s*$`,
 			))
 		})
+
+		It("Requests only the given fields and displays them", func() {
+			// Prepare the server:
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyFormKV("fields", "id,name"),
+					RespondWithJSON(
+						http.StatusOK,
+						`{
+							"kind": "ClusterList",
+							"page": 1,
+							"size": 1,
+							"total": 1,
+							"items": [
+								{
+									"kind": "Cluster",
+									"id": "123",
+									"name": "my_cluster"
+								}
+							]
+						}`,
+					),
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"list", "clusters",
+					"--fields", "id, name",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			lines := result.OutLines()
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(MatchRegexp(`^\s*ID\s+NAME\s*$`))
+			Expect(lines[1]).To(MatchRegexp(`^\s*123\s+my_cluster\s*$`))
+		})
+
+		It("Groups clusters by version", func() {
+			// Prepare the server:
+			apiServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "ClusterList",
+						"page": 1,
+						"size": 3,
+						"total": 3,
+						"items": [
+							{
+								"kind": "Cluster",
+								"id": "123",
+								"openshift_version": "4.7"
+							},
+							{
+								"kind": "Cluster",
+								"id": "456",
+								"openshift_version": "4.8"
+							},
+							{
+								"kind": "Cluster",
+								"id": "789",
+								"openshift_version": "4.8"
+							}
+						]
+					}`,
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"list", "clusters",
+					"--group-by", "version",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			lines := result.OutLines()
+			Expect(lines).To(HaveLen(3))
+			Expect(lines[0]).To(MatchRegexp(`^\s*OPENSHIFT_VERSION\s+COUNT\s*$`))
+			Expect(lines[1]).To(MatchRegexp(`^\s*4\.8\s+2\s*$`))
+			Expect(lines[2]).To(MatchRegexp(`^\s*4\.7\s+1\s*$`))
+		})
+
+		It("Rejects invalid grouping field", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"list", "clusters",
+					"--group-by", "junk",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("Invalid value 'junk'"))
+		})
+
+		It("Filters clusters by creation date", func() {
+			// Prepare the server:
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+					VerifyFormKV(
+						"search",
+						"(creation_timestamp >= '2022-01-01T00:00:00Z') and "+
+							"(creation_timestamp < '2022-02-01T10:00:00Z')",
+					),
+					RespondWithJSON(http.StatusOK, `{
+						"kind": "ClusterList",
+						"page": 1,
+						"size": 0,
+						"total": 0,
+						"items": []
+					}`),
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"list", "clusters",
+					"--created-after", "2022-01-01",
+					"--created-before", "2022-02-01T10:00:00Z",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+		})
+
+		It("Filters clusters by relative creation date", func() {
+			// Prepare the server:
+			var search string
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					func(w http.ResponseWriter, r *http.Request) {
+						search = r.URL.Query().Get("search")
+					},
+					RespondWithJSON(http.StatusOK, `{
+						"kind": "ClusterList",
+						"page": 1,
+						"size": 0,
+						"total": 0,
+						"items": []
+					}`),
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"list", "clusters",
+					"--created-after", "7d",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+
+			// Check that the time sent is seven days before now:
+			Expect(search).To(HavePrefix("creation_timestamp >= '"))
+			value := strings.TrimSuffix(strings.TrimPrefix(search, "creation_timestamp >= '"), "'")
+			after, err := time.Parse(time.RFC3339, value)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(after).To(BeTemporally("~", time.Now().AddDate(0, 0, -7), time.Minute))
+		})
+
+		It("Rejects invalid creation date", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"list", "clusters",
+					"--created-after", "junk",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Invalid value for option '--created-after'",
+			))
+		})
+
+		It("Rejects empty creation window", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"list", "clusters",
+					"--created-after", "1d",
+					"--created-before", "2d",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"must be earlier than the value of option '--created-before'",
+			))
+		})
 	})
 })
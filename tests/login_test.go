@@ -18,6 +18,9 @@ package tests
 
 import (
 	"context"
+	"net/http"
+	"os"
+	"path/filepath"
 	"time"
 
 	sdk "github.com/openshift-online/ocm-sdk-go"
@@ -86,6 +89,42 @@ var _ = Describe("Login", func() {
 		})
 	})
 
+	When("Using offline token from the standard input", func() {
+		It("Creates the configuration file", func() {
+			// Create the tokens:
+			accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+			// Run the command:
+			result := NewCommand().
+				Args(
+					"login",
+					"--token-file", "-",
+					"--token-url", ssoServer.URL(),
+				).
+				InString(accessToken + "\r\n").
+				Run(ctx)
+
+			// Check the content of the configuration file:
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.ConfigString()).To(ContainSubstring(
+				`"access_token": "` + accessToken + `"`,
+			))
+		})
+
+		It("Rejects using also the token option", func() {
+			result := NewCommand().
+				Args(
+					"login",
+					"--token", "my-token",
+					"--token-file", "-",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("mutually exclusive"))
+		})
+	})
+
 	When("Using client credentials grant", func() {
 		It("Creates the configuration file", func() {
 			// Create the token:
@@ -182,4 +221,155 @@ var _ = Describe("Login", func() {
 			))
 		})
 	})
+
+	When("Using device code", func() {
+		It("Creates the configuration file", func() {
+			// Create the tokens:
+			accessToken := MakeTokenString("Bearer", 15*time.Minute)
+			refreshToken := MakeTokenString("Refresh", 10*time.Hour)
+
+			// Prepare the server:
+			ssoServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodPost, "/auth/device"),
+					RespondWithJSON(http.StatusOK, `{
+						"device_code": "my-device-code",
+						"user_code": "ABCD-EFGH",
+						"verification_uri": "https://sso.example.com/device",
+						"verification_uri_complete": "https://sso.example.com/device?code=ABCD-EFGH",
+						"expires_in": 600,
+						"interval": 0
+					}`),
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodPost, "/token"),
+					VerifyFormKV("device_code", "my-device-code"),
+					RespondWithJSON(http.StatusBadRequest, `{
+						"error": "authorization_pending"
+					}`),
+				),
+				CombineHandlers(
+					VerifyRequest(http.MethodPost, "/token"),
+					VerifyFormKV("device_code", "my-device-code"),
+					RespondWithAccessAndRefreshTokens(accessToken, refreshToken),
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				Args(
+					"login",
+					"--use-device-code",
+					"--token-url", ssoServer.URL()+"/token",
+				).
+				Run(ctx)
+
+			// Check the content of the configuration file:
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(Equal(
+				"To log in open 'https://sso.example.com/device?code=ABCD-EFGH' in a " +
+					"browser and check that the code is 'ABCD-EFGH'.\n",
+			))
+			Expect(result.ConfigString()).To(ContainSubstring(
+				`"access_token": "` + accessToken + `"`,
+			))
+			Expect(result.ConfigString()).To(ContainSubstring(
+				`"refresh_token": "` + refreshToken + `"`,
+			))
+			Expect(result.ConfigString()).To(ContainSubstring(
+				`"auth_provider": "device-code"`,
+			))
+		})
+
+		It("Rejects using also the token option", func() {
+			result := NewCommand().
+				Args(
+					"login",
+					"--use-device-code",
+					"--token", MakeTokenString("Bearer", 15*time.Minute),
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Option '--use-device-code' can't be used with other credentials",
+			))
+			Expect(ssoServer.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+
+	When("Using a credential helper", func() {
+		var tmpDir string
+		var helper string
+		var accessToken string
+
+		BeforeEach(func() {
+			// Create the helper:
+			var err error
+			tmpDir, err = os.MkdirTemp("", "ocm-helper-*")
+			Expect(err).ToNot(HaveOccurred())
+			accessToken = MakeTokenString("Bearer", 15*time.Minute)
+			helper = filepath.Join(tmpDir, "helper.sh")
+			err = os.WriteFile(
+				helper,
+				[]byte("#!/bin/sh\necho '{\"access_token\": \""+accessToken+"\"}'\n"),
+				0700, // #nosec G306
+			)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("Saves the command but not the tokens", func() {
+			result := NewCommand().
+				Args(
+					"login",
+					"--url", "https://api.example.com",
+					"--auth-command", helper+" --verbose",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.ConfigString()).To(ContainSubstring(`"auth_provider": "exec"`))
+			Expect(result.ConfigString()).To(ContainSubstring(
+				`"auth_command": "` + helper + ` --verbose"`,
+			))
+			Expect(result.ConfigString()).ToNot(ContainSubstring("access_token"))
+
+			// Other commands should use the token returned by the helper:
+			result = NewCommand().
+				ConfigString(result.ConfigString()).
+				Args("token").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(Equal(accessToken + "\n"))
+		})
+
+		It("Fails if the helper fails", func() {
+			result := NewCommand().
+				Args(
+					"login",
+					"--url", "https://api.example.com",
+					"--auth-command", filepath.Join(tmpDir, "missing.sh"),
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("Credential helper"))
+		})
+
+		It("Rejects using also the token option", func() {
+			result := NewCommand().
+				Args(
+					"login",
+					"--auth-command", helper,
+					"--token", accessToken,
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Option '--auth-command' can't be used with other credentials",
+			))
+		})
+	})
 })
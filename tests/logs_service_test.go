@@ -0,0 +1,311 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Service logs", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// prepareCluster configures the API server to return the cluster used by the tests.
+	prepareCluster := func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"external_id": "456",
+					"subscription": {
+						"kind": "SubscriptionLink",
+						"id": "111"
+					}
+				}`,
+			),
+		)
+	}
+
+	It("Posts a service log rendered from a builtin template", func() {
+		prepareCluster()
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/service_logs/v1/cluster_logs"),
+				VerifyJQ(`.cluster_id`, "123"),
+				VerifyJQ(`.cluster_uuid`, "456"),
+				VerifyJQ(`.subscription_id`, "111"),
+				VerifyJQ(`.severity`, "Warning"),
+				VerifyJQ(`.service_name`, "SREManualAction"),
+				VerifyJQ(`.summary`, "Incident in progress: Console down"),
+				VerifyJQ(
+					`.description`,
+					"Red Hat SRE is investigating an incident affecting cluster "+
+						"'my-cluster'. Console down. We will send the next update "+
+						"within one hour.",
+				),
+				RespondWithJSON(
+					http.StatusCreated,
+					`{
+						"kind": "ClusterLog",
+						"id": "789"
+					}`,
+				),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"logs", "service", "post",
+				"--template", "incident-start",
+				"--var", "cluster=my-cluster",
+				"--var", "impact=Console down",
+			).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal(
+			"Posted service log '789' to cluster 'my-cluster'\n",
+		))
+	})
+
+	It("Shows the service log without posting it in dry run mode", func() {
+		prepareCluster()
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"logs", "service", "post",
+				"--template", "incident-resolved",
+				"--var", "cluster=my-cluster",
+				"--var", "resolution=The router was restarted",
+				"--dry-run",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		var entry map[string]interface{}
+		err := json.Unmarshal([]byte(result.OutString()), &entry)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entry).To(MatchJQ(`.severity`, "Info"))
+		Expect(entry).To(MatchJQ(`.summary`, "Incident resolved"))
+		Expect(entry).To(MatchJQ(
+			`.description`,
+			"The incident affecting cluster 'my-cluster' has been resolved. "+
+				"The router was restarted.",
+		))
+	})
+
+	It("Fails if a mandatory variable is missing", func() {
+		prepareCluster()
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"logs", "service", "post",
+				"--template", "incident-start",
+				"--var", "cluster=my-cluster",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Template 'incident-start' requires variable 'impact'",
+		))
+	})
+
+	It("Requires the cluster variable", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"logs", "service", "post",
+				"--template", "incident-start",
+				"--var", "impact=Console down",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Variable 'cluster' is mandatory"))
+	})
+
+	It("Rejects templates that don't exist", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"logs", "service", "post",
+				"--template", "junk",
+				"--var", "cluster=my-cluster",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Template 'junk' doesn't exist, available templates are incident-resolved, " +
+				"incident-start, incident-update, maintenance-scheduled",
+		))
+	})
+
+	When("The user has templates", func() {
+		var tmpDir string
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = os.MkdirTemp("", "ocm-templates-*")
+			Expect(err).ToNot(HaveOccurred())
+			err = os.WriteFile(filepath.Join(tmpDir, "node-replaced.yaml"), []byte(`
+description: Notify that a node has been replaced
+variables:
+- name: node
+  description: Name of the node
+log:
+  severity: Info
+  service_name: SREManualAction
+  summary: "Node {{ .node }} replaced"
+  description: "Node {{ .node }} of cluster {{ .cluster_id }} has been replaced."
+  internal_only: true
+`), 0600)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("Lists user and builtin templates", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Env("OCM_SERVICE_LOG_TEMPLATES", tmpDir).
+				Args("logs", "service", "templates").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			lines := result.OutLines()
+			Expect(lines).To(HaveLen(6))
+			Expect(lines[0]).To(MatchRegexp(`^NAME\s+SEVERITY\s+SOURCE\s+DESCRIPTION$`))
+			Expect(lines[1]).To(MatchRegexp(`^incident-resolved\s+Info\s+builtin\s+`))
+			Expect(lines[5]).To(MatchRegexp(
+				`^node-replaced\s+Info\s+` + tmpDir + `\s+Notify that a node`,
+			))
+		})
+
+		It("Shows the variables of a template", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Env("OCM_SERVICE_LOG_TEMPLATES", tmpDir).
+				Args("logs", "service", "templates", "node-replaced").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			lines := result.OutLines()
+			Expect(lines[0]).To(MatchRegexp(`^Name:\s+node-replaced$`))
+			Expect(lines).To(ContainElement(MatchRegexp(`^Internal only:\s+true$`)))
+			Expect(lines).To(ContainElement(MatchRegexp(`^VARIABLE\s+DEFAULT\s+DESCRIPTION$`)))
+			Expect(lines).To(ContainElement(MatchRegexp(`^cluster\s+-\s+Name`)))
+			Expect(lines).To(ContainElement(MatchRegexp(`^node\s+-\s+Name of the node$`)))
+		})
+
+		It("Posts a service log rendered from a user template", func() {
+			prepareCluster()
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodPost, "/api/service_logs/v1/cluster_logs"),
+					VerifyJQ(`.summary`, "Node worker-1 replaced"),
+					VerifyJQ(`.description`, "Node worker-1 of cluster 123 has been replaced."),
+					VerifyJQ(`.internal_only`, true),
+					RespondWithJSON(
+						http.StatusCreated,
+						`{
+							"kind": "ClusterLog",
+							"id": "789"
+						}`,
+					),
+				),
+			)
+			result := NewCommand().
+				ConfigString(config).
+				Env("OCM_SERVICE_LOG_TEMPLATES", tmpDir).
+				Args(
+					"logs", "service", "post",
+					"--template", "node-replaced",
+					"--var", "cluster=my-cluster",
+					"--var", "node=worker-1",
+				).
+				Run(ctx)
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.ExitCode()).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,287 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Machine pool IMDSv2", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// prepareCluster prepares the server so that the cluster is found, running in the given
+	// cloud provider:
+	prepareCluster := func(provider string) {
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/accounts_mgmt/v1/subscriptions",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"state": "ready",
+					"cloud_provider": {
+						"id": "`+provider+`"
+					},
+					"ccs": {
+						"enabled": true
+					},
+					"nodes": {
+						"compute": 3,
+						"compute_machine_type": {
+							"id": "m5.xlarge"
+						}
+					},
+					"aws": {
+						"ec2_metadata_http_tokens": "required"
+					}
+				}`,
+			),
+		)
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/machine_types",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "MachineTypeList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "MachineType",
+							"id": "m5.xlarge",
+							"name": "m5.xlarge - General Purpose"
+						}
+					]
+				}`,
+			),
+		)
+	}
+
+	It("Creates a machine pool that requires IMDSv2", func() {
+		prepareCluster("aws")
+		apiServer.RouteToHandler(
+			http.MethodPost,
+			"/api/clusters_mgmt/v1/clusters/123/machine_pools",
+			CombineHandlers(
+				VerifyJQ(`.id`, "mp1"),
+				VerifyJQ(`.instance_type`, "m5.xlarge"),
+				VerifyJQ(`.replicas`, 3.0),
+				VerifyJQ(`.aws.ec2_metadata_http_tokens`, "required"),
+				RespondWithJSON(http.StatusCreated, `{"kind": "MachinePool", "id": "mp1"}`),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"create", "machinepool",
+				"--cluster", "my-cluster",
+				"--instance-type", "m5.xlarge",
+				"--replicas", "3",
+				"--ec2-metadata-http-tokens", "required",
+				"mp1",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+	})
+
+	It("Rejects invalid values", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"create", "machinepool",
+				"--cluster", "my-cluster",
+				"--instance-type", "m5.xlarge",
+				"--replicas", "3",
+				"--ec2-metadata-http-tokens", "always",
+				"mp1",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"A valid --ec2-metadata-http-tokens must be specified",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Rejects the option for clusters that aren't in AWS", func() {
+		prepareCluster("gcp")
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"create", "machinepool",
+				"--cluster", "my-cluster",
+				"--instance-type", "m5.xlarge",
+				"--replicas", "3",
+				"--ec2-metadata-http-tokens", "required",
+				"mp1",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Option '--ec2-metadata-http-tokens' can only be used with AWS clusters",
+		))
+	})
+
+	It("Changes the setting of an existing machine pool", func() {
+		prepareCluster("aws")
+		apiServer.RouteToHandler(
+			http.MethodPatch,
+			"/api/clusters_mgmt/v1/clusters/123/machine_pools/mp1",
+			CombineHandlers(
+				VerifyJQ(`.id`, "mp1"),
+				VerifyJQ(`.aws.ec2_metadata_http_tokens`, "optional"),
+				RespondWithJSON(http.StatusOK, `{"kind": "MachinePool", "id": "mp1"}`),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"edit", "machinepool",
+				"--cluster", "my-cluster",
+				"--ec2-metadata-http-tokens", "optional",
+				"mp1",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+	})
+
+	It("Doesn't change the setting of the default machine pool", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"edit", "machinepool",
+				"--cluster", "my-cluster",
+				"--ec2-metadata-http-tokens", "optional",
+				"default",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"can't be used with the default machine pool",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Shows the setting in the list of machine pools", func() {
+		prepareCluster("aws")
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123/machine_pools",
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "MachinePoolList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "MachinePool",
+							"id": "mp1",
+							"replicas": 2,
+							"instance_type": "m5.xlarge"
+						}
+					]
+				}`,
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args("list", "machinepools", "--cluster", "my-cluster").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(HaveSuffix("EC2 METADATA HTTP TOKENS"))
+		Expect(lines[1]).To(MatchRegexp(`^default\s.*\srequired$`))
+		Expect(lines[2]).To(MatchRegexp(`^mp1\s.*\soptional$`))
+	})
+})
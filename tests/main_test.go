@@ -148,6 +148,12 @@ func (r *CommandRunner) Run(ctx context.Context) *CommandResult {
 		envMap[name] = value
 	}
 
+	// Remove the variables that select the language, so that messages are in English unless the
+	// test explicitly selects other language:
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		delete(envMap, name)
+	}
+
 	// Add the environment variables:
 	for name, value := range r.env {
 		envMap[name] = value
@@ -249,6 +255,21 @@ func (r *CommandResult) ErrString() string {
 	return string(r.err)
 }
 
+// ErrLines returns the standard error output of the CLI command as an array of strings.
+func (r *CommandResult) ErrLines() []string {
+	// Split the output into lines:
+	lines := strings.Split(string(r.err), "\n")
+
+	// If there is a blank line at the end remove it:
+	count := len(lines)
+	if count > 0 && lines[count-1] == "" {
+		lines = lines[0 : count-1]
+	}
+
+	// Return the lines:
+	return lines
+}
+
 // ExitCode returns the exit code of the CLI command.
 func (r *CommandResult) ExitCode() int {
 	return r.exitCode
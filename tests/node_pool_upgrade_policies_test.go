@@ -0,0 +1,318 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Node pool upgrade policies", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server so that the cluster is found:
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "Subscription",
+							"id": "111",
+							"cluster_id": "123"
+						}
+					]
+				}`,
+			),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"state": "ready"
+				}`,
+			),
+		)
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Lists the upgrade policies of all the node pools", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123/node_pools"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "NodePoolList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"kind": "NodePool",
+								"id": "workers"
+							},
+							{
+								"kind": "NodePool",
+								"id": "gpu"
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/node_pools/workers/upgrade_policies",
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "NodePoolUpgradePolicyList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "NodePoolUpgradePolicy",
+								"id": "456",
+								"node_pool_id": "workers",
+								"schedule_type": "manual",
+								"upgrade_type": "NodePool",
+								"version": "4.14.2",
+								"next_run": "2026-10-20T10:00:00Z",
+								"state": {
+									"value": "scheduled"
+								}
+							}
+						]
+					}`,
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/node_pools/gpu/upgrade_policies",
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "NodePoolUpgradePolicyList",
+						"page": 1,
+						"size": 0,
+						"total": 0,
+						"items": []
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("list", "upgradepolicies", "--cluster", "my-cluster", "--node-pools").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[0]).To(MatchRegexp(
+			`^ID\s+NODE POOL\s+SCHEDULE TYPE\s+UPGRADE VERSION\s+STATE\s+NEXT RUN\s*$`,
+		))
+		Expect(lines[1]).To(MatchRegexp(
+			`^456\s+workers\s+manual\s+4\.14\.2\s+scheduled\s+2026-10-20 10:00:00 \+0000 UTC\s*$`,
+		))
+	})
+
+	It("Deletes an upgrade policy of a node pool", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodDelete,
+					"/api/clusters_mgmt/v1/clusters/123/node_pools/workers/upgrade_policies/456",
+				),
+				RespondWith(
+					http.StatusNoContent,
+					nil,
+					http.Header{"Content-Type": []string{"application/json"}},
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"delete", "upgradepolicy",
+				"--cluster", "my-cluster",
+				"--node-pool", "workers",
+				"456",
+			).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring(
+			"Deleted upgrade policy '456' on node pool 'workers' of cluster 'my-cluster'",
+		))
+	})
+	It("Lists only the pending upgrade policies", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/node_pools/workers/upgrade_policies",
+				),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "NodePoolUpgradePolicyList",
+						"page": 1,
+						"size": 2,
+						"total": 2,
+						"items": [
+							{
+								"kind": "NodePoolUpgradePolicy",
+								"id": "455",
+								"node_pool_id": "workers",
+								"schedule_type": "manual",
+								"version": "4.14.1",
+								"state": {
+									"value": "completed"
+								}
+							},
+							{
+								"kind": "NodePoolUpgradePolicy",
+								"id": "456",
+								"node_pool_id": "workers",
+								"schedule_type": "manual",
+								"version": "4.14.2",
+								"state": {
+									"value": "scheduled"
+								}
+							}
+						]
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"list", "upgradepolicies",
+				"--cluster", "my-cluster",
+				"--node-pool", "workers",
+				"--pending",
+			).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[1]).To(MatchRegexp(`^456\s+workers\s+manual\s+4\.14\.2\s+scheduled\s*$`))
+	})
+
+	It("Rejects '--pending' without node pools", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("list", "upgradepolicies", "--cluster", "my-cluster", "--pending").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Option '--pending' requires '--node-pool' or '--node-pools'",
+		))
+	})
+
+	It("Fails to create an upgrade policy for a node pool that doesn't exist", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters/123/node_pools/workers",
+				),
+				RespondWithJSON(
+					http.StatusNotFound,
+					`{
+						"kind": "Error",
+						"id": "404",
+						"code": "CLUSTERS-MGMT-404",
+						"reason": "Node pool 'workers' not found"
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"create", "upgradepolicy",
+				"--cluster", "my-cluster",
+				"--node-pool", "workers",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Node pool 'workers' not found"))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(3))
+	})
+})
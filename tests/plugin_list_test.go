@@ -18,6 +18,7 @@ package tests
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -139,4 +140,70 @@ var _ = Describe("Plugin list", func() {
 			`^\s*ocm-your-plugin\s*$`,
 		))
 	})
+
+	It("Warns about plugins that will never be called", func() {
+		// Create another directory containing a plugin with the same name than one of the
+		// plugins of the first directory:
+		other, err := ioutil.TempDir("", "ocm-test-*.d")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(other)
+		names := map[string]string{
+			"ocm-my-plugin": other,
+			"ocm-version":   tmp,
+		}
+		for name, dir := range names {
+			path := filepath.Join(dir, name)
+			if runtime.GOOS == "windows" {
+				path += ".exe"
+			}
+			err = ioutil.WriteFile(path, []byte{}, 0700)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		// Run the command:
+		result := NewCommand().
+			Env("PATH", tmp+string(os.PathListSeparator)+other).
+			Args("plugin", "list").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutLines()).To(HaveLen(5))
+		Expect(result.ErrLines()).To(ConsistOf(
+			fmt.Sprintf(
+				"Warning: plugin 'ocm-version' in '%s' is overshadowed by the built-in "+
+					"'ocm version' command",
+				tmp,
+			),
+			fmt.Sprintf(
+				"Warning: plugin 'ocm-my-plugin' in '%s' is shadowed by the plugin "+
+					"with the same name in '%s'",
+				other, tmp,
+			),
+		))
+	})
+
+	It("Calls the plugin with the configuration file and context", func() {
+		if runtime.GOOS == "windows" {
+			Skip("Plugin scripts aren't supported in Windows")
+		}
+		path := filepath.Join(tmp, "ocm-my_tool")
+		err := ioutil.WriteFile(path, []byte(
+			"#!/bin/sh\n"+
+				"echo \"args: $*\"\n"+
+				"echo \"config: $OCM_CONFIG\"\n"+
+				"echo \"context: $OCM_CONTEXT\"\n",
+		), 0700) // #nosec G306
+		Expect(err).ToNot(HaveOccurred())
+
+		result := NewCommand().
+			Env("PATH", tmp+string(os.PathListSeparator)+os.Getenv("PATH")).
+			Args("my-tool", "--flag", "value").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(Equal("args: --flag value"))
+		Expect(lines[1]).To(MatchRegexp(`^config: .+\.ocm\.json$`))
+		Expect(lines[2]).To(Equal("context: default"))
+	})
 })
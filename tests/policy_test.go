@@ -0,0 +1,154 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Policies", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+	var policyDir string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Create the policies:
+		var err error
+		policyDir, err = os.MkdirTemp("", "ocm-policies-*")
+		Expect(err).ToNot(HaveOccurred())
+		err = os.WriteFile(
+			filepath.Join(policyDir, "regions.yaml"),
+			[]byte(`
+name: approved-regions
+paths:
+- /api/clusters_mgmt/v1/clusters
+condition: .body.region.id | IN("us-east-1", "eu-west-1") | not
+message: Clusters must be created in approved regions
+---
+name: expiration
+paths:
+- /api/clusters_mgmt/v1/clusters
+condition: .body.expiration_timestamp == null
+action: warn
+message: Clusters should have an expiration time
+`),
+			0600,
+		)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+
+		// Remove the policies:
+		os.RemoveAll(policyDir)
+	})
+
+	It("Blocks requests that violate a policy", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_POLICY_DIR", policyDir).
+			Args("post", "/api/clusters_mgmt/v1/clusters").
+			InString(`{"name": "my-cluster", "region": {"id": "ap-south-1"}}`).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Request denied by policy 'approved-regions': " +
+				"Clusters must be created in approved regions",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Sends requests that only trigger warnings", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/clusters_mgmt/v1/clusters"),
+				VerifyJQ(`.name`, "my-cluster"),
+				RespondWithJSON(http.StatusCreated, `{"kind": "Cluster", "id": "123"}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_POLICY_DIR", policyDir).
+			Args("post", "/api/clusters_mgmt/v1/clusters").
+			InString(`{"name": "my-cluster", "region": {"id": "us-east-1"}}`).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(Equal(
+			"WARNING: Request violates policy 'expiration': " +
+				"Clusters should have an expiration time\n",
+		))
+		Expect(result.OutString()).To(ContainSubstring(`"id": "123"`))
+	})
+
+	It("Doesn't check requests that don't modify anything", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{"kind": "ClusterList", "items": []}`),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_POLICY_DIR", policyDir).
+			Args("get", "/api/clusters_mgmt/v1/clusters").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+	})
+})
@@ -104,6 +104,42 @@ var _ = Describe("Post", func() {
 			Expect(result.ErrString()).To(BeEmpty())
 		})
 
+		It("Converts YAML bodies to JSON", func() {
+			// Prepare the server:
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyJSON(`{
+						"name": "my-cluster",
+						"nodes": {
+							"compute": 3
+						},
+						"labels": ["a", "b"]
+					}`),
+					RespondWithJSON(http.StatusOK, `{}`),
+				),
+			)
+
+			// Run the command:
+			result := NewCommand().
+				ConfigString(config).
+				Args("post", "/api/my_service/v1/my_object").
+				InString("name: my-cluster\nnodes:\n  compute: 3\nlabels:\n- a\n- b\n").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+		})
+
+		It("Rejects YAML bodies with multiple documents", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args("post", "/api/my_service/v1/my_object").
+				InString("name: first\n---\nname: second\n").
+				Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("multiple YAML documents"))
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		})
+
 		It("Honours the --parameter flag", func() {
 			// Prepare the server:
 			apiServer.AppendHandlers(
@@ -169,5 +205,84 @@ var _ = Describe("Post", func() {
 			Expect(result.ExitCode()).To(BeZero())
 			Expect(result.ErrString()).To(BeEmpty())
 		})
+
+		Context("With the --validate flag", func() {
+			spec := `{
+				"openapi": "3.0.0",
+				"paths": {
+					"/api/my_service/v1/my_objects": {
+						"post": {
+							"requestBody": {
+								"content": {
+									"application/json": {
+										"schema": {
+											"type": "object",
+											"required": ["name"],
+											"properties": {
+												"name": {
+													"type": "string"
+												},
+												"size": {
+													"type": "integer"
+												}
+											}
+										}
+									}
+								}
+							}
+						}
+					}
+				}
+			}`
+
+			It("Sends valid bodies", func() {
+				// Prepare the server:
+				apiServer.AppendHandlers(
+					CombineHandlers(
+						VerifyRequest(http.MethodGet, "/api/my_service/v1/openapi"),
+						RespondWithJSON(http.StatusOK, spec),
+					),
+					CombineHandlers(
+						VerifyRequest(http.MethodPost, "/api/my_service/v1/my_objects"),
+						VerifyJSON(`{"name": "my-object", "size": 2}`),
+						RespondWithJSON(http.StatusOK, `{}`),
+					),
+				)
+
+				// Run the command:
+				result := NewCommand().
+					ConfigString(config).
+					Args("post", "--validate", "/api/my_service/v1/my_objects").
+					InString(`{"name": "my-object", "size": 2}`).
+					Run(ctx)
+				Expect(result.ExitCode()).To(BeZero())
+				Expect(result.ErrString()).To(BeEmpty())
+			})
+
+			It("Doesn't send invalid bodies", func() {
+				// Prepare the server:
+				apiServer.AppendHandlers(
+					CombineHandlers(
+						VerifyRequest(http.MethodGet, "/api/my_service/v1/openapi"),
+						RespondWithJSON(http.StatusOK, spec),
+					),
+				)
+
+				// Run the command:
+				result := NewCommand().
+					ConfigString(config).
+					Args("post", "--validate", "/api/my_service/v1/my_objects").
+					InString(`{"nmae": "my-object", "size": "2"}`).
+					Run(ctx)
+				Expect(result.ExitCode()).ToNot(BeZero())
+				Expect(result.ErrString()).To(Equal(
+					"$: field 'name' is required\n" +
+						"$: unknown field 'nmae'\n" +
+						"$.size: expected an integer but got a string\n" +
+						"Error: Body doesn't match the OpenAPI specification\n",
+				))
+				Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
 	})
 })
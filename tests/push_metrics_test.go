@@ -0,0 +1,253 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Push metrics", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// writeExport writes the export file used by the tests and returns its path.
+	writeExport := func() string {
+		tmp, err := os.MkdirTemp("", "ocm-test-*.d")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(os.RemoveAll, tmp)
+		file := filepath.Join(tmp, "clusters.yaml")
+		err = os.WriteFile(file, []byte(`
+clusters:
+- cluster_id: 11111111-1111-1111-1111-111111111111
+  display_name: lab
+  openshift_version: 4.10.3
+  nodes:
+    master: 3
+    compute: 2
+  sockets: 4
+  system_units: Sockets
+- cluster_id: 22222222-2222-2222-2222-222222222222
+  display_name: edge
+  cpus: 16
+`), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		return file
+	}
+
+	// respondWithSubscriptions responds with a list containing the given subscriptions.
+	respondWithSubscriptions := func(items string) http.HandlerFunc {
+		return RespondWithJSON(http.StatusOK, `{
+			"kind": "SubscriptionList",
+			"page": 1,
+			"size": 1,
+			"total": 1,
+			"items": [`+items+`]
+		}`)
+	}
+
+	It("Registers new clusters and updates existing ones", func() {
+		apiServer.AppendHandlers(
+			// The first cluster isn't registered yet:
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/subscriptions"),
+				VerifyFormKV(
+					"search",
+					"external_cluster_id = '11111111-1111-1111-1111-111111111111'",
+				),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 0,
+					"total": 0,
+					"items": []
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodPost, "/api/accounts_mgmt/v1/subscriptions"),
+				VerifyJQ(`.cluster_uuid`, "11111111-1111-1111-1111-111111111111"),
+				VerifyJQ(`.display_name`, "lab"),
+				VerifyJQ(`.plan_id`, "OCP"),
+				VerifyJQ(`.status`, "Disconnected"),
+				RespondWithJSON(http.StatusCreated, `{
+					"kind": "Subscription",
+					"id": "sub1",
+					"status": "Disconnected"
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodPatch, "/api/accounts_mgmt/v1/subscriptions/sub1"),
+				VerifyJQ(`.socket_total`, 4.0),
+				VerifyJQ(`.system_units`, "Sockets"),
+				VerifyJQ(`.metrics[0].openshift_version`, "4.10.3"),
+				VerifyJQ(`.metrics[0].nodes.compute`, 2.0),
+				RespondWithJSON(http.StatusOK, `{}`),
+			),
+
+			// The second cluster is already registered:
+			respondWithSubscriptions(`{
+				"kind": "Subscription",
+				"id": "sub2",
+				"status": "Disconnected"
+			}`),
+			CombineHandlers(
+				VerifyRequest(http.MethodPatch, "/api/accounts_mgmt/v1/subscriptions/sub2"),
+				VerifyJQ(`.cpu_total`, 16.0),
+				VerifyJQ(`.display_name`, "edge"),
+				RespondWithJSON(http.StatusOK, `{}`),
+			),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args("push", "metrics", "--file", writeExport()).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(4))
+		Expect(lines[0]).To(Equal(
+			"lab: register cluster '11111111-1111-1111-1111-111111111111'",
+		))
+		Expect(lines[1]).To(Equal("edge: update subscription 'sub2'"))
+		Expect(lines[3]).To(Equal("Clusters: 2, registered: 1, updated: 1, failed: 0"))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(5))
+	})
+
+	It("Doesn't change anything in dry run mode", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{
+				"kind": "SubscriptionList",
+				"page": 1,
+				"size": 0,
+				"total": 0,
+				"items": []
+			}`),
+			respondWithSubscriptions(`{
+				"kind": "Subscription",
+				"id": "sub2",
+				"status": "Disconnected"
+			}`),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args("push", "metrics", "--file", writeExport(), "--dry-run").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(4))
+		Expect(lines[3]).To(Equal(
+			"Clusters: 2, to be registered: 1, to be updated: 1, failed: 0",
+		))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Doesn't update clusters that report telemetry", func() {
+		apiServer.AppendHandlers(
+			respondWithSubscriptions(`{
+				"kind": "Subscription",
+				"id": "sub1",
+				"status": "Active"
+			}`),
+			respondWithSubscriptions(`{
+				"kind": "Subscription",
+				"id": "sub2",
+				"status": "Disconnected"
+			}`),
+			RespondWithJSON(http.StatusOK, `{}`),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args("push", "metrics", "--file", writeExport()).
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(2))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"lab: Subscription 'sub1' has status 'Active', only disconnected clusters " +
+				"can be updated",
+		))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Failed to push metrics: 1 of 2 items failed",
+		))
+		lines := result.OutLines()
+		Expect(lines[len(lines)-1]).To(Equal(
+			"Clusters: 2, registered: 0, updated: 1, failed: 1",
+		))
+	})
+
+	It("Rejects invalid export files", func() {
+		tmp, err := os.MkdirTemp("", "ocm-test-*.d")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmp)
+		file := filepath.Join(tmp, "clusters.yaml")
+		err = os.WriteFile(file, []byte("clusters:\n- cluster_id: lab\n"), 0600)
+		Expect(err).ToNot(HaveOccurred())
+		result := NewCommand().
+			ConfigString(config).
+			Args("push", "metrics", "--file", file).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("isn't a valid UUID"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,244 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Quota threshold", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// quotaCosts is the list of quota costs of the organization: one consumed at 90%, one at
+	// 50%, one consumed but not allowed and one that isn't used at all.
+	quotaCosts := `{
+		"kind": "QuotaCostList",
+		"page": 1,
+		"size": 4,
+		"total": 4,
+		"items": [
+			{
+				"kind": "QuotaCost",
+				"quota_id": "cluster|byoc|moa|marketplace",
+				"allowed": 10,
+				"consumed": 9,
+				"related_resources": [
+					{
+						"resource_name": "cluster.aws",
+						"availability_zone_type": "multi",
+						"byoc": "byoc"
+					}
+				]
+			},
+			{
+				"kind": "QuotaCost",
+				"quota_id": "compute.node|gpu|byoc",
+				"allowed": 0,
+				"consumed": 2,
+				"related_resources": [
+					{
+						"resource_name": "compute.node",
+						"availability_zone_type": "any",
+						"byoc": "byoc"
+					}
+				]
+			},
+			{
+				"kind": "QuotaCost",
+				"quota_id": "compute.node|standard|byoc",
+				"allowed": 100,
+				"consumed": 50,
+				"related_resources": [
+					{
+						"resource_name": "compute.node",
+						"availability_zone_type": "any",
+						"byoc": "byoc"
+					}
+				]
+			},
+			{
+				"kind": "QuotaCost",
+				"quota_id": "addon|logging",
+				"allowed": 0,
+				"consumed": 0,
+				"related_resources": [
+					{
+						"resource_name": "add-on",
+						"availability_zone_type": "any",
+						"byoc": "any"
+					}
+				]
+			}
+		]
+	}`
+
+	// currentAccount is the response for the account of the current user.
+	currentAccount := RespondWithJSON(http.StatusOK, `{
+		"kind": "Account",
+		"id": "111",
+		"organization": {
+			"kind": "Organization",
+			"id": "333"
+		}
+	}`)
+
+	// checkedQuota is the response for the request that retrieves the quota to check the
+	// threshold.
+	checkedQuota := func(costs string) http.HandlerFunc {
+		return CombineHandlers(
+			VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/organizations/333/quota_cost"),
+			VerifyFormKV("fetchRelatedResources", "true"),
+			RespondWithJSON(http.StatusOK, costs),
+		)
+	}
+
+	It("Fails with exit code 3 and a summary if a quota is above the threshold", func() {
+		apiServer.AppendHandlers(
+			currentAccount,
+			RespondWithJSON(http.StatusOK, quotaCosts),
+			checkedQuota(quotaCosts),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("list", "quota", "--fail-when-above", "80%").
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(3))
+		Expect(result.OutString()).To(ContainSubstring("cluster|byoc|moa|marketplace"))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"2 quotas of organization '333' are above 80%: " +
+				"compute.node|gpu|byoc (2 of 0, over allowed), " +
+				"cluster|byoc|moa|marketplace (9 of 10, 90%)",
+		))
+	})
+
+	It("Succeeds if no quota is above the threshold", func() {
+		costs := `{
+			"kind": "QuotaCostList",
+			"page": 1,
+			"size": 1,
+			"total": 1,
+			"items": [
+				{
+					"kind": "QuotaCost",
+					"quota_id": "cluster|byoc|moa|marketplace",
+					"allowed": 10,
+					"consumed": 9,
+					"related_resources": [
+						{
+							"resource_name": "cluster.aws",
+							"availability_zone_type": "multi",
+							"byoc": "byoc"
+						}
+					]
+				}
+			]
+		}`
+		apiServer.AppendHandlers(
+			currentAccount,
+			RespondWithJSON(http.StatusOK, costs),
+			checkedQuota(costs),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("list", "quota", "--fail-when-above", "90").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+	})
+
+	It("Accepts the option in the deprecated command", func() {
+		apiServer.AppendHandlers(
+			currentAccount,
+			RespondWithJSON(http.StatusOK, `{
+				"kind": "Organization",
+				"id": "333",
+				"name": "My org"
+			}`),
+			RespondWithJSON(http.StatusOK, quotaCosts),
+			checkedQuota(quotaCosts),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("account", "quota", "--fail-when-above", "95%").
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(3))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"1 quotas of organization '333' are above 95%: " +
+				"compute.node|gpu|byoc (2 of 0, over allowed)",
+		))
+	})
+
+	It("Rejects invalid thresholds", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("list", "quota", "--fail-when-above", "120%").
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(1))
+		Expect(result.ErrString()).To(ContainSubstring("Invalid threshold '120%'"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+})
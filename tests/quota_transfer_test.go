@@ -0,0 +1,316 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+	"gopkg.in/yaml.v3"
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Quota transfer", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server so that it returns the current account and the organizations:
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{
+				"kind": "Account",
+				"id": "111",
+				"username": "my-user",
+				"organization": {
+					"kind": "Organization",
+					"id": "org-a"
+				}
+			}`),
+		)
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// prepareOrgs prepares the server so that it returns the source and destination
+	// organizations and the resource quota of the source.
+	prepareOrgs := func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/organizations/org-a"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Organization",
+					"id": "org-a",
+					"name": "Org A",
+					"external_id": "100"
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/organizations/org-b"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Organization",
+					"id": "org-b",
+					"name": "Org B",
+					"external_id": "200"
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/organizations/org-a/resource_quota"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "ResourceQuotaList",
+					"page": 1,
+					"size": 2,
+					"total": 2,
+					"items": [
+						{
+							"kind": "ResourceQuota",
+							"id": "rq1",
+							"sku": "MW00530",
+							"sku_count": 3
+						},
+						{
+							"kind": "ResourceQuota",
+							"id": "rq2",
+							"sku": "MW00530",
+							"sku_count": 2
+						}
+					]
+				}`),
+			),
+		)
+	}
+
+	// prepareCost prepares the server so that it returns the SKU rule and the quota cost with
+	// the given consumed value. Each unit of the SKU allows 4 units of the quota.
+	prepareCost := func(consumed string) {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/sku_rules"),
+				VerifyFormKV("search", "sku = 'MW00530'"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "SkuRuleList",
+					"page": 1,
+					"size": 1,
+					"total": 1,
+					"items": [
+						{
+							"kind": "SkuRule",
+							"id": "r1",
+							"sku": "MW00530",
+							"quota_id": "cluster|byoc|osd",
+							"allowed": 4
+						}
+					]
+				}`),
+			),
+			RespondWithJSON(http.StatusOK, `{
+				"kind": "QuotaCostList",
+				"page": 1,
+				"size": 1,
+				"total": 1,
+				"items": [
+					{
+						"kind": "QuotaCost",
+						"quota_id": "cluster|byoc|osd",
+						"allowed": 20,
+						"consumed": `+consumed+`
+					}
+				]
+			}`),
+		)
+	}
+
+	It("Writes the request to the standard output", func() {
+		prepareOrgs()
+		prepareCost("8")
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"account", "quota-transfer",
+				"--to", "org-b",
+				"--sku", "MW00530",
+				"--amount", "2",
+				"--reason", "Reorganization",
+			).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		var document map[string]interface{}
+		err := yaml.Unmarshal([]byte(result.OutString()), &document)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(document).To(HaveKeyWithValue("kind", "QuotaTransferRequest"))
+		Expect(document).To(HaveKey("created"))
+		Expect(document).To(HaveKeyWithValue("requester", "my-user"))
+		Expect(document).To(HaveKeyWithValue("from", map[string]interface{}{
+			"id":          "org-a",
+			"name":        "Org A",
+			"external_id": "100",
+		}))
+		Expect(document).To(HaveKeyWithValue("to", map[string]interface{}{
+			"id":          "org-b",
+			"name":        "Org B",
+			"external_id": "200",
+		}))
+		Expect(document).To(HaveKeyWithValue("sku", "MW00530"))
+		Expect(document).To(HaveKeyWithValue("amount", 2))
+		Expect(document).To(HaveKeyWithValue("owned", 5))
+		Expect(document).To(HaveKeyWithValue("available", 3))
+		Expect(document).To(HaveKeyWithValue("reason", "Reorganization"))
+	})
+
+	It("Writes the request to a file", func() {
+		tmp, err := os.MkdirTemp("", "ocm-test-*")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmp)
+		file := filepath.Join(tmp, "transfer.yaml")
+
+		prepareOrgs()
+		prepareCost("0")
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"account", "quota-transfer",
+				"--to", "org-b",
+				"--sku", "MW00530",
+				"--amount", "5",
+				"--reason", "Reorganization",
+				"--output", file,
+			).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal(
+			"Request to transfer 5 units of SKU 'MW00530' from organization 'org-a' to " +
+				"'org-b' written to '" + file + "', attach it to the support case\n",
+		))
+		data, err := os.ReadFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("kind: QuotaTransferRequest\n"))
+		Expect(string(data)).To(ContainSubstring("amount: 5\n"))
+	})
+
+	It("Fails if the amount is larger than the part that isn't consumed", func() {
+		prepareOrgs()
+		prepareCost("12")
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"account", "quota-transfer",
+				"--to", "org-b",
+				"--sku", "MW00530",
+				"--amount", "3",
+				"--reason", "Reorganization",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.OutString()).To(BeEmpty())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Organization 'org-a' can transfer at most 2 units of SKU 'MW00530'",
+		))
+	})
+
+	It("Fails if the organization doesn't own the SKU", func() {
+		prepareOrgs()
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"account", "quota-transfer",
+				"--to", "org-b",
+				"--sku", "MW00999",
+				"--amount", "1",
+				"--reason", "Reorganization",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Organization 'org-a' doesn't own SKU 'MW00999'",
+		))
+	})
+
+	It("Fails if the organizations are the same", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"account", "quota-transfer",
+				"--to", "org-a",
+				"--sku", "MW00530",
+				"--amount", "1",
+				"--reason", "Reorganization",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Source and destination organizations must be different",
+		))
+	})
+
+	It("Fails if the reason is missing", func() {
+		prepareOrgs()
+		prepareCost("0")
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"account", "quota-transfer",
+				"--to", "org-b",
+				"--sku", "MW00530",
+				"--amount", "1",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Option '--reason' is required"))
+	})
+})
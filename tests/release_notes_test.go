@@ -0,0 +1,157 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Release notes", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	BeforeEach(func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/versions"),
+				VerifyFormKV("search", "channel_group = 'stable'"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "VersionList",
+					"page": 1,
+					"size": 4,
+					"total": 4,
+					"items": [
+						{
+							"kind": "Version",
+							"id": "openshift-v4.14.12",
+							"raw_id": "4.14.12"
+						},
+						{
+							"kind": "Version",
+							"id": "openshift-v4.14.10",
+							"raw_id": "4.14.10",
+							"available_upgrades": ["4.14.11", "4.14.12"]
+						},
+						{
+							"kind": "Version",
+							"id": "openshift-v4.14.11",
+							"raw_id": "4.14.11",
+							"release_image": "quay.io/openshift-release-dev/ocp-release:4.14.11"
+						},
+						{
+							"kind": "Version",
+							"id": "openshift-v4.14.13",
+							"raw_id": "4.14.13"
+						}
+					]
+				}`),
+			),
+		)
+	})
+
+	It("Writes the release notes of the versions in the range", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("release-notes", "--from", "4.14.10", "--to", "4.14.12").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(8))
+		Expect(lines[0]).To(MatchRegexp(`^From:\s+4\.14\.10$`))
+		Expect(lines[1]).To(MatchRegexp(`^To:\s+4\.14\.12$`))
+		Expect(lines[2]).To(MatchRegexp(`^Channel group:\s+stable$`))
+		Expect(lines[3]).To(MatchRegexp(`^Direct upgrade:\s+yes$`))
+		Expect(lines[5]).To(MatchRegexp(`^VERSION\s+RELEASE NOTES$`))
+		Expect(lines[6]).To(MatchRegexp(
+			`^4\.14\.11\s+https://docs\.openshift\.com/container-platform/4\.14/` +
+				`release_notes/ocp-4-14-release-notes\.html#ocp-4-14-11_release-notes$`,
+		))
+		Expect(lines[7]).To(MatchRegexp(`^4\.14\.12\s+https://.*#ocp-4-14-12_release-notes$`))
+	})
+
+	It("Writes the report in JSON format", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("release-notes", "--from", "4.14.11", "--to", "4.14.13", "--json").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		var report map[string]interface{}
+		err := json.Unmarshal([]byte(result.OutString()), &report)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report).To(MatchJQ(`.from`, "4.14.11"))
+		Expect(report).To(MatchJQ(`.direct_upgrade`, false))
+		Expect(report).To(MatchJQ(`[.versions[].version]`, []interface{}{"4.14.12", "4.14.13"}))
+	})
+
+	It("Fails if the target version doesn't exist", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("release-notes", "--from", "4.14.10", "--to", "4.14.99").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Version '4.14.99' doesn't exist"))
+	})
+})
@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2" // nolint
+	. "github.com/onsi/gomega"    // nolint
+
+	"github.com/openshift-online/ocm-cli/pkg/sandbox"
+	"github.com/openshift-online/ocm-cli/pkg/urls"
+)
+
+var _ = Describe("Sandbox", func() {
+	var ctx context.Context
+	var server *http.Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Start the sandbox server in the default address, as that is what the alias uses:
+		listener, err := net.Listen("tcp", urls.SandboxAddress)
+		if err != nil {
+			Skip("Sandbox address is in use: " + err.Error())
+		}
+		handler, err := sandbox.NewServer()
+		Expect(err).ToNot(HaveOccurred())
+		server = &http.Server{
+			Handler: handler,
+		}
+		go func() {
+			_ = server.Serve(listener)
+		}()
+
+		// Login without credentials:
+		result := NewCommand().
+			Args("login", "--url", "sandbox").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		if server != nil {
+			server.Close()
+		}
+	})
+
+	It("Saves the sandbox URLs", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("config", "get", "token_url").
+			Run(ctx)
+		Expect(result.OutString()).To(Equal(urls.SandboxTokenURL + "\n"))
+	})
+
+	It("Shows the seeded users", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("whoami").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring("alice"))
+	})
+
+	It("Hibernates a seeded cluster", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("hibernate", "cluster", "sandbox-aws").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+
+		result = NewCommand().
+			ConfigString(config).
+			Args("get", "/api/clusters_mgmt/v1/clusters/cls-1").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring(`"state": "hibernating"`))
+	})
+})
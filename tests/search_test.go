@@ -0,0 +1,281 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Search", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Searches clusters with the default columns", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+				VerifyFormKV("search", "state = 'ready'"),
+				VerifyFormKV("page", "1"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "ClusterList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "Cluster",
+								"id": "123",
+								"name": "my-cluster",
+								"openshift_version": "4.12.1",
+								"cloud_provider": {
+									"id": "aws"
+								},
+								"region": {
+									"id": "us-east-1"
+								},
+								"state": "ready"
+							}
+						]
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("search", "clusters", "state = 'ready'").
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[0]).To(MatchRegexp(
+			`^ID\s+NAME\s+OPENSHIFT_VERSION\s+CLOUD_PROVIDER\s+REGION ID\s+STATE\s*$`,
+		))
+		Expect(lines[1]).To(MatchRegexp(
+			`^123\s+my-cluster\s+4\.12\.1\s+aws\s+us-east-1\s+ready\s*$`,
+		))
+	})
+
+	It("Accepts aliases of the categories and writes JSON", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/subscriptions"),
+				VerifyFormKV("search", "organization_id = '456'"),
+				VerifyFormKV("order", "created_at desc"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "SubscriptionList",
+						"page": 1,
+						"size": 1,
+						"total": 1,
+						"items": [
+							{
+								"kind": "Subscription",
+								"id": "789",
+								"cluster_id": "123",
+								"display_name": "my-cluster",
+								"plan": {
+									"id": "OSD"
+								},
+								"status": "Active",
+								"organization_id": "456"
+							}
+						]
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"search", "subs", "organization_id = '456'",
+				"--order", "created_at desc",
+				"--output", "json",
+			).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchJSON(`[
+			{
+				"id": "789",
+				"cluster_id": "123",
+				"display_name": "my-cluster",
+				"plan.id": "OSD",
+				"status": "Active",
+				"organization_id": "456"
+			}
+		]`))
+	})
+
+	It("Writes the selected columns of all the pages", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+				VerifyFormKV("page", "1"),
+				RespondWithJSONTemplate(
+					http.StatusOK,
+					`{
+						"kind": "AccountList",
+						"page": 1,
+						"size": 100,
+						"total": 101,
+						"items": [
+							{{ range $i, $_ := .ids }}
+							{{ if $i }},{{ end }}
+							{
+								"kind": "Account",
+								"id": "{{ $i }}",
+								"email": "user{{ $i }}@example.com"
+							}
+							{{ end }}
+						]
+					}`,
+					"ids", make([]int, 100),
+				),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/accounts"),
+				VerifyFormKV("page", "2"),
+				RespondWithJSON(
+					http.StatusOK,
+					`{
+						"kind": "AccountList",
+						"page": 2,
+						"size": 1,
+						"total": 101,
+						"items": [
+							{
+								"kind": "Account",
+								"id": "last",
+								"email": "last@example.com"
+							}
+						]
+					}`,
+				),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"search", "accounts", "email like '%@example.com'",
+				"--columns", "id,email",
+				"--output", "yaml",
+			).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(ContainSubstring("- id: last\n  email: last@example.com\n"))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Rejects unknown categories", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("search", "pets", "name = 'rex'").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Unknown category 'pets', valid values are 'clusters', 'subscriptions', " +
+				"'accounts', 'organizations'",
+		))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Rejects invalid search expressions before sending them", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("search", "orgs", "name = 'my").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Invalid search expression"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Reports the errors returned by the server", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(
+				http.StatusBadRequest,
+				`{
+					"kind": "Error",
+					"id": "400",
+					"code": "ACCT-MGMT-400",
+					"reason": "Field 'color' doesn't exist"
+				}`,
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args("search", "orgs", "color = 'red'").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Can't search organizations"))
+		Expect(result.ErrString()).To(ContainSubstring("Field 'color' doesn't exist"))
+	})
+})
@@ -18,10 +18,12 @@ package tests
 
 import (
 	"context"
+	"net/http"
 	"time"
 
-	. "github.com/onsi/ginkgo/v2" // nolint
-	. "github.com/onsi/gomega"    // nolint
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
 
 	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
 )
@@ -73,6 +75,192 @@ var _ = Describe("Token", func() {
 		})
 	})
 
+	When("Requesting tokens with scopes or audiences", func() {
+		var ssoServer *Server
+		var accessToken string
+		var refreshToken string
+		var mintedToken string
+
+		BeforeEach(func() {
+			// Create the server:
+			ssoServer = MakeTCPServer()
+
+			// Create the tokens:
+			accessToken = MakeTokenString("Bearer", 10*time.Minute)
+			refreshToken = MakeTokenString("Refresh", 10*time.Hour)
+			mintedToken = MakeTokenString("Bearer", 5*time.Minute)
+
+			// Create the command:
+			cmd = NewCommand().
+				ConfigString(
+					`{
+						"refresh_token": "{{ .refreshToken }}",
+						"access_token": "{{ .accessToken }}",
+						"url": "http://my-server.example.com",
+						"token_url": "{{ .tokenURL }}"
+					}`,
+					"accessToken", accessToken,
+					"refreshToken", refreshToken,
+					"tokenURL", ssoServer.URL(),
+				).
+				Arg("token")
+		})
+
+		AfterEach(func() {
+			ssoServer.Close()
+		})
+
+		It("Requests a token with the given scopes", func() {
+			ssoServer.AppendHandlers(
+				CombineHandlers(
+					VerifyFormKV("grant_type", "refresh_token"),
+					VerifyFormKV("refresh_token", refreshToken),
+					VerifyFormKV("scope", "openid my-scope"),
+					RespondWithAccessToken(mintedToken),
+				),
+			)
+
+			result := cmd.Args("--scope", "openid", "--scope", "my-scope").Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(Equal(mintedToken + "\n"))
+			Expect(result.ConfigString()).ToNot(ContainSubstring(mintedToken))
+		})
+
+		It("Exchanges the access token for the given audience", func() {
+			ssoServer.AppendHandlers(
+				CombineHandlers(
+					VerifyFormKV("grant_type", "urn:ietf:params:oauth:grant-type:token-exchange"),
+					VerifyFormKV("subject_token", accessToken),
+					VerifyFormKV("audience", "my-audience"),
+					RespondWithAccessToken(mintedToken),
+				),
+			)
+
+			result := cmd.Args("--audience", "my-audience").Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(Equal(mintedToken + "\n"))
+		})
+
+		It("Reports the reason when the SSO server rejects the request", func() {
+			ssoServer.AppendHandlers(
+				RespondWithJSON(
+					http.StatusBadRequest,
+					`{
+						"error": "invalid_scope",
+						"error_description": "Invalid scopes: my-scope"
+					}`,
+				),
+			)
+
+			result := cmd.Args("--scope", "my-scope").Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("Invalid scopes: my-scope"))
+		})
+
+		It("Can't be combined with '--refresh'", func() {
+			result := cmd.Args("--scope", "my-scope", "--refresh").Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("can't be used with '--refresh'"))
+			Expect(ssoServer.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+
+	When("Requesting ephemeral tokens", func() {
+		var ssoServer *Server
+		var refreshToken string
+
+		BeforeEach(func() {
+			// Create the server:
+			ssoServer = MakeTCPServer()
+
+			// Create the tokens. The access token has expired, so that the command needs
+			// to refresh it:
+			accessToken := MakeTokenString("Bearer", -5*time.Minute)
+			refreshToken = MakeTokenString("Refresh", 10*time.Hour)
+
+			// Create the command:
+			cmd = NewCommand().
+				ConfigString(
+					`{
+						"refresh_token": "{{ .refreshToken }}",
+						"access_token": "{{ .accessToken }}",
+						"url": "http://my-server.example.com",
+						"token_url": "{{ .tokenURL }}"
+					}`,
+					"accessToken", accessToken,
+					"refreshToken", refreshToken,
+					"tokenURL", ssoServer.URL(),
+				).
+				Args("token", "--ephemeral")
+		})
+
+		AfterEach(func() {
+			ssoServer.Close()
+		})
+
+		It("Prints a new token and doesn't save anything", func() {
+			refreshedToken := MakeTokenString("Bearer", 10*time.Minute)
+			ephemeralToken := MakeTokenString("Bearer", 5*time.Minute)
+			ssoServer.AppendHandlers(
+				RespondWithAccessAndRefreshTokens(refreshedToken, refreshToken),
+				CombineHandlers(
+					VerifyFormKV("grant_type", "refresh_token"),
+					VerifyFormKV("refresh_token", refreshToken),
+					RespondWithAccessToken(ephemeralToken),
+				),
+			)
+
+			result := cmd.Args("--max-lifetime", "5m").Run(ctx)
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(Equal(ephemeralToken + "\n"))
+			Expect(result.ConfigString()).ToNot(ContainSubstring(ephemeralToken))
+			Expect(result.ConfigString()).ToNot(ContainSubstring(refreshedToken))
+		})
+
+		It("Doesn't print tokens that live longer than the maximum", func() {
+			ssoServer.AppendHandlers(
+				RespondWithAccessAndRefreshTokens(MakeTokenString("Bearer", 10*time.Minute), refreshToken),
+				RespondWithAccessToken(MakeTokenString("Bearer", 10*time.Minute)),
+			)
+
+			result := cmd.Args("--max-lifetime", "5m").Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.OutString()).To(BeEmpty())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"which is longer than the maximum of 5m0s",
+			))
+		})
+
+		It("Accepts the lifetime of the SSO tokens by default", func() {
+			ephemeralToken := MakeTokenString("Bearer", 15*time.Minute)
+			ssoServer.AppendHandlers(
+				RespondWithAccessAndRefreshTokens(MakeTokenString("Bearer", 15*time.Minute), refreshToken),
+				RespondWithAccessToken(ephemeralToken),
+			)
+
+			result := cmd.Run(ctx)
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).To(Equal(ephemeralToken + "\n"))
+		})
+
+		It("Can't be combined with '--generate'", func() {
+			result := cmd.Args("--generate").Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring("can't be used with '--refresh' or '--generate'"))
+			Expect(ssoServer.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("Rejects '--max-lifetime' without '--ephemeral'", func() {
+			result := NewCommand().Args("token", "--max-lifetime", "5m").Run(ctx)
+			Expect(result.ExitCode()).ToNot(BeZero())
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Option '--max-lifetime' can only be used with '--ephemeral'",
+			))
+		})
+	})
+
 	When("Not logged in", func() {
 		BeforeEach(func() {
 			cmd = NewCommand().Arg("token")
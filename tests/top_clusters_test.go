@@ -0,0 +1,199 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Top clusters", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// subscriptions returns a handler that responds with three subscriptions, one of them
+	// without metrics.
+	subscriptions := func() http.HandlerFunc {
+		return CombineHandlers(
+			VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/subscriptions"),
+			VerifyFormKV("search", "status = 'Active'"),
+			RespondWithJSON(
+				http.StatusOK,
+				`{
+					"kind": "SubscriptionList",
+					"page": 1,
+					"size": 3,
+					"total": 3,
+					"items": [
+						{
+							"kind": "Subscription",
+							"cluster_id": "123",
+							"display_name": "small",
+							"metrics": [
+								{
+									"cpu": {
+										"used": {"value": 2, "unit": "B"},
+										"total": {"value": 8, "unit": "B"}
+									},
+									"memory": {
+										"used": {"value": 8589934592, "unit": "B"},
+										"total": {"value": 34359738368, "unit": "B"}
+									},
+									"nodes": {"total": 5}
+								}
+							]
+						},
+						{
+							"kind": "Subscription",
+							"cluster_id": "456",
+							"display_name": "big",
+							"metrics": [
+								{
+									"cpu": {
+										"used": {"value": 30, "unit": "B"},
+										"total": {"value": 40, "unit": "B"}
+									},
+									"memory": {
+										"used": {"value": 4294967296, "unit": "B"},
+										"total": {"value": 68719476736, "unit": "B"}
+									},
+									"nodes": {"total": 9}
+								}
+							]
+						},
+						{
+							"kind": "Subscription",
+							"cluster_id": "789",
+							"display_name": "silent"
+						}
+					]
+				}`,
+			),
+		)
+	}
+
+	It("Ranks the clusters by CPU used", func() {
+		apiServer.AppendHandlers(subscriptions())
+		result := NewCommand().
+			ConfigString(config).
+			Args("top", "clusters").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(MatchRegexp(`^ID\s+NAME\s+CPU\s+MEMORY \(GiB\)\s+NODES$`))
+		Expect(lines[1]).To(MatchRegexp(`^456\s+big\s+30\.00/40\.00 \(75%\)\s+4\.00/64\.00 \(6%\)\s+9$`))
+		Expect(lines[2]).To(MatchRegexp(`^123\s+small\s+2\.00/8\.00 \(25%\)\s+8\.00/32\.00 \(25%\)\s+5$`))
+	})
+
+	It("Ranks the clusters by memory used in ascending order", func() {
+		apiServer.AppendHandlers(subscriptions())
+		result := NewCommand().
+			ConfigString(config).
+			Args("top", "clusters", "--sort-by", "memory", "--ascending", "--limit", "1").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		lines := result.OutLines()
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[1]).To(HavePrefix("456 "))
+	})
+
+	It("Writes the ranking in JSON format", func() {
+		apiServer.AppendHandlers(subscriptions())
+		result := NewCommand().
+			ConfigString(config).
+			Args("top", "clusters", "--sort-by", "nodes", "--json").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchJSON(`[
+			{
+				"id": "456",
+				"name": "big",
+				"cpu_used": 30,
+				"cpu_total": 40,
+				"memory_used": 4294967296,
+				"memory_total": 68719476736,
+				"nodes": 9
+			},
+			{
+				"id": "123",
+				"name": "small",
+				"cpu_used": 2,
+				"cpu_total": 8,
+				"memory_used": 8589934592,
+				"memory_total": 34359738368,
+				"nodes": 5
+			}
+		]`))
+	})
+
+	It("Rejects an unknown sort key", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("top", "clusters", "--sort-by", "disk").
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(ContainSubstring("Unknown sort key 'disk'"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,150 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Trace", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Prints each request and the summary", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{"kind": "ClusterList", "page": 2, "items": []}`),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"get", "--trace",
+				"--parameter", "page=2",
+				"/api/clusters_mgmt/v1/clusters",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(MatchJSON(`{"kind": "ClusterList", "page": 2, "items": []}`))
+		Expect(result.ErrLines()).To(HaveLen(2))
+		Expect(result.ErrLines()[0]).To(MatchRegexp(
+			`^TRACE GET /api/clusters_mgmt/v1/clusters page=2 status=200 latency=\d+(\.\d+)?m?s conn=new$`,
+		))
+		Expect(result.ErrLines()[1]).To(MatchRegexp(
+			`^TRACE requests=1 latency=\S+ total=\S+ connections=1 reused=0$`,
+		))
+	})
+
+	It("Reuses the connection for multiple requests", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{"id": "123", "state": "installing"}`),
+			RespondWithJSON(http.StatusOK, `{"id": "123", "state": "installing"}`),
+			RespondWithJSON(http.StatusOK, `{"id": "123", "state": "ready"}`),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"get", "--trace",
+				"--poll", "10ms",
+				"--until", `.state == "ready"`,
+				"/api/clusters_mgmt/v1/clusters/123",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrLines()).To(HaveLen(4))
+		Expect(result.ErrLines()[0]).To(HaveSuffix(" conn=new"))
+		Expect(result.ErrLines()[1]).To(HaveSuffix(" conn=reused"))
+		Expect(result.ErrLines()[2]).To(HaveSuffix(" conn=reused"))
+		Expect(result.ErrLines()[3]).To(HaveSuffix(" connections=1 reused=2"))
+	})
+
+	It("Prints requests that fail", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusNotFound, `{"kind": "Error", "id": "404"}`),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"get", "--trace",
+				"/api/clusters_mgmt/v1/clusters/123",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).ToNot(BeZero())
+		Expect(result.ErrString()).To(MatchRegexp(
+			`TRACE GET /api/clusters_mgmt/v1/clusters/123 page=- status=404 latency=`,
+		))
+	})
+
+	It("Doesn't print anything without the option", func() {
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{"kind": "ClusterList", "items": []}`),
+		)
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"get",
+				"/api/clusters_mgmt/v1/clusters",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+	})
+})
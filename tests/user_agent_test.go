@@ -0,0 +1,152 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("User agent", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+
+		// Prepare the server:
+		apiServer.AppendHandlers(
+			RespondWithJSON(http.StatusOK, `{"kind": "ClusterList", "items": []}`),
+		)
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	// setUserAgent changes the 'user_agent' setting of the configuration:
+	setUserAgent := func(value string) {
+		result := NewCommand().
+			ConfigString(config).
+			Args("config", "set", "user_agent", value).
+			Run(ctx)
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	}
+
+	// userAgent returns the user agent of the request received by the server:
+	userAgent := func() string {
+		requests := apiServer.ReceivedRequests()
+		Expect(requests).To(HaveLen(1))
+		return requests[0].Header.Get("User-Agent")
+	}
+
+	It("Only identifies the command line tool by default", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("get", "/api/clusters_mgmt/v1/clusters").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(userAgent()).To(MatchRegexp(`^OCM-CLI/\S+$`))
+	})
+
+	It("Adds the identifier from the configuration", func() {
+		setUserAgent("team-a-tool/1.0")
+		result := NewCommand().
+			ConfigString(config).
+			Args("get", "/api/clusters_mgmt/v1/clusters").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(userAgent()).To(MatchRegexp(`^OCM-CLI/\S+ team-a-tool/1\.0$`))
+	})
+
+	It("Gives precedence to the environment variable over the configuration", func() {
+		setUserAgent("team-a-tool/1.0")
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_USER_AGENT", "team-b-tool/2.0").
+			Args("get", "/api/clusters_mgmt/v1/clusters").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(userAgent()).To(HaveSuffix(" team-b-tool/2.0"))
+	})
+
+	It("Gives precedence to the flag over the environment variable", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Env("OCM_USER_AGENT", "team-b-tool/2.0").
+			Args("get", "--user-agent", "team-c-job (nightly)", "/api/clusters_mgmt/v1/clusters").
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(userAgent()).To(HaveSuffix(" team-c-job (nightly)"))
+	})
+
+	It("Rejects identifiers that can't be used in the header", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args("config", "set", "user_agent", "team\ttool").
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(1))
+		Expect(result.ErrString()).To(ContainSubstring("Invalid user agent"))
+
+		result = NewCommand().
+			ConfigString(config).
+			Args("get", "--user-agent", "tëam", "/api/clusters_mgmt/v1/clusters").
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(1))
+		Expect(result.ErrString()).To(ContainSubstring("Invalid user agent 'tëam'"))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+})
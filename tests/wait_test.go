@@ -0,0 +1,393 @@
+/*
+Copyright (c) 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"    // nolint
+	. "github.com/onsi/gomega"       // nolint
+	. "github.com/onsi/gomega/ghttp" // nolint
+
+	. "github.com/openshift-online/ocm-sdk-go/testing" // nolint
+)
+
+var _ = Describe("Wait", func() {
+	var ctx context.Context
+	var ssoServer *Server
+	var apiServer *Server
+	var config string
+
+	BeforeEach(func() {
+		// Create a context:
+		ctx = context.Background()
+
+		// Create the servers:
+		ssoServer = MakeTCPServer()
+		apiServer = MakeTCPServer()
+
+		// Create the token:
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+
+		// Prepare the server:
+		ssoServer.AppendHandlers(
+			RespondWithAccessToken(accessToken),
+		)
+
+		// Login:
+		result := NewCommand().
+			Args(
+				"login",
+				"--client-id", "my-client",
+				"--client-secret", "my-secret",
+				"--token-url", ssoServer.URL(),
+				"--url", apiServer.URL(),
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		config = result.ConfigString()
+	})
+
+	AfterEach(func() {
+		// Close the servers:
+		ssoServer.Close()
+		apiServer.Close()
+	})
+
+	It("Waits till the cluster is ready", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Cluster",
+					"id": "123",
+					"state": "installing"
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Cluster",
+					"id": "123",
+					"state": "ready"
+				}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"wait",
+				"--for=condition=ready",
+				"--interval=10ms",
+				"cluster/123",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.ErrString()).To(BeEmpty())
+		Expect(result.OutString()).To(Equal("cluster/123 condition met\n"))
+	})
+
+	It("Waits till the machine pool is deleted", func() {
+		path := "/api/clusters_mgmt/v1/clusters/123/machine_pools/worker"
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, path),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "MachinePool",
+					"id": "worker"
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, path),
+				RespondWithJSON(http.StatusNotFound, `{
+					"kind": "Error",
+					"id": "404"
+				}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"wait",
+				"--for=deleted",
+				"--interval=10ms",
+				"machinepool/123/worker",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal("machinepool/123/worker condition met\n"))
+	})
+
+	It("Waits till the jq expression is true", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/subscriptions/456"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Subscription",
+					"id": "456",
+					"status": "Reserved"
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/subscriptions/456"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Subscription",
+					"id": "456",
+					"status": "Active"
+				}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"wait",
+				`--for=jq=.status == "Active"`,
+				"--interval=10ms",
+				"subscription/456",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(BeZero())
+		Expect(result.OutString()).To(Equal("subscription/456 condition met\n"))
+	})
+
+	It("Fails immediately if the cluster is in error state", func() {
+		apiServer.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Cluster",
+					"id": "123",
+					"state": "error"
+				}`),
+			),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"wait",
+				"--for=condition=ready",
+				"cluster/123",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(1))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Object 'cluster/123' is in state 'error'",
+		))
+	})
+
+	It("Fails when the timeout expires", func() {
+		apiServer.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123",
+			RespondWithJSON(http.StatusOK, `{
+				"kind": "Cluster",
+				"id": "123",
+				"state": "installing"
+			}`),
+		)
+
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"wait",
+				"--for=condition=ready",
+				"--interval=10ms",
+				"--timeout=100ms",
+				"cluster/123",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(1))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Timed out after 100ms waiting for 'condition=ready' on cluster/123",
+		))
+	})
+
+	It("Rejects invalid conditions", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"wait",
+				"--for=ready",
+				"cluster/123",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(1))
+		Expect(result.ErrString()).To(ContainSubstring("Invalid condition 'ready'"))
+	})
+
+	It("Rejects invalid references", func() {
+		result := NewCommand().
+			ConfigString(config).
+			Args(
+				"wait",
+				"--for=deleted",
+				"machinepool/worker",
+			).
+			Run(ctx)
+		Expect(result.ExitCode()).To(Equal(1))
+		Expect(result.ErrString()).To(ContainSubstring(
+			"Reference 'machinepool/worker' isn't valid",
+		))
+	})
+
+	When("Writing the output for GitHub Actions", func() {
+		var tmp string
+		var outputFile string
+
+		BeforeEach(func() {
+			var err error
+			tmp, err = os.MkdirTemp("", "ocm-test-*")
+			Expect(err).ToNot(HaveOccurred())
+			outputFile = filepath.Join(tmp, "output")
+		})
+
+		AfterEach(func() {
+			err := os.RemoveAll(tmp)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Writes the annotation and the details of the cluster", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Cluster",
+					"id": "123",
+					"name": "my-cluster",
+					"state": "ready",
+					"api": {
+						"url": "https://api.my-cluster.example.com:6443"
+					},
+					"console": {
+						"url": "https://console.my-cluster.example.com"
+					}
+				}`),
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Env("GITHUB_OUTPUT", outputFile).
+				Args(
+					"wait",
+					"--for=condition=ready",
+					"--interval=10ms",
+					"--output=github-actions",
+					"cluster/123",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			Expect(result.OutString()).To(Equal(
+				"cluster/123 condition met\n" +
+					"::notice::Condition 'condition=ready' met for 'cluster/123'\n",
+			))
+			data, err := os.ReadFile(outputFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(
+				"api_url=https://api.my-cluster.example.com:6443\n" +
+					"cluster_id=123\n" +
+					"cluster_name=my-cluster\n" +
+					"console_url=https://console.my-cluster.example.com\n" +
+					"state=ready\n",
+			))
+		})
+
+		It("Doesn't set outputs when waiting for multiple objects", func() {
+			apiServer.AppendHandlers(
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Cluster",
+					"id": "123",
+					"state": "ready"
+				}`),
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Cluster",
+					"id": "456",
+					"state": "ready"
+				}`),
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Env("GITHUB_OUTPUT", outputFile).
+				Args(
+					"wait",
+					"--for=condition=ready",
+					"--interval=10ms",
+					"--output=github-actions",
+					"cluster/123",
+					"cluster/456",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutLines()).To(ContainElement(
+				"::notice::Condition 'condition=ready' met for 'cluster/456'",
+			))
+			Expect(outputFile).ToNot(BeAnExistingFile())
+		})
+
+		It("Writes the error annotation when the timeout expires", func() {
+			apiServer.RouteToHandler(
+				http.MethodGet,
+				"/api/clusters_mgmt/v1/clusters/123",
+				RespondWithJSON(http.StatusOK, `{
+					"kind": "Cluster",
+					"id": "123",
+					"state": "installing"
+				}`),
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Env("GITHUB_OUTPUT", outputFile).
+				Args(
+					"wait",
+					"--for=condition=ready",
+					"--interval=10ms",
+					"--timeout=100ms",
+					"--output=github-actions",
+					"cluster/123",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(Equal(1))
+			Expect(result.OutString()).To(Equal(
+				"::error::Timed out after 100ms waiting for 'condition=ready' on cluster/123\n",
+			))
+		})
+
+		It("Rejects unknown output formats", func() {
+			result := NewCommand().
+				ConfigString(config).
+				Args(
+					"wait",
+					"--for=condition=ready",
+					"--output=json",
+					"cluster/123",
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(Equal(1))
+			Expect(result.ErrString()).To(ContainSubstring(
+				"Unknown output format 'json', the only supported value is 'github-actions'",
+			))
+		})
+	})
+})
@@ -19,6 +19,7 @@ package tests
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 
@@ -113,4 +114,158 @@ var _ = Describe("Whoami", func() {
 			))
 		})
 	})
+
+	When("Printing the summary", func() {
+		var ssoServer *Server
+		var apiServer *Server
+		var config string
+
+		BeforeEach(func() {
+			// Create the servers:
+			ssoServer = MakeTCPServer()
+			apiServer = MakeTCPServer()
+
+			// Login:
+			ssoServer.AppendHandlers(
+				RespondWithAccessToken(MakeTokenString("Bearer", 15*time.Minute)),
+			)
+			result := NewCommand().
+				Args(
+					"login",
+					"--client-id", "my-client",
+					"--client-secret", "my-secret",
+					"--token-url", ssoServer.URL(),
+					"--url", apiServer.URL(),
+				).
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			config = result.ConfigString()
+
+			// Prepare the server so that it returns the current account:
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/accounts_mgmt/v1/current_account"),
+					RespondWithJSON(
+						http.StatusOK,
+						`{
+							"kind": "Account",
+							"id": "123",
+							"username": "jdoe",
+							"first_name": "Jane",
+							"last_name": "Doe",
+							"email": "jdoe@example.com",
+							"organization": {
+								"kind": "Organization",
+								"id": "456",
+								"name": "My org"
+							}
+						}`,
+					),
+				),
+			)
+		})
+
+		AfterEach(func() {
+			// Close the servers:
+			ssoServer.Close()
+			apiServer.Close()
+		})
+
+		It("Prints the clusters and the remaining quota", func() {
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters"),
+					RespondWithJSON(
+						http.StatusOK,
+						`{
+							"kind": "ClusterList",
+							"page": 1,
+							"size": 3,
+							"total": 3,
+							"items": [
+								{ "kind": "Cluster", "id": "1", "state": "ready" },
+								{ "kind": "Cluster", "id": "2", "state": "ready" },
+								{ "kind": "Cluster", "id": "3", "state": "installing" }
+							]
+						}`,
+					),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/accounts_mgmt/v1/organizations/456/quota_cost",
+					),
+					RespondWithJSON(
+						http.StatusOK,
+						`{
+							"kind": "QuotaCostList",
+							"page": 1,
+							"size": 2,
+							"total": 2,
+							"items": [
+								{
+									"kind": "QuotaCost",
+									"quota_id": "cluster|byoc|moa",
+									"allowed": 10,
+									"consumed": 3
+								},
+								{
+									"kind": "QuotaCost",
+									"quota_id": "addon|unused",
+									"allowed": 0,
+									"consumed": 0
+								}
+							]
+						}`,
+					),
+				),
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Args("whoami", "--clusters-summary", "--quota-summary").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.ErrString()).To(BeEmpty())
+			lines := result.OutLines()
+			Expect(lines).To(HaveLen(9))
+			Expect(lines[0]).To(MatchRegexp(`^User:\s+jdoe$`))
+			Expect(lines[1]).To(MatchRegexp(`^Name:\s+Jane Doe$`))
+			Expect(lines[2]).To(MatchRegexp(`^Email:\s+jdoe@example.com$`))
+			Expect(lines[3]).To(MatchRegexp(`^Organization:\s+My org \(456\)$`))
+			Expect(lines[4]).To(MatchRegexp(`^API URL:\s+%s$`, apiServer.URL()))
+			Expect(lines[5]).To(MatchRegexp(`^Clusters:\s+3 \(1 installing, 2 ready\)$`))
+			Expect(lines[6]).To(Equal("Quota:"))
+			Expect(lines[8]).To(MatchRegexp(`^\s+cluster\|byoc\|moa\s+3\s+10\s+7$`))
+		})
+
+		It("Doesn't retrieve the clusters if not requested", func() {
+			apiServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/accounts_mgmt/v1/organizations/456/quota_cost",
+					),
+					RespondWithJSON(
+						http.StatusOK,
+						`{
+							"kind": "QuotaCostList",
+							"page": 1,
+							"size": 0,
+							"total": 0,
+							"items": []
+						}`,
+					),
+				),
+			)
+
+			result := NewCommand().
+				ConfigString(config).
+				Args("whoami", "--quota-summary").
+				Run(ctx)
+			Expect(result.ExitCode()).To(BeZero())
+			Expect(result.OutString()).ToNot(ContainSubstring("Clusters:"))
+			Expect(result.OutString()).To(ContainSubstring("Quota:"))
+		})
+	})
 })